@@ -104,6 +104,7 @@ func main() {
 		logger,
 		station.ManagerConfig{
 			SyncInterval: 30 * time.Second,
+			CallTimeout:  cfg.CSMS.CallTimeout,
 		},
 	)
 	logger.Info("Station manager initialized")
@@ -505,6 +506,30 @@ func main() {
 			return
 		}
 
+		// Check if path ends with /device-model (GET viewer + admin, PUT admin only)
+		if strings.HasSuffix(r.URL.Path, "/device-model") {
+			if r.Method == http.MethodPut {
+				if !isAdmin {
+					http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+					return
+				}
+				stationHandler.ImportDeviceModel(w, r)
+				return
+			}
+			stationHandler.GetDeviceModel(w, r)
+			return
+		}
+
+		// Check if path ends with /external-constraints (admin only)
+		if strings.HasSuffix(r.URL.Path, "/external-constraints") {
+			if !isAdmin {
+				http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+				return
+			}
+			stationHandler.SetExternalConstraints(w, r)
+			return
+		}
+
 		// Check if path ends with /charge (admin only)
 		if strings.HasSuffix(r.URL.Path, "/charge") {
 			if !isAdmin {
@@ -2,12 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ruslanhut/ocpp-emu/internal/ocpp"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
 	"github.com/ruslanhut/ocpp-emu/internal/station"
 )
 
@@ -44,6 +48,7 @@ type StationResponse struct {
 	MeterValuesConfig MeterValuesConfigResponse `json:"meterValuesConfig"`
 	CSMSURL           string                    `json:"csmsUrl"`
 	CSMSAuth          *CSMSAuthResponse         `json:"csmsAuth,omitempty"`
+	SecurityProfile   int                       `json:"securityProfile"`
 	Simulation        SimulationConfigResponse  `json:"simulation"`
 	RuntimeState      *RuntimeStateResponse     `json:"runtimeState,omitempty"`
 	CreatedAt         time.Time                 `json:"createdAt"`
@@ -58,6 +63,8 @@ type ConnectorResponse struct {
 	MaxPower             int    `json:"maxPower"`
 	Status               string `json:"status"`
 	CurrentTransactionID *int   `json:"currentTransactionId,omitempty"`
+	PlugAndCharge        bool   `json:"plugAndCharge,omitempty"`
+	EMAID                string `json:"emaid,omitempty"`
 }
 
 // MeterValuesConfigResponse represents meter values config in API response
@@ -77,13 +84,43 @@ type CSMSAuthResponse struct {
 
 // SimulationConfigResponse represents simulation config in API response
 type SimulationConfigResponse struct {
-	BootDelay                  int     `json:"bootDelay"`
-	HeartbeatInterval          int     `json:"heartbeatInterval"`
-	StatusNotificationOnChange bool    `json:"statusNotificationOnChange"`
-	DefaultIDTag               string  `json:"defaultIdTag"`
-	EnergyDeliveryRate         int     `json:"energyDeliveryRate"`
-	RandomizeMeterValues       bool    `json:"randomizeMeterValues"`
-	MeterValueVariance         float64 `json:"meterValueVariance"`
+	BootDelay                  int                        `json:"bootDelay"`
+	HeartbeatInterval          int                        `json:"heartbeatInterval"`
+	StatusNotificationOnChange bool                       `json:"statusNotificationOnChange"`
+	DefaultIDTag               string                     `json:"defaultIdTag"`
+	EnergyDeliveryRate         int                        `json:"energyDeliveryRate"`
+	RandomizeMeterValues       bool                       `json:"randomizeMeterValues"`
+	MeterValueVariance         float64                    `json:"meterValueVariance"`
+	Firmware                   FirmwareSimulationResponse `json:"firmware"`
+	EV                         EVSimulationResponse       `json:"ev"`
+}
+
+// FirmwareSimulationResponse represents firmware simulation config in API response
+type FirmwareSimulationResponse struct {
+	DownloadDuration int    `json:"downloadDuration"`
+	InstallDuration  int    `json:"installDuration"`
+	UploadDuration   int    `json:"uploadDuration"`
+	DownloadFailures int    `json:"downloadFailures"`
+	UploadFailures   int    `json:"uploadFailures"`
+	FailInstallation bool   `json:"failInstallation"`
+	TargetVersion    string `json:"targetVersion,omitempty"`
+	PublishAddress   string `json:"publishAddress,omitempty"`
+}
+
+// EVSimulationResponse represents simulated EV config in API response
+type EVSimulationResponse struct {
+	BatteryCapacity   int  `json:"batteryCapacity"`
+	InitialSoC        int  `json:"initialSoC"`
+	TargetSoC         int  `json:"targetSoC"`
+	MinSoC            int  `json:"minSoC"`
+	MaxChargePower    int  `json:"maxChargePower"`
+	MaxDischargePower int  `json:"maxDischargePower"`
+	DepartureDuration int  `json:"departureDuration"`
+	V2X               bool `json:"v2x"`
+	ISO15118          bool `json:"iso15118"`
+	EnergyRequest     int  `json:"energyRequest"`
+	MinCurrent        int  `json:"minCurrent"`
+	MaxCurrent        int  `json:"maxCurrent"`
 }
 
 // RuntimeStateResponse represents runtime state in API response
@@ -93,7 +130,33 @@ type RuntimeStateResponse struct {
 	LastHeartbeat    *time.Time `json:"lastHeartbeat,omitempty"`
 	LastError        string     `json:"lastError,omitempty"`
 	ConnectedAt      *time.Time `json:"connectedAt,omitempty"`
+	NetworkSlot      int        `json:"networkSlot,omitempty"`
 	TransactionID    *int       `json:"transactionId,omitempty"`
+	QueuedMessages   int        `json:"queuedMessages"`
+
+	// What the EV driver sees on the display of an OCPP 2.0.1/2.1 station
+	DisplayedMessage *DisplayedMessageResponse `json:"displayedMessage,omitempty"`
+	RunningCost      *RunningCostResponse      `json:"runningCost,omitempty"`
+}
+
+// DisplayedMessageResponse represents the display message currently shown in API response
+type DisplayedMessageResponse struct {
+	ID            int        `json:"id"`
+	Priority      string     `json:"priority"`
+	State         string     `json:"state,omitempty"`
+	Format        string     `json:"format"`
+	Language      string     `json:"language,omitempty"`
+	Content       string     `json:"content"`
+	TransactionID string     `json:"transactionId,omitempty"`
+	StartDateTime *time.Time `json:"startDateTime,omitempty"`
+	EndDateTime   *time.Time `json:"endDateTime,omitempty"`
+}
+
+// RunningCostResponse represents the running cost of a transaction in API response
+type RunningCostResponse struct {
+	TransactionID string    `json:"transactionId"`
+	TotalCost     float64   `json:"totalCost"`
+	UpdatedAt     time.Time `json:"updatedAt"`
 }
 
 // CreateStationRequest represents the request to create a new station
@@ -114,15 +177,18 @@ type CreateStationRequest struct {
 	MeterValuesConfig MeterValuesConfigRequest `json:"meterValuesConfig"`
 	CSMSURL           string                   `json:"csmsUrl"`
 	CSMSAuth          *CSMSAuthRequest         `json:"csmsAuth,omitempty"`
+	SecurityProfile   int                      `json:"securityProfile"`
 	Simulation        SimulationConfigRequest  `json:"simulation"`
 	Tags              []string                 `json:"tags,omitempty"`
 }
 
 // ConnectorRequest represents a connector in create/update request
 type ConnectorRequest struct {
-	ID       int    `json:"id"`
-	Type     string `json:"type"`
-	MaxPower int    `json:"maxPower"`
+	ID            int    `json:"id"`
+	Type          string `json:"type"`
+	MaxPower      int    `json:"maxPower"`
+	PlugAndCharge bool   `json:"plugAndCharge,omitempty"`
+	EMAID         string `json:"emaid,omitempty"`
 }
 
 // MeterValuesConfigRequest represents meter values config in request
@@ -142,13 +208,43 @@ type CSMSAuthRequest struct {
 
 // SimulationConfigRequest represents simulation config in request
 type SimulationConfigRequest struct {
-	BootDelay                  int     `json:"bootDelay"`
-	HeartbeatInterval          int     `json:"heartbeatInterval"`
-	StatusNotificationOnChange bool    `json:"statusNotificationOnChange"`
-	DefaultIDTag               string  `json:"defaultIdTag"`
-	EnergyDeliveryRate         int     `json:"energyDeliveryRate"`
-	RandomizeMeterValues       bool    `json:"randomizeMeterValues"`
-	MeterValueVariance         float64 `json:"meterValueVariance"`
+	BootDelay                  int                       `json:"bootDelay"`
+	HeartbeatInterval          int                       `json:"heartbeatInterval"`
+	StatusNotificationOnChange bool                      `json:"statusNotificationOnChange"`
+	DefaultIDTag               string                    `json:"defaultIdTag"`
+	EnergyDeliveryRate         int                       `json:"energyDeliveryRate"`
+	RandomizeMeterValues       bool                      `json:"randomizeMeterValues"`
+	MeterValueVariance         float64                   `json:"meterValueVariance"`
+	Firmware                   FirmwareSimulationRequest `json:"firmware"`
+	EV                         EVSimulationRequest       `json:"ev"`
+}
+
+// FirmwareSimulationRequest represents firmware simulation config in request
+type FirmwareSimulationRequest struct {
+	DownloadDuration int    `json:"downloadDuration"`
+	InstallDuration  int    `json:"installDuration"`
+	UploadDuration   int    `json:"uploadDuration"`
+	DownloadFailures int    `json:"downloadFailures"`
+	UploadFailures   int    `json:"uploadFailures"`
+	FailInstallation bool   `json:"failInstallation"`
+	TargetVersion    string `json:"targetVersion,omitempty"`
+	PublishAddress   string `json:"publishAddress,omitempty"`
+}
+
+// EVSimulationRequest represents simulated EV config in request
+type EVSimulationRequest struct {
+	BatteryCapacity   int  `json:"batteryCapacity"`
+	InitialSoC        int  `json:"initialSoC"`
+	TargetSoC         int  `json:"targetSoC"`
+	MinSoC            int  `json:"minSoC"`
+	MaxChargePower    int  `json:"maxChargePower"`
+	MaxDischargePower int  `json:"maxDischargePower"`
+	DepartureDuration int  `json:"departureDuration"`
+	V2X               bool `json:"v2x"`
+	ISO15118          bool `json:"iso15118"`
+	EnergyRequest     int  `json:"energyRequest"`
+	MinCurrent        int  `json:"minCurrent"`
+	MaxCurrent        int  `json:"maxCurrent"`
 }
 
 // ListStations handles GET /api/stations
@@ -416,6 +512,8 @@ func (h *StationHandler) convertToResponse(st *station.Station) StationResponse
 			MaxPower:             c.MaxPower,
 			Status:               c.Status,
 			CurrentTransactionID: c.CurrentTransactionID,
+			PlugAndCharge:        c.PlugAndCharge,
+			EMAID:                c.EMAID,
 		}
 	}
 
@@ -429,6 +527,36 @@ func (h *StationHandler) convertToResponse(st *station.Station) StationResponse
 		}
 	}
 
+	var displayedMessage *DisplayedMessageResponse
+	if msg := runtimeState.DisplayedMessage; msg != nil {
+		displayedMessage = &DisplayedMessageResponse{
+			ID:            msg.Id,
+			Priority:      string(msg.Priority),
+			Format:        string(msg.Message.Format),
+			Language:      msg.Message.Language,
+			Content:       msg.Message.Content,
+			TransactionID: msg.TransactionId,
+		}
+		if msg.State != nil {
+			displayedMessage.State = string(*msg.State)
+		}
+		if msg.StartDateTime != nil {
+			displayedMessage.StartDateTime = &msg.StartDateTime.Time
+		}
+		if msg.EndDateTime != nil {
+			displayedMessage.EndDateTime = &msg.EndDateTime.Time
+		}
+	}
+
+	var runningCost *RunningCostResponse
+	if cost := runtimeState.RunningCost; cost != nil {
+		runningCost = &RunningCostResponse{
+			TransactionID: cost.TransactionID,
+			TotalCost:     cost.TotalCost,
+			UpdatedAt:     cost.UpdatedAt,
+		}
+	}
+
 	return StationResponse{
 		ID:                config.ID,
 		StationID:         config.StationID,
@@ -449,8 +577,9 @@ func (h *StationHandler) convertToResponse(st *station.Station) StationResponse
 			Measurands:          config.MeterValuesConfig.Measurands,
 			AlignedDataInterval: config.MeterValuesConfig.AlignedDataInterval,
 		},
-		CSMSURL:  config.CSMSURL,
-		CSMSAuth: csmsAuth,
+		CSMSURL:         config.CSMSURL,
+		CSMSAuth:        csmsAuth,
+		SecurityProfile: config.SecurityProfile,
 		Simulation: SimulationConfigResponse{
 			BootDelay:                  config.Simulation.BootDelay,
 			HeartbeatInterval:          config.Simulation.HeartbeatInterval,
@@ -459,6 +588,8 @@ func (h *StationHandler) convertToResponse(st *station.Station) StationResponse
 			EnergyDeliveryRate:         config.Simulation.EnergyDeliveryRate,
 			RandomizeMeterValues:       config.Simulation.RandomizeMeterValues,
 			MeterValueVariance:         config.Simulation.MeterValueVariance,
+			Firmware:                   FirmwareSimulationResponse(config.Simulation.Firmware),
+			EV:                         EVSimulationResponse(config.Simulation.EV),
 		},
 		RuntimeState: &RuntimeStateResponse{
 			State:            string(runtimeState.State),
@@ -466,7 +597,11 @@ func (h *StationHandler) convertToResponse(st *station.Station) StationResponse
 			LastHeartbeat:    runtimeState.LastHeartbeat,
 			LastError:        runtimeState.LastError,
 			ConnectedAt:      runtimeState.ConnectedAt,
+			NetworkSlot:      runtimeState.NetworkSlot,
 			TransactionID:    runtimeState.TransactionID,
+			QueuedMessages:   runtimeState.QueuedMessages,
+			DisplayedMessage: displayedMessage,
+			RunningCost:      runningCost,
 		},
 		CreatedAt: config.CreatedAt,
 		UpdatedAt: config.UpdatedAt,
@@ -478,10 +613,12 @@ func (h *StationHandler) convertToConfig(req *CreateStationRequest) station.Conf
 	connectors := make([]station.ConnectorConfig, len(req.Connectors))
 	for i, c := range req.Connectors {
 		connectors[i] = station.ConnectorConfig{
-			ID:       c.ID,
-			Type:     c.Type,
-			MaxPower: c.MaxPower,
-			Status:   "Available", // Default status for new connectors
+			ID:            c.ID,
+			Type:          c.Type,
+			MaxPower:      c.MaxPower,
+			Status:        "Available", // Default status for new connectors
+			PlugAndCharge: c.PlugAndCharge,
+			EMAID:         c.EMAID,
 		}
 	}
 
@@ -514,8 +651,9 @@ func (h *StationHandler) convertToConfig(req *CreateStationRequest) station.Conf
 			Measurands:          req.MeterValuesConfig.Measurands,
 			AlignedDataInterval: req.MeterValuesConfig.AlignedDataInterval,
 		},
-		CSMSURL:  req.CSMSURL,
-		CSMSAuth: csmsAuth,
+		CSMSURL:         req.CSMSURL,
+		CSMSAuth:        csmsAuth,
+		SecurityProfile: req.SecurityProfile,
 		Simulation: station.SimulationConfig{
 			BootDelay:                  req.Simulation.BootDelay,
 			HeartbeatInterval:          req.Simulation.HeartbeatInterval,
@@ -524,6 +662,8 @@ func (h *StationHandler) convertToConfig(req *CreateStationRequest) station.Conf
 			EnergyDeliveryRate:         req.Simulation.EnergyDeliveryRate,
 			RandomizeMeterValues:       req.Simulation.RandomizeMeterValues,
 			MeterValueVariance:         req.Simulation.MeterValueVariance,
+			Firmware:                   station.FirmwareSimulationConfig(req.Simulation.Firmware),
+			EV:                         station.EVSimulationConfig(req.Simulation.EV),
 		},
 		Tags: req.Tags,
 	}
@@ -551,6 +691,9 @@ func (h *StationHandler) validateCreateRequest(req *CreateStationRequest) error
 	if len(req.Connectors) == 0 {
 		return fmt.Errorf("at least one connector is required")
 	}
+	if req.SecurityProfile < 0 || req.SecurityProfile > 3 {
+		return fmt.Errorf("securityProfile must be between 0 and 3")
+	}
 
 	// Validate connectors
 	for i, c := range req.Connectors {
@@ -613,6 +756,126 @@ func (h *StationHandler) GetConnectors(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetDeviceModel handles GET /api/stations/:id/device-model and returns the device model profile
+func (h *StationHandler) GetDeviceModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	stationID := h.extractStationIDFromAction(r.URL.Path, "/device-model")
+	if stationID == "" {
+		h.sendError(w, http.StatusBadRequest, "Station ID is required")
+		return
+	}
+
+	profile, err := h.manager.ExportDeviceModelProfile(stationID)
+	if err != nil {
+		h.sendError(w, http.StatusNotFound, fmt.Sprintf("Failed to export device model: %v", err))
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, profile)
+}
+
+// ImportDeviceModel handles PUT /api/stations/:id/device-model and applies a device model profile
+func (h *StationHandler) ImportDeviceModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	stationID := h.extractStationIDFromAction(r.URL.Path, "/device-model")
+	if stationID == "" {
+		h.sendError(w, http.StatusBadRequest, "Station ID is required")
+		return
+	}
+
+	var profile v201.DeviceModelProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	if _, err := h.manager.GetStation(stationID); err != nil {
+		h.sendError(w, http.StatusNotFound, fmt.Sprintf("Station not found: %s", stationID))
+		return
+	}
+
+	if err := h.manager.ImportDeviceModelProfile(r.Context(), stationID, &profile); err != nil {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to import device model: %v", err))
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"stationId":  stationID,
+		"components": len(profile.Components),
+	})
+}
+
+// ExternalConstraintsRequest represents a request to set the external constraints of an EVSE
+type ExternalConstraintsRequest struct {
+	EvseID          int                  `json:"evseId"`
+	ChargingProfile v201.ChargingProfile `json:"chargingProfile"`
+}
+
+// SetExternalConstraints handles PUT /api/stations/:id/external-constraints and
+// DELETE /api/stations/:id/external-constraints (optionally ?evseId=N)
+func (h *StationHandler) SetExternalConstraints(w http.ResponseWriter, r *http.Request) {
+	stationID := h.extractStationIDFromAction(r.URL.Path, "/external-constraints")
+	if stationID == "" {
+		h.sendError(w, http.StatusBadRequest, "Station ID is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req ExternalConstraintsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+
+		if err := h.manager.SetExternalConstraints(stationID, req.EvseID, req.ChargingProfile); err != nil {
+			h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to set external constraints: %v", err))
+			return
+		}
+
+		h.sendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"stationId": stationID,
+			"evseId":    req.EvseID,
+		})
+
+	case http.MethodDelete:
+		var evseID *int
+		if value := r.URL.Query().Get("evseId"); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				h.sendError(w, http.StatusBadRequest, "Invalid evseId")
+				return
+			}
+			evseID = &id
+		}
+
+		removed, err := h.manager.ClearExternalConstraints(stationID, evseID)
+		if err != nil {
+			h.sendError(w, http.StatusNotFound, fmt.Sprintf("Failed to clear external constraints: %v", err))
+			return
+		}
+
+		h.sendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"stationId": stationID,
+			"removed":   removed,
+		})
+
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 // StartCharging starts a charging session on a connector
 func (h *StationHandler) StartCharging(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -633,6 +896,7 @@ func (h *StationHandler) StartCharging(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ConnectorID int    `json:"connectorId"`
 		IDTag       string `json:"idTag"`
+		IDTokenType string `json:"idTokenType,omitempty"` // OCPP 2.0.1 idToken type, ISO14443 by default
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -645,13 +909,23 @@ func (h *StationHandler) StartCharging(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.IDTag == "" {
+	// Plug & Charge EVs authorize with the eMAID of their contract certificate
+	if req.IDTag == "" && !h.manager.IsPlugAndCharge(stationID, req.ConnectorID) {
 		h.sendError(w, http.StatusBadRequest, "ID tag is required")
 		return
 	}
 
+	idToken := v201.IdToken{IdToken: req.IDTag, Type: v201.IdTokenTypeISO14443}
+	if req.IDTokenType != "" {
+		idToken.Type = v201.IdTokenType(req.IDTokenType)
+		if !idToken.Type.Valid() {
+			h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid idToken type: %s", req.IDTokenType))
+			return
+		}
+	}
+
 	// Start charging
-	if err := h.manager.StartCharging(r.Context(), stationID, req.ConnectorID, req.IDTag); err != nil {
+	if err := h.manager.StartChargingWithIdToken(r.Context(), stationID, req.ConnectorID, idToken); err != nil {
 		errMsg := err.Error()
 		// Determine appropriate status code based on error type
 		statusCode := http.StatusInternalServerError
@@ -754,7 +1028,8 @@ func (h *StationHandler) SendCustomMessage(w http.ResponseWriter, r *http.Reques
 
 	// Read raw JSON body
 	var req struct {
-		Message json.RawMessage `json:"message"`
+		Message       json.RawMessage `json:"message"`
+		AwaitResponse bool            `json:"awaitResponse"` // Wait for the CSMS response to a Call
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -767,6 +1042,11 @@ func (h *StationHandler) SendCustomMessage(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if req.AwaitResponse {
+		h.sendCustomCall(w, r, stationID, req.Message)
+		return
+	}
+
 	// Send custom message
 	if err := h.manager.SendCustomMessage(r.Context(), stationID, req.Message); err != nil {
 		h.sendError(w, http.StatusInternalServerError, err.Error())
@@ -779,3 +1059,52 @@ func (h *StationHandler) SendCustomMessage(w http.ResponseWriter, r *http.Reques
 		"stationId": stationID,
 	})
 }
+
+// sendCustomCall sends a custom Call and responds with the CSMS response to it
+func (h *StationHandler) sendCustomCall(w http.ResponseWriter, r *http.Request, stationID string, message json.RawMessage) {
+	msg, err := ocpp.ParseMessage(message)
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid OCPP message: %v", err))
+		return
+	}
+
+	call, ok := msg.(*ocpp.Call)
+	if !ok {
+		h.sendError(w, http.StatusBadRequest, "Only Call messages have a response to await")
+		return
+	}
+
+	result, err := h.manager.SendCall(r.Context(), stationID, call)
+
+	var callError *ocpp.CallError
+	switch {
+	case err == nil:
+		h.sendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"stationId": stationID,
+			"messageId": call.UniqueID,
+			"action":    call.Action,
+			"response":  result.Payload,
+		})
+	case errors.As(err, &callError):
+		h.sendJSON(w, http.StatusBadGateway, map[string]interface{}{
+			"success":   false,
+			"stationId": stationID,
+			"messageId": call.UniqueID,
+			"action":    call.Action,
+			"callError": map[string]interface{}{
+				"errorCode":        callError.ErrorCode,
+				"errorDescription": callError.ErrorDesc,
+				"errorDetails":     callError.ErrorDetails,
+			},
+		})
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		h.sendError(w, http.StatusGatewayTimeout, err.Error())
+	case strings.Contains(err.Error(), "not found"):
+		h.sendError(w, http.StatusNotFound, err.Error())
+	case strings.Contains(err.Error(), "not connected"):
+		h.sendError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		h.sendError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -73,11 +73,13 @@ type MongoDBConfig struct {
 
 // MongoDBCollectionsConfig holds collection names
 type MongoDBCollectionsConfig struct {
-	Messages     string `yaml:"messages" env-default:"messages"`
-	Transactions string `yaml:"transactions" env-default:"transactions"`
-	Stations     string `yaml:"stations" env-default:"stations"`
-	Sessions     string `yaml:"sessions" env-default:"sessions"`
-	MeterValues  string `yaml:"meter_values" env-default:"meter_values"`
+	Messages      string `yaml:"messages" env-default:"messages"`
+	Transactions  string `yaml:"transactions" env-default:"transactions"`
+	Stations      string `yaml:"stations" env-default:"stations"`
+	Sessions      string `yaml:"sessions" env-default:"sessions"`
+	MeterValues   string `yaml:"meter_values" env-default:"meter_values"`
+	Authorization string `yaml:"authorization" env-default:"authorization"`
+	MessageQueue  string `yaml:"message_queue" env-default:"message_queue"`
 }
 
 // MongoDBTimeSeriesConfig holds time-series configuration
@@ -90,6 +92,7 @@ type MongoDBTimeSeriesConfig struct {
 type CSMSConfig struct {
 	DefaultURL           string        `yaml:"default_url" env:"CSMS_DEFAULT_URL" env-default:"ws://localhost:9000"`
 	ConnectionTimeout    time.Duration `yaml:"connection_timeout" env:"CSMS_CONNECTION_TIMEOUT" env-default:"30s"`
+	CallTimeout          time.Duration `yaml:"call_timeout" env:"CSMS_CALL_TIMEOUT" env-default:"30s"` // Time a Call waits for the CSMS response
 	HeartbeatInterval    time.Duration `yaml:"heartbeat_interval" env:"CSMS_HEARTBEAT_INTERVAL" env-default:"60s"`
 	MaxReconnectAttempts int           `yaml:"max_reconnect_attempts" env:"CSMS_MAX_RECONNECT_ATTEMPTS" env-default:"5"`
 	ReconnectBackoff     time.Duration `yaml:"reconnect_backoff" env:"CSMS_RECONNECT_BACKOFF" env-default:"10s"`
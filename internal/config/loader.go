@@ -80,6 +80,12 @@ func validate(cfg *Config) error {
 	if cfg.MongoDB.Collections.MeterValues == "" {
 		return fmt.Errorf("mongodb.collections.meter_values is required")
 	}
+	if cfg.MongoDB.Collections.Authorization == "" {
+		return fmt.Errorf("mongodb.collections.authorization is required")
+	}
+	if cfg.MongoDB.Collections.MessageQueue == "" {
+		return fmt.Errorf("mongodb.collections.message_queue is required")
+	}
 
 	// Validate logging config
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
@@ -1,8 +1,11 @@
 package connection
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/ruslanhut/ocpp-emu/internal/config"
 )
@@ -35,20 +38,63 @@ func NewManager(cfg *config.CSMSConfig, logger *slog.Logger) *Manager {
 
 // ConnectStation establishes a connection for a station
 func (m *Manager) ConnectStation(stationID, url, protocolVersion string, tlsConfig *TLSConfig, auth *AuthConfig) error {
+	return m.ConnectStationWithFailover(stationID, protocolVersion, []Endpoint{{URL: url, TLS: tlsConfig, Auth: auth}}, 0)
+}
+
+// ConnectStationWithFailover establishes a connection for a station to the first of the endpoints,
+// in order of priority, that accepts it. Once connected, the station fails over to the next
+// endpoint after having been offline for offlineThreshold.
+func (m *Manager) ConnectStationWithFailover(stationID, protocolVersion string, endpoints []Endpoint, offlineThreshold time.Duration) error {
 	// Check if already connected
 	if m.pool.Has(stationID) {
 		return fmt.Errorf("station %s is already connected", stationID)
 	}
 
-	// Create connection configuration
+	if len(endpoints) == 0 {
+		return fmt.Errorf("no endpoint to connect station %s to", stationID)
+	}
+
+	configs := make([]ConnectionConfig, len(endpoints))
+	for i, endpoint := range endpoints {
+		configs[i] = m.connectionConfig(stationID, protocolVersion, endpoint)
+		configs[i].OfflineThreshold = offlineThreshold
+	}
+
+	// Create WebSocket client
+	client := NewFailoverWebSocketClient(configs, m.logger)
+
+	// Add to pool
+	if err := m.pool.Add(stationID, client); err != nil {
+		return fmt.Errorf("failed to add connection to pool: %w", err)
+	}
+
+	// Connect, falling back to the following endpoints if the preferred one cannot be reached
+	err := client.Connect()
+	for i := 1; err != nil && i < len(configs) && client.failover(); i++ {
+		err = client.Connect()
+	}
+	if err != nil {
+		m.pool.Remove(stationID)
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	return nil
+}
+
+// connectionConfig creates the connection configuration of a station endpoint
+func (m *Manager) connectionConfig(stationID, protocolVersion string, endpoint Endpoint) ConnectionConfig {
+	tlsConfig := endpoint.TLS
+	auth := endpoint.Auth
+
 	connConfig := ConnectionConfig{
-		URL:                  url,
+		URL:                  endpoint.URL,
 		StationID:            stationID,
 		ProtocolVersion:      protocolVersion,
 		ConnectionTimeout:    m.config.ConnectionTimeout,
 		MaxReconnectAttempts: m.config.MaxReconnectAttempts,
 		ReconnectBackoff:     m.config.ReconnectBackoff,
 		ReconnectMaxBackoff:  60 * m.config.ReconnectBackoff, // Max 60x backoff
+		Slot:                 endpoint.Slot,
 	}
 
 	// Apply TLS configuration
@@ -58,6 +104,17 @@ func (m *Manager) ConnectStation(stationID, url, protocolVersion string, tlsConf
 		connConfig.TLSClientCert = tlsConfig.ClientCert
 		connConfig.TLSClientKey = tlsConfig.ClientKey
 		connConfig.TLSSkipVerify = tlsConfig.InsecureSkipVerify
+		connConfig.TLSRootCAs = tlsConfig.RootCAs
+		connConfig.TLSCertificates = tlsConfig.Certificates
+
+		// Fall back to the CSMS defaults for anything the station does not provide
+		if connConfig.TLSCACert == "" && connConfig.TLSRootCAs == nil {
+			connConfig.TLSCACert = m.config.TLS.CACert
+		}
+		if connConfig.TLSClientCert == "" && len(connConfig.TLSCertificates) == 0 {
+			connConfig.TLSClientCert = m.config.TLS.ClientCert
+			connConfig.TLSClientKey = m.config.TLS.ClientKey
+		}
 	} else if m.config.TLS.Enabled {
 		// Use default TLS config from CSMS settings
 		connConfig.TLSEnabled = m.config.TLS.Enabled
@@ -106,21 +163,7 @@ func (m *Manager) ConnectStation(stationID, url, protocolVersion string, tlsConf
 		}
 	}
 
-	// Create WebSocket client
-	client := NewWebSocketClient(connConfig, m.logger)
-
-	// Add to pool
-	if err := m.pool.Add(stationID, client); err != nil {
-		return fmt.Errorf("failed to add connection to pool: %w", err)
-	}
-
-	// Connect
-	if err := client.Connect(); err != nil {
-		m.pool.Remove(stationID)
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-
-	return nil
+	return connConfig
 }
 
 // DisconnectStation disconnects a station
@@ -128,6 +171,11 @@ func (m *Manager) DisconnectStation(stationID string) error {
 	return m.pool.Remove(stationID)
 }
 
+// AbortStation drops the connection of a station abruptly, discarding unsent messages
+func (m *Manager) AbortStation(stationID string) error {
+	return m.pool.Abort(stationID)
+}
+
 // SendMessage sends a message to a specific station
 func (m *Manager) SendMessage(stationID string, message []byte) error {
 	return m.pool.Send(stationID, message)
@@ -209,6 +257,14 @@ func (m *Manager) Shutdown() error {
 	return m.DisconnectAll()
 }
 
+// Endpoint holds the settings of one CSMS a station can connect to
+type Endpoint struct {
+	Slot int // Network configuration slot, 0 if not applicable
+	URL  string
+	TLS  *TLSConfig
+	Auth *AuthConfig
+}
+
 // TLSConfig holds TLS configuration for a connection
 type TLSConfig struct {
 	Enabled            bool
@@ -216,6 +272,10 @@ type TLSConfig struct {
 	ClientCert         string
 	ClientKey          string
 	InsecureSkipVerify bool
+
+	// In-memory alternatives to the certificate files
+	RootCAs      *x509.CertPool
+	Certificates []tls.Certificate
 }
 
 // AuthConfig holds authentication configuration
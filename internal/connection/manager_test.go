@@ -2,10 +2,15 @@ package connection
 
 import (
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/ruslanhut/ocpp-emu/internal/config"
 )
 
@@ -209,3 +214,110 @@ func TestExplicitDisconnectNoReconnect(t *testing.T) {
 		t.Errorf("Expected 0 reconnect attempts after explicit disconnect, got %d", stats.ReconnectAttempts)
 	}
 }
+
+// testCSMS is a WebSocket server that accepts connections until it is taken down
+type testCSMS struct {
+	server *httptest.Server
+	down   atomic.Bool
+	conns  chan *websocket.Conn
+}
+
+// newTestCSMS starts a test CSMS
+func newTestCSMS(t *testing.T) *testCSMS {
+	t.Helper()
+
+	csms := &testCSMS{conns: make(chan *websocket.Conn, 10)}
+	upgrader := websocket.Upgrader{Subprotocols: []string{"ocpp2.0.1"}}
+	csms.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if csms.down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		csms.conns <- conn
+	}))
+	t.Cleanup(csms.server.Close)
+	return csms
+}
+
+// url returns the WebSocket URL of the test CSMS
+func (c *testCSMS) url() string {
+	return "ws" + strings.TrimPrefix(c.server.URL, "http")
+}
+
+// takeDown rejects new connections and drops the open one
+func (c *testCSMS) takeDown(t *testing.T) {
+	t.Helper()
+
+	c.down.Store(true)
+	select {
+	case conn := <-c.conns:
+		conn.Close()
+	case <-time.After(time.Second):
+		t.Fatal("Expected an open connection")
+	}
+}
+
+// waitForSlot waits until the station is connected through the given slot
+func waitForSlot(t *testing.T, manager *Manager, stationID string, slot int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		stats, err := manager.GetConnectionStats(stationID)
+		if err == nil && stats.State == StateConnected && stats.Slot == slot {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats, _ := manager.GetConnectionStats(stationID)
+	t.Fatalf("Expected station to connect through slot %d, got slot %d in state %s", slot, stats.Slot, stats.State)
+}
+
+// TestConnectStationWithFailover tests failing over between the network configuration slots
+func TestConnectStationWithFailover(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	cfg := &config.CSMSConfig{
+		ConnectionTimeout:    time.Second,
+		MaxReconnectAttempts: 100,
+		ReconnectBackoff:     10 * time.Millisecond,
+	}
+	manager := NewManager(cfg, logger)
+	defer manager.Shutdown()
+
+	primary := newTestCSMS(t)
+	secondary := newTestCSMS(t)
+
+	// An unreachable preferred slot falls back to the next one right away
+	primary.down.Store(true)
+	endpoints := []Endpoint{
+		{Slot: 1, URL: primary.url()},
+		{Slot: 2, URL: secondary.url()},
+	}
+	if err := manager.ConnectStationWithFailover("TEST021", "2.0.1", endpoints, 100*time.Millisecond); err != nil {
+		t.Fatalf("Expected connection through the second slot, got %v", err)
+	}
+	waitForSlot(t, manager, "TEST021", 2)
+
+	// Losing the CSMS fails over to the next slot after the offline threshold
+	primary.down.Store(false)
+	started := time.Now()
+	secondary.takeDown(t)
+	waitForSlot(t, manager, "TEST021", 1)
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected failover after the offline threshold, took %v", elapsed)
+	}
+
+	// Every slot unreachable fails the connection
+	manager.DisconnectStation("TEST021")
+	primary.down.Store(true)
+	if err := manager.ConnectStationWithFailover("TEST021", "2.0.1", endpoints, time.Second); err == nil {
+		t.Error("Expected connection to fail with every slot unreachable")
+	}
+	if manager.GetTotalCount() != 0 {
+		t.Errorf("Expected failed connection to be removed, got %d connections", manager.GetTotalCount())
+	}
+}
@@ -64,6 +64,29 @@ func (p *ConnectionPool) Remove(stationID string) error {
 	return nil
 }
 
+// Abort drops a connection without a close handshake and removes it from the pool
+func (p *ConnectionPool) Abort(stationID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, exists := p.connections[stationID]
+	if !exists {
+		return fmt.Errorf("connection for station %s not found", stationID)
+	}
+
+	if err := client.Abort(); err != nil {
+		p.logger.Warn("Error aborting client",
+			"station_id", stationID,
+			"error", err,
+		)
+	}
+
+	delete(p.connections, stationID)
+	p.logger.Info("Aborted connection", "station_id", stationID)
+
+	return nil
+}
+
 // Get retrieves a connection from the pool
 func (p *ConnectionPool) Get(stationID string) (*WebSocketClient, error) {
 	p.mu.RLock()
@@ -1,6 +1,8 @@
 package connection
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"time"
 )
 
@@ -34,6 +36,10 @@ type ConnectionConfig struct {
 	ReconnectBackoff     time.Duration
 	ReconnectMaxBackoff  time.Duration
 
+	// Failover settings
+	Slot             int           // Network configuration slot of these settings, 0 if not applicable
+	OfflineThreshold time.Duration // Time offline before failing over to the next endpoint, 0 disables it
+
 	// TLS settings
 	TLSEnabled    bool
 	TLSCACert     string
@@ -41,6 +47,10 @@ type ConnectionConfig struct {
 	TLSClientKey  string
 	TLSSkipVerify bool
 
+	// In-memory TLS material (e.g. from the station certificate store), taking precedence over the files above
+	TLSRootCAs      *x509.CertPool
+	TLSCertificates []tls.Certificate
+
 	// Authentication
 	BasicAuthUsername string
 	BasicAuthPassword string
@@ -57,6 +67,8 @@ type ConnectionConfig struct {
 type ConnectionStats struct {
 	StationID         string
 	State             ConnectionState
+	URL               string
+	Slot              int
 	ConnectedAt       *time.Time
 	DisconnectedAt    *time.Time
 	LastMessageAt     *time.Time
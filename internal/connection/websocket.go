@@ -29,6 +29,13 @@ type WebSocketClient struct {
 	disconnectedAt *time.Time
 	lastMessageAt  *time.Time
 
+	// Failover between the endpoints, in order of priority. The current endpoint, its config
+	// and the failover state are guarded by stateMu.
+	endpoints    []ConnectionConfig
+	endpoint     int
+	failovers    int
+	offlineSince *time.Time
+
 	// Statistics
 	messagesSent     int64
 	messagesReceived int64
@@ -37,11 +44,12 @@ type WebSocketClient struct {
 	statsMu          sync.RWMutex
 
 	// Control channels
-	ctx       context.Context
-	cancel    context.CancelFunc
-	sendQueue chan Message
-	closeChan chan struct{}
-	closeOnce sync.Once
+	ctx        context.Context
+	cancel     context.CancelFunc
+	connCancel context.CancelFunc // Stops the pumps of the current connection
+	sendQueue  chan Message
+	closeChan  chan struct{}
+	closeOnce  sync.Once
 
 	// Error tracking
 	lastError   string
@@ -50,11 +58,38 @@ type WebSocketClient struct {
 
 // NewWebSocketClient creates a new WebSocket client
 func NewWebSocketClient(config ConnectionConfig, logger *slog.Logger) *WebSocketClient {
+	return NewFailoverWebSocketClient([]ConnectionConfig{config}, logger)
+}
+
+// NewFailoverWebSocketClient creates a WebSocket client for one or more endpoints in order of
+// priority, e.g. the network configuration slots of a station. The client connects to the first
+// endpoint and fails over to the next one when the CSMS cannot be reached.
+func NewFailoverWebSocketClient(endpoints []ConnectionConfig, logger *slog.Logger) *WebSocketClient {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
-	// Set defaults
+	endpoints = append([]ConnectionConfig(nil), endpoints...)
+	for i := range endpoints {
+		applyDefaults(&endpoints[i])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &WebSocketClient{
+		config:    endpoints[0],
+		logger:    logger,
+		state:     StateDisconnected,
+		endpoints: endpoints,
+		ctx:       ctx,
+		cancel:    cancel,
+		sendQueue: make(chan Message, 100),
+		closeChan: make(chan struct{}),
+	}
+}
+
+// applyDefaults sets the defaults of the connection settings left empty
+func applyDefaults(config *ConnectionConfig) {
 	if config.ConnectionTimeout == 0 {
 		config.ConnectionTimeout = 30 * time.Second
 	}
@@ -84,48 +119,38 @@ func NewWebSocketClient(config ConnectionConfig, logger *slog.Logger) *WebSocket
 	if config.Subprotocol == "" {
 		config.Subprotocol = getSubprotocol(config.ProtocolVersion)
 	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-
-	return &WebSocketClient{
-		config:    config,
-		logger:    logger,
-		state:     StateDisconnected,
-		ctx:       ctx,
-		cancel:    cancel,
-		sendQueue: make(chan Message, 100),
-		closeChan: make(chan struct{}),
-	}
 }
 
 // Connect establishes a WebSocket connection to the CSMS
 func (c *WebSocketClient) Connect() error {
 	c.setState(StateConnecting)
+	config := c.currentConfig()
 
 	c.logger.Info("Connecting to CSMS",
-		"station_id", c.config.StationID,
-		"url", c.config.URL,
-		"protocol", c.config.ProtocolVersion,
-		"subprotocol", c.config.Subprotocol,
+		"station_id", config.StationID,
+		"url", config.URL,
+		"slot", config.Slot,
+		"protocol", config.ProtocolVersion,
+		"subprotocol", config.Subprotocol,
 	)
 
 	// Create HTTP headers
 	headers := http.Header{}
-	if c.config.BasicAuthUsername != "" {
-		headers.Set("Authorization", basicAuth(c.config.BasicAuthUsername, c.config.BasicAuthPassword))
-	} else if c.config.BearerToken != "" {
-		headers.Set("Authorization", "Bearer "+c.config.BearerToken)
+	if config.BasicAuthUsername != "" {
+		headers.Set("Authorization", basicAuth(config.BasicAuthUsername, config.BasicAuthPassword))
+	} else if config.BearerToken != "" {
+		headers.Set("Authorization", "Bearer "+config.BearerToken)
 	}
 
 	// Create WebSocket dialer
 	dialer := websocket.Dialer{
-		HandshakeTimeout: c.config.ConnectionTimeout,
-		Subprotocols:     []string{c.config.Subprotocol},
+		HandshakeTimeout: config.ConnectionTimeout,
+		Subprotocols:     []string{config.Subprotocol},
 	}
 
 	// Configure TLS if enabled
-	if c.config.TLSEnabled {
-		tlsConfig, err := c.createTLSConfig()
+	if config.TLSEnabled {
+		tlsConfig, err := createTLSConfig(config)
 		if err != nil {
 			c.setError(fmt.Errorf("failed to create TLS config: %w", err))
 			c.setState(StateError)
@@ -135,7 +160,7 @@ func (c *WebSocketClient) Connect() error {
 	}
 
 	// Establish connection
-	conn, resp, err := dialer.Dial(c.config.URL, headers)
+	conn, resp, err := dialer.Dial(config.URL, headers)
 	if err != nil {
 		c.setError(fmt.Errorf("failed to dial: %w", err))
 		c.setState(StateError)
@@ -145,44 +170,73 @@ func (c *WebSocketClient) Connect() error {
 
 	c.conn = conn
 	now := time.Now()
+	c.stateMu.Lock()
 	c.connectedAt = &now
-	c.setState(StateConnected)
+	c.state = StateConnected
 	c.reconnectCount = 0
+	c.failovers = 0
+	c.offlineSince = nil
+	c.stateMu.Unlock()
 
 	c.logger.Info("Connected to CSMS",
-		"station_id", c.config.StationID,
+		"station_id", config.StationID,
 		"subprotocol", conn.Subprotocol(),
 	)
 
 	// Trigger connected callback
-	if c.config.OnConnected != nil {
-		c.config.OnConnected()
+	if config.OnConnected != nil {
+		config.OnConnected()
 	}
 
-	// Start read/write goroutines
-	go c.readPump()
-	go c.writePump()
-	go c.pingPump()
+	// Start read/write goroutines, they stop with the connection
+	connCtx, connCancel := context.WithCancel(c.ctx)
+	c.stateMu.Lock()
+	c.connCancel = connCancel
+	c.stateMu.Unlock()
+
+	go c.readPump(connCtx, conn, config)
+	go c.writePump(connCtx, conn, config)
+	go c.pingPump(connCtx, conn, config)
 
 	return nil
 }
 
-// Disconnect closes the WebSocket connection
+// Disconnect closes the connection gracefully, flushing queued messages first
 func (c *WebSocketClient) Disconnect() error {
+	c.close(true)
+	return nil
+}
+
+// Abort drops the connection without a close handshake, discarding queued messages
+func (c *WebSocketClient) Abort() error {
+	c.close(false)
+	return nil
+}
+
+// close shuts down the connection, either gracefully or abruptly
+func (c *WebSocketClient) close(graceful bool) {
 	c.closeOnce.Do(func() {
-		c.logger.Info("Disconnecting from CSMS", "station_id", c.config.StationID)
+		config := c.currentConfig()
+		if graceful {
+			c.logger.Info("Disconnecting from CSMS", "station_id", config.StationID)
+			c.flushSendQueue()
+		} else {
+			c.logger.Warn("Aborting connection to CSMS", "station_id", config.StationID)
+		}
 
 		c.cancel()
 		close(c.closeChan)
 
 		if c.conn != nil {
-			// Send close message
-			err := c.conn.WriteMessage(
-				websocket.CloseMessage,
-				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
-			)
-			if err != nil {
-				c.logger.Warn("Failed to send close message", "error", err)
+			if graceful {
+				// Send close message
+				err := c.conn.WriteMessage(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				)
+				if err != nil {
+					c.logger.Warn("Failed to send close message", "error", err)
+				}
 			}
 
 			// Close connection
@@ -191,14 +245,39 @@ func (c *WebSocketClient) Disconnect() error {
 			}
 		}
 
+		if dropped := c.dropSendQueue(); dropped > 0 {
+			c.logger.Warn("Dropped unsent messages", "station_id", config.StationID, "count", dropped)
+		}
+
 		now := time.Now()
+		c.stateMu.Lock()
 		c.disconnectedAt = &now
-		c.setState(StateClosed)
+		c.state = StateClosed
+		c.stateMu.Unlock()
 
-		c.logger.Info("Disconnected from CSMS", "station_id", c.config.StationID)
+		c.logger.Info("Disconnected from CSMS", "station_id", config.StationID)
 	})
+}
 
-	return nil
+// flushSendQueue waits until the queued messages have been written or the write timeout expires
+func (c *WebSocketClient) flushSendQueue() {
+	deadline := time.Now().Add(c.currentConfig().WriteTimeout)
+	for len(c.sendQueue) > 0 && c.GetState() == StateConnected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// dropSendQueue discards the queued messages and returns their number
+func (c *WebSocketClient) dropSendQueue() int {
+	dropped := 0
+	for {
+		select {
+		case <-c.sendQueue:
+			dropped++
+		default:
+			return dropped
+		}
+	}
 }
 
 // Send queues a message to be sent
@@ -218,28 +297,28 @@ func (c *WebSocketClient) Send(data []byte) error {
 }
 
 // readPump reads messages from the WebSocket connection
-func (c *WebSocketClient) readPump() {
+func (c *WebSocketClient) readPump(ctx context.Context, conn *websocket.Conn, config ConnectionConfig) {
 	defer func() {
 		c.handleDisconnect(fmt.Errorf("read pump stopped"))
 	}()
 
 	// Set read deadline
-	c.conn.SetReadDeadline(time.Now().Add(c.config.ReadTimeout))
+	conn.SetReadDeadline(time.Now().Add(config.ReadTimeout))
 
 	// Set pong handler
-	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(c.config.ReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(config.ReadTimeout))
 		return nil
 	})
 
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 		default:
 		}
 
-		messageType, message, err := c.conn.ReadMessage()
+		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
 				c.logger.Error("WebSocket read error", "error", err)
@@ -260,36 +339,36 @@ func (c *WebSocketClient) readPump() {
 		switch messageType {
 		case websocket.TextMessage:
 			c.logger.Debug("Received message",
-				"station_id", c.config.StationID,
+				"station_id", config.StationID,
 				"size", len(message),
 			)
 
-			if c.config.OnMessage != nil {
-				c.config.OnMessage(message)
+			if config.OnMessage != nil {
+				config.OnMessage(message)
 			}
 
 		case websocket.BinaryMessage:
-			c.logger.Warn("Received unexpected binary message", "station_id", c.config.StationID)
+			c.logger.Warn("Received unexpected binary message", "station_id", config.StationID)
 
 		case websocket.CloseMessage:
-			c.logger.Info("Received close message", "station_id", c.config.StationID)
+			c.logger.Info("Received close message", "station_id", config.StationID)
 			c.handleDisconnect(nil)
 			return
 		}
 
 		// Reset read deadline
-		c.conn.SetReadDeadline(time.Now().Add(c.config.ReadTimeout))
+		conn.SetReadDeadline(time.Now().Add(config.ReadTimeout))
 	}
 }
 
 // writePump writes messages from the send queue to the WebSocket connection
-func (c *WebSocketClient) writePump() {
-	ticker := time.NewTicker(c.config.PingInterval)
+func (c *WebSocketClient) writePump(ctx context.Context, conn *websocket.Conn, config ConnectionConfig) {
+	ticker := time.NewTicker(config.PingInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 
 		case message, ok := <-c.sendQueue:
@@ -297,9 +376,9 @@ func (c *WebSocketClient) writePump() {
 				return
 			}
 
-			c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+			conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
 
-			err := c.conn.WriteMessage(int(message.Type), message.Data)
+			err := conn.WriteMessage(int(message.Type), message.Data)
 			if err != nil {
 				c.logger.Error("Failed to write message", "error", err)
 				c.handleDisconnect(err)
@@ -313,7 +392,7 @@ func (c *WebSocketClient) writePump() {
 			c.statsMu.Unlock()
 
 			c.logger.Debug("Sent message",
-				"station_id", c.config.StationID,
+				"station_id", config.StationID,
 				"size", len(message.Data),
 			)
 		}
@@ -321,18 +400,18 @@ func (c *WebSocketClient) writePump() {
 }
 
 // pingPump sends periodic ping messages
-func (c *WebSocketClient) pingPump() {
-	ticker := time.NewTicker(c.config.PingInterval)
+func (c *WebSocketClient) pingPump(ctx context.Context, conn *websocket.Conn, config ConnectionConfig) {
+	ticker := time.NewTicker(config.PingInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				c.logger.Warn("Failed to send ping", "error", err)
 				c.handleDisconnect(err)
 				return
@@ -343,34 +422,37 @@ func (c *WebSocketClient) pingPump() {
 
 // handleDisconnect handles connection disconnection and triggers reconnection if needed
 func (c *WebSocketClient) handleDisconnect(err error) {
+	// Only the first pump to notice the lost connection handles it
 	c.stateMu.Lock()
-	if c.state == StateClosed {
+	if c.state != StateConnected {
 		c.stateMu.Unlock()
 		return
 	}
-	c.stateMu.Unlock()
-
 	now := time.Now()
+	c.state = StateDisconnected
 	c.disconnectedAt = &now
-	c.setState(StateDisconnected)
+	c.offlineSince = &now
+	c.connCancel()
+	config := c.config
+	c.stateMu.Unlock()
 
 	if err != nil {
 		c.setError(err)
-		c.logger.Warn("Connection disconnected", "station_id", c.config.StationID, "error", err)
+		c.logger.Warn("Connection disconnected", "station_id", config.StationID, "error", err)
 	} else {
-		c.logger.Info("Connection disconnected", "station_id", c.config.StationID)
+		c.logger.Info("Connection disconnected", "station_id", config.StationID)
 	}
 
 	// Trigger disconnected callback
-	if c.config.OnDisconnected != nil {
-		c.config.OnDisconnected(err)
+	if config.OnDisconnected != nil {
+		config.OnDisconnected(err)
 	}
 
 	// Check if disconnection was intentional (context cancelled means explicit Disconnect() call)
 	select {
 	case <-c.ctx.Done():
 		// Context cancelled - this was an intentional disconnect, don't reconnect
-		c.logger.Info("Connection closed intentionally, not attempting reconnect", "station_id", c.config.StationID)
+		c.logger.Info("Connection closed intentionally, not attempting reconnect", "station_id", config.StationID)
 		c.setState(StateClosed)
 		return
 	default:
@@ -378,39 +460,110 @@ func (c *WebSocketClient) handleDisconnect(err error) {
 	}
 
 	// Attempt reconnection
-	if c.reconnectCount < c.config.MaxReconnectAttempts {
-		go c.reconnect()
-	} else {
-		c.logger.Error("Max reconnect attempts reached", "station_id", c.config.StationID)
-		c.setState(StateError)
-	}
+	go c.reconnect()
 }
 
-// reconnect attempts to reconnect with exponential backoff
+// reconnect attempts to reconnect with exponential backoff. Once the CSMS has been unreachable
+// for the offline threshold, or the reconnect attempts are used up, the client fails over to
+// the next endpoint. It gives up when no endpoint is left to try.
 func (c *WebSocketClient) reconnect() {
 	c.setState(StateReconnecting)
-	c.reconnectCount++
 
-	// Calculate backoff with exponential increase
-	backoff := c.config.ReconnectBackoff * time.Duration(1<<uint(c.reconnectCount-1))
-	if backoff > c.config.ReconnectMaxBackoff {
-		backoff = c.config.ReconnectMaxBackoff
-	}
+	for {
+		c.stateMu.RLock()
+		exhausted := c.reconnectCount >= c.config.MaxReconnectAttempts
+		c.stateMu.RUnlock()
 
-	c.logger.Info("Attempting to reconnect",
-		"station_id", c.config.StationID,
-		"attempt", c.reconnectCount,
-		"backoff", backoff,
-	)
+		if exhausted || c.offlineThresholdExceeded() {
+			if !c.failover() && exhausted {
+				break
+			}
+		}
+
+		// Snapshot the endpoint under the lock, a failover may just have replaced it
+		c.stateMu.Lock()
+		c.reconnectCount++
+		attempt := c.reconnectCount
+		config := c.config
+		c.stateMu.Unlock()
+
+		// Calculate backoff with exponential increase
+		backoff := config.ReconnectBackoff * time.Duration(1<<uint(attempt-1))
+		if backoff > config.ReconnectMaxBackoff {
+			backoff = config.ReconnectMaxBackoff
+		}
+
+		c.logger.Info("Attempting to reconnect",
+			"station_id", config.StationID,
+			"attempt", attempt,
+			"backoff", backoff,
+		)
 
-	time.Sleep(backoff)
+		select {
+		case <-time.After(backoff):
+		case <-c.ctx.Done():
+			return
+		}
+
+		err := c.Connect()
+		if err == nil {
+			return
+		}
 
-	if err := c.Connect(); err != nil {
 		c.logger.Error("Reconnection failed",
-			"station_id", c.config.StationID,
+			"station_id", config.StationID,
 			"error", err,
 		)
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.setState(StateReconnecting)
 	}
+
+	c.logger.Error("Max reconnect attempts reached", "station_id", c.currentConfig().StationID)
+	c.setState(StateError)
+}
+
+// offlineThresholdExceeded reports whether the current endpoint has been unreachable for
+// longer than the offline threshold
+func (c *WebSocketClient) offlineThresholdExceeded() bool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.config.OfflineThreshold > 0 && c.offlineSince != nil && time.Since(*c.offlineSince) >= c.config.OfflineThreshold
+}
+
+// failover switches to the next endpoint, wrapping around to the first one. Returns false if
+// there is no other endpoint or every endpoint has been tried since the last connection.
+func (c *WebSocketClient) failover() bool {
+	c.stateMu.Lock()
+	if len(c.endpoints) < 2 || c.failovers >= len(c.endpoints) {
+		c.stateMu.Unlock()
+		return false
+	}
+
+	now := time.Now()
+	c.failovers++
+	c.endpoint = (c.endpoint + 1) % len(c.endpoints)
+	c.config = c.endpoints[c.endpoint]
+	c.reconnectCount = 0
+	c.offlineSince = &now
+	config := c.config
+	c.stateMu.Unlock()
+
+	c.logger.Warn("Failing over to next CSMS endpoint",
+		"station_id", config.StationID,
+		"slot", config.Slot,
+		"url", config.URL,
+	)
+	return true
+}
+
+// currentConfig returns a snapshot of the settings of the current endpoint, which a failover
+// may replace at any time
+func (c *WebSocketClient) currentConfig() ConnectionConfig {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.config
 }
 
 // GetState returns the current connection state
@@ -436,6 +589,8 @@ func (c *WebSocketClient) GetStats() ConnectionStats {
 	stats := ConnectionStats{
 		StationID:         c.config.StationID,
 		State:             c.state,
+		URL:               c.config.URL,
+		Slot:              c.config.Slot,
 		ConnectedAt:       c.connectedAt,
 		DisconnectedAt:    c.disconnectedAt,
 		LastMessageAt:     c.lastMessageAt,
@@ -459,22 +614,22 @@ func (c *WebSocketClient) setError(err error) {
 	c.lastErrorMu.Lock()
 	if err != nil {
 		c.lastError = err.Error()
-		if c.config.OnError != nil {
-			c.config.OnError(err)
+		if onError := c.currentConfig().OnError; onError != nil {
+			onError(err)
 		}
 	}
 	c.lastErrorMu.Unlock()
 }
 
-// createTLSConfig creates TLS configuration
-func (c *WebSocketClient) createTLSConfig() (*tls.Config, error) {
+// createTLSConfig creates the TLS configuration of an endpoint
+func createTLSConfig(config ConnectionConfig) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
-		InsecureSkipVerify: c.config.TLSSkipVerify,
+		InsecureSkipVerify: config.TLSSkipVerify,
 	}
 
 	// Load CA certificate
-	if c.config.TLSCACert != "" {
-		caCert, err := os.ReadFile(c.config.TLSCACert)
+	if config.TLSCACert != "" {
+		caCert, err := os.ReadFile(config.TLSCACert)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read CA cert: %w", err)
 		}
@@ -486,15 +641,25 @@ func (c *WebSocketClient) createTLSConfig() (*tls.Config, error) {
 		tlsConfig.RootCAs = caCertPool
 	}
 
+	// In-memory root certificates take precedence over the CA file
+	if config.TLSRootCAs != nil {
+		tlsConfig.RootCAs = config.TLSRootCAs
+	}
+
 	// Load client certificate
-	if c.config.TLSClientCert != "" && c.config.TLSClientKey != "" {
-		cert, err := tls.LoadX509KeyPair(c.config.TLSClientCert, c.config.TLSClientKey)
+	if config.TLSClientCert != "" && config.TLSClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSClientCert, config.TLSClientKey)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load client cert: %w", err)
 		}
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
+	// In-memory client certificates take precedence over the certificate files
+	if len(config.TLSCertificates) > 0 {
+		tlsConfig.Certificates = config.TLSCertificates
+	}
+
 	return tlsConfig, nil
 }
 
@@ -53,7 +53,7 @@ type LoggerConfig struct {
 type MessageEntry struct {
 	StationID       string
 	Direction       string // "sent" or "received"
-	MessageType     string // "Call", "CallResult", "CallError"
+	MessageType     string // "Call", "CallResult", "CallError", "Timeout"
 	Action          string
 	MessageID       string
 	ProtocolVersion string
@@ -277,8 +277,27 @@ func (ml *MessageLogger) LogMessage(
 	message interface{},
 	protocolVersion string,
 ) error {
-	entry := ml.createMessageEntry(stationID, direction, message, protocolVersion)
+	return ml.logEntry(ml.createMessageEntry(stationID, direction, message, protocolVersion))
+}
+
+// LogTimeout logs a Call sent to the CSMS that was not answered in time. The entry has the
+// action and message ID of the Call so it correlates with the Call in the message log.
+func (ml *MessageLogger) LogTimeout(stationID string, call *ocpp.Call, protocolVersion string) error {
+	return ml.logEntry(MessageEntry{
+		StationID:       stationID,
+		Direction:       "sent",
+		MessageType:     "Timeout",
+		Action:          call.Action,
+		MessageID:       call.UniqueID,
+		ProtocolVersion: protocolVersion,
+		Timestamp:       time.Now(),
+		ErrorCode:       "Timeout",
+		ErrorDesc:       "No response received from the CSMS in time",
+	})
+}
 
+// logEntry broadcasts a message entry and buffers it for MongoDB
+func (ml *MessageLogger) logEntry(entry MessageEntry) error {
 	// Broadcast to WebSocket clients in real-time (if broadcaster is set)
 	if ml.broadcaster != nil {
 		ml.broadcaster.BroadcastMessageEntry(entry)
@@ -294,8 +313,8 @@ func (ml *MessageLogger) LogMessage(
 	default:
 		// Buffer full, drop message
 		ml.logger.Warn("Message buffer full, dropping message",
-			"stationId", stationID,
-			"direction", direction,
+			"stationId", entry.StationID,
+			"direction", entry.Direction,
 		)
 		ml.incrementDropped(1)
 		return fmt.Errorf("message buffer full")
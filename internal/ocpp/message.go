@@ -240,6 +240,11 @@ func (cr *CallResult) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Error makes a CallError usable as the error returned for a failed Call
+func (ce *CallError) Error() string {
+	return fmt.Sprintf("%s: %s", ce.ErrorCode, ce.ErrorDesc)
+}
+
 // MarshalJSON marshals a CallError message to JSON in OCPP format
 func (ce *CallError) MarshalJSON() ([]byte, error) {
 	arr := []interface{}{
@@ -0,0 +1,292 @@
+package ocpp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultCallTimeout is the time a Call waits for its CallResult or CallError
+const DefaultCallTimeout = 30 * time.Second
+
+var (
+	// ErrCallTimeout is returned when the peer does not answer a Call in time
+	ErrCallTimeout = errors.New("call timed out")
+
+	// ErrCallCancelled is returned for calls that were outstanding or queued when the tracker was reset
+	ErrCallCancelled = errors.New("call cancelled")
+)
+
+// PendingCall is a Call sent through a RequestTracker whose response can be awaited
+type PendingCall struct {
+	Call *Call
+
+	timeout time.Duration
+	done    chan struct{}
+	result  *CallResult
+	err     error
+}
+
+// Done returns a channel that is closed once the call has been answered, failed or timed out
+func (p *PendingCall) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the call completes or the context is cancelled.
+// A CallError answer is returned as a *CallError error.
+func (p *PendingCall) Wait(ctx context.Context) (*CallResult, error) {
+	select {
+	case <-p.done:
+		return p.result, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// complete finishes the call, must be called exactly once
+func (p *PendingCall) complete(result *CallResult, err error) {
+	p.result = result
+	p.err = err
+	close(p.done)
+}
+
+// RequestTracker correlates the Calls of a station with their responses.
+// OCPP allows a single outstanding Call per direction, further Calls wait in a
+// send queue until the outstanding one has been answered or has timed out.
+type RequestTracker struct {
+	stationID string
+	send      func(data []byte) error
+	timeout   time.Duration
+	logger    *slog.Logger
+
+	mu      sync.Mutex
+	current *PendingCall
+	timer   *time.Timer
+	queue   []*PendingCall
+
+	// OnTimeout is called when a Call has not been answered in time
+	OnTimeout func(call *Call)
+}
+
+// NewRequestTracker creates a request tracker sending Calls with the given function
+func NewRequestTracker(stationID string, send func(data []byte) error, timeout time.Duration, logger *slog.Logger) *RequestTracker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if timeout <= 0 {
+		timeout = DefaultCallTimeout
+	}
+
+	return &RequestTracker{
+		stationID: stationID,
+		send:      send,
+		timeout:   timeout,
+		logger:    logger,
+	}
+}
+
+// SetTimeout sets the default timeout of Calls sent afterwards
+func (t *RequestTracker) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultCallTimeout
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timeout = timeout
+}
+
+// Send sends a Call with the default timeout, see SendWithTimeout
+func (t *RequestTracker) Send(call *Call) (*PendingCall, error) {
+	return t.SendWithTimeout(call, 0)
+}
+
+// SendWithTimeout sends a Call, or queues it while another Call is outstanding, and returns
+// the pending call to await the response. The timeout starts once the Call is on the wire.
+// An error is returned when the Call cannot be sent right away.
+func (t *RequestTracker) SendWithTimeout(call *Call, timeout time.Duration) (*PendingCall, error) {
+	if call == nil {
+		return nil, fmt.Errorf("call is nil")
+	}
+
+	t.mu.Lock()
+	if timeout <= 0 {
+		timeout = t.timeout
+	}
+	pending := &PendingCall{
+		Call:    call,
+		timeout: timeout,
+		done:    make(chan struct{}),
+	}
+
+	if t.current != nil {
+		t.queue = append(t.queue, pending)
+		depth := len(t.queue)
+		t.mu.Unlock()
+
+		t.logger.Debug("Call queued behind outstanding call",
+			"stationId", t.stationID,
+			"action", call.Action,
+			"messageId", call.UniqueID,
+			"queueDepth", depth,
+		)
+		return pending, nil
+	}
+
+	err := t.startLocked(pending)
+	t.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// HandleResult completes the outstanding Call answered by a CallResult.
+// It returns nil when the result does not belong to the outstanding Call.
+func (t *RequestTracker) HandleResult(result *CallResult) *PendingCall {
+	pending := t.finish(result.UniqueID)
+	if pending == nil {
+		return nil
+	}
+
+	pending.complete(result, nil)
+	return pending
+}
+
+// HandleError completes the outstanding Call answered by a CallError.
+// It returns nil when the error does not belong to the outstanding Call.
+func (t *RequestTracker) HandleError(callError *CallError) *PendingCall {
+	pending := t.finish(callError.UniqueID)
+	if pending == nil {
+		return nil
+	}
+
+	pending.complete(nil, callError)
+	return pending
+}
+
+// Reset fails the outstanding and all queued Calls, e.g. when the connection is lost
+func (t *RequestTracker) Reset(err error) {
+	if err == nil {
+		err = ErrCallCancelled
+	}
+
+	t.mu.Lock()
+	calls := t.queue
+	if t.current != nil {
+		calls = append([]*PendingCall{t.current}, calls...)
+	}
+	t.stopTimerLocked()
+	t.current = nil
+	t.queue = nil
+	t.mu.Unlock()
+
+	for _, pending := range calls {
+		pending.complete(nil, err)
+	}
+}
+
+// Pending returns the number of outstanding and queued Calls
+func (t *RequestTracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := len(t.queue)
+	if t.current != nil {
+		count++
+	}
+	return count
+}
+
+// finish detaches the outstanding Call with the given ID and sends the next queued Call
+func (t *RequestTracker) finish(uniqueID string) *PendingCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current == nil || t.current.Call.UniqueID != uniqueID {
+		return nil
+	}
+
+	pending := t.current
+	t.stopTimerLocked()
+	t.current = nil
+	t.sendNextLocked()
+
+	return pending
+}
+
+// startLocked sends a Call and arms its timeout. Must be called with t.mu held.
+func (t *RequestTracker) startLocked(pending *PendingCall) error {
+	data, err := pending.Call.ToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", pending.Call.Action, err)
+	}
+
+	if err := t.send(data); err != nil {
+		return err
+	}
+
+	t.current = pending
+	t.timer = time.AfterFunc(pending.timeout, func() {
+		t.expire(pending)
+	})
+	return nil
+}
+
+// sendNextLocked sends queued Calls until one is on the wire. Calls that cannot
+// be sent are failed. Must be called with t.mu held.
+func (t *RequestTracker) sendNextLocked() {
+	for t.current == nil && len(t.queue) > 0 {
+		pending := t.queue[0]
+		t.queue = t.queue[1:]
+
+		if err := t.startLocked(pending); err != nil {
+			t.logger.Error("Failed to send queued call",
+				"stationId", t.stationID,
+				"action", pending.Call.Action,
+				"messageId", pending.Call.UniqueID,
+				"error", err,
+			)
+			pending.complete(nil, err)
+		}
+	}
+}
+
+// stopTimerLocked stops the timeout of the outstanding Call. Must be called with t.mu held.
+func (t *RequestTracker) stopTimerLocked() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// expire fails a Call that has not been answered in time and moves on to the next one
+func (t *RequestTracker) expire(pending *PendingCall) {
+	t.mu.Lock()
+	if t.current != pending {
+		t.mu.Unlock()
+		return
+	}
+	t.timer = nil
+	t.current = nil
+	t.sendNextLocked()
+	onTimeout := t.OnTimeout
+	t.mu.Unlock()
+
+	t.logger.Warn("Call timed out",
+		"stationId", t.stationID,
+		"action", pending.Call.Action,
+		"messageId", pending.Call.UniqueID,
+		"timeout", pending.timeout,
+	)
+
+	pending.complete(nil, fmt.Errorf("%s %w after %s", pending.Call.Action, ErrCallTimeout, pending.timeout))
+
+	if onTimeout != nil {
+		onTimeout(pending.Call)
+	}
+}
@@ -0,0 +1,166 @@
+package ocpp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// wire records the Calls written by a request tracker
+type wire struct {
+	mu   sync.Mutex
+	sent []string
+	fail bool
+}
+
+func (w *wire) send(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fail {
+		return fmt.Errorf("not connected")
+	}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		return err
+	}
+	w.sent = append(w.sent, msg.(*Call).UniqueID)
+	return nil
+}
+
+func (w *wire) messageIDs() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.sent...)
+}
+
+func newTestCall(t *testing.T, action string) *Call {
+	t.Helper()
+
+	call, err := NewCall(action, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed to create Call: %v", err)
+	}
+	return call
+}
+
+// TestRequestTrackerSingleOutstandingCall tests that Calls are sent one at a time in order
+func TestRequestTrackerSingleOutstandingCall(t *testing.T) {
+	w := &wire{}
+	tracker := NewRequestTracker("CP001", w.send, time.Second, slog.Default())
+
+	first, err := tracker.Send(newTestCall(t, "Heartbeat"))
+	if err != nil {
+		t.Fatalf("Failed to send Call: %v", err)
+	}
+	second, err := tracker.Send(newTestCall(t, "StatusNotification"))
+	if err != nil {
+		t.Fatalf("Failed to queue Call: %v", err)
+	}
+
+	if sent := w.messageIDs(); len(sent) != 1 || sent[0] != first.Call.UniqueID {
+		t.Fatalf("Expected only the first Call on the wire, got %v", sent)
+	}
+	if tracker.Pending() != 2 {
+		t.Errorf("Expected 2 pending calls, got %d", tracker.Pending())
+	}
+
+	// A result for an unknown message is ignored
+	if tracker.HandleResult(&CallResult{UniqueID: "unknown"}) != nil {
+		t.Error("Expected unknown result to be ignored")
+	}
+
+	if tracker.HandleResult(&CallResult{UniqueID: first.Call.UniqueID, Payload: []byte(`{}`)}) != first {
+		t.Fatal("Expected result to complete the first Call")
+	}
+	if _, err := first.Wait(context.Background()); err != nil {
+		t.Errorf("Expected first Call to succeed, got %v", err)
+	}
+
+	if sent := w.messageIDs(); len(sent) != 2 || sent[1] != second.Call.UniqueID {
+		t.Fatalf("Expected the second Call to be sent after the first result, got %v", sent)
+	}
+
+	tracker.HandleError(&CallError{UniqueID: second.Call.UniqueID, ErrorCode: ErrorCodeNotImplemented, ErrorDesc: "unknown action"})
+
+	_, err = second.Wait(context.Background())
+	var callError *CallError
+	if !errors.As(err, &callError) || callError.ErrorCode != ErrorCodeNotImplemented {
+		t.Errorf("Expected CallError NotImplemented, got %v", err)
+	}
+	if tracker.Pending() != 0 {
+		t.Errorf("Expected no pending calls, got %d", tracker.Pending())
+	}
+}
+
+// TestRequestTrackerTimeout tests that an unanswered Call times out and releases the queue
+func TestRequestTrackerTimeout(t *testing.T) {
+	w := &wire{}
+	tracker := NewRequestTracker("CP001", w.send, time.Hour, slog.Default())
+
+	timedOut := make(chan string, 1)
+	tracker.OnTimeout = func(call *Call) {
+		timedOut <- call.Action
+	}
+
+	first, err := tracker.SendWithTimeout(newTestCall(t, "Authorize"), 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to send Call: %v", err)
+	}
+	second, _ := tracker.Send(newTestCall(t, "Heartbeat"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := first.Wait(ctx); !errors.Is(err, ErrCallTimeout) {
+		t.Fatalf("Expected ErrCallTimeout, got %v", err)
+	}
+
+	select {
+	case action := <-timedOut:
+		if action != "Authorize" {
+			t.Errorf("Expected timeout of Authorize, got %s", action)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected OnTimeout to be called")
+	}
+
+	if sent := w.messageIDs(); len(sent) != 2 || sent[1] != second.Call.UniqueID {
+		t.Errorf("Expected the queued Call to be sent after the timeout, got %v", sent)
+	}
+
+	// A late answer to the timed out Call is ignored
+	if tracker.HandleResult(&CallResult{UniqueID: first.Call.UniqueID}) != nil {
+		t.Error("Expected late result to be ignored")
+	}
+}
+
+// TestRequestTrackerReset tests that a reset fails outstanding and queued Calls
+func TestRequestTrackerReset(t *testing.T) {
+	w := &wire{}
+	tracker := NewRequestTracker("CP001", w.send, time.Second, slog.Default())
+
+	first, _ := tracker.Send(newTestCall(t, "Heartbeat"))
+	second, _ := tracker.Send(newTestCall(t, "Heartbeat"))
+
+	tracker.Reset(nil)
+
+	for _, pending := range []*PendingCall{first, second} {
+		if _, err := pending.Wait(context.Background()); !errors.Is(err, ErrCallCancelled) {
+			t.Errorf("Expected ErrCallCancelled, got %v", err)
+		}
+	}
+
+	w.fail = true
+	if _, err := tracker.Send(newTestCall(t, "Heartbeat")); err == nil {
+		t.Error("Expected send error when the Call cannot be written")
+	}
+	if tracker.Pending() != 0 {
+		t.Errorf("Expected no pending calls, got %d", tracker.Pending())
+	}
+}
@@ -24,6 +24,35 @@ type Handler struct {
 	OnClearCache             func(stationID string, req *ClearCacheRequest) (*ClearCacheResponse, error)
 	OnDataTransfer           func(stationID string, req *DataTransferRequest) (*DataTransferResponse, error)
 
+	// Smart Charging callbacks
+	OnSetChargingProfile   func(stationID string, req *SetChargingProfileRequest) (*SetChargingProfileResponse, error)
+	OnClearChargingProfile func(stationID string, req *ClearChargingProfileRequest) (*ClearChargingProfileResponse, error)
+	OnGetCompositeSchedule func(stationID string, req *GetCompositeScheduleRequest) (*GetCompositeScheduleResponse, error)
+
+	// Reservation callbacks
+	OnReserveNow        func(stationID string, req *ReserveNowRequest) (*ReserveNowResponse, error)
+	OnCancelReservation func(stationID string, req *CancelReservationRequest) (*CancelReservationResponse, error)
+
+	// Local Auth List Management callbacks
+	OnSendLocalList       func(stationID string, req *SendLocalListRequest) (*SendLocalListResponse, error)
+	OnGetLocalListVersion func(stationID string, req *GetLocalListVersionRequest) (*GetLocalListVersionResponse, error)
+
+	// Firmware Management callbacks
+	OnUpdateFirmware func(stationID string, req *UpdateFirmwareRequest) (*UpdateFirmwareResponse, error)
+	OnGetDiagnostics func(stationID string, req *GetDiagnosticsRequest) (*GetDiagnosticsResponse, error)
+
+	// Remote Trigger callbacks
+	OnTriggerMessage func(stationID string, req *TriggerMessageRequest) (*TriggerMessageResponse, error)
+
+	// Security extension callbacks
+	OnCertificateSigned          func(stationID string, req *CertificateSignedRequest) (*CertificateSignedResponse, error)
+	OnInstallCertificate         func(stationID string, req *InstallCertificateRequest) (*InstallCertificateResponse, error)
+	OnDeleteCertificate          func(stationID string, req *DeleteCertificateRequest) (*DeleteCertificateResponse, error)
+	OnGetInstalledCertificateIds func(stationID string, req *GetInstalledCertificateIdsRequest) (*GetInstalledCertificateIdsResponse, error)
+	OnExtendedTriggerMessage     func(stationID string, req *ExtendedTriggerMessageRequest) (*ExtendedTriggerMessageResponse, error)
+	OnSignedUpdateFirmware       func(stationID string, req *SignedUpdateFirmwareRequest) (*SignedUpdateFirmwareResponse, error)
+	OnGetLog                     func(stationID string, req *GetLogRequest) (*GetLogResponse, error)
+
 	// Callback for sending messages
 	SendMessage func(stationID string, data []byte) error
 }
@@ -62,6 +91,40 @@ func (h *Handler) HandleCall(stationID string, call *ocpp.Call) (interface{}, er
 		return h.handleClearCache(stationID, call)
 	case ActionDataTransfer:
 		return h.handleDataTransfer(stationID, call)
+	case ActionSetChargingProfile:
+		return h.handleSetChargingProfile(stationID, call)
+	case ActionClearChargingProfile:
+		return h.handleClearChargingProfile(stationID, call)
+	case ActionGetCompositeSchedule:
+		return h.handleGetCompositeSchedule(stationID, call)
+	case ActionReserveNow:
+		return h.handleReserveNow(stationID, call)
+	case ActionCancelReservation:
+		return h.handleCancelReservation(stationID, call)
+	case ActionSendLocalList:
+		return h.handleSendLocalList(stationID, call)
+	case ActionGetLocalListVersion:
+		return h.handleGetLocalListVersion(stationID, call)
+	case ActionUpdateFirmware:
+		return h.handleUpdateFirmware(stationID, call)
+	case ActionGetDiagnostics:
+		return h.handleGetDiagnostics(stationID, call)
+	case ActionTriggerMessage:
+		return h.handleTriggerMessage(stationID, call)
+	case ActionCertificateSigned:
+		return h.handleCertificateSigned(stationID, call)
+	case ActionInstallCertificate:
+		return h.handleInstallCertificate(stationID, call)
+	case ActionDeleteCertificate:
+		return h.handleDeleteCertificate(stationID, call)
+	case ActionGetInstalledCertificateIds:
+		return h.handleGetInstalledCertificateIds(stationID, call)
+	case ActionExtendedTriggerMessage:
+		return h.handleExtendedTriggerMessage(stationID, call)
+	case ActionSignedUpdateFirmware:
+		return h.handleSignedUpdateFirmware(stationID, call)
+	case ActionGetLog:
+		return h.handleGetLog(stationID, call)
 	default:
 		return nil, fmt.Errorf("action not implemented: %s", call.Action)
 	}
@@ -145,7 +208,7 @@ func (h *Handler) handleChangeConfiguration(stationID string, call *ocpp.Call) (
 	}
 
 	if h.OnChangeConfiguration == nil {
-		return &ChangeConfigurationResponse{Status: "NotSupported"}, nil
+		return &ChangeConfigurationResponse{Status: ConfigurationStatusNotSupported}, nil
 	}
 
 	return h.OnChangeConfiguration(stationID, &req)
@@ -193,6 +256,244 @@ func (h *Handler) handleDataTransfer(stationID string, call *ocpp.Call) (*DataTr
 	return h.OnDataTransfer(stationID, &req)
 }
 
+// handleSetChargingProfile handles SetChargingProfile request
+func (h *Handler) handleSetChargingProfile(stationID string, call *ocpp.Call) (*SetChargingProfileResponse, error) {
+	var req SetChargingProfileRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetChargingProfile request: %w", err)
+	}
+
+	if h.OnSetChargingProfile == nil {
+		return &SetChargingProfileResponse{Status: ChargingProfileStatusNotSupported}, nil
+	}
+
+	return h.OnSetChargingProfile(stationID, &req)
+}
+
+// handleClearChargingProfile handles ClearChargingProfile request
+func (h *Handler) handleClearChargingProfile(stationID string, call *ocpp.Call) (*ClearChargingProfileResponse, error) {
+	var req ClearChargingProfileRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ClearChargingProfile request: %w", err)
+	}
+
+	if h.OnClearChargingProfile == nil {
+		return &ClearChargingProfileResponse{Status: ClearChargingProfileStatusUnknown}, nil
+	}
+
+	return h.OnClearChargingProfile(stationID, &req)
+}
+
+// handleGetCompositeSchedule handles GetCompositeSchedule request
+func (h *Handler) handleGetCompositeSchedule(stationID string, call *ocpp.Call) (*GetCompositeScheduleResponse, error) {
+	var req GetCompositeScheduleRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetCompositeSchedule request: %w", err)
+	}
+
+	if h.OnGetCompositeSchedule == nil {
+		return &GetCompositeScheduleResponse{Status: GetCompositeScheduleStatusRejected}, nil
+	}
+
+	return h.OnGetCompositeSchedule(stationID, &req)
+}
+
+// handleReserveNow handles ReserveNow request
+func (h *Handler) handleReserveNow(stationID string, call *ocpp.Call) (*ReserveNowResponse, error) {
+	var req ReserveNowRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ReserveNow request: %w", err)
+	}
+
+	if h.OnReserveNow == nil {
+		return &ReserveNowResponse{Status: ReservationStatusRejected}, nil
+	}
+
+	return h.OnReserveNow(stationID, &req)
+}
+
+// handleCancelReservation handles CancelReservation request
+func (h *Handler) handleCancelReservation(stationID string, call *ocpp.Call) (*CancelReservationResponse, error) {
+	var req CancelReservationRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CancelReservation request: %w", err)
+	}
+
+	if h.OnCancelReservation == nil {
+		return &CancelReservationResponse{Status: CancelReservationStatusRejected}, nil
+	}
+
+	return h.OnCancelReservation(stationID, &req)
+}
+
+// handleSendLocalList handles SendLocalList request
+func (h *Handler) handleSendLocalList(stationID string, call *ocpp.Call) (*SendLocalListResponse, error) {
+	var req SendLocalListRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SendLocalList request: %w", err)
+	}
+
+	if h.OnSendLocalList == nil {
+		return &SendLocalListResponse{Status: UpdateStatusNotSupported}, nil
+	}
+
+	return h.OnSendLocalList(stationID, &req)
+}
+
+// handleGetLocalListVersion handles GetLocalListVersion request
+func (h *Handler) handleGetLocalListVersion(stationID string, call *ocpp.Call) (*GetLocalListVersionResponse, error) {
+	var req GetLocalListVersionRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetLocalListVersion request: %w", err)
+	}
+
+	if h.OnGetLocalListVersion == nil {
+		return &GetLocalListVersionResponse{ListVersion: -1}, nil
+	}
+
+	return h.OnGetLocalListVersion(stationID, &req)
+}
+
+// handleUpdateFirmware handles UpdateFirmware request
+func (h *Handler) handleUpdateFirmware(stationID string, call *ocpp.Call) (*UpdateFirmwareResponse, error) {
+	var req UpdateFirmwareRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal UpdateFirmware request: %w", err)
+	}
+
+	if h.OnUpdateFirmware == nil {
+		return &UpdateFirmwareResponse{}, nil
+	}
+
+	return h.OnUpdateFirmware(stationID, &req)
+}
+
+// handleGetDiagnostics handles GetDiagnostics request
+func (h *Handler) handleGetDiagnostics(stationID string, call *ocpp.Call) (*GetDiagnosticsResponse, error) {
+	var req GetDiagnosticsRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetDiagnostics request: %w", err)
+	}
+
+	if h.OnGetDiagnostics == nil {
+		return &GetDiagnosticsResponse{}, nil
+	}
+
+	return h.OnGetDiagnostics(stationID, &req)
+}
+
+// handleTriggerMessage handles TriggerMessage request
+func (h *Handler) handleTriggerMessage(stationID string, call *ocpp.Call) (*TriggerMessageResponse, error) {
+	var req TriggerMessageRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TriggerMessage request: %w", err)
+	}
+
+	if h.OnTriggerMessage == nil {
+		return &TriggerMessageResponse{Status: TriggerMessageStatusNotImplemented}, nil
+	}
+
+	return h.OnTriggerMessage(stationID, &req)
+}
+
+// handleCertificateSigned handles CertificateSigned request
+func (h *Handler) handleCertificateSigned(stationID string, call *ocpp.Call) (*CertificateSignedResponse, error) {
+	var req CertificateSignedRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CertificateSigned request: %w", err)
+	}
+
+	if h.OnCertificateSigned == nil {
+		return &CertificateSignedResponse{Status: CertificateSignedStatusRejected}, nil
+	}
+
+	return h.OnCertificateSigned(stationID, &req)
+}
+
+// handleInstallCertificate handles InstallCertificate request
+func (h *Handler) handleInstallCertificate(stationID string, call *ocpp.Call) (*InstallCertificateResponse, error) {
+	var req InstallCertificateRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal InstallCertificate request: %w", err)
+	}
+
+	if h.OnInstallCertificate == nil {
+		return &InstallCertificateResponse{Status: CertificateStatusRejected}, nil
+	}
+
+	return h.OnInstallCertificate(stationID, &req)
+}
+
+// handleDeleteCertificate handles DeleteCertificate request
+func (h *Handler) handleDeleteCertificate(stationID string, call *ocpp.Call) (*DeleteCertificateResponse, error) {
+	var req DeleteCertificateRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DeleteCertificate request: %w", err)
+	}
+
+	if h.OnDeleteCertificate == nil {
+		return &DeleteCertificateResponse{Status: DeleteCertificateStatusFailed}, nil
+	}
+
+	return h.OnDeleteCertificate(stationID, &req)
+}
+
+// handleGetInstalledCertificateIds handles GetInstalledCertificateIds request
+func (h *Handler) handleGetInstalledCertificateIds(stationID string, call *ocpp.Call) (*GetInstalledCertificateIdsResponse, error) {
+	var req GetInstalledCertificateIdsRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetInstalledCertificateIds request: %w", err)
+	}
+
+	if h.OnGetInstalledCertificateIds == nil {
+		return &GetInstalledCertificateIdsResponse{Status: GetInstalledCertificateStatusNotFound}, nil
+	}
+
+	return h.OnGetInstalledCertificateIds(stationID, &req)
+}
+
+// handleExtendedTriggerMessage handles ExtendedTriggerMessage request
+func (h *Handler) handleExtendedTriggerMessage(stationID string, call *ocpp.Call) (*ExtendedTriggerMessageResponse, error) {
+	var req ExtendedTriggerMessageRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ExtendedTriggerMessage request: %w", err)
+	}
+
+	if h.OnExtendedTriggerMessage == nil {
+		return &ExtendedTriggerMessageResponse{Status: TriggerMessageStatusNotImplemented}, nil
+	}
+
+	return h.OnExtendedTriggerMessage(stationID, &req)
+}
+
+// handleSignedUpdateFirmware handles SignedUpdateFirmware request
+func (h *Handler) handleSignedUpdateFirmware(stationID string, call *ocpp.Call) (*SignedUpdateFirmwareResponse, error) {
+	var req SignedUpdateFirmwareRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SignedUpdateFirmware request: %w", err)
+	}
+
+	if h.OnSignedUpdateFirmware == nil {
+		return &SignedUpdateFirmwareResponse{Status: UpdateFirmwareStatusRejected}, nil
+	}
+
+	return h.OnSignedUpdateFirmware(stationID, &req)
+}
+
+// handleGetLog handles GetLog request
+func (h *Handler) handleGetLog(stationID string, call *ocpp.Call) (*GetLogResponse, error) {
+	var req GetLogRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetLog request: %w", err)
+	}
+
+	if h.OnGetLog == nil {
+		return &GetLogResponse{Status: LogStatusRejected}, nil
+	}
+
+	return h.OnGetLog(stationID, &req)
+}
+
 // ==================== Outgoing Messages (Charge Point → CSMS) ====================
 
 // SendBootNotification sends a BootNotification request
@@ -433,7 +734,140 @@ func (h *Handler) HandleCallResult(stationID string, result *ocpp.CallResult, or
 		}
 		return &resp, nil
 
+	case ActionSignCertificate:
+		var resp SignCertificateResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SignCertificate response: %w", err)
+		}
+		return &resp, nil
+
 	default:
 		return nil, fmt.Errorf("unknown action for CallResult: %s", originalAction)
 	}
 }
+
+// SendFirmwareStatusNotification sends a FirmwareStatusNotification request
+func (h *Handler) SendFirmwareStatusNotification(stationID string, req *FirmwareStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionFirmwareStatusNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FirmwareStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal FirmwareStatusNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send FirmwareStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// SendDiagnosticsStatusNotification sends a DiagnosticsStatusNotification request
+func (h *Handler) SendDiagnosticsStatusNotification(stationID string, req *DiagnosticsStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionDiagnosticsStatusNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DiagnosticsStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal DiagnosticsStatusNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send DiagnosticsStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// SendSignCertificate sends a SignCertificate request
+func (h *Handler) SendSignCertificate(stationID string, req *SignCertificateRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionSignCertificate), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SignCertificate call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SignCertificate: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send SignCertificate: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// SendSecurityEventNotification sends a SecurityEventNotification request
+func (h *Handler) SendSecurityEventNotification(stationID string, req *SecurityEventNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionSecurityEventNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SecurityEventNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SecurityEventNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send SecurityEventNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// SendSignedFirmwareStatusNotification sends a SignedFirmwareStatusNotification request
+func (h *Handler) SendSignedFirmwareStatusNotification(stationID string, req *SignedFirmwareStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionSignedFirmwareStatusNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SignedFirmwareStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SignedFirmwareStatusNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send SignedFirmwareStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// SendLogStatusNotification sends a LogStatusNotification request
+func (h *Handler) SendLogStatusNotification(stationID string, req *LogStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionLogStatusNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LogStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal LogStatusNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send LogStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
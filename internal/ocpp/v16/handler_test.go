@@ -531,3 +531,304 @@ func TestHandler_HandleCallResult_StartTransaction(t *testing.T) {
 		t.Errorf("Expected status 'Accepted', got '%s'", startTxResp.IdTagInfo.Status)
 	}
 }
+
+func TestHandler_HandleCall_SetChargingProfile(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	var receivedReq *SetChargingProfileRequest
+	handler.OnSetChargingProfile = func(stationID string, req *SetChargingProfileRequest) (*SetChargingProfileResponse, error) {
+		receivedReq = req
+		return &SetChargingProfileResponse{Status: ChargingProfileStatusAccepted}, nil
+	}
+
+	payload := []byte(`{
+		"connectorId": 1,
+		"csChargingProfiles": {
+			"chargingProfileId": 7,
+			"stackLevel": 2,
+			"chargingProfilePurpose": "TxDefaultProfile",
+			"chargingProfileKind": "Absolute",
+			"chargingSchedule": {
+				"startSchedule": "2024-01-01T10:00:00Z",
+				"chargingRateUnit": "A",
+				"chargingSchedulePeriod": [{"startPeriod": 0, "limit": 16.0, "numberPhases": 3}]
+			}
+		}
+	}`)
+
+	call := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-scp",
+		Action:        string(ActionSetChargingProfile),
+		Payload:       payload,
+	}
+
+	resp, err := handler.HandleCall("CP001", call)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+
+	scpResp, ok := resp.(*SetChargingProfileResponse)
+	if !ok {
+		t.Fatalf("Expected *SetChargingProfileResponse, got %T", resp)
+	}
+
+	if scpResp.Status != ChargingProfileStatusAccepted {
+		t.Errorf("Expected status Accepted, got %s", scpResp.Status)
+	}
+
+	if receivedReq == nil {
+		t.Fatal("Callback was not called")
+	}
+
+	profile := receivedReq.CsChargingProfiles
+	if profile.ChargingProfileId != 7 || profile.StackLevel != 2 {
+		t.Errorf("Unexpected profile id/stack level: %d/%d", profile.ChargingProfileId, profile.StackLevel)
+	}
+
+	if profile.ChargingSchedule.StartSchedule == nil {
+		t.Fatal("Expected startSchedule to be parsed")
+	}
+
+	if len(profile.ChargingSchedule.ChargingSchedulePeriod) != 1 || profile.ChargingSchedule.ChargingSchedulePeriod[0].Limit != 16.0 {
+		t.Errorf("Unexpected schedule periods: %+v", profile.ChargingSchedule.ChargingSchedulePeriod)
+	}
+}
+
+func TestHandler_HandleCall_SmartChargingDefaults(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	tests := []struct {
+		action   Action
+		payload  string
+		expected interface{}
+	}{
+		{ActionSetChargingProfile, `{"connectorId":0,"csChargingProfiles":{"chargingProfileId":1,"stackLevel":0,"chargingProfilePurpose":"ChargePointMaxProfile","chargingProfileKind":"Relative","chargingSchedule":{"chargingRateUnit":"W","chargingSchedulePeriod":[{"startPeriod":0,"limit":11000}]}}}`, ChargingProfileStatusNotSupported},
+		{ActionClearChargingProfile, `{}`, ClearChargingProfileStatusUnknown},
+		{ActionGetCompositeSchedule, `{"connectorId":1,"duration":3600}`, GetCompositeScheduleStatusRejected},
+	}
+
+	for _, tt := range tests {
+		call := &ocpp.Call{
+			MessageTypeID: ocpp.MessageTypeCall,
+			UniqueID:      "test-" + string(tt.action),
+			Action:        string(tt.action),
+			Payload:       []byte(tt.payload),
+		}
+
+		resp, err := handler.HandleCall("CP001", call)
+		if err != nil {
+			t.Fatalf("%s: HandleCall failed: %v", tt.action, err)
+		}
+
+		var status interface{}
+		switch r := resp.(type) {
+		case *SetChargingProfileResponse:
+			status = r.Status
+		case *ClearChargingProfileResponse:
+			status = r.Status
+		case *GetCompositeScheduleResponse:
+			status = r.Status
+		}
+
+		if status != tt.expected {
+			t.Errorf("%s: expected status %v without callback, got %v", tt.action, tt.expected, status)
+		}
+	}
+}
+
+func TestHandler_HandleCall_ReserveNow(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	var received *ReserveNowRequest
+	handler.OnReserveNow = func(stationID string, req *ReserveNowRequest) (*ReserveNowResponse, error) {
+		received = req
+		return &ReserveNowResponse{Status: ReservationStatusAccepted}, nil
+	}
+
+	call := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-reserve",
+		Action:        string(ActionReserveNow),
+		Payload:       []byte(`{"connectorId":1,"expiryDate":"2030-01-01T00:00:00Z","idTag":"TAG001","parentIdTag":"FLEET","reservationId":42}`),
+	}
+
+	resp, err := handler.HandleCall("CP001", call)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+
+	reserveResp, ok := resp.(*ReserveNowResponse)
+	if !ok {
+		t.Fatalf("Expected *ReserveNowResponse, got %T", resp)
+	}
+	if reserveResp.Status != ReservationStatusAccepted {
+		t.Errorf("Expected status Accepted, got %s", reserveResp.Status)
+	}
+
+	if received == nil || received.ReservationId != 42 || received.ParentIdTag != "FLEET" {
+		t.Errorf("Unexpected request passed to callback: %+v", received)
+	}
+
+	// Without a callback reservations are rejected
+	handler.OnReserveNow = nil
+	resp, _ = handler.HandleCall("CP001", call)
+	if resp.(*ReserveNowResponse).Status != ReservationStatusRejected {
+		t.Errorf("Expected Rejected without callback, got %s", resp.(*ReserveNowResponse).Status)
+	}
+}
+
+func TestHandler_HandleCall_SendLocalList(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	call := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-local-list",
+		Action:        string(ActionSendLocalList),
+		Payload:       []byte(`{"listVersion":3,"updateType":"Differential","localAuthorizationList":[{"idTag":"TAG001","idTagInfo":{"status":"Accepted"}},{"idTag":"TAG002"}]}`),
+	}
+
+	// Without a callback the local list is not supported
+	resp, err := handler.HandleCall("CP001", call)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+	if resp.(*SendLocalListResponse).Status != UpdateStatusNotSupported {
+		t.Errorf("Expected NotSupported without callback, got %s", resp.(*SendLocalListResponse).Status)
+	}
+
+	var received *SendLocalListRequest
+	handler.OnSendLocalList = func(stationID string, req *SendLocalListRequest) (*SendLocalListResponse, error) {
+		received = req
+		return &SendLocalListResponse{Status: UpdateStatusAccepted}, nil
+	}
+
+	if _, err := handler.HandleCall("CP001", call); err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+
+	if received == nil || received.UpdateType != UpdateTypeDifferential || len(received.LocalAuthorizationList) != 2 {
+		t.Fatalf("Unexpected request passed to callback: %+v", received)
+	}
+	if received.LocalAuthorizationList[1].IdTagInfo != nil {
+		t.Error("Expected entry without idTagInfo to decode as removal")
+	}
+}
+
+func TestHandler_HandleCall_GetDiagnostics(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	var received *GetDiagnosticsRequest
+	handler.OnGetDiagnostics = func(stationID string, req *GetDiagnosticsRequest) (*GetDiagnosticsResponse, error) {
+		received = req
+		return &GetDiagnosticsResponse{FileName: "diagnostics.log"}, nil
+	}
+
+	call := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-diagnostics",
+		Action:        string(ActionGetDiagnostics),
+		Payload:       []byte(`{"location":"ftp://example.com/upload","retries":3,"retryInterval":30}`),
+	}
+
+	resp, err := handler.HandleCall("CP001", call)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+
+	if resp.(*GetDiagnosticsResponse).FileName != "diagnostics.log" {
+		t.Errorf("Expected file name diagnostics.log, got %s", resp.(*GetDiagnosticsResponse).FileName)
+	}
+	if received == nil || received.Retries == nil || *received.Retries != 3 {
+		t.Errorf("Unexpected request passed to callback: %+v", received)
+	}
+}
+
+func TestHandler_HandleCall_TriggerMessage(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	// Without a callback the requested message is not implemented
+	call := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-trigger",
+		Action:        string(ActionTriggerMessage),
+		Payload:       []byte(`{"requestedMessage":"StatusNotification","connectorId":1}`),
+	}
+
+	resp, err := handler.HandleCall("CP001", call)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+	if resp.(*TriggerMessageResponse).Status != TriggerMessageStatusNotImplemented {
+		t.Errorf("Expected NotImplemented, got %s", resp.(*TriggerMessageResponse).Status)
+	}
+
+	var received *TriggerMessageRequest
+	handler.OnTriggerMessage = func(stationID string, req *TriggerMessageRequest) (*TriggerMessageResponse, error) {
+		received = req
+		return &TriggerMessageResponse{Status: TriggerMessageStatusAccepted}, nil
+	}
+
+	resp, err = handler.HandleCall("CP001", call)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+	if resp.(*TriggerMessageResponse).Status != TriggerMessageStatusAccepted {
+		t.Errorf("Expected Accepted, got %s", resp.(*TriggerMessageResponse).Status)
+	}
+	if received == nil || received.RequestedMessage != MessageTriggerStatusNotification || received.ConnectorId == nil || *received.ConnectorId != 1 {
+		t.Errorf("Unexpected request passed to callback: %+v", received)
+	}
+}
+
+func TestHandler_HandleCall_SecurityExtensions(t *testing.T) {
+	handler := NewHandler(slog.Default())
+
+	// Without callbacks the security extensions are rejected
+	trigger := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-ext-trigger",
+		Action:        string(ActionExtendedTriggerMessage),
+		Payload:       []byte(`{"requestedMessage":"SignChargePointCertificate"}`),
+	}
+
+	resp, err := handler.HandleCall("CP001", trigger)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+	if resp.(*ExtendedTriggerMessageResponse).Status != TriggerMessageStatusNotImplemented {
+		t.Errorf("Expected NotImplemented, got %s", resp.(*ExtendedTriggerMessageResponse).Status)
+	}
+
+	install := &ocpp.Call{
+		MessageTypeID: ocpp.MessageTypeCall,
+		UniqueID:      "test-install-cert",
+		Action:        string(ActionInstallCertificate),
+		Payload:       []byte(`{"certificateType":"ManufacturerRootCertificate","certificate":"-----BEGIN CERTIFICATE-----"}`),
+	}
+
+	resp, err = handler.HandleCall("CP001", install)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+	if resp.(*InstallCertificateResponse).Status != CertificateStatusRejected {
+		t.Errorf("Expected Rejected, got %s", resp.(*InstallCertificateResponse).Status)
+	}
+
+	var received *InstallCertificateRequest
+	handler.OnInstallCertificate = func(stationID string, req *InstallCertificateRequest) (*InstallCertificateResponse, error) {
+		received = req
+		return &InstallCertificateResponse{Status: CertificateStatusAccepted}, nil
+	}
+
+	resp, err = handler.HandleCall("CP001", install)
+	if err != nil {
+		t.Fatalf("HandleCall failed: %v", err)
+	}
+	if resp.(*InstallCertificateResponse).Status != CertificateStatusAccepted {
+		t.Errorf("Expected Accepted, got %s", resp.(*InstallCertificateResponse).Status)
+	}
+	if received == nil || received.CertificateType != CertificateUseManufacturerRootCertificate {
+		t.Errorf("Unexpected request passed to callback: %+v", received)
+	}
+}
@@ -133,9 +133,9 @@ type StopTransactionResponse struct {
 
 // RemoteStartTransactionRequest represents a RemoteStartTransaction request
 type RemoteStartTransactionRequest struct {
-	ConnectorId     *int        `json:"connectorId,omitempty" validate:"omitempty,gt=0"`
-	IdTag           string      `json:"idTag" validate:"required,max=20"`
-	ChargingProfile interface{} `json:"chargingProfile,omitempty"` // Complex type, simplified for now
+	ConnectorId     *int             `json:"connectorId,omitempty" validate:"omitempty,gt=0"`
+	IdTag           string           `json:"idTag" validate:"required,max=20"`
+	ChargingProfile *ChargingProfile `json:"chargingProfile,omitempty"` // Must be a TxProfile
 }
 
 // RemoteStartTransactionResponse represents a RemoteStartTransactionResponse
@@ -220,7 +220,7 @@ type ChangeConfigurationRequest struct {
 
 // ChangeConfigurationResponse represents a ChangeConfiguration response
 type ChangeConfigurationResponse struct {
-	Status string `json:"status"` // Accepted, Rejected, RebootRequired, NotSupported
+	Status ConfigurationStatus `json:"status"`
 }
 
 // =========== ClearCache ===========
@@ -234,3 +234,337 @@ type ClearCacheRequest struct {
 type ClearCacheResponse struct {
 	Status string `json:"status"` // Accepted, Rejected
 }
+
+// Smart Charging Profile Message Payloads
+
+// =========== SetChargingProfile ===========
+
+// SetChargingProfileRequest represents a SetChargingProfile request
+type SetChargingProfileRequest struct {
+	ConnectorId        int             `json:"connectorId" validate:"gte=0"`
+	CsChargingProfiles ChargingProfile `json:"csChargingProfiles" validate:"required"`
+}
+
+// SetChargingProfileResponse represents a SetChargingProfile response
+type SetChargingProfileResponse struct {
+	Status ChargingProfileStatus `json:"status"`
+}
+
+// =========== ClearChargingProfile ===========
+
+// ClearChargingProfileRequest represents a ClearChargingProfile request
+type ClearChargingProfileRequest struct {
+	Id                     *int                       `json:"id,omitempty"`
+	ConnectorId            *int                       `json:"connectorId,omitempty"`
+	ChargingProfilePurpose ChargingProfilePurposeType `json:"chargingProfilePurpose,omitempty"`
+	StackLevel             *int                       `json:"stackLevel,omitempty"`
+}
+
+// ClearChargingProfileResponse represents a ClearChargingProfile response
+type ClearChargingProfileResponse struct {
+	Status ClearChargingProfileStatus `json:"status"`
+}
+
+// =========== GetCompositeSchedule ===========
+
+// GetCompositeScheduleRequest represents a GetCompositeSchedule request
+type GetCompositeScheduleRequest struct {
+	ConnectorId      int                  `json:"connectorId" validate:"gte=0"`
+	Duration         int                  `json:"duration" validate:"required"` // Seconds
+	ChargingRateUnit ChargingRateUnitType `json:"chargingRateUnit,omitempty"`
+}
+
+// GetCompositeScheduleResponse represents a GetCompositeSchedule response
+type GetCompositeScheduleResponse struct {
+	Status           GetCompositeScheduleStatus `json:"status"`
+	ConnectorId      *int                       `json:"connectorId,omitempty"`
+	ScheduleStart    *DateTime                  `json:"scheduleStart,omitempty"`
+	ChargingSchedule *ChargingSchedule          `json:"chargingSchedule,omitempty"`
+}
+
+// Reservation Profile Message Payloads
+
+// =========== ReserveNow ===========
+
+// ReserveNowRequest represents a ReserveNow request
+type ReserveNowRequest struct {
+	ConnectorId   int      `json:"connectorId" validate:"gte=0"`
+	ExpiryDate    DateTime `json:"expiryDate" validate:"required"`
+	IdTag         string   `json:"idTag" validate:"required,max=20"`
+	ParentIdTag   string   `json:"parentIdTag,omitempty" validate:"max=20"`
+	ReservationId int      `json:"reservationId" validate:"required"`
+}
+
+// ReserveNowResponse represents a ReserveNow response
+type ReserveNowResponse struct {
+	Status ReservationStatus `json:"status"`
+}
+
+// =========== CancelReservation ===========
+
+// CancelReservationRequest represents a CancelReservation request
+type CancelReservationRequest struct {
+	ReservationId int `json:"reservationId" validate:"required"`
+}
+
+// CancelReservationResponse represents a CancelReservation response
+type CancelReservationResponse struct {
+	Status CancelReservationStatus `json:"status"`
+}
+
+// =========== SendLocalList ===========
+
+// SendLocalListRequest represents a SendLocalList request
+type SendLocalListRequest struct {
+	ListVersion            int                 `json:"listVersion" validate:"required"`
+	LocalAuthorizationList []AuthorizationData `json:"localAuthorizationList,omitempty"`
+	UpdateType             UpdateType          `json:"updateType" validate:"required"`
+}
+
+// SendLocalListResponse represents a SendLocalList response
+type SendLocalListResponse struct {
+	Status UpdateStatus `json:"status"`
+}
+
+// =========== GetLocalListVersion ===========
+
+// GetLocalListVersionRequest represents a GetLocalListVersion request
+type GetLocalListVersionRequest struct {
+	// Empty payload
+}
+
+// GetLocalListVersionResponse represents a GetLocalListVersion response
+type GetLocalListVersionResponse struct {
+	ListVersion int `json:"listVersion"` // -1 if the Local Authorization List is not supported
+}
+
+// =========== UpdateFirmware ===========
+
+// UpdateFirmwareRequest represents an UpdateFirmware request
+type UpdateFirmwareRequest struct {
+	Location      string   `json:"location" validate:"required"`
+	Retries       *int     `json:"retries,omitempty"`
+	RetrieveDate  DateTime `json:"retrieveDate" validate:"required"`
+	RetryInterval *int     `json:"retryInterval,omitempty"` // Seconds
+}
+
+// UpdateFirmwareResponse represents an UpdateFirmware response
+type UpdateFirmwareResponse struct {
+	// Empty payload
+}
+
+// =========== GetDiagnostics ===========
+
+// GetDiagnosticsRequest represents a GetDiagnostics request
+type GetDiagnosticsRequest struct {
+	Location      string    `json:"location" validate:"required"`
+	Retries       *int      `json:"retries,omitempty"`
+	RetryInterval *int      `json:"retryInterval,omitempty"` // Seconds
+	StartTime     *DateTime `json:"startTime,omitempty"`
+	StopTime      *DateTime `json:"stopTime,omitempty"`
+}
+
+// GetDiagnosticsResponse represents a GetDiagnostics response
+type GetDiagnosticsResponse struct {
+	FileName string `json:"fileName,omitempty"` // Empty if no diagnostics are available
+}
+
+// =========== FirmwareStatusNotification ===========
+
+// FirmwareStatusNotificationRequest represents a FirmwareStatusNotification request
+type FirmwareStatusNotificationRequest struct {
+	Status FirmwareStatus `json:"status" validate:"required"`
+}
+
+// FirmwareStatusNotificationResponse represents a FirmwareStatusNotification response
+type FirmwareStatusNotificationResponse struct {
+	// Empty payload
+}
+
+// =========== DiagnosticsStatusNotification ===========
+
+// DiagnosticsStatusNotificationRequest represents a DiagnosticsStatusNotification request
+type DiagnosticsStatusNotificationRequest struct {
+	Status DiagnosticsStatus `json:"status" validate:"required"`
+}
+
+// DiagnosticsStatusNotificationResponse represents a DiagnosticsStatusNotification response
+type DiagnosticsStatusNotificationResponse struct {
+	// Empty payload
+}
+
+// =========== TriggerMessage ===========
+
+// TriggerMessageRequest represents a TriggerMessage request
+type TriggerMessageRequest struct {
+	RequestedMessage MessageTrigger `json:"requestedMessage" validate:"required"`
+	ConnectorId      *int           `json:"connectorId,omitempty"`
+}
+
+// TriggerMessageResponse represents a TriggerMessage response
+type TriggerMessageResponse struct {
+	Status TriggerMessageStatus `json:"status"`
+}
+
+// =========== SignCertificate ===========
+
+// SignCertificateRequest represents a SignCertificate request (Charge Point → Central System)
+type SignCertificateRequest struct {
+	Csr string `json:"csr" validate:"required,max=5500"` // PEM encoded PKCS#10 certificate signing request
+}
+
+// SignCertificateResponse represents a SignCertificate response
+type SignCertificateResponse struct {
+	Status GenericStatus `json:"status"`
+}
+
+// =========== CertificateSigned ===========
+
+// CertificateSignedRequest represents a CertificateSigned request
+type CertificateSignedRequest struct {
+	CertificateChain string `json:"certificateChain" validate:"required,max=10000"` // PEM encoded chain, leaf first
+}
+
+// CertificateSignedResponse represents a CertificateSigned response
+type CertificateSignedResponse struct {
+	Status CertificateSignedStatus `json:"status"`
+}
+
+// =========== InstallCertificate ===========
+
+// InstallCertificateRequest represents an InstallCertificate request
+type InstallCertificateRequest struct {
+	CertificateType CertificateUse `json:"certificateType" validate:"required"`
+	Certificate     string         `json:"certificate" validate:"required,max=5500"` // PEM encoded X.509 certificate
+}
+
+// InstallCertificateResponse represents an InstallCertificate response
+type InstallCertificateResponse struct {
+	Status CertificateStatus `json:"status"`
+}
+
+// =========== DeleteCertificate ===========
+
+// DeleteCertificateRequest represents a DeleteCertificate request
+type DeleteCertificateRequest struct {
+	CertificateHashData CertificateHashData `json:"certificateHashData" validate:"required"`
+}
+
+// DeleteCertificateResponse represents a DeleteCertificate response
+type DeleteCertificateResponse struct {
+	Status DeleteCertificateStatus `json:"status"`
+}
+
+// =========== GetInstalledCertificateIds ===========
+
+// GetInstalledCertificateIdsRequest represents a GetInstalledCertificateIds request
+type GetInstalledCertificateIdsRequest struct {
+	CertificateType CertificateUse `json:"certificateType" validate:"required"`
+}
+
+// GetInstalledCertificateIdsResponse represents a GetInstalledCertificateIds response
+type GetInstalledCertificateIdsResponse struct {
+	Status              GetInstalledCertificateStatus `json:"status"`
+	CertificateHashData []CertificateHashData         `json:"certificateHashData,omitempty"`
+}
+
+// =========== ExtendedTriggerMessage ===========
+
+// ExtendedTriggerMessageRequest represents an ExtendedTriggerMessage request
+type ExtendedTriggerMessageRequest struct {
+	RequestedMessage ExtendedMessageTrigger `json:"requestedMessage" validate:"required"`
+	ConnectorId      *int                   `json:"connectorId,omitempty"`
+}
+
+// ExtendedTriggerMessageResponse represents an ExtendedTriggerMessage response
+type ExtendedTriggerMessageResponse struct {
+	Status TriggerMessageStatus `json:"status"`
+}
+
+// =========== SecurityEventNotification ===========
+
+// SecurityEventNotificationRequest represents a SecurityEventNotification request
+type SecurityEventNotificationRequest struct {
+	Type      string   `json:"type" validate:"required,max=50"`
+	Timestamp DateTime `json:"timestamp" validate:"required"`
+	TechInfo  string   `json:"techInfo,omitempty" validate:"max=255"`
+}
+
+// SecurityEventNotificationResponse represents a SecurityEventNotification response
+type SecurityEventNotificationResponse struct {
+	// Empty payload
+}
+
+// =========== SignedUpdateFirmware ===========
+
+// Firmware describes the signed firmware image of a SignedUpdateFirmware request
+type Firmware struct {
+	Location           string    `json:"location" validate:"required,max=512"`
+	RetrieveDateTime   DateTime  `json:"retrieveDateTime" validate:"required"`
+	InstallDateTime    *DateTime `json:"installDateTime,omitempty"`
+	SigningCertificate string    `json:"signingCertificate" validate:"required,max=5500"` // PEM encoded
+	Signature          string    `json:"signature" validate:"required,max=800"`           // Base64 encoded
+}
+
+// SignedUpdateFirmwareRequest represents a SignedUpdateFirmware request
+type SignedUpdateFirmwareRequest struct {
+	Retries       *int     `json:"retries,omitempty"`
+	RetryInterval *int     `json:"retryInterval,omitempty"` // Seconds
+	RequestId     int      `json:"requestId" validate:"required"`
+	Firmware      Firmware `json:"firmware" validate:"required"`
+}
+
+// SignedUpdateFirmwareResponse represents a SignedUpdateFirmware response
+type SignedUpdateFirmwareResponse struct {
+	Status UpdateFirmwareStatus `json:"status"`
+}
+
+// =========== SignedFirmwareStatusNotification ===========
+
+// SignedFirmwareStatusNotificationRequest represents a SignedFirmwareStatusNotification request
+type SignedFirmwareStatusNotificationRequest struct {
+	Status    FirmwareStatus `json:"status" validate:"required"`
+	RequestId *int           `json:"requestId,omitempty"` // Omitted when not triggered by a SignedUpdateFirmware request
+}
+
+// SignedFirmwareStatusNotificationResponse represents a SignedFirmwareStatusNotification response
+type SignedFirmwareStatusNotificationResponse struct {
+	// Empty payload
+}
+
+// =========== GetLog ===========
+
+// LogParameters describes the log to upload
+type LogParameters struct {
+	RemoteLocation  string    `json:"remoteLocation" validate:"required,max=512"`
+	OldestTimestamp *DateTime `json:"oldestTimestamp,omitempty"`
+	LatestTimestamp *DateTime `json:"latestTimestamp,omitempty"`
+}
+
+// GetLogRequest represents a GetLog request
+type GetLogRequest struct {
+	Log           LogParameters `json:"log" validate:"required"`
+	LogType       LogType       `json:"logType" validate:"required"`
+	RequestId     int           `json:"requestId" validate:"required"`
+	Retries       *int          `json:"retries,omitempty"`
+	RetryInterval *int          `json:"retryInterval,omitempty"` // Seconds
+}
+
+// GetLogResponse represents a GetLog response
+type GetLogResponse struct {
+	Status   LogStatus `json:"status"`
+	Filename string    `json:"filename,omitempty"`
+}
+
+// =========== LogStatusNotification ===========
+
+// LogStatusNotificationRequest represents a LogStatusNotification request
+type LogStatusNotificationRequest struct {
+	Status    UploadLogStatus `json:"status" validate:"required"`
+	RequestId *int            `json:"requestId,omitempty"` // Omitted when not triggered by a GetLog request
+}
+
+// LogStatusNotificationResponse represents a LogStatusNotification response
+type LogStatusNotificationResponse struct {
+	// Empty payload
+}
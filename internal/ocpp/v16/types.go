@@ -43,6 +43,23 @@ const (
 	// Reservation Profile
 	ActionReserveNow        Action = "ReserveNow"
 	ActionCancelReservation Action = "CancelReservation"
+
+	// Local Auth List Management Profile
+	ActionGetLocalListVersion Action = "GetLocalListVersion"
+	ActionSendLocalList       Action = "SendLocalList"
+
+	// Security extensions (OCPP 1.6 Security Whitepaper)
+	ActionCertificateSigned                Action = "CertificateSigned"
+	ActionDeleteCertificate                Action = "DeleteCertificate"
+	ActionExtendedTriggerMessage           Action = "ExtendedTriggerMessage"
+	ActionGetInstalledCertificateIds       Action = "GetInstalledCertificateIds"
+	ActionGetLog                           Action = "GetLog"
+	ActionInstallCertificate               Action = "InstallCertificate"
+	ActionLogStatusNotification            Action = "LogStatusNotification"
+	ActionSecurityEventNotification        Action = "SecurityEventNotification"
+	ActionSignCertificate                  Action = "SignCertificate"
+	ActionSignedFirmwareStatusNotification Action = "SignedFirmwareStatusNotification"
+	ActionSignedUpdateFirmware             Action = "SignedUpdateFirmware"
 )
 
 // ChargePointStatus represents the status of a charge point connector
@@ -241,3 +258,341 @@ type MeterValue struct {
 	Timestamp    DateTime       `json:"timestamp"`
 	SampledValue []SampledValue `json:"sampledValue"`
 }
+
+// ReservationStatus represents the result of a ReserveNow request
+type ReservationStatus string
+
+const (
+	ReservationStatusAccepted    ReservationStatus = "Accepted"
+	ReservationStatusFaulted     ReservationStatus = "Faulted"
+	ReservationStatusOccupied    ReservationStatus = "Occupied"
+	ReservationStatusRejected    ReservationStatus = "Rejected"
+	ReservationStatusUnavailable ReservationStatus = "Unavailable"
+)
+
+// CancelReservationStatus represents the result of a CancelReservation request
+type CancelReservationStatus string
+
+const (
+	CancelReservationStatusAccepted CancelReservationStatus = "Accepted"
+	CancelReservationStatusRejected CancelReservationStatus = "Rejected"
+)
+
+// FirmwareStatus represents the status reported in a FirmwareStatusNotification
+type FirmwareStatus string
+
+const (
+	FirmwareStatusDownloaded         FirmwareStatus = "Downloaded"
+	FirmwareStatusDownloadFailed     FirmwareStatus = "DownloadFailed"
+	FirmwareStatusDownloading        FirmwareStatus = "Downloading"
+	FirmwareStatusIdle               FirmwareStatus = "Idle"
+	FirmwareStatusInstallationFailed FirmwareStatus = "InstallationFailed"
+	FirmwareStatusInstalling         FirmwareStatus = "Installing"
+	FirmwareStatusInstalled          FirmwareStatus = "Installed"
+
+	// Additional statuses of SignedFirmwareStatusNotification
+	FirmwareStatusDownloadScheduled         FirmwareStatus = "DownloadScheduled"
+	FirmwareStatusDownloadPaused            FirmwareStatus = "DownloadPaused"
+	FirmwareStatusInstallRebooting          FirmwareStatus = "InstallRebooting"
+	FirmwareStatusInstallScheduled          FirmwareStatus = "InstallScheduled"
+	FirmwareStatusInstallVerificationFailed FirmwareStatus = "InstallVerificationFailed"
+	FirmwareStatusInvalidSignature          FirmwareStatus = "InvalidSignature"
+	FirmwareStatusSignatureVerified         FirmwareStatus = "SignatureVerified"
+)
+
+// DiagnosticsStatus represents the status reported in a DiagnosticsStatusNotification
+type DiagnosticsStatus string
+
+const (
+	DiagnosticsStatusIdle         DiagnosticsStatus = "Idle"
+	DiagnosticsStatusUploaded     DiagnosticsStatus = "Uploaded"
+	DiagnosticsStatusUploadFailed DiagnosticsStatus = "UploadFailed"
+	DiagnosticsStatusUploading    DiagnosticsStatus = "Uploading"
+)
+
+// MessageTrigger represents the message requested by a TriggerMessage request
+type MessageTrigger string
+
+const (
+	MessageTriggerBootNotification              MessageTrigger = "BootNotification"
+	MessageTriggerDiagnosticsStatusNotification MessageTrigger = "DiagnosticsStatusNotification"
+	MessageTriggerFirmwareStatusNotification    MessageTrigger = "FirmwareStatusNotification"
+	MessageTriggerHeartbeat                     MessageTrigger = "Heartbeat"
+	MessageTriggerMeterValues                   MessageTrigger = "MeterValues"
+	MessageTriggerStatusNotification            MessageTrigger = "StatusNotification"
+)
+
+// ConfigurationStatus represents the result of a ChangeConfiguration request
+type ConfigurationStatus string
+
+const (
+	ConfigurationStatusAccepted       ConfigurationStatus = "Accepted"
+	ConfigurationStatusRejected       ConfigurationStatus = "Rejected"
+	ConfigurationStatusRebootRequired ConfigurationStatus = "RebootRequired"
+	ConfigurationStatusNotSupported   ConfigurationStatus = "NotSupported"
+)
+
+// TriggerMessageStatus represents the result of a TriggerMessage request
+type TriggerMessageStatus string
+
+const (
+	TriggerMessageStatusAccepted       TriggerMessageStatus = "Accepted"
+	TriggerMessageStatusRejected       TriggerMessageStatus = "Rejected"
+	TriggerMessageStatusNotImplemented TriggerMessageStatus = "NotImplemented"
+)
+
+// UpdateType represents the type of a SendLocalList update
+type UpdateType string
+
+const (
+	UpdateTypeDifferential UpdateType = "Differential"
+	UpdateTypeFull         UpdateType = "Full"
+)
+
+// UpdateStatus represents the result of a SendLocalList request
+type UpdateStatus string
+
+const (
+	UpdateStatusAccepted        UpdateStatus = "Accepted"
+	UpdateStatusFailed          UpdateStatus = "Failed"
+	UpdateStatusNotSupported    UpdateStatus = "NotSupported"
+	UpdateStatusVersionMismatch UpdateStatus = "VersionMismatch"
+)
+
+// AuthorizationData represents an entry of the Local Authorization List.
+// A differential update without IdTagInfo removes the ID tag from the list.
+type AuthorizationData struct {
+	IdTag     string     `json:"idTag"`
+	IdTagInfo *IdTagInfo `json:"idTagInfo,omitempty"`
+}
+
+// ChargingProfilePurposeType represents the purpose of a charging profile
+type ChargingProfilePurposeType string
+
+const (
+	ChargingProfilePurposeChargePointMaxProfile ChargingProfilePurposeType = "ChargePointMaxProfile"
+	ChargingProfilePurposeTxDefaultProfile      ChargingProfilePurposeType = "TxDefaultProfile"
+	ChargingProfilePurposeTxProfile             ChargingProfilePurposeType = "TxProfile"
+)
+
+// ChargingProfileKindType represents the kind of charging profile
+type ChargingProfileKindType string
+
+const (
+	ChargingProfileKindAbsolute  ChargingProfileKindType = "Absolute"
+	ChargingProfileKindRecurring ChargingProfileKindType = "Recurring"
+	ChargingProfileKindRelative  ChargingProfileKindType = "Relative"
+)
+
+// RecurrencyKindType represents the recurrence period of a Recurring profile
+type RecurrencyKindType string
+
+const (
+	RecurrencyKindDaily  RecurrencyKindType = "Daily"
+	RecurrencyKindWeekly RecurrencyKindType = "Weekly"
+)
+
+// ChargingRateUnitType represents the unit of a charging schedule limit
+type ChargingRateUnitType string
+
+const (
+	ChargingRateUnitW ChargingRateUnitType = "W"
+	ChargingRateUnitA ChargingRateUnitType = "A"
+)
+
+// ChargingProfileStatus represents the result of a SetChargingProfile request
+type ChargingProfileStatus string
+
+const (
+	ChargingProfileStatusAccepted     ChargingProfileStatus = "Accepted"
+	ChargingProfileStatusRejected     ChargingProfileStatus = "Rejected"
+	ChargingProfileStatusNotSupported ChargingProfileStatus = "NotSupported"
+)
+
+// ClearChargingProfileStatus represents the result of a ClearChargingProfile request
+type ClearChargingProfileStatus string
+
+const (
+	ClearChargingProfileStatusAccepted ClearChargingProfileStatus = "Accepted"
+	ClearChargingProfileStatusUnknown  ClearChargingProfileStatus = "Unknown"
+)
+
+// GetCompositeScheduleStatus represents the result of a GetCompositeSchedule request
+type GetCompositeScheduleStatus string
+
+const (
+	GetCompositeScheduleStatusAccepted GetCompositeScheduleStatus = "Accepted"
+	GetCompositeScheduleStatusRejected GetCompositeScheduleStatus = "Rejected"
+)
+
+// ChargingSchedulePeriod represents a single period of a charging schedule
+type ChargingSchedulePeriod struct {
+	StartPeriod  int     `json:"startPeriod"` // Seconds from the start of the schedule
+	Limit        float64 `json:"limit"`       // In the unit of the schedule, one decimal place
+	NumberPhases *int    `json:"numberPhases,omitempty"`
+}
+
+// ChargingSchedule represents a charging schedule
+type ChargingSchedule struct {
+	Duration               *int                     `json:"duration,omitempty"`
+	StartSchedule          *DateTime                `json:"startSchedule,omitempty"`
+	ChargingRateUnit       ChargingRateUnitType     `json:"chargingRateUnit" validate:"required"`
+	ChargingSchedulePeriod []ChargingSchedulePeriod `json:"chargingSchedulePeriod" validate:"required,min=1"`
+	MinChargingRate        *float64                 `json:"minChargingRate,omitempty"`
+}
+
+// ChargingProfile represents a charging profile
+type ChargingProfile struct {
+	ChargingProfileId      int                        `json:"chargingProfileId" validate:"required"`
+	TransactionId          *int                       `json:"transactionId,omitempty"`
+	StackLevel             int                        `json:"stackLevel" validate:"gte=0"`
+	ChargingProfilePurpose ChargingProfilePurposeType `json:"chargingProfilePurpose" validate:"required"`
+	ChargingProfileKind    ChargingProfileKindType    `json:"chargingProfileKind" validate:"required"`
+	RecurrencyKind         RecurrencyKindType         `json:"recurrencyKind,omitempty"`
+	ValidFrom              *DateTime                  `json:"validFrom,omitempty"`
+	ValidTo                *DateTime                  `json:"validTo,omitempty"`
+	ChargingSchedule       ChargingSchedule           `json:"chargingSchedule" validate:"required"`
+}
+
+// CertificateUse represents the type of a root certificate (security extensions)
+type CertificateUse string
+
+const (
+	CertificateUseCentralSystemRootCertificate CertificateUse = "CentralSystemRootCertificate"
+	CertificateUseManufacturerRootCertificate  CertificateUse = "ManufacturerRootCertificate"
+)
+
+// HashAlgorithm represents the hash algorithm used in CertificateHashData
+type HashAlgorithm string
+
+const (
+	HashAlgorithmSHA256 HashAlgorithm = "SHA256"
+	HashAlgorithmSHA384 HashAlgorithm = "SHA384"
+	HashAlgorithmSHA512 HashAlgorithm = "SHA512"
+)
+
+// CertificateHashData identifies an installed certificate
+type CertificateHashData struct {
+	HashAlgorithm  HashAlgorithm `json:"hashAlgorithm" validate:"required"`
+	IssuerNameHash string        `json:"issuerNameHash" validate:"required,max=128"`
+	IssuerKeyHash  string        `json:"issuerKeyHash" validate:"required,max=128"`
+	SerialNumber   string        `json:"serialNumber" validate:"required,max=40"`
+}
+
+// GenericStatus represents a plain Accepted/Rejected status
+type GenericStatus string
+
+const (
+	GenericStatusAccepted GenericStatus = "Accepted"
+	GenericStatusRejected GenericStatus = "Rejected"
+)
+
+// CertificateSignedStatus represents the result of a CertificateSigned request
+type CertificateSignedStatus string
+
+const (
+	CertificateSignedStatusAccepted CertificateSignedStatus = "Accepted"
+	CertificateSignedStatusRejected CertificateSignedStatus = "Rejected"
+)
+
+// CertificateStatus represents the result of an InstallCertificate request
+type CertificateStatus string
+
+const (
+	CertificateStatusAccepted CertificateStatus = "Accepted"
+	CertificateStatusRejected CertificateStatus = "Rejected"
+	CertificateStatusFailed   CertificateStatus = "Failed"
+)
+
+// DeleteCertificateStatus represents the result of a DeleteCertificate request
+type DeleteCertificateStatus string
+
+const (
+	DeleteCertificateStatusAccepted DeleteCertificateStatus = "Accepted"
+	DeleteCertificateStatusFailed   DeleteCertificateStatus = "Failed"
+	DeleteCertificateStatusNotFound DeleteCertificateStatus = "NotFound"
+)
+
+// GetInstalledCertificateStatus represents the result of a GetInstalledCertificateIds request
+type GetInstalledCertificateStatus string
+
+const (
+	GetInstalledCertificateStatusAccepted GetInstalledCertificateStatus = "Accepted"
+	GetInstalledCertificateStatusNotFound GetInstalledCertificateStatus = "NotFound"
+)
+
+// ExtendedMessageTrigger represents the message requested by an ExtendedTriggerMessage request
+type ExtendedMessageTrigger string
+
+const (
+	ExtendedMessageTriggerBootNotification           ExtendedMessageTrigger = "BootNotification"
+	ExtendedMessageTriggerLogStatusNotification      ExtendedMessageTrigger = "LogStatusNotification"
+	ExtendedMessageTriggerFirmwareStatusNotification ExtendedMessageTrigger = "FirmwareStatusNotification"
+	ExtendedMessageTriggerHeartbeat                  ExtendedMessageTrigger = "Heartbeat"
+	ExtendedMessageTriggerMeterValues                ExtendedMessageTrigger = "MeterValues"
+	ExtendedMessageTriggerSignChargePointCertificate ExtendedMessageTrigger = "SignChargePointCertificate"
+	ExtendedMessageTriggerStatusNotification         ExtendedMessageTrigger = "StatusNotification"
+)
+
+// LogType represents the type of log requested by GetLog
+type LogType string
+
+const (
+	LogTypeDiagnosticsLog LogType = "DiagnosticsLog"
+	LogTypeSecurityLog    LogType = "SecurityLog"
+)
+
+// LogStatus represents the result of a GetLog request
+type LogStatus string
+
+const (
+	LogStatusAccepted         LogStatus = "Accepted"
+	LogStatusRejected         LogStatus = "Rejected"
+	LogStatusAcceptedCanceled LogStatus = "AcceptedCanceled"
+)
+
+// UploadLogStatus represents the status reported in a LogStatusNotification
+type UploadLogStatus string
+
+const (
+	UploadLogStatusBadMessage            UploadLogStatus = "BadMessage"
+	UploadLogStatusIdle                  UploadLogStatus = "Idle"
+	UploadLogStatusNotSupportedOperation UploadLogStatus = "NotSupportedOperation"
+	UploadLogStatusPermissionDenied      UploadLogStatus = "PermissionDenied"
+	UploadLogStatusUploaded              UploadLogStatus = "Uploaded"
+	UploadLogStatusUploadFailure         UploadLogStatus = "UploadFailure"
+	UploadLogStatusUploading             UploadLogStatus = "Uploading"
+)
+
+// UpdateFirmwareStatus represents the result of a SignedUpdateFirmware request
+type UpdateFirmwareStatus string
+
+const (
+	UpdateFirmwareStatusAccepted           UpdateFirmwareStatus = "Accepted"
+	UpdateFirmwareStatusRejected           UpdateFirmwareStatus = "Rejected"
+	UpdateFirmwareStatusAcceptedCanceled   UpdateFirmwareStatus = "AcceptedCanceled"
+	UpdateFirmwareStatusInvalidCertificate UpdateFirmwareStatus = "InvalidCertificate"
+	UpdateFirmwareStatusRevokedCertificate UpdateFirmwareStatus = "RevokedCertificate"
+)
+
+// Security event types reported in SecurityEventNotification
+const (
+	SecurityEventFirmwareUpdated                     = "FirmwareUpdated"
+	SecurityEventFailedToAuthenticateAtCentralSystem = "FailedToAuthenticateAtCentralSystem"
+	SecurityEventCentralSystemFailedToAuthenticate   = "CentralSystemFailedToAuthenticate"
+	SecurityEventSettingSystemTime                   = "SettingSystemTime"
+	SecurityEventStartupOfTheDevice                  = "StartupOfTheDevice"
+	SecurityEventResetOrReboot                       = "ResetOrReboot"
+	SecurityEventSecurityLogWasCleared               = "SecurityLogWasCleared"
+	SecurityEventReconfigurationOfSecurityParameters = "ReconfigurationOfSecurityParameters"
+	SecurityEventMemoryExhaustion                    = "MemoryExhaustion"
+	SecurityEventInvalidMessages                     = "InvalidMessages"
+	SecurityEventAttemptedReplayAttacks              = "AttemptedReplayAttacks"
+	SecurityEventTamperDetectionActivated            = "TamperDetectionActivated"
+	SecurityEventInvalidFirmwareSignature            = "InvalidFirmwareSignature"
+	SecurityEventInvalidFirmwareSigningCertificate   = "InvalidFirmwareSigningCertificate"
+	SecurityEventInvalidCentralSystemCertificate     = "InvalidCentralSystemCertificate"
+	SecurityEventInvalidChargePointCertificate       = "InvalidChargePointCertificate"
+	SecurityEventInvalidTLSVersion                   = "InvalidTLSVersion"
+	SecurityEventInvalidTLSCipherSuite               = "InvalidTLSCipherSuite"
+)
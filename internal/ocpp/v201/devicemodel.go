@@ -2,7 +2,12 @@ package v201
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Mutability represents whether a variable can be modified
@@ -53,6 +58,8 @@ type VariableInstance struct {
 	Instance        string                  `json:"instance,omitempty"`
 	Characteristics VariableCharacteristics `json:"characteristics"`
 	Attributes      map[AttributeType]*VariableAttribute
+	monitors        map[int]*VariableMonitor // key: monitor ID
+	transactionID   string                   // Transaction ongoing when the value was last updated
 	mu              sync.RWMutex
 }
 
@@ -69,6 +76,11 @@ type ComponentInstance struct {
 type DeviceModel struct {
 	Components map[string]*ComponentInstance // key: componentName or componentName:instance
 	mu         sync.RWMutex
+
+	// Monitor and event IDs of variable monitoring
+	nextMonitorID int
+	nextEventID   int
+	monitorMu     sync.Mutex
 }
 
 // NewDeviceModel creates a new device model with standard components
@@ -153,6 +165,25 @@ func (dm *DeviceModel) GetVariable(componentName, componentInstance, variableNam
 	return attr.Value, GetVariableStatusAccepted
 }
 
+// GetCharacteristics retrieves the characteristics of a variable
+func (dm *DeviceModel) GetCharacteristics(componentName, componentInstance, variableName, variableInstance string) (VariableCharacteristics, bool) {
+	comp := dm.GetComponent(componentName, componentInstance)
+	if comp == nil {
+		return VariableCharacteristics{}, false
+	}
+
+	comp.mu.RLock()
+	variable := comp.Variables[getVariableKey(variableName, variableInstance)]
+	comp.mu.RUnlock()
+	if variable == nil {
+		return VariableCharacteristics{}, false
+	}
+
+	variable.mu.RLock()
+	defer variable.mu.RUnlock()
+	return variable.Characteristics, true
+}
+
 // SetVariable sets a variable value in a component
 func (dm *DeviceModel) SetVariable(componentName, componentInstance, variableName, variableInstance string, attrType AttributeType, value string) SetVariableStatusType {
 	comp := dm.GetComponent(componentName, componentInstance)
@@ -187,11 +218,98 @@ func (dm *DeviceModel) SetVariable(componentName, componentInstance, variableNam
 		return SetVariableStatusRejected
 	}
 
-	// TODO: Add value validation based on characteristics
+	if err := variable.Characteristics.Validate(value); err != nil {
+		return SetVariableStatusRejected
+	}
+
 	attr.Value = value
 	return SetVariableStatusAccepted
 }
 
+// Validate checks a value against the data type, limits and values list of a variable.
+// For strings and lists MaxLimit is the maximum length of the value.
+func (c VariableCharacteristics) Validate(value string) error {
+	switch c.DataType {
+	case DataTypeInteger, DataTypeDecimal:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || (c.DataType == DataTypeInteger && number != math.Trunc(number)) {
+			return fmt.Errorf("%q is not a valid %s", value, c.DataType)
+		}
+		if c.MinLimit != nil && number < *c.MinLimit {
+			return fmt.Errorf("%s is below the minimum of %v", value, *c.MinLimit)
+		}
+		if c.MaxLimit != nil && number > *c.MaxLimit {
+			return fmt.Errorf("%s is above the maximum of %v", value, *c.MaxLimit)
+		}
+		return nil
+
+	case DataTypeBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		return nil
+
+	case DataTypeDateTime:
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("%q is not an RFC 3339 date-time", value)
+		}
+		return nil
+
+	case DataTypeOptionList:
+		if !c.allows(value) {
+			return fmt.Errorf("%q is not one of %s", value, c.ValuesList)
+		}
+
+	case DataTypeMemberList, DataTypeSequenceList:
+		seen := make(map[string]bool)
+		for _, member := range splitList(value) {
+			if !c.allows(member) {
+				return fmt.Errorf("%q is not one of %s", member, c.ValuesList)
+			}
+			if seen[member] {
+				return fmt.Errorf("%q is listed more than once", member)
+			}
+			seen[member] = true
+		}
+
+	case DataTypeString:
+
+	default:
+		return fmt.Errorf("unknown data type %q", c.DataType)
+	}
+
+	if c.MaxLimit != nil && float64(len(value)) > *c.MaxLimit {
+		return fmt.Errorf("value is longer than %v characters", *c.MaxLimit)
+	}
+	return nil
+}
+
+// allows reports whether a value is in the values list, an empty list allows any value
+func (c VariableCharacteristics) allows(value string) bool {
+	if c.ValuesList == "" {
+		return true
+	}
+	for _, allowed := range splitList(c.ValuesList) {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated list, an empty string is an empty list
+func splitList(list string) []string {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+
+	items := strings.Split(list, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
 // AddVariable adds a variable to a component
 func (comp *ComponentInstance) AddVariable(name, instance string, characteristics VariableCharacteristics) *VariableInstance {
 	comp.mu.Lock()
@@ -259,6 +377,168 @@ func (dm *DeviceModel) GetAllVariables() []GetVariableResult {
 	return results
 }
 
+// BaseReport returns the report data of a predefined GetBaseReport report, sorted by component and variable.
+// ConfigurationInventory holds the writable variables, SummaryInventory the availability and problem
+// state of the components and FullInventory every variable. Returns false for an unknown report base.
+func (dm *DeviceModel) BaseReport(base ReportBaseType) ([]ReportData, bool) {
+	var include func(variable *VariableInstance) bool
+
+	switch base {
+	case ReportBaseFullInventory:
+		include = func(*VariableInstance) bool { return true }
+	case ReportBaseConfigurationInventory:
+		include = func(variable *VariableInstance) bool {
+			for _, attr := range variable.Attributes {
+				if attr.Mutability != MutabilityReadOnly && !attr.Constant {
+					return true
+				}
+			}
+			return false
+		}
+	case ReportBaseSummaryInventory:
+		include = func(variable *VariableInstance) bool {
+			switch variable.Name {
+			case "Available", "AvailabilityState", "Problem":
+				return true
+			}
+			return false
+		}
+	default:
+		return nil, false
+	}
+
+	return dm.report(func(*ComponentInstance) bool { return true }, include), true
+}
+
+// Report returns the report data of a GetReport request. Only components matching one of the
+// criteria and, if given, one of the component variables are reported.
+func (dm *DeviceModel) Report(componentVariables []ComponentVariable, criteria []ComponentCriterionType) []ReportData {
+	matchComponent := func(comp *ComponentInstance) bool {
+		if len(criteria) == 0 {
+			return true
+		}
+		for _, criterion := range criteria {
+			if variable := comp.Variables[string(criterion)]; variable != nil && variable.actualValue() == "true" {
+				return true
+			}
+		}
+		return false
+	}
+
+	return dm.report(matchComponent, func(*VariableInstance) bool { return true }, componentVariables...)
+}
+
+// report collects the variables of the matching components. Component variables further limit the
+// reported components and variables, a component variable without variable selects all its variables.
+func (dm *DeviceModel) report(matchComponent func(*ComponentInstance) bool, include func(*VariableInstance) bool, componentVariables ...ComponentVariable) []ReportData {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	var results []ReportData
+
+	for _, comp := range dm.Components {
+		comp.mu.RLock()
+		if !matchComponent(comp) {
+			comp.mu.RUnlock()
+			continue
+		}
+
+		for _, variable := range comp.Variables {
+			if len(componentVariables) > 0 && !selectsVariable(componentVariables, comp, variable) {
+				continue
+			}
+
+			variable.mu.RLock()
+			if include(variable) && len(variable.Attributes) > 0 {
+				results = append(results, variable.reportData(comp))
+			}
+			variable.mu.RUnlock()
+		}
+		comp.mu.RUnlock()
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Component.Name != b.Component.Name {
+			return a.Component.Name < b.Component.Name
+		}
+		if a.Component.Instance != b.Component.Instance {
+			return a.Component.Instance < b.Component.Instance
+		}
+		if a.Variable.Name != b.Variable.Name {
+			return a.Variable.Name < b.Variable.Name
+		}
+		return a.Variable.Instance < b.Variable.Instance
+	})
+
+	return results
+}
+
+// selectsVariable reports whether one of the component variables selects the variable of a component
+func selectsVariable(componentVariables []ComponentVariable, comp *ComponentInstance, variable *VariableInstance) bool {
+	for _, cv := range componentVariables {
+		if cv.Component.Name != comp.Name {
+			continue
+		}
+		if cv.Component.Instance != "" && cv.Component.Instance != comp.Instance {
+			continue
+		}
+		if cv.Component.EVSE != nil && (comp.EVSE == nil || cv.Component.EVSE.ID != comp.EVSE.ID) {
+			continue
+		}
+		if cv.Variable == nil {
+			return true
+		}
+		if cv.Variable.Name == variable.Name && (cv.Variable.Instance == "" || cv.Variable.Instance == variable.Instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportData returns the report entry of a variable. Must be called with v.mu held.
+// WriteOnly values are not reported.
+func (v *VariableInstance) reportData(comp *ComponentInstance) ReportData {
+	characteristics := v.Characteristics
+	data := ReportData{
+		Component: Component{
+			Name:     comp.Name,
+			Instance: comp.Instance,
+			EVSE:     comp.EVSE,
+		},
+		Variable: Variable{
+			Name:     v.Name,
+			Instance: v.Instance,
+		},
+		VariableCharacteristics: &characteristics,
+	}
+
+	for _, attrType := range []AttributeType{AttributeActual, AttributeTarget, AttributeMinSet, AttributeMaxSet} {
+		attr := v.Attributes[attrType]
+		if attr == nil {
+			continue
+		}
+		reported := *attr
+		if reported.Mutability == MutabilityWriteOnly {
+			reported.Value = ""
+		}
+		data.VariableAttribute = append(data.VariableAttribute, reported)
+	}
+
+	return data
+}
+
+// actualValue returns the Actual attribute value of a variable
+func (v *VariableInstance) actualValue() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if attr := v.Attributes[AttributeActual]; attr != nil {
+		return attr.Value
+	}
+	return ""
+}
+
 // initializeStandardComponents sets up the standard OCPP 2.0.1 device model components
 func (dm *DeviceModel) initializeStandardComponents() {
 	// ChargingStation component - main station configuration
@@ -277,6 +557,14 @@ func (dm *DeviceModel) initializeStandardComponents() {
 	auth := dm.AddComponent("AuthCtrlr", "", nil)
 	dm.addAuthVariables(auth)
 
+	// LocalAuthListCtrlr component - Local Authorization List settings
+	localAuthList := dm.AddComponent("LocalAuthListCtrlr", "", nil)
+	dm.addLocalAuthListVariables(localAuthList)
+
+	// AuthCacheCtrlr component - authorization cache settings
+	authCache := dm.AddComponent("AuthCacheCtrlr", "", nil)
+	dm.addAuthCacheVariables(authCache)
+
 	// TxCtrlr component - transaction settings
 	tx := dm.AddComponent("TxCtrlr", "", nil)
 	dm.addTxVariables(tx)
@@ -292,6 +580,18 @@ func (dm *DeviceModel) initializeStandardComponents() {
 	// DeviceDataCtrlr component - device information
 	device := dm.AddComponent("DeviceDataCtrlr", "", nil)
 	dm.addDeviceDataVariables(device)
+
+	// MonitoringCtrlr component - variable monitoring settings
+	monitoring := dm.AddComponent("MonitoringCtrlr", "", nil)
+	dm.addMonitoringVariables(monitoring)
+
+	// ReservationCtrlr component - reservation settings
+	reservation := dm.AddComponent("ReservationCtrlr", "", nil)
+	dm.addReservationVariables(reservation)
+
+	// DisplayMessageCtrlr component - display message settings
+	displayMessage := dm.AddComponent("DisplayMessageCtrlr", "", nil)
+	dm.addDisplayMessageVariables(displayMessage)
 }
 
 // addChargingStationVariables adds variables for the ChargingStation component
@@ -396,6 +696,29 @@ func (dm *DeviceModel) addOCPPCommVariables(comp *ComponentInstance) {
 	})
 	netProfiles.SetAttribute(AttributeActual, "1", MutabilityReadOnly, false, false)
 
+	// NetworkConfigurationPriority - network configuration slots in the order they are tried
+	netPriority := comp.AddVariable("NetworkConfigurationPriority", "", VariableCharacteristics{
+		DataType:        DataTypeSequenceList,
+		SupportsMonitor: false,
+		ValuesList:      "1,2,3",
+	})
+	netPriority.SetAttribute(AttributeActual, "1", MutabilityReadWrite, true, false)
+
+	// ActiveNetworkProfile - configuration slot of the current connection
+	activeProfile := comp.AddVariable("ActiveNetworkProfile", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+	})
+	activeProfile.SetAttribute(AttributeActual, "1", MutabilityReadOnly, false, false)
+
+	// OfflineThreshold - time offline before failing over to the next network configuration slot
+	offlineThreshold := comp.AddVariable("OfflineThreshold", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+		Unit:            "s",
+	})
+	offlineThreshold.SetAttribute(AttributeActual, "60", MutabilityReadWrite, true, false)
+
 	// WebSocketPingInterval - WebSocket ping interval
 	wsPing := comp.AddVariable("WebSocketPingInterval", "", VariableCharacteristics{
 		DataType:        DataTypeInteger,
@@ -434,6 +757,87 @@ func (dm *DeviceModel) addAuthVariables(comp *ComponentInstance) {
 		SupportsMonitor: false,
 	})
 	authRemote.SetAttribute(AttributeActual, "false", MutabilityReadWrite, true, false)
+
+	// OfflineTxForUnknownIdEnabled - accept unknown idTokens while offline
+	offlineUnknown := comp.AddVariable("OfflineTxForUnknownIdEnabled", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	offlineUnknown.SetAttribute(AttributeActual, "false", MutabilityReadWrite, true, false)
+}
+
+// addLocalAuthListVariables adds variables for the LocalAuthListCtrlr component
+func (dm *DeviceModel) addLocalAuthListVariables(comp *ComponentInstance) {
+	// Available - the Local Authorization List is supported
+	available := comp.AddVariable("Available", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	available.SetAttribute(AttributeActual, "true", MutabilityReadOnly, true, true)
+
+	// Enabled - the Local Authorization List is used
+	enabled := comp.AddVariable("Enabled", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	enabled.SetAttribute(AttributeActual, "true", MutabilityReadWrite, true, false)
+
+	// Entries - idTokens on the list, maxLimit is the capacity of the list
+	maxEntries := 1000.0
+	entries := comp.AddVariable("Entries", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+		MaxLimit:        &maxEntries,
+	})
+	entries.SetAttribute(AttributeActual, "0", MutabilityReadOnly, false, false)
+
+	// ItemsPerMessage - maximum idTokens of a SendLocalList
+	items := comp.AddVariable("ItemsPerMessage", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+	})
+	items.SetAttribute(AttributeActual, "100", MutabilityReadOnly, true, true)
+}
+
+// addAuthCacheVariables adds variables for the AuthCacheCtrlr component
+func (dm *DeviceModel) addAuthCacheVariables(comp *ComponentInstance) {
+	// Available - the authorization cache is supported
+	available := comp.AddVariable("Available", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	available.SetAttribute(AttributeActual, "true", MutabilityReadOnly, true, true)
+
+	// Enabled - the authorization cache is used
+	enabled := comp.AddVariable("Enabled", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	enabled.SetAttribute(AttributeActual, "true", MutabilityReadWrite, true, false)
+
+	// LifeTime - time an idToken stays in the cache
+	lifeTime := comp.AddVariable("LifeTime", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+		Unit:            "s",
+	})
+	lifeTime.SetAttribute(AttributeActual, "86400", MutabilityReadWrite, true, false)
+
+	// Policy - entries evicted when the cache is full
+	policy := comp.AddVariable("Policy", "", VariableCharacteristics{
+		DataType:        DataTypeOptionList,
+		SupportsMonitor: false,
+		ValuesList:      "LRU",
+	})
+	policy.SetAttribute(AttributeActual, "LRU", MutabilityReadOnly, true, true)
+
+	// Storage - size of the cache
+	storage := comp.AddVariable("Storage", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+		Unit:            "B",
+	})
+	storage.SetAttribute(AttributeActual, "128000", MutabilityReadOnly, true, true)
 }
 
 // addTxVariables adds variables for the TxCtrlr component
@@ -462,17 +866,17 @@ func (dm *DeviceModel) addTxVariables(comp *ComponentInstance) {
 
 	// TxStartPoint - when to start transaction
 	txStartPoint := comp.AddVariable("TxStartPoint", "", VariableCharacteristics{
-		DataType:        DataTypeOptionList,
+		DataType:        DataTypeMemberList,
 		SupportsMonitor: false,
-		ValuesList:      "Authorized,EVConnected,PowerPathClosed",
+		ValuesList:      "ParkingBayOccupancy,EVConnected,Authorized,DataSigned,PowerPathClosed,EnergyTransfer",
 	})
 	txStartPoint.SetAttribute(AttributeActual, "Authorized", MutabilityReadWrite, true, false)
 
 	// TxStopPoint - when to stop transaction
 	txStopPoint := comp.AddVariable("TxStopPoint", "", VariableCharacteristics{
-		DataType:        DataTypeOptionList,
+		DataType:        DataTypeMemberList,
 		SupportsMonitor: false,
-		ValuesList:      "EVConnected,Authorized,PowerPathClosed,EnergyTransfer",
+		ValuesList:      "ParkingBayOccupancy,EVConnected,Authorized,DataSigned,PowerPathClosed,EnergyTransfer",
 	})
 	txStopPoint.SetAttribute(AttributeActual, "EVConnected", MutabilityReadWrite, true, false)
 }
@@ -498,7 +902,7 @@ func (dm *DeviceModel) addSampledDataVariables(comp *ComponentInstance) {
 	txMeasurands := comp.AddVariable("TxUpdatedMeasurands", "", VariableCharacteristics{
 		DataType:        DataTypeMemberList,
 		SupportsMonitor: false,
-		ValuesList:      "Energy.Active.Import.Register,Power.Active.Import,Current.Import,Voltage",
+		ValuesList:      "Energy.Active.Import.Register,Energy.Active.Export.Register,Power.Active.Import,Power.Active.Export,Current.Import,Voltage",
 	})
 	txMeasurands.SetAttribute(AttributeActual, "Energy.Active.Import.Register,Power.Active.Import", MutabilityReadWrite, true, false)
 
@@ -506,7 +910,7 @@ func (dm *DeviceModel) addSampledDataVariables(comp *ComponentInstance) {
 	txEndMeasurands := comp.AddVariable("TxEndedMeasurands", "", VariableCharacteristics{
 		DataType:        DataTypeMemberList,
 		SupportsMonitor: false,
-		ValuesList:      "Energy.Active.Import.Register,Power.Active.Import,SoC",
+		ValuesList:      "Energy.Active.Import.Register,Energy.Active.Export.Register,Power.Active.Import,SoC",
 	})
 	txEndMeasurands.SetAttribute(AttributeActual, "Energy.Active.Import.Register", MutabilityReadWrite, true, false)
 }
@@ -515,7 +919,7 @@ func (dm *DeviceModel) addSampledDataVariables(comp *ComponentInstance) {
 func (dm *DeviceModel) addClockVariables(comp *ComponentInstance) {
 	// TimeSource - time synchronization source
 	timeSource := comp.AddVariable("TimeSource", "", VariableCharacteristics{
-		DataType:        DataTypeOptionList,
+		DataType:        DataTypeSequenceList,
 		SupportsMonitor: false,
 		ValuesList:      "Heartbeat,SNTP,GPS,RTC",
 	})
@@ -545,12 +949,12 @@ func (dm *DeviceModel) addDeviceDataVariables(comp *ComponentInstance) {
 	})
 	bytesPerMsg.SetAttribute(AttributeActual, "65535", MutabilityReadOnly, false, true)
 
-	// ItemsPerMessage - max items per message
+	// ItemsPerMessage - max items per message, writable to exercise report paging
 	itemsPerMsg := comp.AddVariable("ItemsPerMessage", "", VariableCharacteristics{
 		DataType:        DataTypeInteger,
 		SupportsMonitor: false,
 	})
-	itemsPerMsg.SetAttribute(AttributeActual, "100", MutabilityReadOnly, false, true)
+	itemsPerMsg.SetAttribute(AttributeActual, "100", MutabilityReadWrite, true, false)
 
 	// ConfigurationValueSize - max config value size
 	configSize := comp.AddVariable("ConfigurationValueSize", "", VariableCharacteristics{
@@ -560,6 +964,100 @@ func (dm *DeviceModel) addDeviceDataVariables(comp *ComponentInstance) {
 	configSize.SetAttribute(AttributeActual, "1000", MutabilityReadOnly, false, true)
 }
 
+// addMonitoringVariables adds variables for the MonitoringCtrlr component
+func (dm *DeviceModel) addMonitoringVariables(comp *ComponentInstance) {
+	// Enabled - variable monitoring enabled
+	enabled := comp.AddVariable("Enabled", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	enabled.SetAttribute(AttributeActual, "true", MutabilityReadWrite, true, false)
+
+	// ActiveMonitoringBase - monitors kept by the last SetMonitoringBase
+	base := comp.AddVariable("ActiveMonitoringBase", "", VariableCharacteristics{
+		DataType:        DataTypeOptionList,
+		SupportsMonitor: false,
+		ValuesList:      "All,FactoryDefault,HardWiredOnly",
+	})
+	base.SetAttribute(AttributeActual, string(MonitoringBaseAll), MutabilityReadOnly, true, false)
+
+	// ActiveMonitoringLevel - highest severity reported with NotifyEvent
+	minLevel, maxLevel := 0.0, float64(MaxMonitoringSeverity)
+	level := comp.AddVariable("ActiveMonitoringLevel", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+		MinLimit:        &minLevel,
+		MaxLimit:        &maxLevel,
+	})
+	level.SetAttribute(AttributeActual, strconv.Itoa(MaxMonitoringSeverity), MutabilityReadWrite, true, false)
+}
+
+// addReservationVariables adds variables for the ReservationCtrlr component
+func (dm *DeviceModel) addReservationVariables(comp *ComponentInstance) {
+	// Available - reservations are supported
+	available := comp.AddVariable("Available", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	available.SetAttribute(AttributeActual, "true", MutabilityReadOnly, true, true)
+
+	// Enabled - reservations are accepted
+	enabled := comp.AddVariable("Enabled", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	enabled.SetAttribute(AttributeActual, "true", MutabilityReadWrite, true, false)
+
+	// NonEvseSpecific - reservations without EVSE are accepted
+	nonEvseSpecific := comp.AddVariable("NonEvseSpecific", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	nonEvseSpecific.SetAttribute(AttributeActual, "true", MutabilityReadWrite, true, false)
+}
+
+// addDisplayMessageVariables adds variables for the DisplayMessageCtrlr component
+func (dm *DeviceModel) addDisplayMessageVariables(comp *ComponentInstance) {
+	// Available - display messages are supported
+	available := comp.AddVariable("Available", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	available.SetAttribute(AttributeActual, "true", MutabilityReadOnly, true, true)
+
+	// Enabled - display messages are accepted
+	enabled := comp.AddVariable("Enabled", "", VariableCharacteristics{
+		DataType:        DataTypeBoolean,
+		SupportsMonitor: false,
+	})
+	enabled.SetAttribute(AttributeActual, "true", MutabilityReadWrite, true, false)
+
+	// DisplayMessages - configured messages, maxLimit is the number of messages that can be stored
+	maxMessages := 20.0
+	messages := comp.AddVariable("DisplayMessages", "", VariableCharacteristics{
+		DataType:        DataTypeInteger,
+		SupportsMonitor: false,
+		MaxLimit:        &maxMessages,
+	})
+	messages.SetAttribute(AttributeActual, "0", MutabilityReadOnly, false, false)
+
+	// SupportedFormats - message formats the display can show
+	formats := comp.AddVariable("SupportedFormats", "", VariableCharacteristics{
+		DataType:        DataTypeMemberList,
+		SupportsMonitor: false,
+		ValuesList:      "ASCII,HTML,URI,UTF8",
+	})
+	formats.SetAttribute(AttributeActual, "ASCII,HTML,URI,UTF8", MutabilityReadOnly, true, true)
+
+	// SupportedPriorities - message priorities the display supports
+	priorities := comp.AddVariable("SupportedPriorities", "", VariableCharacteristics{
+		DataType:        DataTypeMemberList,
+		SupportsMonitor: false,
+		ValuesList:      "AlwaysFront,InFront,NormalCycle",
+	})
+	priorities.SetAttribute(AttributeActual, "AlwaysFront,InFront,NormalCycle", MutabilityReadOnly, true, true)
+}
+
 // AddEVSEComponent adds an EVSE component with standard variables
 func (dm *DeviceModel) AddEVSEComponent(evseID int) *ComponentInstance {
 	evse := &EVSE{ID: evseID}
@@ -579,6 +1077,7 @@ func (dm *DeviceModel) AddEVSEComponent(evseID int) *ComponentInstance {
 		ValuesList:      "Available,Occupied,Reserved,Unavailable,Faulted",
 	})
 	availState.SetAttribute(AttributeActual, "Available", MutabilityReadOnly, false, false)
+	availState.addMonitor(MonitorDelta, 0, 7, EventNotificationPreconfiguredMonitor, dm.newMonitorID())
 
 	// Power - max power
 	power := comp.AddVariable("Power", "", VariableCharacteristics{
@@ -589,6 +1088,15 @@ func (dm *DeviceModel) AddEVSEComponent(evseID int) *ComponentInstance {
 	power.SetAttribute(AttributeActual, "22000", MutabilityReadOnly, false, false)
 	power.SetAttribute(AttributeMaxSet, "22000", MutabilityReadOnly, true, true)
 
+	// Temperature - EVSE temperature, overheating is monitored by the hardware
+	temperature := comp.AddVariable("Temperature", "", VariableCharacteristics{
+		DataType:        DataTypeDecimal,
+		SupportsMonitor: true,
+		Unit:            "Celsius",
+	})
+	temperature.SetAttribute(AttributeActual, "25", MutabilityReadOnly, false, false)
+	temperature.addMonitor(MonitorUpperThreshold, 60, 2, EventNotificationHardWiredMonitor, dm.newMonitorID())
+
 	// SupplyPhases - number of phases
 	phases := comp.AddVariable("SupplyPhases", "", VariableCharacteristics{
 		DataType:        DataTypeInteger,
@@ -625,7 +1133,7 @@ func (dm *DeviceModel) AddConnectorComponent(evseID, connectorID int, connectorT
 		SupportsMonitor: false,
 		ValuesList:      "cCCS1,cCCS2,cG105,cTesla,cType1,cType2,s309-1P-16A,s309-1P-32A,s309-3P-16A,s309-3P-32A,sBS1361,sCEE-7-7,sType2,sType3,Other1PhMax16A,Other1PhOver16A,Other3Ph,Pan,wInductive,wResonant,Undetermined,Unknown",
 	})
-	connType.SetAttribute(AttributeActual, connectorType, MutabilityReadOnly, true, true)
+	connType.SetAttribute(AttributeActual, string(ConnectorTypeFromName(connectorType)), MutabilityReadOnly, true, true)
 
 	return comp
 }
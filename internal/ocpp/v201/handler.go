@@ -17,15 +17,44 @@ type Handler struct {
 	OnRequestStartTransaction func(stationID string, req *RequestStartTransactionRequest) (*RequestStartTransactionResponse, error)
 	OnRequestStopTransaction  func(stationID string, req *RequestStopTransactionRequest) (*RequestStopTransactionResponse, error)
 	OnReset                   func(stationID string, req *ResetRequest) (*ResetResponse, error)
+	OnSetNetworkProfile       func(stationID string, req *SetNetworkProfileRequest) (*SetNetworkProfileResponse, error)
 	OnGetVariables            func(stationID string, req *GetVariablesRequest) (*GetVariablesResponse, error)
 	OnSetVariables            func(stationID string, req *SetVariablesRequest) (*SetVariablesResponse, error)
+	OnGetBaseReport           func(stationID string, req *GetBaseReportRequest) (*GetBaseReportResponse, error)
+	OnGetReport               func(stationID string, req *GetReportRequest) (*GetReportResponse, error)
+	OnSetVariableMonitoring   func(stationID string, req *SetVariableMonitoringRequest) (*SetVariableMonitoringResponse, error)
+	OnSetMonitoringBase       func(stationID string, req *SetMonitoringBaseRequest) (*SetMonitoringBaseResponse, error)
+	OnSetMonitoringLevel      func(stationID string, req *SetMonitoringLevelRequest) (*SetMonitoringLevelResponse, error)
+	OnClearVariableMonitoring func(stationID string, req *ClearVariableMonitoringRequest) (*ClearVariableMonitoringResponse, error)
+	OnGetMonitoringReport     func(stationID string, req *GetMonitoringReportRequest) (*GetMonitoringReportResponse, error)
 	OnChangeAvailability      func(stationID string, req *ChangeAvailabilityRequest) (*ChangeAvailabilityResponse, error)
 	OnUnlockConnector         func(stationID string, req *UnlockConnectorRequest) (*UnlockConnectorResponse, error)
 	OnClearCache              func(stationID string, req *ClearCacheRequest) (*ClearCacheResponse, error)
+	OnSendLocalList           func(stationID string, req *SendLocalListRequest) (*SendLocalListResponse, error)
+	OnGetLocalListVersion     func(stationID string, req *GetLocalListVersionRequest) (*GetLocalListVersionResponse, error)
 	OnDataTransfer            func(stationID string, req *DataTransferRequest) (*DataTransferResponse, error)
 	OnTriggerMessage          func(stationID string, req *TriggerMessageRequest) (*TriggerMessageResponse, error)
 	OnGetTransactionStatus    func(stationID string, req *GetTransactionStatusRequest) (*GetTransactionStatusResponse, error)
 
+	// Smart charging callbacks (CSMS → CS)
+	OnSetChargingProfile   func(stationID string, req *SetChargingProfileRequest) (*SetChargingProfileResponse, error)
+	OnClearChargingProfile func(stationID string, req *ClearChargingProfileRequest) (*ClearChargingProfileResponse, error)
+	OnGetCompositeSchedule func(stationID string, req *GetCompositeScheduleRequest) (*GetCompositeScheduleResponse, error)
+
+	// Reservation callbacks (CSMS → CS)
+	OnReserveNow        func(stationID string, req *ReserveNowRequest) (*ReserveNowResponse, error)
+	OnCancelReservation func(stationID string, req *CancelReservationRequest) (*CancelReservationResponse, error)
+
+	// Firmware management callbacks (CSMS → CS)
+	OnUpdateFirmware func(stationID string, req *UpdateFirmwareRequest) (*UpdateFirmwareResponse, error)
+	OnGetLog         func(stationID string, req *GetLogRequest) (*GetLogResponse, error)
+
+	// Display message and cost callbacks (CSMS → CS)
+	OnSetDisplayMessage   func(stationID string, req *SetDisplayMessageRequest) (*SetDisplayMessageResponse, error)
+	OnGetDisplayMessages  func(stationID string, req *GetDisplayMessagesRequest) (*GetDisplayMessagesResponse, error)
+	OnClearDisplayMessage func(stationID string, req *ClearDisplayMessageRequest) (*ClearDisplayMessageResponse, error)
+	OnCostUpdated         func(stationID string, req *CostUpdatedRequest) (*CostUpdatedResponse, error)
+
 	// Certificate management callbacks (CSMS → CS)
 	OnCertificateSigned          func(stationID string, req *CertificateSignedRequest) (*CertificateSignedResponse, error)
 	OnDeleteCertificate          func(stationID string, req *DeleteCertificateRequest) (*DeleteCertificateResponse, error)
@@ -58,22 +87,68 @@ func (h *Handler) HandleCall(stationID string, call *ocpp.Call) (interface{}, er
 		return h.handleRequestStopTransaction(stationID, call)
 	case ActionReset:
 		return h.handleReset(stationID, call)
+	case ActionSetNetworkProfile:
+		return h.handleSetNetworkProfile(stationID, call)
 	case ActionGetVariables:
 		return h.handleGetVariables(stationID, call)
 	case ActionSetVariables:
 		return h.handleSetVariables(stationID, call)
+	case ActionGetBaseReport:
+		return h.handleGetBaseReport(stationID, call)
+	case ActionGetReport:
+		return h.handleGetReport(stationID, call)
+	case ActionSetVariableMonitoring:
+		return h.handleSetVariableMonitoring(stationID, call)
+	case ActionSetMonitoringBase:
+		return h.handleSetMonitoringBase(stationID, call)
+	case ActionSetMonitoringLevel:
+		return h.handleSetMonitoringLevel(stationID, call)
+	case ActionClearVariableMonitoring:
+		return h.handleClearVariableMonitoring(stationID, call)
+	case ActionGetMonitoringReport:
+		return h.handleGetMonitoringReport(stationID, call)
 	case ActionChangeAvailability:
 		return h.handleChangeAvailability(stationID, call)
 	case ActionUnlockConnector:
 		return h.handleUnlockConnector(stationID, call)
 	case ActionClearCache:
 		return h.handleClearCache(stationID, call)
+	case ActionSendLocalList:
+		return h.handleSendLocalList(stationID, call)
+	case ActionGetLocalListVersion:
+		return h.handleGetLocalListVersion(stationID, call)
 	case ActionDataTransfer:
 		return h.handleDataTransfer(stationID, call)
 	case ActionTriggerMessage:
 		return h.handleTriggerMessage(stationID, call)
 	case ActionGetTransactionStatus:
 		return h.handleGetTransactionStatus(stationID, call)
+	// Smart charging
+	case ActionSetChargingProfile:
+		return h.handleSetChargingProfile(stationID, call)
+	case ActionClearChargingProfile:
+		return h.handleClearChargingProfile(stationID, call)
+	case ActionGetCompositeSchedule:
+		return h.handleGetCompositeSchedule(stationID, call)
+	// Reservation
+	case ActionReserveNow:
+		return h.handleReserveNow(stationID, call)
+	case ActionCancelReservation:
+		return h.handleCancelReservation(stationID, call)
+	// Firmware management
+	case ActionUpdateFirmware:
+		return h.handleUpdateFirmware(stationID, call)
+	case ActionGetLog:
+		return h.handleGetLog(stationID, call)
+	// Display messages and cost
+	case ActionSetDisplayMessage:
+		return h.handleSetDisplayMessage(stationID, call)
+	case ActionGetDisplayMessages:
+		return h.handleGetDisplayMessages(stationID, call)
+	case ActionClearDisplayMessage:
+		return h.handleClearDisplayMessage(stationID, call)
+	case ActionCostUpdated:
+		return h.handleCostUpdated(stationID, call)
 	// Certificate management
 	case ActionCertificateSigned:
 		return h.handleCertificateSigned(stationID, call)
@@ -132,6 +207,20 @@ func (h *Handler) handleReset(stationID string, call *ocpp.Call) (*ResetResponse
 	return h.OnReset(stationID, &req)
 }
 
+// handleSetNetworkProfile handles SetNetworkProfile request
+func (h *Handler) handleSetNetworkProfile(stationID string, call *ocpp.Call) (*SetNetworkProfileResponse, error) {
+	var req SetNetworkProfileRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetNetworkProfile request: %w", err)
+	}
+
+	if h.OnSetNetworkProfile == nil {
+		return &SetNetworkProfileResponse{Status: SetNetworkProfileStatusRejected}, nil
+	}
+
+	return h.OnSetNetworkProfile(stationID, &req)
+}
+
 // handleGetVariables handles GetVariables request
 func (h *Handler) handleGetVariables(stationID string, call *ocpp.Call) (*GetVariablesResponse, error) {
 	var req GetVariablesRequest
@@ -178,6 +267,119 @@ func (h *Handler) handleSetVariables(stationID string, call *ocpp.Call) (*SetVar
 	return h.OnSetVariables(stationID, &req)
 }
 
+// handleGetBaseReport handles GetBaseReport request
+func (h *Handler) handleGetBaseReport(stationID string, call *ocpp.Call) (*GetBaseReportResponse, error) {
+	var req GetBaseReportRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetBaseReport request: %w", err)
+	}
+
+	if h.OnGetBaseReport == nil {
+		return &GetBaseReportResponse{Status: GenericDeviceModelStatusNotSupported}, nil
+	}
+
+	return h.OnGetBaseReport(stationID, &req)
+}
+
+// handleGetReport handles GetReport request
+func (h *Handler) handleGetReport(stationID string, call *ocpp.Call) (*GetReportResponse, error) {
+	var req GetReportRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetReport request: %w", err)
+	}
+
+	if h.OnGetReport == nil {
+		return &GetReportResponse{Status: GenericDeviceModelStatusNotSupported}, nil
+	}
+
+	return h.OnGetReport(stationID, &req)
+}
+
+// handleSetVariableMonitoring handles SetVariableMonitoring request
+func (h *Handler) handleSetVariableMonitoring(stationID string, call *ocpp.Call) (*SetVariableMonitoringResponse, error) {
+	var req SetVariableMonitoringRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetVariableMonitoring request: %w", err)
+	}
+
+	if h.OnSetVariableMonitoring == nil {
+		results := make([]SetMonitoringResult, 0, len(req.SetMonitoringData))
+		for _, data := range req.SetMonitoringData {
+			results = append(results, SetMonitoringResult{
+				Id:        data.Id,
+				Status:    SetMonitoringStatusRejected,
+				Type:      data.Type,
+				Severity:  data.Severity,
+				Component: data.Component,
+				Variable:  data.Variable,
+			})
+		}
+		return &SetVariableMonitoringResponse{SetMonitoringResult: results}, nil
+	}
+
+	return h.OnSetVariableMonitoring(stationID, &req)
+}
+
+// handleSetMonitoringBase handles SetMonitoringBase request
+func (h *Handler) handleSetMonitoringBase(stationID string, call *ocpp.Call) (*SetMonitoringBaseResponse, error) {
+	var req SetMonitoringBaseRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetMonitoringBase request: %w", err)
+	}
+
+	if h.OnSetMonitoringBase == nil {
+		return &SetMonitoringBaseResponse{Status: GenericDeviceModelStatusNotSupported}, nil
+	}
+
+	return h.OnSetMonitoringBase(stationID, &req)
+}
+
+// handleSetMonitoringLevel handles SetMonitoringLevel request
+func (h *Handler) handleSetMonitoringLevel(stationID string, call *ocpp.Call) (*SetMonitoringLevelResponse, error) {
+	var req SetMonitoringLevelRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetMonitoringLevel request: %w", err)
+	}
+
+	if h.OnSetMonitoringLevel == nil {
+		return &SetMonitoringLevelResponse{Status: GenericStatusRejected}, nil
+	}
+
+	return h.OnSetMonitoringLevel(stationID, &req)
+}
+
+// handleClearVariableMonitoring handles ClearVariableMonitoring request
+func (h *Handler) handleClearVariableMonitoring(stationID string, call *ocpp.Call) (*ClearVariableMonitoringResponse, error) {
+	var req ClearVariableMonitoringRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ClearVariableMonitoring request: %w", err)
+	}
+
+	if h.OnClearVariableMonitoring == nil {
+		results := make([]ClearMonitoringResult, 0, len(req.Id))
+		for _, id := range req.Id {
+			results = append(results, ClearMonitoringResult{Id: id, Status: ClearMonitoringStatusNotFound})
+		}
+		return &ClearVariableMonitoringResponse{ClearMonitoringResult: results}, nil
+	}
+
+	return h.OnClearVariableMonitoring(stationID, &req)
+}
+
+// handleGetMonitoringReport handles GetMonitoringReport request
+func (h *Handler) handleGetMonitoringReport(stationID string, call *ocpp.Call) (*GetMonitoringReportResponse, error) {
+	var req GetMonitoringReportRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetMonitoringReport request: %w", err)
+	}
+
+	if h.OnGetMonitoringReport == nil {
+		return &GetMonitoringReportResponse{Status: GenericDeviceModelStatusNotSupported}, nil
+	}
+
+	return h.OnGetMonitoringReport(stationID, &req)
+}
+
 // handleChangeAvailability handles ChangeAvailability request
 func (h *Handler) handleChangeAvailability(stationID string, call *ocpp.Call) (*ChangeAvailabilityResponse, error) {
 	var req ChangeAvailabilityRequest
@@ -220,6 +422,34 @@ func (h *Handler) handleClearCache(stationID string, call *ocpp.Call) (*ClearCac
 	return h.OnClearCache(stationID, &req)
 }
 
+// handleSendLocalList handles SendLocalList request
+func (h *Handler) handleSendLocalList(stationID string, call *ocpp.Call) (*SendLocalListResponse, error) {
+	var req SendLocalListRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SendLocalList request: %w", err)
+	}
+
+	if h.OnSendLocalList == nil {
+		return &SendLocalListResponse{Status: SendLocalListStatusFailed}, nil
+	}
+
+	return h.OnSendLocalList(stationID, &req)
+}
+
+// handleGetLocalListVersion handles GetLocalListVersion request
+func (h *Handler) handleGetLocalListVersion(stationID string, call *ocpp.Call) (*GetLocalListVersionResponse, error) {
+	var req GetLocalListVersionRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetLocalListVersion request: %w", err)
+	}
+
+	if h.OnGetLocalListVersion == nil {
+		return &GetLocalListVersionResponse{VersionNumber: 0}, nil
+	}
+
+	return h.OnGetLocalListVersion(stationID, &req)
+}
+
 // handleDataTransfer handles DataTransfer request
 func (h *Handler) handleDataTransfer(stationID string, call *ocpp.Call) (*DataTransferResponse, error) {
 	var req DataTransferRequest
@@ -262,6 +492,166 @@ func (h *Handler) handleGetTransactionStatus(stationID string, call *ocpp.Call)
 	return h.OnGetTransactionStatus(stationID, &req)
 }
 
+// ==================== Smart Charging Handlers (CSMS → CS) ====================
+
+// handleSetChargingProfile handles SetChargingProfile request
+func (h *Handler) handleSetChargingProfile(stationID string, call *ocpp.Call) (*SetChargingProfileResponse, error) {
+	var req SetChargingProfileRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetChargingProfile request: %w", err)
+	}
+
+	if h.OnSetChargingProfile == nil {
+		return &SetChargingProfileResponse{Status: ChargingProfileStatusRejected}, nil
+	}
+
+	return h.OnSetChargingProfile(stationID, &req)
+}
+
+// handleClearChargingProfile handles ClearChargingProfile request
+func (h *Handler) handleClearChargingProfile(stationID string, call *ocpp.Call) (*ClearChargingProfileResponse, error) {
+	var req ClearChargingProfileRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ClearChargingProfile request: %w", err)
+	}
+
+	if h.OnClearChargingProfile == nil {
+		return &ClearChargingProfileResponse{Status: ClearChargingProfileStatusUnknown}, nil
+	}
+
+	return h.OnClearChargingProfile(stationID, &req)
+}
+
+// handleGetCompositeSchedule handles GetCompositeSchedule request
+func (h *Handler) handleGetCompositeSchedule(stationID string, call *ocpp.Call) (*GetCompositeScheduleResponse, error) {
+	var req GetCompositeScheduleRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetCompositeSchedule request: %w", err)
+	}
+
+	if h.OnGetCompositeSchedule == nil {
+		return &GetCompositeScheduleResponse{Status: GenericStatusRejected}, nil
+	}
+
+	return h.OnGetCompositeSchedule(stationID, &req)
+}
+
+// ==================== Reservation Handlers (CSMS → CS) ====================
+
+// handleReserveNow handles ReserveNow request
+func (h *Handler) handleReserveNow(stationID string, call *ocpp.Call) (*ReserveNowResponse, error) {
+	var req ReserveNowRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ReserveNow request: %w", err)
+	}
+
+	if h.OnReserveNow == nil {
+		return &ReserveNowResponse{Status: ReserveNowStatusRejected}, nil
+	}
+
+	return h.OnReserveNow(stationID, &req)
+}
+
+// handleCancelReservation handles CancelReservation request
+func (h *Handler) handleCancelReservation(stationID string, call *ocpp.Call) (*CancelReservationResponse, error) {
+	var req CancelReservationRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CancelReservation request: %w", err)
+	}
+
+	if h.OnCancelReservation == nil {
+		return &CancelReservationResponse{Status: CancelReservationStatusRejected}, nil
+	}
+
+	return h.OnCancelReservation(stationID, &req)
+}
+
+// handleUpdateFirmware handles UpdateFirmware request
+func (h *Handler) handleUpdateFirmware(stationID string, call *ocpp.Call) (*UpdateFirmwareResponse, error) {
+	var req UpdateFirmwareRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal UpdateFirmware request: %w", err)
+	}
+
+	if h.OnUpdateFirmware == nil {
+		return &UpdateFirmwareResponse{Status: UpdateFirmwareStatusRejected}, nil
+	}
+
+	return h.OnUpdateFirmware(stationID, &req)
+}
+
+// handleGetLog handles GetLog request
+func (h *Handler) handleGetLog(stationID string, call *ocpp.Call) (*GetLogResponse, error) {
+	var req GetLogRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetLog request: %w", err)
+	}
+
+	if h.OnGetLog == nil {
+		return &GetLogResponse{Status: LogStatusRejected}, nil
+	}
+
+	return h.OnGetLog(stationID, &req)
+}
+
+// ==================== Display Message Handlers (CSMS → CS) ====================
+
+// handleSetDisplayMessage handles SetDisplayMessage request
+func (h *Handler) handleSetDisplayMessage(stationID string, call *ocpp.Call) (*SetDisplayMessageResponse, error) {
+	var req SetDisplayMessageRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetDisplayMessage request: %w", err)
+	}
+
+	if h.OnSetDisplayMessage == nil {
+		return &SetDisplayMessageResponse{Status: DisplayMessageStatusRejected}, nil
+	}
+
+	return h.OnSetDisplayMessage(stationID, &req)
+}
+
+// handleGetDisplayMessages handles GetDisplayMessages request
+func (h *Handler) handleGetDisplayMessages(stationID string, call *ocpp.Call) (*GetDisplayMessagesResponse, error) {
+	var req GetDisplayMessagesRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetDisplayMessages request: %w", err)
+	}
+
+	if h.OnGetDisplayMessages == nil {
+		return &GetDisplayMessagesResponse{Status: GetDisplayMessagesStatusUnknown}, nil
+	}
+
+	return h.OnGetDisplayMessages(stationID, &req)
+}
+
+// handleClearDisplayMessage handles ClearDisplayMessage request
+func (h *Handler) handleClearDisplayMessage(stationID string, call *ocpp.Call) (*ClearDisplayMessageResponse, error) {
+	var req ClearDisplayMessageRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ClearDisplayMessage request: %w", err)
+	}
+
+	if h.OnClearDisplayMessage == nil {
+		return &ClearDisplayMessageResponse{Status: ClearMessageStatusUnknown}, nil
+	}
+
+	return h.OnClearDisplayMessage(stationID, &req)
+}
+
+// handleCostUpdated handles CostUpdated request
+func (h *Handler) handleCostUpdated(stationID string, call *ocpp.Call) (*CostUpdatedResponse, error) {
+	var req CostUpdatedRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CostUpdated request: %w", err)
+	}
+
+	if h.OnCostUpdated == nil {
+		return &CostUpdatedResponse{}, nil
+	}
+
+	return h.OnCostUpdated(stationID, &req)
+}
+
 // ==================== Certificate Management Handlers (CSMS → CS) ====================
 
 // handleCertificateSigned handles CertificateSigned request
@@ -511,6 +901,48 @@ func (h *Handler) SendNotifyEvent(stationID string, req *NotifyEventRequest) (*o
 	return call, nil
 }
 
+// SendFirmwareStatusNotification sends a FirmwareStatusNotification request
+func (h *Handler) SendFirmwareStatusNotification(stationID string, req *FirmwareStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionFirmwareStatusNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FirmwareStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal FirmwareStatusNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send FirmwareStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// SendLogStatusNotification sends a LogStatusNotification request
+func (h *Handler) SendLogStatusNotification(stationID string, req *LogStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionLogStatusNotification), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LogStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal LogStatusNotification: %w", err)
+	}
+
+	if h.SendMessage != nil {
+		if err := h.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send LogStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
 // SendDataTransfer sends a DataTransfer request
 func (h *Handler) SendDataTransfer(stationID string, req *DataTransferRequest) (*ocpp.Call, error) {
 	call, err := ocpp.NewCall(string(ActionDataTransfer), req)
@@ -653,6 +1085,13 @@ func (h *Handler) HandleCallResult(stationID string, result *ocpp.CallResult, or
 		}
 		return &resp, nil
 
+	case ActionNotifyReport:
+		var resp NotifyReportResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal NotifyReport response: %w", err)
+		}
+		return &resp, nil
+
 	case ActionNotifyEvent:
 		var resp NotifyEventResponse
 		if err := json.Unmarshal(result.Payload, &resp); err != nil {
@@ -660,6 +1099,13 @@ func (h *Handler) HandleCallResult(stationID string, result *ocpp.CallResult, or
 		}
 		return &resp, nil
 
+	case ActionNotifyMonitoringReport:
+		var resp NotifyMonitoringReportResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal NotifyMonitoringReport response: %w", err)
+		}
+		return &resp, nil
+
 	case ActionDataTransfer:
 		var resp DataTransferResponse
 		if err := json.Unmarshal(result.Payload, &resp); err != nil {
@@ -667,6 +1113,34 @@ func (h *Handler) HandleCallResult(stationID string, result *ocpp.CallResult, or
 		}
 		return &resp, nil
 
+	case ActionFirmwareStatusNotification:
+		var resp FirmwareStatusNotificationResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal FirmwareStatusNotification response: %w", err)
+		}
+		return &resp, nil
+
+	case ActionLogStatusNotification:
+		var resp LogStatusNotificationResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal LogStatusNotification response: %w", err)
+		}
+		return &resp, nil
+
+	case ActionNotifyDisplayMessages:
+		var resp NotifyDisplayMessagesResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal NotifyDisplayMessages response: %w", err)
+		}
+		return &resp, nil
+
+	case ActionReservationStatusUpdate:
+		var resp ReservationStatusUpdateResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ReservationStatusUpdate response: %w", err)
+		}
+		return &resp, nil
+
 	// Certificate management responses
 	case ActionSignCertificate:
 		var resp SignCertificateResponse
@@ -1,5 +1,7 @@
 package v201
 
+import "encoding/json"
+
 // =========== BootNotification ===========
 
 // BootNotificationRequest represents a BootNotification request (CS → CSMS)
@@ -83,6 +85,10 @@ type TransactionEventRequest struct {
 	EVSE               *EVSE                `json:"evse,omitempty"`
 	IdToken            *IdToken             `json:"idToken,omitempty"`
 	MeterValue         []MeterValue         `json:"meterValue,omitempty"`
+
+	// CostDetails is the cost calculated by an OCPP 2.1 station from its tariff
+	// (v21.CostDetailsType), 2.0.1 stations leave it empty
+	CostDetails json.RawMessage `json:"costDetails,omitempty"`
 }
 
 // TransactionEventResponse represents a TransactionEvent response (CSMS → CS)
@@ -119,15 +125,15 @@ type RequestStartTransactionRequest struct {
 
 // ChargingProfile represents a charging profile
 type ChargingProfile struct {
-	Id                     int                `json:"id"`
-	StackLevel             int                `json:"stackLevel"`
-	ChargingProfilePurpose string             `json:"chargingProfilePurpose"`   // ChargePointMaxProfile, TxDefaultProfile, TxProfile
-	ChargingProfileKind    string             `json:"chargingProfileKind"`      // Absolute, Recurring, Relative
-	RecurrencyKind         string             `json:"recurrencyKind,omitempty"` // Daily, Weekly
-	ValidFrom              *DateTime          `json:"validFrom,omitempty"`
-	ValidTo                *DateTime          `json:"validTo,omitempty"`
-	ChargingSchedule       []ChargingSchedule `json:"chargingSchedule"`
-	TransactionId          string             `json:"transactionId,omitempty"`
+	Id                     int                        `json:"id"`
+	StackLevel             int                        `json:"stackLevel"`
+	ChargingProfilePurpose ChargingProfilePurposeType `json:"chargingProfilePurpose"`
+	ChargingProfileKind    ChargingProfileKindType    `json:"chargingProfileKind"`
+	RecurrencyKind         RecurrencyKindType         `json:"recurrencyKind,omitempty"`
+	ValidFrom              *DateTime                  `json:"validFrom,omitempty"`
+	ValidTo                *DateTime                  `json:"validTo,omitempty"`
+	ChargingSchedule       []ChargingSchedule         `json:"chargingSchedule"`
+	TransactionId          string                     `json:"transactionId,omitempty"`
 }
 
 // ChargingSchedule represents a charging schedule
@@ -135,7 +141,7 @@ type ChargingSchedule struct {
 	Id                     int                      `json:"id"`
 	StartSchedule          *DateTime                `json:"startSchedule,omitempty"`
 	Duration               *int                     `json:"duration,omitempty"`
-	ChargingRateUnit       string                   `json:"chargingRateUnit"` // W, A
+	ChargingRateUnit       ChargingRateUnitType     `json:"chargingRateUnit"`
 	MinChargingRate        *float64                 `json:"minChargingRate,omitempty"`
 	ChargingSchedulePeriod []ChargingSchedulePeriod `json:"chargingSchedulePeriod"`
 	SalesTariff            *SalesTariff             `json:"salesTariff,omitempty"`
@@ -227,6 +233,51 @@ type SetVariablesResponse struct {
 	SetVariableResult []SetVariableResult `json:"setVariableResult"`
 }
 
+// =========== GetBaseReport ===========
+
+// GetBaseReportRequest represents a GetBaseReport request (CSMS → CS)
+type GetBaseReportRequest struct {
+	RequestId  int            `json:"requestId"`
+	ReportBase ReportBaseType `json:"reportBase"`
+}
+
+// GetBaseReportResponse represents a GetBaseReport response (CS → CSMS)
+type GetBaseReportResponse struct {
+	Status     GenericDeviceModelStatusType `json:"status"`
+	StatusInfo *StatusInfo                  `json:"statusInfo,omitempty"`
+}
+
+// =========== GetReport ===========
+
+// GetReportRequest represents a GetReport request (CSMS → CS)
+type GetReportRequest struct {
+	RequestId         int                      `json:"requestId"`
+	ComponentCriteria []ComponentCriterionType `json:"componentCriteria,omitempty"`
+	ComponentVariable []ComponentVariable      `json:"componentVariable,omitempty"`
+}
+
+// GetReportResponse represents a GetReport response (CS → CSMS)
+type GetReportResponse struct {
+	Status     GenericDeviceModelStatusType `json:"status"`
+	StatusInfo *StatusInfo                  `json:"statusInfo,omitempty"`
+}
+
+// =========== NotifyReport ===========
+
+// NotifyReportRequest represents a NotifyReport request (CS → CSMS)
+type NotifyReportRequest struct {
+	RequestId   int          `json:"requestId"`
+	GeneratedAt DateTime     `json:"generatedAt"`
+	Tbc         bool         `json:"tbc,omitempty"` // To Be Continued
+	SeqNo       int          `json:"seqNo"`
+	ReportData  []ReportData `json:"reportData,omitempty"`
+}
+
+// NotifyReportResponse represents a NotifyReport response (CSMS → CS)
+type NotifyReportResponse struct {
+	// Empty payload
+}
+
 // =========== Reset ===========
 
 // ResetRequest represents a Reset request (CSMS → CS)
@@ -241,6 +292,53 @@ type ResetResponse struct {
 	StatusInfo *StatusInfo     `json:"statusInfo,omitempty"`
 }
 
+// =========== SetNetworkProfile ===========
+
+// VPN describes the VPN a network connection profile connects through
+type VPN struct {
+	Server   string `json:"server"`
+	User     string `json:"user"`
+	Group    string `json:"group,omitempty"`
+	Password string `json:"password"`
+	Key      string `json:"key"`
+	Type     string `json:"type"` // IKEv2, IPSec, L2TP, PPTP
+}
+
+// APN describes the mobile access point of a network connection profile
+type APN struct {
+	APN                     string `json:"apn"`
+	APNUserName             string `json:"apnUserName,omitempty"`
+	APNPassword             string `json:"apnPassword,omitempty"`
+	SimPin                  *int   `json:"simPin,omitempty"`
+	PreferredNetwork        string `json:"preferredNetwork,omitempty"`
+	UseOnlyPreferredNetwork bool   `json:"useOnlyPreferredNetwork,omitempty"`
+	APNAuthentication       string `json:"apnAuthentication"` // CHAP, NONE, PAP, AUTO
+}
+
+// NetworkConnectionProfile describes how the station connects to a CSMS
+type NetworkConnectionProfile struct {
+	OcppVersion     OCPPVersionType   `json:"ocppVersion"`
+	OcppTransport   OCPPTransportType `json:"ocppTransport"`
+	OcppCsmsUrl     string            `json:"ocppCsmsUrl"`
+	MessageTimeout  int               `json:"messageTimeout"`
+	SecurityProfile int               `json:"securityProfile"`
+	OcppInterface   OCPPInterfaceType `json:"ocppInterface"`
+	VPN             *VPN              `json:"vpn,omitempty"`
+	APN             *APN              `json:"apn,omitempty"`
+}
+
+// SetNetworkProfileRequest represents a SetNetworkProfile request (CSMS → CS)
+type SetNetworkProfileRequest struct {
+	ConfigurationSlot int                      `json:"configurationSlot"`
+	ConnectionData    NetworkConnectionProfile `json:"connectionData"`
+}
+
+// SetNetworkProfileResponse represents a SetNetworkProfile response (CS → CSMS)
+type SetNetworkProfileResponse struct {
+	Status     SetNetworkProfileStatusType `json:"status"`
+	StatusInfo *StatusInfo                 `json:"statusInfo,omitempty"`
+}
+
 // =========== ChangeAvailability ===========
 
 // ChangeAvailabilityRequest represents a ChangeAvailability request (CSMS → CS)
@@ -282,6 +380,33 @@ type ClearCacheResponse struct {
 	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
 }
 
+// =========== SendLocalList ===========
+
+// SendLocalListRequest represents a SendLocalList request (CSMS → CS)
+type SendLocalListRequest struct {
+	VersionNumber          int                 `json:"versionNumber"`
+	UpdateType             UpdateType          `json:"updateType"`
+	LocalAuthorizationList []AuthorizationData `json:"localAuthorizationList,omitempty"`
+}
+
+// SendLocalListResponse represents a SendLocalList response (CS → CSMS)
+type SendLocalListResponse struct {
+	Status     SendLocalListStatusType `json:"status"`
+	StatusInfo *StatusInfo             `json:"statusInfo,omitempty"`
+}
+
+// =========== GetLocalListVersion ===========
+
+// GetLocalListVersionRequest represents a GetLocalListVersion request (CSMS → CS)
+type GetLocalListVersionRequest struct {
+	// Empty payload
+}
+
+// GetLocalListVersionResponse represents a GetLocalListVersion response (CS → CSMS)
+type GetLocalListVersionResponse struct {
+	VersionNumber int `json:"versionNumber"`
+}
+
 // =========== DataTransfer ===========
 
 // DataTransferRequest represents a DataTransfer request (bidirectional)
@@ -338,18 +463,19 @@ type NotifyEventRequest struct {
 
 // EventData represents event data
 type EventData struct {
-	EventId               int       `json:"eventId"`
-	Timestamp             DateTime  `json:"timestamp"`
-	Trigger               string    `json:"trigger"` // Alerting, Delta, Periodic
-	Cause                 *int      `json:"cause,omitempty"`
-	ActualValue           string    `json:"actualValue"`
-	TechCode              string    `json:"techCode,omitempty"`
-	TechInfo              string    `json:"techInfo,omitempty"`
-	Cleared               *bool     `json:"cleared,omitempty"`
-	TransactionId         string    `json:"transactionId,omitempty"`
-	Component             Component `json:"component"`
-	Variable              Variable  `json:"variable"`
-	EventNotificationType string    `json:"eventNotificationType"` // HardWiredNotification, HardWiredMonitor, PreconfiguredMonitor, CustomMonitor
+	EventId               int                   `json:"eventId"`
+	Timestamp             DateTime              `json:"timestamp"`
+	Trigger               EventTriggerType      `json:"trigger"`
+	Cause                 *int                  `json:"cause,omitempty"`
+	ActualValue           string                `json:"actualValue"`
+	TechCode              string                `json:"techCode,omitempty"`
+	TechInfo              string                `json:"techInfo,omitempty"`
+	Cleared               *bool                 `json:"cleared,omitempty"`
+	TransactionId         string                `json:"transactionId,omitempty"`
+	VariableMonitoringId  *int                  `json:"variableMonitoringId,omitempty"`
+	Component             Component             `json:"component"`
+	Variable              Variable              `json:"variable"`
+	EventNotificationType EventNotificationType `json:"eventNotificationType"`
 }
 
 // NotifyEventResponse represents a NotifyEvent response (CSMS → CS)
@@ -357,6 +483,87 @@ type NotifyEventResponse struct {
 	// Empty payload
 }
 
+// =========== SetVariableMonitoring ===========
+
+// SetVariableMonitoringRequest represents a SetVariableMonitoring request (CSMS → CS)
+type SetVariableMonitoringRequest struct {
+	SetMonitoringData []SetMonitoringData `json:"setMonitoringData"`
+}
+
+// SetVariableMonitoringResponse represents a SetVariableMonitoring response (CS → CSMS)
+type SetVariableMonitoringResponse struct {
+	SetMonitoringResult []SetMonitoringResult `json:"setMonitoringResult"`
+}
+
+// =========== SetMonitoringBase ===========
+
+// SetMonitoringBaseRequest represents a SetMonitoringBase request (CSMS → CS)
+type SetMonitoringBaseRequest struct {
+	MonitoringBase MonitoringBaseType `json:"monitoringBase"`
+}
+
+// SetMonitoringBaseResponse represents a SetMonitoringBase response (CS → CSMS)
+type SetMonitoringBaseResponse struct {
+	Status     GenericDeviceModelStatusType `json:"status"`
+	StatusInfo *StatusInfo                  `json:"statusInfo,omitempty"`
+}
+
+// =========== SetMonitoringLevel ===========
+
+// SetMonitoringLevelRequest represents a SetMonitoringLevel request (CSMS → CS)
+type SetMonitoringLevelRequest struct {
+	Severity int `json:"severity"`
+}
+
+// SetMonitoringLevelResponse represents a SetMonitoringLevel response (CS → CSMS)
+type SetMonitoringLevelResponse struct {
+	Status     GenericStatusType `json:"status"`
+	StatusInfo *StatusInfo       `json:"statusInfo,omitempty"`
+}
+
+// =========== ClearVariableMonitoring ===========
+
+// ClearVariableMonitoringRequest represents a ClearVariableMonitoring request (CSMS → CS)
+type ClearVariableMonitoringRequest struct {
+	Id []int `json:"id"`
+}
+
+// ClearVariableMonitoringResponse represents a ClearVariableMonitoring response (CS → CSMS)
+type ClearVariableMonitoringResponse struct {
+	ClearMonitoringResult []ClearMonitoringResult `json:"clearMonitoringResult"`
+}
+
+// =========== GetMonitoringReport ===========
+
+// GetMonitoringReportRequest represents a GetMonitoringReport request (CSMS → CS)
+type GetMonitoringReportRequest struct {
+	RequestId          int                       `json:"requestId"`
+	MonitoringCriteria []MonitoringCriterionType `json:"monitoringCriteria,omitempty"`
+	ComponentVariable  []ComponentVariable       `json:"componentVariable,omitempty"`
+}
+
+// GetMonitoringReportResponse represents a GetMonitoringReport response (CS → CSMS)
+type GetMonitoringReportResponse struct {
+	Status     GenericDeviceModelStatusType `json:"status"`
+	StatusInfo *StatusInfo                  `json:"statusInfo,omitempty"`
+}
+
+// =========== NotifyMonitoringReport ===========
+
+// NotifyMonitoringReportRequest represents a NotifyMonitoringReport request (CS → CSMS)
+type NotifyMonitoringReportRequest struct {
+	RequestId   int              `json:"requestId"`
+	Tbc         bool             `json:"tbc,omitempty"` // To Be Continued
+	SeqNo       int              `json:"seqNo"`
+	GeneratedAt DateTime         `json:"generatedAt"`
+	Monitor     []MonitoringData `json:"monitor,omitempty"`
+}
+
+// NotifyMonitoringReportResponse represents a NotifyMonitoringReport response (CSMS → CS)
+type NotifyMonitoringReportResponse struct {
+	// Empty payload
+}
+
 // =========== GetTransactionStatus ===========
 
 // GetTransactionStatusRequest represents a GetTransactionStatus request (CSMS → CS)
@@ -370,6 +577,248 @@ type GetTransactionStatusResponse struct {
 	MessagesInQueue  bool  `json:"messagesInQueue"`
 }
 
+// =========== SetChargingProfile ===========
+
+// SetChargingProfileRequest represents a SetChargingProfile request (CSMS → CS)
+type SetChargingProfileRequest struct {
+	EvseId          int             `json:"evseId"`
+	ChargingProfile ChargingProfile `json:"chargingProfile"`
+}
+
+// SetChargingProfileResponse represents a SetChargingProfile response (CS → CSMS)
+type SetChargingProfileResponse struct {
+	Status     ChargingProfileStatusType `json:"status"`
+	StatusInfo *StatusInfo               `json:"statusInfo,omitempty"`
+}
+
+// =========== ClearChargingProfile ===========
+
+// ClearChargingProfileRequest represents a ClearChargingProfile request (CSMS → CS)
+type ClearChargingProfileRequest struct {
+	ChargingProfileId       *int                  `json:"chargingProfileId,omitempty"`
+	ChargingProfileCriteria *ClearChargingProfile `json:"chargingProfileCriteria,omitempty"`
+}
+
+// ClearChargingProfile represents the criteria of the charging profiles to clear
+type ClearChargingProfile struct {
+	EvseId                 *int                       `json:"evseId,omitempty"`
+	ChargingProfilePurpose ChargingProfilePurposeType `json:"chargingProfilePurpose,omitempty"`
+	StackLevel             *int                       `json:"stackLevel,omitempty"`
+}
+
+// ClearChargingProfileResponse represents a ClearChargingProfile response (CS → CSMS)
+type ClearChargingProfileResponse struct {
+	Status     ClearChargingProfileStatusType `json:"status"`
+	StatusInfo *StatusInfo                    `json:"statusInfo,omitempty"`
+}
+
+// =========== GetCompositeSchedule ===========
+
+// GetCompositeScheduleRequest represents a GetCompositeSchedule request (CSMS → CS)
+type GetCompositeScheduleRequest struct {
+	Duration         int                  `json:"duration"`
+	ChargingRateUnit ChargingRateUnitType `json:"chargingRateUnit,omitempty"`
+	EvseId           int                  `json:"evseId"`
+}
+
+// GetCompositeScheduleResponse represents a GetCompositeSchedule response (CS → CSMS)
+type GetCompositeScheduleResponse struct {
+	Status     GenericStatusType  `json:"status"`
+	StatusInfo *StatusInfo        `json:"statusInfo,omitempty"`
+	Schedule   *CompositeSchedule `json:"schedule,omitempty"`
+}
+
+// CompositeSchedule represents the combined charging schedule of an EVSE
+type CompositeSchedule struct {
+	EvseId                 int                      `json:"evseId"`
+	Duration               int                      `json:"duration"`
+	ScheduleStart          DateTime                 `json:"scheduleStart"`
+	ChargingRateUnit       ChargingRateUnitType     `json:"chargingRateUnit"`
+	ChargingSchedulePeriod []ChargingSchedulePeriod `json:"chargingSchedulePeriod"`
+}
+
+// =========== ReserveNow ===========
+
+// ReserveNowRequest represents a ReserveNow request (CSMS → CS). Without evseId any EVSE with
+// a connector of the requested type can be used.
+type ReserveNowRequest struct {
+	Id             int            `json:"id"`
+	ExpiryDateTime DateTime       `json:"expiryDateTime"`
+	ConnectorType  *ConnectorType `json:"connectorType,omitempty"`
+	EvseId         *int           `json:"evseId,omitempty"`
+	IdToken        IdToken        `json:"idToken"`
+	GroupIdToken   *IdToken       `json:"groupIdToken,omitempty"`
+}
+
+// ReserveNowResponse represents a ReserveNow response (CS → CSMS)
+type ReserveNowResponse struct {
+	Status     ReserveNowStatusType `json:"status"`
+	StatusInfo *StatusInfo          `json:"statusInfo,omitempty"`
+}
+
+// =========== CancelReservation ===========
+
+// CancelReservationRequest represents a CancelReservation request (CSMS → CS)
+type CancelReservationRequest struct {
+	ReservationId int `json:"reservationId"`
+}
+
+// CancelReservationResponse represents a CancelReservation response (CS → CSMS)
+type CancelReservationResponse struct {
+	Status     CancelReservationStatusType `json:"status"`
+	StatusInfo *StatusInfo                 `json:"statusInfo,omitempty"`
+}
+
+// =========== ReservationStatusUpdate ===========
+
+// ReservationStatusUpdateRequest represents a ReservationStatusUpdate request (CS → CSMS)
+type ReservationStatusUpdateRequest struct {
+	ReservationId           int                         `json:"reservationId"`
+	ReservationUpdateStatus ReservationUpdateStatusType `json:"reservationUpdateStatus"`
+}
+
+// ReservationStatusUpdateResponse represents a ReservationStatusUpdate response (CSMS → CS)
+type ReservationStatusUpdateResponse struct{}
+
+// =========== UpdateFirmware ===========
+
+// Firmware describes the firmware image of an UpdateFirmware request
+type Firmware struct {
+	Location           string    `json:"location"`
+	RetrieveDateTime   DateTime  `json:"retrieveDateTime"`
+	InstallDateTime    *DateTime `json:"installDateTime,omitempty"`
+	SigningCertificate string    `json:"signingCertificate,omitempty"` // PEM encoded
+	Signature          string    `json:"signature,omitempty"`          // Base64 encoded
+}
+
+// UpdateFirmwareRequest represents an UpdateFirmware request (CSMS → CS)
+type UpdateFirmwareRequest struct {
+	Retries       *int     `json:"retries,omitempty"`
+	RetryInterval *int     `json:"retryInterval,omitempty"`
+	RequestId     int      `json:"requestId"`
+	Firmware      Firmware `json:"firmware"`
+}
+
+// UpdateFirmwareResponse represents an UpdateFirmware response (CS → CSMS)
+type UpdateFirmwareResponse struct {
+	Status     UpdateFirmwareStatusType `json:"status"`
+	StatusInfo *StatusInfo              `json:"statusInfo,omitempty"`
+}
+
+// =========== FirmwareStatusNotification ===========
+
+// FirmwareStatusNotificationRequest represents a FirmwareStatusNotification request (CS → CSMS)
+type FirmwareStatusNotificationRequest struct {
+	Status    FirmwareStatusType `json:"status"`
+	RequestId *int               `json:"requestId,omitempty"`
+}
+
+// FirmwareStatusNotificationResponse represents a FirmwareStatusNotification response (CSMS → CS)
+type FirmwareStatusNotificationResponse struct{}
+
+// =========== GetLog ===========
+
+// LogParameters describes where and which part of a log is uploaded
+type LogParameters struct {
+	RemoteLocation  string    `json:"remoteLocation"`
+	OldestTimestamp *DateTime `json:"oldestTimestamp,omitempty"`
+	LatestTimestamp *DateTime `json:"latestTimestamp,omitempty"`
+}
+
+// GetLogRequest represents a GetLog request (CSMS → CS)
+type GetLogRequest struct {
+	Log           LogParameters `json:"log"`
+	LogType       LogType       `json:"logType"`
+	RequestId     int           `json:"requestId"`
+	Retries       *int          `json:"retries,omitempty"`
+	RetryInterval *int          `json:"retryInterval,omitempty"`
+}
+
+// GetLogResponse represents a GetLog response (CS → CSMS)
+type GetLogResponse struct {
+	Status     LogStatusType `json:"status"`
+	StatusInfo *StatusInfo   `json:"statusInfo,omitempty"`
+	Filename   string        `json:"filename,omitempty"`
+}
+
+// =========== LogStatusNotification ===========
+
+// LogStatusNotificationRequest represents a LogStatusNotification request (CS → CSMS)
+type LogStatusNotificationRequest struct {
+	Status    UploadLogStatusType `json:"status"`
+	RequestId *int                `json:"requestId,omitempty"`
+}
+
+// LogStatusNotificationResponse represents a LogStatusNotification response (CSMS → CS)
+type LogStatusNotificationResponse struct{}
+
+// =========== SetDisplayMessage ===========
+
+// SetDisplayMessageRequest represents a SetDisplayMessage request (CSMS → CS). A message with the
+// id of an existing message replaces it.
+type SetDisplayMessageRequest struct {
+	Message MessageInfo `json:"message"`
+}
+
+// SetDisplayMessageResponse represents a SetDisplayMessage response (CS → CSMS)
+type SetDisplayMessageResponse struct {
+	Status     DisplayMessageStatusType `json:"status"`
+	StatusInfo *StatusInfo              `json:"statusInfo,omitempty"`
+}
+
+// =========== GetDisplayMessages ===========
+
+// GetDisplayMessagesRequest represents a GetDisplayMessages request (CSMS → CS). The messages
+// matching all given criteria are reported with NotifyDisplayMessages.
+type GetDisplayMessagesRequest struct {
+	Id        []int                `json:"id,omitempty"`
+	RequestId int                  `json:"requestId"`
+	Priority  *MessagePriorityType `json:"priority,omitempty"`
+	State     *MessageStateType    `json:"state,omitempty"`
+}
+
+// GetDisplayMessagesResponse represents a GetDisplayMessages response (CS → CSMS)
+type GetDisplayMessagesResponse struct {
+	Status     GetDisplayMessagesStatusType `json:"status"`
+	StatusInfo *StatusInfo                  `json:"statusInfo,omitempty"`
+}
+
+// =========== ClearDisplayMessage ===========
+
+// ClearDisplayMessageRequest represents a ClearDisplayMessage request (CSMS → CS)
+type ClearDisplayMessageRequest struct {
+	Id int `json:"id"`
+}
+
+// ClearDisplayMessageResponse represents a ClearDisplayMessage response (CS → CSMS)
+type ClearDisplayMessageResponse struct {
+	Status     ClearMessageStatusType `json:"status"`
+	StatusInfo *StatusInfo            `json:"statusInfo,omitempty"`
+}
+
+// =========== NotifyDisplayMessages ===========
+
+// NotifyDisplayMessagesRequest represents a NotifyDisplayMessages request (CS → CSMS)
+type NotifyDisplayMessagesRequest struct {
+	RequestId   int           `json:"requestId"`
+	Tbc         bool          `json:"tbc,omitempty"`
+	MessageInfo []MessageInfo `json:"messageInfo,omitempty"`
+}
+
+// NotifyDisplayMessagesResponse represents a NotifyDisplayMessages response (CSMS → CS)
+type NotifyDisplayMessagesResponse struct{}
+
+// =========== CostUpdated ===========
+
+// CostUpdatedRequest represents a CostUpdated request (CSMS → CS) with the running cost of a transaction
+type CostUpdatedRequest struct {
+	TotalCost     float64 `json:"totalCost"`
+	TransactionId string  `json:"transactionId"`
+}
+
+// CostUpdatedResponse represents a CostUpdated response (CS → CSMS)
+type CostUpdatedResponse struct{}
+
 // =========== Certificate Management ===========
 
 // CertificateHashDataType contains hash data for certificate identification
@@ -0,0 +1,569 @@
+package v201
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MaxMonitoringSeverity is the lowest monitor severity (Debug), 0 is the highest (Danger)
+const MaxMonitoringSeverity = 9
+
+// maxEventValueLength is the maximum length of the actualValue of an event
+const maxEventValueLength = 2500
+
+// VariableMonitor is a monitor attached to a variable. Threshold and Delta monitors are evaluated
+// when the Actual value changes, Periodic monitors report the value at a fixed interval.
+type VariableMonitor struct {
+	ID          int
+	Type        MonitorType
+	Value       float64
+	Severity    int
+	Transaction bool                  // Only active while a transaction is ongoing
+	Origin      EventNotificationType // HardWiredMonitor, PreconfiguredMonitor or CustomMonitor
+
+	alerting   bool      // Threshold exceeded
+	reference  string    // Value of the last Delta event
+	nextReport time.Time // Next Periodic event
+}
+
+// newMonitorID allocates a variable monitor ID
+func (dm *DeviceModel) newMonitorID() int {
+	dm.monitorMu.Lock()
+	defer dm.monitorMu.Unlock()
+
+	dm.nextMonitorID++
+	return dm.nextMonitorID
+}
+
+// newEventID allocates a NotifyEvent event ID
+func (dm *DeviceModel) newEventID() int {
+	dm.monitorMu.Lock()
+	defer dm.monitorMu.Unlock()
+
+	dm.nextEventID++
+	return dm.nextEventID
+}
+
+// addMonitor attaches a monitor to a variable
+func (v *VariableInstance) addMonitor(monitorType MonitorType, value float64, severity int, origin EventNotificationType, id int) *VariableMonitor {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.addMonitorLocked(&VariableMonitor{
+		ID:       id,
+		Type:     monitorType,
+		Value:    value,
+		Severity: severity,
+		Origin:   origin,
+	}, time.Now())
+}
+
+// addMonitorLocked attaches a monitor starting from the current value. Must be called with v.mu held.
+func (v *VariableInstance) addMonitorLocked(monitor *VariableMonitor, now time.Time) *VariableMonitor {
+	if v.monitors == nil {
+		v.monitors = make(map[int]*VariableMonitor)
+	}
+
+	if attr := v.Attributes[AttributeActual]; attr != nil {
+		monitor.reference = attr.Value
+	}
+	monitor.scheduleNext(now)
+
+	v.monitors[monitor.ID] = monitor
+	return monitor
+}
+
+// sortedMonitors returns the monitors of a variable by ID. Must be called with v.mu held.
+func (v *VariableInstance) sortedMonitors() []*VariableMonitor {
+	monitors := make([]*VariableMonitor, 0, len(v.monitors))
+	for _, monitor := range v.monitors {
+		monitors = append(monitors, monitor)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].ID < monitors[j].ID })
+	return monitors
+}
+
+// scheduleNext sets the time of the next event of a Periodic monitor
+func (m *VariableMonitor) scheduleNext(now time.Time) {
+	interval := time.Duration(m.Value * float64(time.Second))
+	if interval <= 0 {
+		return
+	}
+
+	switch m.Type {
+	case MonitorPeriodic:
+		m.nextReport = now.Add(interval)
+	case MonitorPeriodicClockAligned:
+		m.nextReport = now.Truncate(interval).Add(interval)
+	}
+}
+
+// isNumeric reports whether threshold monitors apply to a variable
+func (c VariableCharacteristics) isNumeric() bool {
+	return c.DataType == DataTypeDecimal || c.DataType == DataTypeInteger
+}
+
+// MonitoringLevel returns the highest severity reported with NotifyEvent (ActiveMonitoringLevel)
+func (dm *DeviceModel) MonitoringLevel() int {
+	value, status := dm.GetVariable("MonitoringCtrlr", "", "ActiveMonitoringLevel", "", AttributeActual)
+	if status != GetVariableStatusAccepted {
+		return MaxMonitoringSeverity
+	}
+
+	level, err := strconv.Atoi(value)
+	if err != nil {
+		return MaxMonitoringSeverity
+	}
+	return level
+}
+
+// SetMonitoringLevel sets the highest severity reported with NotifyEvent.
+// Returns false when the severity is out of range.
+func (dm *DeviceModel) SetMonitoringLevel(severity int) bool {
+	if severity < 0 || severity > MaxMonitoringSeverity {
+		return false
+	}
+
+	dm.setActual("MonitoringCtrlr", "", "ActiveMonitoringLevel", strconv.Itoa(severity))
+	return true
+}
+
+// monitoringEnabled reports whether variable monitoring is enabled
+func (dm *DeviceModel) monitoringEnabled() bool {
+	value, status := dm.GetVariable("MonitoringCtrlr", "", "Enabled", "", AttributeActual)
+	return status != GetVariableStatusAccepted || value != "false"
+}
+
+// monitoringBase returns the active monitoring base
+func (dm *DeviceModel) monitoringBase() MonitoringBaseType {
+	value, status := dm.GetVariable("MonitoringCtrlr", "", "ActiveMonitoringBase", "", AttributeActual)
+	if status != GetVariableStatusAccepted || value == "" {
+		return MonitoringBaseAll
+	}
+	return MonitoringBaseType(value)
+}
+
+// isActive reports whether a monitor is in effect for a monitoring base
+func (m *VariableMonitor) isActive(base MonitoringBaseType) bool {
+	return base != MonitoringBaseHardWiredOnly || m.Origin == EventNotificationHardWiredMonitor
+}
+
+// SetMonitoringBase activates a monitoring base. FactoryDefault and HardWiredOnly remove the
+// custom monitors, HardWiredOnly also disables the preconfigured ones.
+func (dm *DeviceModel) SetMonitoringBase(base MonitoringBaseType) GenericDeviceModelStatusType {
+	switch base {
+	case MonitoringBaseAll:
+	case MonitoringBaseFactoryDefault, MonitoringBaseHardWiredOnly:
+		dm.forEachVariable(func(_ *ComponentInstance, variable *VariableInstance) {
+			variable.mu.Lock()
+			for id, monitor := range variable.monitors {
+				if monitor.Origin == EventNotificationCustomMonitor {
+					delete(variable.monitors, id)
+				}
+			}
+			variable.mu.Unlock()
+		})
+	default:
+		return GenericDeviceModelStatusNotSupported
+	}
+
+	dm.setActual("MonitoringCtrlr", "", "ActiveMonitoringBase", string(base))
+	return GenericDeviceModelStatusAccepted
+}
+
+// SetVariableMonitoring sets custom monitors. A monitor with an ID replaces the existing custom monitor.
+func (dm *DeviceModel) SetVariableMonitoring(data []SetMonitoringData) []SetMonitoringResult {
+	results := make([]SetMonitoringResult, 0, len(data))
+	for _, d := range data {
+		result := SetMonitoringResult{
+			Id:        d.Id,
+			Type:      d.Type,
+			Severity:  d.Severity,
+			Component: d.Component,
+			Variable:  d.Variable,
+		}
+		result.Status, result.Id = dm.setVariableMonitor(d)
+		results = append(results, result)
+	}
+	return results
+}
+
+// setVariableMonitor validates and sets a single custom monitor, returning its status and ID
+func (dm *DeviceModel) setVariableMonitor(d SetMonitoringData) (SetMonitoringStatusType, *int) {
+	comp := dm.GetComponent(d.Component.Name, d.Component.Instance)
+	if comp == nil {
+		return SetMonitoringStatusUnknownComponent, d.Id
+	}
+
+	comp.mu.RLock()
+	variable := comp.Variables[getVariableKey(d.Variable.Name, d.Variable.Instance)]
+	comp.mu.RUnlock()
+	if variable == nil {
+		return SetMonitoringStatusUnknownVariable, d.Id
+	}
+
+	switch d.Type {
+	case MonitorUpperThreshold, MonitorLowerThreshold:
+		if !variable.Characteristics.isNumeric() {
+			return SetMonitoringStatusUnsupportedMonitorType, d.Id
+		}
+	case MonitorDelta:
+		if d.Value < 0 {
+			return SetMonitoringStatusRejected, d.Id
+		}
+	case MonitorPeriodic, MonitorPeriodicClockAligned:
+		if d.Value <= 0 {
+			return SetMonitoringStatusRejected, d.Id
+		}
+	default:
+		return SetMonitoringStatusUnsupportedMonitorType, d.Id
+	}
+
+	if !variable.Characteristics.SupportsMonitor || d.Severity < 0 || d.Severity > MaxMonitoringSeverity {
+		return SetMonitoringStatusRejected, d.Id
+	}
+
+	// A replaced monitor must be a custom monitor of the same variable
+	if d.Id != nil {
+		_, owner := dm.findMonitor(*d.Id)
+		if owner != variable {
+			return SetMonitoringStatusRejected, d.Id
+		}
+	}
+
+	variable.mu.Lock()
+	defer variable.mu.Unlock()
+
+	if d.Id != nil {
+		if existing := variable.monitors[*d.Id]; existing == nil || existing.Origin != EventNotificationCustomMonitor {
+			return SetMonitoringStatusRejected, d.Id
+		}
+	}
+
+	for _, monitor := range variable.monitors {
+		if d.Id != nil && monitor.ID == *d.Id {
+			continue
+		}
+		if monitor.Type == d.Type && monitor.Severity == d.Severity {
+			return SetMonitoringStatusDuplicate, d.Id
+		}
+	}
+
+	id := 0
+	if d.Id != nil {
+		id = *d.Id
+	} else {
+		id = dm.newMonitorID()
+	}
+
+	variable.addMonitorLocked(&VariableMonitor{
+		ID:          id,
+		Type:        d.Type,
+		Value:       d.Value,
+		Severity:    d.Severity,
+		Transaction: d.Transaction,
+		Origin:      EventNotificationCustomMonitor,
+	}, time.Now())
+
+	return SetMonitoringStatusAccepted, &id
+}
+
+// ClearVariableMonitoring removes custom monitors, hard wired and preconfigured monitors cannot be cleared
+func (dm *DeviceModel) ClearVariableMonitoring(ids []int) []ClearMonitoringResult {
+	results := make([]ClearMonitoringResult, 0, len(ids))
+	for _, id := range ids {
+		result := ClearMonitoringResult{Id: id, Status: ClearMonitoringStatusNotFound}
+
+		if monitor, variable := dm.findMonitor(id); monitor != nil {
+			result.Status = ClearMonitoringStatusRejected
+			if monitor.Origin == EventNotificationCustomMonitor {
+				variable.mu.Lock()
+				delete(variable.monitors, id)
+				variable.mu.Unlock()
+				result.Status = ClearMonitoringStatusAccepted
+			}
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+// findMonitor returns the monitor with the given ID and the variable it is attached to
+func (dm *DeviceModel) findMonitor(id int) (*VariableMonitor, *VariableInstance) {
+	var found *VariableMonitor
+	var owner *VariableInstance
+
+	dm.forEachVariable(func(_ *ComponentInstance, variable *VariableInstance) {
+		variable.mu.RLock()
+		if monitor := variable.monitors[id]; monitor != nil {
+			found, owner = monitor, variable
+		}
+		variable.mu.RUnlock()
+	})
+
+	return found, owner
+}
+
+// forEachVariable calls fn for every variable of the device model
+func (dm *DeviceModel) forEachVariable(fn func(comp *ComponentInstance, variable *VariableInstance)) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	for _, comp := range dm.Components {
+		comp.mu.RLock()
+		for _, variable := range comp.Variables {
+			fn(comp, variable)
+		}
+		comp.mu.RUnlock()
+	}
+}
+
+// MonitoringReport returns the active monitors of a GetMonitoringReport request, sorted by component
+// and variable. Criteria limit the monitor types, component variables the reported variables.
+func (dm *DeviceModel) MonitoringReport(componentVariables []ComponentVariable, criteria []MonitoringCriterionType) []MonitoringData {
+	base := dm.monitoringBase()
+
+	matchType := func(monitorType MonitorType) bool {
+		if len(criteria) == 0 {
+			return true
+		}
+		for _, criterion := range criteria {
+			switch criterion {
+			case MonitoringCriterionThreshold:
+				if monitorType == MonitorUpperThreshold || monitorType == MonitorLowerThreshold {
+					return true
+				}
+			case MonitoringCriterionDelta:
+				if monitorType == MonitorDelta {
+					return true
+				}
+			case MonitoringCriterionPeriodic:
+				if monitorType == MonitorPeriodic || monitorType == MonitorPeriodicClockAligned {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var results []MonitoringData
+
+	dm.forEachVariable(func(comp *ComponentInstance, variable *VariableInstance) {
+		if len(componentVariables) > 0 && !selectsVariable(componentVariables, comp, variable) {
+			return
+		}
+
+		variable.mu.RLock()
+		defer variable.mu.RUnlock()
+
+		data := MonitoringData{
+			Component: Component{Name: comp.Name, Instance: comp.Instance, EVSE: comp.EVSE},
+			Variable:  Variable{Name: variable.Name, Instance: variable.Instance},
+		}
+		for _, monitor := range variable.sortedMonitors() {
+			if !monitor.isActive(base) || !matchType(monitor.Type) {
+				continue
+			}
+			data.VariableMonitoring = append(data.VariableMonitoring, VariableMonitoring{
+				Id:          monitor.ID,
+				Transaction: monitor.Transaction,
+				Value:       monitor.Value,
+				Type:        monitor.Type,
+				Severity:    monitor.Severity,
+			})
+		}
+
+		if len(data.VariableMonitoring) > 0 {
+			results = append(results, data)
+		}
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Component.Name != b.Component.Name {
+			return a.Component.Name < b.Component.Name
+		}
+		if a.Component.Instance != b.Component.Instance {
+			return a.Component.Instance < b.Component.Instance
+		}
+		if a.Variable.Name != b.Variable.Name {
+			return a.Variable.Name < b.Variable.Name
+		}
+		return a.Variable.Instance < b.Variable.Instance
+	})
+
+	return results
+}
+
+// UpdateActualValue sets the Actual value of a variable changed by the station itself, regardless of
+// its mutability, and evaluates the Threshold and Delta monitors of the variable. transactionID is the
+// transaction ongoing on the component, if any. Returns the events of the triggered monitors whose
+// severity is within the active monitoring level.
+func (dm *DeviceModel) UpdateActualValue(componentName, componentInstance, variableName, value, transactionID string) []EventData {
+	enabled := dm.monitoringEnabled()
+	level := dm.MonitoringLevel()
+	base := dm.monitoringBase()
+
+	comp := dm.GetComponent(componentName, componentInstance)
+	if comp == nil {
+		return nil
+	}
+
+	comp.mu.RLock()
+	defer comp.mu.RUnlock()
+
+	variable := comp.Variables[getVariableKey(variableName, "")]
+	if variable == nil {
+		return nil
+	}
+
+	variable.mu.Lock()
+	defer variable.mu.Unlock()
+
+	attr := variable.Attributes[AttributeActual]
+	if attr == nil || attr.Value == value {
+		variable.transactionID = transactionID
+		return nil
+	}
+	attr.Value = value
+	variable.transactionID = transactionID
+
+	if !enabled {
+		return nil
+	}
+
+	numeric := variable.Characteristics.isNumeric()
+	current, err := strconv.ParseFloat(value, 64)
+	if numeric && err != nil {
+		return nil
+	}
+
+	var events []EventData
+	for _, monitor := range variable.sortedMonitors() {
+		if !monitor.isActive(base) || (monitor.Transaction && transactionID == "") {
+			continue
+		}
+
+		var trigger EventTriggerType
+		cleared := false
+
+		switch monitor.Type {
+		case MonitorUpperThreshold, MonitorLowerThreshold:
+			exceeded := current > monitor.Value
+			if monitor.Type == MonitorLowerThreshold {
+				exceeded = current < monitor.Value
+			}
+			if exceeded == monitor.alerting {
+				continue
+			}
+			monitor.alerting = exceeded
+			trigger, cleared = EventTriggerAlerting, !exceeded
+
+		case MonitorDelta:
+			if numeric {
+				reference, err := strconv.ParseFloat(monitor.reference, 64)
+				if err == nil && math.Abs(current-reference) < monitor.Value {
+					continue
+				}
+			} else if value == monitor.reference {
+				continue
+			}
+			monitor.reference = value
+			trigger = EventTriggerDelta
+
+		default:
+			continue
+		}
+
+		if monitor.Severity <= level {
+			events = append(events, dm.monitorEvent(comp, variable, monitor, trigger, cleared))
+		}
+	}
+
+	return events
+}
+
+// PeriodicEvents returns the events of the Periodic and PeriodicClockAligned monitors that are due
+func (dm *DeviceModel) PeriodicEvents(now time.Time) []EventData {
+	if !dm.monitoringEnabled() {
+		return nil
+	}
+	level := dm.MonitoringLevel()
+	base := dm.monitoringBase()
+
+	var events []EventData
+
+	dm.forEachVariable(func(comp *ComponentInstance, variable *VariableInstance) {
+		variable.mu.Lock()
+		defer variable.mu.Unlock()
+
+		for _, monitor := range variable.sortedMonitors() {
+			if monitor.Type != MonitorPeriodic && monitor.Type != MonitorPeriodicClockAligned {
+				continue
+			}
+			if monitor.nextReport.IsZero() || now.Before(monitor.nextReport) {
+				continue
+			}
+			monitor.scheduleNext(now)
+
+			if !monitor.isActive(base) || monitor.Severity > level || (monitor.Transaction && variable.transactionID == "") {
+				continue
+			}
+			events = append(events, dm.monitorEvent(comp, variable, monitor, EventTriggerPeriodic, false))
+		}
+	})
+
+	sort.Slice(events, func(i, j int) bool { return events[i].EventId < events[j].EventId })
+	return events
+}
+
+// monitorEvent builds the event of a triggered monitor. Must be called with variable.mu held.
+func (dm *DeviceModel) monitorEvent(comp *ComponentInstance, variable *VariableInstance, monitor *VariableMonitor, trigger EventTriggerType, cleared bool) EventData {
+	actualValue := ""
+	if attr := variable.Attributes[AttributeActual]; attr != nil {
+		actualValue = attr.Value
+	}
+	if len(actualValue) > maxEventValueLength {
+		actualValue = actualValue[:maxEventValueLength]
+	}
+
+	monitorID := monitor.ID
+	event := EventData{
+		EventId:               dm.newEventID(),
+		Timestamp:             DateTime{Time: time.Now()},
+		Trigger:               trigger,
+		ActualValue:           actualValue,
+		TransactionId:         variable.transactionID,
+		VariableMonitoringId:  &monitorID,
+		Component:             Component{Name: comp.Name, Instance: comp.Instance, EVSE: comp.EVSE},
+		Variable:              Variable{Name: variable.Name, Instance: variable.Instance},
+		EventNotificationType: monitor.Origin,
+	}
+	if cleared {
+		event.Cleared = &cleared
+	}
+
+	return event
+}
+
+// setActual sets the Actual value of a variable regardless of its mutability
+func (dm *DeviceModel) setActual(componentName, componentInstance, variableName, value string) {
+	comp := dm.GetComponent(componentName, componentInstance)
+	if comp == nil {
+		return
+	}
+
+	comp.mu.RLock()
+	defer comp.mu.RUnlock()
+
+	if variable := comp.Variables[getVariableKey(variableName, "")]; variable != nil {
+		variable.mu.Lock()
+		if attr := variable.Attributes[AttributeActual]; attr != nil {
+			attr.Value = value
+		}
+		variable.mu.Unlock()
+	}
+}
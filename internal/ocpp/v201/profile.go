@@ -0,0 +1,223 @@
+package v201
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DeviceModelProfile describes the components and variables of specific charging station hardware.
+// Importing a profile adds its components to the device model and replaces the listed variables.
+type DeviceModelProfile struct {
+	Components []ComponentProfile `json:"components"`
+}
+
+// ComponentProfile describes a component of a device model profile
+type ComponentProfile struct {
+	Name      string            `json:"name"`
+	Instance  string            `json:"instance,omitempty"`
+	EVSE      *EVSE             `json:"evse,omitempty"`
+	Variables []VariableProfile `json:"variables"`
+}
+
+// VariableProfile describes a variable of a device model profile
+type VariableProfile struct {
+	Name            string                  `json:"name"`
+	Instance        string                  `json:"instance,omitempty"`
+	Characteristics VariableCharacteristics `json:"characteristics"`
+	Attributes      []VariableAttribute     `json:"attributes"`
+}
+
+// VariableValue is the value of a variable attribute, used to persist the device model
+type VariableValue struct {
+	Component         string        `json:"component"`
+	ComponentInstance string        `json:"componentInstance,omitempty"`
+	Variable          string        `json:"variable"`
+	VariableInstance  string        `json:"variableInstance,omitempty"`
+	AttributeType     AttributeType `json:"attributeType"`
+	Value             string        `json:"value"`
+}
+
+// ExportProfile returns the device model as a profile, sorted by component and variable.
+// WriteOnly values are not exported.
+func (dm *DeviceModel) ExportProfile() *DeviceModelProfile {
+	profile := &DeviceModelProfile{}
+
+	dm.mu.RLock()
+	for _, comp := range dm.Components {
+		comp.mu.RLock()
+		component := ComponentProfile{
+			Name:     comp.Name,
+			Instance: comp.Instance,
+			EVSE:     comp.EVSE,
+		}
+
+		for _, variable := range comp.Variables {
+			variable.mu.RLock()
+			data := variable.reportData(comp)
+			component.Variables = append(component.Variables, VariableProfile{
+				Name:            variable.Name,
+				Instance:        variable.Instance,
+				Characteristics: variable.Characteristics,
+				Attributes:      data.VariableAttribute,
+			})
+			variable.mu.RUnlock()
+		}
+		comp.mu.RUnlock()
+
+		sort.Slice(component.Variables, func(i, j int) bool {
+			a, b := component.Variables[i], component.Variables[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.Instance < b.Instance
+		})
+		profile.Components = append(profile.Components, component)
+	}
+	dm.mu.RUnlock()
+
+	sort.Slice(profile.Components, func(i, j int) bool {
+		a, b := profile.Components[i], profile.Components[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Instance < b.Instance
+	})
+
+	return profile
+}
+
+// Validate checks that a profile is complete and that every attribute value matches the
+// characteristics of its variable
+func (p *DeviceModelProfile) Validate() error {
+	for _, comp := range p.Components {
+		if comp.Name == "" {
+			return fmt.Errorf("component without name")
+		}
+
+		for _, variable := range comp.Variables {
+			if variable.Name == "" {
+				return fmt.Errorf("%s: variable without name", getComponentKey(comp.Name, comp.Instance))
+			}
+
+			for _, attr := range variable.Attributes {
+				switch attr.Type {
+				case AttributeActual, AttributeTarget, AttributeMinSet, AttributeMaxSet:
+				default:
+					return fmt.Errorf("%s.%s: unknown attribute type %q",
+						getComponentKey(comp.Name, comp.Instance), getVariableKey(variable.Name, variable.Instance), attr.Type)
+				}
+
+				switch attr.Mutability {
+				case MutabilityReadOnly, MutabilityWriteOnly, MutabilityReadWrite:
+				default:
+					return fmt.Errorf("%s.%s: unknown mutability %q",
+						getComponentKey(comp.Name, comp.Instance), getVariableKey(variable.Name, variable.Instance), attr.Mutability)
+				}
+
+				// Unset WriteOnly values, e.g. of an exported profile, are left empty
+				if attr.Mutability == MutabilityWriteOnly && attr.Value == "" {
+					continue
+				}
+				if err := variable.Characteristics.Validate(attr.Value); err != nil {
+					return fmt.Errorf("%s.%s %s: %w",
+						getComponentKey(comp.Name, comp.Instance), getVariableKey(variable.Name, variable.Instance), attr.Type, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ImportProfile validates a profile and applies it to the device model. Missing components are
+// added, listed variables replace the existing ones while keeping their monitors.
+func (dm *DeviceModel) ImportProfile(profile *DeviceModelProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	for _, cp := range profile.Components {
+		comp := dm.GetComponent(cp.Name, cp.Instance)
+		if comp == nil {
+			comp = dm.AddComponent(cp.Name, cp.Instance, cp.EVSE)
+		}
+
+		for _, vp := range cp.Variables {
+			comp.mu.Lock()
+			variable := comp.Variables[getVariableKey(vp.Name, vp.Instance)]
+			if variable == nil {
+				variable = &VariableInstance{Name: vp.Name, Instance: vp.Instance}
+				comp.Variables[getVariableKey(vp.Name, vp.Instance)] = variable
+			}
+			comp.mu.Unlock()
+
+			variable.mu.Lock()
+			variable.Characteristics = vp.Characteristics
+			variable.Attributes = make(map[AttributeType]*VariableAttribute, len(vp.Attributes))
+			for _, attr := range vp.Attributes {
+				variable.Attributes[attr.Type] = &attr
+			}
+			variable.mu.Unlock()
+		}
+	}
+
+	return nil
+}
+
+// PersistentValues returns the values of the persistent attributes that can be changed with
+// SetVariables, sorted by component and variable
+func (dm *DeviceModel) PersistentValues() []VariableValue {
+	var values []VariableValue
+
+	dm.forEachVariable(func(comp *ComponentInstance, variable *VariableInstance) {
+		variable.mu.RLock()
+		defer variable.mu.RUnlock()
+
+		for _, attrType := range []AttributeType{AttributeActual, AttributeTarget, AttributeMinSet, AttributeMaxSet} {
+			attr := variable.Attributes[attrType]
+			if attr == nil || !attr.Persistent || attr.Constant || attr.Mutability == MutabilityReadOnly {
+				continue
+			}
+			values = append(values, VariableValue{
+				Component:         comp.Name,
+				ComponentInstance: comp.Instance,
+				Variable:          variable.Name,
+				VariableInstance:  variable.Instance,
+				AttributeType:     attrType,
+				Value:             attr.Value,
+			})
+		}
+	})
+
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.ComponentInstance != b.ComponentInstance {
+			return a.ComponentInstance < b.ComponentInstance
+		}
+		if a.Variable != b.Variable {
+			return a.Variable < b.Variable
+		}
+		if a.VariableInstance != b.VariableInstance {
+			return a.VariableInstance < b.VariableInstance
+		}
+		return a.AttributeType < b.AttributeType
+	})
+
+	return values
+}
+
+// RestoreValues applies persisted values with SetVariable. Values that are no longer accepted,
+// e.g. after a profile changed the variable, are skipped and returned as errors.
+func (dm *DeviceModel) RestoreValues(values []VariableValue) []error {
+	var errs []error
+	for _, v := range values {
+		status := dm.SetVariable(v.Component, v.ComponentInstance, v.Variable, v.VariableInstance, v.AttributeType, v.Value)
+		if status != SetVariableStatusAccepted {
+			errs = append(errs, fmt.Errorf("%s.%s %s: %s",
+				getComponentKey(v.Component, v.ComponentInstance), getVariableKey(v.Variable, v.VariableInstance), v.AttributeType, status))
+		}
+	}
+	return errs
+}
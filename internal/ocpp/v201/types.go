@@ -15,6 +15,7 @@ const (
 	ActionStatusNotification Action = "StatusNotification"
 	ActionNotifyReport       Action = "NotifyReport"
 	ActionGetBaseReport      Action = "GetBaseReport"
+	ActionGetReport          Action = "GetReport"
 	ActionSetVariables       Action = "SetVariables"
 	ActionGetVariables       Action = "GetVariables"
 	ActionNotifyEvent        Action = "NotifyEvent"
@@ -61,6 +62,7 @@ const (
 	ActionSetMonitoringBase       Action = "SetMonitoringBase"
 	ActionSetMonitoringLevel      Action = "SetMonitoringLevel"
 	ActionNotifyMonitoringReport  Action = "NotifyMonitoringReport"
+	ActionGetMonitoringReport     Action = "GetMonitoringReport"
 
 	// Security
 	ActionSecurityEventNotification  Action = "SecurityEventNotification"
@@ -82,8 +84,12 @@ const (
 	ActionClearDisplayMessage   Action = "ClearDisplayMessage"
 
 	// Reservation
-	ActionReserveNow        Action = "ReserveNow"
-	ActionCancelReservation Action = "CancelReservation"
+	ActionReserveNow              Action = "ReserveNow"
+	ActionCancelReservation       Action = "CancelReservation"
+	ActionReservationStatusUpdate Action = "ReservationStatusUpdate"
+
+	// Tariff and cost
+	ActionCostUpdated Action = "CostUpdated"
 )
 
 // ========== Enums ==========
@@ -153,6 +159,16 @@ const (
 	IdTokenTypeNoAuthorization IdTokenType = "NoAuthorization"
 )
 
+// Valid reports whether the idToken type is defined by OCPP 2.0.1
+func (t IdTokenType) Valid() bool {
+	switch t {
+	case IdTokenTypeCentral, IdTokenTypeEMAID, IdTokenTypeISO14443, IdTokenTypeISO15693, IdTokenTypeKeyCode,
+		IdTokenTypeLocal, IdTokenTypeMacAddress, IdTokenTypeNoAuthorization:
+		return true
+	}
+	return false
+}
+
 // TransactionEventType represents the type of transaction event
 type TransactionEventType string
 
@@ -314,6 +330,48 @@ const (
 	ResetStatusScheduled ResetStatusType = "Scheduled"
 )
 
+// SetNetworkProfileStatusType represents the result of a SetNetworkProfile request
+type SetNetworkProfileStatusType string
+
+const (
+	SetNetworkProfileStatusAccepted SetNetworkProfileStatusType = "Accepted"
+	SetNetworkProfileStatusRejected SetNetworkProfileStatusType = "Rejected"
+	SetNetworkProfileStatusFailed   SetNetworkProfileStatusType = "Failed"
+)
+
+// OCPPVersionType represents the OCPP version of a network connection profile
+type OCPPVersionType string
+
+const (
+	OCPPVersion12 OCPPVersionType = "OCPP12"
+	OCPPVersion15 OCPPVersionType = "OCPP15"
+	OCPPVersion16 OCPPVersionType = "OCPP16"
+	OCPPVersion20 OCPPVersionType = "OCPP20"
+	OCPPVersion21 OCPPVersionType = "OCPP21"
+)
+
+// OCPPTransportType represents the transport of a network connection profile
+type OCPPTransportType string
+
+const (
+	OCPPTransportJSON OCPPTransportType = "JSON"
+	OCPPTransportSOAP OCPPTransportType = "SOAP"
+)
+
+// OCPPInterfaceType represents the network interface of a network connection profile
+type OCPPInterfaceType string
+
+const (
+	OCPPInterfaceWired0    OCPPInterfaceType = "Wired0"
+	OCPPInterfaceWired1    OCPPInterfaceType = "Wired1"
+	OCPPInterfaceWired2    OCPPInterfaceType = "Wired2"
+	OCPPInterfaceWired3    OCPPInterfaceType = "Wired3"
+	OCPPInterfaceWireless0 OCPPInterfaceType = "Wireless0"
+	OCPPInterfaceWireless1 OCPPInterfaceType = "Wireless1"
+	OCPPInterfaceWireless2 OCPPInterfaceType = "Wireless2"
+	OCPPInterfaceWireless3 OCPPInterfaceType = "Wireless3"
+)
+
 // AttributeType represents the type of variable attribute
 type AttributeType string
 
@@ -347,6 +405,385 @@ const (
 	GetVariableStatusNotSupportedAttributeType GetVariableStatusType = "NotSupportedAttributeType"
 )
 
+// ReportBaseType represents the predefined reports of GetBaseReport
+type ReportBaseType string
+
+const (
+	ReportBaseConfigurationInventory ReportBaseType = "ConfigurationInventory"
+	ReportBaseFullInventory          ReportBaseType = "FullInventory"
+	ReportBaseSummaryInventory       ReportBaseType = "SummaryInventory"
+)
+
+// ComponentCriterionType represents a criterion limiting the components of GetReport
+type ComponentCriterionType string
+
+const (
+	ComponentCriterionActive    ComponentCriterionType = "Active"
+	ComponentCriterionAvailable ComponentCriterionType = "Available"
+	ComponentCriterionEnabled   ComponentCriterionType = "Enabled"
+	ComponentCriterionProblem   ComponentCriterionType = "Problem"
+)
+
+// GenericDeviceModelStatusType represents the status of a device model report request
+type GenericDeviceModelStatusType string
+
+const (
+	GenericDeviceModelStatusAccepted       GenericDeviceModelStatusType = "Accepted"
+	GenericDeviceModelStatusRejected       GenericDeviceModelStatusType = "Rejected"
+	GenericDeviceModelStatusNotSupported   GenericDeviceModelStatusType = "NotSupported"
+	GenericDeviceModelStatusEmptyResultSet GenericDeviceModelStatusType = "EmptyResultSet"
+)
+
+// MonitorType represents the type of a variable monitor
+type MonitorType string
+
+const (
+	MonitorUpperThreshold       MonitorType = "UpperThreshold"
+	MonitorLowerThreshold       MonitorType = "LowerThreshold"
+	MonitorDelta                MonitorType = "Delta"
+	MonitorPeriodic             MonitorType = "Periodic"
+	MonitorPeriodicClockAligned MonitorType = "PeriodicClockAligned"
+)
+
+// MonitoringBaseType represents the monitors kept by SetMonitoringBase
+type MonitoringBaseType string
+
+const (
+	MonitoringBaseAll            MonitoringBaseType = "All"
+	MonitoringBaseFactoryDefault MonitoringBaseType = "FactoryDefault"
+	MonitoringBaseHardWiredOnly  MonitoringBaseType = "HardWiredOnly"
+)
+
+// MonitoringCriterionType represents a criterion limiting the monitors of GetMonitoringReport
+type MonitoringCriterionType string
+
+const (
+	MonitoringCriterionThreshold MonitoringCriterionType = "ThresholdMonitoring"
+	MonitoringCriterionDelta     MonitoringCriterionType = "DeltaMonitoring"
+	MonitoringCriterionPeriodic  MonitoringCriterionType = "PeriodicMonitoring"
+)
+
+// SetMonitoringStatusType represents the result of setting a variable monitor
+type SetMonitoringStatusType string
+
+const (
+	SetMonitoringStatusAccepted               SetMonitoringStatusType = "Accepted"
+	SetMonitoringStatusUnknownComponent       SetMonitoringStatusType = "UnknownComponent"
+	SetMonitoringStatusUnknownVariable        SetMonitoringStatusType = "UnknownVariable"
+	SetMonitoringStatusUnsupportedMonitorType SetMonitoringStatusType = "UnsupportedMonitorType"
+	SetMonitoringStatusRejected               SetMonitoringStatusType = "Rejected"
+	SetMonitoringStatusDuplicate              SetMonitoringStatusType = "Duplicate"
+)
+
+// ClearMonitoringStatusType represents the result of clearing a variable monitor
+type ClearMonitoringStatusType string
+
+const (
+	ClearMonitoringStatusAccepted ClearMonitoringStatusType = "Accepted"
+	ClearMonitoringStatusRejected ClearMonitoringStatusType = "Rejected"
+	ClearMonitoringStatusNotFound ClearMonitoringStatusType = "NotFound"
+)
+
+// EventTriggerType represents what triggered a NotifyEvent
+type EventTriggerType string
+
+const (
+	EventTriggerAlerting EventTriggerType = "Alerting"
+	EventTriggerDelta    EventTriggerType = "Delta"
+	EventTriggerPeriodic EventTriggerType = "Periodic"
+)
+
+// EventNotificationType represents the origin of the monitor that triggered an event
+type EventNotificationType string
+
+const (
+	EventNotificationHardWiredNotification EventNotificationType = "HardWiredNotification"
+	EventNotificationHardWiredMonitor      EventNotificationType = "HardWiredMonitor"
+	EventNotificationPreconfiguredMonitor  EventNotificationType = "PreconfiguredMonitor"
+	EventNotificationCustomMonitor         EventNotificationType = "CustomMonitor"
+)
+
+// GenericStatusType represents a generic Accepted/Rejected status
+type GenericStatusType string
+
+const (
+	GenericStatusAccepted GenericStatusType = "Accepted"
+	GenericStatusRejected GenericStatusType = "Rejected"
+)
+
+// ChargingProfilePurposeType represents the purpose of a charging profile
+type ChargingProfilePurposeType string
+
+const (
+	ChargingProfilePurposeChargingStationExternalConstraints ChargingProfilePurposeType = "ChargingStationExternalConstraints"
+	ChargingProfilePurposeChargingStationMaxProfile          ChargingProfilePurposeType = "ChargingStationMaxProfile"
+	ChargingProfilePurposeTxDefaultProfile                   ChargingProfilePurposeType = "TxDefaultProfile"
+	ChargingProfilePurposeTxProfile                          ChargingProfilePurposeType = "TxProfile"
+)
+
+// ChargingProfileKindType represents the kind of a charging profile
+type ChargingProfileKindType string
+
+const (
+	ChargingProfileKindAbsolute  ChargingProfileKindType = "Absolute"
+	ChargingProfileKindRecurring ChargingProfileKindType = "Recurring"
+	ChargingProfileKindRelative  ChargingProfileKindType = "Relative"
+)
+
+// RecurrencyKindType represents the recurrency of a recurring charging profile
+type RecurrencyKindType string
+
+const (
+	RecurrencyKindDaily  RecurrencyKindType = "Daily"
+	RecurrencyKindWeekly RecurrencyKindType = "Weekly"
+)
+
+// ChargingRateUnitType represents the unit of a charging schedule limit
+type ChargingRateUnitType string
+
+const (
+	ChargingRateUnitW ChargingRateUnitType = "W"
+	ChargingRateUnitA ChargingRateUnitType = "A"
+)
+
+// ChargingProfileStatusType represents the result of a SetChargingProfile request
+type ChargingProfileStatusType string
+
+const (
+	ChargingProfileStatusAccepted ChargingProfileStatusType = "Accepted"
+	ChargingProfileStatusRejected ChargingProfileStatusType = "Rejected"
+)
+
+// ClearChargingProfileStatusType represents the result of a ClearChargingProfile request
+type ClearChargingProfileStatusType string
+
+const (
+	ClearChargingProfileStatusAccepted ClearChargingProfileStatusType = "Accepted"
+	ClearChargingProfileStatusUnknown  ClearChargingProfileStatusType = "Unknown"
+)
+
+// UpdateType represents the kind of a SendLocalList update
+type UpdateType string
+
+const (
+	UpdateTypeDifferential UpdateType = "Differential"
+	UpdateTypeFull         UpdateType = "Full"
+)
+
+// SendLocalListStatusType represents the result of a SendLocalList request
+type SendLocalListStatusType string
+
+const (
+	SendLocalListStatusAccepted        SendLocalListStatusType = "Accepted"
+	SendLocalListStatusFailed          SendLocalListStatusType = "Failed"
+	SendLocalListStatusVersionMismatch SendLocalListStatusType = "VersionMismatch"
+)
+
+// ReserveNowStatusType represents the result of a ReserveNow request
+type ReserveNowStatusType string
+
+const (
+	ReserveNowStatusAccepted    ReserveNowStatusType = "Accepted"
+	ReserveNowStatusFaulted     ReserveNowStatusType = "Faulted"
+	ReserveNowStatusOccupied    ReserveNowStatusType = "Occupied"
+	ReserveNowStatusRejected    ReserveNowStatusType = "Rejected"
+	ReserveNowStatusUnavailable ReserveNowStatusType = "Unavailable"
+)
+
+// CancelReservationStatusType represents the result of a CancelReservation request
+type CancelReservationStatusType string
+
+const (
+	CancelReservationStatusAccepted CancelReservationStatusType = "Accepted"
+	CancelReservationStatusRejected CancelReservationStatusType = "Rejected"
+)
+
+// ReservationUpdateStatusType represents why a reservation ended without being used
+type ReservationUpdateStatusType string
+
+const (
+	ReservationUpdateStatusExpired ReservationUpdateStatusType = "Expired"
+	ReservationUpdateStatusRemoved ReservationUpdateStatusType = "Removed"
+)
+
+// ConnectorType represents the type of an EVSE connector
+type ConnectorType string
+
+const (
+	ConnectorTypeCCS1            ConnectorType = "cCCS1"
+	ConnectorTypeCCS2            ConnectorType = "cCCS2"
+	ConnectorTypeG105            ConnectorType = "cG105"
+	ConnectorTypeTesla           ConnectorType = "cTesla"
+	ConnectorTypeType1           ConnectorType = "cType1"
+	ConnectorTypeType2           ConnectorType = "cType2"
+	ConnectorTypeS309_1P_16A     ConnectorType = "s309-1P-16A"
+	ConnectorTypeS309_1P_32A     ConnectorType = "s309-1P-32A"
+	ConnectorTypeS309_3P_16A     ConnectorType = "s309-3P-16A"
+	ConnectorTypeS309_3P_32A     ConnectorType = "s309-3P-32A"
+	ConnectorTypeSBS1361         ConnectorType = "sBS1361"
+	ConnectorTypeSCEE_7_7        ConnectorType = "sCEE-7-7"
+	ConnectorTypeSType2          ConnectorType = "sType2"
+	ConnectorTypeSType3          ConnectorType = "sType3"
+	ConnectorTypeOther1PhMax16A  ConnectorType = "Other1PhMax16A"
+	ConnectorTypeOther1PhOver16A ConnectorType = "Other1PhOver16A"
+	ConnectorTypeOther3Ph        ConnectorType = "Other3Ph"
+	ConnectorTypePan             ConnectorType = "Pan"
+	ConnectorTypeWInductive      ConnectorType = "wInductive"
+	ConnectorTypeWResonant       ConnectorType = "wResonant"
+	ConnectorTypeUndetermined    ConnectorType = "Undetermined"
+	ConnectorTypeUnknown         ConnectorType = "Unknown"
+)
+
+// connectorTypeNames maps the connector names used in station configurations to OCPP 2.0.1
+// connector types
+var connectorTypeNames = map[string]ConnectorType{
+	"Type1":   ConnectorTypeType1,
+	"Type2":   ConnectorTypeType2,
+	"CCS":     ConnectorTypeCCS2,
+	"CCS1":    ConnectorTypeCCS1,
+	"CCS2":    ConnectorTypeCCS2,
+	"CHAdeMO": ConnectorTypeG105,
+	"Tesla":   ConnectorTypeTesla,
+}
+
+// ConnectorTypeFromName returns the connector type of a configured connector name, e.g. Type2
+// or CCS. OCPP 2.0.1 connector types are returned as is, unknown names as Unknown.
+func ConnectorTypeFromName(name string) ConnectorType {
+	if connectorType, ok := connectorTypeNames[name]; ok {
+		return connectorType
+	}
+
+	switch connectorType := ConnectorType(name); connectorType {
+	case ConnectorTypeCCS1, ConnectorTypeCCS2, ConnectorTypeG105, ConnectorTypeTesla, ConnectorTypeType1, ConnectorTypeType2,
+		ConnectorTypeS309_1P_16A, ConnectorTypeS309_1P_32A, ConnectorTypeS309_3P_16A, ConnectorTypeS309_3P_32A,
+		ConnectorTypeSBS1361, ConnectorTypeSCEE_7_7, ConnectorTypeSType2, ConnectorTypeSType3,
+		ConnectorTypeOther1PhMax16A, ConnectorTypeOther1PhOver16A, ConnectorTypeOther3Ph, ConnectorTypePan,
+		ConnectorTypeWInductive, ConnectorTypeWResonant, ConnectorTypeUndetermined:
+		return connectorType
+	}
+	return ConnectorTypeUnknown
+}
+
+// UpdateFirmwareStatusType represents the result of an UpdateFirmware request
+type UpdateFirmwareStatusType string
+
+const (
+	UpdateFirmwareStatusAccepted           UpdateFirmwareStatusType = "Accepted"
+	UpdateFirmwareStatusRejected           UpdateFirmwareStatusType = "Rejected"
+	UpdateFirmwareStatusAcceptedCanceled   UpdateFirmwareStatusType = "AcceptedCanceled"
+	UpdateFirmwareStatusInvalidCertificate UpdateFirmwareStatusType = "InvalidCertificate"
+	UpdateFirmwareStatusRevokedCertificate UpdateFirmwareStatusType = "RevokedCertificate"
+)
+
+// FirmwareStatusType represents the progress of a firmware update
+type FirmwareStatusType string
+
+const (
+	FirmwareStatusDownloaded                FirmwareStatusType = "Downloaded"
+	FirmwareStatusDownloadFailed            FirmwareStatusType = "DownloadFailed"
+	FirmwareStatusDownloading               FirmwareStatusType = "Downloading"
+	FirmwareStatusDownloadScheduled         FirmwareStatusType = "DownloadScheduled"
+	FirmwareStatusDownloadPaused            FirmwareStatusType = "DownloadPaused"
+	FirmwareStatusIdle                      FirmwareStatusType = "Idle"
+	FirmwareStatusInstallationFailed        FirmwareStatusType = "InstallationFailed"
+	FirmwareStatusInstalling                FirmwareStatusType = "Installing"
+	FirmwareStatusInstalled                 FirmwareStatusType = "Installed"
+	FirmwareStatusInstallRebooting          FirmwareStatusType = "InstallRebooting"
+	FirmwareStatusInstallScheduled          FirmwareStatusType = "InstallScheduled"
+	FirmwareStatusInstallVerificationFailed FirmwareStatusType = "InstallVerificationFailed"
+	FirmwareStatusInvalidSignature          FirmwareStatusType = "InvalidSignature"
+	FirmwareStatusSignatureVerified         FirmwareStatusType = "SignatureVerified"
+)
+
+// LogType represents the type of log requested with GetLog
+type LogType string
+
+const (
+	LogTypeDiagnosticsLog   LogType = "DiagnosticsLog"
+	LogTypeSecurityLog      LogType = "SecurityLog"
+	LogTypeDataCollectorLog LogType = "DataCollectorLog"
+)
+
+// LogStatusType represents the result of a GetLog request
+type LogStatusType string
+
+const (
+	LogStatusAccepted         LogStatusType = "Accepted"
+	LogStatusRejected         LogStatusType = "Rejected"
+	LogStatusAcceptedCanceled LogStatusType = "AcceptedCanceled"
+)
+
+// UploadLogStatusType represents the progress of a log upload
+type UploadLogStatusType string
+
+const (
+	UploadLogStatusBadMessage            UploadLogStatusType = "BadMessage"
+	UploadLogStatusIdle                  UploadLogStatusType = "Idle"
+	UploadLogStatusNotSupportedOperation UploadLogStatusType = "NotSupportedOperation"
+	UploadLogStatusPermissionDenied      UploadLogStatusType = "PermissionDenied"
+	UploadLogStatusUploaded              UploadLogStatusType = "Uploaded"
+	UploadLogStatusUploadFailure         UploadLogStatusType = "UploadFailure"
+	UploadLogStatusUploading             UploadLogStatusType = "Uploading"
+	UploadLogStatusAcceptedCanceled      UploadLogStatusType = "AcceptedCanceled"
+)
+
+// MessagePriorityType represents the priority of a display message
+type MessagePriorityType string
+
+const (
+	MessagePriorityAlwaysFront MessagePriorityType = "AlwaysFront"
+	MessagePriorityInFront     MessagePriorityType = "InFront"
+	MessagePriorityNormalCycle MessagePriorityType = "NormalCycle"
+)
+
+// MessageStateType represents the station state in which a display message is shown
+type MessageStateType string
+
+const (
+	MessageStateCharging    MessageStateType = "Charging"
+	MessageStateFaulted     MessageStateType = "Faulted"
+	MessageStateIdle        MessageStateType = "Idle"
+	MessageStateUnavailable MessageStateType = "Unavailable"
+)
+
+// MessageFormatType represents the format of a message content
+type MessageFormatType string
+
+const (
+	MessageFormatASCII MessageFormatType = "ASCII"
+	MessageFormatHTML  MessageFormatType = "HTML"
+	MessageFormatURI   MessageFormatType = "URI"
+	MessageFormatUTF8  MessageFormatType = "UTF8"
+)
+
+// DisplayMessageStatusType represents the result of a SetDisplayMessage
+type DisplayMessageStatusType string
+
+const (
+	DisplayMessageStatusAccepted                  DisplayMessageStatusType = "Accepted"
+	DisplayMessageStatusNotSupportedMessageFormat DisplayMessageStatusType = "NotSupportedMessageFormat"
+	DisplayMessageStatusRejected                  DisplayMessageStatusType = "Rejected"
+	DisplayMessageStatusNotSupportedPriority      DisplayMessageStatusType = "NotSupportedPriority"
+	DisplayMessageStatusNotSupportedState         DisplayMessageStatusType = "NotSupportedState"
+	DisplayMessageStatusUnknownTransaction        DisplayMessageStatusType = "UnknownTransaction"
+)
+
+// GetDisplayMessagesStatusType represents the result of a GetDisplayMessages
+type GetDisplayMessagesStatusType string
+
+const (
+	GetDisplayMessagesStatusAccepted GetDisplayMessagesStatusType = "Accepted"
+	GetDisplayMessagesStatusUnknown  GetDisplayMessagesStatusType = "Unknown"
+)
+
+// ClearMessageStatusType represents the result of a ClearDisplayMessage
+type ClearMessageStatusType string
+
+const (
+	ClearMessageStatusAccepted ClearMessageStatusType = "Accepted"
+	ClearMessageStatusUnknown  ClearMessageStatusType = "Unknown"
+)
+
 // DataTransferStatusType represents the status of a data transfer
 type DataTransferStatusType string
 
@@ -432,11 +869,30 @@ type IdTokenInfo struct {
 	EvseId              []int                   `json:"evseId,omitempty"`
 }
 
+// AuthorizationData represents an entry of the Local Authorization List, an entry without
+// idTokenInfo removes the idToken with a differential update
+type AuthorizationData struct {
+	IdToken     IdToken      `json:"idToken"`
+	IdTokenInfo *IdTokenInfo `json:"idTokenInfo,omitempty"`
+}
+
 // MessageContent represents a display message
 type MessageContent struct {
-	Format   string `json:"format"` // ASCII, HTML, URI, UTF8
-	Language string `json:"language,omitempty"`
-	Content  string `json:"content"`
+	Format   MessageFormatType `json:"format"`
+	Language string            `json:"language,omitempty"`
+	Content  string            `json:"content"`
+}
+
+// MessageInfo represents a message shown on a display of the charging station
+type MessageInfo struct {
+	Id            int                 `json:"id"`
+	Priority      MessagePriorityType `json:"priority"`
+	State         *MessageStateType   `json:"state,omitempty"`
+	StartDateTime *DateTime           `json:"startDateTime,omitempty"`
+	EndDateTime   *DateTime           `json:"endDateTime,omitempty"`
+	TransactionId string              `json:"transactionId,omitempty"`
+	Message       MessageContent      `json:"message"`
+	Display       *Component          `json:"display,omitempty"`
 }
 
 // Transaction represents a transaction
@@ -478,6 +934,20 @@ type SetVariableResult struct {
 	StatusInfo      *StatusInfo           `json:"statusInfo,omitempty"`
 }
 
+// ComponentVariable selects a component and optionally one of its variables
+type ComponentVariable struct {
+	Component Component `json:"component"`
+	Variable  *Variable `json:"variable,omitempty"`
+}
+
+// ReportData represents a variable of a device model report
+type ReportData struct {
+	Component               Component                `json:"component"`
+	Variable                Variable                 `json:"variable"`
+	VariableAttribute       []VariableAttribute      `json:"variableAttribute"`
+	VariableCharacteristics *VariableCharacteristics `json:"variableCharacteristics,omitempty"`
+}
+
 // GetVariableData represents data for getting a variable
 type GetVariableData struct {
 	AttributeType *AttributeType `json:"attributeType,omitempty"`
@@ -525,3 +995,48 @@ type UnitOfMeasure struct {
 	Unit       string `json:"unit,omitempty"` // Wh, kWh, varh, kvarh, W, kW, VA, kVA, var, kvar, A, V, K, Celsius, Fahrenheit, Percent
 	Multiplier *int   `json:"multiplier,omitempty"`
 }
+
+// SetMonitoringData represents a monitor to set with SetVariableMonitoring
+type SetMonitoringData struct {
+	Id          *int        `json:"id,omitempty"`
+	Transaction bool        `json:"transaction,omitempty"`
+	Value       float64     `json:"value"`
+	Type        MonitorType `json:"type"`
+	Severity    int         `json:"severity"`
+	Component   Component   `json:"component"`
+	Variable    Variable    `json:"variable"`
+}
+
+// SetMonitoringResult represents the result of setting a monitor
+type SetMonitoringResult struct {
+	Id         *int                    `json:"id,omitempty"`
+	Status     SetMonitoringStatusType `json:"status"`
+	Type       MonitorType             `json:"type"`
+	Severity   int                     `json:"severity"`
+	Component  Component               `json:"component"`
+	Variable   Variable                `json:"variable"`
+	StatusInfo *StatusInfo             `json:"statusInfo,omitempty"`
+}
+
+// ClearMonitoringResult represents the result of clearing a monitor
+type ClearMonitoringResult struct {
+	Status     ClearMonitoringStatusType `json:"status"`
+	Id         int                       `json:"id"`
+	StatusInfo *StatusInfo               `json:"statusInfo,omitempty"`
+}
+
+// VariableMonitoring represents a monitor in a NotifyMonitoringReport
+type VariableMonitoring struct {
+	Id          int         `json:"id"`
+	Transaction bool        `json:"transaction"`
+	Value       float64     `json:"value"`
+	Type        MonitorType `json:"type"`
+	Severity    int         `json:"severity"`
+}
+
+// MonitoringData represents the monitors of a variable in a NotifyMonitoringReport
+type MonitoringData struct {
+	Component          Component            `json:"component"`
+	Variable           Variable             `json:"variable"`
+	VariableMonitoring []VariableMonitoring `json:"variableMonitoring"`
+}
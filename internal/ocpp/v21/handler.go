@@ -14,8 +14,12 @@ type Handler struct {
 	*v201.Handler // Embed 2.0.1 handler for inherited functionality
 
 	// Cost and Tariff callbacks (CSMS → CS)
-	OnCostUpdated         func(stationID string, req *CostUpdatedRequest) (*CostUpdatedResponse, error)
-	OnCustomerInformation func(stationID string, req *CustomerInformationRequest) (*CustomerInformationResponse, error)
+	OnCostUpdated             func(stationID string, req *CostUpdatedRequest) (*CostUpdatedResponse, error)
+	OnCustomerInformation     func(stationID string, req *CustomerInformationRequest) (*CustomerInformationResponse, error)
+	OnSetDefaultTariff        func(stationID string, req *SetDefaultTariffRequest) (*SetDefaultTariffResponse, error)
+	OnGetTariffs              func(stationID string, req *GetTariffsRequest) (*GetTariffsResponse, error)
+	OnClearTariffs            func(stationID string, req *ClearTariffsRequest) (*ClearTariffsResponse, error)
+	OnChangeTransactionTariff func(stationID string, req *ChangeTransactionTariffRequest) (*ChangeTransactionTariffResponse, error)
 
 	// Display Message callbacks (CSMS → CS)
 	OnSetDisplayMessage   func(stationID string, req *SetDisplayMessageRequest) (*SetDisplayMessageResponse, error)
@@ -40,6 +44,10 @@ type Handler struct {
 	OnUpdateFirmware    func(stationID string, req *UpdateFirmwareRequest) (*UpdateFirmwareResponse, error)
 	OnSetNetworkProfile func(stationID string, req *SetNetworkProfileRequest) (*SetNetworkProfileResponse, error)
 	OnGetLog            func(stationID string, req *GetLogRequest) (*GetLogResponse, error)
+
+	// Local Controller callbacks (CSMS → Local Controller)
+	OnPublishFirmware   func(stationID string, req *PublishFirmwareRequest) (*PublishFirmwareResponse, error)
+	OnUnpublishFirmware func(stationID string, req *UnpublishFirmwareRequest) (*UnpublishFirmwareResponse, error)
 }
 
 // NewHandler creates a new OCPP 2.1 handler
@@ -60,6 +68,14 @@ func (h *Handler) HandleCall(stationID string, call *ocpp.Call) (interface{}, er
 		return h.handleCostUpdated(stationID, call)
 	case ActionCustomerInformation:
 		return h.handleCustomerInformation(stationID, call)
+	case ActionSetDefaultTariff:
+		return h.handleSetDefaultTariff(stationID, call)
+	case ActionGetTariffs:
+		return h.handleGetTariffs(stationID, call)
+	case ActionClearTariffs:
+		return h.handleClearTariffs(stationID, call)
+	case ActionChangeTransactionTariff:
+		return h.handleChangeTransactionTariff(stationID, call)
 
 	// Display Messages
 	case ActionSetDisplayMessage:
@@ -98,6 +114,10 @@ func (h *Handler) HandleCall(stationID string, call *ocpp.Call) (interface{}, er
 		return h.handleSetNetworkProfile(stationID, call)
 	case ActionGetLog:
 		return h.handleGetLog(stationID, call)
+	case ActionPublishFirmware:
+		return h.handlePublishFirmware(stationID, call)
+	case ActionUnpublishFirmware:
+		return h.handleUnpublishFirmware(stationID, call)
 
 	default:
 		// Fall back to 2.0.1 handler for inherited actions
@@ -133,6 +153,58 @@ func (h *Handler) handleCustomerInformation(stationID string, call *ocpp.Call) (
 	return h.OnCustomerInformation(stationID, &req)
 }
 
+func (h *Handler) handleSetDefaultTariff(stationID string, call *ocpp.Call) (*SetDefaultTariffResponse, error) {
+	var req SetDefaultTariffRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SetDefaultTariff request: %w", err)
+	}
+
+	if h.OnSetDefaultTariff == nil {
+		return &SetDefaultTariffResponse{Status: TariffSetStatusRejected}, nil
+	}
+
+	return h.OnSetDefaultTariff(stationID, &req)
+}
+
+func (h *Handler) handleGetTariffs(stationID string, call *ocpp.Call) (*GetTariffsResponse, error) {
+	var req GetTariffsRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GetTariffs request: %w", err)
+	}
+
+	if h.OnGetTariffs == nil {
+		return &GetTariffsResponse{Status: TariffGetStatusNoTariff}, nil
+	}
+
+	return h.OnGetTariffs(stationID, &req)
+}
+
+func (h *Handler) handleClearTariffs(stationID string, call *ocpp.Call) (*ClearTariffsResponse, error) {
+	var req ClearTariffsRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ClearTariffs request: %w", err)
+	}
+
+	if h.OnClearTariffs == nil {
+		return &ClearTariffsResponse{ClearTariffsResult: []ClearTariffsResultType{{Status: TariffClearStatusNoTariff}}}, nil
+	}
+
+	return h.OnClearTariffs(stationID, &req)
+}
+
+func (h *Handler) handleChangeTransactionTariff(stationID string, call *ocpp.Call) (*ChangeTransactionTariffResponse, error) {
+	var req ChangeTransactionTariffRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ChangeTransactionTariff request: %w", err)
+	}
+
+	if h.OnChangeTransactionTariff == nil {
+		return &ChangeTransactionTariffResponse{Status: TariffChangeStatusRejected}, nil
+	}
+
+	return h.OnChangeTransactionTariff(stationID, &req)
+}
+
 // ==================== Display Message Handlers ====================
 
 func (h *Handler) handleSetDisplayMessage(stationID string, call *ocpp.Call) (*SetDisplayMessageResponse, error) {
@@ -299,6 +371,32 @@ func (h *Handler) handleUpdateFirmware(stationID string, call *ocpp.Call) (*Upda
 	return h.OnUpdateFirmware(stationID, &req)
 }
 
+func (h *Handler) handlePublishFirmware(stationID string, call *ocpp.Call) (*PublishFirmwareResponse, error) {
+	var req PublishFirmwareRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal PublishFirmware request: %w", err)
+	}
+
+	if h.OnPublishFirmware == nil {
+		return &PublishFirmwareResponse{Status: GenericStatusRejected}, nil
+	}
+
+	return h.OnPublishFirmware(stationID, &req)
+}
+
+func (h *Handler) handleUnpublishFirmware(stationID string, call *ocpp.Call) (*UnpublishFirmwareResponse, error) {
+	var req UnpublishFirmwareRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal UnpublishFirmware request: %w", err)
+	}
+
+	if h.OnUnpublishFirmware == nil {
+		return &UnpublishFirmwareResponse{Status: UnpublishFirmwareStatusNoFirmware}, nil
+	}
+
+	return h.OnUnpublishFirmware(stationID, &req)
+}
+
 func (h *Handler) handleSetNetworkProfile(stationID string, call *ocpp.Call) (*SetNetworkProfileResponse, error) {
 	var req SetNetworkProfileRequest
 	if err := json.Unmarshal(call.Payload, &req); err != nil {
@@ -494,3 +592,24 @@ func (h *Handler) SendFirmwareStatusNotification(stationID string, req *Firmware
 
 	return call, nil
 }
+
+// SendPublishFirmwareStatusNotification sends a PublishFirmwareStatusNotification request
+func (h *Handler) SendPublishFirmwareStatusNotification(stationID string, req *PublishFirmwareStatusNotificationRequest) (*ocpp.Call, error) {
+	call, err := ocpp.NewCall(string(ActionPublishFirmwareStatusNotif), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PublishFirmwareStatusNotification call: %w", err)
+	}
+
+	data, err := call.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PublishFirmwareStatusNotification: %w", err)
+	}
+
+	if h.Handler.SendMessage != nil {
+		if err := h.Handler.SendMessage(stationID, data); err != nil {
+			return nil, fmt.Errorf("failed to send PublishFirmwareStatusNotification: %w", err)
+		}
+	}
+
+	return call, nil
+}
@@ -16,6 +16,67 @@ type CostUpdatedRequest struct {
 type CostUpdatedResponse struct {
 }
 
+// SetDefaultTariffRequest represents a SetDefaultTariff request (CSMS → CS)
+type SetDefaultTariffRequest struct {
+	EvseId int        `json:"evseId"` // 0 for all EVSEs
+	Tariff TariffType `json:"tariff"`
+}
+
+// SetDefaultTariffResponse represents a SetDefaultTariff response (CS → CSMS)
+type SetDefaultTariffResponse struct {
+	Status     TariffSetStatusType `json:"status"`
+	StatusInfo *StatusInfo         `json:"statusInfo,omitempty"`
+}
+
+// GetTariffsRequest represents a GetTariffs request (CSMS → CS)
+type GetTariffsRequest struct {
+	EvseId int `json:"evseId"` // 0 for all EVSEs
+}
+
+// GetTariffsResponse represents a GetTariffs response (CS → CSMS)
+type GetTariffsResponse struct {
+	Status            TariffGetStatusType    `json:"status"`
+	StatusInfo        *StatusInfo            `json:"statusInfo,omitempty"`
+	TariffAssignments []TariffAssignmentType `json:"tariffAssignments,omitempty"`
+}
+
+// ClearTariffsRequest represents a ClearTariffs request (CSMS → CS)
+type ClearTariffsRequest struct {
+	TariffIds []string `json:"tariffIds,omitempty"` // All tariffs if empty
+	EvseId    *int     `json:"evseId,omitempty"`
+}
+
+// ClearTariffsResultType represents the result of clearing a tariff
+type ClearTariffsResultType struct {
+	TariffId   *string               `json:"tariffId,omitempty"`
+	Status     TariffClearStatusType `json:"status"`
+	StatusInfo *StatusInfo           `json:"statusInfo,omitempty"`
+}
+
+// ClearTariffsResponse represents a ClearTariffs response (CS → CSMS)
+type ClearTariffsResponse struct {
+	ClearTariffsResult []ClearTariffsResultType `json:"clearTariffsResult"`
+}
+
+// ChangeTransactionTariffRequest represents a ChangeTransactionTariff request (CSMS → CS)
+type ChangeTransactionTariffRequest struct {
+	Tariff        TariffType `json:"tariff"`
+	TransactionId string     `json:"transactionId"`
+}
+
+// ChangeTransactionTariffResponse represents a ChangeTransactionTariff response (CS → CSMS)
+type ChangeTransactionTariffResponse struct {
+	Status     TariffChangeStatusType `json:"status"`
+	StatusInfo *StatusInfo            `json:"statusInfo,omitempty"`
+}
+
+// AuthorizeResponse represents an OCPP 2.1 Authorize response (CSMS → CS), which extends the
+// 2.0.1 response with the driver specific tariff
+type AuthorizeResponse struct {
+	v201.AuthorizeResponse
+	Tariff *TariffType `json:"tariff,omitempty"`
+}
+
 // NotifyCustomerInformationRequest represents a NotifyCustomerInformation request (CS → CSMS)
 type NotifyCustomerInformationRequest struct {
 	Data        string `json:"data"`
@@ -322,6 +383,45 @@ type UpdateFirmwareResponse struct {
 	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
 }
 
+// PublishFirmwareRequest represents a PublishFirmware request (CSMS → Local Controller)
+type PublishFirmwareRequest struct {
+	Location      string `json:"location"`
+	Retries       *int   `json:"retries,omitempty"`
+	Checksum      string `json:"checksum"` // MD5 hex digest of the firmware file
+	RequestId     int    `json:"requestId"`
+	RetryInterval *int   `json:"retryInterval,omitempty"`
+}
+
+// PublishFirmwareResponse represents a PublishFirmware response (Local Controller → CSMS)
+type PublishFirmwareResponse struct {
+	Status     GenericStatusType `json:"status"`
+	StatusInfo *StatusInfo       `json:"statusInfo,omitempty"`
+}
+
+// UnpublishFirmwareRequest represents an UnpublishFirmware request (CSMS → Local Controller)
+type UnpublishFirmwareRequest struct {
+	Checksum string `json:"checksum"`
+}
+
+// UnpublishFirmwareResponse represents an UnpublishFirmware response (Local Controller → CSMS)
+type UnpublishFirmwareResponse struct {
+	Status UnpublishFirmwareStatusType `json:"status"`
+}
+
+// PublishFirmwareStatusNotificationRequest represents a PublishFirmwareStatusNotification
+// request (Local Controller → CSMS). Location holds the URIs of the published firmware.
+type PublishFirmwareStatusNotificationRequest struct {
+	Status     PublishFirmwareStatusType `json:"status"`
+	Location   []string                  `json:"location,omitempty"`
+	RequestId  *int                      `json:"requestId,omitempty"`
+	StatusInfo *StatusInfo               `json:"statusInfo,omitempty"`
+}
+
+// PublishFirmwareStatusNotificationResponse represents a PublishFirmwareStatusNotification
+// response (CSMS → Local Controller)
+type PublishFirmwareStatusNotificationResponse struct {
+}
+
 // =========== Network Profile Messages ===========
 
 // SetNetworkProfileRequest represents a SetNetworkProfile request (CSMS → CS)
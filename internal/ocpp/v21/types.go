@@ -40,9 +40,21 @@ const (
 	ActionSecurityEventNotification  Action = Action(v201.ActionSecurityEventNotification)
 	ActionNotifyReport               Action = Action(v201.ActionNotifyReport)
 	ActionGetBaseReport              Action = Action(v201.ActionGetBaseReport)
+	ActionGetReport                  Action = Action(v201.ActionGetReport)
+	ActionNotifyEvent                Action = Action(v201.ActionNotifyEvent)
+	ActionSetVariableMonitoring      Action = Action(v201.ActionSetVariableMonitoring)
+	ActionSetMonitoringBase          Action = Action(v201.ActionSetMonitoringBase)
+	ActionSetMonitoringLevel         Action = Action(v201.ActionSetMonitoringLevel)
+	ActionClearVariableMonitoring    Action = Action(v201.ActionClearVariableMonitoring)
+	ActionGetMonitoringReport        Action = Action(v201.ActionGetMonitoringReport)
+	ActionNotifyMonitoringReport     Action = Action(v201.ActionNotifyMonitoringReport)
 
 	// OCPP 2.1 New Actions - Cost and Tariff
 	ActionCostUpdated               Action = "CostUpdated"
+	ActionSetDefaultTariff          Action = "SetDefaultTariff"
+	ActionGetTariffs                Action = "GetTariffs"
+	ActionClearTariffs              Action = "ClearTariffs"
+	ActionChangeTransactionTariff   Action = "ChangeTransactionTariff"
 	ActionNotifyCustomerInformation Action = "NotifyCustomerInformation"
 	ActionCustomerInformation       Action = "CustomerInformation"
 	ActionNotifyEVChargingNeeds     Action = "NotifyEVChargingNeeds"
@@ -125,6 +137,194 @@ type SalesTariffType struct {
 	SalesTariffEntry       []SalesTariffEntryType `json:"salesTariffEntry"`
 }
 
+// TaxRateType represents a tax applied to a price
+type TaxRateType struct {
+	Type  string  `json:"type"` // e.g. VAT, federal, state
+	Tax   float64 `json:"tax"`  // Percent
+	Stack *int    `json:"stack,omitempty"`
+}
+
+// PriceType represents a price with and without taxes
+type PriceType struct {
+	ExclTax  *float64      `json:"exclTax,omitempty"`
+	InclTax  *float64      `json:"inclTax,omitempty"`
+	TaxRates []TaxRateType `json:"taxRates,omitempty"`
+}
+
+// TariffConditionsType represents the conditions under which a tariff price applies.
+// Energy is in Wh, power in W, current in A and times in seconds.
+type TariffConditionsType struct {
+	StartTimeOfDay  *string  `json:"startTimeOfDay,omitempty"` // HH:MM
+	EndTimeOfDay    *string  `json:"endTimeOfDay,omitempty"`   // HH:MM
+	DayOfWeek       []string `json:"dayOfWeek,omitempty"`      // Monday, Tuesday, ...
+	ValidFromDate   *string  `json:"validFromDate,omitempty"`  // YYYY-MM-DD
+	ValidToDate     *string  `json:"validToDate,omitempty"`    // YYYY-MM-DD
+	EvseKind        *string  `json:"evseKind,omitempty"`       // AC, DC
+	MinEnergy       *float64 `json:"minEnergy,omitempty"`
+	MaxEnergy       *float64 `json:"maxEnergy,omitempty"`
+	MinCurrent      *float64 `json:"minCurrent,omitempty"`
+	MaxCurrent      *float64 `json:"maxCurrent,omitempty"`
+	MinPower        *float64 `json:"minPower,omitempty"`
+	MaxPower        *float64 `json:"maxPower,omitempty"`
+	MinTime         *int     `json:"minTime,omitempty"`
+	MaxTime         *int     `json:"maxTime,omitempty"`
+	MinChargingTime *int     `json:"minChargingTime,omitempty"`
+	MaxChargingTime *int     `json:"maxChargingTime,omitempty"`
+	MinIdleTime     *int     `json:"minIdleTime,omitempty"`
+	MaxIdleTime     *int     `json:"maxIdleTime,omitempty"`
+}
+
+// TariffEnergyPriceType represents a price per kWh
+type TariffEnergyPriceType struct {
+	PriceKwh   float64               `json:"priceKwh"`
+	Conditions *TariffConditionsType `json:"conditions,omitempty"`
+}
+
+// TariffEnergyType represents the energy prices of a tariff, the first price whose conditions
+// are met applies
+type TariffEnergyType struct {
+	Prices   []TariffEnergyPriceType `json:"prices"`
+	TaxRates []TaxRateType           `json:"taxRates,omitempty"`
+}
+
+// TariffTimePriceType represents a price per minute
+type TariffTimePriceType struct {
+	PriceMinute float64               `json:"priceMinute"`
+	Conditions  *TariffConditionsType `json:"conditions,omitempty"`
+}
+
+// TariffTimeType represents the charging or idle time prices of a tariff
+type TariffTimeType struct {
+	Prices   []TariffTimePriceType `json:"prices"`
+	TaxRates []TaxRateType         `json:"taxRates,omitempty"`
+}
+
+// TariffFixedPriceType represents a fixed price per transaction
+type TariffFixedPriceType struct {
+	PriceFixed float64               `json:"priceFixed"`
+	Conditions *TariffConditionsType `json:"conditions,omitempty"`
+}
+
+// TariffFixedType represents the fixed fees of a tariff
+type TariffFixedType struct {
+	Prices   []TariffFixedPriceType `json:"prices"`
+	TaxRates []TaxRateType          `json:"taxRates,omitempty"`
+}
+
+// TariffType represents a tariff the charging station uses to calculate the cost of a transaction
+type TariffType struct {
+	TariffId     string               `json:"tariffId"`
+	Description  []MessageContentType `json:"description,omitempty"`
+	Currency     string               `json:"currency"` // ISO 4217
+	ValidFrom    *string              `json:"validFrom,omitempty"`
+	Energy       *TariffEnergyType    `json:"energy,omitempty"`
+	ChargingTime *TariffTimeType      `json:"chargingTime,omitempty"`
+	IdleTime     *TariffTimeType      `json:"idleTime,omitempty"`
+	FixedFee     *TariffFixedType     `json:"fixedFee,omitempty"`
+	MinCost      *PriceType           `json:"minCost,omitempty"`
+	MaxCost      *PriceType           `json:"maxCost,omitempty"`
+}
+
+// TariffKindType represents how a tariff is assigned
+type TariffKindType string
+
+const (
+	TariffKindDefaultTariff TariffKindType = "DefaultTariff"
+	TariffKindDriverTariff  TariffKindType = "DriverTariff"
+)
+
+// TariffAssignmentType represents a tariff in use by the charging station
+type TariffAssignmentType struct {
+	TariffId   string         `json:"tariffId"`
+	TariffKind TariffKindType `json:"tariffKind"`
+	ValidFrom  *string        `json:"validFrom,omitempty"`
+	EvseIds    []int          `json:"evseIds,omitempty"`
+	IdTokens   []string       `json:"idTokens,omitempty"`
+}
+
+// TariffSetStatusType represents the status of a SetDefaultTariff request
+type TariffSetStatusType string
+
+const (
+	TariffSetStatusAccepted              TariffSetStatusType = "Accepted"
+	TariffSetStatusRejected              TariffSetStatusType = "Rejected"
+	TariffSetStatusTooManyElements       TariffSetStatusType = "TooManyElements"
+	TariffSetStatusConditionNotSupported TariffSetStatusType = "ConditionNotSupported"
+	TariffSetStatusDuplicateTariffId     TariffSetStatusType = "DuplicateTariffId"
+)
+
+// TariffGetStatusType represents the status of a GetTariffs request
+type TariffGetStatusType string
+
+const (
+	TariffGetStatusAccepted TariffGetStatusType = "Accepted"
+	TariffGetStatusRejected TariffGetStatusType = "Rejected"
+	TariffGetStatusNoTariff TariffGetStatusType = "NoTariff"
+)
+
+// TariffClearStatusType represents the status of clearing a tariff
+type TariffClearStatusType string
+
+const (
+	TariffClearStatusAccepted TariffClearStatusType = "Accepted"
+	TariffClearStatusRejected TariffClearStatusType = "Rejected"
+	TariffClearStatusNoTariff TariffClearStatusType = "NoTariff"
+)
+
+// TariffChangeStatusType represents the status of a ChangeTransactionTariff request
+type TariffChangeStatusType string
+
+const (
+	TariffChangeStatusAccepted              TariffChangeStatusType = "Accepted"
+	TariffChangeStatusRejected              TariffChangeStatusType = "Rejected"
+	TariffChangeStatusTooManyElements       TariffChangeStatusType = "TooManyElements"
+	TariffChangeStatusConditionNotSupported TariffChangeStatusType = "ConditionNotSupported"
+	TariffChangeStatusTxNotFound            TariffChangeStatusType = "TxNotFound"
+	TariffChangeStatusNoCurrencyChange      TariffChangeStatusType = "NoCurrencyChange"
+)
+
+// TariffCostType represents whether the total cost of a transaction is its calculated cost or
+// the minimum or maximum cost of the tariff
+type TariffCostType string
+
+const (
+	TariffCostNormalCost TariffCostType = "NormalCost"
+	TariffCostMinCost    TariffCostType = "MinCost"
+	TariffCostMaxCost    TariffCostType = "MaxCost"
+)
+
+// TotalPriceType represents the total cost with and without taxes
+type TotalPriceType struct {
+	ExclTax *float64 `json:"exclTax,omitempty"`
+	InclTax *float64 `json:"inclTax,omitempty"`
+}
+
+// TotalCostType represents the cost of a transaction per tariff component
+type TotalCostType struct {
+	Currency     string         `json:"currency"`
+	TypeOfCost   TariffCostType `json:"typeOfCost"`
+	Fixed        *PriceType     `json:"fixed,omitempty"`
+	Energy       *PriceType     `json:"energy,omitempty"`
+	ChargingTime *PriceType     `json:"chargingTime,omitempty"`
+	IdleTime     *PriceType     `json:"idleTime,omitempty"`
+	Total        TotalPriceType `json:"total"`
+}
+
+// TotalUsageType represents the usage a transaction is billed for
+type TotalUsageType struct {
+	Energy       float64 `json:"energy"`       // Wh
+	ChargingTime int     `json:"chargingTime"` // Seconds
+	IdleTime     int     `json:"idleTime"`     // Seconds
+}
+
+// CostDetailsType represents the cost of a transaction calculated by the charging station
+type CostDetailsType struct {
+	FailureToCalculate *bool          `json:"failureToCalculate,omitempty"`
+	FailureReason      *string        `json:"failureReason,omitempty"`
+	TotalCost          TotalCostType  `json:"totalCost"`
+	TotalUsage         TotalUsageType `json:"totalUsage"`
+}
+
 // ChargingScheduleType represents a charging schedule (enhanced for 2.1)
 type ChargingScheduleType struct {
 	ID                     int                          `json:"id"`
@@ -138,12 +338,29 @@ type ChargingScheduleType struct {
 
 // ChargingSchedulePeriodType represents a period in a charging schedule
 type ChargingSchedulePeriodType struct {
-	StartPeriod  int     `json:"startPeriod"`
-	Limit        float64 `json:"limit"`
-	NumberPhases *int    `json:"numberPhases,omitempty"`
-	PhaseToUse   *int    `json:"phaseToUse,omitempty"`
+	StartPeriod    int                `json:"startPeriod"`
+	Limit          float64            `json:"limit"`
+	NumberPhases   *int               `json:"numberPhases,omitempty"`
+	PhaseToUse     *int               `json:"phaseToUse,omitempty"`
+	DischargeLimit *float64           `json:"dischargeLimit,omitempty"` // <= 0, maximum discharging rate
+	Setpoint       *float64           `json:"setpoint,omitempty"`       // Negative values discharge the EV
+	OperationMode  *OperationModeType `json:"operationMode,omitempty"`  // ChargingOnly if not set
 }
 
+// OperationModeType represents the operation mode of a charging schedule period
+type OperationModeType string
+
+const (
+	OperationModeIdle               OperationModeType = "Idle"
+	OperationModeChargingOnly       OperationModeType = "ChargingOnly"
+	OperationModeCentralSetpoint    OperationModeType = "CentralSetpoint"
+	OperationModeExternalSetpoint   OperationModeType = "ExternalSetpoint"
+	OperationModeExternalLimits     OperationModeType = "ExternalLimits"
+	OperationModeCentralFrequency   OperationModeType = "CentralFrequency"
+	OperationModeLocalFrequency     OperationModeType = "LocalFrequency"
+	OperationModeLocalLoadBalancing OperationModeType = "LocalLoadBalancing"
+)
+
 // ============ Display Message Types ============
 
 // MessagePriorityType represents message priority
@@ -318,6 +535,41 @@ const (
 	ClearChargingProfileStatusUnknown  ClearChargingProfileStatusType = "Unknown"
 )
 
+// ============ Firmware Publishing Types ============
+
+// PublishFirmwareStatusType represents the status of firmware published by a Local Controller
+type PublishFirmwareStatusType string
+
+const (
+	PublishFirmwareStatusIdle              PublishFirmwareStatusType = "Idle"
+	PublishFirmwareStatusDownloadScheduled PublishFirmwareStatusType = "DownloadScheduled"
+	PublishFirmwareStatusDownloading       PublishFirmwareStatusType = "Downloading"
+	PublishFirmwareStatusDownloaded        PublishFirmwareStatusType = "Downloaded"
+	PublishFirmwareStatusPublished         PublishFirmwareStatusType = "Published"
+	PublishFirmwareStatusDownloadFailed    PublishFirmwareStatusType = "DownloadFailed"
+	PublishFirmwareStatusDownloadPaused    PublishFirmwareStatusType = "DownloadPaused"
+	PublishFirmwareStatusInvalidChecksum   PublishFirmwareStatusType = "InvalidChecksum"
+	PublishFirmwareStatusChecksumVerified  PublishFirmwareStatusType = "ChecksumVerified"
+	PublishFirmwareStatusPublishFailed     PublishFirmwareStatusType = "PublishFailed"
+)
+
+// GenericStatusType represents the generic Accepted/Rejected status of a response
+type GenericStatusType string
+
+const (
+	GenericStatusAccepted GenericStatusType = "Accepted"
+	GenericStatusRejected GenericStatusType = "Rejected"
+)
+
+// UnpublishFirmwareStatusType represents the status of an UnpublishFirmware request
+type UnpublishFirmwareStatusType string
+
+const (
+	UnpublishFirmwareStatusDownloadOngoing UnpublishFirmwareStatusType = "DownloadOngoing"
+	UnpublishFirmwareStatusNoFirmware      UnpublishFirmwareStatusType = "NoFirmware"
+	UnpublishFirmwareStatusUnpublished     UnpublishFirmwareStatusType = "Unpublished"
+)
+
 // ============ EV Charging Needs Types ============
 
 // ACChargingParametersType represents AC charging parameters from EV
@@ -340,12 +592,34 @@ type DCChargingParametersType struct {
 	BulkSoC          *int `json:"bulkSoC,omitempty"`
 }
 
+// V2XChargingParametersType represents the charging and discharging limits of a bidirectional EV
+type V2XChargingParametersType struct {
+	MinChargePower        *float64 `json:"minChargePower,omitempty"`
+	MaxChargePower        *float64 `json:"maxChargePower,omitempty"`
+	MinDischargePower     *float64 `json:"minDischargePower,omitempty"`
+	MaxDischargePower     *float64 `json:"maxDischargePower,omitempty"`
+	MinChargeCurrent      *float64 `json:"minChargeCurrent,omitempty"`
+	MaxChargeCurrent      *float64 `json:"maxChargeCurrent,omitempty"`
+	MinDischargeCurrent   *float64 `json:"minDischargeCurrent,omitempty"`
+	MaxDischargeCurrent   *float64 `json:"maxDischargeCurrent,omitempty"`
+	EVTargetEnergyRequest *float64 `json:"evTargetEnergyRequest,omitempty"` // Wh to reach the target SoC
+	EVMinEnergyRequest    *float64 `json:"evMinEnergyRequest,omitempty"`    // Wh to reach the minimum SoC, negative if above it
+	EVMaxEnergyRequest    *float64 `json:"evMaxEnergyRequest,omitempty"`    // Wh to a full battery
+	EVMinV2XEnergyRequest *float64 `json:"evMinV2XEnergyRequest,omitempty"` // Wh to the minimum SoC allowed for V2X
+	EVMaxV2XEnergyRequest *float64 `json:"evMaxV2XEnergyRequest,omitempty"` // Wh to the maximum SoC allowed for V2X
+	TargetSoC             *int     `json:"targetSoC,omitempty"`
+}
+
 // ChargingNeedsType represents the EV's charging needs
 type ChargingNeedsType struct {
-	RequestedEnergyTransfer string                    `json:"requestedEnergyTransfer"` // AC_single_phase, AC_two_phase, AC_three_phase, DC
-	DepartureTime           *string                   `json:"departureTime,omitempty"`
-	ACChargingParameters    *ACChargingParametersType `json:"acChargingParameters,omitempty"`
-	DCChargingParameters    *DCChargingParametersType `json:"dcChargingParameters,omitempty"`
+	RequestedEnergyTransfer string                     `json:"requestedEnergyTransfer"` // AC_single_phase, AC_two_phase, AC_three_phase, DC, AC_BPT, DC_BPT
+	AvailableEnergyTransfer []string                   `json:"availableEnergyTransfer,omitempty"`
+	ControlMode             *string                    `json:"controlMode,omitempty"`       // ScheduledControl, DynamicControl
+	MobilityNeedsMode       *string                    `json:"mobilityNeedsMode,omitempty"` // EVCC, EVCC_SECC
+	DepartureTime           *string                    `json:"departureTime,omitempty"`
+	ACChargingParameters    *ACChargingParametersType  `json:"acChargingParameters,omitempty"`
+	DCChargingParameters    *DCChargingParametersType  `json:"dcChargingParameters,omitempty"`
+	V2XChargingParameters   *V2XChargingParametersType `json:"v2xChargingParameters,omitempty"`
 }
 
 // ============ Reexport common types from v201 ============
@@ -19,6 +19,7 @@ type StationController interface {
 	StartCharging(ctx context.Context, stationID string, connectorID int, idTag string) error
 	StopCharging(ctx context.Context, stationID string, connectorID int, reason string) error
 	SendCustomMessage(ctx context.Context, stationID string, messageJSON []byte) error
+	SendCall(ctx context.Context, stationID string, action string, payload interface{}) (json.RawMessage, error)
 	GetConnectors(ctx context.Context, stationID string) ([]map[string]interface{}, error)
 	IsStationConnected(stationID string) bool
 }
@@ -669,6 +670,24 @@ func (r *Runner) executeSendMessage(ctx context.Context, active *activeExecution
 	action, _ := step.Params["action"].(string)
 	payload := step.Params["payload"]
 
+	// Send a Call and wait for the CSMS response
+	if awaitResponse, _ := step.Params["awaitResponse"].(bool); awaitResponse && messageType == 2 {
+		responseJSON, err := r.controller.SendCall(ctx, stationID, action, payload)
+		if err != nil {
+			return nil, fmt.Errorf("call %s failed: %w", action, err)
+		}
+
+		var response interface{}
+		if err := json.Unmarshal(responseJSON, &response); err != nil {
+			return nil, fmt.Errorf("invalid %s response: %w", action, err)
+		}
+
+		return map[string]interface{}{
+			"action":   action,
+			"response": response,
+		}, nil
+	}
+
 	// Build OCPP message array
 	messageID := uuid.New().String()
 	var message interface{}
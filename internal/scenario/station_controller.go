@@ -2,7 +2,9 @@ package scenario
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/ruslanhut/ocpp-emu/internal/ocpp"
 	"github.com/ruslanhut/ocpp-emu/internal/station"
 )
 
@@ -43,6 +45,20 @@ func (c *StationManagerController) SendCustomMessage(ctx context.Context, statio
 	return c.manager.SendCustomMessage(ctx, stationID, messageJSON)
 }
 
+// SendCall sends an OCPP Call and returns the payload of the CSMS response.
+func (c *StationManagerController) SendCall(ctx context.Context, stationID string, action string, payload interface{}) (json.RawMessage, error) {
+	call, err := ocpp.NewCall(action, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.manager.SendCall(ctx, stationID, call)
+	if err != nil {
+		return nil, err
+	}
+	return result.Payload, nil
+}
+
 // GetConnectors returns connector information for a station.
 func (c *StationManagerController) GetConnectors(ctx context.Context, stationID string) ([]map[string]interface{}, error) {
 	return c.manager.GetConnectors(ctx, stationID)
@@ -137,10 +137,11 @@ type WaitConditionParams struct {
 
 // SendMessageParams defines parameters for send_message steps.
 type SendMessageParams struct {
-	StationID   string      `json:"stationId"`
-	MessageType int         `json:"messageType"` // 2=Call, 3=CallResult, 4=CallError
-	Action      string      `json:"action"`
-	Payload     interface{} `json:"payload"`
+	StationID     string      `json:"stationId"`
+	MessageType   int         `json:"messageType"` // 2=Call, 3=CallResult, 4=CallError
+	Action        string      `json:"action"`
+	Payload       interface{} `json:"payload"`
+	AwaitResponse bool        `json:"awaitResponse,omitempty"` // Wait for the CSMS response to a Call
 }
 
 // Execution represents a scenario execution instance.
@@ -0,0 +1,506 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	"github.com/ruslanhut/ocpp-emu/internal/storage"
+)
+
+// Authorization related configuration keys (OCPP 1.6)
+const (
+	ConfigKeyLocalAuthListEnabled       = "LocalAuthListEnabled"
+	ConfigKeyLocalAuthListMaxLength     = "LocalAuthListMaxLength"
+	ConfigKeySendLocalListMaxLength     = "SendLocalListMaxLength"
+	ConfigKeyLocalPreAuthorize          = "LocalPreAuthorize"
+	ConfigKeyLocalAuthorizeOffline      = "LocalAuthorizeOffline"
+	ConfigKeyAuthorizationCacheEnabled  = "AuthorizationCacheEnabled"
+	ConfigKeyAllowOfflineTxForUnknownId = "AllowOfflineTxForUnknownId"
+)
+
+// AuthorizationConfig controls how ID tags are authorized locally
+type AuthorizationConfig struct {
+	LocalAuthListEnabled       bool
+	LocalAuthListMaxLength     int
+	SendLocalListMaxLength     int
+	LocalPreAuthorize          bool
+	LocalAuthorizeOffline      bool
+	AuthorizationCacheEnabled  bool
+	AllowOfflineTxForUnknownId bool
+}
+
+// DefaultAuthorizationConfig returns the default local authorization configuration
+func DefaultAuthorizationConfig() AuthorizationConfig {
+	return AuthorizationConfig{
+		LocalAuthListEnabled:       true,
+		LocalAuthListMaxLength:     1000,
+		SendLocalListMaxLength:     100,
+		LocalPreAuthorize:          false,
+		LocalAuthorizeOffline:      true,
+		AuthorizationCacheEnabled:  true,
+		AllowOfflineTxForUnknownId: false,
+	}
+}
+
+// LocalAuthorization holds the Local Authorization List and the authorization cache of a station
+type LocalAuthorization struct {
+	stationID   string
+	config      AuthorizationConfig
+	listVersion int
+	localList   map[string]v16.IdTagInfo
+	cache       map[string]v16.IdTagInfo
+	mu          sync.RWMutex
+	repo        *storage.AuthorizationRepository
+	logger      *slog.Logger
+
+	// Snapshots are versioned under mu and saved under persistMu, so a stale snapshot
+	// saved after a newer one never overwrites it
+	snapshotVersion  uint64
+	persistedVersion uint64
+	persistMu        sync.Mutex
+}
+
+// authorizationSnapshot is the state of an authorization store to persist
+type authorizationSnapshot struct {
+	version uint64
+	data    storage.AuthorizationData
+}
+
+// NewLocalAuthorization creates an empty local authorization store with default configuration
+func NewLocalAuthorization(stationID string, logger *slog.Logger) *LocalAuthorization {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &LocalAuthorization{
+		stationID: stationID,
+		config:    DefaultAuthorizationConfig(),
+		localList: make(map[string]v16.IdTagInfo),
+		cache:     make(map[string]v16.IdTagInfo),
+		logger:    logger,
+	}
+}
+
+// SetRepository sets the repository used to persist the list and cache
+func (la *LocalAuthorization) SetRepository(repo *storage.AuthorizationRepository) {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	la.repo = repo
+}
+
+// Load restores the list and cache from the repository
+func (la *LocalAuthorization) Load(ctx context.Context) error {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+
+	if la.repo == nil {
+		return nil
+	}
+
+	data, err := la.repo.Get(ctx, la.stationID)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	la.listVersion = data.LocalListVersion
+	la.localList = entriesToIdTagInfo(data.LocalList)
+	la.cache = entriesToIdTagInfo(data.Cache)
+
+	la.logger.Info("Loaded local authorization data",
+		"stationId", la.stationID,
+		"listVersion", la.listVersion,
+		"listSize", len(la.localList),
+		"cacheSize", len(la.cache),
+	)
+
+	return nil
+}
+
+// Config returns the current authorization configuration
+func (la *LocalAuthorization) Config() AuthorizationConfig {
+	la.mu.RLock()
+	defer la.mu.RUnlock()
+	return la.config
+}
+
+// SetConfig replaces the authorization configuration
+func (la *LocalAuthorization) SetConfig(config AuthorizationConfig) {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	la.config = config
+}
+
+// ConfigurationKeys returns the authorization configuration as OCPP configuration keys
+func (la *LocalAuthorization) ConfigurationKeys() []v16.KeyValue {
+	config := la.Config()
+
+	keyValue := func(key, value string, readonly bool) v16.KeyValue {
+		return v16.KeyValue{Key: key, Readonly: readonly, Value: value}
+	}
+
+	return []v16.KeyValue{
+		keyValue(ConfigKeyLocalAuthListEnabled, strconv.FormatBool(config.LocalAuthListEnabled), false),
+		keyValue(ConfigKeyLocalAuthListMaxLength, strconv.Itoa(config.LocalAuthListMaxLength), true),
+		keyValue(ConfigKeySendLocalListMaxLength, strconv.Itoa(config.SendLocalListMaxLength), true),
+		keyValue(ConfigKeyLocalPreAuthorize, strconv.FormatBool(config.LocalPreAuthorize), false),
+		keyValue(ConfigKeyLocalAuthorizeOffline, strconv.FormatBool(config.LocalAuthorizeOffline), false),
+		keyValue(ConfigKeyAuthorizationCacheEnabled, strconv.FormatBool(config.AuthorizationCacheEnabled), false),
+		keyValue(ConfigKeyAllowOfflineTxForUnknownId, strconv.FormatBool(config.AllowOfflineTxForUnknownId), false),
+	}
+}
+
+// ChangeConfiguration applies an authorization configuration key.
+// Returns false if the key is not an authorization key.
+func (la *LocalAuthorization) ChangeConfiguration(key, value string) (bool, error) {
+	var target *bool
+
+	la.mu.Lock()
+	defer la.mu.Unlock()
+
+	switch key {
+	case ConfigKeyLocalAuthListEnabled:
+		target = &la.config.LocalAuthListEnabled
+	case ConfigKeyLocalPreAuthorize:
+		target = &la.config.LocalPreAuthorize
+	case ConfigKeyLocalAuthorizeOffline:
+		target = &la.config.LocalAuthorizeOffline
+	case ConfigKeyAuthorizationCacheEnabled:
+		target = &la.config.AuthorizationCacheEnabled
+	case ConfigKeyAllowOfflineTxForUnknownId:
+		target = &la.config.AllowOfflineTxForUnknownId
+	case ConfigKeyLocalAuthListMaxLength, ConfigKeySendLocalListMaxLength:
+		return true, fmt.Errorf("configuration key %s is read-only", key)
+	default:
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return true, fmt.Errorf("invalid value for %s: %s", key, value)
+	}
+
+	*target = enabled
+	return true, nil
+}
+
+// ListVersion returns the version of the Local Authorization List, -1 if the list is disabled
+func (la *LocalAuthorization) ListVersion() int {
+	la.mu.RLock()
+	defer la.mu.RUnlock()
+
+	if !la.config.LocalAuthListEnabled {
+		return -1
+	}
+
+	return la.listVersion
+}
+
+// SendLocalList applies a full or differential update of the Local Authorization List
+func (la *LocalAuthorization) SendLocalList(req *v16.SendLocalListRequest) v16.UpdateStatus {
+	la.mu.Lock()
+
+	if !la.config.LocalAuthListEnabled {
+		la.mu.Unlock()
+		return v16.UpdateStatusNotSupported
+	}
+
+	if req.ListVersion <= 0 || len(req.LocalAuthorizationList) > la.config.SendLocalListMaxLength {
+		la.mu.Unlock()
+		return v16.UpdateStatusFailed
+	}
+
+	var list map[string]v16.IdTagInfo
+
+	switch req.UpdateType {
+	case v16.UpdateTypeFull:
+		list = make(map[string]v16.IdTagInfo, len(req.LocalAuthorizationList))
+		for _, entry := range req.LocalAuthorizationList {
+			if entry.IdTagInfo == nil {
+				la.mu.Unlock()
+				return v16.UpdateStatusFailed
+			}
+			list[entry.IdTag] = *entry.IdTagInfo
+		}
+
+	case v16.UpdateTypeDifferential:
+		if req.ListVersion <= la.listVersion {
+			la.mu.Unlock()
+			return v16.UpdateStatusVersionMismatch
+		}
+
+		list = make(map[string]v16.IdTagInfo, len(la.localList))
+		for idTag, info := range la.localList {
+			list[idTag] = info
+		}
+		for _, entry := range req.LocalAuthorizationList {
+			if entry.IdTagInfo == nil {
+				delete(list, entry.IdTag)
+				continue
+			}
+			list[entry.IdTag] = *entry.IdTagInfo
+		}
+
+	default:
+		la.mu.Unlock()
+		return v16.UpdateStatusFailed
+	}
+
+	if len(list) > la.config.LocalAuthListMaxLength {
+		la.mu.Unlock()
+		return v16.UpdateStatusFailed
+	}
+
+	la.localList = list
+	la.listVersion = req.ListVersion
+
+	// ID tags on the local list are not kept in the cache
+	for idTag := range list {
+		delete(la.cache, idTag)
+	}
+
+	data := la.snapshot()
+	la.mu.Unlock()
+
+	la.logger.Info("Local authorization list updated",
+		"stationId", la.stationID,
+		"updateType", req.UpdateType,
+		"listVersion", req.ListVersion,
+		"listSize", len(list),
+	)
+
+	la.persist(data)
+	return v16.UpdateStatusAccepted
+}
+
+// ClearCache removes all entries from the authorization cache
+func (la *LocalAuthorization) ClearCache() {
+	la.mu.Lock()
+	la.cache = make(map[string]v16.IdTagInfo)
+	data := la.snapshot()
+	la.mu.Unlock()
+
+	la.logger.Info("Authorization cache cleared", "stationId", la.stationID)
+	la.persist(data)
+}
+
+// UpdateCache stores the authorization info returned by the CSMS for an ID tag.
+// Tags present on the Local Authorization List are not cached.
+func (la *LocalAuthorization) UpdateCache(idTag string, info v16.IdTagInfo) {
+	la.mu.Lock()
+
+	if !la.config.AuthorizationCacheEnabled || idTag == "" {
+		la.mu.Unlock()
+		return
+	}
+
+	if _, onList := la.localList[idTag]; onList && la.config.LocalAuthListEnabled {
+		la.mu.Unlock()
+		return
+	}
+
+	if cached, exists := la.cache[idTag]; exists && idTagInfoEqual(cached, info) {
+		la.mu.Unlock()
+		return
+	}
+
+	la.cache[idTag] = info
+	data := la.snapshot()
+	la.mu.Unlock()
+
+	la.persist(data)
+}
+
+// Lookup returns the locally known authorization info of an ID tag.
+// The Local Authorization List takes precedence over the cache; expired entries report Expired.
+func (la *LocalAuthorization) Lookup(idTag string) (*v16.IdTagInfo, bool) {
+	la.mu.Lock()
+
+	if la.config.LocalAuthListEnabled {
+		if info, exists := la.localList[idTag]; exists {
+			la.mu.Unlock()
+			return applyExpiry(info), true
+		}
+	}
+
+	if !la.config.AuthorizationCacheEnabled {
+		la.mu.Unlock()
+		return nil, false
+	}
+
+	info, exists := la.cache[idTag]
+	if !exists {
+		la.mu.Unlock()
+		return nil, false
+	}
+
+	result := applyExpiry(info)
+	if result.Status == info.Status {
+		la.mu.Unlock()
+		return result, true
+	}
+
+	// Expired cache entries are updated in place
+	la.cache[idTag] = *result
+	data := la.snapshot()
+	la.mu.Unlock()
+
+	la.persist(data)
+	return result, true
+}
+
+// PreAuthorize returns the local authorization of an ID tag when LocalPreAuthorize allows
+// starting without waiting for the CSMS. Only valid Accepted entries pre-authorize.
+func (la *LocalAuthorization) PreAuthorize(idTag string) (*v16.IdTagInfo, bool) {
+	if !la.Config().LocalPreAuthorize {
+		return nil, false
+	}
+
+	info, found := la.Lookup(idTag)
+	if !found || info.Status != v16.AuthorizationStatusAccepted {
+		return nil, false
+	}
+
+	return info, true
+}
+
+// AuthorizeOffline decides on an ID tag while the CSMS is unreachable
+func (la *LocalAuthorization) AuthorizeOffline(idTag string) *v16.IdTagInfo {
+	config := la.Config()
+
+	if config.LocalAuthorizeOffline {
+		if info, found := la.Lookup(idTag); found {
+			return info
+		}
+	}
+
+	if config.AllowOfflineTxForUnknownId {
+		return &v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted}
+	}
+
+	return &v16.IdTagInfo{Status: v16.AuthorizationStatusInvalid}
+}
+
+// LocalList returns a copy of the Local Authorization List
+func (la *LocalAuthorization) LocalList() map[string]v16.IdTagInfo {
+	la.mu.RLock()
+	defer la.mu.RUnlock()
+
+	list := make(map[string]v16.IdTagInfo, len(la.localList))
+	for idTag, info := range la.localList {
+		list[idTag] = info
+	}
+	return list
+}
+
+// CacheSize returns the number of cached ID tags
+func (la *LocalAuthorization) CacheSize() int {
+	la.mu.RLock()
+	defer la.mu.RUnlock()
+	return len(la.cache)
+}
+
+// snapshot converts the list and cache to the next version of their storage form (caller must
+// hold the write lock)
+func (la *LocalAuthorization) snapshot() authorizationSnapshot {
+	la.snapshotVersion++
+	return authorizationSnapshot{
+		version: la.snapshotVersion,
+		data: storage.AuthorizationData{
+			StationID:        la.stationID,
+			LocalListVersion: la.listVersion,
+			LocalList:        idTagInfoToEntries(la.localList),
+			Cache:            idTagInfoToEntries(la.cache),
+		},
+	}
+}
+
+// persist saves a snapshot to the repository if one is configured, unless a newer one has been saved
+func (la *LocalAuthorization) persist(snapshot authorizationSnapshot) {
+	la.mu.RLock()
+	repo := la.repo
+	la.mu.RUnlock()
+
+	if repo == nil {
+		return
+	}
+
+	la.persistMu.Lock()
+	defer la.persistMu.Unlock()
+
+	if snapshot.version <= la.persistedVersion {
+		return
+	}
+	la.persistedVersion = snapshot.version
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.Save(ctx, snapshot.data); err != nil {
+		la.logger.Error("Failed to persist local authorization data",
+			"stationId", la.stationID,
+			"error", err,
+		)
+	}
+}
+
+// applyExpiry returns a copy of the info with status Expired if its expiry date has passed
+func applyExpiry(info v16.IdTagInfo) *v16.IdTagInfo {
+	result := info
+	if result.ExpiryDate != nil && result.Status == v16.AuthorizationStatusAccepted && time.Now().After(result.ExpiryDate.Time) {
+		result.Status = v16.AuthorizationStatusExpired
+	}
+	return &result
+}
+
+// idTagInfoEqual reports whether two authorization infos are identical
+func idTagInfoEqual(a, b v16.IdTagInfo) bool {
+	if a.Status != b.Status || a.ParentIdTag != b.ParentIdTag {
+		return false
+	}
+	if a.ExpiryDate == nil || b.ExpiryDate == nil {
+		return a.ExpiryDate == b.ExpiryDate
+	}
+	return a.ExpiryDate.Time.Equal(b.ExpiryDate.Time)
+}
+
+// idTagInfoToEntries converts ID tag infos to storage entries
+func idTagInfoToEntries(infos map[string]v16.IdTagInfo) []storage.AuthorizationEntry {
+	entries := make([]storage.AuthorizationEntry, 0, len(infos))
+	for idTag, info := range infos {
+		entry := storage.AuthorizationEntry{
+			IDTag:       idTag,
+			Status:      string(info.Status),
+			ParentIDTag: info.ParentIdTag,
+		}
+		if info.ExpiryDate != nil {
+			expiry := info.ExpiryDate.Time
+			entry.ExpiryDate = &expiry
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entriesToIdTagInfo converts storage entries to ID tag infos
+func entriesToIdTagInfo(entries []storage.AuthorizationEntry) map[string]v16.IdTagInfo {
+	infos := make(map[string]v16.IdTagInfo, len(entries))
+	for _, entry := range entries {
+		info := v16.IdTagInfo{
+			Status:      v16.AuthorizationStatus(entry.Status),
+			ParentIdTag: entry.ParentIDTag,
+		}
+		if entry.ExpiryDate != nil {
+			info.ExpiryDate = &v16.DateTime{Time: *entry.ExpiryDate}
+		}
+		infos[entry.IDTag] = info
+	}
+	return infos
+}
@@ -0,0 +1,199 @@
+package station
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+)
+
+func acceptedIdTagInfo() *v16.IdTagInfo {
+	return &v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted}
+}
+
+func TestLocalAuthorization_SendLocalList(t *testing.T) {
+	la := NewLocalAuthorization("CP001", slog.Default())
+
+	if la.ListVersion() != 0 {
+		t.Errorf("Expected initial list version 0, got %d", la.ListVersion())
+	}
+
+	status := la.SendLocalList(&v16.SendLocalListRequest{
+		ListVersion: 1,
+		UpdateType:  v16.UpdateTypeFull,
+		LocalAuthorizationList: []v16.AuthorizationData{
+			{IdTag: "TAG001", IdTagInfo: acceptedIdTagInfo()},
+			{IdTag: "TAG002", IdTagInfo: acceptedIdTagInfo()},
+		},
+	})
+	if status != v16.UpdateStatusAccepted {
+		t.Fatalf("Expected Accepted for full update, got %s", status)
+	}
+
+	// Differential update adds and removes entries
+	status = la.SendLocalList(&v16.SendLocalListRequest{
+		ListVersion: 2,
+		UpdateType:  v16.UpdateTypeDifferential,
+		LocalAuthorizationList: []v16.AuthorizationData{
+			{IdTag: "TAG001"},
+			{IdTag: "TAG003", IdTagInfo: &v16.IdTagInfo{Status: v16.AuthorizationStatusBlocked}},
+		},
+	})
+	if status != v16.UpdateStatusAccepted {
+		t.Fatalf("Expected Accepted for differential update, got %s", status)
+	}
+
+	list := la.LocalList()
+	if _, exists := list["TAG001"]; exists {
+		t.Error("Expected TAG001 to be removed")
+	}
+	if list["TAG003"].Status != v16.AuthorizationStatusBlocked {
+		t.Errorf("Expected TAG003 to be Blocked, got %s", list["TAG003"].Status)
+	}
+	if la.ListVersion() != 2 {
+		t.Errorf("Expected list version 2, got %d", la.ListVersion())
+	}
+
+	// Differential update must move the version forward
+	status = la.SendLocalList(&v16.SendLocalListRequest{ListVersion: 2, UpdateType: v16.UpdateTypeDifferential})
+	if status != v16.UpdateStatusVersionMismatch {
+		t.Errorf("Expected VersionMismatch, got %s", status)
+	}
+
+	// Full update entries require IdTagInfo
+	status = la.SendLocalList(&v16.SendLocalListRequest{
+		ListVersion:            3,
+		UpdateType:             v16.UpdateTypeFull,
+		LocalAuthorizationList: []v16.AuthorizationData{{IdTag: "TAG004"}},
+	})
+	if status != v16.UpdateStatusFailed {
+		t.Errorf("Expected Failed for full update without IdTagInfo, got %s", status)
+	}
+
+	// Disabled list
+	la.ChangeConfiguration(ConfigKeyLocalAuthListEnabled, "false")
+	if la.ListVersion() != -1 {
+		t.Errorf("Expected list version -1 when disabled, got %d", la.ListVersion())
+	}
+	status = la.SendLocalList(&v16.SendLocalListRequest{ListVersion: 4, UpdateType: v16.UpdateTypeFull})
+	if status != v16.UpdateStatusNotSupported {
+		t.Errorf("Expected NotSupported when disabled, got %s", status)
+	}
+}
+
+func TestLocalAuthorization_Cache(t *testing.T) {
+	la := NewLocalAuthorization("CP001", slog.Default())
+
+	la.UpdateCache("TAG001", *acceptedIdTagInfo())
+	if la.CacheSize() != 1 {
+		t.Fatalf("Expected 1 cached tag, got %d", la.CacheSize())
+	}
+
+	// Expired cache entries report Expired
+	past := v16.DateTime{Time: time.Now().Add(-time.Minute)}
+	la.UpdateCache("TAG002", v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted, ExpiryDate: &past})
+	if info, found := la.Lookup("TAG002"); !found || info.Status != v16.AuthorizationStatusExpired {
+		t.Errorf("Expected cached TAG002 to be Expired, got %+v", info)
+	}
+
+	// Tags on the local list are not cached
+	la.SendLocalList(&v16.SendLocalListRequest{
+		ListVersion:            1,
+		UpdateType:             v16.UpdateTypeFull,
+		LocalAuthorizationList: []v16.AuthorizationData{{IdTag: "TAG003", IdTagInfo: acceptedIdTagInfo()}},
+	})
+	la.UpdateCache("TAG003", v16.IdTagInfo{Status: v16.AuthorizationStatusBlocked})
+	if info, _ := la.Lookup("TAG003"); info.Status != v16.AuthorizationStatusAccepted {
+		t.Errorf("Expected local list entry to take precedence, got %s", info.Status)
+	}
+
+	la.ClearCache()
+	if la.CacheSize() != 0 {
+		t.Errorf("Expected empty cache after ClearCache, got %d", la.CacheSize())
+	}
+	if _, found := la.Lookup("TAG003"); !found {
+		t.Error("Expected ClearCache to keep the local list")
+	}
+
+	// Disabled cache ignores updates
+	la.ChangeConfiguration(ConfigKeyAuthorizationCacheEnabled, "false")
+	la.UpdateCache("TAG001", *acceptedIdTagInfo())
+	if la.CacheSize() != 0 {
+		t.Errorf("Expected cache to stay empty when disabled, got %d", la.CacheSize())
+	}
+}
+
+func TestLocalAuthorization_ChangeConfiguration(t *testing.T) {
+	la := NewLocalAuthorization("CP001", slog.Default())
+
+	if handled, err := la.ChangeConfiguration(ConfigKeyLocalPreAuthorize, "true"); !handled || err != nil {
+		t.Errorf("Expected LocalPreAuthorize to be applied, handled=%v err=%v", handled, err)
+	}
+	if !la.Config().LocalPreAuthorize {
+		t.Error("Expected LocalPreAuthorize to be enabled")
+	}
+
+	if handled, err := la.ChangeConfiguration(ConfigKeyLocalPreAuthorize, "maybe"); !handled || err == nil {
+		t.Error("Expected invalid boolean value to be rejected")
+	}
+
+	if handled, err := la.ChangeConfiguration(ConfigKeyLocalAuthListMaxLength, "5"); !handled || err == nil {
+		t.Error("Expected read-only key to be rejected")
+	}
+
+	if handled, _ := la.ChangeConfiguration("HeartbeatInterval", "60"); handled {
+		t.Error("Expected non-authorization key not to be handled")
+	}
+}
+
+func TestSessionManager_AuthorizeOfflineFallback(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+
+	online := true
+	csmsCalls := 0
+	sm.SendAuthorize = func(idTag string) (*v16.AuthorizeResponse, error) {
+		csmsCalls++
+		if !online {
+			return nil, errors.New("not connected")
+		}
+		return &v16.AuthorizeResponse{IdTagInfo: *acceptedIdTagInfo()}, nil
+	}
+
+	// Online authorization fills the cache
+	if info, _ := sm.Authorize("TAG001"); info.Status != v16.AuthorizationStatusAccepted {
+		t.Fatalf("Expected Accepted online, got %s", info.Status)
+	}
+
+	online = false
+
+	// Cached tag is accepted offline, unknown tag is not
+	if info, err := sm.Authorize("TAG001"); err != nil || info.Status != v16.AuthorizationStatusAccepted {
+		t.Errorf("Expected cached tag to be Accepted offline, got %+v (err=%v)", info, err)
+	}
+	if info, _ := sm.Authorize("TAG999"); info.Status != v16.AuthorizationStatusInvalid {
+		t.Errorf("Expected unknown tag to be Invalid offline, got %s", info.Status)
+	}
+
+	// LocalAuthorizeOffline disabled ignores the cache
+	sm.Authorization().ChangeConfiguration(ConfigKeyLocalAuthorizeOffline, "false")
+	if info, _ := sm.Authorize("TAG001"); info.Status != v16.AuthorizationStatusInvalid {
+		t.Errorf("Expected Invalid with LocalAuthorizeOffline disabled, got %s", info.Status)
+	}
+
+	// LocalPreAuthorize skips the CSMS for known tags
+	online = true
+	sm.Authorization().ChangeConfiguration(ConfigKeyLocalPreAuthorize, "true")
+	calls := csmsCalls
+	if info, _ := sm.Authorize("TAG001"); info.Status != v16.AuthorizationStatusAccepted {
+		t.Errorf("Expected pre-authorized tag to be Accepted, got %s", info.Status)
+	}
+	if csmsCalls != calls {
+		t.Error("Expected pre-authorized tag not to be sent to the CSMS")
+	}
+}
@@ -2,6 +2,8 @@ package station
 
 import (
 	"time"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
 )
 
 // Config represents a station configuration
@@ -34,12 +36,23 @@ type Config struct {
 	MeterValuesConfig MeterValuesConfig
 
 	// CSMS Connection
-	CSMSURL  string
-	CSMSAuth *CSMSAuthConfig
+	CSMSURL         string
+	CSMSAuth        *CSMSAuthConfig
+	SecurityProfile int // OCPP security profile (0 = use the connection settings as configured)
+
+	// OCPP 2.0.1 network connection profiles set by the CSMS, by configuration slot
+	NetworkProfiles []NetworkProfile
 
 	// Simulation
 	Simulation SimulationConfig
 
+	// Values of writable OCPP 1.6 configuration keys changed by the CSMS
+	OCPPConfiguration map[string]string
+
+	// OCPP 2.0.1 device model: profile of the emulated hardware and the persistent variable values
+	DeviceModelProfile *v201.DeviceModelProfile
+	DeviceModelValues  []v201.VariableValue
+
 	// Metadata
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -53,6 +66,14 @@ type ConnectorConfig struct {
 	MaxPower             int
 	Status               string
 	CurrentTransactionID *int
+	PlugAndCharge        bool   // ISO 15118 EV authorized with its contract certificate (OCPP 2.0.1/2.1)
+	EMAID                string // Contract ID of the Plug & Charge EV, derived from the station and connector if empty
+}
+
+// NetworkProfile represents the network connection profile of a configuration slot
+type NetworkProfile struct {
+	Slot    int
+	Profile v201.NetworkConnectionProfile
 }
 
 // MeterValuesConfig represents meter values configuration
@@ -79,6 +100,37 @@ type SimulationConfig struct {
 	EnergyDeliveryRate         int
 	RandomizeMeterValues       bool
 	MeterValueVariance         float64
+	Firmware                   FirmwareSimulationConfig
+	EV                         EVSimulationConfig
+}
+
+// FirmwareSimulationConfig controls the simulated firmware update and diagnostics upload
+type FirmwareSimulationConfig struct {
+	DownloadDuration int    // Seconds per download attempt
+	InstallDuration  int    // Seconds
+	UploadDuration   int    // Seconds per upload attempt
+	DownloadFailures int    // Number of download attempts that fail before one succeeds
+	UploadFailures   int    // Number of upload attempts that fail before one succeeds
+	FailInstallation bool   // Report InstallationFailed after installing
+	TargetVersion    string // Firmware version after update, derived from the location if empty
+	PublishAddress   string // Listen address of the Local Controller file server, a random local port if empty
+}
+
+// EVSimulationConfig describes the simulated EV of a transaction. Zero values keep the default
+// EV, which charges at 5-7.5 kW without a battery model.
+type EVSimulationConfig struct {
+	BatteryCapacity   int  // Wh, 0 disables the state of charge
+	InitialSoC        int  // Percent at the start of a transaction
+	TargetSoC         int  // Percent the driver needs at departure
+	MinSoC            int  // Percent below which the EV does not discharge
+	MaxChargePower    int  // W
+	MaxDischargePower int  // W, 0 means no limit of its own
+	DepartureDuration int  // Seconds from the start of a transaction until departure
+	V2X               bool // Bidirectional EV, discharges at negative limits of OCPP 2.1 profiles
+	ISO15118          bool // Negotiates its charging needs and schedule with the CSMS (OCPP 2.0.1/2.1)
+	EnergyRequest     int  // Wh, derived from the battery and the target state of charge if 0
+	MinCurrent        int  // A per phase below which the EV does not charge, 6 A if 0
+	MaxCurrent        int  // A per phase, derived from the maximum charge power if 0
 }
 
 // RuntimeState represents the runtime state of a station
@@ -88,8 +140,12 @@ type RuntimeState struct {
 	LastHeartbeat    *time.Time
 	LastError        string
 	ConnectedAt      *time.Time
+	NetworkSlot      int // Network configuration slot of the current connection, 0 if not connected
 	TransactionID    *int
 	CurrentSession   *SessionInfo
+	QueuedMessages   int               // Transaction messages awaiting delivery to the CSMS
+	DisplayedMessage *v201.MessageInfo // Display message the EV driver currently sees
+	RunningCost      *RunningCost      // Last cost reported by the CSMS
 }
 
 // SessionInfo represents current session information
@@ -0,0 +1,474 @@
+package station
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+)
+
+// Core configuration keys (OCPP 1.6)
+const (
+	ConfigKeyAuthorizeRemoteTxRequests         = "AuthorizeRemoteTxRequests"
+	ConfigKeyClockAlignedDataInterval          = "ClockAlignedDataInterval"
+	ConfigKeyConnectionTimeOut                 = "ConnectionTimeOut"
+	ConfigKeyConnectorPhaseRotation            = "ConnectorPhaseRotation"
+	ConfigKeyGetConfigurationMaxKeys           = "GetConfigurationMaxKeys"
+	ConfigKeyHeartbeatInterval                 = "HeartbeatInterval"
+	ConfigKeyMeterValuesAlignedData            = "MeterValuesAlignedData"
+	ConfigKeyMeterValuesAlignedDataMaxLength   = "MeterValuesAlignedDataMaxLength"
+	ConfigKeyMeterValuesSampledData            = "MeterValuesSampledData"
+	ConfigKeyMeterValuesSampledDataMaxLength   = "MeterValuesSampledDataMaxLength"
+	ConfigKeyMeterValueSampleInterval          = "MeterValueSampleInterval"
+	ConfigKeyNumberOfConnectors                = "NumberOfConnectors"
+	ConfigKeyResetRetries                      = "ResetRetries"
+	ConfigKeyStopTransactionOnEVSideDisconnect = "StopTransactionOnEVSideDisconnect"
+	ConfigKeyStopTransactionOnInvalidId        = "StopTransactionOnInvalidId"
+	ConfigKeyStopTxnAlignedData                = "StopTxnAlignedData"
+	ConfigKeyStopTxnAlignedDataMaxLength       = "StopTxnAlignedDataMaxLength"
+	ConfigKeyStopTxnSampledData                = "StopTxnSampledData"
+	ConfigKeyStopTxnSampledDataMaxLength       = "StopTxnSampledDataMaxLength"
+	ConfigKeySupportedFeatureProfiles          = "SupportedFeatureProfiles"
+	ConfigKeyTransactionMessageAttempts        = "TransactionMessageAttempts"
+	ConfigKeyTransactionMessageRetryInterval   = "TransactionMessageRetryInterval"
+	ConfigKeyUnlockConnectorOnEVSideDisconnect = "UnlockConnectorOnEVSideDisconnect"
+	ConfigKeyWebSocketPingInterval             = "WebSocketPingInterval"
+)
+
+// Smart Charging configuration keys (OCPP 1.6)
+const (
+	ConfigKeyChargeProfileMaxStackLevel              = "ChargeProfileMaxStackLevel"
+	ConfigKeyChargingScheduleAllowedChargingRateUnit = "ChargingScheduleAllowedChargingRateUnit"
+	ConfigKeyChargingScheduleMaxPeriods              = "ChargingScheduleMaxPeriods"
+	ConfigKeyMaxChargingProfilesInstalled            = "MaxChargingProfilesInstalled"
+)
+
+const (
+	// defaultMeterValueSampleInterval is the interval of periodic meter values in seconds
+	defaultMeterValueSampleInterval = 60
+
+	// measurandListMaxLength is the maximum number of measurands in a meter value configuration key
+	measurandListMaxLength = 8
+)
+
+// supportedMeasurands are the measurands the simulated meter can sample
+var supportedMeasurands = []v16.Measurand{
+	v16.MeasurandEnergyActiveImportRegister,
+	v16.MeasurandEnergyActiveImportInterval,
+	v16.MeasurandEnergyActiveExportRegister,
+	v16.MeasurandPowerActiveImport,
+	v16.MeasurandPowerActiveExport,
+	v16.MeasurandPowerOffered,
+	v16.MeasurandCurrentImport,
+	v16.MeasurandCurrentOffered,
+	v16.MeasurandVoltage,
+}
+
+// defaultSampledData returns the measurands of periodic meter values if none are configured
+func defaultSampledData() []v16.Measurand {
+	return []v16.Measurand{v16.MeasurandEnergyActiveImportRegister, v16.MeasurandPowerActiveImport}
+}
+
+// ConfigValueType is the value type of a configuration key
+type ConfigValueType int
+
+const (
+	ConfigValueString ConfigValueType = iota
+	ConfigValueBool
+	ConfigValueInt
+	ConfigValueMeasurandList // Comma separated list of measurands
+)
+
+// ConfigKeyDefinition describes a configuration key of the registry
+type ConfigKeyDefinition struct {
+	Key            string
+	Type           ConfigValueType
+	Readonly       bool
+	RebootRequired bool // The new value only takes effect after a reboot
+	Min            int  // Minimum value of integer keys
+}
+
+// configKeyDefinitions lists the standard Core, Local Auth List Management and Smart Charging keys
+var configKeyDefinitions = []ConfigKeyDefinition{
+	// Core
+	{Key: ConfigKeyAllowOfflineTxForUnknownId, Type: ConfigValueBool},
+	{Key: ConfigKeyAuthorizationCacheEnabled, Type: ConfigValueBool},
+	{Key: ConfigKeyAuthorizeRemoteTxRequests, Type: ConfigValueBool},
+	{Key: ConfigKeyClockAlignedDataInterval, Type: ConfigValueInt},
+	{Key: ConfigKeyConnectionTimeOut, Type: ConfigValueInt},
+	{Key: ConfigKeyConnectorPhaseRotation, Type: ConfigValueString},
+	{Key: ConfigKeyGetConfigurationMaxKeys, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyHeartbeatInterval, Type: ConfigValueInt, Min: 1},
+	{Key: ConfigKeyLocalAuthorizeOffline, Type: ConfigValueBool},
+	{Key: ConfigKeyLocalPreAuthorize, Type: ConfigValueBool},
+	{Key: ConfigKeyMeterValuesAlignedData, Type: ConfigValueMeasurandList},
+	{Key: ConfigKeyMeterValuesAlignedDataMaxLength, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyMeterValuesSampledData, Type: ConfigValueMeasurandList},
+	{Key: ConfigKeyMeterValuesSampledDataMaxLength, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyMeterValueSampleInterval, Type: ConfigValueInt},
+	{Key: ConfigKeyNumberOfConnectors, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyResetRetries, Type: ConfigValueInt},
+	{Key: ConfigKeyStopTransactionOnEVSideDisconnect, Type: ConfigValueBool},
+	{Key: ConfigKeyStopTransactionOnInvalidId, Type: ConfigValueBool},
+	{Key: ConfigKeyStopTxnAlignedData, Type: ConfigValueMeasurandList},
+	{Key: ConfigKeyStopTxnAlignedDataMaxLength, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyStopTxnSampledData, Type: ConfigValueMeasurandList},
+	{Key: ConfigKeyStopTxnSampledDataMaxLength, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeySupportedFeatureProfiles, Type: ConfigValueString, Readonly: true},
+	{Key: ConfigKeyTransactionMessageAttempts, Type: ConfigValueInt},
+	{Key: ConfigKeyTransactionMessageRetryInterval, Type: ConfigValueInt},
+	{Key: ConfigKeyUnlockConnectorOnEVSideDisconnect, Type: ConfigValueBool},
+	{Key: ConfigKeyWebSocketPingInterval, Type: ConfigValueInt, RebootRequired: true},
+
+	// Local Auth List Management
+	{Key: ConfigKeyLocalAuthListEnabled, Type: ConfigValueBool},
+	{Key: ConfigKeyLocalAuthListMaxLength, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeySendLocalListMaxLength, Type: ConfigValueInt, Readonly: true},
+
+	// Smart Charging
+	{Key: ConfigKeyChargeProfileMaxStackLevel, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyChargingScheduleAllowedChargingRateUnit, Type: ConfigValueString, Readonly: true},
+	{Key: ConfigKeyChargingScheduleMaxPeriods, Type: ConfigValueInt, Readonly: true},
+	{Key: ConfigKeyMaxChargingProfilesInstalled, Type: ConfigValueInt, Readonly: true},
+}
+
+// ConfigurationStore is the OCPP 1.6 configuration key registry of a station
+type ConfigurationStore struct {
+	stationID   string
+	definitions map[string]ConfigKeyDefinition
+	values      map[string]string
+	mu          sync.RWMutex
+	logger      *slog.Logger
+
+	// Called after a key has been changed by the CSMS
+	OnChange func(key, value string)
+}
+
+// NewConfigurationStore creates the configuration key registry of a station.
+// Defaults are derived from the station configuration and overridden by the persisted values.
+func NewConfigurationStore(config Config, logger *slog.Logger) *ConfigurationStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	cs := &ConfigurationStore{
+		stationID:   config.StationID,
+		definitions: make(map[string]ConfigKeyDefinition, len(configKeyDefinitions)),
+		values:      make(map[string]string, len(configKeyDefinitions)),
+		logger:      logger,
+	}
+
+	for _, def := range configKeyDefinitions {
+		cs.definitions[def.Key] = def
+	}
+
+	for key, value := range defaultConfiguration(config) {
+		cs.values[key] = value
+	}
+
+	for key, value := range config.OCPPConfiguration {
+		def, exists := cs.definitions[key]
+		if !exists || def.Readonly {
+			continue
+		}
+
+		normalized, err := cs.validate(def, value)
+		if err != nil {
+			logger.Warn("Ignoring invalid persisted configuration value",
+				"stationId", config.StationID,
+				"key", key,
+				"error", err,
+			)
+			continue
+		}
+		cs.values[key] = normalized
+	}
+
+	return cs
+}
+
+// defaultConfiguration returns the initial values of all configuration keys
+func defaultConfiguration(config Config) map[string]string {
+	auth := DefaultAuthorizationConfig()
+
+	heartbeatInterval := config.Simulation.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 60
+	}
+
+	sampleInterval := config.MeterValuesConfig.Interval
+	if sampleInterval <= 0 {
+		sampleInterval = defaultMeterValueSampleInterval
+	}
+
+	var sampledData []string
+	for _, measurand := range config.MeterValuesConfig.Measurands {
+		if isSupportedMeasurand(measurand) {
+			sampledData = append(sampledData, measurand)
+		}
+	}
+	if len(sampledData) == 0 {
+		for _, measurand := range defaultSampledData() {
+			sampledData = append(sampledData, string(measurand))
+		}
+	}
+
+	profiles := config.SupportedProfiles
+	if len(profiles) == 0 {
+		profiles = []string{"Core"}
+	}
+
+	maxLength := strconv.Itoa(measurandListMaxLength)
+
+	return map[string]string{
+		ConfigKeyAllowOfflineTxForUnknownId:        strconv.FormatBool(auth.AllowOfflineTxForUnknownId),
+		ConfigKeyAuthorizationCacheEnabled:         strconv.FormatBool(auth.AuthorizationCacheEnabled),
+		ConfigKeyAuthorizeRemoteTxRequests:         "true",
+		ConfigKeyClockAlignedDataInterval:          strconv.Itoa(config.MeterValuesConfig.AlignedDataInterval),
+		ConfigKeyConnectionTimeOut:                 "60",
+		ConfigKeyConnectorPhaseRotation:            "NotApplicable",
+		ConfigKeyGetConfigurationMaxKeys:           "100",
+		ConfigKeyHeartbeatInterval:                 strconv.Itoa(heartbeatInterval),
+		ConfigKeyLocalAuthorizeOffline:             strconv.FormatBool(auth.LocalAuthorizeOffline),
+		ConfigKeyLocalPreAuthorize:                 strconv.FormatBool(auth.LocalPreAuthorize),
+		ConfigKeyMeterValuesAlignedData:            string(v16.MeasurandEnergyActiveImportRegister),
+		ConfigKeyMeterValuesAlignedDataMaxLength:   maxLength,
+		ConfigKeyMeterValuesSampledData:            strings.Join(sampledData, ","),
+		ConfigKeyMeterValuesSampledDataMaxLength:   maxLength,
+		ConfigKeyMeterValueSampleInterval:          strconv.Itoa(sampleInterval),
+		ConfigKeyNumberOfConnectors:                strconv.Itoa(len(config.Connectors)),
+		ConfigKeyResetRetries:                      "3",
+		ConfigKeyStopTransactionOnEVSideDisconnect: "true",
+		ConfigKeyStopTransactionOnInvalidId:        "true",
+		ConfigKeyStopTxnAlignedData:                "",
+		ConfigKeyStopTxnAlignedDataMaxLength:       maxLength,
+		ConfigKeyStopTxnSampledData:                "",
+		ConfigKeyStopTxnSampledDataMaxLength:       maxLength,
+		ConfigKeySupportedFeatureProfiles:          strings.Join(profiles, ","),
+		ConfigKeyTransactionMessageAttempts:        "3",
+		ConfigKeyTransactionMessageRetryInterval:   "60",
+		ConfigKeyUnlockConnectorOnEVSideDisconnect: "true",
+		ConfigKeyWebSocketPingInterval:             "30",
+
+		ConfigKeyLocalAuthListEnabled:   strconv.FormatBool(auth.LocalAuthListEnabled),
+		ConfigKeyLocalAuthListMaxLength: strconv.Itoa(auth.LocalAuthListMaxLength),
+		ConfigKeySendLocalListMaxLength: strconv.Itoa(auth.SendLocalListMaxLength),
+
+		ConfigKeyChargeProfileMaxStackLevel:              "10",
+		ConfigKeyChargingScheduleAllowedChargingRateUnit: "Current,Power",
+		ConfigKeyChargingScheduleMaxPeriods:              "24",
+		ConfigKeyMaxChargingProfilesInstalled:            "10",
+	}
+}
+
+// isSupportedMeasurand reports whether the simulated meter can sample the measurand
+func isSupportedMeasurand(measurand string) bool {
+	for _, supported := range supportedMeasurands {
+		if string(supported) == measurand {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the requested configuration keys, or all keys if none are requested.
+// Keys that are not in the registry are returned as unknown.
+func (cs *ConfigurationStore) Get(keys []string) ([]v16.KeyValue, []string) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if len(keys) == 0 {
+		keyValues := make([]v16.KeyValue, 0, len(configKeyDefinitions))
+		for _, def := range configKeyDefinitions {
+			keyValues = append(keyValues, cs.keyValue(def))
+		}
+		return keyValues, nil
+	}
+
+	keyValues := []v16.KeyValue{}
+	var unknown []string
+	for _, key := range keys {
+		def, exists := cs.definitions[key]
+		if !exists {
+			unknown = append(unknown, key)
+			continue
+		}
+		keyValues = append(keyValues, cs.keyValue(def))
+	}
+
+	return keyValues, unknown
+}
+
+// keyValue returns a configuration key as reported to the CSMS (caller must hold the lock)
+func (cs *ConfigurationStore) keyValue(def ConfigKeyDefinition) v16.KeyValue {
+	return v16.KeyValue{Key: def.Key, Readonly: def.Readonly, Value: cs.values[def.Key]}
+}
+
+// Change validates and applies a configuration change requested by the CSMS
+func (cs *ConfigurationStore) Change(key, value string) (v16.ConfigurationStatus, error) {
+	def, exists := cs.definitions[key]
+	if !exists {
+		return v16.ConfigurationStatusNotSupported, fmt.Errorf("unknown configuration key %s", key)
+	}
+	if def.Readonly {
+		return v16.ConfigurationStatusRejected, fmt.Errorf("configuration key %s is read-only", key)
+	}
+
+	normalized, err := cs.validate(def, value)
+	if err != nil {
+		return v16.ConfigurationStatusRejected, err
+	}
+
+	cs.mu.Lock()
+	cs.values[key] = normalized
+	cs.mu.Unlock()
+
+	cs.logger.Info("Configuration changed", "stationId", cs.stationID, "key", key, "value", normalized)
+
+	if cs.OnChange != nil {
+		cs.OnChange(key, normalized)
+	}
+
+	if def.RebootRequired {
+		return v16.ConfigurationStatusRebootRequired, nil
+	}
+	return v16.ConfigurationStatusAccepted, nil
+}
+
+// Set updates a configuration key on behalf of the station itself, e.g. the
+// heartbeat interval received in a BootNotification response
+func (cs *ConfigurationStore) Set(key, value string) error {
+	def, exists := cs.definitions[key]
+	if !exists {
+		return fmt.Errorf("unknown configuration key %s", key)
+	}
+
+	normalized, err := cs.validate(def, value)
+	if err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	cs.values[key] = normalized
+	cs.mu.Unlock()
+
+	return nil
+}
+
+// validate checks a value against the key definition and returns its normalized form
+func (cs *ConfigurationStore) validate(def ConfigKeyDefinition, value string) (string, error) {
+	switch def.Type {
+	case ConfigValueBool:
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s: %s", def.Key, value)
+		}
+		return strconv.FormatBool(enabled), nil
+
+	case ConfigValueInt:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s: %s", def.Key, value)
+		}
+		if n < def.Min {
+			return "", fmt.Errorf("value for %s must be at least %d", def.Key, def.Min)
+		}
+		return strconv.Itoa(n), nil
+
+	case ConfigValueMeasurandList:
+		var measurands []string
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if !isSupportedMeasurand(item) {
+				return "", fmt.Errorf("unsupported measurand %s for %s", item, def.Key)
+			}
+			measurands = append(measurands, item)
+		}
+		if maxLength := cs.Int(def.Key + "MaxLength"); maxLength > 0 && len(measurands) > maxLength {
+			return "", fmt.Errorf("%s supports at most %d measurands", def.Key, maxLength)
+		}
+		return strings.Join(measurands, ","), nil
+	}
+
+	return value, nil
+}
+
+// Value returns the value of a configuration key
+func (cs *ConfigurationStore) Value(key string) (string, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	value, exists := cs.values[key]
+	return value, exists
+}
+
+// Int returns the value of an integer configuration key, 0 if it is not set
+func (cs *ConfigurationStore) Int(key string) int {
+	value, _ := cs.Value(key)
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// Bool returns the value of a boolean configuration key, false if it is not set
+func (cs *ConfigurationStore) Bool(key string) bool {
+	value, _ := cs.Value(key)
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// Measurands returns the value of a measurand list configuration key
+func (cs *ConfigurationStore) Measurands(key string) []v16.Measurand {
+	value, _ := cs.Value(key)
+
+	var measurands []v16.Measurand
+	for _, item := range strings.Split(value, ",") {
+		if item != "" {
+			measurands = append(measurands, v16.Measurand(item))
+		}
+	}
+	return measurands
+}
+
+// AuthorizationConfig returns the local authorization configuration held by the registry
+func (cs *ConfigurationStore) AuthorizationConfig() AuthorizationConfig {
+	return AuthorizationConfig{
+		LocalAuthListEnabled:       cs.Bool(ConfigKeyLocalAuthListEnabled),
+		LocalAuthListMaxLength:     cs.Int(ConfigKeyLocalAuthListMaxLength),
+		SendLocalListMaxLength:     cs.Int(ConfigKeySendLocalListMaxLength),
+		LocalPreAuthorize:          cs.Bool(ConfigKeyLocalPreAuthorize),
+		LocalAuthorizeOffline:      cs.Bool(ConfigKeyLocalAuthorizeOffline),
+		AuthorizationCacheEnabled:  cs.Bool(ConfigKeyAuthorizationCacheEnabled),
+		AllowOfflineTxForUnknownId: cs.Bool(ConfigKeyAllowOfflineTxForUnknownId),
+	}
+}
+
+// IsAuthorizationKey reports whether a key configures the local authorization
+func IsAuthorizationKey(key string) bool {
+	switch key {
+	case ConfigKeyLocalAuthListEnabled, ConfigKeyLocalAuthListMaxLength, ConfigKeySendLocalListMaxLength,
+		ConfigKeyLocalPreAuthorize, ConfigKeyLocalAuthorizeOffline, ConfigKeyAuthorizationCacheEnabled,
+		ConfigKeyAllowOfflineTxForUnknownId:
+		return true
+	}
+	return false
+}
+
+// Values returns the writable configuration values for persistence
+func (cs *ConfigurationStore) Values() map[string]string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	values := make(map[string]string)
+	for key, value := range cs.values {
+		if !cs.definitions[key].Readonly {
+			values[key] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,136 @@
+package station
+
+import (
+	"testing"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+)
+
+func newTestConfigurationStore(persisted map[string]string) *ConfigurationStore {
+	config := Config{
+		StationID:         "CP001",
+		Connectors:        []ConnectorConfig{{ID: 1}, {ID: 2}},
+		SupportedProfiles: []string{"Core", "SmartCharging"},
+		Simulation:        SimulationConfig{HeartbeatInterval: 300},
+		OCPPConfiguration: persisted,
+	}
+	return NewConfigurationStore(config, nil)
+}
+
+func TestConfigurationStore_Defaults(t *testing.T) {
+	cs := newTestConfigurationStore(nil)
+
+	if cs.Int(ConfigKeyHeartbeatInterval) != 300 {
+		t.Errorf("Expected HeartbeatInterval 300, got %d", cs.Int(ConfigKeyHeartbeatInterval))
+	}
+	if cs.Int(ConfigKeyNumberOfConnectors) != 2 {
+		t.Errorf("Expected NumberOfConnectors 2, got %d", cs.Int(ConfigKeyNumberOfConnectors))
+	}
+	if cs.Int(ConfigKeyMeterValueSampleInterval) != defaultMeterValueSampleInterval {
+		t.Errorf("Expected default MeterValueSampleInterval, got %d", cs.Int(ConfigKeyMeterValueSampleInterval))
+	}
+	if value, _ := cs.Value(ConfigKeySupportedFeatureProfiles); value != "Core,SmartCharging" {
+		t.Errorf("Expected SupportedFeatureProfiles Core,SmartCharging, got %s", value)
+	}
+
+	keyValues, unknown := cs.Get([]string{ConfigKeyNumberOfConnectors, "UnknownKey"})
+	if len(keyValues) != 1 || !keyValues[0].Readonly {
+		t.Errorf("Expected read-only NumberOfConnectors, got %+v", keyValues)
+	}
+	if len(unknown) != 1 || unknown[0] != "UnknownKey" {
+		t.Errorf("Expected UnknownKey to be reported, got %v", unknown)
+	}
+
+	all, _ := cs.Get(nil)
+	if len(all) != len(configKeyDefinitions) {
+		t.Errorf("Expected %d keys, got %d", len(configKeyDefinitions), len(all))
+	}
+}
+
+func TestConfigurationStore_Change(t *testing.T) {
+	cs := newTestConfigurationStore(nil)
+
+	var changed []string
+	cs.OnChange = func(key, value string) {
+		changed = append(changed, key+"="+value)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		status v16.ConfigurationStatus
+	}{
+		{"UnknownKey", "1", v16.ConfigurationStatusNotSupported},
+		{ConfigKeyNumberOfConnectors, "4", v16.ConfigurationStatusRejected},
+		{ConfigKeyHeartbeatInterval, "abc", v16.ConfigurationStatusRejected},
+		{ConfigKeyHeartbeatInterval, "0", v16.ConfigurationStatusRejected},
+		{ConfigKeyHeartbeatInterval, "120", v16.ConfigurationStatusAccepted},
+		{ConfigKeyStopTransactionOnInvalidId, "FALSE", v16.ConfigurationStatusAccepted},
+		{ConfigKeyMeterValuesSampledData, "Energy.Active.Import.Register,SoC", v16.ConfigurationStatusRejected},
+		{ConfigKeyMeterValuesSampledData, "Energy.Active.Import.Register, Voltage", v16.ConfigurationStatusAccepted},
+		{ConfigKeyWebSocketPingInterval, "60", v16.ConfigurationStatusRebootRequired},
+	}
+
+	for _, tt := range tests {
+		status, _ := cs.Change(tt.key, tt.value)
+		if status != tt.status {
+			t.Errorf("Change(%s, %s): expected %s, got %s", tt.key, tt.value, tt.status, status)
+		}
+	}
+
+	if cs.Int(ConfigKeyHeartbeatInterval) != 120 {
+		t.Errorf("Expected HeartbeatInterval 120, got %d", cs.Int(ConfigKeyHeartbeatInterval))
+	}
+	if cs.Bool(ConfigKeyStopTransactionOnInvalidId) {
+		t.Error("Expected StopTransactionOnInvalidId to be disabled")
+	}
+
+	measurands := cs.Measurands(ConfigKeyMeterValuesSampledData)
+	if len(measurands) != 2 || measurands[1] != v16.MeasurandVoltage {
+		t.Errorf("Expected normalized measurand list, got %v", measurands)
+	}
+
+	expected := []string{
+		"HeartbeatInterval=120",
+		"StopTransactionOnInvalidId=false",
+		"MeterValuesSampledData=Energy.Active.Import.Register,Voltage",
+		"WebSocketPingInterval=60",
+	}
+	if len(changed) != len(expected) {
+		t.Fatalf("Expected %d change callbacks, got %v", len(expected), changed)
+	}
+	for i := range expected {
+		if changed[i] != expected[i] {
+			t.Errorf("Expected change %s, got %s", expected[i], changed[i])
+		}
+	}
+}
+
+func TestConfigurationStore_Persistence(t *testing.T) {
+	cs := newTestConfigurationStore(nil)
+	cs.Change(ConfigKeyMeterValueSampleInterval, "15")
+	cs.Change(ConfigKeyLocalPreAuthorize, "true")
+
+	values := cs.Values()
+	if _, exists := values[ConfigKeyNumberOfConnectors]; exists {
+		t.Error("Read-only keys must not be persisted")
+	}
+
+	// Invalid and read-only persisted values are ignored
+	values[ConfigKeyNumberOfConnectors] = "8"
+	values[ConfigKeyHeartbeatInterval] = "-5"
+
+	restored := newTestConfigurationStore(values)
+	if restored.Int(ConfigKeyMeterValueSampleInterval) != 15 {
+		t.Errorf("Expected restored MeterValueSampleInterval 15, got %d", restored.Int(ConfigKeyMeterValueSampleInterval))
+	}
+	if !restored.AuthorizationConfig().LocalPreAuthorize {
+		t.Error("Expected restored LocalPreAuthorize to be enabled")
+	}
+	if restored.Int(ConfigKeyNumberOfConnectors) != 2 {
+		t.Errorf("Expected NumberOfConnectors 2, got %d", restored.Int(ConfigKeyNumberOfConnectors))
+	}
+	if restored.Int(ConfigKeyHeartbeatInterval) != 300 {
+		t.Errorf("Expected default HeartbeatInterval 300, got %d", restored.Int(ConfigKeyHeartbeatInterval))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
 )
 
 // ConnectorState represents the state of a connector following OCPP 1.6 spec
@@ -48,6 +49,8 @@ type Transaction struct {
 	StartTime       time.Time
 	StartMeterValue int
 	CurrentMeter    int
+	ExportMeter     int     // Wh the EV discharged to the grid (V2X)
+	SoC             float64 // Percent, state of charge of the simulated EV battery
 	StopTime        *time.Time
 	StopMeterValue  *int
 	StopReason      v16.Reason
@@ -71,6 +74,10 @@ type Reservation struct {
 	IDTag       string
 	ExpiryDate  time.Time
 	ParentIDTag string
+
+	// ConnectorType restricts an OCPP 2.0.1 reservation without EVSE to connectors of this
+	// type, empty for any connector
+	ConnectorType v201.ConnectorType
 }
 
 // NewConnector creates a new connector
@@ -270,6 +277,22 @@ func (c *Connector) UpdateTransactionID(newID int) error {
 	return nil
 }
 
+// SetStringTransactionID sets the OCPP 2.0.1 transaction ID of the current transaction
+func (c *Connector) SetStringTransactionID(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Transaction == nil {
+		return fmt.Errorf("connector %d has no active transaction", c.ID)
+	}
+
+	c.Transaction.mu.Lock()
+	c.Transaction.StringID = id
+	c.Transaction.mu.Unlock()
+
+	return nil
+}
+
 // AddMeterValue adds a meter value sample to the current transaction
 func (c *Connector) AddMeterValue(sample MeterValueSample) error {
 	c.mu.Lock()
@@ -303,12 +326,33 @@ func (c *Connector) UpdateMeter(value int) error {
 	return nil
 }
 
+// UpdateEnergy updates the import and export registers and the EV state of charge
+// of the active transaction
+func (c *Connector) UpdateEnergy(importMeter, exportMeter int, soc float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Transaction == nil {
+		return fmt.Errorf("connector %d has no active transaction", c.ID)
+	}
+
+	c.Transaction.mu.Lock()
+	c.Transaction.CurrentMeter = importMeter
+	c.Transaction.ExportMeter = exportMeter
+	c.Transaction.SoC = soc
+	c.Transaction.mu.Unlock()
+
+	return nil
+}
+
 // Reserve reserves the connector
 func (c *Connector) Reserve(reservationID int, idTag string, expiryDate time.Time, parentIDTag string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.State != ConnectorStateAvailable {
+	// A reservation can be replaced by one with the same ID
+	sameReservation := c.State == ConnectorStateReserved && c.Reservation != nil && c.Reservation.ID == reservationID
+	if c.State != ConnectorStateAvailable && !sameReservation {
 		return fmt.Errorf("connector %d is not available for reservation (state: %s)", c.ID, c.State)
 	}
 
@@ -335,6 +379,19 @@ func (c *Connector) CancelReservation() error {
 	return nil
 }
 
+// GetReservation returns a copy of the current reservation, or nil
+func (c *Connector) GetReservation() *Reservation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Reservation == nil {
+		return nil
+	}
+
+	reservation := *c.Reservation
+	return &reservation
+}
+
 // IsReserved checks if connector is reserved
 func (c *Connector) IsReserved() bool {
 	c.mu.RLock()
@@ -0,0 +1,248 @@
+package station
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// RunningCost is the last cost of a transaction reported by the CSMS
+type RunningCost struct {
+	TransactionID string
+	TotalCost     float64
+	UpdatedAt     time.Time
+}
+
+// displayMessage is a stored display message, seq orders the messages by the time they were set
+type displayMessage struct {
+	info v201.MessageInfo
+	seq  int
+}
+
+// DisplayMessageStore holds the messages shown on the display of an OCPP 2.0.1/2.1 station
+// and the running cost of its transactions
+type DisplayMessageStore struct {
+	mu       sync.RWMutex
+	messages map[int]*displayMessage
+	seq      int
+	cost     *RunningCost
+}
+
+// NewDisplayMessageStore creates an empty display message store
+func NewDisplayMessageStore() *DisplayMessageStore {
+	return &DisplayMessageStore{
+		messages: make(map[int]*displayMessage),
+	}
+}
+
+// Set stores a message, replacing the message with the same id. Returns false if the store
+// already holds maxMessages other messages, a limit of 0 means unlimited.
+func (s *DisplayMessageStore) Set(info v201.MessageInfo, maxMessages int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(time.Now())
+
+	if _, exists := s.messages[info.Id]; !exists && maxMessages > 0 && len(s.messages) >= maxMessages {
+		return false
+	}
+
+	s.seq++
+	s.messages[info.Id] = &displayMessage{info: info, seq: s.seq}
+	return true
+}
+
+// Get returns the messages matching the ids, priority and state, sorted by id. Empty criteria
+// match all messages.
+func (s *DisplayMessageStore) Get(ids []int, priority *v201.MessagePriorityType, state *v201.MessageStateType) []v201.MessageInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(time.Now())
+
+	var result []v201.MessageInfo
+	for id, msg := range s.messages {
+		if len(ids) > 0 && !containsInt(ids, id) {
+			continue
+		}
+		if priority != nil && msg.info.Priority != *priority {
+			continue
+		}
+		if state != nil && (msg.info.State == nil || *msg.info.State != *state) {
+			continue
+		}
+		result = append(result, msg.info)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result
+}
+
+// Clear removes a message and reports whether it existed
+func (s *DisplayMessageStore) Clear(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.messages[id]; !exists {
+		return false
+	}
+	delete(s.messages, id)
+	return true
+}
+
+// EndTransaction removes the messages bound to a transaction that has ended and returns
+// how many were removed
+func (s *DisplayMessageStore) EndTransaction(transactionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, msg := range s.messages {
+		if msg.info.TransactionId == transactionID {
+			delete(s.messages, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of stored messages
+func (s *DisplayMessageStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(time.Now())
+	return len(s.messages)
+}
+
+// Displayed returns the message the driver currently sees in the given station state, nil if
+// no message is shown. Messages outside their validity window or of another state are skipped.
+// AlwaysFront messages go before InFront messages, which go before the NormalCycle; within a
+// priority the message set last is shown.
+func (s *DisplayMessageStore) Displayed(state v201.MessageStateType) *v201.MessageInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	var shown *displayMessage
+	for _, msg := range s.messages {
+		if msg.info.StartDateTime != nil && now.Before(msg.info.StartDateTime.Time) {
+			continue
+		}
+		if msg.info.EndDateTime != nil && !now.Before(msg.info.EndDateTime.Time) {
+			continue
+		}
+		if msg.info.State != nil && *msg.info.State != state {
+			continue
+		}
+
+		if shown == nil {
+			shown = msg
+			continue
+		}
+		if rank, shownRank := priorityRank(msg.info.Priority), priorityRank(shown.info.Priority); rank < shownRank || (rank == shownRank && msg.seq > shown.seq) {
+			shown = msg
+		}
+	}
+
+	if shown == nil {
+		return nil
+	}
+	info := shown.info
+	return &info
+}
+
+// UpdateCost records the running cost of a transaction
+func (s *DisplayMessageStore) UpdateCost(transactionID string, totalCost float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cost = &RunningCost{
+		TransactionID: transactionID,
+		TotalCost:     totalCost,
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// RunningCost returns the last reported cost, nil if none has been reported
+func (s *DisplayMessageStore) RunningCost() *RunningCost {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.cost == nil {
+		return nil
+	}
+	cost := *s.cost
+	return &cost
+}
+
+// removeExpired removes the messages whose end date has passed. Must be called with the lock held.
+func (s *DisplayMessageStore) removeExpired(now time.Time) {
+	for id, msg := range s.messages {
+		if msg.info.EndDateTime != nil && !now.Before(msg.info.EndDateTime.Time) {
+			delete(s.messages, id)
+		}
+	}
+}
+
+// priorityRank orders display message priorities, lower ranks are shown first
+func priorityRank(priority v201.MessagePriorityType) int {
+	switch priority {
+	case v201.MessagePriorityAlwaysFront:
+		return 0
+	case v201.MessagePriorityInFront:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// messageInfoFromV21 converts an OCPP 2.1 display message to its OCPP 2.0.1 equivalent
+func messageInfoFromV21(m v21.DisplayMessageType) (v201.MessageInfo, error) {
+	info := v201.MessageInfo{
+		Priority: v201.MessagePriorityType(m.Priority),
+		Message: v201.MessageContent{
+			Format:  v201.MessageFormatType(m.Message.Format),
+			Content: m.Message.Content,
+		},
+	}
+	if m.ID == nil {
+		return v201.MessageInfo{}, fmt.Errorf("missing message id")
+	}
+	info.Id = *m.ID
+	if m.State != nil {
+		state := v201.MessageStateType(*m.State)
+		info.State = &state
+	}
+	if m.TransactionId != nil {
+		info.TransactionId = *m.TransactionId
+	}
+	if m.Message.Language != nil {
+		info.Message.Language = *m.Message.Language
+	}
+
+	var err error
+	if info.StartDateTime, err = parseOptionalDateTime(m.StartDateTime); err != nil {
+		return v201.MessageInfo{}, fmt.Errorf("invalid startDateTime: %w", err)
+	}
+	if info.EndDateTime, err = parseOptionalDateTime(m.EndDateTime); err != nil {
+		return v201.MessageInfo{}, fmt.Errorf("invalid endDateTime: %w", err)
+	}
+
+	return info, nil
+}
+
+// containsInt reports whether a slice contains a value
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package station
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func newDisplayMessage(id int, priority v201.MessagePriorityType, content string) v201.MessageInfo {
+	return v201.MessageInfo{
+		Id:       id,
+		Priority: priority,
+		Message:  v201.MessageContent{Format: v201.MessageFormatUTF8, Content: content},
+	}
+}
+
+func TestDisplayMessageStore_Displayed(t *testing.T) {
+	store := NewDisplayMessageStore()
+
+	if store.Displayed(v201.MessageStateIdle) != nil {
+		t.Error("Expected no message on an empty display")
+	}
+
+	store.Set(newDisplayMessage(1, v201.MessagePriorityNormalCycle, "Welcome"), 0)
+	store.Set(newDisplayMessage(2, v201.MessagePriorityNormalCycle, "Scan your card"), 0)
+	if shown := store.Displayed(v201.MessageStateIdle); shown == nil || shown.Id != 2 {
+		t.Errorf("Expected the last NormalCycle message 2, got %+v", shown)
+	}
+
+	store.Set(newDisplayMessage(3, v201.MessagePriorityInFront, "Reduced power"), 0)
+	store.Set(newDisplayMessage(1, v201.MessagePriorityNormalCycle, "Welcome back"), 0)
+	if shown := store.Displayed(v201.MessageStateIdle); shown == nil || shown.Id != 3 {
+		t.Errorf("Expected InFront message 3, got %+v", shown)
+	}
+
+	// AlwaysFront only while charging
+	charging := v201.MessageStateCharging
+	alwaysFront := newDisplayMessage(4, v201.MessagePriorityAlwaysFront, "Charging")
+	alwaysFront.State = &charging
+	store.Set(alwaysFront, 0)
+	if shown := store.Displayed(v201.MessageStateIdle); shown == nil || shown.Id != 3 {
+		t.Errorf("Expected InFront message 3 while idle, got %+v", shown)
+	}
+	if shown := store.Displayed(v201.MessageStateCharging); shown == nil || shown.Id != 4 {
+		t.Errorf("Expected AlwaysFront message 4 while charging, got %+v", shown)
+	}
+
+	// Validity window
+	future := newDisplayMessage(5, v201.MessagePriorityAlwaysFront, "Maintenance tonight")
+	future.StartDateTime = &v201.DateTime{Time: time.Now().Add(time.Hour)}
+	store.Set(future, 0)
+	expired := newDisplayMessage(6, v201.MessagePriorityAlwaysFront, "Old news")
+	expired.EndDateTime = &v201.DateTime{Time: time.Now().Add(-time.Minute)}
+	store.Set(expired, 0)
+	if shown := store.Displayed(v201.MessageStateIdle); shown == nil || shown.Id != 3 {
+		t.Errorf("Expected messages outside their validity window to be skipped, got %+v", shown)
+	}
+	if store.Len() != 5 {
+		t.Errorf("Expected expired message to be removed, got %d messages", store.Len())
+	}
+
+	if !store.Clear(3) || store.Clear(3) {
+		t.Error("Expected message 3 to be cleared once")
+	}
+	if shown := store.Displayed(v201.MessageStateIdle); shown == nil || shown.Id != 1 || shown.Message.Content != "Welcome back" {
+		t.Errorf("Expected replaced message 1, got %+v", shown)
+	}
+}
+
+func TestDisplayMessageStore_GetAndTransactions(t *testing.T) {
+	store := NewDisplayMessageStore()
+
+	if !store.Set(newDisplayMessage(1, v201.MessagePriorityNormalCycle, "Welcome"), 2) {
+		t.Fatal("Expected message 1 to be stored")
+	}
+	txMessage := newDisplayMessage(2, v201.MessagePriorityInFront, "Enjoy your charge")
+	txMessage.TransactionId = "tx-1"
+	store.Set(txMessage, 2)
+
+	if store.Set(newDisplayMessage(3, v201.MessagePriorityNormalCycle, "Full"), 2) {
+		t.Error("Expected a full store to reject a new message")
+	}
+	if !store.Set(newDisplayMessage(1, v201.MessagePriorityNormalCycle, "Replaced"), 2) {
+		t.Error("Expected a full store to replace an existing message")
+	}
+
+	if messages := store.Get(nil, nil, nil); len(messages) != 2 || messages[0].Id != 1 || messages[1].Id != 2 {
+		t.Errorf("Expected messages 1 and 2, got %+v", messages)
+	}
+	inFront := v201.MessagePriorityInFront
+	if messages := store.Get(nil, &inFront, nil); len(messages) != 1 || messages[0].Id != 2 {
+		t.Errorf("Expected InFront message 2, got %+v", messages)
+	}
+	if messages := store.Get([]int{1, 7}, &inFront, nil); len(messages) != 0 {
+		t.Errorf("Expected no message matching all criteria, got %+v", messages)
+	}
+	idle := v201.MessageStateIdle
+	if messages := store.Get(nil, nil, &idle); len(messages) != 0 {
+		t.Errorf("Expected no message for state Idle, got %+v", messages)
+	}
+
+	if removed := store.EndTransaction("tx-1"); removed != 1 || store.Len() != 1 {
+		t.Errorf("Expected the transaction message to be removed, removed %d, %d left", removed, store.Len())
+	}
+
+	if store.RunningCost() != nil {
+		t.Error("Expected no running cost")
+	}
+	store.UpdateCost("tx-1", 4.25)
+	if cost := store.RunningCost(); cost == nil || cost.TransactionID != "tx-1" || cost.TotalCost != 4.25 {
+		t.Errorf("Expected running cost 4.25 of tx-1, got %+v", cost)
+	}
+}
+
+func TestHandleV201DisplayMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	deviceModel := v201.NewDeviceModel()
+	station := &Station{
+		Config:          Config{StationID: "TEST020", ProtocolVersion: "ocpp2.0.1"},
+		StateMachine:    NewStateMachine(),
+		SessionManager:  sm,
+		DeviceModel:     deviceModel,
+		DisplayMessages: NewDisplayMessageStore(),
+	}
+	manager.mu.Lock()
+	manager.stations["TEST020"] = station
+	manager.mu.Unlock()
+
+	resp, _ := manager.v201Handler.OnSetDisplayMessage("TEST020", &v201.SetDisplayMessageRequest{
+		Message: newDisplayMessage(1, v201.MessagePriorityNormalCycle, "Welcome"),
+	})
+	if resp.Status != v201.DisplayMessageStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", resp.Status)
+	}
+	if count, _ := deviceModel.GetVariable("DisplayMessageCtrlr", "", "DisplayMessages", "", v201.AttributeActual); count != "1" {
+		t.Errorf("Expected DisplayMessageCtrlr.DisplayMessages 1, got %s", count)
+	}
+
+	unknownTx := newDisplayMessage(2, v201.MessagePriorityInFront, "Your session")
+	unknownTx.TransactionId = "unknown"
+	if resp, _ := manager.v201Handler.OnSetDisplayMessage("TEST020", &v201.SetDisplayMessageRequest{Message: unknownTx}); resp.Status != v201.DisplayMessageStatusUnknownTransaction {
+		t.Errorf("Expected UnknownTransaction, got %s", resp.Status)
+	}
+
+	unsupported := newDisplayMessage(3, "Blinking", "Hello")
+	if resp, _ := manager.v201Handler.OnSetDisplayMessage("TEST020", &v201.SetDisplayMessageRequest{Message: unsupported}); resp.Status != v201.DisplayMessageStatusNotSupportedPriority {
+		t.Errorf("Expected NotSupportedPriority, got %s", resp.Status)
+	}
+
+	deviceModel.ImportProfile(&v201.DeviceModelProfile{Components: []v201.ComponentProfile{{
+		Name: "DisplayMessageCtrlr",
+		Variables: []v201.VariableProfile{{
+			Name:            "SupportedFormats",
+			Characteristics: v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "ASCII,HTML,URI,UTF8"},
+			Attributes:      []v201.VariableAttribute{{Type: v201.AttributeActual, Value: "ASCII", Mutability: v201.MutabilityReadOnly}},
+		}},
+	}}})
+	if resp, _ := manager.v201Handler.OnSetDisplayMessage("TEST020", &v201.SetDisplayMessageRequest{Message: newDisplayMessage(4, v201.MessagePriorityInFront, "Grüße")}); resp.Status != v201.DisplayMessageStatusNotSupportedMessageFormat {
+		t.Errorf("Expected NotSupportedMessageFormat, got %s", resp.Status)
+	}
+
+	// Messages bound to a running transaction
+	if _, err := sm.StartRemoteCharging(1, v201.IdToken{IdToken: "TAG1", Type: v201.IdTokenTypeISO14443}, 1); err != nil {
+		t.Fatalf("StartRemoteCharging failed: %v", err)
+	}
+	connector, _ := sm.GetConnector(1)
+	transactionID := connector.GetTransaction().StringID
+
+	txMessage := newDisplayMessage(5, v201.MessagePriorityInFront, "Charging at 11 kW")
+	txMessage.Message.Format = v201.MessageFormatASCII
+	txMessage.TransactionId = transactionID
+	if resp, _ := manager.v201Handler.OnSetDisplayMessage("TEST020", &v201.SetDisplayMessageRequest{Message: txMessage}); resp.Status != v201.DisplayMessageStatusAccepted {
+		t.Errorf("Expected transaction message to be accepted, got %s", resp.Status)
+	}
+
+	manager.v201Handler.OnCostUpdated("TEST020", &v201.CostUpdatedRequest{TotalCost: 1.5, TransactionId: transactionID})
+
+	_, state := station.GetData()
+	if state.DisplayedMessage == nil || state.DisplayedMessage.Id != 5 {
+		t.Errorf("Expected transaction message 5 on the display, got %+v", state.DisplayedMessage)
+	}
+	if state.RunningCost == nil || state.RunningCost.TotalCost != 1.5 {
+		t.Errorf("Expected running cost 1.5, got %+v", state.RunningCost)
+	}
+
+	if err := sm.StopCharging(1, v16.ReasonRemote); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+
+	// GetDisplayMessages
+	if resp, _ := manager.v201Handler.OnGetDisplayMessages("TEST020", &v201.GetDisplayMessagesRequest{RequestId: 1, Id: []int{9}}); resp.Status != v201.GetDisplayMessagesStatusUnknown {
+		t.Errorf("Expected Unknown for missing message, got %s", resp.Status)
+	}
+	if resp, _ := manager.v201Handler.OnGetDisplayMessages("TEST020", &v201.GetDisplayMessagesRequest{RequestId: 2}); resp.Status != v201.GetDisplayMessagesStatusAccepted {
+		t.Errorf("Expected Accepted, got %s", resp.Status)
+	}
+
+	// ClearDisplayMessage, also with OCPP 2.1
+	if resp, _ := manager.v201Handler.OnClearDisplayMessage("TEST020", &v201.ClearDisplayMessageRequest{Id: 1}); resp.Status != v201.ClearMessageStatusAccepted {
+		t.Errorf("Expected Accepted, got %s", resp.Status)
+	}
+	if resp, _ := manager.v21Handler.OnClearDisplayMessage("TEST020", &v21.ClearDisplayMessageRequest{Id: 1}); resp.Status != v21.ClearMessageStatusUnknown {
+		t.Errorf("Expected Unknown for a cleared message, got %s", resp.Status)
+	}
+
+	id := 6
+	start := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	resp21, _ := manager.v21Handler.OnSetDisplayMessage("TEST020", &v21.SetDisplayMessageRequest{Message: v21.DisplayMessageType{
+		ID:            &id,
+		Priority:      v21.MessagePriorityAlwaysFront,
+		StartDateTime: &start,
+		Message:       v21.MessageContentType{Format: v21.MessageFormatASCII, Content: "Out of order"},
+	}})
+	if resp21.Status != v21.DisplayMessageStatusAccepted {
+		t.Errorf("Expected OCPP 2.1 message to be accepted, got %s", resp21.Status)
+	}
+	if shown := station.DisplayMessages.Displayed(v201.MessageStateIdle); shown == nil || shown.Id != 6 {
+		t.Errorf("Expected OCPP 2.1 message 6 on the display, got %+v", shown)
+	}
+}
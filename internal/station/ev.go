@@ -0,0 +1,239 @@
+package station
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// Energy transfer modes of the simulated EV
+const (
+	energyTransferAC    = "AC_three_phase"
+	energyTransferACBPT = "AC_BPT" // AC bidirectional power transfer
+)
+
+// Charging needs of an ISO 15118 EV without configured values
+const (
+	defaultEVMinCurrent  = 6     // A, the minimum charging current of IEC 61851-1
+	defaultEVMaxCurrent  = 16    // A
+	defaultEnergyRequest = 20000 // Wh
+)
+
+// SetEVConfig sets the simulated EV of new transactions
+func (sm *SessionManager) SetEVConfig(ev EVSimulationConfig) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.ev = ev
+}
+
+// EVConfig returns the simulated EV
+func (sm *SessionManager) EVConfig() EVSimulationConfig {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.ev
+}
+
+// sendEVChargingNeeds advertises the charging needs of an ISO 15118 EV, or of a V2X EV of an
+// OCPP 2.1 station, to the CSMS when its transaction starts. An ISO 15118 EV then negotiates
+// its charging schedule for the TxProfile of the CSMS.
+func (sm *SessionManager) sendEVChargingNeeds(connectorID int, soc float64, start time.Time) {
+	ev := sm.EVConfig()
+	ocpp21 := ocppVersion(sm.protocolVersion) == v201.OCPPVersion21
+	if !ev.ISO15118 && !(ev.V2X && ocpp21) {
+		return
+	}
+
+	needs := ev.chargingNeeds(soc, start, ocpp21)
+	if ev.ISO15118 {
+		sm.startEVChargingSession(connectorID, ev, needs, start)
+	}
+	if sm.SendNotifyEVChargingNeeds == nil {
+		return
+	}
+
+	req := &v21.NotifyEVChargingNeedsRequest{
+		EvseId:        connectorID,
+		ChargingNeeds: needs,
+	}
+	if err := sm.SendNotifyEVChargingNeeds(req); err != nil {
+		sm.logger.Warn("Failed to send NotifyEVChargingNeeds",
+			"stationId", sm.stationID,
+			"evseId", connectorID,
+			"error", err,
+		)
+	}
+}
+
+// chargePower returns the power at which the EV charges without a charging limit
+func (ev EVSimulationConfig) chargePower() int {
+	if ev.MaxChargePower > 0 {
+		return ev.MaxChargePower
+	}
+	return 5000 + rand.Intn(2500)
+}
+
+// limitedPower returns the power of the EV at a charging limit, negative while it discharges.
+// Only V2X EVs discharge at negative limits, other EVs stop charging.
+func (ev EVSimulationConfig) limitedPower(powerWatts int, limitWatts float64) int {
+	switch {
+	case float64(powerWatts) <= limitWatts:
+		return powerWatts
+	case limitWatts >= 0:
+		return int(limitWatts)
+	case !ev.V2X:
+		return 0
+	case ev.MaxDischargePower > 0 && -limitWatts > float64(ev.MaxDischargePower):
+		return -ev.MaxDischargePower
+	default:
+		return int(limitWatts)
+	}
+}
+
+// batteryPower limits the power to what the battery takes at a state of charge: a full battery
+// is not charged and a battery at its minimum state of charge is not discharged
+func (ev EVSimulationConfig) batteryPower(powerWatts int, soc float64) int {
+	if ev.BatteryCapacity <= 0 {
+		return powerWatts
+	}
+	if (powerWatts > 0 && soc >= 100) || (powerWatts < 0 && soc <= float64(ev.MinSoC)) {
+		return 0
+	}
+	return powerWatts
+}
+
+// stateOfCharge returns the state of charge after the battery has been charged with energy,
+// negative energy discharges it
+func (ev EVSimulationConfig) stateOfCharge(soc float64, energyWh int) float64 {
+	if ev.BatteryCapacity <= 0 {
+		return soc
+	}
+	soc += float64(energyWh) * 100 / float64(ev.BatteryCapacity)
+	return math.Max(0, math.Min(100, soc))
+}
+
+// energyRequest returns the energy in Wh needed to charge the battery from a state of charge to
+// a target, negative if the battery is above the target
+func (ev EVSimulationConfig) energyRequest(soc float64, target int) float64 {
+	return math.Round((float64(target) - soc) * float64(ev.BatteryCapacity) / 100)
+}
+
+// minCurrent returns the current per phase below which the EV does not charge
+func (ev EVSimulationConfig) minCurrent() int {
+	if ev.MinCurrent > 0 {
+		return ev.MinCurrent
+	}
+	return defaultEVMinCurrent
+}
+
+// maxCurrent returns the maximum current per phase of the EV
+func (ev EVSimulationConfig) maxCurrent() int {
+	switch {
+	case ev.MaxCurrent > 0:
+		return ev.MaxCurrent
+	case ev.MaxChargePower > 0:
+		return int(math.Ceil(float64(ev.MaxChargePower) / (nominalVoltage * defaultNumberPhases)))
+	default:
+		return defaultEVMaxCurrent
+	}
+}
+
+// energyAmount returns the energy in Wh the EV requests at a state of charge
+func (ev EVSimulationConfig) energyAmount(soc float64) int {
+	switch {
+	case ev.EnergyRequest > 0:
+		return ev.EnergyRequest
+	case ev.BatteryCapacity > 0:
+		targetSoC := ev.TargetSoC
+		if targetSoC <= 0 {
+			targetSoC = 100
+		}
+		return int(math.Max(0, ev.energyRequest(soc, targetSoC)))
+	default:
+		return defaultEnergyRequest
+	}
+}
+
+// chargingNeeds returns the charging needs of the EV at the start of a transaction. An ISO 15118
+// EV requests AC charging within its current limits, on OCPP 2.1 stations under scheduled
+// control of the CSMS. A V2X EV of an OCPP 2.1 station offers bidirectional power transfer under
+// dynamic control of the CSMS within its V2X parameters.
+func (ev EVSimulationConfig) chargingNeeds(soc float64, start time.Time, ocpp21 bool) v21.ChargingNeedsType {
+	needs := v21.ChargingNeedsType{RequestedEnergyTransfer: energyTransferAC}
+	if ev.DepartureDuration > 0 {
+		departure := start.Add(time.Duration(ev.DepartureDuration) * time.Second).UTC().Format(time.RFC3339)
+		needs.DepartureTime = &departure
+	}
+	if ev.ISO15118 {
+		needs.ACChargingParameters = &v21.ACChargingParametersType{
+			EnergyAmount: ev.energyAmount(soc),
+			EVMinCurrent: ev.minCurrent(),
+			EVMaxCurrent: ev.maxCurrent(),
+			EVMaxVoltage: int(nominalVoltage),
+		}
+	}
+
+	if !ocpp21 {
+		return needs
+	}
+	if !ev.V2X {
+		controlMode := "ScheduledControl"
+		needs.ControlMode = &controlMode
+		return needs
+	}
+
+	controlMode, mobilityNeedsMode := "DynamicControl", "EVCC_SECC"
+	needs.RequestedEnergyTransfer = energyTransferACBPT
+	needs.AvailableEnergyTransfer = []string{energyTransferAC, energyTransferACBPT}
+	needs.ControlMode = &controlMode
+	needs.MobilityNeedsMode = &mobilityNeedsMode
+
+	params := &v21.V2XChargingParametersType{}
+	if ev.MaxChargePower > 0 {
+		maxCharge := float64(ev.MaxChargePower)
+		params.MaxChargePower = &maxCharge
+	}
+	if ev.MaxDischargePower > 0 {
+		maxDischarge := float64(ev.MaxDischargePower)
+		params.MaxDischargePower = &maxDischarge
+	}
+	if ev.BatteryCapacity > 0 {
+		targetSoC := ev.TargetSoC
+		if targetSoC <= 0 {
+			targetSoC = 100
+		}
+		target := ev.energyRequest(soc, targetSoC)
+		minimum := ev.energyRequest(soc, ev.MinSoC)
+		maximum := ev.energyRequest(soc, 100)
+
+		params.TargetSoC = &targetSoC
+		params.EVTargetEnergyRequest = &target
+		params.EVMinEnergyRequest = &minimum
+		params.EVMaxEnergyRequest = &maximum
+		params.EVMinV2XEnergyRequest = &minimum
+		params.EVMaxV2XEnergyRequest = &maximum
+	}
+	needs.V2XChargingParameters = params
+
+	return needs
+}
+
+// withExportRegister adds the export register next to the import register of sampled measurands
+func withExportRegister(measurands []v16.Measurand) []v16.Measurand {
+	hasImport := false
+	for _, measurand := range measurands {
+		switch measurand {
+		case v16.MeasurandEnergyActiveExportRegister:
+			return measurands
+		case v16.MeasurandEnergyActiveImportRegister:
+			hasImport = true
+		}
+	}
+	if !hasImport {
+		return measurands
+	}
+	return append(measurands, v16.MeasurandEnergyActiveExportRegister)
+}
@@ -0,0 +1,239 @@
+package station
+
+import (
+	"math"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// evScheduleDuration is the duration in seconds of the charging schedule of an EV without
+// departure time
+const evScheduleDuration = 24 * 3600
+
+// evChargingSession is the ISO 15118 charging schedule negotiation of the EV of a transaction.
+// The EV advertises its charging needs when the transaction starts and selects its charging
+// schedule within the limits of the TxProfile the CSMS installs for them.
+type evChargingSession struct {
+	ev           EVSimulationConfig
+	energyAmount int        // Wh requested at the start of the transaction
+	departure    *time.Time // Departure time of the driver, nil if unknown
+
+	schedule *v21.ChargingScheduleType // Schedule selected by the EV, nil until negotiated
+	timeBase time.Time                 // Start of the schedule periods
+}
+
+// startEVChargingSession starts the schedule negotiation of the ISO 15118 EV at a connector
+func (sm *SessionManager) startEVChargingSession(connectorID int, ev EVSimulationConfig, needs v21.ChargingNeedsType, start time.Time) {
+	session := &evChargingSession{ev: ev}
+	if needs.ACChargingParameters != nil {
+		session.energyAmount = needs.ACChargingParameters.EnergyAmount
+	}
+	if ev.DepartureDuration > 0 {
+		departure := start.Add(time.Duration(ev.DepartureDuration) * time.Second)
+		session.departure = &departure
+	}
+
+	sm.mu.Lock()
+	sm.evSessions[connectorID] = session
+	sm.mu.Unlock()
+}
+
+// endEVChargingSession ends the schedule negotiation of the EV at a connector
+func (sm *SessionManager) endEVChargingSession(connectorID int) {
+	sm.mu.Lock()
+	delete(sm.evSessions, connectorID)
+	sm.mu.Unlock()
+}
+
+// NegotiateEVChargingSchedule lets the ISO 15118 EV at an EVSE select its charging schedule
+// after the CSMS installed a TxProfile and reports it with NotifyEVChargingSchedule. The EV
+// plans within the composite schedule of the EVSE until departure and stops once its energy
+// request is delivered. scheduleID identifies the schedule of the TxProfile the EV answers.
+// It returns the selected schedule, nil without an ISO 15118 EV.
+func (sm *SessionManager) NegotiateEVChargingSchedule(evseID, scheduleID int) *v21.ChargingScheduleType {
+	connector, err := sm.GetConnector(evseID)
+	if err != nil {
+		return nil
+	}
+	tx := connector.GetTransaction()
+
+	sm.mu.RLock()
+	session := sm.evSessions[evseID]
+	sm.mu.RUnlock()
+
+	if session == nil || tx == nil {
+		return nil
+	}
+
+	tx.mu.RLock()
+	delivered := tx.CurrentMeter - tx.StartMeterValue
+	tx.mu.RUnlock()
+
+	now := time.Now()
+	duration := session.duration(now)
+	offered := sm.chargingProfiles.GetCompositeSchedule(evseID, now, duration, v16.ChargingRateUnitA, transactionStart(connector))
+	schedule := &v21.ChargingScheduleType{
+		ID:                     scheduleID,
+		Duration:               &duration,
+		ChargingRateUnit:       string(v16.ChargingRateUnitA),
+		ChargingSchedulePeriod: session.ev.chargingSchedule(offered.ChargingSchedulePeriod, duration, session.energyAmount-delivered),
+	}
+
+	sm.mu.Lock()
+	if sm.evSessions[evseID] != session {
+		// The transaction ended in the meantime
+		sm.mu.Unlock()
+		return nil
+	}
+	session.schedule, session.timeBase = schedule, now
+	sm.mu.Unlock()
+
+	sm.logger.Info("EV selected charging schedule",
+		"stationId", sm.stationID,
+		"evseId", evseID,
+		"scheduleId", scheduleID,
+		"periods", len(schedule.ChargingSchedulePeriod),
+	)
+
+	if sm.SendNotifyEVChargingSchedule != nil {
+		req := &v21.NotifyEVChargingScheduleRequest{
+			TimeBase:         now.UTC().Format(time.RFC3339),
+			EvseId:           evseID,
+			ChargingSchedule: *schedule,
+		}
+		if err := sm.SendNotifyEVChargingSchedule(req); err != nil {
+			sm.logger.Warn("Failed to send NotifyEVChargingSchedule",
+				"stationId", sm.stationID,
+				"evseId", evseID,
+				"error", err,
+			)
+		}
+	}
+
+	return schedule
+}
+
+// EVChargingSchedule returns the charging schedule negotiated by the EV at a connector, nil if
+// there is none
+func (sm *SessionManager) EVChargingSchedule(connectorID int) *v21.ChargingScheduleType {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if session := sm.evSessions[connectorID]; session != nil {
+		return session.schedule
+	}
+	return nil
+}
+
+// evScheduleLimit returns the power in watts the EV at a connector draws according to its
+// negotiated charging schedule. The second return value is false without a schedule or after it.
+func (sm *SessionManager) evScheduleLimit(connectorID int, now time.Time) (float64, bool) {
+	sm.mu.RLock()
+	session := sm.evSessions[connectorID]
+	var (
+		schedule *v21.ChargingScheduleType
+		timeBase time.Time
+	)
+	if session != nil {
+		schedule, timeBase = session.schedule, session.timeBase
+	}
+	sm.mu.RUnlock()
+
+	if schedule == nil {
+		return 0, false
+	}
+
+	elapsed := int(now.Sub(timeBase).Seconds())
+	if schedule.Duration != nil && elapsed >= *schedule.Duration {
+		return 0, false
+	}
+
+	var period *v21.ChargingSchedulePeriodType
+	for i := range schedule.ChargingSchedulePeriod {
+		if schedule.ChargingSchedulePeriod[i].StartPeriod <= elapsed {
+			period = &schedule.ChargingSchedulePeriod[i]
+		}
+	}
+	if period == nil {
+		return 0, false
+	}
+
+	phases := defaultNumberPhases
+	if period.NumberPhases != nil {
+		phases = *period.NumberPhases
+	}
+	return period.Limit * nominalVoltage * float64(phases), true
+}
+
+// duration returns the seconds from now until departure, evScheduleDuration without departure time
+func (s *evChargingSession) duration(now time.Time) int {
+	if s.departure != nil && s.departure.After(now) {
+		return int(math.Ceil(s.departure.Sub(now).Seconds()))
+	}
+	return evScheduleDuration
+}
+
+// chargingSchedule returns the periods of the charging schedule the EV selects within the offered
+// periods in A: it charges at most at its maximum current, not below its minimum current, and
+// stops once energyWh has been delivered. Without offered periods the EV charges at its maximum
+// current.
+func (ev EVSimulationConfig) chargingSchedule(offered []v16.ChargingSchedulePeriod, duration, energyWh int) []v21.ChargingSchedulePeriodType {
+	if len(offered) == 0 {
+		phases := defaultNumberPhases
+		offered = []v16.ChargingSchedulePeriod{{Limit: float64(ev.maxCurrent()), NumberPhases: &phases}}
+	}
+
+	periods := make([]v21.ChargingSchedulePeriodType, 0, len(offered))
+	add := func(start int, current float64, phases int) {
+		if n := len(periods); n > 0 && periods[n-1].Limit == current && *periods[n-1].NumberPhases == phases {
+			return
+		}
+		periods = append(periods, v21.ChargingSchedulePeriodType{StartPeriod: start, Limit: current, NumberPhases: &phases})
+	}
+
+	remaining := float64(energyWh)
+	for i, period := range offered {
+		phases := defaultNumberPhases
+		if period.NumberPhases != nil {
+			phases = *period.NumberPhases
+		}
+		end := duration
+		if i+1 < len(offered) {
+			end = offered[i+1].StartPeriod
+		}
+
+		current := ev.scheduledCurrent(period.Limit)
+		if remaining <= 0 && current > 0 {
+			current = 0
+		}
+		add(period.StartPeriod, current, phases)
+		if current <= 0 {
+			continue
+		}
+
+		watts := current * nominalVoltage * float64(phases)
+		if seconds := remaining * 3600 / watts; float64(period.StartPeriod)+seconds < float64(end) {
+			add(period.StartPeriod+int(math.Ceil(seconds)), 0, phases)
+			remaining = 0
+			continue
+		}
+		remaining -= watts * float64(end-period.StartPeriod) / 3600
+	}
+
+	return periods
+}
+
+// scheduledCurrent returns the current per phase the EV plans at an offered limit. Only V2X EVs
+// follow negative limits.
+func (ev EVSimulationConfig) scheduledCurrent(limit float64) float64 {
+	switch {
+	case limit < 0 && ev.V2X:
+		return limit
+	case limit < float64(ev.minCurrent()):
+		return 0
+	default:
+		return math.Min(limit, float64(ev.maxCurrent()))
+	}
+}
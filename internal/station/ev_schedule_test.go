@@ -0,0 +1,224 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// schedulePeriods formats schedule periods as "startPeriod:limit"
+func schedulePeriods(periods []v21.ChargingSchedulePeriodType) string {
+	formatted := make([]string, 0, len(periods))
+	for _, period := range periods {
+		formatted = append(formatted, fmt.Sprintf("%d:%g", period.StartPeriod, period.Limit))
+	}
+	return fmt.Sprint(formatted)
+}
+
+func TestEVSimulation_ChargingSchedule(t *testing.T) {
+	ev := EVSimulationConfig{ISO15118: true, MaxCurrent: 16}
+	phases := 3
+	offered := []v16.ChargingSchedulePeriod{
+		{StartPeriod: 0, Limit: 32, NumberPhases: &phases},
+		{StartPeriod: 1800, Limit: 4, NumberPhases: &phases},
+		{StartPeriod: 3600, Limit: 10, NumberPhases: &phases},
+	}
+
+	// 5520 Wh at 16 A, a pause below the minimum current of 6 A and 3450 Wh at 10 A
+	if periods := schedulePeriods(ev.chargingSchedule(offered, 7200, 8970)); periods != "[0:16 1800:0 3600:10 5400:0]" {
+		t.Errorf("Expected the EV to stop once 8970 Wh are delivered, got %s", periods)
+	}
+	if periods := schedulePeriods(ev.chargingSchedule(offered, 7200, 50000)); periods != "[0:16 1800:0 3600:10]" {
+		t.Errorf("Expected the EV to charge until departure, got %s", periods)
+	}
+	if periods := schedulePeriods(ev.chargingSchedule(nil, 7200, 50000)); periods != "[0:16]" {
+		t.Errorf("Expected the maximum current of the EV without limits, got %s", periods)
+	}
+
+	ev = EVSimulationConfig{BatteryCapacity: 60000, TargetSoC: 80, MaxChargePower: 11000}
+	if amount, current := ev.energyAmount(50), ev.maxCurrent(); amount != 18000 || current != 16 || ev.minCurrent() != defaultEVMinCurrent {
+		t.Errorf("Expected 18000 Wh at 6-16 A derived from the battery and power, got %d Wh at %d-%d A", amount, ev.minCurrent(), current)
+	}
+}
+
+func TestEVChargingScheduleNegotiation(t *testing.T) {
+	sm, recorder := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	sm.SetMeterValueConfig(3600, defaultSampledData())
+	sm.SetEVConfig(EVSimulationConfig{
+		MaxChargePower:    11000,
+		DepartureDuration: 7200,
+		ISO15118:          true,
+		EnergyRequest:     30000,
+		MaxCurrent:        8,
+	})
+
+	var mu sync.Mutex
+	var needs *v21.NotifyEVChargingNeedsRequest
+	var schedules []*v21.NotifyEVChargingScheduleRequest
+	sm.SendNotifyEVChargingNeeds = func(req *v21.NotifyEVChargingNeedsRequest) error {
+		needs = req
+		return nil
+	}
+	sm.SendNotifyEVChargingSchedule = func(req *v21.NotifyEVChargingScheduleRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		schedules = append(schedules, req)
+		return nil
+	}
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+
+	// The EV advertises its AC charging needs when the transaction starts
+	if needs == nil || needs.EvseId != 1 || needs.ChargingNeeds.RequestedEnergyTransfer != "AC_three_phase" || needs.ChargingNeeds.DepartureTime == nil {
+		t.Fatalf("Expected AC charging needs for EVSE 1, got %+v", needs)
+	}
+	expected := v21.ACChargingParametersType{EnergyAmount: 30000, EVMinCurrent: 6, EVMaxCurrent: 8, EVMaxVoltage: 230}
+	if params := needs.ChargingNeeds.ACChargingParameters; params == nil || *params != expected {
+		t.Errorf("Expected AC charging parameters %+v, got %+v", expected, params)
+	}
+	if needs.ChargingNeeds.ControlMode != nil || needs.ChargingNeeds.V2XChargingParameters != nil {
+		t.Errorf("Expected no OCPP 2.1 charging needs for an OCPP 2.0.1 station, got %+v", needs.ChargingNeeds)
+	}
+	if sm.NegotiateEVChargingSchedule(1, 1) == nil {
+		t.Fatal("Expected the EV to select a schedule without TxProfile")
+	}
+
+	// The CSMS offers 10 A, 4 A after half an hour and 32 A after an hour
+	connector, _ := sm.GetConnector(1)
+	phases := 3
+	profile := v201.ChargingProfile{
+		Id:                     5,
+		ChargingProfilePurpose: v201.ChargingProfilePurposeTxProfile,
+		ChargingProfileKind:    v201.ChargingProfileKindRelative,
+		TransactionId:          connector.GetTransaction().StringID,
+		ChargingSchedule: []v201.ChargingSchedule{{
+			Id:               7,
+			ChargingRateUnit: v201.ChargingRateUnitA,
+			ChargingSchedulePeriod: []v201.ChargingSchedulePeriod{
+				{StartPeriod: 0, Limit: 10, NumberPhases: &phases},
+				{StartPeriod: 1800, Limit: 4, NumberPhases: &phases},
+				{StartPeriod: 3600, Limit: 32, NumberPhases: &phases},
+			},
+		}},
+	}
+	if err := sm.SetEVSEChargingProfile(1, profile); err != nil {
+		t.Fatalf("Expected the TxProfile to be accepted, got %v", err)
+	}
+
+	schedule := sm.NegotiateEVChargingSchedule(1, 7)
+	if schedule == nil || schedule.ID != 7 || schedule.ChargingRateUnit != "A" || *schedule.Duration != 7200 {
+		t.Fatalf("Expected a schedule in A until departure, got %+v", schedule)
+	}
+	// Periods of the TxProfile are relative to the start of the transaction a moment ago
+	periods := schedule.ChargingSchedulePeriod
+	if len(periods) != 3 || periods[0].Limit != 8 || periods[1].Limit != 0 || periods[2].Limit != 8 ||
+		1800-periods[1].StartPeriod > 1 || 3600-periods[2].StartPeriod > 1 {
+		t.Errorf("Expected the EV to charge at up to 8 A and pause below 6 A, got %s", schedulePeriods(periods))
+	}
+
+	mu.Lock()
+	last := schedules[len(schedules)-1]
+	mu.Unlock()
+	if len(schedules) != 2 || last.EvseId != 1 || last.TimeBase == "" || last.ChargingSchedule.ID != 7 {
+		t.Errorf("Expected NotifyEVChargingSchedule for the TxProfile, got %d %+v", len(schedules), last)
+	}
+
+	// Charging follows the negotiated schedule below the 10 A of the TxProfile
+	sm.sendMeterValue(connector)
+	for _, sv := range recorder.last().MeterValue[0].SampledValue {
+		if *sv.Measurand == v201.MeasurandPowerActiveImport && sv.Value != 5520 {
+			t.Errorf("Expected charging at 8 A (5520 W), got %v W", sv.Value)
+		}
+	}
+	if sm.EVChargingSchedule(1) != schedule {
+		t.Error("Expected the negotiated schedule of the connector")
+	}
+
+	if err := sm.StopCharging(1, v16.ReasonLocal); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+	if sm.EVChargingSchedule(1) != nil || sm.NegotiateEVChargingSchedule(1, 7) != nil {
+		t.Error("Expected the schedule to end with the transaction")
+	}
+}
+
+func TestHandleSetChargingProfile_EVChargingSchedule(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+	sm.SetProtocolVersion("ocpp2.1")
+	sm.SetEVConfig(EVSimulationConfig{ISO15118: true})
+
+	var needs *v21.NotifyEVChargingNeedsRequest
+	sm.SendNotifyEVChargingNeeds = func(req *v21.NotifyEVChargingNeedsRequest) error {
+		needs = req
+		return nil
+	}
+	scheduled := make(chan *v21.NotifyEVChargingScheduleRequest, 1)
+	sm.SendNotifyEVChargingSchedule = func(req *v21.NotifyEVChargingScheduleRequest) error {
+		scheduled <- req
+		return nil
+	}
+
+	station := &Station{
+		Config:         Config{StationID: "TEST025", ProtocolVersion: "ocpp2.1"},
+		SessionManager: sm,
+	}
+	manager.mu.Lock()
+	manager.stations["TEST025"] = station
+	manager.mu.Unlock()
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	if needs == nil || needs.ChargingNeeds.ControlMode == nil || *needs.ChargingNeeds.ControlMode != "ScheduledControl" {
+		t.Fatalf("Expected scheduled control of an OCPP 2.1 station, got %+v", needs)
+	}
+
+	connector, _ := sm.GetConnector(1)
+	transactionID := connector.GetTransaction().StringID
+	resp, _ := manager.v21Handler.OnSetChargingProfile("TEST025", &v21.SetChargingProfileRequest{
+		EvseId: 1,
+		ChargingProfile: v21.ChargingProfileType{
+			ID:                     1,
+			ChargingProfilePurpose: "TxProfile",
+			ChargingProfileKind:    "Relative",
+			TransactionId:          &transactionID,
+			ChargingSchedule: []v21.ChargingScheduleType{{
+				ID:                     3,
+				ChargingRateUnit:       "W",
+				ChargingSchedulePeriod: []v21.ChargingSchedulePeriodType{{Limit: 6900}},
+			}},
+		},
+	})
+	if resp.Status != v21.ChargingProfileStatusAccepted {
+		t.Fatalf("Expected the TxProfile to be accepted, got %s %+v", resp.Status, resp.StatusInfo)
+	}
+
+	// The EV selects its schedule once the response has been sent
+	manager.runAfterResponseActions(station)
+
+	select {
+	case req := <-scheduled:
+		// 20 kWh at the offered 10 A take 10435 s
+		if periods := schedulePeriods(req.ChargingSchedule.ChargingSchedulePeriod); req.ChargingSchedule.ID != 3 || periods != "[0:10 10435:0]" {
+			t.Errorf("Expected the EV to charge 20 kWh at the offered 10 A, got %s", periods)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for NotifyEVChargingSchedule")
+	}
+}
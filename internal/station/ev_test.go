@@ -0,0 +1,179 @@
+package station
+
+import (
+	"context"
+	"testing"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func TestPeriodLimitFromV21(t *testing.T) {
+	mode := func(m v21.OperationModeType) *v21.OperationModeType { return &m }
+	value := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		period  v21.ChargingSchedulePeriodType
+		limit   float64
+		wantErr bool
+	}{
+		{"charging only", v21.ChargingSchedulePeriodType{Limit: 11000}, 11000, false},
+		{"negative limit while charging only", v21.ChargingSchedulePeriodType{Limit: -5000}, 0, true},
+		{"discharge limit while charging only", v21.ChargingSchedulePeriodType{Limit: 11000, OperationMode: mode(v21.OperationModeChargingOnly), DischargeLimit: value(-5000)}, 0, true},
+		{"idle", v21.ChargingSchedulePeriodType{Limit: 11000, OperationMode: mode(v21.OperationModeIdle)}, 0, false},
+		{"discharge setpoint", v21.ChargingSchedulePeriodType{Limit: 11000, Setpoint: value(-7000), OperationMode: mode(v21.OperationModeCentralSetpoint)}, -7000, false},
+		{"setpoint capped by discharge limit", v21.ChargingSchedulePeriodType{Setpoint: value(-7000), DischargeLimit: value(-4000), OperationMode: mode(v21.OperationModeExternalSetpoint)}, -4000, false},
+		{"negative external limit", v21.ChargingSchedulePeriodType{Limit: -3000, OperationMode: mode(v21.OperationModeExternalLimits)}, -3000, false},
+		{"positive discharge limit", v21.ChargingSchedulePeriodType{Limit: -3000, DischargeLimit: value(2000), OperationMode: mode(v21.OperationModeLocalLoadBalancing)}, 0, true},
+		{"unknown mode", v21.ChargingSchedulePeriodType{Limit: 11000, OperationMode: mode("Turbo")}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := periodLimitFromV21(tt.period)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if limit != tt.limit {
+				t.Errorf("Expected limit %v, got %v", tt.limit, limit)
+			}
+		})
+	}
+}
+
+func TestEVSimulation_LimitedPower(t *testing.T) {
+	ev := EVSimulationConfig{MaxChargePower: 11000, MaxDischargePower: 7000, V2X: true}
+
+	if power := ev.limitedPower(11000, 4000); power != 4000 {
+		t.Errorf("Expected charging at 4000 W, got %d", power)
+	}
+	if power := ev.limitedPower(11000, -5000); power != -5000 {
+		t.Errorf("Expected discharging at 5000 W, got %d", power)
+	}
+	if power := ev.limitedPower(11000, -10000); power != -7000 {
+		t.Errorf("Expected discharging at the EV maximum of 7000 W, got %d", power)
+	}
+
+	ev.V2X = false
+	if power := ev.limitedPower(11000, -5000); power != 0 {
+		t.Errorf("Expected an EV without V2X to stop charging, got %d", power)
+	}
+
+	ev = EVSimulationConfig{BatteryCapacity: 50000, MinSoC: 30}
+	if power := ev.batteryPower(-5000, 30); power != 0 {
+		t.Errorf("Expected no discharging at the minimum state of charge, got %d", power)
+	}
+	if power := ev.batteryPower(5000, 100); power != 0 {
+		t.Errorf("Expected no charging of a full battery, got %d", power)
+	}
+	if soc := ev.stateOfCharge(50, -5000); soc != 40 {
+		t.Errorf("Expected 40%% after discharging 5 kWh, got %v", soc)
+	}
+}
+
+func TestV2XDischarge(t *testing.T) {
+	sm, recorder := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	sm.SetProtocolVersion("ocpp2.1")
+	sm.SetMeterValueConfig(3600, defaultSampledData())
+	sm.SetEVConfig(EVSimulationConfig{
+		BatteryCapacity:   60000,
+		InitialSoC:        80,
+		TargetSoC:         90,
+		MinSoC:            30,
+		MaxChargePower:    11000,
+		MaxDischargePower: 7000,
+		DepartureDuration: 3600,
+		V2X:               true,
+	})
+
+	var needs *v21.NotifyEVChargingNeedsRequest
+	sm.SendNotifyEVChargingNeeds = func(req *v21.NotifyEVChargingNeedsRequest) error {
+		needs = req
+		return nil
+	}
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+
+	// The EV advertises its V2X parameters when the transaction starts
+	if needs == nil || needs.EvseId != 1 || needs.ChargingNeeds.RequestedEnergyTransfer != "AC_BPT" || needs.ChargingNeeds.DepartureTime == nil {
+		t.Fatalf("Expected bidirectional charging needs for EVSE 1, got %+v", needs)
+	}
+	params := needs.ChargingNeeds.V2XChargingParameters
+	if params == nil || *params.MaxDischargePower != 7000 || *params.TargetSoC != 90 || *params.EVTargetEnergyRequest != 6000 || *params.EVMinV2XEnergyRequest != -30000 {
+		t.Errorf("Expected V2X parameters of the EV, got %+v", params)
+	}
+
+	// A discharge setpoint of 10 kW is capped by the EV
+	connector, _ := sm.GetConnector(1)
+	setpoint, mode := -10000.0, v21.OperationModeCentralSetpoint
+	profile, err := chargingProfileFromV21(v21.ChargingProfileType{
+		ID:                     1,
+		ChargingProfilePurpose: "TxProfile",
+		ChargingProfileKind:    "Relative",
+		TransactionId:          &connector.GetTransaction().StringID,
+		ChargingSchedule: []v21.ChargingScheduleType{{
+			ID:               1,
+			ChargingRateUnit: "W",
+			ChargingSchedulePeriod: []v21.ChargingSchedulePeriodType{{
+				Setpoint:      &setpoint,
+				OperationMode: &mode,
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected a valid V2X profile, got %v", err)
+	}
+	if err := sm.SetEVSEChargingProfile(1, profile); err != nil {
+		t.Fatalf("Expected the discharge profile to be accepted, got %v", err)
+	}
+
+	sm.sendMeterValue(connector)
+
+	samples := make(map[v201.MeasurandType]float64)
+	for _, sv := range recorder.last().MeterValue[0].SampledValue {
+		samples[*sv.Measurand] = sv.Value
+	}
+	if samples[v201.MeasurandPowerActiveImport] != -7000 || samples[v201.MeasurandEnergyActiveExportRegister] != 7000 || samples[v201.MeasurandEnergyActiveImportRegister] != 0 {
+		t.Errorf("Expected discharging at 7000 W for an hour, got %v", samples)
+	}
+	if tx := connector.GetTransaction(); tx.ExportMeter != 7000 || tx.SoC >= 80 {
+		t.Errorf("Expected 7000 Wh exported from the battery, got %d Wh at %.1f%%", tx.ExportMeter, tx.SoC)
+	}
+
+	if err := sm.StopCharging(1, v16.ReasonLocal); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+	ended := recorder.last()
+	if ended.EventType != v201.TransactionEventEnded || len(ended.MeterValue[0].SampledValue) != 2 || *ended.MeterValue[0].SampledValue[1].Measurand != v201.MeasurandEnergyActiveExportRegister {
+		t.Errorf("Expected the export register in the Ended event, got %+v", ended.MeterValue)
+	}
+}
+
+func TestV2XDischarge_RequiresOCPP21(t *testing.T) {
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	profile := v201.ChargingProfile{
+		Id:                     1,
+		ChargingProfilePurpose: v201.ChargingProfilePurposeTxDefaultProfile,
+		ChargingProfileKind:    v201.ChargingProfileKindRelative,
+		ChargingSchedule: []v201.ChargingSchedule{{
+			Id:                     1,
+			ChargingRateUnit:       v201.ChargingRateUnitW,
+			ChargingSchedulePeriod: []v201.ChargingSchedulePeriod{{Limit: -5000}},
+		}},
+	}
+	if err := sm.SetEVSEChargingProfile(1, profile); err == nil {
+		t.Error("Expected a negative limit to be rejected for an OCPP 2.0.1 station")
+	}
+
+	sm.SetProtocolVersion("ocpp2.1")
+	if err := sm.SetEVSEChargingProfile(1, profile); err != nil {
+		t.Errorf("Expected a negative limit to be accepted for an OCPP 2.1 station, got %v", err)
+	}
+}
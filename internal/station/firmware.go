@@ -0,0 +1,737 @@
+package station
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// Default durations of the simulated firmware lifecycle (seconds)
+const (
+	defaultDownloadDuration = 3
+	defaultInstallDuration  = 3
+	defaultUploadDuration   = 2
+	defaultRetryInterval    = 5
+)
+
+// uploadStage is a stage of a simulated diagnostics or log upload
+type uploadStage int
+
+const (
+	uploadStageUploading uploadStage = iota
+	uploadStageUploaded
+	uploadStageFailed
+)
+
+// signedFirmware holds the security extension parameters of a SignedUpdateFirmware request
+type signedFirmware struct {
+	requestID          int
+	installDate        *time.Time
+	signature          string
+	signingCertificate string
+	transfer           bool // Downloaded over HTTP(S) instead of simulated
+}
+
+// FirmwareImage describes the firmware of an OCPP 2.0.1 UpdateFirmware request
+type FirmwareImage struct {
+	Location           string
+	RetrieveDate       time.Time
+	InstallDate        *time.Time
+	SigningCertificate string // PEM encoded
+	Signature          string // Base64 encoded, the image is installed without verification if empty
+}
+
+// FirmwareManager simulates the OCPP 1.6 firmware update, diagnostics and log upload of a station
+// and the firmware publishing of an OCPP 2.1 Local Controller
+type FirmwareManager struct {
+	stationID string
+	config    FirmwareSimulationConfig
+	logger    *slog.Logger
+
+	// timeUnit scales all simulated durations (one second in production)
+	timeUnit time.Duration
+
+	// client downloads firmware and uploads logs of OCPP 2.0.1 requests
+	client *http.Client
+
+	mu                sync.RWMutex
+	firmwareStatus    v16.FirmwareStatus
+	diagnosticsStatus v16.DiagnosticsStatus
+	updateCancel      context.CancelFunc
+	uploadCancel      context.CancelFunc
+	updateID          int // Identifies the running update so a replaced one does not clear its successor
+	uploadID          int
+	firmwareRequestID *int // Request ID of the running SignedUpdateFirmware, nil for UpdateFirmware
+	logStatus         v16.UploadLogStatus
+	logRequestID      *int
+
+	// Firmware published as OCPP 2.1 Local Controller
+	publishCancel    context.CancelFunc
+	publishID        int
+	publishChecksum  string // Checksum of the firmware being downloaded for publishing
+	publishStatus    v21.PublishFirmwareStatusType
+	publishRequestID *int
+	published        map[string]publishedFirmware // By checksum
+	fileServer       *firmwareFileServer
+
+	// Callbacks
+	SendFirmwareStatus        func(status v16.FirmwareStatus) error
+	SendSignedFirmwareStatus  func(status v16.FirmwareStatus, requestID *int) error
+	SendDiagnosticsStatus     func(status v16.DiagnosticsStatus) error
+	SendLogStatus             func(status v16.UploadLogStatus, requestID *int) error
+	SendPublishFirmwareStatus func(status v21.PublishFirmwareStatusType, locations []string, requestID int) error
+	WaitForIdle               func(ctx context.Context) error // Blocks until no transaction is active
+	OnFirmwareInstalled       func(version string)            // Reboots the station with the new firmware
+	OnSecurityEvent           func(eventType, techInfo string)
+
+	// CollectLog returns the lines of a log between the optional oldest and latest timestamps
+	CollectLog func(logType v16.LogType, oldest, latest *time.Time) []string
+}
+
+// NewFirmwareManager creates a firmware manager for a station
+func NewFirmwareManager(stationID string, config FirmwareSimulationConfig, logger *slog.Logger) *FirmwareManager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &FirmwareManager{
+		stationID:         stationID,
+		config:            config,
+		logger:            logger,
+		timeUnit:          time.Second,
+		client:            &http.Client{Timeout: transferTimeout},
+		firmwareStatus:    v16.FirmwareStatusIdle,
+		diagnosticsStatus: v16.DiagnosticsStatusIdle,
+		logStatus:         v16.UploadLogStatusIdle,
+		publishStatus:     v21.PublishFirmwareStatusIdle,
+		published:         make(map[string]publishedFirmware),
+	}
+}
+
+// SetConfig replaces the simulation config used by subsequent updates and uploads
+func (fm *FirmwareManager) SetConfig(config FirmwareSimulationConfig) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.config = config
+}
+
+// FirmwareStatus returns the last reported firmware status
+func (fm *FirmwareManager) FirmwareStatus() v16.FirmwareStatus {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.firmwareStatus
+}
+
+// DiagnosticsStatus returns the last reported diagnostics status
+func (fm *FirmwareManager) DiagnosticsStatus() v16.DiagnosticsStatus {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.diagnosticsStatus
+}
+
+// FirmwareRequestID returns the request ID of the running signed firmware update, nil if there is none
+func (fm *FirmwareManager) FirmwareRequestID() *int {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.firmwareRequestID
+}
+
+// LogStatus returns the last reported log upload status and its request ID
+func (fm *FirmwareManager) LogStatus() (v16.UploadLogStatus, *int) {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.logStatus, fm.logRequestID
+}
+
+// IsUpdating reports whether a firmware update is in progress
+func (fm *FirmwareManager) IsUpdating() bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.updateCancel != nil
+}
+
+// UpdateFirmware starts downloading and installing firmware from the location at the retrieve date.
+// A running update is replaced by the new one.
+func (fm *FirmwareManager) UpdateFirmware(location string, retrieveDate time.Time, retries, retryInterval *int) {
+	fm.startUpdate(location, retrieveDate, nil, retries, retryInterval)
+}
+
+// UpdateSignedFirmware starts a SignedUpdateFirmware. The signature is verified once the firmware
+// has been downloaded and installation waits for the install date if one is given.
+// Returns AcceptedCanceled if a running update was replaced.
+func (fm *FirmwareManager) UpdateSignedFirmware(requestID int, location string, retrieveDate time.Time, installDate *time.Time, signature string, retries, retryInterval *int) v16.UpdateFirmwareStatus {
+	replaced := fm.startUpdate(location, retrieveDate, &signedFirmware{
+		requestID:   requestID,
+		installDate: installDate,
+		signature:   signature,
+	}, retries, retryInterval)
+
+	if replaced {
+		return v16.UpdateFirmwareStatusAcceptedCanceled
+	}
+	return v16.UpdateFirmwareStatusAccepted
+}
+
+// DownloadFirmware starts an OCPP 2.0.1 UpdateFirmware. The image is downloaded from its HTTP(S)
+// location and its signature verified with the signing certificate before it is installed.
+// Returns Rejected for other locations and AcceptedCanceled if a running update was replaced.
+func (fm *FirmwareManager) DownloadFirmware(requestID int, image FirmwareImage, retries, retryInterval *int) v16.UpdateFirmwareStatus {
+	if err := validateTransferLocation(image.Location); err != nil {
+		fm.logger.Warn("Rejecting firmware update", "stationId", fm.stationID, "error", err)
+		return v16.UpdateFirmwareStatusRejected
+	}
+
+	replaced := fm.startUpdate(image.Location, image.RetrieveDate, &signedFirmware{
+		requestID:          requestID,
+		installDate:        image.InstallDate,
+		signature:          image.Signature,
+		signingCertificate: image.SigningCertificate,
+		transfer:           true,
+	}, retries, retryInterval)
+
+	if replaced {
+		return v16.UpdateFirmwareStatusAcceptedCanceled
+	}
+	return v16.UpdateFirmwareStatusAccepted
+}
+
+// startUpdate replaces any running update with a new one and reports whether one was replaced
+func (fm *FirmwareManager) startUpdate(location string, retrieveDate time.Time, signed *signedFirmware, retries, retryInterval *int) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fm.mu.Lock()
+	replaced := fm.updateCancel != nil
+	if replaced {
+		fm.logger.Warn("Replacing running firmware update", "stationId", fm.stationID)
+		fm.updateCancel()
+	}
+	fm.updateCancel = cancel
+	fm.updateID++
+	updateID := fm.updateID
+	config := fm.config
+	fm.firmwareRequestID = nil
+	if signed != nil {
+		requestID := signed.requestID
+		fm.firmwareRequestID = &requestID
+	}
+	fm.mu.Unlock()
+
+	fm.logger.Info("Scheduling firmware update",
+		"stationId", fm.stationID,
+		"location", location,
+		"retrieveDate", retrieveDate,
+		"signed", signed != nil,
+	)
+
+	go fm.runUpdate(ctx, updateID, config, location, retrieveDate, signed, attempts(retries), fm.retryDelay(retryInterval))
+
+	return replaced
+}
+
+// GetDiagnostics starts uploading a diagnostics file to the location and returns its name
+func (fm *FirmwareManager) GetDiagnostics(location string, retries, retryInterval *int) string {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fm.mu.Lock()
+	if fm.uploadCancel != nil {
+		fm.uploadCancel()
+	}
+	fm.uploadCancel = cancel
+	fm.uploadID++
+	uploadID := fm.uploadID
+	config := fm.config
+	fm.mu.Unlock()
+
+	fileName := fmt.Sprintf("diagnostics-%s-%s.log", fm.stationID, time.Now().UTC().Format("20060102T150405Z"))
+
+	fm.logger.Info("Starting diagnostics upload",
+		"stationId", fm.stationID,
+		"location", location,
+		"fileName", fileName,
+	)
+
+	go fm.runUpload(ctx, uploadID, config, attempts(retries), fm.retryDelay(retryInterval), nil, fm.reportDiagnosticsUpload)
+
+	return fileName
+}
+
+// GetLog starts uploading a diagnostics or security log and returns its file name.
+// Returns AcceptedCanceled if a running log upload was replaced.
+func (fm *FirmwareManager) GetLog(requestID int, logType v16.LogType, location string, retries, retryInterval *int) (v16.LogStatus, string) {
+	return fm.startLogUpload(requestID, logType, location, nil, retries, retryInterval)
+}
+
+// UploadLog starts an OCPP 2.0.1 GetLog. An archive of the log entries between the optional
+// oldest and latest timestamps is uploaded to the HTTP(S) location. Returns Rejected for other
+// locations and AcceptedCanceled if a running log upload was replaced.
+func (fm *FirmwareManager) UploadLog(requestID int, logType v16.LogType, location string, oldest, latest *time.Time, retries, retryInterval *int) (v16.LogStatus, string) {
+	if err := validateTransferLocation(location); err != nil {
+		fm.logger.Warn("Rejecting log upload", "stationId", fm.stationID, "error", err)
+		return v16.LogStatusRejected, ""
+	}
+
+	return fm.startLogUpload(requestID, logType, location, &logRange{oldest: oldest, latest: latest}, retries, retryInterval)
+}
+
+// logRange selects the entries of an uploaded log
+type logRange struct {
+	oldest *time.Time
+	latest *time.Time
+}
+
+// startLogUpload replaces any running upload with a log upload, the log is only generated and
+// transferred when a range is given
+func (fm *FirmwareManager) startLogUpload(requestID int, logType v16.LogType, location string, transfer *logRange, retries, retryInterval *int) (v16.LogStatus, string) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fm.mu.Lock()
+	status := v16.LogStatusAccepted
+	if fm.uploadCancel != nil {
+		fm.uploadCancel()
+		if fm.logRequestID != nil {
+			status = v16.LogStatusAcceptedCanceled
+		}
+	}
+	fm.uploadCancel = cancel
+	fm.uploadID++
+	uploadID := fm.uploadID
+	config := fm.config
+	fm.logRequestID = &requestID
+	fm.mu.Unlock()
+
+	prefix := "diagnostics"
+	if logType == v16.LogTypeSecurityLog {
+		prefix = "security"
+	}
+	extension := "log"
+	if transfer != nil {
+		extension = "tar.gz"
+	}
+	fileName := fmt.Sprintf("%s-%s-%s.%s", prefix, fm.stationID, time.Now().UTC().Format("20060102T150405Z"), extension)
+
+	fm.logger.Info("Starting log upload",
+		"stationId", fm.stationID,
+		"requestId", requestID,
+		"logType", logType,
+		"location", location,
+		"fileName", fileName,
+	)
+
+	report := func(stage uploadStage) {
+		switch stage {
+		case uploadStageUploading:
+			fm.setLogStatus(v16.UploadLogStatusUploading, requestID)
+		case uploadStageUploaded:
+			fm.setLogStatus(v16.UploadLogStatusUploaded, requestID)
+		case uploadStageFailed:
+			fm.setLogStatus(v16.UploadLogStatusUploadFailure, requestID)
+		}
+	}
+
+	var upload func(ctx context.Context) error
+	if transfer != nil {
+		var lines []string
+		if fm.CollectLog != nil {
+			lines = fm.CollectLog(logType, transfer.oldest, transfer.latest)
+		}
+		header := fmt.Sprintf("# %s of %s generated %s", logType, fm.stationID, time.Now().UTC().Format(time.RFC3339))
+
+		archive, err := logArchive(string(logType)+".log", append([]string{header}, lines...))
+		if err != nil {
+			fm.logger.Error("Failed to generate log archive", "stationId", fm.stationID, "error", err)
+		}
+		upload = func(ctx context.Context) error {
+			if err != nil {
+				return err
+			}
+			return fm.upload(ctx, location, fileName, archive)
+		}
+	}
+
+	go fm.runUpload(ctx, uploadID, config, attempts(retries), fm.retryDelay(retryInterval), upload, report)
+
+	return status, fileName
+}
+
+// Shutdown cancels any running update, upload or publishing and stops serving published firmware
+func (fm *FirmwareManager) Shutdown() {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.updateCancel != nil {
+		fm.updateCancel()
+		fm.updateCancel = nil
+	}
+	if fm.uploadCancel != nil {
+		fm.uploadCancel()
+		fm.uploadCancel = nil
+	}
+	if fm.publishCancel != nil {
+		fm.publishCancel()
+		fm.publishCancel = nil
+	}
+	if fm.fileServer != nil {
+		fm.fileServer.close()
+		fm.fileServer = nil
+	}
+}
+
+// runUpdate runs the download/install lifecycle of a firmware update
+func (fm *FirmwareManager) runUpdate(ctx context.Context, updateID int, config FirmwareSimulationConfig, location string, retrieveDate time.Time, signed *signedFirmware, maxAttempts int, retryDelay time.Duration) {
+	defer fm.finishUpdate(updateID)
+
+	if signed != nil && time.Until(retrieveDate) > 0 {
+		fm.setFirmwareStatus(v16.FirmwareStatusDownloadScheduled)
+	}
+
+	if !fm.sleep(ctx, time.Until(retrieveDate)) {
+		return
+	}
+
+	var image []byte
+	downloaded := false
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		fm.setFirmwareStatus(v16.FirmwareStatusDownloading)
+
+		if signed != nil && signed.transfer {
+			data, err := fm.download(ctx, location)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				image = data
+				downloaded = true
+				break
+			}
+
+			fm.logger.Warn("Firmware download failed",
+				"stationId", fm.stationID,
+				"attempt", attempt,
+				"maxAttempts", maxAttempts,
+				"error", err,
+			)
+		} else {
+			if !fm.sleep(ctx, fm.duration(config.DownloadDuration, defaultDownloadDuration)) {
+				return
+			}
+
+			if attempt > config.DownloadFailures {
+				downloaded = true
+				break
+			}
+
+			fm.logger.Warn("Simulated firmware download failure",
+				"stationId", fm.stationID,
+				"attempt", attempt,
+				"maxAttempts", maxAttempts,
+			)
+		}
+
+		if attempt < maxAttempts && !fm.sleep(ctx, retryDelay) {
+			return
+		}
+	}
+
+	if !downloaded {
+		fm.setFirmwareStatus(v16.FirmwareStatusDownloadFailed)
+		return
+	}
+
+	fm.setFirmwareStatus(v16.FirmwareStatusDownloaded)
+
+	if signed != nil {
+		if signed.verifies() {
+			if err := signed.verify(image); err != nil {
+				fm.logger.Warn("Invalid firmware signature", "stationId", fm.stationID, "error", err)
+				fm.setFirmwareStatus(v16.FirmwareStatusInvalidSignature)
+				if fm.OnSecurityEvent != nil {
+					fm.OnSecurityEvent(v16.SecurityEventInvalidFirmwareSignature, location)
+				}
+				return
+			}
+
+			fm.setFirmwareStatus(v16.FirmwareStatusSignatureVerified)
+		}
+
+		if signed.installDate != nil && time.Until(*signed.installDate) > 0 {
+			fm.setFirmwareStatus(v16.FirmwareStatusInstallScheduled)
+			if !fm.sleep(ctx, time.Until(*signed.installDate)) {
+				return
+			}
+		}
+	}
+
+	// Installation waits until ongoing transactions have finished
+	if fm.WaitForIdle != nil {
+		if err := fm.WaitForIdle(ctx); err != nil {
+			return
+		}
+	}
+
+	fm.setFirmwareStatus(v16.FirmwareStatusInstalling)
+
+	if !fm.sleep(ctx, fm.duration(config.InstallDuration, defaultInstallDuration)) {
+		return
+	}
+
+	if config.FailInstallation {
+		fm.setFirmwareStatus(v16.FirmwareStatusInstallationFailed)
+		return
+	}
+
+	fm.setFirmwareStatus(v16.FirmwareStatusInstalled)
+
+	version := config.TargetVersion
+	if version == "" {
+		version = firmwareVersionFromLocation(location)
+	}
+
+	fm.logger.Info("Firmware installed", "stationId", fm.stationID, "version", version)
+
+	// The station is back to Idle once it reboots with the new firmware
+	fm.mu.Lock()
+	fm.firmwareStatus = v16.FirmwareStatusIdle
+	fm.firmwareRequestID = nil
+	fm.mu.Unlock()
+
+	if fm.OnFirmwareInstalled != nil {
+		fm.OnFirmwareInstalled(version)
+	}
+}
+
+// runUpload runs a diagnostics or log upload with retries, the upload is simulated if no
+// transfer function is given
+func (fm *FirmwareManager) runUpload(ctx context.Context, uploadID int, config FirmwareSimulationConfig, maxAttempts int, retryDelay time.Duration, upload func(ctx context.Context) error, report func(stage uploadStage)) {
+	defer fm.finishUpload(uploadID)
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		report(uploadStageUploading)
+
+		if upload != nil {
+			err := upload(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				report(uploadStageUploaded)
+				return
+			}
+
+			fm.logger.Warn("Log upload failed",
+				"stationId", fm.stationID,
+				"attempt", attempt,
+				"maxAttempts", maxAttempts,
+				"error", err,
+			)
+		} else {
+			if !fm.sleep(ctx, fm.duration(config.UploadDuration, defaultUploadDuration)) {
+				return
+			}
+
+			if attempt > config.UploadFailures {
+				report(uploadStageUploaded)
+				return
+			}
+
+			fm.logger.Warn("Simulated upload failure",
+				"stationId", fm.stationID,
+				"attempt", attempt,
+				"maxAttempts", maxAttempts,
+			)
+		}
+
+		if attempt < maxAttempts && !fm.sleep(ctx, retryDelay) {
+			return
+		}
+	}
+
+	report(uploadStageFailed)
+}
+
+// reportDiagnosticsUpload reports the stages of a GetDiagnostics upload
+func (fm *FirmwareManager) reportDiagnosticsUpload(stage uploadStage) {
+	switch stage {
+	case uploadStageUploading:
+		fm.setDiagnosticsStatus(v16.DiagnosticsStatusUploading)
+	case uploadStageUploaded:
+		fm.setDiagnosticsStatus(v16.DiagnosticsStatusUploaded)
+	case uploadStageFailed:
+		fm.setDiagnosticsStatus(v16.DiagnosticsStatusUploadFailed)
+	}
+}
+
+// finishUpdate clears the running update unless it has been replaced
+func (fm *FirmwareManager) finishUpdate(updateID int) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.updateID == updateID && fm.updateCancel != nil {
+		fm.updateCancel()
+		fm.updateCancel = nil
+	}
+}
+
+// finishUpload clears the running upload unless it has been replaced
+func (fm *FirmwareManager) finishUpload(uploadID int) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.uploadID == uploadID && fm.uploadCancel != nil {
+		fm.uploadCancel()
+		fm.uploadCancel = nil
+	}
+}
+
+// setFirmwareStatus records and reports a firmware status.
+// Signed updates are reported with SignedFirmwareStatusNotification.
+func (fm *FirmwareManager) setFirmwareStatus(status v16.FirmwareStatus) {
+	fm.mu.Lock()
+	fm.firmwareStatus = status
+	requestID := fm.firmwareRequestID
+	fm.mu.Unlock()
+
+	fm.logger.Info("Firmware status changed", "stationId", fm.stationID, "status", status)
+
+	if requestID != nil {
+		if fm.SendSignedFirmwareStatus != nil {
+			if err := fm.SendSignedFirmwareStatus(status, requestID); err != nil {
+				fm.logger.Warn("Failed to report signed firmware status", "stationId", fm.stationID, "error", err)
+			}
+		}
+		return
+	}
+
+	if fm.SendFirmwareStatus != nil {
+		if err := fm.SendFirmwareStatus(status); err != nil {
+			fm.logger.Warn("Failed to report firmware status", "stationId", fm.stationID, "error", err)
+		}
+	}
+}
+
+// setDiagnosticsStatus records and reports a diagnostics status
+func (fm *FirmwareManager) setDiagnosticsStatus(status v16.DiagnosticsStatus) {
+	fm.mu.Lock()
+	fm.diagnosticsStatus = status
+	fm.mu.Unlock()
+
+	fm.logger.Info("Diagnostics status changed", "stationId", fm.stationID, "status", status)
+
+	if fm.SendDiagnosticsStatus != nil {
+		if err := fm.SendDiagnosticsStatus(status); err != nil {
+			fm.logger.Warn("Failed to report diagnostics status", "stationId", fm.stationID, "error", err)
+		}
+	}
+}
+
+// setLogStatus records and reports a log upload status
+func (fm *FirmwareManager) setLogStatus(status v16.UploadLogStatus, requestID int) {
+	fm.mu.Lock()
+	fm.logStatus = status
+	fm.mu.Unlock()
+
+	fm.logger.Info("Log upload status changed", "stationId", fm.stationID, "status", status, "requestId", requestID)
+
+	if fm.SendLogStatus != nil {
+		if err := fm.SendLogStatus(status, &requestID); err != nil {
+			fm.logger.Warn("Failed to report log status", "stationId", fm.stationID, "error", err)
+		}
+	}
+}
+
+// sleep waits for the duration and returns false if the context was cancelled
+func (fm *FirmwareManager) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// duration converts a configured number of seconds to a duration, using the default if unset
+func (fm *FirmwareManager) duration(seconds, defaultSeconds int) time.Duration {
+	if seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	return time.Duration(seconds) * fm.timeUnit
+}
+
+// retryDelay returns the delay between attempts requested by the CSMS
+func (fm *FirmwareManager) retryDelay(retryInterval *int) time.Duration {
+	if retryInterval == nil {
+		return fm.duration(0, defaultRetryInterval)
+	}
+	return time.Duration(*retryInterval) * fm.timeUnit
+}
+
+// attempts returns the number of attempts for the requested number of retries
+func attempts(retries *int) int {
+	if retries == nil || *retries < 0 {
+		return 1
+	}
+	return *retries + 1
+}
+
+// firmwareVersionFromLocation derives a firmware version from the file name of the location,
+// e.g. "https://example.com/fw/cp-2.1.0.bin" becomes "cp-2.1.0"
+func firmwareVersionFromLocation(location string) string {
+	p := location
+	if u, err := url.Parse(location); err == nil && u.Path != "" {
+		p = u.Path
+	}
+
+	name := path.Base(p)
+	if name == "." || name == "/" {
+		return ""
+	}
+
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip", ".bin", ".img", ".hex", ".fw"} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+
+	return name
+}
+
+// verifies reports whether the firmware signature is verified before installation. OCPP 2.0.1
+// firmware is only verified when it is signed.
+func (sf *signedFirmware) verifies() bool {
+	return !sf.transfer || sf.signature != ""
+}
+
+// verify checks the signature of a downloaded firmware image, simulated downloads only check
+// that a signature is present
+func (sf *signedFirmware) verify(image []byte) error {
+	if !sf.transfer {
+		if !validFirmwareSignature(sf.signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+	return verifyFirmwareSignature(image, sf.signingCertificate, sf.signature)
+}
+
+// validFirmwareSignature simulates the verification of a firmware signature.
+// The firmware image is not really downloaded, so any non-empty Base64 signature is accepted.
+func validFirmwareSignature(signature string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	return err == nil && len(decoded) > 0
+}
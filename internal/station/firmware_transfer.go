@@ -0,0 +1,192 @@
+package station
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// transferTimeout limits a single firmware download or log upload attempt
+	transferTimeout = 5 * time.Minute
+
+	// maxFirmwareSize is the largest firmware image that is downloaded
+	maxFirmwareSize = 256 << 20
+)
+
+// validateTransferLocation checks that firmware and logs can be transferred with the location
+func validateTransferLocation(location string) error {
+	u, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("invalid location %q: %w", location, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported location %q, only HTTP(S) transfers are supported", location)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid location %q: missing host", location)
+	}
+	return nil
+}
+
+// download fetches a firmware image
+func (fm *FirmwareManager) download(ctx context.Context, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fm.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	image, err := io.ReadAll(io.LimitReader(resp.Body, maxFirmwareSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(image) > maxFirmwareSize {
+		return nil, fmt.Errorf("firmware image exceeds %d bytes", maxFirmwareSize)
+	}
+
+	fm.logger.Info("Firmware downloaded", "stationId", fm.stationID, "location", location, "size", len(image))
+	return image, nil
+}
+
+// upload sends a file to the remote location. A location ending with a slash is a directory the
+// file is PUT into, any other location receives the file as multipart/form-data POST.
+func (fm *FirmwareManager) upload(ctx context.Context, location, fileName string, data []byte) error {
+	var req *http.Request
+	var err error
+
+	if strings.HasSuffix(location, "/") {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPut, location+url.PathEscape(fileName), bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/gzip")
+	} else {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", fileName)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(data); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, location, &body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+	}
+
+	resp, err := fm.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("upload failed: %s", resp.Status)
+	}
+
+	fm.logger.Info("Log uploaded", "stationId", fm.stationID, "location", req.URL.String(), "size", len(data))
+	return nil
+}
+
+// logArchive packs log lines as a single file into a gzip compressed tar archive
+func logArchive(name string, lines []string) ([]byte, error) {
+	content := []byte(strings.Join(lines, "\n") + "\n")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// verifyFirmwareSignature checks the Base64 signature of a firmware image with the public key of
+// the PEM signing certificate. RSA (PKCS #1 v1.5 or PSS) and ECDSA signatures of the SHA-256
+// digest are supported.
+func verifyFirmwareSignature(image []byte, certificate, signature string) error {
+	if certificate == "" {
+		return errors.New("missing signing certificate")
+	}
+
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return errors.New("failed to decode PEM signing certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid Base64 signature: %w", err)
+	}
+
+	digest := sha256.Sum256(image)
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil {
+			return nil
+		}
+		return rsa.VerifyPSS(key, crypto.SHA256, digest[:], sig, nil)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], sig) {
+			return errors.New("ECDSA signature does not match")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing key %T", cert.PublicKey)
+	}
+}
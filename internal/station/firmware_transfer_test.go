@@ -0,0 +1,402 @@
+package station
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+// signFirmware returns a PEM signing certificate and the Base64 ECDSA signature of an image
+func signFirmware(t *testing.T, image []byte) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(5001),
+		Subject:      pkix.Name{CommonName: "Firmware Signing"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	digest := sha256.Sum256(image)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign firmware: %v", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), base64.StdEncoding.EncodeToString(sig)
+}
+
+// recordSignedStatuses records the statuses reported for an OCPP 2.0.1 firmware update
+func recordSignedStatuses(fm *FirmwareManager) func() []v16.FirmwareStatus {
+	var mu sync.Mutex
+	var statuses []v16.FirmwareStatus
+	fm.SendSignedFirmwareStatus = func(status v16.FirmwareStatus, requestID *int) error {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+		return nil
+	}
+
+	return func() []v16.FirmwareStatus {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]v16.FirmwareStatus(nil), statuses...)
+	}
+}
+
+func TestFirmwareManager_DownloadFirmware(t *testing.T) {
+	image := []byte("firmware image 3.1.0")
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt fails to exercise the retries
+		if requests.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(image)
+	}))
+	defer server.Close()
+
+	fm, _ := newTestFirmwareManager(FirmwareSimulationConfig{InstallDuration: 5})
+	statuses := recordSignedStatuses(fm)
+
+	installed := make(chan string, 1)
+	fm.OnFirmwareInstalled = func(version string) {
+		installed <- version
+	}
+
+	certificate, signature := signFirmware(t, image)
+	retries, retryInterval := 1, 5
+	status := fm.DownloadFirmware(11, FirmwareImage{
+		Location:           server.URL + "/fw/cp-3.1.0.bin",
+		RetrieveDate:       time.Now(),
+		SigningCertificate: certificate,
+		Signature:          signature,
+	}, &retries, &retryInterval)
+	if status != v16.UpdateFirmwareStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+
+	select {
+	case version := <-installed:
+		if version != "cp-3.1.0" {
+			t.Errorf("Expected version cp-3.1.0, got %s", version)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for firmware installation")
+	}
+
+	expected := []v16.FirmwareStatus{
+		v16.FirmwareStatusDownloading,
+		v16.FirmwareStatusDownloading,
+		v16.FirmwareStatusDownloaded,
+		v16.FirmwareStatusSignatureVerified,
+		v16.FirmwareStatusInstalling,
+		v16.FirmwareStatusInstalled,
+	}
+	if got := statuses(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, got)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 download attempts, got %d", requests.Load())
+	}
+}
+
+func TestFirmwareManager_DownloadFirmwareFailures(t *testing.T) {
+	image := []byte("firmware image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(image)
+	}))
+	defer server.Close()
+
+	fm, _ := newTestFirmwareManager(FirmwareSimulationConfig{InstallDuration: 5})
+
+	if status := fm.DownloadFirmware(1, FirmwareImage{Location: "ftp://example.com/fw.bin", RetrieveDate: time.Now()}, nil, nil); status != v16.UpdateFirmwareStatusRejected {
+		t.Errorf("Expected FTP location to be rejected, got %s", status)
+	}
+
+	// Download failure
+	statuses := recordSignedStatuses(fm)
+	fm.DownloadFirmware(2, FirmwareImage{Location: server.URL + "/missing.bin", RetrieveDate: time.Now()}, nil, nil)
+	waitFor(t, func() bool { return !fm.IsUpdating() })
+
+	expected := []v16.FirmwareStatus{v16.FirmwareStatusDownloading, v16.FirmwareStatusDownloadFailed}
+	if got := statuses(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, got)
+	}
+
+	// Signature of another image
+	statuses = recordSignedStatuses(fm)
+	events := make(chan string, 1)
+	fm.OnSecurityEvent = func(eventType, techInfo string) {
+		events <- eventType
+	}
+	fm.OnFirmwareInstalled = func(version string) {
+		t.Error("Firmware with an invalid signature must not be installed")
+	}
+
+	certificate, signature := signFirmware(t, []byte("other image"))
+	fm.DownloadFirmware(3, FirmwareImage{
+		Location:           server.URL + "/fw.bin",
+		RetrieveDate:       time.Now(),
+		SigningCertificate: certificate,
+		Signature:          signature,
+	}, nil, nil)
+
+	select {
+	case eventType := <-events:
+		if eventType != v16.SecurityEventInvalidFirmwareSignature {
+			t.Errorf("Expected InvalidFirmwareSignature, got %s", eventType)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for security event")
+	}
+	waitFor(t, func() bool { return !fm.IsUpdating() })
+
+	expected = []v16.FirmwareStatus{v16.FirmwareStatusDownloading, v16.FirmwareStatusDownloaded, v16.FirmwareStatusInvalidSignature}
+	if got := statuses(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, got)
+	}
+}
+
+// readLogArchive returns the content of the single file of an uploaded log archive
+func readLogArchive(t *testing.T, r io.Reader) (string, string) {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("Failed to read gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil {
+		t.Fatalf("Failed to read tar: %v", err)
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	return header.Name, string(content)
+}
+
+func TestFirmwareManager_UploadLog(t *testing.T) {
+	type received struct {
+		method, path, name, content string
+	}
+	uploads := make(chan received, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upload := received{method: r.Method, path: r.URL.Path}
+		if r.Method == http.MethodPost {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Expected multipart file: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			upload.name, upload.content = readLogArchive(t, file)
+		} else {
+			upload.name, upload.content = readLogArchive(t, r.Body)
+		}
+		uploads <- upload
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	fm, _ := newTestFirmwareManager(FirmwareSimulationConfig{})
+	var collected v16.LogType
+	fm.CollectLog = func(logType v16.LogType, oldest, latest *time.Time) []string {
+		collected = logType
+		return []string{"first entry", "second entry"}
+	}
+
+	var mu sync.Mutex
+	var statuses []v16.UploadLogStatus
+	fm.SendLogStatus = func(status v16.UploadLogStatus, requestID *int) error {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+		return nil
+	}
+
+	// PUT into a directory
+	status, fileName := fm.UploadLog(21, v16.LogTypeSecurityLog, server.URL+"/logs/", nil, nil, nil, nil)
+	if status != v16.LogStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+	if !strings.HasSuffix(fileName, ".tar.gz") {
+		t.Errorf("Expected a tar.gz file name, got %s", fileName)
+	}
+
+	select {
+	case upload := <-uploads:
+		if upload.method != http.MethodPut || upload.path != "/logs/"+fileName {
+			t.Errorf("Expected PUT to /logs/%s, got %s %s", fileName, upload.method, upload.path)
+		}
+		if upload.name != "SecurityLog.log" || !strings.Contains(upload.content, "second entry") {
+			t.Errorf("Unexpected log %s: %q", upload.name, upload.content)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for upload")
+	}
+	if collected != v16.LogTypeSecurityLog {
+		t.Errorf("Expected SecurityLog to be collected, got %s", collected)
+	}
+
+	waitFor(t, func() bool {
+		status, _ := fm.LogStatus()
+		return status == v16.UploadLogStatusUploaded
+	})
+
+	// POST as form upload
+	fm.UploadLog(22, v16.LogTypeDiagnosticsLog, server.URL+"/upload", nil, nil, nil, nil)
+
+	select {
+	case upload := <-uploads:
+		if upload.method != http.MethodPost || upload.path != "/upload" {
+			t.Errorf("Expected POST to /upload, got %s %s", upload.method, upload.path)
+		}
+		if upload.name != "DiagnosticsLog.log" {
+			t.Errorf("Expected DiagnosticsLog.log, got %s", upload.name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for upload")
+	}
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(statuses) == 4
+	})
+
+	mu.Lock()
+	expected := []v16.UploadLogStatus{
+		v16.UploadLogStatusUploading,
+		v16.UploadLogStatusUploaded,
+		v16.UploadLogStatusUploading,
+		v16.UploadLogStatusUploaded,
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, statuses)
+	}
+	mu.Unlock()
+
+	if status, _ := fm.UploadLog(23, v16.LogTypeDiagnosticsLog, "ftp://example.com/logs", nil, nil, nil, nil); status != v16.LogStatusRejected {
+		t.Errorf("Expected FTP location to be rejected, got %s", status)
+	}
+}
+
+func TestFirmwareManager_UploadLogFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	fm, _ := newTestFirmwareManager(FirmwareSimulationConfig{})
+
+	retries, retryInterval := 1, 5
+	fm.UploadLog(31, v16.LogTypeDiagnosticsLog, server.URL+"/logs/", nil, nil, &retries, &retryInterval)
+
+	waitFor(t, func() bool {
+		status, _ := fm.LogStatus()
+		return status == v16.UploadLogStatusUploadFailure
+	})
+}
+
+func TestHandleV201UpdateFirmware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	fm, _ := newTestFirmwareManager(FirmwareSimulationConfig{})
+	station := &Station{
+		Config:   Config{StationID: "TEST019"},
+		Firmware: fm,
+		Security: newTestSecurityManager(),
+	}
+	manager.mu.Lock()
+	manager.stations["TEST019"] = station
+	manager.mu.Unlock()
+
+	certificate, signature := signFirmware(t, []byte("image"))
+	resp, _ := manager.handleV201UpdateFirmware("TEST019", &v201.UpdateFirmwareRequest{
+		RequestId: 1,
+		Firmware: v201.Firmware{
+			Location:           "https://example.com/fw.bin",
+			RetrieveDateTime:   v201.DateTime{Time: time.Now()},
+			SigningCertificate: certificate,
+			Signature:          signature,
+		},
+	})
+	if resp.Status != v201.UpdateFirmwareStatusInvalidCertificate {
+		t.Errorf("Expected InvalidCertificate without ManufacturerRootCertificate, got %s", resp.Status)
+	}
+	if fm.IsUpdating() {
+		t.Error("Expected no update for an invalid signing certificate")
+	}
+
+	events := station.Security.SecurityLog()
+	if len(events) == 0 || events[len(events)-1].Type != v16.SecurityEventInvalidFirmwareSigningCertificate {
+		t.Errorf("Expected InvalidFirmwareSigningCertificate security event, got %v", events)
+	}
+
+	resp, _ = manager.handleV201UpdateFirmware("TEST019", &v201.UpdateFirmwareRequest{
+		RequestId: 2,
+		Firmware:  v201.Firmware{Location: "ftp://example.com/fw.bin"},
+	})
+	if resp.Status != v201.UpdateFirmwareStatusRejected {
+		t.Errorf("Expected FTP location to be rejected, got %s", resp.Status)
+	}
+
+	logResp, _ := manager.handleV201GetLog("TEST019", &v201.GetLogRequest{
+		LogType:   "ChargingLog",
+		RequestId: 3,
+		Log:       v201.LogParameters{RemoteLocation: "https://example.com/logs/"},
+	})
+	if logResp.Status != v201.LogStatusRejected {
+		t.Errorf("Expected unknown log type to be rejected, got %s", logResp.Status)
+	}
+
+	logResp, _ = manager.handleV201GetLog("UNKNOWN", &v201.GetLogRequest{
+		LogType: v201.LogTypeDiagnosticsLog,
+		Log:     v201.LogParameters{RemoteLocation: "https://example.com/logs/"},
+	})
+	if logResp.Status != v201.LogStatusRejected {
+		t.Errorf("Expected log of unknown station to be rejected, got %s", logResp.Status)
+	}
+}
@@ -0,0 +1,580 @@
+package station
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	"github.com/ruslanhut/ocpp-emu/internal/storage"
+)
+
+// authCacheEntrySize is the storage an authorization cache entry takes, used to derive the
+// number of cached idTokens from AuthCacheCtrlr.Storage
+const authCacheEntrySize = 128
+
+// IdTokenAuthConfig holds the AuthCtrlr, LocalAuthListCtrlr and AuthCacheCtrlr variables that
+// control how OCPP 2.0.1 idTokens are authorized
+type IdTokenAuthConfig struct {
+	Enabled                      bool // AuthCtrlr.Enabled, without authorization every idToken is accepted
+	LocalPreAuthorize            bool
+	LocalAuthorizeOffline        bool
+	OfflineTxForUnknownIdEnabled bool
+	LocalAuthListEnabled         bool
+	LocalAuthListMaxEntries      int // Maximum of LocalAuthListCtrlr.Entries
+	ItemsPerMessage              int // Maximum entries of a SendLocalList
+	AuthCacheEnabled             bool
+	AuthCacheLifeTime            time.Duration // 0 keeps entries until they are evicted
+	AuthCacheMaxEntries          int
+}
+
+// DefaultIdTokenAuthConfig returns the authorization configuration of a new device model
+func DefaultIdTokenAuthConfig() IdTokenAuthConfig {
+	return IdTokenAuthConfig{
+		Enabled:                 true,
+		LocalAuthorizeOffline:   true,
+		LocalAuthListEnabled:    true,
+		LocalAuthListMaxEntries: 1000,
+		ItemsPerMessage:         100,
+		AuthCacheEnabled:        true,
+		AuthCacheLifeTime:       24 * time.Hour,
+		AuthCacheMaxEntries:     1000,
+	}
+}
+
+// IdTokenAuthConfigFromDeviceModel reads the authorization configuration of a station from its
+// device model, missing variables keep their defaults
+func IdTokenAuthConfigFromDeviceModel(dm *v201.DeviceModel) IdTokenAuthConfig {
+	config := DefaultIdTokenAuthConfig()
+	if dm == nil {
+		return config
+	}
+
+	boolVar := func(component, variable string, target *bool) {
+		if value, status := dm.GetVariable(component, "", variable, "", v201.AttributeActual); status == v201.GetVariableStatusAccepted {
+			*target = strings.EqualFold(value, "true")
+		}
+	}
+	intVar := func(component, variable string) (int, bool) {
+		value, status := dm.GetVariable(component, "", variable, "", v201.AttributeActual)
+		if status != v201.GetVariableStatusAccepted {
+			return 0, false
+		}
+		n, err := strconv.Atoi(value)
+		return n, err == nil && n >= 0
+	}
+
+	boolVar("AuthCtrlr", "Enabled", &config.Enabled)
+	boolVar("AuthCtrlr", "LocalPreAuthorize", &config.LocalPreAuthorize)
+	boolVar("AuthCtrlr", "LocalAuthorizeOffline", &config.LocalAuthorizeOffline)
+	boolVar("AuthCtrlr", "OfflineTxForUnknownIdEnabled", &config.OfflineTxForUnknownIdEnabled)
+	boolVar("LocalAuthListCtrlr", "Enabled", &config.LocalAuthListEnabled)
+	boolVar("AuthCacheCtrlr", "Enabled", &config.AuthCacheEnabled)
+
+	if characteristics, ok := dm.GetCharacteristics("LocalAuthListCtrlr", "", "Entries", ""); ok && characteristics.MaxLimit != nil {
+		config.LocalAuthListMaxEntries = int(*characteristics.MaxLimit)
+	}
+	if n, ok := intVar("LocalAuthListCtrlr", "ItemsPerMessage"); ok && n > 0 {
+		config.ItemsPerMessage = n
+	}
+	if n, ok := intVar("AuthCacheCtrlr", "LifeTime"); ok {
+		config.AuthCacheLifeTime = time.Duration(n) * time.Second
+	}
+	if n, ok := intVar("AuthCacheCtrlr", "Storage"); ok {
+		config.AuthCacheMaxEntries = n / authCacheEntrySize
+	}
+
+	return config
+}
+
+// idTokenKey identifies an idToken, equal values of different types are different idTokens
+type idTokenKey struct {
+	idToken   string
+	tokenType v201.IdTokenType
+}
+
+func keyOf(idToken v201.IdToken) idTokenKey {
+	return idTokenKey{idToken: idToken.IdToken, tokenType: idToken.Type}
+}
+
+// cachedIdToken is an entry of the authorization cache
+type cachedIdToken struct {
+	info      v201.IdTokenInfo
+	expiresAt time.Time // End of the cache lifetime, zero if unlimited
+	lastUsed  time.Time
+}
+
+// IdTokenAuthorization holds the OCPP 2.0.1 Local Authorization List and authorization cache of
+// a station. Cache entries expire after AuthCacheCtrlr.LifeTime or their cacheExpiryDateTime, the
+// least recently used entry is evicted when the cache is full.
+type IdTokenAuthorization struct {
+	stationID   string
+	config      IdTokenAuthConfig
+	listVersion int
+	localList   map[idTokenKey]v201.IdTokenInfo
+	cache       map[idTokenKey]*cachedIdToken
+	mu          sync.RWMutex
+	repo        *storage.AuthorizationRepository
+	logger      *slog.Logger
+
+	// Snapshots are versioned under mu and saved under persistMu, so a stale snapshot
+	// saved after a newer one never overwrites it
+	snapshotVersion  uint64
+	persistedVersion uint64
+	persistMu        sync.Mutex
+}
+
+// NewIdTokenAuthorization creates an empty idToken authorization store with default configuration
+func NewIdTokenAuthorization(stationID string, logger *slog.Logger) *IdTokenAuthorization {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &IdTokenAuthorization{
+		stationID: stationID,
+		config:    DefaultIdTokenAuthConfig(),
+		localList: make(map[idTokenKey]v201.IdTokenInfo),
+		cache:     make(map[idTokenKey]*cachedIdToken),
+		logger:    logger,
+	}
+}
+
+// SetRepository sets the repository used to persist the list and cache
+func (ia *IdTokenAuthorization) SetRepository(repo *storage.AuthorizationRepository) {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+	ia.repo = repo
+}
+
+// Load restores the list and cache from the repository
+func (ia *IdTokenAuthorization) Load(ctx context.Context) error {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	if ia.repo == nil {
+		return nil
+	}
+
+	data, err := ia.repo.Get(ctx, ia.stationID)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	ia.listVersion = data.IdTokenListVersion
+	ia.localList = make(map[idTokenKey]v201.IdTokenInfo, len(data.IdTokenList))
+	for _, entry := range data.IdTokenList {
+		key, info := idTokenEntryToInfo(entry)
+		ia.localList[key] = info
+	}
+	ia.cache = make(map[idTokenKey]*cachedIdToken, len(data.IdTokenCache))
+	for _, entry := range data.IdTokenCache {
+		key, info := idTokenEntryToInfo(entry)
+		cached := &cachedIdToken{info: info}
+		if entry.ExpiresAt != nil {
+			cached.expiresAt = *entry.ExpiresAt
+		}
+		if entry.LastUsed != nil {
+			cached.lastUsed = *entry.LastUsed
+		}
+		ia.cache[key] = cached
+	}
+
+	ia.logger.Info("Loaded idToken authorization data",
+		"stationId", ia.stationID,
+		"listVersion", ia.listVersion,
+		"listSize", len(ia.localList),
+		"cacheSize", len(ia.cache),
+	)
+
+	return nil
+}
+
+// Config returns the current authorization configuration
+func (ia *IdTokenAuthorization) Config() IdTokenAuthConfig {
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+	return ia.config
+}
+
+// SetConfig replaces the authorization configuration, a smaller cache evicts its least recently
+// used entries
+func (ia *IdTokenAuthorization) SetConfig(config IdTokenAuthConfig) {
+	ia.mu.Lock()
+	ia.config = config
+	evicted := ia.evictLocked(time.Now(), 0)
+	data := ia.snapshot()
+	ia.mu.Unlock()
+
+	if evicted {
+		ia.persist(data)
+	}
+}
+
+// ListVersion returns the version of the Local Authorization List, 0 without list
+func (ia *IdTokenAuthorization) ListVersion() int {
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+
+	if !ia.config.LocalAuthListEnabled {
+		return 0
+	}
+	return ia.listVersion
+}
+
+// Entries returns the number of idTokens on the Local Authorization List
+func (ia *IdTokenAuthorization) Entries() int {
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+	return len(ia.localList)
+}
+
+// SendLocalList applies a full or differential update of the Local Authorization List
+func (ia *IdTokenAuthorization) SendLocalList(req *v201.SendLocalListRequest) v201.SendLocalListStatusType {
+	ia.mu.Lock()
+
+	if !ia.config.LocalAuthListEnabled || req.VersionNumber <= 0 || len(req.LocalAuthorizationList) > ia.config.ItemsPerMessage {
+		ia.mu.Unlock()
+		return v201.SendLocalListStatusFailed
+	}
+
+	for _, entry := range req.LocalAuthorizationList {
+		if entry.IdToken.IdToken == "" || !entry.IdToken.Type.Valid() {
+			ia.mu.Unlock()
+			return v201.SendLocalListStatusFailed
+		}
+	}
+
+	var list map[idTokenKey]v201.IdTokenInfo
+
+	switch req.UpdateType {
+	case v201.UpdateTypeFull:
+		list = make(map[idTokenKey]v201.IdTokenInfo, len(req.LocalAuthorizationList))
+		for _, entry := range req.LocalAuthorizationList {
+			if entry.IdTokenInfo == nil {
+				ia.mu.Unlock()
+				return v201.SendLocalListStatusFailed
+			}
+			list[keyOf(entry.IdToken)] = *entry.IdTokenInfo
+		}
+
+	case v201.UpdateTypeDifferential:
+		if req.VersionNumber <= ia.listVersion {
+			ia.mu.Unlock()
+			return v201.SendLocalListStatusVersionMismatch
+		}
+
+		list = make(map[idTokenKey]v201.IdTokenInfo, len(ia.localList))
+		for key, info := range ia.localList {
+			list[key] = info
+		}
+		for _, entry := range req.LocalAuthorizationList {
+			if entry.IdTokenInfo == nil {
+				delete(list, keyOf(entry.IdToken))
+				continue
+			}
+			list[keyOf(entry.IdToken)] = *entry.IdTokenInfo
+		}
+
+	default:
+		ia.mu.Unlock()
+		return v201.SendLocalListStatusFailed
+	}
+
+	if len(list) > ia.config.LocalAuthListMaxEntries {
+		ia.mu.Unlock()
+		return v201.SendLocalListStatusFailed
+	}
+
+	ia.localList = list
+	ia.listVersion = req.VersionNumber
+
+	// idTokens on the local list are not kept in the cache
+	for key := range list {
+		delete(ia.cache, key)
+	}
+
+	data := ia.snapshot()
+	ia.mu.Unlock()
+
+	ia.logger.Info("Local authorization list updated",
+		"stationId", ia.stationID,
+		"updateType", req.UpdateType,
+		"versionNumber", req.VersionNumber,
+		"listSize", len(list),
+	)
+
+	ia.persist(data)
+	return v201.SendLocalListStatusAccepted
+}
+
+// ClearCache removes all entries from the authorization cache.
+// Returns false if the cache is disabled.
+func (ia *IdTokenAuthorization) ClearCache() bool {
+	ia.mu.Lock()
+	if !ia.config.AuthCacheEnabled {
+		ia.mu.Unlock()
+		return false
+	}
+
+	ia.cache = make(map[idTokenKey]*cachedIdToken)
+	data := ia.snapshot()
+	ia.mu.Unlock()
+
+	ia.logger.Info("Authorization cache cleared", "stationId", ia.stationID)
+	ia.persist(data)
+	return true
+}
+
+// UpdateCache stores the idTokenInfo returned by the CSMS for an idToken.
+// idTokens on the Local Authorization List are not cached.
+func (ia *IdTokenAuthorization) UpdateCache(idToken v201.IdToken, info v201.IdTokenInfo) {
+	ia.mu.Lock()
+
+	key := keyOf(idToken)
+	if !ia.config.AuthCacheEnabled || ia.config.AuthCacheMaxEntries == 0 || key.idToken == "" {
+		ia.mu.Unlock()
+		return
+	}
+
+	if _, onList := ia.localList[key]; onList && ia.config.LocalAuthListEnabled {
+		ia.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	cached := &cachedIdToken{info: info, lastUsed: now}
+	if ia.config.AuthCacheLifeTime > 0 {
+		cached.expiresAt = now.Add(ia.config.AuthCacheLifeTime)
+	}
+	if info.CacheExpiryDateTime != nil && (cached.expiresAt.IsZero() || info.CacheExpiryDateTime.Time.Before(cached.expiresAt)) {
+		cached.expiresAt = info.CacheExpiryDateTime.Time
+	}
+
+	if _, exists := ia.cache[key]; !exists {
+		ia.evictLocked(now, 1)
+	}
+	ia.cache[key] = cached
+	data := ia.snapshot()
+	ia.mu.Unlock()
+
+	ia.persist(data)
+}
+
+// Lookup returns the locally known idTokenInfo of an idToken. The Local Authorization List takes
+// precedence over the cache, expired list entries report Expired and expired cache entries are
+// removed.
+func (ia *IdTokenAuthorization) Lookup(idToken v201.IdToken) (*v201.IdTokenInfo, bool) {
+	ia.mu.Lock()
+
+	key := keyOf(idToken)
+	now := time.Now()
+
+	if ia.config.LocalAuthListEnabled {
+		if info, exists := ia.localList[key]; exists {
+			ia.mu.Unlock()
+			if info.CacheExpiryDateTime != nil && info.Status == v201.AuthorizationStatusAccepted && now.After(info.CacheExpiryDateTime.Time) {
+				info.Status = v201.AuthorizationStatusExpired
+			}
+			return &info, true
+		}
+	}
+
+	if !ia.config.AuthCacheEnabled {
+		ia.mu.Unlock()
+		return nil, false
+	}
+
+	cached, exists := ia.cache[key]
+	if !exists {
+		ia.mu.Unlock()
+		return nil, false
+	}
+
+	if !cached.expiresAt.IsZero() && now.After(cached.expiresAt) {
+		delete(ia.cache, key)
+		data := ia.snapshot()
+		ia.mu.Unlock()
+
+		ia.persist(data)
+		return nil, false
+	}
+
+	cached.lastUsed = now
+	info := cached.info
+	ia.mu.Unlock()
+
+	return &info, true
+}
+
+// PreAuthorize returns the local authorization of an idToken when LocalPreAuthorize allows
+// starting without waiting for the CSMS. Only Accepted entries pre-authorize.
+func (ia *IdTokenAuthorization) PreAuthorize(idToken v201.IdToken) (*v201.IdTokenInfo, bool) {
+	if !ia.Config().LocalPreAuthorize {
+		return nil, false
+	}
+
+	info, found := ia.Lookup(idToken)
+	if !found || info.Status != v201.AuthorizationStatusAccepted {
+		return nil, false
+	}
+
+	return info, true
+}
+
+// AuthorizeOffline decides on an idToken while the CSMS is unreachable
+func (ia *IdTokenAuthorization) AuthorizeOffline(idToken v201.IdToken) *v201.IdTokenInfo {
+	config := ia.Config()
+
+	if config.LocalAuthorizeOffline {
+		if info, found := ia.Lookup(idToken); found {
+			return info
+		}
+	}
+
+	if config.OfflineTxForUnknownIdEnabled {
+		return &v201.IdTokenInfo{Status: v201.AuthorizationStatusAccepted}
+	}
+
+	return &v201.IdTokenInfo{Status: v201.AuthorizationStatusUnknown}
+}
+
+// CacheSize returns the number of cached idTokens
+func (ia *IdTokenAuthorization) CacheSize() int {
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+	return len(ia.cache)
+}
+
+// evictLocked removes expired cache entries, then the least recently used ones until room is
+// left for the given number of new entries. Returns whether entries were removed (caller must
+// hold the lock).
+func (ia *IdTokenAuthorization) evictLocked(now time.Time, room int) bool {
+	evicted := false
+	for key, cached := range ia.cache {
+		if !cached.expiresAt.IsZero() && now.After(cached.expiresAt) {
+			delete(ia.cache, key)
+			evicted = true
+		}
+	}
+
+	for len(ia.cache) > 0 && len(ia.cache)+room > ia.config.AuthCacheMaxEntries {
+		var oldest idTokenKey
+		var oldestUse time.Time
+		first := true
+		for key, cached := range ia.cache {
+			if first || cached.lastUsed.Before(oldestUse) {
+				oldest, oldestUse, first = key, cached.lastUsed, false
+			}
+		}
+
+		ia.logger.Debug("Authorization cache entry evicted", "stationId", ia.stationID, "idToken", oldest.idToken)
+		delete(ia.cache, oldest)
+		evicted = true
+	}
+
+	return evicted
+}
+
+// snapshot converts the list and cache to the next version of their storage form (caller must
+// hold the write lock)
+func (ia *IdTokenAuthorization) snapshot() authorizationSnapshot {
+	data := storage.AuthorizationData{
+		StationID:          ia.stationID,
+		IdTokenListVersion: ia.listVersion,
+		IdTokenList:        make([]storage.IdTokenEntry, 0, len(ia.localList)),
+		IdTokenCache:       make([]storage.IdTokenEntry, 0, len(ia.cache)),
+	}
+
+	for key, info := range ia.localList {
+		data.IdTokenList = append(data.IdTokenList, idTokenInfoToEntry(key, info))
+	}
+	for key, cached := range ia.cache {
+		entry := idTokenInfoToEntry(key, cached.info)
+		if !cached.expiresAt.IsZero() {
+			expiresAt := cached.expiresAt
+			entry.ExpiresAt = &expiresAt
+		}
+		lastUsed := cached.lastUsed
+		entry.LastUsed = &lastUsed
+		data.IdTokenCache = append(data.IdTokenCache, entry)
+	}
+
+	ia.snapshotVersion++
+	return authorizationSnapshot{version: ia.snapshotVersion, data: data}
+}
+
+// persist saves a snapshot to the repository if one is configured, unless a newer one has been saved
+func (ia *IdTokenAuthorization) persist(snapshot authorizationSnapshot) {
+	ia.mu.RLock()
+	repo := ia.repo
+	ia.mu.RUnlock()
+
+	if repo == nil {
+		return
+	}
+
+	ia.persistMu.Lock()
+	defer ia.persistMu.Unlock()
+
+	if snapshot.version <= ia.persistedVersion {
+		return
+	}
+	ia.persistedVersion = snapshot.version
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.SaveIdTokens(ctx, snapshot.data); err != nil {
+		ia.logger.Error("Failed to persist idToken authorization data",
+			"stationId", ia.stationID,
+			"error", err,
+		)
+	}
+}
+
+// idTokenInfoToEntry converts an idToken with its info to a storage entry.
+// The personal message is not persisted.
+func idTokenInfoToEntry(key idTokenKey, info v201.IdTokenInfo) storage.IdTokenEntry {
+	entry := storage.IdTokenEntry{
+		IDToken:          key.idToken,
+		Type:             string(key.tokenType),
+		Status:           string(info.Status),
+		ChargingPriority: info.ChargingPriority,
+		Language1:        info.Language1,
+		Language2:        info.Language2,
+		EvseIDs:          info.EvseId,
+	}
+	if info.CacheExpiryDateTime != nil {
+		expiry := info.CacheExpiryDateTime.Time
+		entry.CacheExpiry = &expiry
+	}
+	if info.GroupIdToken != nil {
+		entry.GroupIDToken = info.GroupIdToken.IdToken
+		entry.GroupIDTokenType = string(info.GroupIdToken.Type)
+	}
+	return entry
+}
+
+// idTokenEntryToInfo converts a storage entry to an idToken with its info
+func idTokenEntryToInfo(entry storage.IdTokenEntry) (idTokenKey, v201.IdTokenInfo) {
+	info := v201.IdTokenInfo{
+		Status:           v201.AuthorizationStatusType(entry.Status),
+		ChargingPriority: entry.ChargingPriority,
+		Language1:        entry.Language1,
+		Language2:        entry.Language2,
+		EvseId:           entry.EvseIDs,
+	}
+	if entry.CacheExpiry != nil {
+		info.CacheExpiryDateTime = &v201.DateTime{Time: *entry.CacheExpiry}
+	}
+	if entry.GroupIDToken != "" {
+		info.GroupIdToken = &v201.IdToken{IdToken: entry.GroupIDToken, Type: v201.IdTokenType(entry.GroupIDTokenType)}
+	}
+	return idTokenKey{idToken: entry.IDToken, tokenType: v201.IdTokenType(entry.Type)}, info
+}
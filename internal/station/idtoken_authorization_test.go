@@ -0,0 +1,343 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func acceptedIdTokenInfo() *v201.IdTokenInfo {
+	return &v201.IdTokenInfo{Status: v201.AuthorizationStatusAccepted}
+}
+
+func TestIdTokenAuthorization_SendLocalList(t *testing.T) {
+	ia := NewIdTokenAuthorization("CP001", slog.Default())
+
+	rfid := v201.IdToken{IdToken: "TOKEN1", Type: v201.IdTokenTypeISO14443}
+	keyCode := v201.IdToken{IdToken: "TOKEN1", Type: v201.IdTokenTypeKeyCode}
+	emaid := v201.IdToken{IdToken: "NL-ABC-123", Type: v201.IdTokenTypeEMAID}
+
+	status := ia.SendLocalList(&v201.SendLocalListRequest{
+		VersionNumber: 1,
+		UpdateType:    v201.UpdateTypeFull,
+		LocalAuthorizationList: []v201.AuthorizationData{
+			{IdToken: rfid, IdTokenInfo: acceptedIdTokenInfo()},
+			{IdToken: emaid, IdTokenInfo: acceptedIdTokenInfo()},
+		},
+	})
+	if status != v201.SendLocalListStatusAccepted {
+		t.Fatalf("Expected Accepted for full update, got %s", status)
+	}
+
+	// The same value with another type is a different idToken
+	if _, found := ia.Lookup(keyCode); found {
+		t.Error("Expected KeyCode TOKEN1 not to match ISO14443 TOKEN1")
+	}
+
+	status = ia.SendLocalList(&v201.SendLocalListRequest{
+		VersionNumber: 2,
+		UpdateType:    v201.UpdateTypeDifferential,
+		LocalAuthorizationList: []v201.AuthorizationData{
+			{IdToken: rfid},
+			{IdToken: keyCode, IdTokenInfo: &v201.IdTokenInfo{Status: v201.AuthorizationStatusBlocked}},
+		},
+	})
+	if status != v201.SendLocalListStatusAccepted {
+		t.Fatalf("Expected Accepted for differential update, got %s", status)
+	}
+
+	if _, found := ia.Lookup(rfid); found {
+		t.Error("Expected ISO14443 TOKEN1 to be removed")
+	}
+	if info, found := ia.Lookup(keyCode); !found || info.Status != v201.AuthorizationStatusBlocked {
+		t.Errorf("Expected KeyCode TOKEN1 to be Blocked, got %+v", info)
+	}
+	if ia.ListVersion() != 2 || ia.Entries() != 2 {
+		t.Errorf("Expected version 2 with 2 entries, got version %d with %d entries", ia.ListVersion(), ia.Entries())
+	}
+
+	// A differential update must move the version forward
+	status = ia.SendLocalList(&v201.SendLocalListRequest{VersionNumber: 2, UpdateType: v201.UpdateTypeDifferential})
+	if status != v201.SendLocalListStatusVersionMismatch {
+		t.Errorf("Expected VersionMismatch, got %s", status)
+	}
+
+	// Unknown idToken types are rejected
+	status = ia.SendLocalList(&v201.SendLocalListRequest{
+		VersionNumber: 3,
+		UpdateType:    v201.UpdateTypeFull,
+		LocalAuthorizationList: []v201.AuthorizationData{
+			{IdToken: v201.IdToken{IdToken: "X", Type: "Barcode"}, IdTokenInfo: acceptedIdTokenInfo()},
+		},
+	})
+	if status != v201.SendLocalListStatusFailed {
+		t.Errorf("Expected Failed for unknown idToken type, got %s", status)
+	}
+
+	// A full update replaces the list
+	status = ia.SendLocalList(&v201.SendLocalListRequest{VersionNumber: 3, UpdateType: v201.UpdateTypeFull})
+	if status != v201.SendLocalListStatusAccepted || ia.Entries() != 0 {
+		t.Errorf("Expected empty list after full update, got %s with %d entries", status, ia.Entries())
+	}
+
+	// Without list the version is 0 and updates fail
+	config := ia.Config()
+	config.LocalAuthListEnabled = false
+	ia.SetConfig(config)
+
+	if ia.ListVersion() != 0 {
+		t.Errorf("Expected version 0 with disabled list, got %d", ia.ListVersion())
+	}
+	status = ia.SendLocalList(&v201.SendLocalListRequest{VersionNumber: 4, UpdateType: v201.UpdateTypeFull})
+	if status != v201.SendLocalListStatusFailed {
+		t.Errorf("Expected Failed with disabled list, got %s", status)
+	}
+}
+
+func TestIdTokenAuthorization_SendLocalListLimits(t *testing.T) {
+	ia := NewIdTokenAuthorization("CP001", slog.Default())
+
+	config := ia.Config()
+	config.ItemsPerMessage = 2
+	config.LocalAuthListMaxEntries = 3
+	ia.SetConfig(config)
+
+	entry := func(idToken string) v201.AuthorizationData {
+		return v201.AuthorizationData{
+			IdToken:     v201.IdToken{IdToken: idToken, Type: v201.IdTokenTypeCentral},
+			IdTokenInfo: acceptedIdTokenInfo(),
+		}
+	}
+
+	status := ia.SendLocalList(&v201.SendLocalListRequest{
+		VersionNumber:          1,
+		UpdateType:             v201.UpdateTypeFull,
+		LocalAuthorizationList: []v201.AuthorizationData{entry("A"), entry("B"), entry("C")},
+	})
+	if status != v201.SendLocalListStatusFailed {
+		t.Errorf("Expected Failed above ItemsPerMessage, got %s", status)
+	}
+
+	for version, list := range [][]v201.AuthorizationData{{entry("A"), entry("B")}, {entry("C")}} {
+		status = ia.SendLocalList(&v201.SendLocalListRequest{
+			VersionNumber:          version + 1,
+			UpdateType:             v201.UpdateTypeDifferential,
+			LocalAuthorizationList: list,
+		})
+		if status != v201.SendLocalListStatusAccepted {
+			t.Fatalf("Expected Accepted for version %d, got %s", version+1, status)
+		}
+	}
+
+	status = ia.SendLocalList(&v201.SendLocalListRequest{
+		VersionNumber:          3,
+		UpdateType:             v201.UpdateTypeDifferential,
+		LocalAuthorizationList: []v201.AuthorizationData{entry("D")},
+	})
+	if status != v201.SendLocalListStatusFailed || ia.ListVersion() != 2 {
+		t.Errorf("Expected Failed above the list capacity at version 2, got %s at version %d", status, ia.ListVersion())
+	}
+}
+
+func TestIdTokenAuthorization_Cache(t *testing.T) {
+	ia := NewIdTokenAuthorization("CP001", slog.Default())
+
+	config := ia.Config()
+	config.AuthCacheMaxEntries = 2
+	ia.SetConfig(config)
+
+	token := func(idToken string) v201.IdToken {
+		return v201.IdToken{IdToken: idToken, Type: v201.IdTokenTypeISO15693}
+	}
+
+	ia.UpdateCache(token("A"), *acceptedIdTokenInfo())
+	time.Sleep(time.Millisecond)
+	ia.UpdateCache(token("B"), *acceptedIdTokenInfo())
+	time.Sleep(time.Millisecond)
+
+	// Using A makes B the least recently used entry
+	if _, found := ia.Lookup(token("A")); !found {
+		t.Fatal("Expected A to be cached")
+	}
+	ia.UpdateCache(token("C"), *acceptedIdTokenInfo())
+
+	if _, found := ia.Lookup(token("B")); found {
+		t.Error("Expected B to be evicted")
+	}
+	if ia.CacheSize() != 2 {
+		t.Errorf("Expected 2 cached idTokens, got %d", ia.CacheSize())
+	}
+
+	// Entries expire with their cacheExpiryDateTime
+	expired := v201.IdTokenInfo{
+		Status:              v201.AuthorizationStatusAccepted,
+		CacheExpiryDateTime: &v201.DateTime{Time: time.Now().Add(-time.Minute)},
+	}
+	ia.UpdateCache(token("D"), expired)
+	if _, found := ia.Lookup(token("D")); found {
+		t.Error("Expected expired entry not to be found")
+	}
+
+	// Entries expire after AuthCacheCtrlr.LifeTime
+	config.AuthCacheLifeTime = time.Millisecond
+	ia.SetConfig(config)
+	ia.UpdateCache(token("E"), *acceptedIdTokenInfo())
+	time.Sleep(5 * time.Millisecond)
+	if _, found := ia.Lookup(token("E")); found {
+		t.Error("Expected entry to expire after its lifetime")
+	}
+
+	if !ia.ClearCache() || ia.CacheSize() != 0 {
+		t.Errorf("Expected cleared cache, got %d entries", ia.CacheSize())
+	}
+
+	config.AuthCacheEnabled = false
+	ia.SetConfig(config)
+	if ia.ClearCache() {
+		t.Error("Expected ClearCache to fail with disabled cache")
+	}
+}
+
+func TestIdTokenAuthConfigFromDeviceModel(t *testing.T) {
+	dm := v201.NewDeviceModel()
+
+	config := IdTokenAuthConfigFromDeviceModel(dm)
+	if config != DefaultIdTokenAuthConfig() {
+		t.Errorf("Expected defaults for a new device model, got %+v", config)
+	}
+
+	for _, v := range []struct{ component, variable, value string }{
+		{"AuthCtrlr", "LocalPreAuthorize", "true"},
+		{"AuthCtrlr", "OfflineTxForUnknownIdEnabled", "true"},
+		{"LocalAuthListCtrlr", "Enabled", "false"},
+		{"AuthCacheCtrlr", "LifeTime", "60"},
+	} {
+		if status := dm.SetVariable(v.component, "", v.variable, "", v201.AttributeActual, v.value); status != v201.SetVariableStatusAccepted {
+			t.Fatalf("Failed to set %s.%s: %s", v.component, v.variable, status)
+		}
+	}
+
+	config = IdTokenAuthConfigFromDeviceModel(dm)
+	if !config.LocalPreAuthorize || !config.OfflineTxForUnknownIdEnabled || config.LocalAuthListEnabled || config.AuthCacheLifeTime != time.Minute {
+		t.Errorf("Expected device model values to be applied, got %+v", config)
+	}
+}
+
+func TestSessionManager_AuthorizeIdToken(t *testing.T) {
+	sm := NewSessionManager("CP001", nil, slog.Default())
+	sm.SetProtocolVersion("ocpp2.0.1")
+
+	var requests []v201.IdToken
+	online := true
+	sm.SendAuthorizeIdToken = func(idToken v201.IdToken) (*v201.AuthorizeResponse, error) {
+		requests = append(requests, idToken)
+		if !online {
+			return nil, errors.New("not connected")
+		}
+		return &v201.AuthorizeResponse{IdTokenInfo: v201.IdTokenInfo{
+			Status:       v201.AuthorizationStatusAccepted,
+			GroupIdToken: &v201.IdToken{IdToken: "FLEET", Type: v201.IdTokenTypeCentral},
+		}}, nil
+	}
+
+	emaid := v201.IdToken{IdToken: "NL-ABC-123", Type: v201.IdTokenTypeEMAID}
+	info, err := sm.AuthorizeIdToken(emaid)
+	if err != nil || info.Status != v201.AuthorizationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %+v (%v)", info, err)
+	}
+	if len(requests) != 1 || requests[0].IdToken != emaid.IdToken || requests[0].Type != emaid.Type {
+		t.Errorf("Expected the typed idToken to be sent, got %+v", requests)
+	}
+
+	// The groupIdToken becomes the parent ID tag of the session
+	idTagInfo, err := sm.Authorize("NL-ABC-123")
+	if err != nil || idTagInfo.ParentIdTag != "FLEET" {
+		t.Errorf("Expected parent ID tag FLEET, got %+v (%v)", idTagInfo, err)
+	}
+
+	// Offline, the cached idToken is still accepted and unknown ones are not
+	online = false
+	if info, _ := sm.AuthorizeIdToken(emaid); info.Status != v201.AuthorizationStatusAccepted {
+		t.Errorf("Expected cached idToken to be accepted offline, got %s", info.Status)
+	}
+	if info, _ := sm.AuthorizeIdToken(v201.IdToken{IdToken: "UNKNOWN", Type: v201.IdTokenTypeKeyCode}); info.Status != v201.AuthorizationStatusUnknown {
+		t.Errorf("Expected unknown idToken to be Unknown offline, got %s", info.Status)
+	}
+
+	// NoAuthorization idTokens are never sent to the CSMS
+	sent := len(requests)
+	if info, _ := sm.AuthorizeIdToken(v201.IdToken{Type: v201.IdTokenTypeNoAuthorization}); info.Status != v201.AuthorizationStatusAccepted {
+		t.Errorf("Expected NoAuthorization to be accepted, got %s", info.Status)
+	}
+	if len(requests) != sent {
+		t.Error("Expected no Authorize for NoAuthorization")
+	}
+
+	if _, err := sm.AuthorizeIdToken(v201.IdToken{IdToken: "X", Type: "Barcode"}); err == nil {
+		t.Error("Expected an error for an unknown idToken type")
+	}
+}
+
+func TestHandleV201SendLocalList(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	deviceModel := v201.NewDeviceModel()
+	station := &Station{
+		Config:         Config{StationID: "TEST018", ProtocolVersion: "ocpp2.0.1"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: sm,
+		DeviceModel:    deviceModel,
+	}
+	manager.mu.Lock()
+	manager.stations["TEST018"] = station
+	manager.mu.Unlock()
+
+	resp, _ := manager.v201Handler.OnSendLocalList("TEST018", &v201.SendLocalListRequest{
+		VersionNumber: 5,
+		UpdateType:    v201.UpdateTypeFull,
+		LocalAuthorizationList: []v201.AuthorizationData{
+			{IdToken: v201.IdToken{IdToken: "TAG1", Type: v201.IdTokenTypeISO14443}, IdTokenInfo: acceptedIdTokenInfo()},
+		},
+	})
+	if resp.Status != v201.SendLocalListStatusAccepted {
+		t.Fatalf("Expected Accepted, got %+v", resp)
+	}
+
+	if entries, _ := deviceModel.GetVariable("LocalAuthListCtrlr", "", "Entries", "", v201.AttributeActual); entries != "1" {
+		t.Errorf("Expected LocalAuthListCtrlr.Entries 1, got %s", entries)
+	}
+
+	version, _ := manager.v201Handler.OnGetLocalListVersion("TEST018", &v201.GetLocalListVersionRequest{})
+	if version.VersionNumber != 5 {
+		t.Errorf("Expected version 5, got %d", version.VersionNumber)
+	}
+
+	// OCPP 2.1 uses the same list
+	version21, _ := manager.v21Handler.OnGetLocalListVersion("TEST018", &v21.GetLocalListVersionRequest{})
+	if version21.VersionNumber != 5 {
+		t.Errorf("Expected version 5 for OCPP 2.1, got %d", version21.VersionNumber)
+	}
+
+	// Disabling the cache with SetVariables makes ClearCache fail
+	manager.v201Handler.OnSetVariables("TEST018", &v201.SetVariablesRequest{
+		SetVariableData: []v201.SetVariableData{{
+			Component:      v201.Component{Name: "AuthCacheCtrlr"},
+			Variable:       v201.Variable{Name: "Enabled"},
+			AttributeValue: "false",
+		}},
+	})
+	clearResp, _ := manager.v201Handler.OnClearCache("TEST018", &v201.ClearCacheRequest{})
+	if clearResp.Status != "Rejected" {
+		t.Errorf("Expected ClearCache to be Rejected with disabled cache, got %s", clearResp.Status)
+	}
+}
@@ -2,10 +2,15 @@ package station
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,6 +40,7 @@ type Manager struct {
 	v16Handler    *v16.Handler  // OCPP 1.6 message handler
 	v201Handler   *v201.Handler // OCPP 2.0.1 message handler
 	v21Handler    *v21.Handler  // OCPP 2.1 message handler
+	callTimeout   time.Duration // How long a Call waits for the CSMS response
 }
 
 // Station represents a managed charging station instance
@@ -45,6 +51,11 @@ type Station struct {
 	SessionManager   *SessionManager        // Enhanced session manager for charging
 	DeviceModel      *v201.DeviceModel      // OCPP 2.0.1 device model
 	CertificateStore *v201.CertificateStore // ISO 15118 certificate management
+	Firmware         *FirmwareManager       // OCPP 1.6 firmware update and diagnostics simulation
+	Security         *SecurityManager       // OCPP 1.6 security extensions
+	Configuration    *ConfigurationStore    // OCPP 1.6 configuration keys
+	MessageQueue     *MessageQueue          // Transaction messages awaiting delivery
+	DisplayMessages  *DisplayMessageStore   // OCPP 2.0.1 display messages and running cost
 	mu               sync.RWMutex
 	lastSync         time.Time
 
@@ -52,37 +63,83 @@ type Station struct {
 	heartbeatCancel context.CancelFunc
 	heartbeatDone   chan struct{}
 
-	// Pending requests tracking (message ID -> action)
-	pendingRequests map[string]string
-	pendingMu       sync.RWMutex
+	// Periodic variable monitors of OCPP 2.0.1/2.1 stations
+	monitoringCancel context.CancelFunc
+	monitoringDone   chan struct{}
 
-	// Pending StartTransaction tracking (message ID -> {connector ID, idTag})
-	// Used to update the correct connector when CSMS responds with transaction ID
-	pendingStartTx   map[string]int
-	pendingStartTags map[string]string
-	pendingStartMu   sync.RWMutex
-
-	// Pending Authorize tracking (message ID -> response channel)
-	// Used to wait for actual CSMS response
-	pendingAuthResp   map[string]chan *v16.AuthorizeResponse
-	pendingAuthRespMu sync.RWMutex
+	// Calls sent to the CSMS awaiting their response, created on first use
+	requests *ocpp.RequestTracker
 
 	// Failed authorizations tracking (idTag -> timestamp)
 	// Used to reject transactions for recently rejected ID tags
 	failedAuths   map[string]time.Time
 	failedAuthsMu sync.RWMutex
+
+	// Calls from the CSMS are handled one at a time, so the actions scheduled while
+	// handling a Call run once the response to that Call has been sent
+	callMu          sync.Mutex
+	afterResponse   []func()
+	afterResponseMu sync.Mutex
+
+	// Reset handling
+	bootReason     v201.BootReasonType // Reason reported by the next 2.0.1 BootNotification
+	resetScheduled bool                // An OnIdle reset waits for the transactions to finish
 }
 
 // GetData returns a thread-safe copy of the station's config and runtime state
 func (s *Station) GetData() (Config, RuntimeState) {
+	s.mu.RLock()
+	config, runtimeState := s.Config, s.RuntimeState
+	s.mu.RUnlock()
+
+	if s.MessageQueue != nil {
+		runtimeState.QueuedMessages = s.MessageQueue.Len()
+	}
+	if s.DisplayMessages != nil {
+		runtimeState.DisplayedMessage = s.DisplayMessages.Displayed(s.displayState())
+		runtimeState.RunningCost = s.DisplayMessages.RunningCost()
+	}
+	return config, runtimeState
+}
+
+// usesTransactionEvents reports whether the station speaks OCPP 2.0.1 or 2.1
+func (s *Station) usesTransactionEvents() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.Config, s.RuntimeState
+	return usesTransactionEvents(s.Config.ProtocolVersion)
+}
+
+// displayState returns the state of the station that selects the display messages to show
+func (s *Station) displayState() v201.MessageStateType {
+	if s.SessionManager == nil {
+		return v201.MessageStateIdle
+	}
+
+	connectors := s.SessionManager.GetAllConnectors()
+	unavailable := 0
+	for _, connector := range connectors {
+		if connector.HasActiveTransaction() {
+			return v201.MessageStateCharging
+		}
+	}
+	for _, connector := range connectors {
+		switch connector.GetState() {
+		case ConnectorStateFaulted:
+			return v201.MessageStateFaulted
+		case ConnectorStateUnavailable:
+			unavailable++
+		}
+	}
+	if len(connectors) > 0 && unavailable == len(connectors) {
+		return v201.MessageStateUnavailable
+	}
+	return v201.MessageStateIdle
 }
 
 // ManagerConfig represents the manager configuration
 type ManagerConfig struct {
 	SyncInterval time.Duration // How often to sync state to MongoDB
+	CallTimeout  time.Duration // How long a Call waits for the CSMS response
 }
 
 // NewManager creates a new station manager
@@ -98,6 +155,9 @@ func NewManager(
 	if config.SyncInterval == 0 {
 		config.SyncInterval = 30 * time.Second
 	}
+	if config.CallTimeout == 0 {
+		config.CallTimeout = ocpp.DefaultCallTimeout
+	}
 
 	m := &Manager{
 		stations:      make(map[string]*Station),
@@ -108,21 +168,22 @@ func NewManager(
 		ctx:           ctx,
 		cancel:        cancel,
 		syncInterval:  config.SyncInterval,
+		callTimeout:   config.CallTimeout,
 	}
 
 	// Initialize OCPP 1.6 handler
 	m.v16Handler = v16.NewHandler(logger)
-	m.v16Handler.SendMessage = connManager.SendMessage
+	m.v16Handler.SendMessage = m.sendMessage
 	m.setupV16HandlerCallbacks()
 
 	// Initialize OCPP 2.0.1 handler
 	m.v201Handler = v201.NewHandler(logger)
-	m.v201Handler.SendMessage = connManager.SendMessage
+	m.v201Handler.SendMessage = m.sendMessage
 	m.setupV201HandlerCallbacks()
 
 	// Initialize OCPP 2.1 handler
 	m.v21Handler = v21.NewHandler(logger)
-	m.v21Handler.SendMessage = connManager.SendMessage
+	m.v21Handler.SendMessage = m.sendMessage
 	m.setupV21HandlerCallbacks()
 
 	return m
@@ -149,6 +210,15 @@ func (m *Manager) setupV16HandlerCallbacks() {
 			connectorID = *req.ConnectorId
 		}
 
+		// A charging profile sent with RemoteStartTransaction must be a TxProfile
+		if req.ChargingProfile != nil && req.ChargingProfile.ChargingProfilePurpose != v16.ChargingProfilePurposeTxProfile {
+			m.logger.Warn("Rejecting RemoteStartTransaction with non-TxProfile charging profile",
+				"stationId", stationID,
+				"purpose", req.ChargingProfile.ChargingProfilePurpose,
+			)
+			return &v16.RemoteStartTransactionResponse{Status: "Rejected"}, nil
+		}
+
 		// Start charging session
 		_, err := station.SessionManager.StartCharging(connectorID, req.IdTag)
 		if err != nil {
@@ -156,6 +226,19 @@ func (m *Manager) setupV16HandlerCallbacks() {
 			return &v16.RemoteStartTransactionResponse{Status: "Rejected"}, nil
 		}
 
+		// Bind the provided TxProfile to the new transaction
+		if req.ChargingProfile != nil {
+			profile := *req.ChargingProfile
+			profile.TransactionId = nil
+			if err := station.SessionManager.SetChargingProfile(connectorID, profile); err != nil {
+				m.logger.Warn("Failed to install charging profile from RemoteStartTransaction",
+					"stationId", stationID,
+					"connectorId", connectorID,
+					"error", err,
+				)
+			}
+		}
+
 		return &v16.RemoteStartTransactionResponse{
 			Status: "Accepted",
 		}, nil
@@ -209,11 +292,23 @@ func (m *Manager) setupV16HandlerCallbacks() {
 	m.v16Handler.OnReset = func(stationID string, req *v16.ResetRequest) (*v16.ResetResponse, error) {
 		m.logger.Info("Handling Reset", "stationId", stationID, "type", req.Type)
 
-		// TODO: Implement actual reset logic
-		// For now, accept the request
-		return &v16.ResetResponse{
-			Status: "Accepted",
-		}, nil
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || (req.Type != "Hard" && req.Type != "Soft") {
+			return &v16.ResetResponse{Status: "Rejected"}, nil
+		}
+
+		// Reset once the response has been sent
+		if req.Type == "Hard" {
+			m.afterResponse(station, func() { m.resetStation(stationID, true, v16.ReasonHardReset) })
+		} else {
+			m.afterResponse(station, func() { m.resetStation(stationID, false, v16.ReasonSoftReset) })
+		}
+
+		return &v16.ResetResponse{Status: "Accepted"}, nil
 	}
 
 	// UnlockConnector handler
@@ -257,31 +352,104 @@ func (m *Manager) setupV16HandlerCallbacks() {
 	m.v16Handler.OnChangeConfiguration = func(stationID string, req *v16.ChangeConfigurationRequest) (*v16.ChangeConfigurationResponse, error) {
 		m.logger.Info("Handling ChangeConfiguration", "stationId", stationID, "key", req.Key, "value", req.Value)
 
-		// TODO: Implement actual configuration change logic
-		// For now, return not supported
-		return &v16.ChangeConfigurationResponse{
-			Status: "NotSupported",
-		}, nil
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists {
+			return &v16.ChangeConfigurationResponse{Status: v16.ConfigurationStatusNotSupported}, nil
+		}
+
+		// Security keys may require reconnecting with the new settings
+		previousProfile := station.Security.Profile()
+		handled, err := station.Security.ChangeConfiguration(req.Key, req.Value)
+		if handled {
+			if err != nil {
+				m.logger.Warn("Configuration change rejected", "stationId", stationID, "key", req.Key, "error", err)
+				return &v16.ChangeConfigurationResponse{Status: v16.ConfigurationStatusRejected}, nil
+			}
+
+			if req.Key == ConfigKeySecurityProfile || req.Key == ConfigKeyAuthorizationKey {
+				m.applySecurityProfile(station, station.Security.Profile())
+				station.Security.AddSecurityEvent(v16.SecurityEventReconfigurationOfSecurityParameters, req.Key)
+				m.afterResponse(station, func() { m.reconnectStation(stationID, previousProfile) })
+			}
+
+			return &v16.ChangeConfigurationResponse{Status: v16.ConfigurationStatusAccepted}, nil
+		}
+
+		status, err := station.Configuration.Change(req.Key, req.Value)
+		if err != nil {
+			m.logger.Warn("Configuration change rejected", "stationId", stationID, "key", req.Key, "status", status, "error", err)
+		}
+
+		return &v16.ChangeConfigurationResponse{Status: status}, nil
 	}
 
 	// GetConfiguration handler
 	m.v16Handler.OnGetConfiguration = func(stationID string, req *v16.GetConfigurationRequest) (*v16.GetConfigurationResponse, error) {
 		m.logger.Info("Handling GetConfiguration", "stationId", stationID, "keys", req.Key)
 
-		// TODO: Implement actual configuration retrieval logic
-		// For now, return empty response
-		return &v16.GetConfigurationResponse{
-			ConfigurationKey: []v16.KeyValue{},
-			UnknownKey:       req.Key,
-		}, nil
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists {
+			return &v16.GetConfigurationResponse{
+				ConfigurationKey: []v16.KeyValue{},
+				UnknownKey:       req.Key,
+			}, nil
+		}
+
+		resp := &v16.GetConfigurationResponse{}
+		var unknown []string
+		resp.ConfigurationKey, unknown = station.Configuration.Get(req.Key)
+
+		// Security keys are held by the security manager
+		securityKeys := station.Security.ConfigurationKeys()
+		if len(req.Key) == 0 {
+			resp.ConfigurationKey = append(resp.ConfigurationKey, securityKeys...)
+			return resp, nil
+		}
+
+		for _, key := range unknown {
+			found := false
+			for _, kv := range securityKeys {
+				if kv.Key == key {
+					resp.ConfigurationKey = append(resp.ConfigurationKey, kv)
+					found = true
+					break
+				}
+			}
+			if !found {
+				resp.UnknownKey = append(resp.UnknownKey, key)
+			}
+		}
+
+		return resp, nil
 	}
 
 	// ClearCache handler
 	m.v16Handler.OnClearCache = func(stationID string, req *v16.ClearCacheRequest) (*v16.ClearCacheResponse, error) {
 		m.logger.Info("Handling ClearCache", "stationId", stationID)
 
-		// TODO: Implement actual cache clear logic
-		// For now, accept the request
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.SessionManager == nil {
+			return &v16.ClearCacheResponse{Status: "Rejected"}, nil
+		}
+
+		if !station.SessionManager.Authorization().Config().AuthorizationCacheEnabled {
+			return &v16.ClearCacheResponse{Status: "Rejected"}, nil
+		}
+
+		station.SessionManager.Authorization().ClearCache()
+
 		return &v16.ClearCacheResponse{
 			Status: "Accepted",
 		}, nil
@@ -297,216 +465,202 @@ func (m *Manager) setupV16HandlerCallbacks() {
 			Status: "UnknownVendorId",
 		}, nil
 	}
-}
 
-// setupV201HandlerCallbacks sets up callbacks for OCPP 2.0.1 handler
-func (m *Manager) setupV201HandlerCallbacks() {
-	// RequestStartTransaction handler (replaces RemoteStartTransaction in 2.0.1)
-	m.v201Handler.OnRequestStartTransaction = func(stationID string, req *v201.RequestStartTransactionRequest) (*v201.RequestStartTransactionResponse, error) {
-		m.logger.Info("Handling RequestStartTransaction (2.0.1)", "stationId", stationID, "idToken", req.IdToken.IdToken)
+	// SetChargingProfile handler
+	m.v16Handler.OnSetChargingProfile = func(stationID string, req *v16.SetChargingProfileRequest) (*v16.SetChargingProfileResponse, error) {
+		m.logger.Info("Handling SetChargingProfile",
+			"stationId", stationID,
+			"connectorId", req.ConnectorId,
+			"profileId", req.CsChargingProfiles.ChargingProfileId,
+			"purpose", req.CsChargingProfiles.ChargingProfilePurpose,
+			"stackLevel", req.CsChargingProfiles.StackLevel,
+		)
 
 		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists {
-			return &v201.RequestStartTransactionResponse{Status: "Rejected"}, nil
-		}
-
-		// Determine EVSE/connector ID
-		connectorID := 1
-		if req.EvseId != nil {
-			connectorID = *req.EvseId
+		if !exists || station.SessionManager == nil {
+			return &v16.SetChargingProfileResponse{Status: v16.ChargingProfileStatusRejected}, nil
 		}
 
-		// Start charging session
-		_, err := station.SessionManager.StartCharging(connectorID, req.IdToken.IdToken)
-		if err != nil {
-			m.logger.Error("Failed to start charging", "error", err)
-			return &v201.RequestStartTransactionResponse{Status: "Rejected"}, nil
+		if err := station.SessionManager.SetChargingProfile(req.ConnectorId, req.CsChargingProfiles); err != nil {
+			m.logger.Warn("Charging profile rejected", "stationId", stationID, "error", err)
+			return &v16.SetChargingProfileResponse{Status: v16.ChargingProfileStatusRejected}, nil
 		}
 
-		return &v201.RequestStartTransactionResponse{
-			Status: "Accepted",
-		}, nil
+		return &v16.SetChargingProfileResponse{Status: v16.ChargingProfileStatusAccepted}, nil
 	}
 
-	// RequestStopTransaction handler (replaces RemoteStopTransaction in 2.0.1)
-	m.v201Handler.OnRequestStopTransaction = func(stationID string, req *v201.RequestStopTransactionRequest) (*v201.RequestStopTransactionResponse, error) {
-		m.logger.Info("Handling RequestStopTransaction (2.0.1)", "stationId", stationID, "transactionId", req.TransactionId)
+	// ClearChargingProfile handler
+	m.v16Handler.OnClearChargingProfile = func(stationID string, req *v16.ClearChargingProfileRequest) (*v16.ClearChargingProfileResponse, error) {
+		m.logger.Info("Handling ClearChargingProfile",
+			"stationId", stationID,
+			"id", req.Id,
+			"connectorId", req.ConnectorId,
+			"purpose", req.ChargingProfilePurpose,
+			"stackLevel", req.StackLevel,
+		)
 
 		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists {
-			return &v201.RequestStopTransactionResponse{Status: "Rejected"}, nil
+		if !exists || station.SessionManager == nil {
+			return &v16.ClearChargingProfileResponse{Status: v16.ClearChargingProfileStatusUnknown}, nil
 		}
 
-		// Find connector with this transaction
-		connectors := station.SessionManager.GetAllConnectors()
-		var targetConnectorID int
-		found := false
-
-		for _, connector := range connectors {
-			tx := connector.GetTransaction()
-			if tx != nil && tx.StringID == req.TransactionId {
-				targetConnectorID = connector.ID
-				found = true
-				break
-			}
+		removed := station.SessionManager.ChargingProfiles().ClearProfiles(req.Id, req.ConnectorId, req.ChargingProfilePurpose, req.StackLevel)
+		if removed == 0 {
+			return &v16.ClearChargingProfileResponse{Status: v16.ClearChargingProfileStatusUnknown}, nil
 		}
 
-		if !found {
-			m.logger.Warn("Transaction not found", "transactionId", req.TransactionId)
-			return &v201.RequestStopTransactionResponse{Status: "Rejected"}, nil
+		m.logger.Info("Cleared charging profiles", "stationId", stationID, "count", removed)
+		return &v16.ClearChargingProfileResponse{Status: v16.ClearChargingProfileStatusAccepted}, nil
+	}
+
+	// GetCompositeSchedule handler
+	m.v16Handler.OnGetCompositeSchedule = func(stationID string, req *v16.GetCompositeScheduleRequest) (*v16.GetCompositeScheduleResponse, error) {
+		m.logger.Info("Handling GetCompositeSchedule",
+			"stationId", stationID,
+			"connectorId", req.ConnectorId,
+			"duration", req.Duration,
+			"unit", req.ChargingRateUnit,
+		)
+
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.SessionManager == nil || req.Duration <= 0 {
+			return &v16.GetCompositeScheduleResponse{Status: v16.GetCompositeScheduleStatusRejected}, nil
 		}
 
-		// Stop charging session
-		err := station.SessionManager.StopCharging(targetConnectorID, v16.ReasonRemote)
+		schedule, err := station.SessionManager.GetCompositeSchedule(req.ConnectorId, req.Duration, req.ChargingRateUnit)
 		if err != nil {
-			m.logger.Error("Failed to stop charging", "error", err)
-			return &v201.RequestStopTransactionResponse{Status: "Rejected"}, nil
+			m.logger.Warn("Cannot calculate composite schedule", "stationId", stationID, "error", err)
+			return &v16.GetCompositeScheduleResponse{Status: v16.GetCompositeScheduleStatusRejected}, nil
 		}
 
-		return &v201.RequestStopTransactionResponse{
-			Status: "Accepted",
+		connectorID := req.ConnectorId
+		return &v16.GetCompositeScheduleResponse{
+			Status:           v16.GetCompositeScheduleStatusAccepted,
+			ConnectorId:      &connectorID,
+			ScheduleStart:    schedule.StartSchedule,
+			ChargingSchedule: schedule,
 		}, nil
 	}
 
-	// Reset handler
-	m.v201Handler.OnReset = func(stationID string, req *v201.ResetRequest) (*v201.ResetResponse, error) {
-		m.logger.Info("Handling Reset (2.0.1)", "stationId", stationID, "type", req.Type)
+	// ReserveNow handler
+	m.v16Handler.OnReserveNow = func(stationID string, req *v16.ReserveNowRequest) (*v16.ReserveNowResponse, error) {
+		m.logger.Info("Handling ReserveNow",
+			"stationId", stationID,
+			"connectorId", req.ConnectorId,
+			"reservationId", req.ReservationId,
+			"idTag", req.IdTag,
+			"expiryDate", req.ExpiryDate,
+		)
 
-		// TODO: Implement actual reset logic
-		return &v201.ResetResponse{
-			Status: v201.ResetStatusAccepted,
-		}, nil
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.SessionManager == nil {
+			return &v16.ReserveNowResponse{Status: v16.ReservationStatusRejected}, nil
+		}
+
+		status := station.SessionManager.ReserveNow(req.ConnectorId, req.ReservationId, req.IdTag, req.ParentIdTag, req.ExpiryDate.Time)
+		return &v16.ReserveNowResponse{Status: status}, nil
 	}
 
-	// GetVariables handler - uses device model
-	m.v201Handler.OnGetVariables = func(stationID string, req *v201.GetVariablesRequest) (*v201.GetVariablesResponse, error) {
-		m.logger.Info("Handling GetVariables (2.0.1)", "stationId", stationID, "count", len(req.GetVariableData))
+	// CancelReservation handler
+	m.v16Handler.OnCancelReservation = func(stationID string, req *v16.CancelReservationRequest) (*v16.CancelReservationResponse, error) {
+		m.logger.Info("Handling CancelReservation", "stationId", stationID, "reservationId", req.ReservationId)
 
-		// Get station to access device model
+		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists || station.DeviceModel == nil {
-			// Return rejected for all if station or device model not found
-			results := make([]v201.GetVariableResult, len(req.GetVariableData))
-			for i, data := range req.GetVariableData {
-				results[i] = v201.GetVariableResult{
-					AttributeStatus: v201.GetVariableStatusRejected,
-					Component:       data.Component,
-					Variable:        data.Variable,
-				}
-			}
-			return &v201.GetVariablesResponse{GetVariableResult: results}, nil
+		if !exists || station.SessionManager == nil {
+			return &v16.CancelReservationResponse{Status: v16.CancelReservationStatusRejected}, nil
 		}
 
-		// Query device model for each requested variable
-		results := make([]v201.GetVariableResult, len(req.GetVariableData))
-		for i, data := range req.GetVariableData {
-			// Determine attribute type (default to Actual)
-			attrType := v201.AttributeActual
-			if data.AttributeType != nil {
-				attrType = *data.AttributeType
-			}
+		if err := station.SessionManager.CancelReservation(req.ReservationId); err != nil {
+			m.logger.Warn("Cannot cancel reservation", "stationId", stationID, "error", err)
+			return &v16.CancelReservationResponse{Status: v16.CancelReservationStatusRejected}, nil
+		}
 
-			// Get variable from device model
-			value, status := station.DeviceModel.GetVariable(
-				data.Component.Name,
-				data.Component.Instance,
-				data.Variable.Name,
-				data.Variable.Instance,
-				attrType,
-			)
+		return &v16.CancelReservationResponse{Status: v16.CancelReservationStatusAccepted}, nil
+	}
 
-			attrTypeCopy := attrType
-			results[i] = v201.GetVariableResult{
-				AttributeStatus: status,
-				AttributeType:   &attrTypeCopy,
-				AttributeValue:  value,
-				Component:       data.Component,
-				Variable:        data.Variable,
-			}
+	// SendLocalList handler
+	m.v16Handler.OnSendLocalList = func(stationID string, req *v16.SendLocalListRequest) (*v16.SendLocalListResponse, error) {
+		m.logger.Info("Handling SendLocalList",
+			"stationId", stationID,
+			"listVersion", req.ListVersion,
+			"updateType", req.UpdateType,
+			"entries", len(req.LocalAuthorizationList),
+		)
 
-			m.logger.Debug("GetVariable result",
-				"component", data.Component.Name,
-				"variable", data.Variable.Name,
-				"status", status,
-				"value", value,
-			)
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.SessionManager == nil {
+			return &v16.SendLocalListResponse{Status: v16.UpdateStatusFailed}, nil
 		}
-		return &v201.GetVariablesResponse{GetVariableResult: results}, nil
+
+		status := station.SessionManager.Authorization().SendLocalList(req)
+		return &v16.SendLocalListResponse{Status: status}, nil
 	}
 
-	// SetVariables handler - uses device model
-	m.v201Handler.OnSetVariables = func(stationID string, req *v201.SetVariablesRequest) (*v201.SetVariablesResponse, error) {
-		m.logger.Info("Handling SetVariables (2.0.1)", "stationId", stationID, "count", len(req.SetVariableData))
+	// GetLocalListVersion handler
+	m.v16Handler.OnGetLocalListVersion = func(stationID string, req *v16.GetLocalListVersionRequest) (*v16.GetLocalListVersionResponse, error) {
+		m.logger.Info("Handling GetLocalListVersion", "stationId", stationID)
 
-		// Get station to access device model
+		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists || station.DeviceModel == nil {
-			// Return rejected for all if station or device model not found
-			results := make([]v201.SetVariableResult, len(req.SetVariableData))
-			for i, data := range req.SetVariableData {
-				results[i] = v201.SetVariableResult{
-					AttributeStatus: v201.SetVariableStatusRejected,
-					Component:       data.Component,
-					Variable:        data.Variable,
-				}
-			}
-			return &v201.SetVariablesResponse{SetVariableResult: results}, nil
+		if !exists || station.SessionManager == nil {
+			return &v16.GetLocalListVersionResponse{ListVersion: -1}, nil
 		}
 
-		// Set each variable in device model
-		results := make([]v201.SetVariableResult, len(req.SetVariableData))
-		for i, data := range req.SetVariableData {
-			// Determine attribute type (default to Actual)
-			attrType := v201.AttributeActual
-			if data.AttributeType != nil {
-				attrType = *data.AttributeType
-			}
+		return &v16.GetLocalListVersionResponse{
+			ListVersion: station.SessionManager.Authorization().ListVersion(),
+		}, nil
+	}
 
-			// Set variable in device model
-			status := station.DeviceModel.SetVariable(
-				data.Component.Name,
-				data.Component.Instance,
-				data.Variable.Name,
-				data.Variable.Instance,
-				attrType,
-				data.AttributeValue,
-			)
+	// UpdateFirmware handler
+	m.v16Handler.OnUpdateFirmware = func(stationID string, req *v16.UpdateFirmwareRequest) (*v16.UpdateFirmwareResponse, error) {
+		m.logger.Info("Handling UpdateFirmware",
+			"stationId", stationID,
+			"location", req.Location,
+			"retrieveDate", req.RetrieveDate.Time,
+		)
 
-			attrTypeCopy := attrType
-			results[i] = v201.SetVariableResult{
-				AttributeStatus: status,
-				AttributeType:   &attrTypeCopy,
-				Component:       data.Component,
-				Variable:        data.Variable,
-			}
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
 
-			m.logger.Debug("SetVariable result",
-				"component", data.Component.Name,
-				"variable", data.Variable.Name,
-				"status", status,
-				"value", data.AttributeValue,
-			)
+		if exists {
+			station.Firmware.UpdateFirmware(req.Location, req.RetrieveDate.Time, req.Retries, req.RetryInterval)
 		}
-		return &v201.SetVariablesResponse{SetVariableResult: results}, nil
+
+		return &v16.UpdateFirmwareResponse{}, nil
 	}
 
-	// ChangeAvailability handler
-	m.v201Handler.OnChangeAvailability = func(stationID string, req *v201.ChangeAvailabilityRequest) (*v201.ChangeAvailabilityResponse, error) {
-		m.logger.Info("Handling ChangeAvailability (2.0.1)", "stationId", stationID, "status", req.OperationalStatus)
+	// GetDiagnostics handler
+	m.v16Handler.OnGetDiagnostics = func(stationID string, req *v16.GetDiagnosticsRequest) (*v16.GetDiagnosticsResponse, error) {
+		m.logger.Info("Handling GetDiagnostics", "stationId", stationID, "location", req.Location)
 
 		// Get station
 		m.mu.RLock()
@@ -514,58 +668,20 @@ func (m *Manager) setupV201HandlerCallbacks() {
 		m.mu.RUnlock()
 
 		if !exists {
-			return &v201.ChangeAvailabilityResponse{Status: "Rejected"}, nil
-		}
-
-		// Determine which connector to change (0 means all)
-		connectorID := 0
-		if req.EVSE != nil {
-			connectorID = req.EVSE.ID
-		}
-
-		// Map OCPP 2.0.1 status to 1.6 availability type
-		availType := "Operative"
-		if req.OperationalStatus == "Inoperative" {
-			availType = "Inoperative"
-		}
-
-		// Change availability
-		err := station.SessionManager.ChangeAvailability(connectorID, availType)
-		if err != nil {
-			m.logger.Warn("Cannot change availability immediately", "error", err)
-			return &v201.ChangeAvailabilityResponse{Status: "Scheduled"}, nil
+			return &v16.GetDiagnosticsResponse{}, nil
 		}
 
-		return &v201.ChangeAvailabilityResponse{Status: "Accepted"}, nil
-	}
-
-	// UnlockConnector handler
-	m.v201Handler.OnUnlockConnector = func(stationID string, req *v201.UnlockConnectorRequest) (*v201.UnlockConnectorResponse, error) {
-		m.logger.Info("Handling UnlockConnector (2.0.1)", "stationId", stationID, "evseId", req.EvseId, "connectorId", req.ConnectorId)
-
-		// TODO: Implement actual unlock logic
-		return &v201.UnlockConnectorResponse{Status: "UnknownConnector"}, nil
-	}
-
-	// ClearCache handler
-	m.v201Handler.OnClearCache = func(stationID string, req *v201.ClearCacheRequest) (*v201.ClearCacheResponse, error) {
-		m.logger.Info("Handling ClearCache (2.0.1)", "stationId", stationID)
-
-		// TODO: Implement actual cache clear logic
-		return &v201.ClearCacheResponse{Status: "Accepted"}, nil
-	}
-
-	// DataTransfer handler
-	m.v201Handler.OnDataTransfer = func(stationID string, req *v201.DataTransferRequest) (*v201.DataTransferResponse, error) {
-		m.logger.Info("Handling DataTransfer (2.0.1)", "stationId", stationID, "vendorId", req.VendorId, "messageId", req.MessageId)
-
-		// TODO: Implement actual data transfer logic
-		return &v201.DataTransferResponse{Status: v201.DataTransferStatusUnknownVendorId}, nil
+		fileName := station.Firmware.GetDiagnostics(req.Location, req.Retries, req.RetryInterval)
+		return &v16.GetDiagnosticsResponse{FileName: fileName}, nil
 	}
 
 	// TriggerMessage handler
-	m.v201Handler.OnTriggerMessage = func(stationID string, req *v201.TriggerMessageRequest) (*v201.TriggerMessageResponse, error) {
-		m.logger.Info("Handling TriggerMessage (2.0.1)", "stationId", stationID, "requestedMessage", req.RequestedMessage)
+	m.v16Handler.OnTriggerMessage = func(stationID string, req *v16.TriggerMessageRequest) (*v16.TriggerMessageResponse, error) {
+		m.logger.Info("Handling TriggerMessage",
+			"stationId", stationID,
+			"requestedMessage", req.RequestedMessage,
+			"connectorId", req.ConnectorId,
+		)
 
 		// Get station
 		m.mu.RLock()
@@ -573,32 +689,46 @@ func (m *Manager) setupV201HandlerCallbacks() {
 		m.mu.RUnlock()
 
 		if !exists {
-			return &v201.TriggerMessageResponse{Status: "Rejected"}, nil
+			return &v16.TriggerMessageResponse{Status: v16.TriggerMessageStatusRejected}, nil
 		}
 
-		// Handle different trigger types
+		var action func()
+
 		switch req.RequestedMessage {
-		case "BootNotification":
-			go m.sendBootNotification(stationID)
-			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
-		case "Heartbeat":
-			go m.sendHeartbeat(stationID, station)
-			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
-		case "StatusNotification":
-			go m.sendAllConnectorStatus(stationID, station)
-			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
-		case "SignCertificate":
-			// Generate and send SignCertificate request for charging station certificate
-			go m.sendSignCertificateRequest(stationID, station, v201.CertificateUseChargingStationCertificate)
-			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
+		case v16.MessageTriggerFirmwareStatusNotification:
+			action = func() {
+				if err := station.Firmware.SendFirmwareStatus(station.Firmware.FirmwareStatus()); err != nil {
+					m.logger.Error("Failed to send FirmwareStatusNotification", "stationId", stationID, "error", err)
+				}
+			}
+
+		case v16.MessageTriggerDiagnosticsStatusNotification:
+			action = func() {
+				if err := station.Firmware.SendDiagnosticsStatus(station.Firmware.DiagnosticsStatus()); err != nil {
+					m.logger.Error("Failed to send DiagnosticsStatusNotification", "stationId", stationID, "error", err)
+				}
+			}
+
 		default:
-			return &v201.TriggerMessageResponse{Status: "NotImplemented"}, nil
+			var status v16.TriggerMessageStatus
+			action, status = m.triggerCoreMessage(stationID, station, string(req.RequestedMessage), req.ConnectorId)
+			if action == nil {
+				return &v16.TriggerMessageResponse{Status: status}, nil
+			}
 		}
+
+		m.afterResponse(station, action)
+
+		return &v16.TriggerMessageResponse{Status: v16.TriggerMessageStatusAccepted}, nil
 	}
 
-	// GetTransactionStatus handler
-	m.v201Handler.OnGetTransactionStatus = func(stationID string, req *v201.GetTransactionStatusRequest) (*v201.GetTransactionStatusResponse, error) {
-		m.logger.Info("Handling GetTransactionStatus (2.0.1)", "stationId", stationID, "transactionId", req.TransactionId)
+	// ExtendedTriggerMessage handler (security extensions)
+	m.v16Handler.OnExtendedTriggerMessage = func(stationID string, req *v16.ExtendedTriggerMessageRequest) (*v16.ExtendedTriggerMessageResponse, error) {
+		m.logger.Info("Handling ExtendedTriggerMessage",
+			"stationId", stationID,
+			"requestedMessage", req.RequestedMessage,
+			"connectorId", req.ConnectorId,
+		)
 
 		// Get station
 		m.mu.RLock()
@@ -606,147 +736,104 @@ func (m *Manager) setupV201HandlerCallbacks() {
 		m.mu.RUnlock()
 
 		if !exists {
-			return &v201.GetTransactionStatusResponse{MessagesInQueue: false}, nil
+			return &v16.ExtendedTriggerMessageResponse{Status: v16.TriggerMessageStatusRejected}, nil
 		}
 
-		// Check if transaction is ongoing
-		ongoing := false
-		if req.TransactionId != "" {
-			connectors := station.SessionManager.GetAllConnectors()
-			for _, connector := range connectors {
-				tx := connector.GetTransaction()
-				if tx != nil && tx.StringID == req.TransactionId {
-					ongoing = true
-					break
+		var action func()
+
+		switch req.RequestedMessage {
+		case v16.ExtendedMessageTriggerFirmwareStatusNotification:
+			action = func() {
+				// The request ID is only reported while an update is in progress
+				status := station.Firmware.FirmwareStatus()
+				var requestID *int
+				if status != v16.FirmwareStatusIdle {
+					requestID = station.Firmware.FirmwareRequestID()
+				}
+				if err := station.Firmware.SendSignedFirmwareStatus(status, requestID); err != nil {
+					m.logger.Error("Failed to send SignedFirmwareStatusNotification", "stationId", stationID, "error", err)
 				}
 			}
-		}
 
-		return &v201.GetTransactionStatusResponse{
-			OngoingIndicator: &ongoing,
-			MessagesInQueue:  false,
-		}, nil
-	}
-
-	// ==================== Certificate Management Handlers ====================
-
-	// CertificateSigned handler - CSMS sends signed certificate after CSR
-	m.v201Handler.OnCertificateSigned = func(stationID string, req *v201.CertificateSignedRequest) (*v201.CertificateSignedResponse, error) {
-		m.logger.Info("Handling CertificateSigned (2.0.1)", "stationId", stationID, "certType", req.CertificateType)
-
-		// Get station to access certificate store
-		m.mu.RLock()
-		station, exists := m.stations[stationID]
-		m.mu.RUnlock()
-
-		if !exists || station.CertificateStore == nil {
-			return &v201.CertificateSignedResponse{Status: "Rejected"}, nil
-		}
+		case v16.ExtendedMessageTriggerLogStatusNotification:
+			action = func() {
+				status, requestID := station.Firmware.LogStatus()
+				if status != v16.UploadLogStatusUploading {
+					status = v16.UploadLogStatusIdle
+					requestID = nil
+				}
+				if err := station.Firmware.SendLogStatus(status, requestID); err != nil {
+					m.logger.Error("Failed to send LogStatusNotification", "stationId", stationID, "error", err)
+				}
+			}
 
-		// Determine certificate type
-		certType := v201.CertificateUseChargingStationCertificate
-		if req.CertificateType == "V2GCertificate" {
-			certType = v201.CertificateUseV2GCertificate
-		}
+		case v16.ExtendedMessageTriggerSignChargePointCertificate:
+			action = func() { m.sendV16SignCertificate(stationID, station) }
 
-		// Check if we have a pending CSR for this certificate type
-		if !station.CertificateStore.HasPendingCSR(certType) {
-			m.logger.Warn("No pending CSR for certificate type", "certType", certType)
-			return &v201.CertificateSignedResponse{Status: "Rejected"}, nil
+		default:
+			var status v16.TriggerMessageStatus
+			action, status = m.triggerCoreMessage(stationID, station, string(req.RequestedMessage), req.ConnectorId)
+			if action == nil {
+				return &v16.ExtendedTriggerMessageResponse{Status: status}, nil
+			}
 		}
 
-		// Install the signed certificate
-		status, err := station.CertificateStore.InstallSignedCertificate(certType, req.CertificateChain)
-		if err != nil {
-			m.logger.Error("Failed to install signed certificate", "error", err)
-			return &v201.CertificateSignedResponse{Status: status}, nil
-		}
+		m.afterResponse(station, action)
 
-		m.logger.Info("Installed signed certificate", "stationId", stationID, "certType", certType)
-		return &v201.CertificateSignedResponse{Status: status}, nil
+		return &v16.ExtendedTriggerMessageResponse{Status: v16.TriggerMessageStatusAccepted}, nil
 	}
 
-	// DeleteCertificate handler
-	m.v201Handler.OnDeleteCertificate = func(stationID string, req *v201.DeleteCertificateRequest) (*v201.DeleteCertificateResponse, error) {
-		m.logger.Info("Handling DeleteCertificate (2.0.1)", "stationId", stationID,
-			"hashAlgorithm", req.CertificateHashData.HashAlgorithm,
-			"serialNumber", req.CertificateHashData.SerialNumber)
+	// CertificateSigned handler (security extensions)
+	m.v16Handler.OnCertificateSigned = func(stationID string, req *v16.CertificateSignedRequest) (*v16.CertificateSignedResponse, error) {
+		m.logger.Info("Handling CertificateSigned", "stationId", stationID)
 
-		// Get station to access certificate store
+		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists || station.CertificateStore == nil {
-			return &v201.DeleteCertificateResponse{Status: "Failed"}, nil
+		if !exists {
+			return &v16.CertificateSignedResponse{Status: v16.CertificateSignedStatusRejected}, nil
 		}
 
-		// Delete the certificate
-		status := station.CertificateStore.DeleteCertificate(req.CertificateHashData)
-		m.logger.Info("Deleted certificate", "stationId", stationID, "status", status, "serialNumber", req.CertificateHashData.SerialNumber)
-
-		return &v201.DeleteCertificateResponse{Status: string(status)}, nil
+		return &v16.CertificateSignedResponse{Status: station.Security.CertificateSigned(req.CertificateChain)}, nil
 	}
 
-	// GetInstalledCertificateIds handler
-	m.v201Handler.OnGetInstalledCertificateIds = func(stationID string, req *v201.GetInstalledCertificateIdsRequest) (*v201.GetInstalledCertificateIdsResponse, error) {
-		m.logger.Info("Handling GetInstalledCertificateIds (2.0.1)", "stationId", stationID, "types", req.CertificateType)
+	// InstallCertificate handler (security extensions)
+	m.v16Handler.OnInstallCertificate = func(stationID string, req *v16.InstallCertificateRequest) (*v16.InstallCertificateResponse, error) {
+		m.logger.Info("Handling InstallCertificate", "stationId", stationID, "certType", req.CertificateType)
 
-		// Get station to access certificate store
+		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists || station.CertificateStore == nil {
-			return &v201.GetInstalledCertificateIdsResponse{
-				Status:                   "NotFound",
-				CertificateHashDataChain: []v201.CertificateHashDataChainType{},
-			}, nil
+		if !exists {
+			return &v16.InstallCertificateResponse{Status: v16.CertificateStatusRejected}, nil
 		}
 
-		// Get installed certificate IDs
-		status, certChain := station.CertificateStore.GetInstalledCertificateIds(req.CertificateType)
-		m.logger.Info("Got installed certificates", "stationId", stationID, "status", status, "count", len(certChain))
-
-		return &v201.GetInstalledCertificateIdsResponse{
-			Status:                   string(status),
-			CertificateHashDataChain: certChain,
-		}, nil
+		return &v16.InstallCertificateResponse{Status: station.Security.InstallCertificate(req.CertificateType, req.Certificate)}, nil
 	}
 
-	// InstallCertificate handler - for root certificates
-	m.v201Handler.OnInstallCertificate = func(stationID string, req *v201.InstallCertificateRequest) (*v201.InstallCertificateResponse, error) {
-		m.logger.Info("Handling InstallCertificate (2.0.1)", "stationId", stationID, "certType", req.CertificateType)
+	// DeleteCertificate handler (security extensions)
+	m.v16Handler.OnDeleteCertificate = func(stationID string, req *v16.DeleteCertificateRequest) (*v16.DeleteCertificateResponse, error) {
+		m.logger.Info("Handling DeleteCertificate", "stationId", stationID, "serialNumber", req.CertificateHashData.SerialNumber)
 
-		// Get station to access certificate store
+		// Get station
 		m.mu.RLock()
 		station, exists := m.stations[stationID]
 		m.mu.RUnlock()
 
-		if !exists || station.CertificateStore == nil {
-			return &v201.InstallCertificateResponse{Status: "Rejected"}, nil
-		}
-
-		// Install the certificate
-		status, err := station.CertificateStore.InstallCertificate(v201.CertificateUseType(req.CertificateType), req.Certificate)
-		if err != nil {
-			m.logger.Error("Failed to install certificate", "error", err)
-			return &v201.InstallCertificateResponse{Status: string(status)}, nil
+		if !exists {
+			return &v16.DeleteCertificateResponse{Status: v16.DeleteCertificateStatusFailed}, nil
 		}
 
-		m.logger.Info("Installed certificate", "stationId", stationID, "certType", req.CertificateType)
-		return &v201.InstallCertificateResponse{Status: string(status)}, nil
+		return &v16.DeleteCertificateResponse{Status: station.Security.DeleteCertificate(req.CertificateHashData)}, nil
 	}
-}
-
-// setupV21HandlerCallbacks sets up callbacks for OCPP 2.1 handler
-func (m *Manager) setupV21HandlerCallbacks() {
-	// Since v21.Handler embeds v201.Handler, most callbacks are inherited
-	// We only need to add 2.1-specific callbacks here
 
-	// CostUpdated handler - CSMS updates running transaction cost
-	m.v21Handler.OnCostUpdated = func(stationID string, req *v21.CostUpdatedRequest) (*v21.CostUpdatedResponse, error) {
-		m.logger.Info("Handling CostUpdated (2.1)", "stationId", stationID, "transactionId", req.TransactionId, "totalCost", req.TotalCost)
+	// GetInstalledCertificateIds handler (security extensions)
+	m.v16Handler.OnGetInstalledCertificateIds = func(stationID string, req *v16.GetInstalledCertificateIdsRequest) (*v16.GetInstalledCertificateIdsResponse, error) {
+		m.logger.Info("Handling GetInstalledCertificateIds", "stationId", stationID, "certType", req.CertificateType)
 
 		// Get station
 		m.mu.RLock()
@@ -754,81 +841,62 @@ func (m *Manager) setupV21HandlerCallbacks() {
 		m.mu.RUnlock()
 
 		if !exists {
-			m.logger.Warn("Station not found for CostUpdated", "stationId", stationID)
-			return &v21.CostUpdatedResponse{}, nil
-		}
-
-		// Find the transaction and update cost (for display purposes)
-		connectors := station.SessionManager.GetAllConnectors()
-		for _, connector := range connectors {
-			tx := connector.GetTransaction()
-			if tx != nil && tx.StringID == req.TransactionId {
-				// Store cost for potential display/logging
-				m.logger.Info("Updated transaction cost",
-					"stationId", stationID,
-					"transactionId", req.TransactionId,
-					"cost", req.TotalCost,
-				)
-				break
-			}
+			return &v16.GetInstalledCertificateIdsResponse{Status: v16.GetInstalledCertificateStatusNotFound}, nil
 		}
 
-		return &v21.CostUpdatedResponse{}, nil
-	}
-
-	// CustomerInformation handler - request customer information report
-	m.v21Handler.OnCustomerInformation = func(stationID string, req *v21.CustomerInformationRequest) (*v21.CustomerInformationResponse, error) {
-		m.logger.Info("Handling CustomerInformation (2.1)", "stationId", stationID, "requestId", req.RequestId)
-
-		// For emulator, we accept but report no customer data
-		go func() {
-			time.Sleep(100 * time.Millisecond) // Brief delay before sending notification
-			m.sendNotifyCustomerInformation(stationID, req.RequestId, "", true)
-		}()
-
-		return &v21.CustomerInformationResponse{Status: "Accepted"}, nil
+		status, hashData := station.Security.GetInstalledCertificateIds(req.CertificateType)
+		return &v16.GetInstalledCertificateIdsResponse{Status: status, CertificateHashData: hashData}, nil
 	}
 
-	// SetDisplayMessage handler - display message on station screen
-	m.v21Handler.OnSetDisplayMessage = func(stationID string, req *v21.SetDisplayMessageRequest) (*v21.SetDisplayMessageResponse, error) {
-		m.logger.Info("Handling SetDisplayMessage (2.1)",
+	// SignedUpdateFirmware handler (security extensions)
+	m.v16Handler.OnSignedUpdateFirmware = func(stationID string, req *v16.SignedUpdateFirmwareRequest) (*v16.SignedUpdateFirmwareResponse, error) {
+		m.logger.Info("Handling SignedUpdateFirmware",
 			"stationId", stationID,
-			"priority", req.Message.Priority,
-			"content", req.Message.Message.Content,
+			"requestId", req.RequestId,
+			"location", req.Firmware.Location,
+			"retrieveDateTime", req.Firmware.RetrieveDateTime.Time,
 		)
 
-		// For emulator, we accept all display messages
-		return &v21.SetDisplayMessageResponse{Status: v21.DisplayMessageStatusAccepted}, nil
-	}
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
 
-	// GetDisplayMessages handler - retrieve stored display messages
-	m.v21Handler.OnGetDisplayMessages = func(stationID string, req *v21.GetDisplayMessagesRequest) (*v21.GetDisplayMessagesResponse, error) {
-		m.logger.Info("Handling GetDisplayMessages (2.1)", "stationId", stationID, "requestId", req.RequestId)
+		if !exists {
+			return &v16.SignedUpdateFirmwareResponse{Status: v16.UpdateFirmwareStatusRejected}, nil
+		}
 
-		// For emulator, return that we have no stored messages (async response via NotifyDisplayMessages)
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			m.sendNotifyDisplayMessages(stationID, req.RequestId, nil, true)
-		}()
+		if err := station.Security.VerifyFirmwareSigningCertificate(req.Firmware.SigningCertificate); err != nil {
+			m.logger.Warn("Invalid firmware signing certificate", "stationId", stationID, "error", err)
+			station.Security.AddSecurityEvent(v16.SecurityEventInvalidFirmwareSigningCertificate, err.Error())
+			return &v16.SignedUpdateFirmwareResponse{Status: v16.UpdateFirmwareStatusInvalidCertificate}, nil
+		}
 
-		return &v21.GetDisplayMessagesResponse{Status: "Accepted"}, nil
-	}
+		var installDate *time.Time
+		if req.Firmware.InstallDateTime != nil {
+			installDate = &req.Firmware.InstallDateTime.Time
+		}
 
-	// ClearDisplayMessage handler - clear specific display message
-	m.v21Handler.OnClearDisplayMessage = func(stationID string, req *v21.ClearDisplayMessageRequest) (*v21.ClearDisplayMessageResponse, error) {
-		m.logger.Info("Handling ClearDisplayMessage (2.1)", "stationId", stationID, "messageId", req.Id)
+		status := station.Firmware.UpdateSignedFirmware(
+			req.RequestId,
+			req.Firmware.Location,
+			req.Firmware.RetrieveDateTime.Time,
+			installDate,
+			req.Firmware.Signature,
+			req.Retries,
+			req.RetryInterval,
+		)
 
-		// For emulator, report success (message not found since we don't store them)
-		return &v21.ClearDisplayMessageResponse{Status: v21.ClearMessageStatusAccepted}, nil
+		return &v16.SignedUpdateFirmwareResponse{Status: status}, nil
 	}
 
-	// ReserveNow handler - make a reservation
-	m.v21Handler.OnReserveNow = func(stationID string, req *v21.ReserveNowRequest) (*v21.ReserveNowResponse, error) {
-		m.logger.Info("Handling ReserveNow (2.1)",
+	// GetLog handler (security extensions)
+	m.v16Handler.OnGetLog = func(stationID string, req *v16.GetLogRequest) (*v16.GetLogResponse, error) {
+		m.logger.Info("Handling GetLog",
 			"stationId", stationID,
-			"reservationId", req.Id,
-			"evseId", req.EvseId,
-			"expiryDateTime", req.ExpiryDateTime,
+			"requestId", req.RequestId,
+			"logType", req.LogType,
+			"location", req.Log.RemoteLocation,
 		)
 
 		// Get station
@@ -837,411 +905,3639 @@ func (m *Manager) setupV21HandlerCallbacks() {
 		m.mu.RUnlock()
 
 		if !exists {
-			return &v21.ReserveNowResponse{Status: v21.ReservationStatusRejected}, nil
+			return &v16.GetLogResponse{Status: v16.LogStatusRejected}, nil
 		}
 
-		// Check if EVSE is available
-		connectorID := 0
+		status, fileName := station.Firmware.GetLog(req.RequestId, req.LogType, req.Log.RemoteLocation, req.Retries, req.RetryInterval)
+		return &v16.GetLogResponse{Status: status, Filename: fileName}, nil
+	}
+}
+
+// setupV201HandlerCallbacks sets up callbacks for OCPP 2.0.1 handler
+func (m *Manager) setupV201HandlerCallbacks() {
+	// RequestStartTransaction handler (replaces RemoteStartTransaction in 2.0.1)
+	m.v201Handler.OnRequestStartTransaction = func(stationID string, req *v201.RequestStartTransactionRequest) (*v201.RequestStartTransactionResponse, error) {
+		m.logger.Info("Handling RequestStartTransaction (2.0.1)", "stationId", stationID, "idToken", req.IdToken.IdToken)
+
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists {
+			return &v201.RequestStartTransactionResponse{Status: "Rejected"}, nil
+		}
+
+		// Determine EVSE/connector ID
+		connectorID := 1
 		if req.EvseId != nil {
 			connectorID = *req.EvseId
 		}
 
-		if connectorID > 0 {
-			connector, err := station.SessionManager.GetConnector(connectorID)
-			if err != nil || connector == nil {
-				return &v21.ReserveNowResponse{Status: v21.ReservationStatusUnavailable}, nil
+		// Start charging session
+		_, err := station.SessionManager.StartRemoteCharging(connectorID, req.IdToken, req.RemoteStartId)
+		if err != nil {
+			m.logger.Error("Failed to start charging", "error", err)
+			return &v201.RequestStartTransactionResponse{Status: "Rejected"}, nil
+		}
+
+		resp := &v201.RequestStartTransactionResponse{
+			Status: "Accepted",
+		}
+		if connector, err := station.SessionManager.GetConnector(connectorID); err == nil {
+			if tx := connector.GetTransaction(); tx != nil {
+				resp.TransactionId = tx.StringID
 			}
-			state := connector.GetState()
-			if state != ConnectorStateAvailable {
-				if state == ConnectorStatePreparing || state == ConnectorStateCharging {
-					return &v21.ReserveNowResponse{Status: v21.ReservationStatusOccupied}, nil
-				}
-				if state == ConnectorStateFaulted {
-					return &v21.ReserveNowResponse{Status: v21.ReservationStatusFaulted}, nil
+		}
+
+		// Install the TxProfile sent with the request for the new transaction
+		if req.ChargingProfile != nil && resp.TransactionId != "" {
+			profile := *req.ChargingProfile
+			profile.TransactionId = resp.TransactionId
+			if err := station.SessionManager.SetEVSEChargingProfile(connectorID, profile); err != nil {
+				m.logger.Warn("Charging profile of RequestStartTransaction rejected", "stationId", stationID, "error", err)
+			}
+		}
+		return resp, nil
+	}
+
+	// RequestStopTransaction handler (replaces RemoteStopTransaction in 2.0.1)
+	m.v201Handler.OnRequestStopTransaction = func(stationID string, req *v201.RequestStopTransactionRequest) (*v201.RequestStopTransactionResponse, error) {
+		m.logger.Info("Handling RequestStopTransaction (2.0.1)", "stationId", stationID, "transactionId", req.TransactionId)
+
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists {
+			return &v201.RequestStopTransactionResponse{Status: "Rejected"}, nil
+		}
+
+		// Find connector with this transaction
+		connectors := station.SessionManager.GetAllConnectors()
+		var targetConnectorID int
+		found := false
+
+		for _, connector := range connectors {
+			tx := connector.GetTransaction()
+			if tx != nil && tx.StringID == req.TransactionId {
+				targetConnectorID = connector.ID
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			m.logger.Warn("Transaction not found", "transactionId", req.TransactionId)
+			return &v201.RequestStopTransactionResponse{Status: "Rejected"}, nil
+		}
+
+		// Stop charging session
+		err := station.SessionManager.StopCharging(targetConnectorID, v16.ReasonRemote)
+		if err != nil {
+			m.logger.Error("Failed to stop charging", "error", err)
+			return &v201.RequestStopTransactionResponse{Status: "Rejected"}, nil
+		}
+
+		return &v201.RequestStopTransactionResponse{
+			Status: "Accepted",
+		}, nil
+	}
+
+	// Reset handler
+	m.v201Handler.OnReset = m.handleV201Reset
+
+	// SetNetworkProfile handler
+	m.v201Handler.OnSetNetworkProfile = m.handleV201SetNetworkProfile
+
+	// GetVariables handler - uses device model
+	m.v201Handler.OnGetVariables = func(stationID string, req *v201.GetVariablesRequest) (*v201.GetVariablesResponse, error) {
+		m.logger.Info("Handling GetVariables (2.0.1)", "stationId", stationID, "count", len(req.GetVariableData))
+
+		// Get station to access device model
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.DeviceModel == nil {
+			// Return rejected for all if station or device model not found
+			results := make([]v201.GetVariableResult, len(req.GetVariableData))
+			for i, data := range req.GetVariableData {
+				results[i] = v201.GetVariableResult{
+					AttributeStatus: v201.GetVariableStatusRejected,
+					Component:       data.Component,
+					Variable:        data.Variable,
 				}
-				return &v21.ReserveNowResponse{Status: v21.ReservationStatusUnavailable}, nil
 			}
+			return &v201.GetVariablesResponse{GetVariableResult: results}, nil
 		}
 
-		// For emulator, accept the reservation
-		m.logger.Info("Reservation accepted", "stationId", stationID, "reservationId", req.Id)
-		return &v21.ReserveNowResponse{Status: v21.ReservationStatusAccepted}, nil
+		// Query device model for each requested variable
+		results := make([]v201.GetVariableResult, len(req.GetVariableData))
+		for i, data := range req.GetVariableData {
+			// Determine attribute type (default to Actual)
+			attrType := v201.AttributeActual
+			if data.AttributeType != nil {
+				attrType = *data.AttributeType
+			}
+
+			// Get variable from device model
+			value, status := station.DeviceModel.GetVariable(
+				data.Component.Name,
+				data.Component.Instance,
+				data.Variable.Name,
+				data.Variable.Instance,
+				attrType,
+			)
+
+			attrTypeCopy := attrType
+			results[i] = v201.GetVariableResult{
+				AttributeStatus: status,
+				AttributeType:   &attrTypeCopy,
+				AttributeValue:  value,
+				Component:       data.Component,
+				Variable:        data.Variable,
+			}
+
+			m.logger.Debug("GetVariable result",
+				"component", data.Component.Name,
+				"variable", data.Variable.Name,
+				"status", status,
+				"value", value,
+			)
+		}
+		return &v201.GetVariablesResponse{GetVariableResult: results}, nil
 	}
 
-	// CancelReservation handler - cancel a reservation
-	m.v21Handler.OnCancelReservation = func(stationID string, req *v21.CancelReservationRequest) (*v21.CancelReservationResponse, error) {
-		m.logger.Info("Handling CancelReservation (2.1)", "stationId", stationID, "reservationId", req.ReservationId)
+	// SetVariables handler - uses device model
+	m.v201Handler.OnSetVariables = func(stationID string, req *v201.SetVariablesRequest) (*v201.SetVariablesResponse, error) {
+		m.logger.Info("Handling SetVariables (2.0.1)", "stationId", stationID, "count", len(req.SetVariableData))
+
+		// Get station to access device model
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.DeviceModel == nil {
+			// Return rejected for all if station or device model not found
+			results := make([]v201.SetVariableResult, len(req.SetVariableData))
+			for i, data := range req.SetVariableData {
+				results[i] = v201.SetVariableResult{
+					AttributeStatus: v201.SetVariableStatusRejected,
+					Component:       data.Component,
+					Variable:        data.Variable,
+				}
+			}
+			return &v201.SetVariablesResponse{SetVariableResult: results}, nil
+		}
+
+		// Set each variable in device model
+		results := make([]v201.SetVariableResult, len(req.SetVariableData))
+		changed := false
+		for i, data := range req.SetVariableData {
+			// Determine attribute type (default to Actual)
+			attrType := v201.AttributeActual
+			if data.AttributeType != nil {
+				attrType = *data.AttributeType
+			}
+
+			// Only configuration slots holding a network profile can be prioritized
+			networkPriority := data.Component.Name == "OCPPCommCtrlr" && data.Variable.Name == "NetworkConfigurationPriority"
+
+			// Set variable in device model
+			var status v201.SetVariableStatusType
+			if networkPriority && !m.networkSlotsConfigured(station, data.AttributeValue) {
+				status = v201.SetVariableStatusRejected
+			} else {
+				status = station.DeviceModel.SetVariable(
+					data.Component.Name,
+					data.Component.Instance,
+					data.Variable.Name,
+					data.Variable.Instance,
+					attrType,
+					data.AttributeValue,
+				)
+			}
+			if status == v201.SetVariableStatusAccepted {
+				changed = true
+
+				// The new priority applies when the station reconnects
+				if networkPriority {
+					status = v201.SetVariableStatusRebootRequired
+				}
+			}
+
+			attrTypeCopy := attrType
+			results[i] = v201.SetVariableResult{
+				AttributeStatus: status,
+				AttributeType:   &attrTypeCopy,
+				Component:       data.Component,
+				Variable:        data.Variable,
+			}
+
+			m.logger.Debug("SetVariable result",
+				"component", data.Component.Name,
+				"variable", data.Variable.Name,
+				"status", status,
+				"value", data.AttributeValue,
+			)
 
-		// For emulator, accept the cancellation
-		return &v21.CancelReservationResponse{Status: v21.CancelReservationStatusAccepted}, nil
+			// Apply transaction settings to the following transactions
+			if status == v201.SetVariableStatusAccepted && data.Component.Name == "TxCtrlr" && station.SessionManager != nil {
+				station.SessionManager.SetTxControl(TxControlFromDeviceModel(station.DeviceModel))
+			}
+
+			// Apply authorization settings to the following authorizations
+			if status == v201.SetVariableStatusAccepted && isIdTokenAuthComponent(data.Component.Name) && station.SessionManager != nil {
+				station.SessionManager.IdTokenAuthorization().SetConfig(IdTokenAuthConfigFromDeviceModel(station.DeviceModel))
+			}
+		}
+
+		if changed {
+			m.persistDeviceModel(station)
+		}
+		return &v201.SetVariablesResponse{SetVariableResult: results}, nil
 	}
 
-	// SetChargingProfile handler - set/update charging profile
-	m.v21Handler.OnSetChargingProfile = func(stationID string, req *v21.SetChargingProfileRequest) (*v21.SetChargingProfileResponse, error) {
-		m.logger.Info("Handling SetChargingProfile (2.1)",
-			"stationId", stationID,
-			"evseId", req.EvseId,
-			"profileId", req.ChargingProfile.ID,
-			"purpose", req.ChargingProfile.ChargingProfilePurpose,
-		)
+	// ChangeAvailability handler
+	m.v201Handler.OnChangeAvailability = func(stationID string, req *v201.ChangeAvailabilityRequest) (*v201.ChangeAvailabilityResponse, error) {
+		m.logger.Info("Handling ChangeAvailability (2.0.1)", "stationId", stationID, "status", req.OperationalStatus)
+
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists {
+			return &v201.ChangeAvailabilityResponse{Status: "Rejected"}, nil
+		}
+
+		// Determine which connector to change (0 means all)
+		connectorID := 0
+		if req.EVSE != nil {
+			connectorID = req.EVSE.ID
+		}
+
+		// Map OCPP 2.0.1 status to 1.6 availability type
+		availType := "Operative"
+		if req.OperationalStatus == "Inoperative" {
+			availType = "Inoperative"
+		}
+
+		// Change availability
+		err := station.SessionManager.ChangeAvailability(connectorID, availType)
+		if err != nil {
+			m.logger.Warn("Cannot change availability immediately", "error", err)
+			return &v201.ChangeAvailabilityResponse{Status: "Scheduled"}, nil
+		}
 
-		// For emulator, accept all charging profiles
-		return &v21.SetChargingProfileResponse{Status: v21.ChargingProfileStatusAccepted}, nil
+		return &v201.ChangeAvailabilityResponse{Status: "Accepted"}, nil
 	}
 
-	// GetChargingProfiles handler - get installed charging profiles
-	m.v21Handler.OnGetChargingProfiles = func(stationID string, req *v21.GetChargingProfilesRequest) (*v21.GetChargingProfilesResponse, error) {
-		m.logger.Info("Handling GetChargingProfiles (2.1)", "stationId", stationID, "requestId", req.RequestId)
+	// UnlockConnector handler
+	m.v201Handler.OnUnlockConnector = func(stationID string, req *v201.UnlockConnectorRequest) (*v201.UnlockConnectorResponse, error) {
+		m.logger.Info("Handling UnlockConnector (2.0.1)", "stationId", stationID, "evseId", req.EvseId, "connectorId", req.ConnectorId)
 
-		// For emulator, report no profiles (async response via ReportChargingProfiles)
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			m.sendReportChargingProfiles(stationID, req.RequestId, nil, true)
-		}()
+		// TODO: Implement actual unlock logic
+		return &v201.UnlockConnectorResponse{Status: "UnknownConnector"}, nil
+	}
+
+	// ClearCache handler
+	m.v201Handler.OnClearCache = func(stationID string, req *v201.ClearCacheRequest) (*v201.ClearCacheResponse, error) {
+		m.logger.Info("Handling ClearCache (2.0.1)", "stationId", stationID)
 
-		return &v21.GetChargingProfilesResponse{Status: "NoProfiles"}, nil
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.SessionManager == nil {
+			return &v201.ClearCacheResponse{Status: "Rejected"}, nil
+		}
+
+		if !station.SessionManager.IdTokenAuthorization().ClearCache() {
+			return &v201.ClearCacheResponse{Status: "Rejected"}, nil
+		}
+
+		return &v201.ClearCacheResponse{Status: "Accepted"}, nil
+	}
+
+	// Local Authorization List handlers
+	m.v201Handler.OnSendLocalList = m.handleV201SendLocalList
+	m.v201Handler.OnGetLocalListVersion = m.handleV201GetLocalListVersion
+
+	// DataTransfer handler
+	m.v201Handler.OnDataTransfer = func(stationID string, req *v201.DataTransferRequest) (*v201.DataTransferResponse, error) {
+		m.logger.Info("Handling DataTransfer (2.0.1)", "stationId", stationID, "vendorId", req.VendorId, "messageId", req.MessageId)
+
+		// TODO: Implement actual data transfer logic
+		return &v201.DataTransferResponse{Status: v201.DataTransferStatusUnknownVendorId}, nil
+	}
+
+	// TriggerMessage handler
+	m.v201Handler.OnTriggerMessage = func(stationID string, req *v201.TriggerMessageRequest) (*v201.TriggerMessageResponse, error) {
+		m.logger.Info("Handling TriggerMessage (2.0.1)", "stationId", stationID, "requestedMessage", req.RequestedMessage)
+
+		// Get station
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists {
+			return &v201.TriggerMessageResponse{Status: "Rejected"}, nil
+		}
+
+		// Handle different trigger types
+		switch req.RequestedMessage {
+		case "BootNotification":
+			go m.sendBootNotification(stationID)
+			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
+		case "Heartbeat":
+			go m.sendHeartbeat(stationID, station)
+			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
+		case "StatusNotification":
+			go m.sendAllConnectorStatus(stationID, station)
+			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
+		case "SignCertificate":
+			// Generate and send SignCertificate request for charging station certificate
+			go m.sendSignCertificateRequest(stationID, station, v201.CertificateUseChargingStationCertificate)
+			return &v201.TriggerMessageResponse{Status: "Accepted"}, nil
+		default:
+			return &v201.TriggerMessageResponse{Status: "NotImplemented"}, nil
+		}
+	}
+
+	// GetTransactionStatus handler
+	m.v201Handler.OnGetTransactionStatus = m.handleV201GetTransactionStatus
+
+	// Device model reports, sent with NotifyReport after the response
+	m.v201Handler.OnGetBaseReport = m.handleV201GetBaseReport
+	m.v201Handler.OnGetReport = m.handleV201GetReport
+
+	// Variable monitoring, triggered monitors are reported with NotifyEvent
+	m.v201Handler.OnSetVariableMonitoring = m.handleV201SetVariableMonitoring
+	m.v201Handler.OnSetMonitoringBase = m.handleV201SetMonitoringBase
+	m.v201Handler.OnSetMonitoringLevel = m.handleV201SetMonitoringLevel
+	m.v201Handler.OnClearVariableMonitoring = m.handleV201ClearVariableMonitoring
+	m.v201Handler.OnGetMonitoringReport = m.handleV201GetMonitoringReport
+
+	// Smart charging, shares the charging profile engine with OCPP 1.6
+	m.v201Handler.OnSetChargingProfile = m.handleV201SetChargingProfile
+	m.v201Handler.OnClearChargingProfile = m.handleV201ClearChargingProfile
+	m.v201Handler.OnGetCompositeSchedule = m.handleV201GetCompositeSchedule
+
+	// Reservations of an EVSE or of any EVSE with a connector type
+	m.v201Handler.OnReserveNow = m.handleV201ReserveNow
+	m.v201Handler.OnCancelReservation = m.handleV201CancelReservation
+
+	// Firmware management handlers
+	m.v201Handler.OnUpdateFirmware = m.handleV201UpdateFirmware
+	m.v201Handler.OnGetLog = m.handleV201GetLog
+
+	// Display message and cost handlers
+	m.v201Handler.OnSetDisplayMessage = m.handleV201SetDisplayMessage
+	m.v201Handler.OnGetDisplayMessages = m.handleV201GetDisplayMessages
+	m.v201Handler.OnClearDisplayMessage = m.handleV201ClearDisplayMessage
+	m.v201Handler.OnCostUpdated = m.handleV201CostUpdated
+
+	// ==================== Certificate Management Handlers ====================
+
+	// CertificateSigned handler - CSMS sends signed certificate after CSR
+	m.v201Handler.OnCertificateSigned = func(stationID string, req *v201.CertificateSignedRequest) (*v201.CertificateSignedResponse, error) {
+		m.logger.Info("Handling CertificateSigned (2.0.1)", "stationId", stationID, "certType", req.CertificateType)
+
+		// Get station to access certificate store
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.CertificateStore == nil {
+			return &v201.CertificateSignedResponse{Status: "Rejected"}, nil
+		}
+
+		// Determine certificate type
+		certType := v201.CertificateUseChargingStationCertificate
+		if req.CertificateType == "V2GCertificate" {
+			certType = v201.CertificateUseV2GCertificate
+		}
+
+		// Check if we have a pending CSR for this certificate type
+		if !station.CertificateStore.HasPendingCSR(certType) {
+			m.logger.Warn("No pending CSR for certificate type", "certType", certType)
+			return &v201.CertificateSignedResponse{Status: "Rejected"}, nil
+		}
+
+		// Install the signed certificate
+		status, err := station.CertificateStore.InstallSignedCertificate(certType, req.CertificateChain)
+		if err != nil {
+			m.logger.Error("Failed to install signed certificate", "error", err)
+			return &v201.CertificateSignedResponse{Status: status}, nil
+		}
+
+		m.logger.Info("Installed signed certificate", "stationId", stationID, "certType", certType)
+		return &v201.CertificateSignedResponse{Status: status}, nil
+	}
+
+	// DeleteCertificate handler
+	m.v201Handler.OnDeleteCertificate = func(stationID string, req *v201.DeleteCertificateRequest) (*v201.DeleteCertificateResponse, error) {
+		m.logger.Info("Handling DeleteCertificate (2.0.1)", "stationId", stationID,
+			"hashAlgorithm", req.CertificateHashData.HashAlgorithm,
+			"serialNumber", req.CertificateHashData.SerialNumber)
+
+		// Get station to access certificate store
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.CertificateStore == nil {
+			return &v201.DeleteCertificateResponse{Status: "Failed"}, nil
+		}
+
+		// Delete the certificate
+		status := station.CertificateStore.DeleteCertificate(req.CertificateHashData)
+		m.logger.Info("Deleted certificate", "stationId", stationID, "status", status, "serialNumber", req.CertificateHashData.SerialNumber)
+
+		return &v201.DeleteCertificateResponse{Status: string(status)}, nil
+	}
+
+	// GetInstalledCertificateIds handler
+	m.v201Handler.OnGetInstalledCertificateIds = func(stationID string, req *v201.GetInstalledCertificateIdsRequest) (*v201.GetInstalledCertificateIdsResponse, error) {
+		m.logger.Info("Handling GetInstalledCertificateIds (2.0.1)", "stationId", stationID, "types", req.CertificateType)
+
+		// Get station to access certificate store
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.CertificateStore == nil {
+			return &v201.GetInstalledCertificateIdsResponse{
+				Status:                   "NotFound",
+				CertificateHashDataChain: []v201.CertificateHashDataChainType{},
+			}, nil
+		}
+
+		// Get installed certificate IDs
+		status, certChain := station.CertificateStore.GetInstalledCertificateIds(req.CertificateType)
+		m.logger.Info("Got installed certificates", "stationId", stationID, "status", status, "count", len(certChain))
+
+		return &v201.GetInstalledCertificateIdsResponse{
+			Status:                   string(status),
+			CertificateHashDataChain: certChain,
+		}, nil
+	}
+
+	// InstallCertificate handler - for root certificates
+	m.v201Handler.OnInstallCertificate = func(stationID string, req *v201.InstallCertificateRequest) (*v201.InstallCertificateResponse, error) {
+		m.logger.Info("Handling InstallCertificate (2.0.1)", "stationId", stationID, "certType", req.CertificateType)
+
+		// Get station to access certificate store
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.CertificateStore == nil {
+			return &v201.InstallCertificateResponse{Status: "Rejected"}, nil
+		}
+
+		// Install the certificate
+		status, err := station.CertificateStore.InstallCertificate(v201.CertificateUseType(req.CertificateType), req.Certificate)
+		if err != nil {
+			m.logger.Error("Failed to install certificate", "error", err)
+			return &v201.InstallCertificateResponse{Status: string(status)}, nil
+		}
+
+		m.logger.Info("Installed certificate", "stationId", stationID, "certType", req.CertificateType)
+		return &v201.InstallCertificateResponse{Status: string(status)}, nil
+	}
+}
+
+// setupV21HandlerCallbacks sets up callbacks for OCPP 2.1 handler
+func (m *Manager) setupV21HandlerCallbacks() {
+	// Since v21.Handler embeds v201.Handler, most callbacks are inherited
+	// We only need to add 2.1-specific callbacks here
+
+	// CostUpdated handler - CSMS updates running transaction cost
+	// Reset and GetTransactionStatus are inherited from 2.0.1
+	m.v21Handler.OnReset = m.handleV201Reset
+	m.v21Handler.OnGetTransactionStatus = m.handleV201GetTransactionStatus
+	m.v21Handler.OnGetBaseReport = m.handleV201GetBaseReport
+	m.v21Handler.OnGetReport = m.handleV201GetReport
+	m.v21Handler.OnSetVariableMonitoring = m.handleV201SetVariableMonitoring
+	m.v21Handler.OnSetMonitoringBase = m.handleV201SetMonitoringBase
+	m.v21Handler.OnSetMonitoringLevel = m.handleV201SetMonitoringLevel
+	m.v21Handler.OnClearVariableMonitoring = m.handleV201ClearVariableMonitoring
+	m.v21Handler.OnGetMonitoringReport = m.handleV201GetMonitoringReport
+
+	m.v21Handler.OnCostUpdated = func(stationID string, req *v21.CostUpdatedRequest) (*v21.CostUpdatedResponse, error) {
+		m.handleV201CostUpdated(stationID, &v201.CostUpdatedRequest{TotalCost: req.TotalCost, TransactionId: req.TransactionId})
+		return &v21.CostUpdatedResponse{}, nil
+	}
+
+	// Tariff handlers - default tariffs and the tariffs of running transactions
+	m.v21Handler.OnSetDefaultTariff = m.handleV21SetDefaultTariff
+	m.v21Handler.OnGetTariffs = m.handleV21GetTariffs
+	m.v21Handler.OnClearTariffs = m.handleV21ClearTariffs
+	m.v21Handler.OnChangeTransactionTariff = m.handleV21ChangeTransactionTariff
+
+	// Local Controller handlers - firmware published to the stations behind it
+	m.v21Handler.OnPublishFirmware = m.handleV21PublishFirmware
+	m.v21Handler.OnUnpublishFirmware = m.handleV21UnpublishFirmware
+
+	// CustomerInformation handler - request customer information report
+	m.v21Handler.OnCustomerInformation = func(stationID string, req *v21.CustomerInformationRequest) (*v21.CustomerInformationResponse, error) {
+		m.logger.Info("Handling CustomerInformation (2.1)", "stationId", stationID, "requestId", req.RequestId)
+
+		// For emulator, we accept but report no customer data
+		go func() {
+			time.Sleep(100 * time.Millisecond) // Brief delay before sending notification
+			m.sendNotifyCustomerInformation(stationID, req.RequestId, "", true)
+		}()
+
+		return &v21.CustomerInformationResponse{Status: "Accepted"}, nil
+	}
+
+	// SetDisplayMessage handler - display message on station screen
+	m.v21Handler.OnSetDisplayMessage = func(stationID string, req *v21.SetDisplayMessageRequest) (*v21.SetDisplayMessageResponse, error) {
+		info, err := messageInfoFromV21(req.Message)
+		if err != nil {
+			return &v21.SetDisplayMessageResponse{
+				Status:     v21.DisplayMessageStatusRejected,
+				StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidMessage", AdditionalInfo: err.Error()},
+			}, nil
+		}
+
+		resp, _ := m.handleV201SetDisplayMessage(stationID, &v201.SetDisplayMessageRequest{Message: info})
+		return &v21.SetDisplayMessageResponse{
+			Status:     v21.DisplayMessageStatusType(resp.Status),
+			StatusInfo: resp.StatusInfo,
+		}, nil
+	}
+
+	// GetDisplayMessages handler - retrieve stored display messages
+	m.v21Handler.OnGetDisplayMessages = func(stationID string, req *v21.GetDisplayMessagesRequest) (*v21.GetDisplayMessagesResponse, error) {
+		v201Req := &v201.GetDisplayMessagesRequest{Id: req.Id, RequestId: req.RequestId}
+		if req.Priority != nil {
+			priority := v201.MessagePriorityType(*req.Priority)
+			v201Req.Priority = &priority
+		}
+		if req.State != nil {
+			state := v201.MessageStateType(*req.State)
+			v201Req.State = &state
+		}
+
+		resp, _ := m.handleV201GetDisplayMessages(stationID, v201Req)
+		return &v21.GetDisplayMessagesResponse{Status: string(resp.Status), StatusInfo: resp.StatusInfo}, nil
+	}
+
+	// ClearDisplayMessage handler - clear specific display message
+	m.v21Handler.OnClearDisplayMessage = func(stationID string, req *v21.ClearDisplayMessageRequest) (*v21.ClearDisplayMessageResponse, error) {
+		resp, _ := m.handleV201ClearDisplayMessage(stationID, &v201.ClearDisplayMessageRequest{Id: req.Id})
+		return &v21.ClearDisplayMessageResponse{
+			Status:     v21.ClearMessageStatusType(resp.Status),
+			StatusInfo: resp.StatusInfo,
+		}, nil
+	}
+
+	// ReserveNow handler - make a reservation
+	m.v21Handler.OnReserveNow = func(stationID string, req *v21.ReserveNowRequest) (*v21.ReserveNowResponse, error) {
+		expiryDateTime, err := time.Parse(time.RFC3339, req.ExpiryDateTime)
+		if err != nil {
+			return &v21.ReserveNowResponse{
+				Status:     v21.ReservationStatusRejected,
+				StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidExpiryDateTime", AdditionalInfo: err.Error()},
+			}, nil
+		}
+
+		reservation := &v201.ReserveNowRequest{
+			Id:             req.Id,
+			ExpiryDateTime: v201.DateTime{Time: expiryDateTime},
+			EvseId:         req.EvseId,
+			IdToken:        req.IdToken,
+			GroupIdToken:   req.GroupIdToken,
+		}
+		if req.ConnectorType != nil {
+			connectorType := v201.ConnectorType(*req.ConnectorType)
+			reservation.ConnectorType = &connectorType
+		}
+
+		resp, _ := m.handleV201ReserveNow(stationID, reservation)
+		return &v21.ReserveNowResponse{
+			Status:     v21.ReservationStatusType(resp.Status),
+			StatusInfo: resp.StatusInfo,
+		}, nil
+	}
+
+	// CancelReservation handler - cancel a reservation
+	m.v21Handler.OnCancelReservation = func(stationID string, req *v21.CancelReservationRequest) (*v21.CancelReservationResponse, error) {
+		resp, _ := m.handleV201CancelReservation(stationID, &v201.CancelReservationRequest{ReservationId: req.ReservationId})
+		return &v21.CancelReservationResponse{
+			Status:     v21.CancelReservationStatusType(resp.Status),
+			StatusInfo: resp.StatusInfo,
+		}, nil
+	}
+
+	// SetChargingProfile handler - set/update charging profile
+	m.v21Handler.OnSetChargingProfile = func(stationID string, req *v21.SetChargingProfileRequest) (*v21.SetChargingProfileResponse, error) {
+		m.logger.Info("Handling SetChargingProfile (2.1)",
+			"stationId", stationID,
+			"evseId", req.EvseId,
+			"profileId", req.ChargingProfile.ID,
+			"purpose", req.ChargingProfile.ChargingProfilePurpose,
+		)
+
+		profile, err := chargingProfileFromV21(req.ChargingProfile)
+		if err != nil {
+			return &v21.SetChargingProfileResponse{
+				Status:     v21.ChargingProfileStatusRejected,
+				StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidProfile", AdditionalInfo: err.Error()},
+			}, nil
+		}
+
+		resp, _ := m.handleV201SetChargingProfile(stationID, &v201.SetChargingProfileRequest{EvseId: req.EvseId, ChargingProfile: profile})
+		return &v21.SetChargingProfileResponse{
+			Status:     v21.ChargingProfileStatusType(resp.Status),
+			StatusInfo: resp.StatusInfo,
+		}, nil
+	}
+
+	// GetChargingProfiles handler - get installed charging profiles
+	m.v21Handler.OnGetChargingProfiles = func(stationID string, req *v21.GetChargingProfilesRequest) (*v21.GetChargingProfilesResponse, error) {
+		m.logger.Info("Handling GetChargingProfiles (2.1)", "stationId", stationID, "requestId", req.RequestId)
+
+		m.mu.RLock()
+		station, exists := m.stations[stationID]
+		m.mu.RUnlock()
+
+		if !exists || station.SessionManager == nil {
+			return &v21.GetChargingProfilesResponse{Status: "NoProfiles"}, nil
+		}
+
+		reports := m.chargingProfileReports(station, req)
+		if len(reports) == 0 {
+			return &v21.GetChargingProfilesResponse{Status: "NoProfiles"}, nil
+		}
+
+		// The profiles are reported once the response has been sent
+		m.afterResponse(station, func() {
+			for _, report := range reports {
+				m.sendReportChargingProfiles(stationID, report)
+			}
+		})
+
+		return &v21.GetChargingProfilesResponse{Status: "Accepted"}, nil
+	}
+
+	// ClearChargingProfile handler - clear charging profiles
+	m.v21Handler.OnClearChargingProfile = func(stationID string, req *v21.ClearChargingProfileRequest) (*v21.ClearChargingProfileResponse, error) {
+		m.logger.Info("Handling ClearChargingProfile (2.1)",
+			"stationId", stationID,
+			"chargingProfileId", req.ChargingProfileId,
+		)
+
+		clearReq := &v201.ClearChargingProfileRequest{ChargingProfileId: req.ChargingProfileId}
+		if criteria := req.ChargingProfileCriteria; criteria != nil {
+			clearReq.ChargingProfileCriteria = &v201.ClearChargingProfile{
+				EvseId:     criteria.EvseId,
+				StackLevel: criteria.StackLevel,
+			}
+			if criteria.ChargingProfilePurpose != nil {
+				clearReq.ChargingProfileCriteria.ChargingProfilePurpose = v201.ChargingProfilePurposeType(*criteria.ChargingProfilePurpose)
+			}
+		}
+
+		resp, _ := m.handleV201ClearChargingProfile(stationID, clearReq)
+		return &v21.ClearChargingProfileResponse{
+			Status:     v21.ClearChargingProfileStatusType(resp.Status),
+			StatusInfo: resp.StatusInfo,
+		}, nil
+	}
+
+	// GetCompositeSchedule handler - get composite charging schedule
+	m.v21Handler.OnGetCompositeSchedule = func(stationID string, req *v21.GetCompositeScheduleRequest) (*v21.GetCompositeScheduleResponse, error) {
+		m.logger.Info("Handling GetCompositeSchedule (2.1)",
+			"stationId", stationID,
+			"evseId", req.EvseId,
+			"duration", req.Duration,
+		)
+
+		scheduleReq := &v201.GetCompositeScheduleRequest{Duration: req.Duration, EvseId: req.EvseId}
+		if req.ChargingRateUnit != nil {
+			scheduleReq.ChargingRateUnit = v201.ChargingRateUnitType(*req.ChargingRateUnit)
+		}
+
+		resp, _ := m.handleV201GetCompositeSchedule(stationID, scheduleReq)
+		result := &v21.GetCompositeScheduleResponse{Status: string(resp.Status), StatusInfo: resp.StatusInfo}
+		if schedule := resp.Schedule; schedule != nil {
+			result.Schedule = &v21.CompositeScheduleType{
+				EvseId:                 schedule.EvseId,
+				Duration:               schedule.Duration,
+				ScheduleStart:          schedule.ScheduleStart.Format(time.RFC3339),
+				ChargingRateUnit:       string(schedule.ChargingRateUnit),
+				ChargingSchedulePeriod: make([]v21.ChargingSchedulePeriodType, len(schedule.ChargingSchedulePeriod)),
+			}
+			for i, period := range schedule.ChargingSchedulePeriod {
+				result.Schedule.ChargingSchedulePeriod[i] = v21.ChargingSchedulePeriodType{
+					StartPeriod:  period.StartPeriod,
+					Limit:        period.Limit,
+					NumberPhases: period.NumberPhases,
+					PhaseToUse:   period.PhaseToUse,
+				}
+				// Negative limits of the composite schedule discharge the EV
+				if period.Limit < 0 {
+					mode := v21.OperationModeCentralSetpoint
+					result.Schedule.ChargingSchedulePeriod[i].OperationMode = &mode
+				}
+			}
+		}
+		return result, nil
+	}
+
+	// GetLocalListVersion handler
+	m.v21Handler.OnGetLocalListVersion = func(stationID string, req *v21.GetLocalListVersionRequest) (*v21.GetLocalListVersionResponse, error) {
+		resp, _ := m.handleV201GetLocalListVersion(stationID, &v201.GetLocalListVersionRequest{})
+		return &v21.GetLocalListVersionResponse{VersionNumber: resp.VersionNumber}, nil
+	}
+
+	// SendLocalList handler
+	m.v21Handler.OnSendLocalList = func(stationID string, req *v21.SendLocalListRequest) (*v21.SendLocalListResponse, error) {
+		v201Req := &v201.SendLocalListRequest{
+			VersionNumber:          req.VersionNumber,
+			UpdateType:             v201.UpdateType(req.UpdateType),
+			LocalAuthorizationList: make([]v201.AuthorizationData, len(req.LocalAuthorizationList)),
+		}
+		for i, entry := range req.LocalAuthorizationList {
+			v201Req.LocalAuthorizationList[i] = v201.AuthorizationData{IdToken: entry.IdToken, IdTokenInfo: entry.IdTokenInfo}
+		}
+
+		resp, _ := m.handleV201SendLocalList(stationID, v201Req)
+		return &v21.SendLocalListResponse{Status: string(resp.Status), StatusInfo: resp.StatusInfo}, nil
+	}
+
+	// UpdateFirmware handler
+	m.v21Handler.OnUpdateFirmware = func(stationID string, req *v21.UpdateFirmwareRequest) (*v21.UpdateFirmwareResponse, error) {
+		retrieveDate, err := time.Parse(time.RFC3339, req.Firmware.RetrieveDateTime)
+		if err != nil {
+			return &v21.UpdateFirmwareResponse{
+				Status:     string(v201.UpdateFirmwareStatusRejected),
+				StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidRetrieveDateTime", AdditionalInfo: err.Error()},
+			}, nil
+		}
+
+		v201Req := &v201.UpdateFirmwareRequest{
+			Retries:       req.Retries,
+			RetryInterval: req.RetryInterval,
+			RequestId:     req.RequestId,
+			Firmware: v201.Firmware{
+				Location:         req.Firmware.Location,
+				RetrieveDateTime: v201.DateTime{Time: retrieveDate},
+			},
+		}
+		if req.Firmware.InstallDateTime != nil {
+			installDate, err := time.Parse(time.RFC3339, *req.Firmware.InstallDateTime)
+			if err != nil {
+				return &v21.UpdateFirmwareResponse{
+					Status:     string(v201.UpdateFirmwareStatusRejected),
+					StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidInstallDateTime", AdditionalInfo: err.Error()},
+				}, nil
+			}
+			v201Req.Firmware.InstallDateTime = &v201.DateTime{Time: installDate}
+		}
+		if req.Firmware.SigningCertificate != nil {
+			v201Req.Firmware.SigningCertificate = *req.Firmware.SigningCertificate
+		}
+		if req.Firmware.Signature != nil {
+			v201Req.Firmware.Signature = *req.Firmware.Signature
+		}
+
+		resp, _ := m.handleV201UpdateFirmware(stationID, v201Req)
+		return &v21.UpdateFirmwareResponse{Status: string(resp.Status), StatusInfo: resp.StatusInfo}, nil
+	}
+
+	// SetNetworkProfile handler
+	m.v21Handler.OnSetNetworkProfile = func(stationID string, req *v21.SetNetworkProfileRequest) (*v21.SetNetworkProfileResponse, error) {
+		resp, _ := m.handleV201SetNetworkProfile(stationID, &v201.SetNetworkProfileRequest{
+			ConfigurationSlot: req.ConfigurationSlot,
+			ConnectionData:    networkProfileFromV21(req.ConnectionData),
+		})
+		return &v21.SetNetworkProfileResponse{Status: string(resp.Status), StatusInfo: resp.StatusInfo}, nil
+	}
+
+	// GetLog handler
+	m.v21Handler.OnGetLog = func(stationID string, req *v21.GetLogRequest) (*v21.GetLogResponse, error) {
+		v201Req := &v201.GetLogRequest{
+			Log:           v201.LogParameters{RemoteLocation: req.Log.RemoteLocation},
+			LogType:       v201.LogType(req.LogType),
+			RequestId:     req.RequestId,
+			Retries:       req.Retries,
+			RetryInterval: req.RetryInterval,
+		}
+		for _, timestamp := range []struct {
+			value  *string
+			target **v201.DateTime
+		}{
+			{req.Log.OldestTimestamp, &v201Req.Log.OldestTimestamp},
+			{req.Log.LatestTimestamp, &v201Req.Log.LatestTimestamp},
+		} {
+			if timestamp.value == nil {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, *timestamp.value)
+			if err != nil {
+				return &v21.GetLogResponse{
+					Status:     string(v201.LogStatusRejected),
+					StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidTimestamp", AdditionalInfo: err.Error()},
+				}, nil
+			}
+			*timestamp.target = &v201.DateTime{Time: t}
+		}
+
+		resp, _ := m.handleV201GetLog(stationID, v201Req)
+		result := &v21.GetLogResponse{Status: string(resp.Status), StatusInfo: resp.StatusInfo}
+		if resp.Filename != "" {
+			result.Filename = &resp.Filename
+		}
+		return result, nil
+	}
+}
+
+// Helper methods for OCPP 2.1 async notifications
+
+func (m *Manager) sendNotifyCustomerInformation(stationID string, requestId int, data string, tbc bool) {
+	req := &v21.NotifyCustomerInformationRequest{
+		Data:      data,
+		SeqNo:     0,
+		RequestId: requestId,
+		Tbc:       tbc,
+	}
+	_, err := m.v21Handler.SendNotifyCustomerInformation(stationID, req)
+	if err != nil {
+		m.logger.Error("Failed to send NotifyCustomerInformation", "error", err)
+	}
+}
+
+// chargingProfileReports returns the ReportChargingProfiles requests with the installed profiles
+// matching a GetChargingProfiles request, one per EVSE and charging limit source. Profiles set by
+// the CSMS are reported with source CSO, external constraints with source EMS.
+func (m *Manager) chargingProfileReports(station *Station, req *v21.GetChargingProfilesRequest) []*v21.ReportChargingProfilesRequest {
+	sm := station.SessionManager
+
+	evseIDs := []int{0}
+	for _, connector := range sm.GetAllConnectors() {
+		evseIDs = append(evseIDs, connector.ID)
+	}
+	if req.EvseId != nil {
+		evseIDs = []int{*req.EvseId}
+	}
+
+	matches := func(profile v21.ChargingProfileType, source string) bool {
+		criterion := req.ChargingProfile
+		if criterion == nil {
+			return true
+		}
+		if len(criterion.ChargingProfileId) > 0 && !slices.Contains(criterion.ChargingProfileId, profile.ID) {
+			return false
+		}
+		if criterion.ChargingProfilePurpose != nil && *criterion.ChargingProfilePurpose != profile.ChargingProfilePurpose {
+			return false
+		}
+		if criterion.StackLevel != nil && *criterion.StackLevel != profile.StackLevel {
+			return false
+		}
+		return len(criterion.ChargingLimitSource) == 0 || slices.Contains(criterion.ChargingLimitSource, source)
+	}
+
+	var reports []*v21.ReportChargingProfilesRequest
+	for _, evseID := range evseIDs {
+		var transactionID string
+		if connector, err := sm.GetConnector(evseID); err == nil {
+			if tx := connector.GetTransaction(); tx != nil && connector.HasActiveTransaction() {
+				transactionID = tx.StringID
+			}
+		}
+
+		bySource := make(map[string][]v21.ChargingProfileType)
+		for _, stored := range sm.ChargingProfiles().GetProfiles(evseID) {
+			profileTransactionID := ""
+			if stored.ChargingProfilePurpose == v16.ChargingProfilePurposeTxProfile {
+				profileTransactionID = transactionID
+			}
+			profile := chargingProfileToV21(stored, profileTransactionID)
+
+			source := "CSO"
+			if stored.ChargingProfilePurpose == ChargingProfilePurposeExternalConstraints {
+				source = "EMS"
+			}
+			if matches(profile, source) {
+				bySource[source] = append(bySource[source], profile)
+			}
+		}
+
+		for _, source := range []string{"CSO", "EMS"} {
+			if profiles := bySource[source]; len(profiles) > 0 {
+				reports = append(reports, &v21.ReportChargingProfilesRequest{
+					RequestId:           req.RequestId,
+					ChargingLimitSource: source,
+					EvseId:              evseID,
+					ChargingProfile:     profiles,
+				})
+			}
+		}
+	}
+
+	// All but the last report are to be continued
+	for i := range reports {
+		reports[i].Tbc = i < len(reports)-1
+	}
+	return reports
+}
+
+func (m *Manager) sendReportChargingProfiles(stationID string, req *v21.ReportChargingProfilesRequest) {
+	_, err := m.v21Handler.SendReportChargingProfiles(stationID, req)
+	if err != nil {
+		m.logger.Error("Failed to send ReportChargingProfiles", "error", err)
+	}
+}
+
+// setupSessionManagerCallbacks wires up SessionManager callbacks to OCPP message handlers
+func (m *Manager) setupSessionManagerCallbacks(station *Station) {
+	stationID := station.Config.StationID
+
+	// SendStatusNotification - sends status notification to CSMS
+	station.SessionManager.SendStatusNotification = func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error {
+		if station.usesTransactionEvents() {
+			return m.sendV201StatusNotification(station, connectorID, status)
+		}
+
+		req := &v16.StatusNotificationRequest{
+			ConnectorId:     connectorID,
+			Status:          status,
+			ErrorCode:       errorCode,
+			Info:            info,
+			VendorId:        station.Config.Vendor,
+			VendorErrorCode: "",
+		}
+
+		call, err := m.v16Handler.SendStatusNotification(stationID, req)
+		if err != nil {
+			m.logger.Error("Failed to send StatusNotification",
+				"stationId", stationID,
+				"connectorId", connectorID,
+				"error", err,
+			)
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// SendMeterValues - sends meter values to CSMS
+	station.SessionManager.SendMeterValues = func(connectorID int, transactionID *int, meterValues []v16.MeterValue) error {
+		req := &v16.MeterValuesRequest{
+			ConnectorId:   connectorID,
+			TransactionId: transactionID,
+			MeterValue:    meterValues,
+		}
+
+		// Meter values of a transaction are delivered in order with the other transaction messages
+		if transactionID != nil && station.MessageQueue.ShouldQueue() {
+			return station.MessageQueue.Enqueue(string(v16.ActionMeterValues), req, strconv.Itoa(*transactionID))
+		}
+
+		call, err := m.v16Handler.SendMeterValues(stationID, req)
+		if err != nil && transactionID != nil {
+			m.logger.Warn("Failed to send MeterValues, queueing", "stationId", stationID, "error", err)
+			return station.MessageQueue.Enqueue(string(v16.ActionMeterValues), req, strconv.Itoa(*transactionID))
+		}
+		if err != nil {
+			m.logger.Error("Failed to send MeterValues",
+				"stationId", stationID,
+				"connectorId", connectorID,
+				"error", err,
+			)
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// SendAuthorize - sends authorization request to CSMS and waits for response
+	station.SessionManager.SendAuthorize = func(idTag string) (*v16.AuthorizeResponse, error) {
+		req := &v16.AuthorizeRequest{
+			IdTag: idTag,
+		}
+
+		call, err := ocpp.NewCall(string(v16.ActionAuthorize), req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Authorize call: %w", err)
+		}
+
+		pending, err := m.sendCall(station, call, authorizeTimeout)
+		if err != nil {
+			m.logger.Error("Failed to send Authorize",
+				"stationId", stationID,
+				"idTag", idTag,
+				"error", err,
+			)
+			return nil, err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		m.logger.Debug("Waiting for Authorize response from CSMS",
+			"stationId", stationID,
+			"idTag", idTag,
+			"messageId", call.UniqueID,
+		)
+
+		result, err := pending.Wait(m.ctx)
+		if err != nil {
+			m.logger.Error("No Authorize response from CSMS",
+				"stationId", stationID,
+				"idTag", idTag,
+				"error", err,
+			)
+			return nil, fmt.Errorf("authorization failed: %w", err)
+		}
+
+		var resp v16.AuthorizeResponse
+		if err := json.Unmarshal(result.Payload, &resp); err != nil {
+			return nil, fmt.Errorf("invalid Authorize response: %w", err)
+		}
+
+		m.logger.Info("Received real Authorize response",
+			"stationId", stationID,
+			"idTag", idTag,
+			"status", resp.IdTagInfo.Status,
+		)
+		return &resp, nil
+	}
+
+	// SendAuthorizeIdToken - authorizes typed idTokens of OCPP 2.0.1/2.1 stations with the CSMS
+	station.SessionManager.SendAuthorizeIdToken = func(idToken v201.IdToken) (*v201.AuthorizeResponse, error) {
+		return m.sendAuthorize(station, &v201.AuthorizeRequest{IdToken: idToken})
+	}
+
+	// SendAuthorizeContract - authorizes the contract certificate of a Plug & Charge EV
+	station.SessionManager.SendAuthorizeContract = func(idToken v201.IdToken, hashData []v201.OCSPRequestData) (*v201.AuthorizeResponse, error) {
+		return m.sendAuthorize(station, &v201.AuthorizeRequest{IdToken: idToken, Iso15118CertificateHashData: hashData})
+	}
+
+	// SendGet15118EVCertificate - installs and updates contract certificates of Plug & Charge EVs
+	station.SessionManager.SendGet15118EVCertificate = func(req *v201.Get15118EVCertificateRequest) (*v201.Get15118EVCertificateResponse, error) {
+		var resp v201.Get15118EVCertificateResponse
+		if err := m.sendCertificateCall(station, string(v201.ActionGet15118EVCertificate), req, &resp); err != nil {
+			return nil, err
+		}
+
+		m.logger.Info("Received Get15118EVCertificate response",
+			"stationId", stationID,
+			"action", req.Action,
+			"status", resp.Status,
+		)
+		return &resp, nil
+	}
+
+	// SendGetCertificateStatus - obtains the OCSP status of contract certificates
+	station.SessionManager.SendGetCertificateStatus = func(req *v201.GetCertificateStatusRequest) (*v201.GetCertificateStatusResponse, error) {
+		var resp v201.GetCertificateStatusResponse
+		if err := m.sendCertificateCall(station, string(v201.ActionGetCertificateStatus), req, &resp); err != nil {
+			return nil, err
+		}
+
+		m.logger.Info("Received GetCertificateStatus response",
+			"stationId", stationID,
+			"serialNumber", req.OcspRequestData.SerialNumber,
+			"status", resp.Status,
+		)
+		return &resp, nil
+	}
+
+	// SendStartTransaction - sends start transaction request to CSMS
+	station.SessionManager.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
+		req := &v16.StartTransactionRequest{
+			ConnectorId: connectorID,
+			IdTag:       idTag,
+			MeterStart:  meterStart,
+			Timestamp:   v16.DateTime{Time: timestamp},
+		}
+
+		// Reference the reservation this transaction ends
+		if connector, err := station.SessionManager.GetConnector(connectorID); err == nil {
+			if reservation := connector.GetReservation(); reservation != nil {
+				reservationID := reservation.ID
+				req.ReservationId = &reservationID
+			}
+		}
+
+		// The transaction runs with a temporary ID until the CSMS responds with the real one
+		localID := station.MessageQueue.NextTemporaryTransactionID()
+		placeholder := &v16.StartTransactionResponse{
+			TransactionId: localID,
+			IdTagInfo: v16.IdTagInfo{
+				Status: "Accepted",
+			},
+		}
+
+		// Offline transaction, started once the queue is delivered
+		if station.MessageQueue.ShouldQueue() {
+			if err := station.MessageQueue.Enqueue(string(v16.ActionStartTransaction), req, strconv.Itoa(localID)); err != nil {
+				return nil, err
+			}
+			return placeholder, nil
+		}
+
+		call, err := ocpp.NewCall(string(v16.ActionStartTransaction), req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create StartTransaction call: %w", err)
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			m.logger.Warn("Failed to send StartTransaction, queueing",
+				"stationId", stationID,
+				"connectorId", connectorID,
+				"idTag", idTag,
+				"error", err,
+			)
+			if err := station.MessageQueue.Enqueue(string(v16.ActionStartTransaction), req, strconv.Itoa(localID)); err != nil {
+				return nil, err
+			}
+			return placeholder, nil
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		// The response is applied once the local transaction exists
+		go func() {
+			result, err := pending.Wait(m.ctx)
+			if err != nil {
+				m.requeueUnanswered(station, call, strconv.Itoa(localID), err)
+				return
+			}
+			m.handleStartTransactionResponse(stationID, station, result, connectorID, idTag, localID)
+		}()
+
+		return placeholder, nil
+	}
+
+	// SendStopTransaction - sends stop transaction request to CSMS
+	station.SessionManager.SendStopTransaction = func(transactionID int, idTag string, meterStop int, timestamp time.Time, reason v16.Reason) (*v16.StopTransactionResponse, error) {
+		req := &v16.StopTransactionRequest{
+			TransactionId: transactionID,
+			IdTag:         idTag,
+			MeterStop:     meterStop,
+			Timestamp:     v16.DateTime{Time: timestamp},
+			Reason:        reason,
+		}
+
+		// The transaction ends locally regardless of the CSMS response
+		accepted := &v16.StopTransactionResponse{
+			IdTagInfo: &v16.IdTagInfo{
+				Status: "Accepted",
+			},
+		}
+
+		if station.MessageQueue.ShouldQueue() {
+			if err := station.MessageQueue.Enqueue(string(v16.ActionStopTransaction), req, strconv.Itoa(transactionID)); err != nil {
+				return nil, err
+			}
+			return accepted, nil
+		}
+
+		call, err := ocpp.NewCall(string(v16.ActionStopTransaction), req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create StopTransaction call: %w", err)
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			m.logger.Warn("Failed to send StopTransaction, queueing",
+				"stationId", stationID,
+				"transactionId", transactionID,
+				"error", err,
+			)
+			if err := station.MessageQueue.Enqueue(string(v16.ActionStopTransaction), req, strconv.Itoa(transactionID)); err != nil {
+				return nil, err
+			}
+			return accepted, nil
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		go func() {
+			if _, err := pending.Wait(m.ctx); err != nil {
+				m.requeueUnanswered(station, call, strconv.Itoa(transactionID), err)
+			}
+		}()
+
+		return accepted, nil
+	}
+
+	// SendTransactionEvent - sends transaction events of OCPP 2.0.1/2.1 stations to CSMS
+	station.SessionManager.SendTransactionEvent = func(req *v201.TransactionEventRequest) error {
+		transactionID := req.TransactionInfo.TransactionId
+
+		// Messages bound to the transaction are no longer shown once it has ended
+		if req.EventType == v201.TransactionEventEnded && station.DisplayMessages.EndTransaction(transactionID) > 0 {
+			m.updateDisplayMessageCount(station)
+		}
+
+		// Events that occur while offline are flagged and delivered once the queue is replayed
+		if station.MessageQueue.ShouldQueue() {
+			if !station.MessageQueue.IsOnline() {
+				offline := true
+				req.Offline = &offline
+			}
+			return station.MessageQueue.Enqueue(string(v201.ActionTransactionEvent), req, transactionID)
+		}
+
+		call, err := ocpp.NewCall(string(v201.ActionTransactionEvent), req)
+		if err != nil {
+			return fmt.Errorf("failed to create TransactionEvent call: %w", err)
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			m.logger.Warn("Failed to send TransactionEvent, queueing",
+				"stationId", stationID,
+				"transactionId", transactionID,
+				"eventType", req.EventType,
+				"error", err,
+			)
+			offline := true
+			req.Offline = &offline
+			return station.MessageQueue.Enqueue(string(v201.ActionTransactionEvent), req, transactionID)
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		go func() {
+			result, err := pending.Wait(m.ctx)
+			if err != nil {
+				m.requeueUnanswered(station, call, transactionID, err)
+				return
+			}
+			m.handleTransactionEventResponse(stationID, station, req, result)
+		}()
+
+		return nil
+	}
+
+	// SendReservationStatusUpdate - reports expired and removed reservations of OCPP 2.0.1/2.1 stations
+	station.SessionManager.SendReservationStatusUpdate = func(reservationID int, status v201.ReservationUpdateStatusType) error {
+		req := &v201.ReservationStatusUpdateRequest{
+			ReservationId:           reservationID,
+			ReservationUpdateStatus: status,
+		}
+
+		call, err := ocpp.NewCall(string(v201.ActionReservationStatusUpdate), req)
+		if err != nil {
+			return fmt.Errorf("failed to create ReservationStatusUpdate call: %w", err)
+		}
+
+		if _, err := m.sendCall(station, call, 0); err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// SendNotifyEVChargingNeeds - advertises the charging needs of ISO 15118 and V2X EVs
+	station.SessionManager.SendNotifyEVChargingNeeds = func(req *v21.NotifyEVChargingNeedsRequest) error {
+		call, err := ocpp.NewCall(string(v21.ActionNotifyEVChargingNeeds), req)
+		if err != nil {
+			return fmt.Errorf("failed to create NotifyEVChargingNeeds call: %w", err)
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		go func() {
+			result, err := pending.Wait(m.ctx)
+			if err != nil {
+				m.logger.Warn("NotifyEVChargingNeeds not answered", "stationId", stationID, "evseId", req.EvseId, "error", err)
+				return
+			}
+			var resp v21.NotifyEVChargingNeedsResponse
+			if err := json.Unmarshal(result.Payload, &resp); err != nil {
+				m.logger.Error("Failed to unmarshal NotifyEVChargingNeeds response", "stationId", stationID, "error", err)
+				return
+			}
+			m.logger.Info("NotifyEVChargingNeeds response received",
+				"stationId", stationID,
+				"evseId", req.EvseId,
+				"status", resp.Status,
+			)
+		}()
+
+		return nil
+	}
+
+	// SendNotifyEVChargingSchedule - reports the charging schedules selected by ISO 15118 EVs
+	station.SessionManager.SendNotifyEVChargingSchedule = func(req *v21.NotifyEVChargingScheduleRequest) error {
+		call, err := ocpp.NewCall(string(v21.ActionNotifyEVChargingSchedule), req)
+		if err != nil {
+			return fmt.Errorf("failed to create NotifyEVChargingSchedule call: %w", err)
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		go func() {
+			result, err := pending.Wait(m.ctx)
+			if err != nil {
+				m.logger.Warn("NotifyEVChargingSchedule not answered", "stationId", stationID, "evseId", req.EvseId, "error", err)
+				return
+			}
+			var resp v21.NotifyEVChargingScheduleResponse
+			if err := json.Unmarshal(result.Payload, &resp); err != nil {
+				m.logger.Error("Failed to unmarshal NotifyEVChargingSchedule response", "stationId", stationID, "error", err)
+				return
+			}
+			m.logger.Info("NotifyEVChargingSchedule response received",
+				"stationId", stationID,
+				"evseId", req.EvseId,
+				"status", resp.Status,
+			)
+		}()
+
+		return nil
+	}
+
+	// UpdateEVSEVariable - evaluates the variable monitors of simulated EVSE values
+	station.SessionManager.UpdateEVSEVariable = func(connectorID int, variable, value string) {
+		m.updateEVSEVariable(station, connectorID, variable, value)
+	}
+}
+
+// sendAuthorize sends an OCPP 2.0.1/2.1 Authorize request and waits for the CSMS response.
+// OCPP 2.1 responses carry the tariff of the driver.
+func (m *Manager) sendAuthorize(station *Station, req *v201.AuthorizeRequest) (*v201.AuthorizeResponse, error) {
+	stationID := station.Config.StationID
+	idToken := req.IdToken
+
+	call, err := ocpp.NewCall(string(v201.ActionAuthorize), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Authorize call: %w", err)
+	}
+
+	pending, err := m.sendCall(station, call, authorizeTimeout)
+	if err != nil {
+		m.logger.Error("Failed to send Authorize",
+			"stationId", stationID,
+			"idToken", idToken.IdToken,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	// Store sent message
+	go m.storeMessage(stationID, "sent", call)
+
+	result, err := pending.Wait(m.ctx)
+	if err != nil {
+		m.logger.Error("No Authorize response from CSMS",
+			"stationId", stationID,
+			"idToken", idToken.IdToken,
+			"error", err,
+		)
+		return nil, fmt.Errorf("authorization failed: %w", err)
+	}
+
+	// OCPP 2.1 responses carry the tariff of the driver
+	var resp v21.AuthorizeResponse
+	if err := json.Unmarshal(result.Payload, &resp); err != nil {
+		return nil, fmt.Errorf("invalid Authorize response: %w", err)
+	}
+
+	m.logger.Info("Received real Authorize response",
+		"stationId", stationID,
+		"idToken", idToken.IdToken,
+		"type", idToken.Type,
+		"status", resp.IdTokenInfo.Status,
+	)
+
+	if ocppVersion(station.Config.ProtocolVersion) == v201.OCPPVersion21 {
+		if err := station.SessionManager.Tariffs().SetDriverTariff(idToken.IdToken, resp.Tariff); err != nil {
+			m.logger.Warn("Ignoring invalid driver tariff",
+				"stationId", stationID,
+				"idToken", idToken.IdToken,
+				"error", err,
+			)
+		}
+	}
+	return &resp.AuthorizeResponse, nil
+}
+
+// sendCertificateCall sends an ISO 15118 certificate request of a Plug & Charge EV to the CSMS
+// and waits for its response
+func (m *Manager) sendCertificateCall(station *Station, action string, req, resp interface{}) error {
+	stationID := station.Config.StationID
+
+	call, err := ocpp.NewCall(action, req)
+	if err != nil {
+		return fmt.Errorf("failed to create %s call: %w", action, err)
+	}
+
+	pending, err := m.sendCall(station, call, 0)
+	if err != nil {
+		m.logger.Error("Failed to send certificate request", "stationId", stationID, "action", action, "error", err)
+		return err
+	}
+
+	// Store sent message
+	go m.storeMessage(stationID, "sent", call)
+
+	result, err := pending.Wait(m.ctx)
+	if err != nil {
+		return fmt.Errorf("no %s response: %w", action, err)
+	}
+
+	if err := json.Unmarshal(result.Payload, resp); err != nil {
+		return fmt.Errorf("invalid %s response: %w", action, err)
+	}
+	return nil
+}
+
+// sendV201StatusNotification sends the OCPP 2.0.1 StatusNotification of a connector
+func (m *Manager) sendV201StatusNotification(station *Station, connectorID int, status v16.ChargePointStatus) error {
+	stationID := station.Config.StationID
+
+	// OCPP 2.0.1 has no station wide connector status
+	if connectorID == 0 {
+		return nil
+	}
+
+	req := &v201.StatusNotificationRequest{
+		Timestamp:       v201.DateTime{Time: time.Now()},
+		ConnectorStatus: connectorStatusV201(status),
+		EvseId:          connectorID,
+		ConnectorId:     1,
+	}
+
+	call, err := m.v201Handler.SendStatusNotification(stationID, req)
+	if err != nil {
+		m.logger.Error("Failed to send StatusNotification",
+			"stationId", stationID,
+			"connectorId", connectorID,
+			"error", err,
+		)
+		return err
+	}
+
+	// Store sent message
+	go m.storeMessage(stationID, "sent", call)
+
+	return nil
+}
+
+// defaultReportItemsPerMessage is the NotifyReport page size when ItemsPerMessage is not set
+const defaultReportItemsPerMessage = 100
+
+// monitoringInterval is how often periodic variable monitors are evaluated
+const monitoringInterval = time.Second
+
+// authorizeTimeout is how long an Authorize request waits for the CSMS response
+var authorizeTimeout = 10 * time.Second
+
+// requeueUnanswered hands a transaction message the CSMS did not answer to the message queue,
+// so it is delivered again with the configured retry policy
+func (m *Manager) requeueUnanswered(station *Station, call *ocpp.Call, transactionID string, err error) {
+	stationID := station.Config.StationID
+
+	if !errors.Is(err, ocpp.ErrCallTimeout) && !errors.Is(err, ocpp.ErrCallCancelled) {
+		m.logger.Error("Transaction message failed",
+			"stationId", stationID,
+			"action", call.Action,
+			"messageId", call.UniqueID,
+			"error", err,
+		)
+		return
+	}
+
+	m.logger.Warn("Transaction message not answered, queueing for retry",
+		"stationId", stationID,
+		"action", call.Action,
+		"messageId", call.UniqueID,
+		"error", err,
+	)
+
+	if err := station.MessageQueue.Enqueue(call.Action, call.Payload, transactionID); err != nil {
+		m.logger.Error("Failed to queue transaction message",
+			"stationId", stationID,
+			"action", call.Action,
+			"error", err,
+		)
+	}
+}
+
+// setupMessageQueueCallbacks wires the message queue of a station to its WebSocket connection
+func (m *Manager) setupMessageQueueCallbacks(station *Station) {
+	station.MessageQueue.Send = func(ctx context.Context, msg QueuedMessage) error {
+		return m.sendQueuedMessage(ctx, station, msg)
+	}
+}
+
+// sendQueuedMessage sends a queued message and waits until the CSMS has responded.
+// Returns an error if the message was not sent, answered with a CallError or not answered in time.
+func (m *Manager) sendQueuedMessage(ctx context.Context, station *Station, msg QueuedMessage) error {
+	stationID := station.Config.StationID
+
+	call, err := ocpp.NewCall(msg.Action, msg.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to create %s call: %w", msg.Action, err)
+	}
+
+	pending, err := m.sendCall(station, call, 0)
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", msg.Action, err)
+	}
+
+	m.logger.Info("Sent queued message",
+		"stationId", stationID,
+		"action", msg.Action,
+		"messageId", call.UniqueID,
+		"queuedAt", msg.QueuedAt,
+	)
+
+	// Store sent message
+	go m.storeMessage(stationID, "sent", call)
+
+	result, err := pending.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Process the response before the next queued message is sent
+	switch msg.Action {
+	case string(v16.ActionStartTransaction):
+		var req v16.StartTransactionRequest
+		if err := json.Unmarshal(msg.Payload, &req); err == nil {
+			localID, _ := strconv.Atoi(msg.TransactionID)
+			m.handleStartTransactionResponse(stationID, station, result, req.ConnectorId, req.IdTag, localID)
+		}
+	case string(v201.ActionTransactionEvent):
+		var req v201.TransactionEventRequest
+		if err := json.Unmarshal(msg.Payload, &req); err == nil {
+			m.handleTransactionEventResponse(stationID, station, &req, result)
+		}
+	}
+
+	return nil
+}
+
+// setupFirmwareManagerCallbacks wires the firmware simulation of a station to the OCPP 1.6 handler
+func (m *Manager) setupFirmwareManagerCallbacks(station *Station) {
+	stationID := station.Config.StationID
+
+	// SendFirmwareStatus - sends firmware status notification to CSMS
+	station.Firmware.SendFirmwareStatus = func(status v16.FirmwareStatus) error {
+		call, err := m.v16Handler.SendFirmwareStatusNotification(stationID, &v16.FirmwareStatusNotificationRequest{Status: status})
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// SendSignedFirmwareStatus - sends signed firmware status notification to CSMS, OCPP 2.0.1/2.1
+	// stations report the status of their UpdateFirmware with FirmwareStatusNotification
+	station.Firmware.SendSignedFirmwareStatus = func(status v16.FirmwareStatus, requestID *int) error {
+		if station.usesTransactionEvents() {
+			call, err := m.v201Handler.SendFirmwareStatusNotification(stationID, &v201.FirmwareStatusNotificationRequest{
+				Status:    v201.FirmwareStatusType(status),
+				RequestId: requestID,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Store sent message
+			go m.storeMessage(stationID, "sent", call)
+
+			return nil
+		}
+
+		call, err := m.v16Handler.SendSignedFirmwareStatusNotification(stationID, &v16.SignedFirmwareStatusNotificationRequest{
+			Status:    status,
+			RequestId: requestID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// SendLogStatus - sends log status notification to CSMS
+	station.Firmware.SendLogStatus = func(status v16.UploadLogStatus, requestID *int) error {
+		if station.usesTransactionEvents() {
+			call, err := m.v201Handler.SendLogStatusNotification(stationID, &v201.LogStatusNotificationRequest{
+				Status:    v201.UploadLogStatusType(status),
+				RequestId: requestID,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Store sent message
+			go m.storeMessage(stationID, "sent", call)
+
+			return nil
+		}
+
+		call, err := m.v16Handler.SendLogStatusNotification(stationID, &v16.LogStatusNotificationRequest{
+			Status:    status,
+			RequestId: requestID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// SendPublishFirmwareStatus - reports the firmware published by an OCPP 2.1 Local Controller
+	station.Firmware.SendPublishFirmwareStatus = func(status v21.PublishFirmwareStatusType, locations []string, requestID int) error {
+		call, err := m.v21Handler.SendPublishFirmwareStatusNotification(stationID, &v21.PublishFirmwareStatusNotificationRequest{
+			Status:    status,
+			Location:  locations,
+			RequestId: &requestID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// OnSecurityEvent - records security events of the firmware update (e.g. invalid signature)
+	station.Firmware.OnSecurityEvent = func(eventType, techInfo string) {
+		station.Security.AddSecurityEvent(eventType, techInfo)
+	}
+
+	// CollectLog - provides the content of logs uploaded with OCPP 2.0.1 GetLog
+	station.Firmware.CollectLog = func(logType v16.LogType, oldest, latest *time.Time) []string {
+		return m.collectLog(station, logType, oldest, latest)
+	}
+
+	// SendDiagnosticsStatus - sends diagnostics status notification to CSMS
+	station.Firmware.SendDiagnosticsStatus = func(status v16.DiagnosticsStatus) error {
+		call, err := m.v16Handler.SendDiagnosticsStatusNotification(stationID, &v16.DiagnosticsStatusNotificationRequest{Status: status})
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+
+	// WaitForIdle - firmware is only installed once all transactions have finished
+	station.Firmware.WaitForIdle = func(ctx context.Context) error {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			if !m.hasActiveTransaction(station) {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+
+	// OnFirmwareInstalled - reboot with the new firmware version
+	station.Firmware.OnFirmwareInstalled = func(version string) {
+		m.applyFirmwareVersion(station, version)
+		station.Security.AddSecurityEvent(v16.SecurityEventFirmwareUpdated, version)
+
+		if err := m.rebootStation(stationID, "firmware update", false); err != nil {
+			m.logger.Error("Failed to reboot station after firmware update", "stationId", stationID, "error", err)
+		}
+	}
+}
+
+// setupSecurityManagerCallbacks wires the security extensions of a station to the OCPP 1.6 handler
+func (m *Manager) setupSecurityManagerCallbacks(station *Station) {
+	stationID := station.Config.StationID
+
+	// SendSecurityEvent - sends security event notification to CSMS
+	station.Security.SendSecurityEvent = func(event SecurityEvent) error {
+		station.mu.RLock()
+		protocolVersion := station.Config.ProtocolVersion
+		station.mu.RUnlock()
+
+		// Security events are only reported by OCPP 1.6 stations
+		if ocppVersion(protocolVersion) != v201.OCPPVersion16 {
+			return nil
+		}
+
+		if !m.connManager.IsConnected(stationID) {
+			return fmt.Errorf("station not connected: %s", stationID)
+		}
+
+		call, err := m.v16Handler.SendSecurityEventNotification(stationID, &v16.SecurityEventNotificationRequest{
+			Type:      event.Type,
+			Timestamp: v16.DateTime{Time: event.Timestamp},
+			TechInfo:  event.TechInfo,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		return nil
+	}
+}
+
+// securityProfileConnection returns the TLS and authentication settings required by a security profile
+// to connect to a CSMS URL (caller must hold the station lock)
+func (m *Manager) securityProfileConnection(station *Station, csmsURL string, profile int) (*connection.TLSConfig, *connection.AuthConfig, error) {
+	secure := strings.HasPrefix(strings.ToLower(csmsURL), "wss://")
+
+	// Basic Authentication uses the station identity and the AuthorizationKey,
+	// falling back to the configured password until the CSMS has set a key
+	basicAuth := func() (*connection.AuthConfig, error) {
+		password := station.Security.AuthorizationKey()
+		if password == "" && station.Config.CSMSAuth != nil && station.Config.CSMSAuth.Type == "basic" {
+			password = station.Config.CSMSAuth.Password
+		}
+		if password == "" {
+			return nil, fmt.Errorf("no AuthorizationKey or basic auth password configured")
+		}
+		return &connection.AuthConfig{
+			Type:     "basic",
+			Username: station.Config.StationID,
+			Password: password,
+		}, nil
+	}
+
+	switch profile {
+	case SecurityProfileBasicAuth:
+		if secure {
+			return nil, nil, fmt.Errorf("unsecured ws:// CSMS URL required")
+		}
+		auth, err := basicAuth()
+		return nil, auth, err
+
+	case SecurityProfileTLSBasicAuth:
+		if !secure {
+			return nil, nil, fmt.Errorf("wss:// CSMS URL required")
+		}
+		auth, err := basicAuth()
+		if err != nil {
+			return nil, nil, err
+		}
+		return &connection.TLSConfig{Enabled: true, RootCAs: station.Security.RootCAs()}, auth, nil
+
+	case SecurityProfileTLSClientCert:
+		if !secure {
+			return nil, nil, fmt.Errorf("wss:// CSMS URL required")
+		}
+		tlsConfig := &connection.TLSConfig{Enabled: true, RootCAs: station.Security.RootCAs()}
+		cert, err := station.Security.ClientCertificate()
+		if err != nil {
+			// The client certificate configured for the CSMS connection is used instead
+			m.logger.Warn("No charge point certificate installed", "stationId", station.Config.StationID, "error", err)
+		} else {
+			tlsConfig.Certificates = []tls.Certificate{*cert}
+		}
+		return tlsConfig, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown security profile")
+	}
+}
+
+// endpointConnection returns the TLS and authentication settings to connect to a CSMS URL. Without
+// a security profile the connection settings of the station are used as configured
+// (caller must hold the station lock).
+func (m *Manager) endpointConnection(station *Station, csmsURL string, profile int) (*connection.TLSConfig, *connection.AuthConfig, error) {
+	if profile != SecurityProfileNone {
+		return m.securityProfileConnection(station, csmsURL, profile)
+	}
+
+	if station.Config.CSMSAuth == nil {
+		return nil, nil, nil
+	}
+	auth := *station.Config.CSMSAuth
+	switch auth.Type {
+	case "basic":
+		return nil, &connection.AuthConfig{
+			Type:     "basic",
+			Username: auth.Username,
+			Password: auth.Password,
+		}, nil
+	case "bearer":
+		return nil, &connection.AuthConfig{
+			Type:  "bearer",
+			Token: auth.Token,
+		}, nil
+	}
+	return nil, nil, nil
+}
+
+// networkEndpoints returns the CSMS endpoints of a station in the order of NetworkConfigurationPriority.
+// Slots the station cannot connect with are skipped (caller must hold the station lock).
+func (m *Manager) networkEndpoints(station *Station, securityProfile int) ([]connection.Endpoint, error) {
+	slots := []int{defaultNetworkSlot}
+	if station.DeviceModel != nil {
+		value, _ := station.DeviceModel.GetVariable("OCPPCommCtrlr", "", "NetworkConfigurationPriority", "", v201.AttributeActual)
+		if priority, err := parseNetworkPriority(value); err == nil {
+			slots = priority
+		}
+	}
+
+	var endpoints []connection.Endpoint
+	var lastErr error
+	for _, slot := range slots {
+		profile, ok := networkProfile(station.Config, securityProfile, slot)
+		if !ok {
+			lastErr = fmt.Errorf("no network connection profile in slot %d", slot)
+			m.logger.Warn("Skipping network configuration slot", "stationId", station.Config.StationID, "slot", slot, "error", lastErr)
+			continue
+		}
+
+		tlsConfig, auth, err := m.endpointConnection(station, profile.OcppCsmsUrl, profile.SecurityProfile)
+		if err != nil {
+			lastErr = fmt.Errorf("security profile %d: %w", profile.SecurityProfile, err)
+			m.logger.Warn("Skipping network configuration slot", "stationId", station.Config.StationID, "slot", slot, "error", lastErr)
+			continue
+		}
+
+		endpoints = append(endpoints, connection.Endpoint{
+			Slot: slot,
+			URL:  profile.OcppCsmsUrl,
+			TLS:  tlsConfig,
+			Auth: auth,
+		})
+	}
+
+	if len(endpoints) == 0 {
+		return nil, lastErr
+	}
+	return endpoints, nil
+}
+
+// networkSlotsConfigured reports whether every configuration slot of a NetworkConfigurationPriority
+// value holds a network connection profile
+func (m *Manager) networkSlotsConfigured(station *Station, priority string) bool {
+	slots, err := parseNetworkPriority(priority)
+	if err != nil {
+		return false
+	}
+
+	station.mu.RLock()
+	defer station.mu.RUnlock()
+
+	for _, slot := range slots {
+		if _, ok := networkProfile(station.Config, SecurityProfileNone, slot); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// offlineThreshold returns how long a station stays offline before failing over to the next
+// network configuration slot, 0 if it never fails over
+func (m *Manager) offlineThreshold(station *Station) time.Duration {
+	if station.DeviceModel == nil {
+		return 0
+	}
+
+	value, _ := station.DeviceModel.GetVariable("OCPPCommCtrlr", "", "OfflineThreshold", "", v201.AttributeActual)
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// applySecurityProfile updates the security profile of the station configuration and persists it
+func (m *Manager) applySecurityProfile(station *Station, profile int) {
+	station.mu.Lock()
+	station.Config.SecurityProfile = profile
+	station.Config.UpdatedAt = time.Now()
+	stationID := station.Config.StationID
+	station.mu.Unlock()
+
+	if m.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.saveStationToDB(ctx, station); err != nil {
+		m.logger.Error("Failed to persist security profile", "stationId", stationID, "error", err)
+	}
+}
+
+// applyNetworkProfile stores the network connection profile of a configuration slot and persists it
+func (m *Manager) applyNetworkProfile(station *Station, slot int, profile v201.NetworkConnectionProfile) {
+	station.mu.Lock()
+	station.Config.NetworkProfiles = setNetworkProfile(station.Config.NetworkProfiles, slot, profile)
+	station.Config.UpdatedAt = time.Now()
+	stationID := station.Config.StationID
+	station.mu.Unlock()
+
+	if m.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.saveStationToDB(ctx, station); err != nil {
+		m.logger.Error("Failed to persist network profile", "stationId", stationID, "slot", slot, "error", err)
+	}
+}
+
+// reconnectStation reconnects a station to apply new connection security settings.
+// If the station cannot connect with a new security profile it falls back to the previous one.
+func (m *Manager) reconnectStation(stationID string, previousProfile int) {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	m.logger.Info("Reconnecting station", "stationId", stationID, "securityProfile", station.Security.Profile())
+
+	m.stopHeartbeat(station)
+	m.stopMonitoring(station)
+
+	if err := m.StopStation(m.ctx, stationID); err != nil {
+		m.logger.Error("Failed to stop station for reconnect", "stationId", stationID, "error", err)
+		return
+	}
+
+	err := m.StartStation(m.ctx, stationID)
+	if err == nil {
+		return
+	}
+
+	m.logger.Warn("Failed to reconnect station", "stationId", stationID, "error", err)
+
+	if station.Security.Profile() == previousProfile {
+		return
+	}
+
+	m.logger.Info("Falling back to previous security profile", "stationId", stationID, "securityProfile", previousProfile)
+	station.Security.SetProfile(previousProfile)
+	m.applySecurityProfile(station, previousProfile)
+
+	if err := m.StartStation(m.ctx, stationID); err != nil {
+		m.logger.Error("Failed to reconnect station with previous security profile", "stationId", stationID, "error", err)
+	}
+}
+
+// setupConfigurationCallbacks applies configuration changes to the running station and persists them
+func (m *Manager) setupConfigurationCallbacks(station *Station) {
+	stationID := station.Config.StationID
+
+	station.Configuration.OnChange = func(key, value string) {
+		switch {
+		case key == ConfigKeyHeartbeatInterval:
+			// Restart a running heartbeat once the response has been sent
+			station.mu.RLock()
+			running := station.heartbeatCancel != nil
+			station.mu.RUnlock()
+
+			if running {
+				interval := station.Configuration.Int(ConfigKeyHeartbeatInterval)
+				m.afterResponse(station, func() { m.startHeartbeat(stationID, station, interval) })
+			}
+
+		case key == ConfigKeyMeterValueSampleInterval || key == ConfigKeyMeterValuesSampledData:
+			if station.SessionManager != nil {
+				station.SessionManager.SetMeterValueConfig(
+					station.Configuration.Int(ConfigKeyMeterValueSampleInterval),
+					station.Configuration.Measurands(ConfigKeyMeterValuesSampledData),
+				)
+			}
+
+		case IsAuthorizationKey(key):
+			if station.SessionManager != nil {
+				station.SessionManager.Authorization().SetConfig(station.Configuration.AuthorizationConfig())
+			}
+
+		case key == ConfigKeyTransactionMessageAttempts || key == ConfigKeyTransactionMessageRetryInterval:
+			station.MessageQueue.SetRetryPolicy(
+				station.Configuration.Int(ConfigKeyTransactionMessageAttempts),
+				time.Duration(station.Configuration.Int(ConfigKeyTransactionMessageRetryInterval))*time.Second,
+			)
+		}
+
+		m.persistConfiguration(station)
+	}
+}
+
+// persistConfiguration saves the configuration keys of a station to MongoDB
+func (m *Manager) persistConfiguration(station *Station) {
+	station.mu.Lock()
+	station.Config.OCPPConfiguration = station.Configuration.Values()
+	station.Config.UpdatedAt = time.Now()
+	stationID := station.Config.StationID
+	station.mu.Unlock()
+
+	if m.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.saveStationToDB(ctx, station); err != nil {
+		m.logger.Error("Failed to persist configuration", "stationId", stationID, "error", err)
+	}
+}
+
+// persistDeviceModel saves the persistent device model variables of a station to MongoDB
+func (m *Manager) persistDeviceModel(station *Station) {
+	station.mu.Lock()
+	station.Config.DeviceModelValues = station.DeviceModel.PersistentValues()
+	station.Config.UpdatedAt = time.Now()
+	stationID := station.Config.StationID
+	station.mu.Unlock()
+
+	if m.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.saveStationToDB(ctx, station); err != nil {
+		m.logger.Error("Failed to persist device model", "stationId", stationID, "error", err)
+	}
+}
+
+// restoreDeviceModel applies the device model profile and the persisted variable values of a
+// station configuration
+func (m *Manager) restoreDeviceModel(deviceModel *v201.DeviceModel, config Config) {
+	if config.DeviceModelProfile != nil {
+		if err := deviceModel.ImportProfile(config.DeviceModelProfile); err != nil {
+			m.logger.Warn("Failed to apply device model profile", "stationId", config.StationID, "error", err)
+		}
+	}
+
+	for _, err := range deviceModel.RestoreValues(config.DeviceModelValues) {
+		m.logger.Warn("Failed to restore device model value", "stationId", config.StationID, "error", err)
+	}
+}
+
+// hasActiveTransaction reports whether any connector of the station has an active transaction
+func (m *Manager) hasActiveTransaction(station *Station) bool {
+	if station.SessionManager == nil {
+		return false
+	}
+
+	for _, connector := range station.SessionManager.GetAllConnectors() {
+		if connector.HasActiveTransaction() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyFirmwareVersion updates the firmware version reported by the station and persists it
+func (m *Manager) applyFirmwareVersion(station *Station, version string) {
+	if version == "" {
+		return
+	}
+
+	station.mu.Lock()
+	station.Config.FirmwareVersion = version
+	station.Config.UpdatedAt = time.Now()
+	config := station.Config
+	station.mu.Unlock()
+
+	station.DeviceModel.UpdateStationInfo(config.Vendor, config.Model, config.SerialNumber, version)
+
+	if m.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.saveStationToDB(ctx, station); err != nil {
+		m.logger.Error("Failed to persist firmware version", "stationId", config.StationID, "error", err)
+	}
+}
+
+// rebootStation disconnects a station and reconnects it after its boot delay.
+// The reconnect sends a new BootNotification.
+func (m *Manager) rebootStation(stationID, reason string, hard bool) error {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("station not found: %s", stationID)
+	}
+
+	m.logger.Info("Rebooting station", "stationId", stationID, "reason", reason, "hard", hard)
+
+	station.Security.AddSecurityEvent(v16.SecurityEventResetOrReboot, reason)
+
+	m.stopHeartbeat(station)
+	m.stopMonitoring(station)
+
+	if err := m.stopStation(stationID, reason, hard); err != nil {
+		return fmt.Errorf("failed to stop station: %w", err)
+	}
+
+	station.mu.RLock()
+	bootDelay := time.Duration(station.Config.Simulation.BootDelay) * time.Second
+	station.mu.RUnlock()
+
+	select {
+	case <-time.After(bootDelay):
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.startStation(m.ctx, stationID, "reboot after "+reason)
+}
+
+// resetStation performs a reset requested by the CSMS. A soft reset stops the active
+// transactions gracefully before closing the connection, a hard reset drops the
+// connection abruptly, losing unsent messages. The station boots again after the boot delay.
+func (m *Manager) resetStation(stationID string, hard bool, stopReason v16.Reason) {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	reason := "soft reset"
+	if hard {
+		reason = "hard reset"
+	}
+
+	station.mu.Lock()
+	station.StateMachine.SetState(StateResetting, reason+" requested")
+	station.RuntimeState.State = StateResetting
+	station.bootReason = v201.BootReasonRemoteReset
+	station.resetScheduled = false
+	station.mu.Unlock()
+
+	if station.SessionManager != nil {
+		if hard {
+			if aborted := station.SessionManager.AbortTransactions(stopReason); aborted > 0 {
+				m.logger.Warn("Transactions aborted by hard reset", "stationId", stationID, "count", aborted)
+			}
+		} else {
+			station.SessionManager.StopAllTransactions(stopReason)
+		}
+	}
+
+	// Responses to requests sent before a hard reset are never processed
+	if hard {
+		m.requestTracker(station).Reset(nil)
+	}
+
+	if err := m.rebootStation(stationID, reason, hard); err != nil {
+		m.logger.Error("Failed to reset station", "stationId", stationID, "reason", reason, "error", err)
+	}
+}
+
+// idleResetPollInterval is how often a scheduled OnIdle reset checks for active transactions
+var idleResetPollInterval = time.Second
+
+// scheduleIdleReset resets a station once all of its transactions have finished
+func (m *Manager) scheduleIdleReset(stationID string, station *Station) {
+	station.mu.Lock()
+	if station.resetScheduled {
+		station.mu.Unlock()
+		return
+	}
+	station.resetScheduled = true
+	station.mu.Unlock()
+
+	m.logger.Info("Reset scheduled until transactions have finished", "stationId", stationID)
+
+	go func() {
+		ticker := time.NewTicker(idleResetPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			station.mu.RLock()
+			scheduled := station.resetScheduled
+			station.mu.RUnlock()
+
+			// Cancelled or superseded by an immediate reset
+			if !scheduled {
+				return
+			}
+
+			if !m.hasActiveTransaction(station) {
+				m.resetStation(stationID, false, v16.ReasonSoftReset)
+				return
+			}
+		}
+	}()
+}
+
+// handleV201Reset handles Reset requests of OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201Reset(stationID string, req *v201.ResetRequest) (*v201.ResetResponse, error) {
+	m.logger.Info("Handling Reset (2.0.1)", "stationId", stationID, "type", req.Type, "evseId", req.EvseId)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	// Resetting a single EVSE is not supported
+	if !exists || req.EvseId != nil {
+		return &v201.ResetResponse{Status: v201.ResetStatusRejected}, nil
+	}
+
+	switch req.Type {
+	case v201.ResetImmediate:
+		m.afterResponse(station, func() { m.resetStation(stationID, false, v16.ReasonSoftReset) })
+		return &v201.ResetResponse{Status: v201.ResetStatusAccepted}, nil
+
+	case v201.ResetOnIdle:
+		if m.hasActiveTransaction(station) {
+			m.scheduleIdleReset(stationID, station)
+			return &v201.ResetResponse{Status: v201.ResetStatusScheduled}, nil
+		}
+		m.afterResponse(station, func() { m.resetStation(stationID, false, v16.ReasonSoftReset) })
+		return &v201.ResetResponse{Status: v201.ResetStatusAccepted}, nil
+	}
+
+	return &v201.ResetResponse{Status: v201.ResetStatusRejected}, nil
+}
+
+// handleV201SetNetworkProfile stores the network connection profile of a configuration slot
+// (OCPP 2.0.1 and 2.1). The station connects through the slot once NetworkConfigurationPriority
+// lists it and the station reconnects.
+func (m *Manager) handleV201SetNetworkProfile(stationID string, req *v201.SetNetworkProfileRequest) (*v201.SetNetworkProfileResponse, error) {
+	m.logger.Info("Handling SetNetworkProfile (2.0.1)", "stationId", stationID, "slot", req.ConfigurationSlot, "url", req.ConnectionData.OcppCsmsUrl)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		return &v201.SetNetworkProfileResponse{Status: v201.SetNetworkProfileStatusRejected}, nil
+	}
+
+	rejected := func(reasonCode string, err error) (*v201.SetNetworkProfileResponse, error) {
+		m.logger.Warn("Network profile rejected", "stationId", stationID, "slot", req.ConfigurationSlot, "reason", reasonCode, "error", err)
+		return &v201.SetNetworkProfileResponse{
+			Status:     v201.SetNetworkProfileStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: reasonCode, AdditionalInfo: err.Error()},
+		}, nil
+	}
+
+	// The slots are the values of NetworkConfigurationPriority
+	characteristics, _ := station.DeviceModel.GetCharacteristics("OCPPCommCtrlr", "", "NetworkConfigurationPriority", "")
+	if err := characteristics.Validate(strconv.Itoa(req.ConfigurationSlot)); err != nil {
+		return rejected("InvalidConfSlot", err)
+	}
+
+	profile := req.ConnectionData
+
+	station.mu.RLock()
+	protocolVersion := station.Config.ProtocolVersion
+	activeSlot := station.RuntimeState.NetworkSlot
+	station.mu.RUnlock()
+
+	// The profile of the current connection cannot be replaced
+	if req.ConfigurationSlot == activeSlot {
+		return rejected("InvalidConfSlot", fmt.Errorf("slot %d is in use", activeSlot))
+	}
+	if err := validateCSMSURL(profile.OcppCsmsUrl); err != nil {
+		return rejected("InvalidURL", err)
+	}
+	if err := validateNetworkProfile(profile, protocolVersion); err != nil {
+		return rejected("InvalidProfile", err)
+	}
+	if active := station.Security.Profile(); profile.SecurityProfile < active {
+		return rejected("NoSecurityDowngrade", fmt.Errorf("security profile %d is lower than the active security profile %d", profile.SecurityProfile, active))
+	}
+
+	station.mu.RLock()
+	_, _, err := m.endpointConnection(station, profile.OcppCsmsUrl, profile.SecurityProfile)
+	station.mu.RUnlock()
+	if err != nil {
+		return rejected("InvalidProfile", err)
+	}
+
+	m.applyNetworkProfile(station, req.ConfigurationSlot, profile)
+	return &v201.SetNetworkProfileResponse{Status: v201.SetNetworkProfileStatusAccepted}, nil
+}
+
+// handleV201GetTransactionStatus reports whether a transaction is ongoing and whether its messages
+// still wait in the message queue (OCPP 2.0.1 and 2.1)
+func (m *Manager) handleV201GetTransactionStatus(stationID string, req *v201.GetTransactionStatusRequest) (*v201.GetTransactionStatusResponse, error) {
+	m.logger.Info("Handling GetTransactionStatus (2.0.1)", "stationId", stationID, "transactionId", req.TransactionId)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return &v201.GetTransactionStatusResponse{MessagesInQueue: false}, nil
+	}
+
+	// Check if transaction is ongoing
+	ongoing := false
+	if req.TransactionId != "" {
+		connectors := station.SessionManager.GetAllConnectors()
+		for _, connector := range connectors {
+			tx := connector.GetTransaction()
+			if tx != nil && tx.StringID == req.TransactionId {
+				ongoing = true
+				break
+			}
+		}
+	}
+
+	// Without a transaction ID, report whether any message is queued
+	messagesInQueue := false
+	if station.MessageQueue != nil {
+		messagesInQueue = station.MessageQueue.HasMessages(req.TransactionId)
+	}
+
+	response := &v201.GetTransactionStatusResponse{MessagesInQueue: messagesInQueue}
+	if req.TransactionId != "" {
+		response.OngoingIndicator = &ongoing
+	}
+
+	return response, nil
+}
+
+// handleV201GetBaseReport handles GetBaseReport for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201GetBaseReport(stationID string, req *v201.GetBaseReportRequest) (*v201.GetBaseReportResponse, error) {
+	m.logger.Info("Handling GetBaseReport (2.0.1)", "stationId", stationID, "requestId", req.RequestId, "reportBase", req.ReportBase)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		return &v201.GetBaseReportResponse{Status: v201.GenericDeviceModelStatusRejected}, nil
+	}
+
+	reportData, supported := station.DeviceModel.BaseReport(req.ReportBase)
+	if !supported {
+		return &v201.GetBaseReportResponse{Status: v201.GenericDeviceModelStatusNotSupported}, nil
+	}
+	if len(reportData) == 0 {
+		return &v201.GetBaseReportResponse{Status: v201.GenericDeviceModelStatusEmptyResultSet}, nil
+	}
+
+	requestID := req.RequestId
+	m.afterResponse(station, func() { m.sendReport(station, requestID, reportData) })
+
+	return &v201.GetBaseReportResponse{Status: v201.GenericDeviceModelStatusAccepted}, nil
+}
+
+// handleV201GetReport handles GetReport for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201GetReport(stationID string, req *v201.GetReportRequest) (*v201.GetReportResponse, error) {
+	m.logger.Info("Handling GetReport (2.0.1)",
+		"stationId", stationID,
+		"requestId", req.RequestId,
+		"componentVariables", len(req.ComponentVariable),
+		"componentCriteria", req.ComponentCriteria,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		return &v201.GetReportResponse{Status: v201.GenericDeviceModelStatusRejected}, nil
+	}
+
+	for _, criterion := range req.ComponentCriteria {
+		switch criterion {
+		case v201.ComponentCriterionActive, v201.ComponentCriterionAvailable, v201.ComponentCriterionEnabled, v201.ComponentCriterionProblem:
+		default:
+			return &v201.GetReportResponse{Status: v201.GenericDeviceModelStatusNotSupported}, nil
+		}
+	}
+
+	reportData := station.DeviceModel.Report(req.ComponentVariable, req.ComponentCriteria)
+	if len(reportData) == 0 {
+		return &v201.GetReportResponse{Status: v201.GenericDeviceModelStatusEmptyResultSet}, nil
+	}
+
+	requestID := req.RequestId
+	m.afterResponse(station, func() { m.sendReport(station, requestID, reportData) })
+
+	return &v201.GetReportResponse{Status: v201.GenericDeviceModelStatusAccepted}, nil
+}
+
+// sendReport streams a device model report to the CSMS as NotifyReport messages of at most
+// ItemsPerMessage entries. Each part waits for the response to the previous one.
+func (m *Manager) sendReport(station *Station, requestID int, reportData []v201.ReportData) {
+	stationID := station.Config.StationID
+
+	for _, req := range reportParts(requestID, reportData, m.itemsPerMessage(station), time.Now()) {
+		call, err := ocpp.NewCall(string(v201.ActionNotifyReport), req)
+		if err != nil {
+			m.logger.Error("Failed to create NotifyReport", "stationId", stationID, "error", err)
+			return
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			m.logger.Error("Failed to send NotifyReport",
+				"stationId", stationID,
+				"requestId", requestID,
+				"seqNo", req.SeqNo,
+				"error", err,
+			)
+			return
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		if _, err := pending.Wait(m.ctx); err != nil {
+			m.logger.Error("NotifyReport not accepted, aborting report",
+				"stationId", stationID,
+				"requestId", requestID,
+				"seqNo", req.SeqNo,
+				"error", err,
+			)
+			return
+		}
+	}
+
+	m.logger.Info("Device model report sent",
+		"stationId", stationID,
+		"requestId", requestID,
+		"items", len(reportData),
+	)
+}
+
+// reportParts splits report data into NotifyReport requests of at most itemsPerMessage entries,
+// numbered by seqNo and flagged tbc (to be continued) on all but the last part
+func reportParts(requestID int, reportData []v201.ReportData, itemsPerMessage int, generatedAt time.Time) []*v201.NotifyReportRequest {
+	if itemsPerMessage <= 0 {
+		itemsPerMessage = defaultReportItemsPerMessage
+	}
+
+	var parts []*v201.NotifyReportRequest
+	for start := 0; start < len(reportData); start += itemsPerMessage {
+		end := start + itemsPerMessage
+		if end > len(reportData) {
+			end = len(reportData)
+		}
+
+		parts = append(parts, &v201.NotifyReportRequest{
+			RequestId:   requestID,
+			GeneratedAt: v201.DateTime{Time: generatedAt},
+			Tbc:         end < len(reportData),
+			SeqNo:       len(parts),
+			ReportData:  reportData[start:end],
+		})
+	}
+	return parts
+}
+
+// handleV201SetVariableMonitoring handles SetVariableMonitoring for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201SetVariableMonitoring(stationID string, req *v201.SetVariableMonitoringRequest) (*v201.SetVariableMonitoringResponse, error) {
+	m.logger.Info("Handling SetVariableMonitoring (2.0.1)", "stationId", stationID, "monitors", len(req.SetMonitoringData))
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		results := make([]v201.SetMonitoringResult, 0, len(req.SetMonitoringData))
+		for _, data := range req.SetMonitoringData {
+			results = append(results, v201.SetMonitoringResult{
+				Id:        data.Id,
+				Status:    v201.SetMonitoringStatusRejected,
+				Type:      data.Type,
+				Severity:  data.Severity,
+				Component: data.Component,
+				Variable:  data.Variable,
+			})
+		}
+		return &v201.SetVariableMonitoringResponse{SetMonitoringResult: results}, nil
+	}
+
+	results := station.DeviceModel.SetVariableMonitoring(req.SetMonitoringData)
+	for _, result := range results {
+		m.logger.Debug("Variable monitor set",
+			"stationId", stationID,
+			"component", result.Component.Name,
+			"variable", result.Variable.Name,
+			"type", result.Type,
+			"severity", result.Severity,
+			"status", result.Status,
+		)
+	}
+
+	return &v201.SetVariableMonitoringResponse{SetMonitoringResult: results}, nil
+}
+
+// handleV201SetMonitoringBase handles SetMonitoringBase for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201SetMonitoringBase(stationID string, req *v201.SetMonitoringBaseRequest) (*v201.SetMonitoringBaseResponse, error) {
+	m.logger.Info("Handling SetMonitoringBase (2.0.1)", "stationId", stationID, "monitoringBase", req.MonitoringBase)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		return &v201.SetMonitoringBaseResponse{Status: v201.GenericDeviceModelStatusRejected}, nil
+	}
+
+	return &v201.SetMonitoringBaseResponse{Status: station.DeviceModel.SetMonitoringBase(req.MonitoringBase)}, nil
+}
+
+// handleV201SetMonitoringLevel handles SetMonitoringLevel for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201SetMonitoringLevel(stationID string, req *v201.SetMonitoringLevelRequest) (*v201.SetMonitoringLevelResponse, error) {
+	m.logger.Info("Handling SetMonitoringLevel (2.0.1)", "stationId", stationID, "severity", req.Severity)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil || !station.DeviceModel.SetMonitoringLevel(req.Severity) {
+		return &v201.SetMonitoringLevelResponse{Status: v201.GenericStatusRejected}, nil
+	}
+
+	return &v201.SetMonitoringLevelResponse{Status: v201.GenericStatusAccepted}, nil
+}
+
+// handleV201ClearVariableMonitoring handles ClearVariableMonitoring for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201ClearVariableMonitoring(stationID string, req *v201.ClearVariableMonitoringRequest) (*v201.ClearVariableMonitoringResponse, error) {
+	m.logger.Info("Handling ClearVariableMonitoring (2.0.1)", "stationId", stationID, "ids", req.Id)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		results := make([]v201.ClearMonitoringResult, 0, len(req.Id))
+		for _, id := range req.Id {
+			results = append(results, v201.ClearMonitoringResult{Id: id, Status: v201.ClearMonitoringStatusRejected})
+		}
+		return &v201.ClearVariableMonitoringResponse{ClearMonitoringResult: results}, nil
+	}
+
+	return &v201.ClearVariableMonitoringResponse{ClearMonitoringResult: station.DeviceModel.ClearVariableMonitoring(req.Id)}, nil
+}
+
+// handleV201GetMonitoringReport handles GetMonitoringReport for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201GetMonitoringReport(stationID string, req *v201.GetMonitoringReportRequest) (*v201.GetMonitoringReportResponse, error) {
+	m.logger.Info("Handling GetMonitoringReport (2.0.1)",
+		"stationId", stationID,
+		"requestId", req.RequestId,
+		"componentVariables", len(req.ComponentVariable),
+		"monitoringCriteria", req.MonitoringCriteria,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		return &v201.GetMonitoringReportResponse{Status: v201.GenericDeviceModelStatusRejected}, nil
+	}
+
+	for _, criterion := range req.MonitoringCriteria {
+		switch criterion {
+		case v201.MonitoringCriterionThreshold, v201.MonitoringCriterionDelta, v201.MonitoringCriterionPeriodic:
+		default:
+			return &v201.GetMonitoringReportResponse{Status: v201.GenericDeviceModelStatusNotSupported}, nil
+		}
+	}
+
+	monitors := station.DeviceModel.MonitoringReport(req.ComponentVariable, req.MonitoringCriteria)
+	if len(monitors) == 0 {
+		return &v201.GetMonitoringReportResponse{Status: v201.GenericDeviceModelStatusEmptyResultSet}, nil
+	}
+
+	requestID := req.RequestId
+	m.afterResponse(station, func() { m.sendMonitoringReport(station, requestID, monitors) })
+
+	return &v201.GetMonitoringReportResponse{Status: v201.GenericDeviceModelStatusAccepted}, nil
+}
+
+// sendMonitoringReport streams the monitors of a station to the CSMS as NotifyMonitoringReport
+// messages of at most ItemsPerMessage entries
+func (m *Manager) sendMonitoringReport(station *Station, requestID int, monitors []v201.MonitoringData) {
+	stationID := station.Config.StationID
+	itemsPerMessage := m.itemsPerMessage(station)
+	generatedAt := v201.DateTime{Time: time.Now()}
+
+	for seqNo, start := 0, 0; start < len(monitors); seqNo, start = seqNo+1, start+itemsPerMessage {
+		end := min(start+itemsPerMessage, len(monitors))
+
+		req := &v201.NotifyMonitoringReportRequest{
+			RequestId:   requestID,
+			Tbc:         end < len(monitors),
+			SeqNo:       seqNo,
+			GeneratedAt: generatedAt,
+			Monitor:     monitors[start:end],
+		}
+
+		call, err := ocpp.NewCall(string(v201.ActionNotifyMonitoringReport), req)
+		if err != nil {
+			m.logger.Error("Failed to create NotifyMonitoringReport", "stationId", stationID, "error", err)
+			return
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			m.logger.Error("Failed to send NotifyMonitoringReport",
+				"stationId", stationID,
+				"requestId", requestID,
+				"seqNo", seqNo,
+				"error", err,
+			)
+			return
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+
+		if _, err := pending.Wait(m.ctx); err != nil {
+			m.logger.Error("NotifyMonitoringReport not accepted, aborting report",
+				"stationId", stationID,
+				"requestId", requestID,
+				"seqNo", seqNo,
+				"error", err,
+			)
+			return
+		}
+	}
+}
+
+// updateEVSEVariable stores a simulated EVSE value in the device model and reports the events
+// of the triggered monitors. Connector availability is mirrored to the Connector component.
+func (m *Manager) updateEVSEVariable(station *Station, connectorID int, variable, value string) {
+	if station.DeviceModel == nil {
+		return
+	}
+
+	transactionID := ""
+	if connector, err := station.SessionManager.GetConnector(connectorID); err == nil {
+		if tx := connector.GetTransaction(); tx != nil {
+			transactionID = tx.StringID
+		}
+	}
+
+	evseID := strconv.Itoa(connectorID)
+	events := station.DeviceModel.UpdateActualValue("EVSE", evseID, variable, value, transactionID)
+	if variable == "AvailabilityState" {
+		events = append(events, station.DeviceModel.UpdateActualValue("Connector", evseID+":1", variable, value, transactionID)...)
+	}
+
+	if len(events) > 0 && station.usesTransactionEvents() {
+		m.sendNotifyEvent(station, events)
+	}
+}
+
+// sendNotifyEvent reports monitoring events to the CSMS in NotifyEvent messages of at most
+// ItemsPerMessage events. Events are dropped while the station is offline.
+func (m *Manager) sendNotifyEvent(station *Station, events []v201.EventData) {
+	stationID := station.Config.StationID
+	itemsPerMessage := m.itemsPerMessage(station)
+	generatedAt := v201.DateTime{Time: time.Now()}
+
+	for seqNo, start := 0, 0; start < len(events); seqNo, start = seqNo+1, start+itemsPerMessage {
+		end := min(start+itemsPerMessage, len(events))
+
+		req := &v201.NotifyEventRequest{
+			GeneratedAt: generatedAt,
+			SeqNo:       seqNo,
+			EventData:   events[start:end],
+		}
+		if end < len(events) {
+			tbc := true
+			req.Tbc = &tbc
+		}
+
+		call, err := ocpp.NewCall(string(v201.ActionNotifyEvent), req)
+		if err != nil {
+			m.logger.Error("Failed to create NotifyEvent", "stationId", stationID, "error", err)
+			return
+		}
+
+		// Calls are sent in order by the request tracker
+		if _, err := m.sendCall(station, call, 0); err != nil {
+			m.logger.Debug("Monitoring events not reported", "stationId", stationID, "events", len(events), "error", err)
+			return
+		}
+
+		// Store sent message
+		go m.storeMessage(stationID, "sent", call)
+	}
+
+	for _, event := range events {
+		m.logger.Info("Monitoring event reported",
+			"stationId", stationID,
+			"eventId", event.EventId,
+			"trigger", event.Trigger,
+			"component", event.Component.Name,
+			"variable", event.Variable.Name,
+			"actualValue", event.ActualValue,
+		)
+	}
+}
+
+// itemsPerMessage returns the DeviceDataCtrlr ItemsPerMessage limit of a station
+func (m *Manager) itemsPerMessage(station *Station) int {
+	value, status := station.DeviceModel.GetVariable("DeviceDataCtrlr", "", "ItemsPerMessage", "", v201.AttributeActual)
+	if status == v201.GetVariableStatusAccepted {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultReportItemsPerMessage
+}
+
+// startMonitoring starts evaluating the periodic variable monitors of a station
+func (m *Manager) startMonitoring(station *Station) {
+	m.stopMonitoring(station)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	station.mu.Lock()
+	station.monitoringCancel = cancel
+	station.monitoringDone = done
+	station.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(monitoringInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if events := station.DeviceModel.PeriodicEvents(now); len(events) > 0 {
+					m.sendNotifyEvent(station, events)
+				}
+			}
+		}
+	}()
+}
+
+// stopMonitoring stops the periodic variable monitors of a station
+func (m *Manager) stopMonitoring(station *Station) {
+	station.mu.Lock()
+	if station.monitoringCancel != nil {
+		station.monitoringCancel()
+		station.monitoringCancel = nil
+	}
+	done := station.monitoringDone
+	station.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// handleV201SetChargingProfile handles SetChargingProfile for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201SetChargingProfile(stationID string, req *v201.SetChargingProfileRequest) (*v201.SetChargingProfileResponse, error) {
+	m.logger.Info("Handling SetChargingProfile (2.0.1)",
+		"stationId", stationID,
+		"evseId", req.EvseId,
+		"profileId", req.ChargingProfile.Id,
+		"purpose", req.ChargingProfile.ChargingProfilePurpose,
+		"stackLevel", req.ChargingProfile.StackLevel,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil {
+		return &v201.SetChargingProfileResponse{Status: v201.ChargingProfileStatusRejected}, nil
+	}
+
+	// External constraints are set by an energy management system, not by the CSMS
+	if req.ChargingProfile.ChargingProfilePurpose == v201.ChargingProfilePurposeChargingStationExternalConstraints {
+		return &v201.SetChargingProfileResponse{
+			Status:     v201.ChargingProfileStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "InvalidProfile", AdditionalInfo: "external constraints cannot be set by the CSMS"},
+		}, nil
+	}
+
+	if err := station.SessionManager.SetEVSEChargingProfile(req.EvseId, req.ChargingProfile); err != nil {
+		m.logger.Warn("Charging profile rejected", "stationId", stationID, "error", err)
+		return &v201.SetChargingProfileResponse{
+			Status:     v201.ChargingProfileStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "InvalidProfile", AdditionalInfo: err.Error()},
+		}, nil
+	}
+
+	// An ISO 15118 EV answers the TxProfile with the charging schedule it selected
+	if req.ChargingProfile.ChargingProfilePurpose == v201.ChargingProfilePurposeTxProfile {
+		scheduleID := req.ChargingProfile.ChargingSchedule[0].Id
+		m.afterResponse(station, func() { station.SessionManager.NegotiateEVChargingSchedule(req.EvseId, scheduleID) })
+	}
+
+	return &v201.SetChargingProfileResponse{Status: v201.ChargingProfileStatusAccepted}, nil
+}
+
+// handleV201ClearChargingProfile handles ClearChargingProfile for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201ClearChargingProfile(stationID string, req *v201.ClearChargingProfileRequest) (*v201.ClearChargingProfileResponse, error) {
+	var (
+		evseID     *int
+		purpose    v201.ChargingProfilePurposeType
+		stackLevel *int
+	)
+	if req.ChargingProfileCriteria != nil {
+		evseID = req.ChargingProfileCriteria.EvseId
+		purpose = req.ChargingProfileCriteria.ChargingProfilePurpose
+		stackLevel = req.ChargingProfileCriteria.StackLevel
+	}
+
+	m.logger.Info("Handling ClearChargingProfile (2.0.1)",
+		"stationId", stationID,
+		"id", req.ChargingProfileId,
+		"evseId", evseID,
+		"purpose", purpose,
+		"stackLevel", stackLevel,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil || purpose == v201.ChargingProfilePurposeChargingStationExternalConstraints {
+		return &v201.ClearChargingProfileResponse{Status: v201.ClearChargingProfileStatusUnknown}, nil
+	}
+
+	removed := station.SessionManager.ChargingProfiles().ClearProfiles(req.ChargingProfileId, evseID, chargingProfilePurposeFromV201(purpose), stackLevel)
+	if removed == 0 {
+		return &v201.ClearChargingProfileResponse{Status: v201.ClearChargingProfileStatusUnknown}, nil
+	}
+
+	m.logger.Info("Cleared charging profiles", "stationId", stationID, "count", removed)
+	return &v201.ClearChargingProfileResponse{Status: v201.ClearChargingProfileStatusAccepted}, nil
+}
+
+// handleV201GetCompositeSchedule handles GetCompositeSchedule for OCPP 2.0.1 and 2.1 stations
+func (m *Manager) handleV201GetCompositeSchedule(stationID string, req *v201.GetCompositeScheduleRequest) (*v201.GetCompositeScheduleResponse, error) {
+	m.logger.Info("Handling GetCompositeSchedule (2.0.1)",
+		"stationId", stationID,
+		"evseId", req.EvseId,
+		"duration", req.Duration,
+		"unit", req.ChargingRateUnit,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil || req.Duration <= 0 {
+		return &v201.GetCompositeScheduleResponse{Status: v201.GenericStatusRejected}, nil
+	}
+
+	schedule, err := station.SessionManager.GetCompositeSchedule(req.EvseId, req.Duration, v16.ChargingRateUnitType(req.ChargingRateUnit))
+	if err != nil {
+		m.logger.Warn("Cannot calculate composite schedule", "stationId", stationID, "error", err)
+		return &v201.GetCompositeScheduleResponse{
+			Status:     v201.GenericStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "UnknownEVSE"},
+		}, nil
+	}
+
+	return &v201.GetCompositeScheduleResponse{
+		Status:   v201.GenericStatusAccepted,
+		Schedule: compositeScheduleToV201(req.EvseId, schedule),
+	}, nil
+}
+
+// handleV201ReserveNow reserves an EVSE, or any EVSE with the requested connector type when no
+// EVSE is given. The ReservationCtrlr variables decide whether such reservations are accepted.
+func (m *Manager) handleV201ReserveNow(stationID string, req *v201.ReserveNowRequest) (*v201.ReserveNowResponse, error) {
+	m.logger.Info("Handling ReserveNow (2.0.1)",
+		"stationId", stationID,
+		"reservationId", req.Id,
+		"evseId", req.EvseId,
+		"connectorType", req.ConnectorType,
+		"idToken", req.IdToken.IdToken,
+		"expiryDateTime", req.ExpiryDateTime.Time,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil {
+		return &v201.ReserveNowResponse{Status: v201.ReserveNowStatusRejected}, nil
+	}
+
+	if !m.reservationSetting(station, "Enabled") {
+		return &v201.ReserveNowResponse{
+			Status:     v201.ReserveNowStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "ReservationsDisabled"},
+		}, nil
+	}
+	if req.EvseId == nil && !m.reservationSetting(station, "NonEvseSpecific") {
+		return &v201.ReserveNowResponse{
+			Status:     v201.ReserveNowStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "MissingEvseId"},
+		}, nil
+	}
+
+	var connectorType v201.ConnectorType
+	if req.ConnectorType != nil {
+		connectorType = *req.ConnectorType
+	}
+	groupIDToken := ""
+	if req.GroupIdToken != nil {
+		groupIDToken = req.GroupIdToken.IdToken
+	}
+
+	status := station.SessionManager.ReserveEVSE(req.Id, req.EvseId, connectorType, req.IdToken.IdToken, groupIDToken, req.ExpiryDateTime.Time)
+	return &v201.ReserveNowResponse{Status: v201.ReserveNowStatusType(status)}, nil
+}
+
+// handleV201CancelReservation cancels a reservation of an EVSE or without EVSE
+func (m *Manager) handleV201CancelReservation(stationID string, req *v201.CancelReservationRequest) (*v201.CancelReservationResponse, error) {
+	m.logger.Info("Handling CancelReservation (2.0.1)", "stationId", stationID, "reservationId", req.ReservationId)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil {
+		return &v201.CancelReservationResponse{Status: v201.CancelReservationStatusRejected}, nil
+	}
+
+	if err := station.SessionManager.CancelReservation(req.ReservationId); err != nil {
+		m.logger.Warn("Cannot cancel reservation", "stationId", stationID, "error", err)
+		return &v201.CancelReservationResponse{Status: v201.CancelReservationStatusRejected}, nil
+	}
+
+	return &v201.CancelReservationResponse{Status: v201.CancelReservationStatusAccepted}, nil
+}
+
+// handleV201SetDisplayMessage stores a message for the display. The DisplayMessageCtrlr variables
+// decide which formats and priorities are supported and how many messages can be stored.
+func (m *Manager) handleV201SetDisplayMessage(stationID string, req *v201.SetDisplayMessageRequest) (*v201.SetDisplayMessageResponse, error) {
+	msg := req.Message
+	m.logger.Info("Handling SetDisplayMessage (2.0.1)",
+		"stationId", stationID,
+		"messageId", msg.Id,
+		"priority", msg.Priority,
+		"state", msg.State,
+		"transactionId", msg.TransactionId,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.DeviceModel == nil {
+		return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusRejected}, nil
+	}
+
+	if value, status := station.DeviceModel.GetVariable("DisplayMessageCtrlr", "", "Enabled", "", v201.AttributeActual); status == v201.GetVariableStatusAccepted && value == "false" {
+		return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusRejected}, nil
+	}
+	if !m.displayMessageSupports(station, "SupportedPriorities", string(msg.Priority)) {
+		return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusNotSupportedPriority}, nil
+	}
+	if !m.displayMessageSupports(station, "SupportedFormats", string(msg.Message.Format)) {
+		return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusNotSupportedMessageFormat}, nil
+	}
+	if msg.State != nil {
+		switch *msg.State {
+		case v201.MessageStateCharging, v201.MessageStateFaulted, v201.MessageStateIdle, v201.MessageStateUnavailable:
+		default:
+			return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusNotSupportedState}, nil
+		}
+	}
+
+	if msg.TransactionId != "" {
+		known := false
+		for _, connector := range station.SessionManager.GetAllConnectors() {
+			if tx := connector.GetTransaction(); tx != nil && connector.HasActiveTransaction() && tx.StringID == msg.TransactionId {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusUnknownTransaction}, nil
+		}
+	}
+
+	maxMessages := 0
+	if characteristics, ok := station.DeviceModel.GetCharacteristics("DisplayMessageCtrlr", "", "DisplayMessages", ""); ok && characteristics.MaxLimit != nil {
+		maxMessages = int(*characteristics.MaxLimit)
+	}
+	if !station.DisplayMessages.Set(msg, maxMessages) {
+		return &v201.SetDisplayMessageResponse{
+			Status:     v201.DisplayMessageStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "NoCapacity"},
+		}, nil
+	}
+
+	m.updateDisplayMessageCount(station)
+
+	return &v201.SetDisplayMessageResponse{Status: v201.DisplayMessageStatusAccepted}, nil
+}
+
+// handleV201GetDisplayMessages reports the matching display messages with NotifyDisplayMessages
+// once the response has been sent
+func (m *Manager) handleV201GetDisplayMessages(stationID string, req *v201.GetDisplayMessagesRequest) (*v201.GetDisplayMessagesResponse, error) {
+	m.logger.Info("Handling GetDisplayMessages (2.0.1)",
+		"stationId", stationID,
+		"requestId", req.RequestId,
+		"ids", req.Id,
+		"priority", req.Priority,
+		"state", req.State,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return &v201.GetDisplayMessagesResponse{Status: v201.GetDisplayMessagesStatusUnknown}, nil
+	}
+
+	messages := station.DisplayMessages.Get(req.Id, req.Priority, req.State)
+	if len(messages) == 0 {
+		return &v201.GetDisplayMessagesResponse{Status: v201.GetDisplayMessagesStatusUnknown}, nil
+	}
+
+	requestID := req.RequestId
+	m.afterResponse(station, func() { m.sendDisplayMessages(station, requestID, messages) })
+
+	return &v201.GetDisplayMessagesResponse{Status: v201.GetDisplayMessagesStatusAccepted}, nil
+}
+
+// handleV201ClearDisplayMessage removes a display message
+func (m *Manager) handleV201ClearDisplayMessage(stationID string, req *v201.ClearDisplayMessageRequest) (*v201.ClearDisplayMessageResponse, error) {
+	m.logger.Info("Handling ClearDisplayMessage (2.0.1)", "stationId", stationID, "messageId", req.Id)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || !station.DisplayMessages.Clear(req.Id) {
+		return &v201.ClearDisplayMessageResponse{Status: v201.ClearMessageStatusUnknown}, nil
+	}
+
+	m.updateDisplayMessageCount(station)
+
+	return &v201.ClearDisplayMessageResponse{Status: v201.ClearMessageStatusAccepted}, nil
+}
+
+// handleV201CostUpdated records the running cost of a transaction for the display
+func (m *Manager) handleV201CostUpdated(stationID string, req *v201.CostUpdatedRequest) (*v201.CostUpdatedResponse, error) {
+	m.logger.Info("Handling CostUpdated (2.0.1)", "stationId", stationID, "transactionId", req.TransactionId, "totalCost", req.TotalCost)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		m.logger.Warn("Station not found for CostUpdated", "stationId", stationID)
+		return &v201.CostUpdatedResponse{}, nil
 	}
 
-	// ClearChargingProfile handler - clear charging profiles
-	m.v21Handler.OnClearChargingProfile = func(stationID string, req *v21.ClearChargingProfileRequest) (*v21.ClearChargingProfileResponse, error) {
-		m.logger.Info("Handling ClearChargingProfile (2.1)",
-			"stationId", stationID,
-			"chargingProfileId", req.ChargingProfileId,
-		)
+	station.DisplayMessages.UpdateCost(req.TransactionId, req.TotalCost)
 
-		// For emulator, report unknown (no profiles stored)
-		return &v21.ClearChargingProfileResponse{Status: v21.ClearChargingProfileStatusUnknown}, nil
-	}
+	return &v201.CostUpdatedResponse{}, nil
+}
 
-	// GetCompositeSchedule handler - get composite charging schedule
-	m.v21Handler.OnGetCompositeSchedule = func(stationID string, req *v21.GetCompositeScheduleRequest) (*v21.GetCompositeScheduleResponse, error) {
-		m.logger.Info("Handling GetCompositeSchedule (2.1)",
-			"stationId", stationID,
-			"evseId", req.EvseId,
-			"duration", req.Duration,
-		)
+// handleV21SetDefaultTariff handles SetDefaultTariff requests (OCPP 2.1)
+func (m *Manager) handleV21SetDefaultTariff(stationID string, req *v21.SetDefaultTariffRequest) (*v21.SetDefaultTariffResponse, error) {
+	m.logger.Info("Handling SetDefaultTariff (2.1)", "stationId", stationID, "evseId", req.EvseId, "tariffId", req.Tariff.TariffId)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
 
-		// For emulator, report accepted but empty schedule
-		return &v21.GetCompositeScheduleResponse{Status: "Accepted"}, nil
+	if !exists || station.SessionManager == nil {
+		m.logger.Warn("Station not found for SetDefaultTariff", "stationId", stationID)
+		return &v21.SetDefaultTariffResponse{Status: v21.TariffSetStatusRejected}, nil
 	}
 
-	// GetLocalListVersion handler
-	m.v21Handler.OnGetLocalListVersion = func(stationID string, req *v21.GetLocalListVersionRequest) (*v21.GetLocalListVersionResponse, error) {
-		m.logger.Info("Handling GetLocalListVersion (2.1)", "stationId", stationID)
-		return &v21.GetLocalListVersionResponse{VersionNumber: 0}, nil
+	if req.EvseId != 0 {
+		if _, err := station.SessionManager.GetConnector(req.EvseId); err != nil {
+			return &v21.SetDefaultTariffResponse{
+				Status:     v21.TariffSetStatusRejected,
+				StatusInfo: &v21.StatusInfo{ReasonCode: "UnknownEvse"},
+			}, nil
+		}
 	}
 
-	// SendLocalList handler
-	m.v21Handler.OnSendLocalList = func(stationID string, req *v21.SendLocalListRequest) (*v21.SendLocalListResponse, error) {
-		m.logger.Info("Handling SendLocalList (2.1)", "stationId", stationID, "version", req.VersionNumber)
-		return &v21.SendLocalListResponse{Status: "Accepted"}, nil
+	if err := station.SessionManager.Tariffs().SetDefault(req.EvseId, req.Tariff); err != nil {
+		return &v21.SetDefaultTariffResponse{
+			Status:     v21.TariffSetStatusRejected,
+			StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidTariff", AdditionalInfo: err.Error()},
+		}, nil
 	}
 
-	// UpdateFirmware handler
-	m.v21Handler.OnUpdateFirmware = func(stationID string, req *v21.UpdateFirmwareRequest) (*v21.UpdateFirmwareResponse, error) {
-		m.logger.Info("Handling UpdateFirmware (2.1)", "stationId", stationID, "requestId", req.RequestId)
+	return &v21.SetDefaultTariffResponse{Status: v21.TariffSetStatusAccepted}, nil
+}
 
-		// Send firmware status notifications
-		go func() {
-			m.sendFirmwareStatusNotification(stationID, req.RequestId, "Downloading")
-			time.Sleep(2 * time.Second)
-			m.sendFirmwareStatusNotification(stationID, req.RequestId, "Downloaded")
-			time.Sleep(1 * time.Second)
-			m.sendFirmwareStatusNotification(stationID, req.RequestId, "Installing")
-			time.Sleep(2 * time.Second)
-			m.sendFirmwareStatusNotification(stationID, req.RequestId, "Installed")
-		}()
+// handleV21GetTariffs handles GetTariffs requests (OCPP 2.1)
+func (m *Manager) handleV21GetTariffs(stationID string, req *v21.GetTariffsRequest) (*v21.GetTariffsResponse, error) {
+	m.logger.Info("Handling GetTariffs (2.1)", "stationId", stationID, "evseId", req.EvseId)
 
-		return &v21.UpdateFirmwareResponse{Status: "Accepted"}, nil
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil {
+		m.logger.Warn("Station not found for GetTariffs", "stationId", stationID)
+		return &v21.GetTariffsResponse{Status: v21.TariffGetStatusRejected}, nil
 	}
 
-	// SetNetworkProfile handler
-	m.v21Handler.OnSetNetworkProfile = func(stationID string, req *v21.SetNetworkProfileRequest) (*v21.SetNetworkProfileResponse, error) {
-		m.logger.Info("Handling SetNetworkProfile (2.1)", "stationId", stationID, "slot", req.ConfigurationSlot)
-		return &v21.SetNetworkProfileResponse{Status: "Accepted"}, nil
+	assignments := station.SessionManager.Tariffs().Assignments(req.EvseId)
+	if len(assignments) == 0 {
+		return &v21.GetTariffsResponse{Status: v21.TariffGetStatusNoTariff}, nil
 	}
 
-	// GetLog handler
-	m.v21Handler.OnGetLog = func(stationID string, req *v21.GetLogRequest) (*v21.GetLogResponse, error) {
-		m.logger.Info("Handling GetLog (2.1)", "stationId", stationID, "logType", req.LogType)
+	return &v21.GetTariffsResponse{Status: v21.TariffGetStatusAccepted, TariffAssignments: assignments}, nil
+}
 
-		// Send log status notifications
-		go func() {
-			m.sendLogStatusNotification(stationID, "Accepted")
-			time.Sleep(1 * time.Second)
-			m.sendLogStatusNotification(stationID, "Uploading")
-			time.Sleep(2 * time.Second)
-			m.sendLogStatusNotification(stationID, "Uploaded")
-		}()
+// handleV21ClearTariffs handles ClearTariffs requests (OCPP 2.1)
+func (m *Manager) handleV21ClearTariffs(stationID string, req *v21.ClearTariffsRequest) (*v21.ClearTariffsResponse, error) {
+	m.logger.Info("Handling ClearTariffs (2.1)", "stationId", stationID, "tariffIds", req.TariffIds, "evseId", req.EvseId)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
 
-		return &v21.GetLogResponse{Status: "Accepted"}, nil
+	if !exists || station.SessionManager == nil {
+		m.logger.Warn("Station not found for ClearTariffs", "stationId", stationID)
+		return &v21.ClearTariffsResponse{ClearTariffsResult: []v21.ClearTariffsResultType{{Status: v21.TariffClearStatusRejected}}}, nil
 	}
+
+	return &v21.ClearTariffsResponse{
+		ClearTariffsResult: station.SessionManager.Tariffs().ClearDefaults(req.TariffIds, req.EvseId),
+	}, nil
 }
 
-// Helper methods for OCPP 2.1 async notifications
+// handleV21ChangeTransactionTariff handles ChangeTransactionTariff requests (OCPP 2.1)
+func (m *Manager) handleV21ChangeTransactionTariff(stationID string, req *v21.ChangeTransactionTariffRequest) (*v21.ChangeTransactionTariffResponse, error) {
+	m.logger.Info("Handling ChangeTransactionTariff (2.1)", "stationId", stationID, "transactionId", req.TransactionId, "tariffId", req.Tariff.TariffId)
 
-func (m *Manager) sendNotifyCustomerInformation(stationID string, requestId int, data string, tbc bool) {
-	req := &v21.NotifyCustomerInformationRequest{
-		Data:      data,
-		SeqNo:     0,
-		RequestId: requestId,
-		Tbc:       tbc,
-	}
-	_, err := m.v21Handler.SendNotifyCustomerInformation(stationID, req)
-	if err != nil {
-		m.logger.Error("Failed to send NotifyCustomerInformation", "error", err)
-	}
-}
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
 
-func (m *Manager) sendNotifyDisplayMessages(stationID string, requestId int, messages []v21.DisplayMessageType, tbc bool) {
-	req := &v21.NotifyDisplayMessagesRequest{
-		RequestId:   requestId,
-		Tbc:         tbc,
-		MessageInfo: messages,
-	}
-	_, err := m.v21Handler.SendNotifyDisplayMessages(stationID, req)
-	if err != nil {
-		m.logger.Error("Failed to send NotifyDisplayMessages", "error", err)
+	if !exists || station.SessionManager == nil {
+		m.logger.Warn("Station not found for ChangeTransactionTariff", "stationId", stationID)
+		return &v21.ChangeTransactionTariffResponse{Status: v21.TariffChangeStatusRejected}, nil
 	}
-}
 
-func (m *Manager) sendReportChargingProfiles(stationID string, requestId int, profiles []v21.ChargingProfileType, tbc bool) {
-	req := &v21.ReportChargingProfilesRequest{
-		RequestId:           requestId,
-		ChargingLimitSource: "Other",
-		EvseId:              0,
-		Tbc:                 tbc,
-		ChargingProfile:     profiles,
-	}
-	_, err := m.v21Handler.SendReportChargingProfiles(stationID, req)
+	status, err := station.SessionManager.Tariffs().ChangeTransactionTariff(req.TransactionId, req.Tariff, time.Now())
 	if err != nil {
-		m.logger.Error("Failed to send ReportChargingProfiles", "error", err)
+		reasonCode := string(status)
+		if status == v21.TariffChangeStatusRejected {
+			reasonCode = "InvalidTariff"
+		}
+		return &v21.ChangeTransactionTariffResponse{
+			Status:     status,
+			StatusInfo: &v21.StatusInfo{ReasonCode: reasonCode, AdditionalInfo: err.Error()},
+		}, nil
 	}
+
+	return &v21.ChangeTransactionTariffResponse{Status: status}, nil
 }
 
-func (m *Manager) sendFirmwareStatusNotification(stationID string, requestId int, status string) {
-	req := &v21.FirmwareStatusNotificationRequest{
-		Status:    status,
-		RequestId: &requestId,
+// handleV21PublishFirmware handles PublishFirmware requests (OCPP 2.1). The station acts as Local
+// Controller and serves the firmware to other stations once its checksum has been verified.
+func (m *Manager) handleV21PublishFirmware(stationID string, req *v21.PublishFirmwareRequest) (*v21.PublishFirmwareResponse, error) {
+	m.logger.Info("Handling PublishFirmware (2.1)",
+		"stationId", stationID,
+		"requestId", req.RequestId,
+		"location", req.Location,
+		"checksum", req.Checksum,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		m.logger.Warn("Station not found for PublishFirmware", "stationId", stationID)
+		return &v21.PublishFirmwareResponse{Status: v21.GenericStatusRejected}, nil
 	}
-	_, err := m.v21Handler.SendFirmwareStatusNotification(stationID, req)
-	if err != nil {
-		m.logger.Error("Failed to send FirmwareStatusNotification", "error", err)
+
+	if err := station.Firmware.PublishFirmware(req.RequestId, req.Location, req.Checksum, req.Retries, req.RetryInterval); err != nil {
+		return &v21.PublishFirmwareResponse{
+			Status:     v21.GenericStatusRejected,
+			StatusInfo: &v21.StatusInfo{ReasonCode: "InvalidValue", AdditionalInfo: err.Error()},
+		}, nil
 	}
+
+	return &v21.PublishFirmwareResponse{Status: v21.GenericStatusAccepted}, nil
 }
 
-func (m *Manager) sendLogStatusNotification(stationID string, status string) {
-	req := &v21.LogStatusNotificationRequest{
-		Status: status,
-	}
-	_, err := m.v21Handler.SendLogStatusNotification(stationID, req)
-	if err != nil {
-		m.logger.Error("Failed to send LogStatusNotification", "error", err)
+// handleV21UnpublishFirmware handles UnpublishFirmware requests (OCPP 2.1)
+func (m *Manager) handleV21UnpublishFirmware(stationID string, req *v21.UnpublishFirmwareRequest) (*v21.UnpublishFirmwareResponse, error) {
+	m.logger.Info("Handling UnpublishFirmware (2.1)", "stationId", stationID, "checksum", req.Checksum)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		m.logger.Warn("Station not found for UnpublishFirmware", "stationId", stationID)
+		return &v21.UnpublishFirmwareResponse{Status: v21.UnpublishFirmwareStatusNoFirmware}, nil
 	}
+
+	return &v21.UnpublishFirmwareResponse{Status: station.Firmware.UnpublishFirmware(req.Checksum)}, nil
 }
 
-// setupSessionManagerCallbacks wires up SessionManager callbacks to OCPP message handlers
-func (m *Manager) setupSessionManagerCallbacks(station *Station) {
+// sendDisplayMessages streams display messages to the CSMS as NotifyDisplayMessages messages of at
+// most ItemsPerMessage entries. Each part waits for the response to the previous one.
+func (m *Manager) sendDisplayMessages(station *Station, requestID int, messages []v201.MessageInfo) {
 	stationID := station.Config.StationID
+	itemsPerMessage := m.itemsPerMessage(station)
 
-	// SendStatusNotification - sends status notification to CSMS
-	station.SessionManager.SendStatusNotification = func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error {
-		req := &v16.StatusNotificationRequest{
-			ConnectorId:     connectorID,
-			Status:          status,
-			ErrorCode:       errorCode,
-			Info:            info,
-			VendorId:        station.Config.Vendor,
-			VendorErrorCode: "",
+	for start := 0; start < len(messages); start += itemsPerMessage {
+		end := min(start+itemsPerMessage, len(messages))
+
+		req := &v201.NotifyDisplayMessagesRequest{
+			RequestId:   requestID,
+			Tbc:         end < len(messages),
+			MessageInfo: messages[start:end],
 		}
 
-		call, err := m.v16Handler.SendStatusNotification(stationID, req)
+		call, err := ocpp.NewCall(string(v201.ActionNotifyDisplayMessages), req)
 		if err != nil {
-			m.logger.Error("Failed to send StatusNotification",
+			m.logger.Error("Failed to create NotifyDisplayMessages", "stationId", stationID, "error", err)
+			return
+		}
+
+		pending, err := m.sendCall(station, call, 0)
+		if err != nil {
+			m.logger.Error("Failed to send NotifyDisplayMessages",
 				"stationId", stationID,
-				"connectorId", connectorID,
+				"requestId", requestID,
 				"error", err,
 			)
-			return err
+			return
 		}
 
 		// Store sent message
 		go m.storeMessage(stationID, "sent", call)
 
-		return nil
-	}
-
-	// SendMeterValues - sends meter values to CSMS
-	station.SessionManager.SendMeterValues = func(connectorID int, transactionID *int, meterValues []v16.MeterValue) error {
-		req := &v16.MeterValuesRequest{
-			ConnectorId:   connectorID,
-			TransactionId: transactionID,
-			MeterValue:    meterValues,
-		}
-
-		call, err := m.v16Handler.SendMeterValues(stationID, req)
-		if err != nil {
-			m.logger.Error("Failed to send MeterValues",
+		if _, err := pending.Wait(m.ctx); err != nil {
+			m.logger.Error("NotifyDisplayMessages not accepted, aborting report",
 				"stationId", stationID,
-				"connectorId", connectorID,
+				"requestId", requestID,
 				"error", err,
 			)
-			return err
+			return
 		}
+	}
+}
 
-		// Store sent message
-		go m.storeMessage(stationID, "sent", call)
+// displayMessageSupports reports whether a DisplayMessageCtrlr member list contains a value,
+// everything is supported when the variable is not configured
+func (m *Manager) displayMessageSupports(station *Station, variable, value string) bool {
+	list, status := station.DeviceModel.GetVariable("DisplayMessageCtrlr", "", variable, "", v201.AttributeActual)
+	if status != v201.GetVariableStatusAccepted {
+		return true
+	}
+	for _, member := range strings.Split(list, ",") {
+		if strings.TrimSpace(member) == value {
+			return true
+		}
+	}
+	return false
+}
 
-		return nil
+// updateDisplayMessageCount stores the number of display messages in the device model and reports
+// the events of the triggered monitors
+func (m *Manager) updateDisplayMessageCount(station *Station) {
+	if station.DeviceModel == nil {
+		return
 	}
 
-	// SendAuthorize - sends authorization request to CSMS and waits for response
-	station.SessionManager.SendAuthorize = func(idTag string) (*v16.AuthorizeResponse, error) {
-		req := &v16.AuthorizeRequest{
-			IdTag: idTag,
-		}
+	events := station.DeviceModel.UpdateActualValue("DisplayMessageCtrlr", "", "DisplayMessages", strconv.Itoa(station.DisplayMessages.Len()), "")
+	if len(events) > 0 && station.usesTransactionEvents() {
+		m.sendNotifyEvent(station, events)
+	}
+}
 
-		call, err := m.v16Handler.SendAuthorize(stationID, req)
-		if err != nil {
-			m.logger.Error("Failed to send Authorize",
-				"stationId", stationID,
-				"idTag", idTag,
-				"error", err,
-			)
-			return nil, err
+// handleV201UpdateFirmware downloads firmware from an HTTP(S) location and installs it once its
+// signing certificate and signature have been verified
+func (m *Manager) handleV201UpdateFirmware(stationID string, req *v201.UpdateFirmwareRequest) (*v201.UpdateFirmwareResponse, error) {
+	m.logger.Info("Handling UpdateFirmware (2.0.1)",
+		"stationId", stationID,
+		"requestId", req.RequestId,
+		"location", req.Firmware.Location,
+		"retrieveDateTime", req.Firmware.RetrieveDateTime.Time,
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.Firmware == nil {
+		return &v201.UpdateFirmwareResponse{Status: v201.UpdateFirmwareStatusRejected}, nil
+	}
+
+	if req.Firmware.SigningCertificate != "" {
+		if err := station.Security.VerifyFirmwareSigningCertificate(req.Firmware.SigningCertificate); err != nil {
+			m.logger.Warn("Invalid firmware signing certificate", "stationId", stationID, "error", err)
+			station.Security.AddSecurityEvent(v16.SecurityEventInvalidFirmwareSigningCertificate, err.Error())
+			return &v201.UpdateFirmwareResponse{Status: v201.UpdateFirmwareStatusInvalidCertificate}, nil
 		}
+	}
 
-		// Track pending request
-		station.pendingMu.Lock()
-		station.pendingRequests[call.UniqueID] = string(v16.ActionAuthorize)
-		station.pendingMu.Unlock()
+	var installDate *time.Time
+	if req.Firmware.InstallDateTime != nil {
+		installDate = &req.Firmware.InstallDateTime.Time
+	}
 
-		// Create response channel and register it
-		respChan := make(chan *v16.AuthorizeResponse, 1)
-		station.pendingAuthRespMu.Lock()
-		station.pendingAuthResp[call.UniqueID] = respChan
-		station.pendingAuthRespMu.Unlock()
+	status := station.Firmware.DownloadFirmware(req.RequestId, FirmwareImage{
+		Location:           req.Firmware.Location,
+		RetrieveDate:       req.Firmware.RetrieveDateTime.Time,
+		InstallDate:        installDate,
+		SigningCertificate: req.Firmware.SigningCertificate,
+		Signature:          req.Firmware.Signature,
+	}, req.Retries, req.RetryInterval)
 
-		// Store sent message
-		go m.storeMessage(stationID, "sent", call)
+	resp := &v201.UpdateFirmwareResponse{Status: v201.UpdateFirmwareStatusType(status)}
+	if status == v16.UpdateFirmwareStatusRejected {
+		resp.StatusInfo = &v201.StatusInfo{ReasonCode: "UnsupportedLocation"}
+	}
+	return resp, nil
+}
 
-		m.logger.Debug("Waiting for Authorize response from CSMS",
-			"stationId", stationID,
-			"idTag", idTag,
-			"messageId", call.UniqueID,
-		)
+// handleV201GetLog uploads a generated log archive to an HTTP(S) location
+func (m *Manager) handleV201GetLog(stationID string, req *v201.GetLogRequest) (*v201.GetLogResponse, error) {
+	m.logger.Info("Handling GetLog (2.0.1)",
+		"stationId", stationID,
+		"requestId", req.RequestId,
+		"logType", req.LogType,
+		"location", req.Log.RemoteLocation,
+	)
 
-		// Wait for response with timeout
-		select {
-		case resp := <-respChan:
-			m.logger.Info("Received real Authorize response",
-				"stationId", stationID,
-				"idTag", idTag,
-				"status", resp.IdTagInfo.Status,
-			)
-			return resp, nil
-		case <-time.After(10 * time.Second):
-			// Cleanup on timeout
-			station.pendingAuthRespMu.Lock()
-			delete(station.pendingAuthResp, call.UniqueID)
-			station.pendingAuthRespMu.Unlock()
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
 
-			m.logger.Error("Timeout waiting for Authorize response",
-				"stationId", stationID,
-				"idTag", idTag,
-			)
-			return nil, fmt.Errorf("timeout waiting for authorization response")
-		}
+	if !exists || station.Firmware == nil {
+		return &v201.GetLogResponse{Status: v201.LogStatusRejected}, nil
 	}
 
-	// SendStartTransaction - sends start transaction request to CSMS
-	station.SessionManager.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
-		req := &v16.StartTransactionRequest{
-			ConnectorId: connectorID,
-			IdTag:       idTag,
-			MeterStart:  meterStart,
-			Timestamp:   v16.DateTime{Time: timestamp},
+	switch req.LogType {
+	case v201.LogTypeDiagnosticsLog, v201.LogTypeSecurityLog, v201.LogTypeDataCollectorLog:
+	default:
+		return &v201.GetLogResponse{
+			Status:     v201.LogStatusRejected,
+			StatusInfo: &v201.StatusInfo{ReasonCode: "UnsupportedLogType"},
+		}, nil
+	}
+
+	var oldest, latest *time.Time
+	if req.Log.OldestTimestamp != nil {
+		oldest = &req.Log.OldestTimestamp.Time
+	}
+	if req.Log.LatestTimestamp != nil {
+		latest = &req.Log.LatestTimestamp.Time
+	}
+
+	status, fileName := station.Firmware.UploadLog(req.RequestId, v16.LogType(req.LogType), req.Log.RemoteLocation, oldest, latest, req.Retries, req.RetryInterval)
+
+	resp := &v201.GetLogResponse{Status: v201.LogStatusType(status), Filename: fileName}
+	if status == v16.LogStatusRejected {
+		resp.StatusInfo = &v201.StatusInfo{ReasonCode: "UnsupportedLocation"}
+	}
+	return resp, nil
+}
+
+// maxLogMessages limits the OCPP messages included in an uploaded diagnostics log
+const maxLogMessages = 1000
+
+// collectLog returns the lines of a station log between the optional oldest and latest
+// timestamps. Security logs hold the security events, diagnostics logs the station state
+// followed by its OCPP messages.
+func (m *Manager) collectLog(station *Station, logType v16.LogType, oldest, latest *time.Time) []string {
+	inRange := func(t time.Time) bool {
+		return (oldest == nil || !t.Before(*oldest)) && (latest == nil || !t.After(*latest))
+	}
+
+	var lines []string
+
+	if logType == v16.LogTypeSecurityLog {
+		for _, event := range station.Security.SecurityLog() {
+			if inRange(event.Timestamp) {
+				lines = append(lines, fmt.Sprintf("%s %s %s", event.Timestamp.UTC().Format(time.RFC3339), event.Type, event.TechInfo))
+			}
 		}
+		return lines
+	}
 
-		call, err := m.v16Handler.SendStartTransaction(stationID, req)
-		if err != nil {
-			m.logger.Error("Failed to send StartTransaction",
-				"stationId", stationID,
-				"connectorId", connectorID,
-				"idTag", idTag,
-				"error", err,
-			)
-			return nil, err
+	station.mu.RLock()
+	lines = append(lines,
+		fmt.Sprintf("station=%s vendor=%s model=%s firmware=%s protocol=%s",
+			station.Config.StationID, station.Config.Vendor, station.Config.Model, station.Config.FirmwareVersion, station.Config.ProtocolVersion),
+		fmt.Sprintf("state=%s connection=%s", station.RuntimeState.State, station.RuntimeState.ConnectionStatus),
+	)
+	station.mu.RUnlock()
+
+	if station.SessionManager != nil {
+		for _, connector := range station.SessionManager.GetAllConnectors() {
+			line := fmt.Sprintf("connector=%d state=%s", connector.ID, connector.GetState())
+			if tx := connector.GetTransaction(); tx != nil {
+				line += fmt.Sprintf(" transaction=%d", tx.ID)
+			}
+			lines = append(lines, line)
 		}
+	}
 
-		// Track pending request
-		station.pendingMu.Lock()
-		station.pendingRequests[call.UniqueID] = string(v16.ActionStartTransaction)
-		station.pendingMu.Unlock()
+	if m.messageLogger == nil {
+		return lines
+	}
 
-		// Track which connector and idTag are starting this transaction
-		station.pendingStartMu.Lock()
-		station.pendingStartTx[call.UniqueID] = connectorID
-		station.pendingStartTags[call.UniqueID] = idTag
-		station.pendingStartMu.Unlock()
+	filter := logging.MessageFilter{StationID: station.Config.StationID, Limit: maxLogMessages}
+	if oldest != nil {
+		filter.StartTime = *oldest
+	}
+	if latest != nil {
+		filter.EndTime = *latest
+	}
 
-		m.logger.Info("Tracking StartTransaction for response",
-			"stationId", stationID,
-			"messageId", call.UniqueID,
-			"connectorId", connectorID,
-			"idTag", idTag,
-		)
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	defer cancel()
+
+	messages, err := m.messageLogger.GetMessages(ctx, filter)
+	if err != nil {
+		m.logger.Warn("Failed to read messages for log upload", "stationId", station.Config.StationID, "error", err)
+		return lines
+	}
+	for _, msg := range messages {
+		lines = append(lines, fmt.Sprintf("%s %s %s %s %s", msg.Timestamp.UTC().Format(time.RFC3339), msg.Direction, msg.MessageType, msg.Action, msg.MessageID))
+	}
+
+	return lines
+}
+
+// handleV201SendLocalList updates the Local Authorization List of typed idTokens and reports the
+// new number of entries to the device model
+func (m *Manager) handleV201SendLocalList(stationID string, req *v201.SendLocalListRequest) (*v201.SendLocalListResponse, error) {
+	m.logger.Info("Handling SendLocalList (2.0.1)",
+		"stationId", stationID,
+		"versionNumber", req.VersionNumber,
+		"updateType", req.UpdateType,
+		"entries", len(req.LocalAuthorizationList),
+	)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
 
-		// Store sent message
-		go m.storeMessage(stationID, "sent", call)
+	if !exists || station.SessionManager == nil {
+		return &v201.SendLocalListResponse{Status: v201.SendLocalListStatusFailed}, nil
+	}
 
-		// For now, return a placeholder transaction ID
-		// TODO: Implement async request/response tracking
-		// The real transaction ID comes from CSMS response
-		return &v16.StartTransactionResponse{
-			TransactionId: 1,
-			IdTagInfo: v16.IdTagInfo{
-				Status: "Accepted",
-			},
-		}, nil
+	auth := station.SessionManager.IdTokenAuthorization()
+	status := auth.SendLocalList(req)
+	if status != v201.SendLocalListStatusAccepted {
+		return &v201.SendLocalListResponse{Status: status}, nil
 	}
 
-	// SendStopTransaction - sends stop transaction request to CSMS
-	station.SessionManager.SendStopTransaction = func(transactionID int, idTag string, meterStop int, timestamp time.Time, reason v16.Reason) (*v16.StopTransactionResponse, error) {
-		req := &v16.StopTransactionRequest{
-			TransactionId: transactionID,
-			IdTag:         idTag,
-			MeterStop:     meterStop,
-			Timestamp:     v16.DateTime{Time: timestamp},
-			Reason:        reason,
-		}
+	events := station.DeviceModel.UpdateActualValue("LocalAuthListCtrlr", "", "Entries", strconv.Itoa(auth.Entries()), "")
+	if len(events) > 0 {
+		m.sendNotifyEvent(station, events)
+	}
 
-		call, err := m.v16Handler.SendStopTransaction(stationID, req)
-		if err != nil {
-			m.logger.Error("Failed to send StopTransaction",
-				"stationId", stationID,
-				"transactionId", transactionID,
-				"error", err,
-			)
-			return nil, err
-		}
+	return &v201.SendLocalListResponse{Status: status}, nil
+}
 
-		// Track pending request
-		station.pendingMu.Lock()
-		station.pendingRequests[call.UniqueID] = string(v16.ActionStopTransaction)
-		station.pendingMu.Unlock()
+// handleV201GetLocalListVersion returns the version of the Local Authorization List, 0 when the
+// list is disabled or has not been set
+func (m *Manager) handleV201GetLocalListVersion(stationID string, req *v201.GetLocalListVersionRequest) (*v201.GetLocalListVersionResponse, error) {
+	m.logger.Info("Handling GetLocalListVersion (2.0.1)", "stationId", stationID)
 
-		// Store sent message
-		go m.storeMessage(stationID, "sent", call)
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
 
-		// For now, return accepted status
-		// TODO: Implement async request/response tracking
-		return &v16.StopTransactionResponse{
-			IdTagInfo: &v16.IdTagInfo{
-				Status: "Accepted",
-			},
-		}, nil
+	if !exists || station.SessionManager == nil {
+		return &v201.GetLocalListVersionResponse{VersionNumber: 0}, nil
+	}
+
+	return &v201.GetLocalListVersionResponse{
+		VersionNumber: station.SessionManager.IdTokenAuthorization().ListVersion(),
+	}, nil
+}
+
+// isIdTokenAuthComponent reports whether a device model component configures idToken authorization
+func isIdTokenAuthComponent(component string) bool {
+	switch component {
+	case "AuthCtrlr", "LocalAuthListCtrlr", "AuthCacheCtrlr":
+		return true
 	}
+	return false
+}
+
+// reservationSetting returns a boolean ReservationCtrlr variable of a station, reservations are
+// allowed when it is not configured
+func (m *Manager) reservationSetting(station *Station, variable string) bool {
+	value, status := station.DeviceModel.GetVariable("ReservationCtrlr", "", variable, "", v201.AttributeActual)
+	return status != v201.GetVariableStatusAccepted || value != "false"
 }
 
 // LoadStations loads all stations from MongoDB
@@ -1273,6 +4569,45 @@ func (m *Manager) LoadStations(ctx context.Context) error {
 		transactionRepo := storage.NewTransactionRepository(m.db)
 		sessionManager.SetTransactionRepository(transactionRepo)
 		sessionManager.SetProtocolVersion(config.ProtocolVersion)
+		sessionManager.SetEVConfig(config.Simulation.EV)
+
+		// Restore the local authorization list and cache
+		sessionManager.Authorization().SetRepository(storage.NewAuthorizationRepository(m.db))
+		if err := sessionManager.Authorization().Load(ctx); err != nil {
+			m.logger.Warn("Failed to load local authorization data",
+				"stationId", config.StationID,
+				"error", err,
+			)
+		}
+		sessionManager.IdTokenAuthorization().SetRepository(storage.NewAuthorizationRepository(m.db))
+		if err := sessionManager.IdTokenAuthorization().Load(ctx); err != nil {
+			m.logger.Warn("Failed to load idToken authorization data",
+				"stationId", config.StationID,
+				"error", err,
+			)
+		}
+
+		// Apply the persisted configuration keys
+		configuration := NewConfigurationStore(config, m.logger)
+		sessionManager.Authorization().SetConfig(configuration.AuthorizationConfig())
+		sessionManager.SetMeterValueConfig(
+			configuration.Int(ConfigKeyMeterValueSampleInterval),
+			configuration.Measurands(ConfigKeyMeterValuesSampledData),
+		)
+
+		// Restore transaction messages that were not delivered before the restart
+		messageQueue := NewMessageQueue(config.StationID, m.logger)
+		messageQueue.SetRepository(storage.NewMessageQueueRepository(m.db))
+		messageQueue.SetRetryPolicy(
+			configuration.Int(ConfigKeyTransactionMessageAttempts),
+			time.Duration(configuration.Int(ConfigKeyTransactionMessageRetryInterval))*time.Second,
+		)
+		if err := messageQueue.Load(ctx); err != nil {
+			m.logger.Warn("Failed to load message queue",
+				"stationId", config.StationID,
+				"error", err,
+			)
+		}
 
 		// Create station instance with device model
 		deviceModel := v201.NewDeviceModel()
@@ -1283,6 +4618,10 @@ func (m *Manager) LoadStations(ctx context.Context) error {
 			deviceModel.AddEVSEComponent(conn.ID)
 			deviceModel.AddConnectorComponent(conn.ID, 1, conn.Type)
 		}
+		m.restoreDeviceModel(deviceModel, config)
+		sessionManager.SetTxControl(TxControlFromDeviceModel(deviceModel))
+		sessionManager.IdTokenAuthorization().SetConfig(IdTokenAuthConfigFromDeviceModel(deviceModel))
+		deviceModel.UpdateActualValue("LocalAuthListCtrlr", "", "Entries", strconv.Itoa(sessionManager.IdTokenAuthorization().Entries()), "")
 
 		// Create certificate store for ISO 15118 support
 		certStore := v201.NewCertificateStore(config.StationID, config.Vendor, "US")
@@ -1293,10 +4632,11 @@ func (m *Manager) LoadStations(ctx context.Context) error {
 			SessionManager:   sessionManager,
 			DeviceModel:      deviceModel,
 			CertificateStore: certStore,
-			pendingRequests:  make(map[string]string),
-			pendingStartTx:   make(map[string]int),
-			pendingStartTags: make(map[string]string),
-			pendingAuthResp:  make(map[string]chan *v16.AuthorizeResponse),
+			Firmware:         NewFirmwareManager(config.StationID, config.Simulation.Firmware, m.logger),
+			Security:         NewSecurityManager(config.StationID, config.SecurityProfile, certStore, m.logger),
+			Configuration:    configuration,
+			MessageQueue:     messageQueue,
+			DisplayMessages:  NewDisplayMessageStore(),
 			failedAuths:      make(map[string]time.Time),
 			RuntimeState: RuntimeState{
 				State:            StateDisconnected,
@@ -1309,7 +4649,8 @@ func (m *Manager) LoadStations(ctx context.Context) error {
 			isConnected := station.RuntimeState.ConnectionStatus == "connected"
 			station.mu.RUnlock()
 
-			if !isConnected {
+			// Connector changes while resetting do not change the station state
+			if !isConnected || station.StateMachine.GetState() == StateResetting {
 				return
 			}
 
@@ -1320,8 +4661,12 @@ func (m *Manager) LoadStations(ctx context.Context) error {
 			station.mu.Unlock()
 		})
 
-		// Set up session manager callbacks
+		// Set up session manager, firmware, security, configuration and message queue callbacks
 		m.setupSessionManagerCallbacks(station)
+		m.setupFirmwareManagerCallbacks(station)
+		m.setupSecurityManagerCallbacks(station)
+		m.setupConfigurationCallbacks(station)
+		m.setupMessageQueueCallbacks(station)
 
 		m.mu.Lock()
 		m.stations[config.StationID] = station
@@ -1558,7 +4903,7 @@ func (m *Manager) AutoStart(ctx context.Context) error {
 		station.mu.RUnlock()
 
 		if shouldStart {
-			if err := m.startStation(ctx, stationID); err != nil {
+			if err := m.startStation(ctx, stationID, "auto start"); err != nil {
 				m.logger.Error("Failed to auto-start station",
 					"stationId", stationID,
 					"error", err,
@@ -1578,11 +4923,11 @@ func (m *Manager) StartStation(ctx context.Context, stationID string) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.startStation(ctx, stationID)
+	return m.startStation(ctx, stationID, "manual start")
 }
 
 // startStation is the internal start implementation (caller must hold read lock)
-func (m *Manager) startStation(ctx context.Context, stationID string) error {
+func (m *Manager) startStation(ctx context.Context, stationID, reason string) error {
 	station, exists := m.stations[stationID]
 	if !exists {
 		return fmt.Errorf("station not found: %s", stationID)
@@ -1601,44 +4946,34 @@ func (m *Manager) startStation(ctx context.Context, stationID string) error {
 		return fmt.Errorf("station is disabled: %s", stationID)
 	}
 
-	m.logger.Info("Starting station", "stationId", stationID)
+	// CSMS endpoints with the connection settings required by their security profiles
+	profile := station.Security.Profile()
+	endpoints, err := m.networkEndpoints(station, profile)
+	if err != nil {
+		station.mu.Unlock()
+		return err
+	}
+	offlineThreshold := m.offlineThreshold(station)
+
+	m.logger.Info("Starting station", "stationId", stationID, "securityProfile", profile, "endpoints", len(endpoints))
 
 	// Update state while holding lock
-	station.StateMachine.SetState(StateConnecting, "manual start")
+	station.StateMachine.SetState(StateConnecting, reason)
 	station.RuntimeState.State = StateConnecting
 	station.RuntimeState.ConnectionStatus = "connecting"
 	station.RuntimeState.LastError = ""
 
 	// Capture configuration needed for connection
-	url := station.Config.CSMSURL
 	protocol := station.Config.ProtocolVersion
-	var authConfig *connection.AuthConfig
-	if station.Config.CSMSAuth != nil {
-		auth := *station.Config.CSMSAuth
-		switch auth.Type {
-		case "basic":
-			authConfig = &connection.AuthConfig{
-				Type:     "basic",
-				Username: auth.Username,
-				Password: auth.Password,
-			}
-		case "bearer":
-			authConfig = &connection.AuthConfig{
-				Type:  "bearer",
-				Token: auth.Token,
-			}
-		}
-	}
 
 	station.mu.Unlock()
 
 	// Initiate WebSocket connection
-	err := m.connManager.ConnectStation(
+	err = m.connManager.ConnectStationWithFailover(
 		stationID,
-		url,
 		protocol,
-		nil,
-		authConfig,
+		endpoints,
+		offlineThreshold,
 	)
 	if err != nil {
 		station.mu.Lock()
@@ -1655,6 +4990,12 @@ func (m *Manager) startStation(ctx context.Context, stationID string) error {
 
 // StopStation stops a specific station
 func (m *Manager) StopStation(ctx context.Context, stationID string) error {
+	return m.stopStation(stationID, "manual stop", false)
+}
+
+// stopStation disconnects a station. Aborting drops the connection without
+// a close handshake and discards unsent messages.
+func (m *Manager) stopStation(stationID, reason string, abort bool) error {
 	m.mu.RLock()
 	station, exists := m.stations[stationID]
 	m.mu.RUnlock()
@@ -1666,14 +5007,22 @@ func (m *Manager) StopStation(ctx context.Context, stationID string) error {
 	station.mu.Lock()
 	defer station.mu.Unlock()
 
-	m.logger.Info("Stopping station", "stationId", stationID)
+	m.logger.Info("Stopping station", "stationId", stationID, "reason", reason)
+
+	if station.MessageQueue != nil {
+		station.MessageQueue.SetOnline(false)
+	}
 
 	// Update state
-	station.StateMachine.SetState(StateStopping, "manual stop")
+	station.StateMachine.SetState(StateStopping, reason)
 	station.RuntimeState.State = StateStopping
 
 	// Disconnect WebSocket
-	if err := m.connManager.DisconnectStation(stationID); err != nil {
+	disconnect := m.connManager.DisconnectStation
+	if abort {
+		disconnect = m.connManager.AbortStation
+	}
+	if err := disconnect(stationID); err != nil {
 		m.logger.Error("Failed to disconnect station", "stationId", stationID, "error", err)
 	}
 
@@ -1682,6 +5031,7 @@ func (m *Manager) StopStation(ctx context.Context, stationID string) error {
 	station.RuntimeState.State = StateDisconnected
 	station.RuntimeState.ConnectionStatus = "disconnected"
 	station.RuntimeState.ConnectedAt = nil
+	station.RuntimeState.NetworkSlot = 0
 
 	return nil
 }
@@ -1723,15 +5073,17 @@ func (m *Manager) AddStation(ctx context.Context, config Config) error {
 	}
 
 	// Create station instance
+	certStore := v201.NewCertificateStore(config.StationID, config.Vendor, "US")
 	station := &Station{
 		Config:           config,
 		StateMachine:     NewStateMachine(),
 		DeviceModel:      v201.NewDeviceModel(),
-		CertificateStore: v201.NewCertificateStore(config.StationID, config.Vendor, "US"),
-		pendingRequests:  make(map[string]string),
-		pendingStartTx:   make(map[string]int),
-		pendingStartTags: make(map[string]string),
-		pendingAuthResp:  make(map[string]chan *v16.AuthorizeResponse),
+		CertificateStore: certStore,
+		Firmware:         NewFirmwareManager(config.StationID, config.Simulation.Firmware, m.logger),
+		Security:         NewSecurityManager(config.StationID, config.SecurityProfile, certStore, m.logger),
+		Configuration:    NewConfigurationStore(config, m.logger),
+		MessageQueue:     NewMessageQueue(config.StationID, m.logger),
+		DisplayMessages:  NewDisplayMessageStore(),
 		failedAuths:      make(map[string]time.Time),
 		RuntimeState: RuntimeState{
 			State:            StateDisconnected,
@@ -1747,6 +5099,16 @@ func (m *Manager) AddStation(ctx context.Context, config Config) error {
 		station.DeviceModel.AddEVSEComponent(conn.ID)
 		station.DeviceModel.AddConnectorComponent(conn.ID, 1, conn.Type)
 	}
+	m.restoreDeviceModel(station.DeviceModel, config)
+
+	if m.db != nil {
+		station.MessageQueue.SetRepository(storage.NewMessageQueueRepository(m.db))
+	}
+
+	m.setupFirmwareManagerCallbacks(station)
+	m.setupSecurityManagerCallbacks(station)
+	m.setupConfigurationCallbacks(station)
+	m.setupMessageQueueCallbacks(station)
 
 	m.stations[config.StationID] = station
 
@@ -1782,6 +5144,7 @@ func (m *Manager) RemoveStation(ctx context.Context, stationID string) error {
 
 	// Remove from memory
 	delete(m.stations, stationID)
+	station.Firmware.Shutdown()
 
 	// Remove from MongoDB
 	collection := m.db.StationsCollection
@@ -1790,6 +5153,14 @@ func (m *Manager) RemoveStation(ctx context.Context, stationID string) error {
 		return fmt.Errorf("failed to delete station from database: %w", err)
 	}
 
+	if err := storage.NewAuthorizationRepository(m.db).Delete(ctx, stationID); err != nil {
+		m.logger.Warn("Failed to delete local authorization data", "stationId", stationID, "error", err)
+	}
+
+	if err := storage.NewMessageQueueRepository(m.db).Delete(ctx, stationID); err != nil {
+		m.logger.Warn("Failed to delete message queue", "stationId", stationID, "error", err)
+	}
+
 	m.logger.Info("Removed station", "stationId", stationID)
 	return nil
 }
@@ -1805,10 +5176,21 @@ func (m *Manager) UpdateStation(ctx context.Context, stationID string, config Co
 	}
 
 	station.mu.Lock()
+	// The device model is only changed through SetVariables and profile imports,
+	// the network profiles through SetNetworkProfile
+	config.DeviceModelProfile = station.Config.DeviceModelProfile
+	config.DeviceModelValues = station.Config.DeviceModelValues
+	config.NetworkProfiles = station.Config.NetworkProfiles
 	station.Config = config
 	station.Config.UpdatedAt = time.Now()
 	station.mu.Unlock()
 
+	station.Firmware.SetConfig(config.Simulation.Firmware)
+	station.Security.SetProfile(config.SecurityProfile)
+	if station.SessionManager != nil {
+		station.SessionManager.SetEVConfig(config.Simulation.EV)
+	}
+
 	// Persist to MongoDB
 	if err := m.saveStationToDB(ctx, station); err != nil {
 		return fmt.Errorf("failed to update station in database: %w", err)
@@ -1909,16 +5291,26 @@ func (m *Manager) OnStationConnected(stationID string) {
 		return
 	}
 
+	// Network configuration slot the connection was established through
+	var slot int
+	if stats, err := m.connManager.GetConnectionStats(stationID); err == nil {
+		slot = stats.Slot
+	}
+	if slot > 0 && station.DeviceModel != nil {
+		station.DeviceModel.UpdateActualValue("OCPPCommCtrlr", "", "ActiveNetworkProfile", strconv.Itoa(slot), "")
+	}
+
 	station.mu.Lock()
 	now := time.Now()
 	station.StateMachine.SetState(StateConnected, "websocket connected")
 	station.RuntimeState.State = StateConnected
 	station.RuntimeState.ConnectionStatus = "connected"
 	station.RuntimeState.ConnectedAt = &now
+	station.RuntimeState.NetworkSlot = slot
 	station.RuntimeState.LastError = ""
 	station.mu.Unlock()
 
-	m.logger.Info("Station connected", "stationId", stationID)
+	m.logger.Info("Station connected", "stationId", stationID, "networkSlot", slot)
 
 	if station.SessionManager != nil {
 		station.SessionManager.NotifyStationState("initial connector snapshot")
@@ -1938,8 +5330,17 @@ func (m *Manager) OnStationDisconnected(stationID string, err error) {
 		return
 	}
 
-	// Stop heartbeat
+	// Stop heartbeat and periodic monitors
 	m.stopHeartbeat(station)
+	m.stopMonitoring(station)
+
+	// Queue transaction messages until the station is back online
+	if station.MessageQueue != nil {
+		station.MessageQueue.SetOnline(false)
+	}
+
+	// Responses to outstanding requests are lost with the connection
+	m.requestTracker(station).Reset(nil)
 
 	station.mu.Lock()
 	defer station.mu.Unlock()
@@ -1948,6 +5349,7 @@ func (m *Manager) OnStationDisconnected(stationID string, err error) {
 	station.RuntimeState.State = StateDisconnected
 	station.RuntimeState.ConnectionStatus = "disconnected"
 	station.RuntimeState.ConnectedAt = nil
+	station.RuntimeState.NetworkSlot = 0
 
 	if err != nil {
 		station.RuntimeState.LastError = err.Error()
@@ -1971,7 +5373,9 @@ func (m *Manager) OnMessageReceived(stationID string, message []byte) {
 	// Handle different message types
 	switch typedMsg := msg.(type) {
 	case *ocpp.Call:
-		m.handleCall(stationID, typedMsg)
+		// Handlers may await responses from the CSMS, which arrive on this read loop;
+		// handleCall serializes the Calls of a station
+		go m.handleCall(stationID, typedMsg)
 	case *ocpp.CallResult:
 		m.handleCallResult(stationID, typedMsg)
 	case *ocpp.CallError:
@@ -1999,6 +5403,11 @@ func (m *Manager) handleCall(stationID string, call *ocpp.Call) {
 		return
 	}
 
+	// The CSMS awaits each response before its next Call; handling them in order also keeps
+	// the actions scheduled with afterResponse scoped to the Call that scheduled them
+	station.callMu.Lock()
+	defer station.callMu.Unlock()
+
 	station.mu.RLock()
 	protocolVersion := station.Config.ProtocolVersion
 	station.mu.RUnlock()
@@ -2007,23 +5416,27 @@ func (m *Manager) handleCall(stationID string, call *ocpp.Call) {
 	var response interface{}
 	var err error
 
-	switch protocolVersion {
-	case "ocpp1.6", "1.6":
-		response, err = m.v16Handler.HandleCall(stationID, call)
-	case "ocpp2.0.1", "2.0.1", "ocpp201":
-		response, err = m.v201Handler.HandleCall(stationID, call)
-	case "ocpp2.1", "2.1", "ocpp21":
-		response, err = m.v21Handler.HandleCall(stationID, call)
-	default:
+	version, known := parseProtocolVersion(protocolVersion)
+	if !known {
 		m.logger.Error("Unsupported protocol version", "stationId", stationID, "version", protocolVersion)
 		m.sendNotImplementedError(stationID, call.UniqueID, call.Action)
 		return
 	}
 
+	switch version {
+	case v201.OCPPVersion16:
+		response, err = m.v16Handler.HandleCall(stationID, call)
+	case v201.OCPPVersion20:
+		response, err = m.v201Handler.HandleCall(stationID, call)
+	case v201.OCPPVersion21:
+		response, err = m.v21Handler.HandleCall(stationID, call)
+	}
+
 	// Handle errors
 	if err != nil {
 		m.logger.Error("Failed to handle call", "stationId", stationID, "action", call.Action, "error", err)
 		m.sendNotImplementedError(stationID, call.UniqueID, call.Action)
+		m.runAfterResponseActions(station)
 		return
 	}
 
@@ -2031,6 +5444,34 @@ func (m *Manager) handleCall(stationID string, call *ocpp.Call) {
 	if err := m.sendCallResult(stationID, call.UniqueID, response); err != nil {
 		m.logger.Error("Failed to send response", "stationId", stationID, "error", err)
 	}
+
+	m.runAfterResponseActions(station)
+}
+
+// afterResponse schedules an action to run once the response to the Call currently
+// being handled for the station has been sent (e.g. messages requested by TriggerMessage)
+func (m *Manager) afterResponse(station *Station, action func()) {
+	station.afterResponseMu.Lock()
+	station.afterResponse = append(station.afterResponse, action)
+	station.afterResponseMu.Unlock()
+}
+
+// runAfterResponseActions runs the actions scheduled while handling a Call
+func (m *Manager) runAfterResponseActions(station *Station) {
+	station.afterResponseMu.Lock()
+	actions := station.afterResponse
+	station.afterResponse = nil
+	station.afterResponseMu.Unlock()
+
+	if len(actions) == 0 {
+		return
+	}
+
+	go func() {
+		for _, action := range actions {
+			action()
+		}
+	}()
 }
 
 // handleCallResult handles CallResult responses
@@ -2050,18 +5491,13 @@ func (m *Manager) handleCallResult(stationID string, result *ocpp.CallResult) {
 		return
 	}
 
-	// Get and remove pending request
-	station.pendingMu.Lock()
-	action, hasPending := station.pendingRequests[result.UniqueID]
-	if hasPending {
-		delete(station.pendingRequests, result.UniqueID)
-	}
-	station.pendingMu.Unlock()
-
-	if !hasPending {
+	// Complete the outstanding request, waking up whoever awaits the response
+	pending := m.requestTracker(station).HandleResult(result)
+	if pending == nil {
 		m.logger.Debug("No pending request for CallResult", "stationId", stationID, "uniqueId", result.UniqueID)
 		return
 	}
+	action := pending.Call.Action
 
 	// Get protocol version
 	station.mu.RLock()
@@ -2075,12 +5511,8 @@ func (m *Manager) handleCallResult(stationID string, result *ocpp.CallResult) {
 		m.handleBootNotificationResponse(stationID, station, result, protocolVersion)
 	case "Heartbeat":
 		m.handleHeartbeatResponse(stationID, station, result)
-	case "Authorize":
-		m.handleAuthorizeResponse(stationID, station, result)
-	case "StartTransaction":
-		m.handleStartTransactionResponse(stationID, station, result)
-	case "TransactionEvent":
-		m.handleTransactionEventResponse(stationID, station, result)
+	case "SignCertificate":
+		m.handleSignCertificateResponse(stationID, result)
 	default:
 		m.logger.Debug("CallResult for action", "stationId", stationID, "action", action)
 	}
@@ -2097,6 +5529,20 @@ func (m *Manager) handleCallError(stationID string, callError *ocpp.CallError) {
 
 	// Store message in MongoDB
 	go m.storeMessage(stationID, "received", callError)
+
+	// Get station
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	// The request fails with the CallError as error
+	if m.requestTracker(station).HandleError(callError) == nil {
+		m.logger.Debug("No pending request for CallError", "stationId", stationID, "uniqueId", callError.UniqueID)
+	}
 }
 
 // sendBootNotification sends a BootNotification request
@@ -2119,12 +5565,21 @@ func (m *Manager) sendBootNotification(stationID string) {
 	imsi := station.Config.IMSI
 	station.mu.RUnlock()
 
+	// Report why the station booted, once
+	station.mu.Lock()
+	bootReason := station.bootReason
+	station.bootReason = ""
+	station.mu.Unlock()
+	if bootReason == "" {
+		bootReason = v201.BootReasonPowerUp
+	}
+
 	var call *ocpp.Call
 	var err error
 
-	switch protocolVersion {
-	case "ocpp2.0.1", "2.0.1", "ocpp201":
-		// OCPP 2.0.1 BootNotification
+	switch {
+	case usesTransactionEvents(protocolVersion):
+		// OCPP 2.0.1/2.1 BootNotification
 		req := v201.BootNotificationRequest{
 			ChargingStation: v201.ChargingStation{
 				Model:           model,
@@ -2132,7 +5587,7 @@ func (m *Manager) sendBootNotification(stationID string) {
 				SerialNumber:    serialNumber,
 				FirmwareVersion: firmwareVersion,
 			},
-			Reason: v201.BootReasonPowerUp,
+			Reason: bootReason,
 		}
 		// Add modem info if available
 		if iccid != "" || imsi != "" {
@@ -2160,18 +5615,7 @@ func (m *Manager) sendBootNotification(stationID string) {
 		return
 	}
 
-	// Track pending request
-	station.pendingMu.Lock()
-	station.pendingRequests[call.UniqueID] = string(v16.ActionBootNotification)
-	station.pendingMu.Unlock()
-
-	data, err := call.ToBytes()
-	if err != nil {
-		m.logger.Error("Failed to marshal BootNotification", "stationId", stationID, "error", err)
-		return
-	}
-
-	if err := m.connManager.SendMessage(stationID, data); err != nil {
+	if _, err := m.sendCall(station, call, 0); err != nil {
 		m.logger.Error("Failed to send BootNotification", "stationId", stationID, "error", err)
 		return
 	}
@@ -2182,6 +5626,51 @@ func (m *Manager) sendBootNotification(stationID string) {
 	go m.storeMessage(stationID, "sent", call)
 }
 
+// requestTracker returns the tracker of the Calls sent to the CSMS by a station
+func (m *Manager) requestTracker(station *Station) *ocpp.RequestTracker {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	if station.requests == nil {
+		stationID := station.Config.StationID
+		station.requests = ocpp.NewRequestTracker(stationID, func(data []byte) error {
+			return m.connManager.SendMessage(stationID, data)
+		}, m.callTimeout, m.logger)
+		station.requests.OnTimeout = func(call *ocpp.Call) {
+			go m.storeTimeout(stationID, call)
+		}
+	}
+	return station.requests
+}
+
+// sendCall sends a Call to the CSMS and returns the pending call to await its response.
+// Calls wait while another Call of the station is outstanding, a timeout of 0 uses the default.
+func (m *Manager) sendCall(station *Station, call *ocpp.Call, timeout time.Duration) (*ocpp.PendingCall, error) {
+	stationID := station.Config.StationID
+
+	if m.connManager == nil || !m.connManager.IsConnected(stationID) {
+		return nil, fmt.Errorf("station %s is not connected", stationID)
+	}
+
+	return m.requestTracker(station).SendWithTimeout(call, timeout)
+}
+
+// sendMessage sends a message built by a protocol handler, Calls are tracked until answered
+func (m *Manager) sendMessage(stationID string, data []byte) error {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	msg, err := ocpp.ParseMessage(data)
+	call, isCall := msg.(*ocpp.Call)
+	if !exists || err != nil || !isCall {
+		return m.connManager.SendMessage(stationID, data)
+	}
+
+	_, err = m.sendCall(station, call, 0)
+	return err
+}
+
 // sendNotImplementedError sends a NotImplemented error response
 func (m *Manager) sendNotImplementedError(stationID, uniqueID, action string) {
 	callError, err := ocpp.NewCallError(
@@ -2230,21 +5719,9 @@ func (m *Manager) sendCallResult(stationID, uniqueID string, payload interface{}
 
 // storeMessage stores a message using the message logger
 func (m *Manager) storeMessage(stationID, direction string, message interface{}) {
-	// Get protocol version from station
-	m.mu.RLock()
-	station, exists := m.stations[stationID]
-	m.mu.RUnlock()
-
-	protocolVersion := "ocpp1.6" // default
-	if exists {
-		station.mu.RLock()
-		protocolVersion = station.Config.ProtocolVersion
-		station.mu.RUnlock()
-	}
-
 	// Log message using message logger
 	if m.messageLogger != nil {
-		if err := m.messageLogger.LogMessage(stationID, direction, message, protocolVersion); err != nil {
+		if err := m.messageLogger.LogMessage(stationID, direction, message, m.protocolVersion(stationID)); err != nil {
 			m.logger.Error("Failed to log message",
 				"stationId", stationID,
 				"direction", direction,
@@ -2254,6 +5731,34 @@ func (m *Manager) storeMessage(stationID, direction string, message interface{})
 	}
 }
 
+// storeTimeout records a Call that was not answered in time in the message log
+func (m *Manager) storeTimeout(stationID string, call *ocpp.Call) {
+	if m.messageLogger != nil {
+		if err := m.messageLogger.LogTimeout(stationID, call, m.protocolVersion(stationID)); err != nil {
+			m.logger.Error("Failed to log call timeout",
+				"stationId", stationID,
+				"action", call.Action,
+				"error", err,
+			)
+		}
+	}
+}
+
+// protocolVersion returns the protocol version of a station, OCPP 1.6 for unknown stations
+func (m *Manager) protocolVersion(stationID string) string {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return "ocpp1.6"
+	}
+
+	station.mu.RLock()
+	defer station.mu.RUnlock()
+	return station.Config.ProtocolVersion
+}
+
 // saveStationToDB persists station to MongoDB
 func (m *Manager) saveStationToDB(ctx context.Context, station *Station) error {
 	station.mu.RLock()
@@ -2265,6 +5770,10 @@ func (m *Manager) saveStationToDB(ctx context.Context, station *Station) error {
 	dbStation.LastError = station.RuntimeState.LastError
 	station.mu.RUnlock()
 
+	if station.Configuration != nil {
+		dbStation.OCPPConfiguration = station.Configuration.Values()
+	}
+
 	// Update connector states from SessionManager
 	if station.SessionManager != nil {
 		connectors := station.SessionManager.GetAllConnectors()
@@ -2322,6 +5831,8 @@ func (m *Manager) convertStorageToConfig(dbStation storage.Station) Config {
 			MaxPower:             conn.MaxPower,
 			Status:               conn.Status,
 			CurrentTransactionID: conn.CurrentTransactionID,
+			PlugAndCharge:        conn.PlugAndCharge,
+			EMAID:                conn.EMAID,
 		}
 	}
 
@@ -2334,6 +5845,35 @@ func (m *Manager) convertStorageToConfig(dbStation storage.Station) Config {
 		}
 	}
 
+	var deviceModelProfile *v201.DeviceModelProfile
+	if dbStation.DeviceModelProfile != "" {
+		deviceModelProfile = &v201.DeviceModelProfile{}
+		if err := json.Unmarshal([]byte(dbStation.DeviceModelProfile), deviceModelProfile); err != nil {
+			m.logger.Warn("Failed to parse device model profile", "stationId", dbStation.StationID, "error", err)
+			deviceModelProfile = nil
+		}
+	}
+
+	var networkProfiles []NetworkProfile
+	if dbStation.NetworkProfiles != "" {
+		if err := json.Unmarshal([]byte(dbStation.NetworkProfiles), &networkProfiles); err != nil {
+			m.logger.Warn("Failed to parse network profiles", "stationId", dbStation.StationID, "error", err)
+			networkProfiles = nil
+		}
+	}
+
+	deviceModelValues := make([]v201.VariableValue, len(dbStation.DeviceModelValues))
+	for i, value := range dbStation.DeviceModelValues {
+		deviceModelValues[i] = v201.VariableValue{
+			Component:         value.Component,
+			ComponentInstance: value.ComponentInstance,
+			Variable:          value.Variable,
+			VariableInstance:  value.VariableInstance,
+			AttributeType:     v201.AttributeType(value.AttributeType),
+			Value:             value.Value,
+		}
+	}
+
 	return Config{
 		ID:                dbStation.ID,
 		StationID:         dbStation.StationID,
@@ -2354,8 +5894,10 @@ func (m *Manager) convertStorageToConfig(dbStation storage.Station) Config {
 			Measurands:          dbStation.MeterValuesConfig.Measurands,
 			AlignedDataInterval: dbStation.MeterValuesConfig.AlignedDataInterval,
 		},
-		CSMSURL:  dbStation.CSMSURL,
-		CSMSAuth: csmsAuth,
+		CSMSURL:         dbStation.CSMSURL,
+		CSMSAuth:        csmsAuth,
+		SecurityProfile: dbStation.SecurityProfile,
+		NetworkProfiles: networkProfiles,
 		Simulation: SimulationConfig{
 			BootDelay:                  dbStation.Simulation.BootDelay,
 			HeartbeatInterval:          dbStation.Simulation.HeartbeatInterval,
@@ -2364,10 +5906,15 @@ func (m *Manager) convertStorageToConfig(dbStation storage.Station) Config {
 			EnergyDeliveryRate:         dbStation.Simulation.EnergyDeliveryRate,
 			RandomizeMeterValues:       dbStation.Simulation.RandomizeMeterValues,
 			MeterValueVariance:         dbStation.Simulation.MeterValueVariance,
+			Firmware:                   FirmwareSimulationConfig(dbStation.Simulation.Firmware),
+			EV:                         EVSimulationConfig(dbStation.Simulation.EV),
 		},
-		CreatedAt: dbStation.CreatedAt,
-		UpdatedAt: dbStation.UpdatedAt,
-		Tags:      dbStation.Tags,
+		OCPPConfiguration:  dbStation.OCPPConfiguration,
+		DeviceModelProfile: deviceModelProfile,
+		DeviceModelValues:  deviceModelValues,
+		CreatedAt:          dbStation.CreatedAt,
+		UpdatedAt:          dbStation.UpdatedAt,
+		Tags:               dbStation.Tags,
 	}
 }
 
@@ -2381,6 +5928,8 @@ func (m *Manager) convertConfigToStorage(config Config) storage.Station {
 			MaxPower:             conn.MaxPower,
 			Status:               conn.Status,
 			CurrentTransactionID: conn.CurrentTransactionID,
+			PlugAndCharge:        conn.PlugAndCharge,
+			EMAID:                conn.EMAID,
 		}
 	}
 
@@ -2393,6 +5942,26 @@ func (m *Manager) convertConfigToStorage(config Config) storage.Station {
 		}
 	}
 
+	var deviceModelProfile string
+	if config.DeviceModelProfile != nil {
+		data, err := json.Marshal(config.DeviceModelProfile)
+		if err != nil {
+			m.logger.Warn("Failed to encode device model profile", "stationId", config.StationID, "error", err)
+		} else {
+			deviceModelProfile = string(data)
+		}
+	}
+
+	var networkProfiles string
+	if len(config.NetworkProfiles) > 0 {
+		data, err := json.Marshal(config.NetworkProfiles)
+		if err != nil {
+			m.logger.Warn("Failed to encode network profiles", "stationId", config.StationID, "error", err)
+		} else {
+			networkProfiles = string(data)
+		}
+	}
+
 	return storage.Station{
 		ID:                config.ID,
 		StationID:         config.StationID,
@@ -2413,8 +5982,10 @@ func (m *Manager) convertConfigToStorage(config Config) storage.Station {
 			Measurands:          config.MeterValuesConfig.Measurands,
 			AlignedDataInterval: config.MeterValuesConfig.AlignedDataInterval,
 		},
-		CSMSURL:  config.CSMSURL,
-		CSMSAuth: csmsAuth,
+		CSMSURL:         config.CSMSURL,
+		CSMSAuth:        csmsAuth,
+		SecurityProfile: config.SecurityProfile,
+		NetworkProfiles: networkProfiles,
 		Simulation: storage.SimulationConfig{
 			BootDelay:                  config.Simulation.BootDelay,
 			HeartbeatInterval:          config.Simulation.HeartbeatInterval,
@@ -2423,11 +5994,36 @@ func (m *Manager) convertConfigToStorage(config Config) storage.Station {
 			EnergyDeliveryRate:         config.Simulation.EnergyDeliveryRate,
 			RandomizeMeterValues:       config.Simulation.RandomizeMeterValues,
 			MeterValueVariance:         config.Simulation.MeterValueVariance,
+			Firmware:                   storage.FirmwareSimulationConfig(config.Simulation.Firmware),
+			EV:                         storage.EVSimulationConfig(config.Simulation.EV),
 		},
-		CreatedAt: config.CreatedAt,
-		UpdatedAt: config.UpdatedAt,
-		Tags:      config.Tags,
+		OCPPConfiguration:  config.OCPPConfiguration,
+		DeviceModelValues:  convertDeviceModelValuesToStorage(config.DeviceModelValues),
+		DeviceModelProfile: deviceModelProfile,
+		CreatedAt:          config.CreatedAt,
+		UpdatedAt:          config.UpdatedAt,
+		Tags:               config.Tags,
+	}
+}
+
+// convertDeviceModelValuesToStorage converts device model values to their storage representation
+func convertDeviceModelValuesToStorage(values []v201.VariableValue) []storage.DeviceModelValue {
+	if len(values) == 0 {
+		return nil
 	}
+
+	dbValues := make([]storage.DeviceModelValue, len(values))
+	for i, value := range values {
+		dbValues[i] = storage.DeviceModelValue{
+			Component:         value.Component,
+			ComponentInstance: value.ComponentInstance,
+			Variable:          value.Variable,
+			VariableInstance:  value.VariableInstance,
+			AttributeType:     string(value.AttributeType),
+			Value:             value.Value,
+		}
+	}
+	return dbValues
 }
 
 // Shutdown gracefully shuts down the manager
@@ -2454,6 +6050,11 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Cancel running firmware updates and diagnostics uploads
+	for _, station := range m.GetAllStations() {
+		station.Firmware.Shutdown()
+	}
+
 	// Final state sync
 	m.syncState()
 
@@ -2473,13 +6074,17 @@ func (m *Manager) GetStats() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	var connected, disconnected, charging, available, faulted, unavailable int
+	var connected, disconnected, charging, available, faulted, unavailable, queuedMessages int
 
 	for _, station := range m.stations {
 		station.mu.RLock()
 		state := station.StateMachine.GetState()
 		station.mu.RUnlock()
 
+		if station.MessageQueue != nil {
+			queuedMessages += station.MessageQueue.Len()
+		}
+
 		if station.StateMachine.IsConnected() {
 			connected++
 		} else if state == StateDisconnected || state == StateUnknown {
@@ -2499,14 +6104,15 @@ func (m *Manager) GetStats() map[string]interface{} {
 	}
 
 	stats := map[string]interface{}{
-		"total":        len(m.stations),
-		"connected":    connected,
-		"disconnected": disconnected,
-		"charging":     charging,
-		"available":    available,
-		"faulted":      faulted,
-		"unavailable":  unavailable,
-		"syncInterval": m.syncInterval.String(),
+		"total":          len(m.stations),
+		"connected":      connected,
+		"disconnected":   disconnected,
+		"charging":       charging,
+		"available":      available,
+		"faulted":        faulted,
+		"unavailable":    unavailable,
+		"queuedMessages": queuedMessages,
+		"syncInterval":   m.syncInterval.String(),
 	}
 
 	// Add message logger stats if available
@@ -2534,8 +6140,8 @@ func (m *Manager) handleBootNotificationResponse(stationID string, station *Stat
 	var status string
 	var interval int
 
-	switch protocolVersion {
-	case "ocpp2.0.1", "2.0.1", "ocpp201":
+	switch {
+	case usesTransactionEvents(protocolVersion):
 		var resp v201.BootNotificationResponse
 		if err := json.Unmarshal(result.Payload, &resp); err != nil {
 			m.logger.Error("Failed to unmarshal BootNotification response (2.0.1)", "stationId", stationID, "error", err)
@@ -2566,83 +6172,69 @@ func (m *Manager) handleBootNotificationResponse(stationID string, station *Stat
 	}
 
 	if status == "Accepted" {
-		// Start heartbeat with interval from CSMS (or use configured default)
-		if interval <= 0 {
-			station.mu.RLock()
-			interval = station.Config.Simulation.HeartbeatInterval
-			station.mu.RUnlock()
-			if interval <= 0 {
-				interval = 60 // Default to 60 seconds
-			}
+		// Start heartbeat with interval from CSMS (or use the configured HeartbeatInterval)
+		if interval > 0 {
+			station.Configuration.Set(ConfigKeyHeartbeatInterval, strconv.Itoa(interval))
+		} else {
+			interval = station.Configuration.Int(ConfigKeyHeartbeatInterval)
 		}
 
 		m.startHeartbeat(stationID, station, interval)
 
+		// Evaluate periodic variable monitors while connected
+		if station.DeviceModel != nil && station.usesTransactionEvents() {
+			m.startMonitoring(station)
+		}
+
 		// Send initial StatusNotification for all connectors
 		go m.sendAllConnectorStatus(stationID, station)
+
+		// Report security events recorded while the station was offline
+		go station.Security.SendUnsentEvents()
+
+		// Deliver transaction messages queued while the station was offline
+		if station.MessageQueue != nil {
+			station.MessageQueue.SetOnline(true)
+		}
 	}
 }
 
-// handleHeartbeatResponse processes Heartbeat responses
-func (m *Manager) handleHeartbeatResponse(stationID string, station *Station, result *ocpp.CallResult) {
-	var resp v16.HeartbeatResponse
+// handleSignCertificateResponse processes SignCertificate responses
+func (m *Manager) handleSignCertificateResponse(stationID string, result *ocpp.CallResult) {
+	// OCPP 1.6 and 2.0.1 responses share the status field
+	var resp v16.SignCertificateResponse
 	if err := json.Unmarshal(result.Payload, &resp); err != nil {
-		m.logger.Error("Failed to unmarshal Heartbeat response", "stationId", stationID, "error", err)
+		m.logger.Error("Failed to unmarshal SignCertificate response", "stationId", stationID, "error", err)
 		return
 	}
 
-	now := time.Now()
-	station.mu.Lock()
-	station.RuntimeState.LastHeartbeat = &now
-	station.mu.Unlock()
+	if resp.Status != v16.GenericStatusAccepted {
+		m.logger.Warn("SignCertificate rejected by CSMS", "stationId", stationID, "status", resp.Status)
+		return
+	}
 
-	m.logger.Debug("Heartbeat acknowledged", "stationId", stationID, "serverTime", resp.CurrentTime)
+	m.logger.Info("SignCertificate accepted, awaiting CertificateSigned", "stationId", stationID)
 }
 
-// handleAuthorizeResponse processes Authorize responses
-func (m *Manager) handleAuthorizeResponse(stationID string, station *Station, result *ocpp.CallResult) {
-	var resp v16.AuthorizeResponse
+// handleHeartbeatResponse processes Heartbeat responses
+func (m *Manager) handleHeartbeatResponse(stationID string, station *Station, result *ocpp.CallResult) {
+	var resp v16.HeartbeatResponse
 	if err := json.Unmarshal(result.Payload, &resp); err != nil {
-		m.logger.Error("Failed to unmarshal Authorize response", "stationId", stationID, "error", err)
+		m.logger.Error("Failed to unmarshal Heartbeat response", "stationId", stationID, "error", err)
 		return
 	}
-
-	m.logger.Debug("Processing Authorize response",
-		"stationId", stationID,
-		"messageId", result.UniqueID,
-		"status", resp.IdTagInfo.Status,
-	)
-
-	// Find the response channel and send the response
-	station.pendingAuthRespMu.Lock()
-	respChan, found := station.pendingAuthResp[result.UniqueID]
-	delete(station.pendingAuthResp, result.UniqueID)
-	station.pendingAuthRespMu.Unlock()
-
-	if found {
-		// Send response to waiting goroutine (non-blocking)
-		select {
-		case respChan <- &resp:
-			m.logger.Debug("Sent Authorize response to waiting goroutine",
-				"stationId", stationID,
-				"messageId", result.UniqueID,
-			)
-		default:
-			m.logger.Warn("Failed to send Authorize response - channel full or closed",
-				"stationId", stationID,
-				"messageId", result.UniqueID,
-			)
-		}
-	} else {
-		m.logger.Warn("No waiting goroutine for Authorize response",
-			"stationId", stationID,
-			"messageId", result.UniqueID,
-		)
-	}
+
+	now := time.Now()
+	station.mu.Lock()
+	station.RuntimeState.LastHeartbeat = &now
+	station.mu.Unlock()
+
+	m.logger.Debug("Heartbeat acknowledged", "stationId", stationID, "serverTime", resp.CurrentTime)
 }
 
-// handleStartTransactionResponse processes StartTransaction responses
-func (m *Manager) handleStartTransactionResponse(stationID string, station *Station, result *ocpp.CallResult) {
+// handleStartTransactionResponse processes the response to the StartTransaction request of a
+// transaction running on a connector with a temporary ID
+func (m *Manager) handleStartTransactionResponse(stationID string, station *Station, result *ocpp.CallResult, connectorID int, idTag string, localID int) {
 	var resp v16.StartTransactionResponse
 	if err := json.Unmarshal(result.Payload, &resp); err != nil {
 		m.logger.Error("Failed to unmarshal StartTransaction response", "stationId", stationID, "error", err)
@@ -2656,6 +6248,16 @@ func (m *Manager) handleStartTransactionResponse(stationID string, station *Stat
 		"idTagStatus", resp.IdTagInfo.Status,
 	)
 
+	// Keep the authorization cache in sync with the CSMS decision
+	if idTag != "" && station.SessionManager != nil {
+		station.SessionManager.Authorization().UpdateCache(idTag, resp.IdTagInfo)
+	}
+
+	// Messages queued for the transaction still carry its temporary ID
+	if localID != 0 && station.MessageQueue != nil {
+		station.MessageQueue.RemapTransactionID(localID, resp.TransactionId)
+	}
+
 	// Check if transaction was accepted
 	if resp.IdTagInfo.Status != v16.AuthorizationStatusAccepted {
 		m.logger.Warn("Transaction rejected by CSMS",
@@ -2664,64 +6266,28 @@ func (m *Manager) handleStartTransactionResponse(stationID string, station *Stat
 			"status", resp.IdTagInfo.Status,
 		)
 
-		// Find which connector this response is for and stop the transaction
-		station.pendingStartMu.Lock()
-		connectorID, found := station.pendingStartTx[result.UniqueID]
-		delete(station.pendingStartTx, result.UniqueID)
-		station.pendingStartMu.Unlock()
-
-		if found {
-			// Get the connector
-			connector, err := station.SessionManager.GetConnector(connectorID)
-			if err == nil && connector.HasActiveTransaction() {
-				m.logger.Info("Stopping transaction that was rejected by CSMS",
+		// Stop the transaction running on the connector
+		connector, err := station.SessionManager.GetConnector(connectorID)
+		if err == nil && connector.HasActiveTransaction() {
+			m.logger.Info("Stopping transaction that was rejected by CSMS",
+				"stationId", stationID,
+				"connectorId", connectorID,
+				"transactionId", resp.TransactionId,
+			)
+
+			// Stop the transaction
+			if err := station.SessionManager.StopCharging(connectorID, v16.ReasonDeAuthorized); err != nil {
+				m.logger.Error("Failed to stop rejected transaction",
 					"stationId", stationID,
 					"connectorId", connectorID,
-					"transactionId", resp.TransactionId,
+					"error", err,
 				)
-
-				// Stop the transaction
-				if err := station.SessionManager.StopCharging(connectorID, v16.ReasonDeAuthorized); err != nil {
-					m.logger.Error("Failed to stop rejected transaction",
-						"stationId", stationID,
-						"connectorId", connectorID,
-						"error", err,
-					)
-				}
 			}
 		}
 
 		return
 	}
 
-	// Find which connector and idTag this response is for
-	station.pendingStartMu.Lock()
-	m.logger.Debug("Looking up pending StartTransaction",
-		"stationId", stationID,
-		"messageId", result.UniqueID,
-		"pendingCount", len(station.pendingStartTx),
-	)
-	connectorID, found := station.pendingStartTx[result.UniqueID]
-	idTag := station.pendingStartTags[result.UniqueID]
-	delete(station.pendingStartTx, result.UniqueID)
-	delete(station.pendingStartTags, result.UniqueID)
-	station.pendingStartMu.Unlock()
-
-	if !found {
-		m.logger.Warn("No pending StartTransaction found for message",
-			"stationId", stationID,
-			"messageId", result.UniqueID,
-		)
-		return
-	}
-
-	m.logger.Info("Found pending StartTransaction for connector",
-		"stationId", stationID,
-		"messageId", result.UniqueID,
-		"connectorId", connectorID,
-		"idTag", idTag,
-	)
-
 	// Get the specific connector
 	connector, err := station.SessionManager.GetConnector(connectorID)
 	if err != nil {
@@ -2742,7 +6308,7 @@ func (m *Manager) handleStartTransactionResponse(stationID string, station *Stat
 		if connector.HasActiveTransaction() {
 			// Get old ID before updating (using a copy is OK here for reading)
 			tx := connector.GetTransaction()
-			if tx != nil {
+			if tx != nil && (localID == 0 || tx.ID == localID) {
 				oldID = tx.ID
 				transactionFound = true
 				break
@@ -2752,6 +6318,12 @@ func (m *Manager) handleStartTransactionResponse(stationID string, station *Stat
 	}
 
 	if !transactionFound {
+		// A transaction that ended while offline only needs its stored ID updated
+		if localID != 0 {
+			m.updateStoredTransactionID(stationID, localID, resp.TransactionId)
+			return
+		}
+
 		m.logger.Warn("Transaction not found after waiting",
 			"stationId", stationID,
 			"connectorId", connectorID,
@@ -2779,25 +6351,31 @@ func (m *Manager) handleStartTransactionResponse(stationID string, station *Stat
 	)
 
 	// Update transaction in database
-	if m.db != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	m.updateStoredTransactionID(stationID, oldID, resp.TransactionId)
+}
 
-		transactionRepo := storage.NewTransactionRepository(m.db)
-		err := transactionRepo.UpdateTransactionID(ctx, stationID, oldID, resp.TransactionId)
-		if err != nil {
-			m.logger.Error("Failed to update transaction ID in database",
-				"stationId", stationID,
-				"oldTransactionId", oldID,
-				"newTransactionId", resp.TransactionId,
-				"error", err,
-			)
-		}
+// updateStoredTransactionID replaces the ID of a stored transaction with the one assigned by the CSMS
+func (m *Manager) updateStoredTransactionID(stationID string, oldID, newID int) {
+	if m.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	transactionRepo := storage.NewTransactionRepository(m.db)
+	if err := transactionRepo.UpdateTransactionID(ctx, stationID, oldID, newID); err != nil {
+		m.logger.Error("Failed to update transaction ID in database",
+			"stationId", stationID,
+			"oldTransactionId", oldID,
+			"newTransactionId", newID,
+			"error", err,
+		)
 	}
 }
 
 // handleTransactionEventResponse processes TransactionEvent responses (OCPP 2.0.1)
-func (m *Manager) handleTransactionEventResponse(stationID string, station *Station, result *ocpp.CallResult) {
+func (m *Manager) handleTransactionEventResponse(stationID string, station *Station, req *v201.TransactionEventRequest, result *ocpp.CallResult) {
 	var resp v201.TransactionEventResponse
 	if err := json.Unmarshal(result.Payload, &resp); err != nil {
 		m.logger.Error("Failed to unmarshal TransactionEvent response", "stationId", stationID, "error", err)
@@ -2807,18 +6385,20 @@ func (m *Manager) handleTransactionEventResponse(stationID string, station *Stat
 	m.logger.Info("TransactionEvent response received",
 		"stationId", stationID,
 		"messageId", result.UniqueID,
+		"transactionId", req.TransactionInfo.TransactionId,
+		"eventType", req.EventType,
 		"totalCost", resp.TotalCost,
 		"chargingPriority", resp.ChargingPriority,
 	)
 
-	// Check if authorization info was returned
-	if resp.IdTokenInfo != nil {
-		if resp.IdTokenInfo.Status != v201.AuthorizationStatusAccepted {
-			m.logger.Warn("Transaction authorization rejected",
-				"stationId", stationID,
-				"status", resp.IdTokenInfo.Status,
-			)
-		}
+	// The running cost is shown on the display
+	if resp.TotalCost != nil {
+		station.DisplayMessages.UpdateCost(req.TransactionInfo.TransactionId, *resp.TotalCost)
+	}
+
+	// Apply the authorization info, a rejected idToken may stop the transaction
+	if station.SessionManager != nil {
+		station.SessionManager.HandleTransactionEventResponse(req, &resp)
 	}
 }
 
@@ -2883,18 +6463,7 @@ func (m *Manager) sendHeartbeat(stationID string, station *Station) {
 		return
 	}
 
-	// Track pending request
-	station.pendingMu.Lock()
-	station.pendingRequests[call.UniqueID] = string(v16.ActionHeartbeat)
-	station.pendingMu.Unlock()
-
-	data, err := call.ToBytes()
-	if err != nil {
-		m.logger.Error("Failed to marshal Heartbeat", "stationId", stationID, "error", err)
-		return
-	}
-
-	if err := m.connManager.SendMessage(stationID, data); err != nil {
+	if _, err := m.sendCall(station, call, 0); err != nil {
 		m.logger.Error("Failed to send Heartbeat", "stationID", stationID, "error", err)
 		return
 	}
@@ -2919,6 +6488,115 @@ func (m *Manager) sendAllConnectorStatus(stationID string, station *Station) {
 	}
 }
 
+// triggerCoreMessage returns the action sending a core message requested by TriggerMessage or
+// ExtendedTriggerMessage, or nil with the status to respond if it cannot be triggered
+func (m *Manager) triggerCoreMessage(stationID string, station *Station, requested string, connectorID *int) (func(), v16.TriggerMessageStatus) {
+	// A given connector must exist (0 addresses the charge point itself)
+	if connectorID != nil && *connectorID != 0 {
+		if station.SessionManager == nil {
+			return nil, v16.TriggerMessageStatusRejected
+		}
+		if _, err := station.SessionManager.GetConnector(*connectorID); err != nil {
+			return nil, v16.TriggerMessageStatusRejected
+		}
+	}
+
+	switch v16.MessageTrigger(requested) {
+	case v16.MessageTriggerBootNotification:
+		return func() { m.sendBootNotification(stationID) }, v16.TriggerMessageStatusAccepted
+
+	case v16.MessageTriggerHeartbeat:
+		return func() { m.sendHeartbeat(stationID, station) }, v16.TriggerMessageStatusAccepted
+
+	case v16.MessageTriggerStatusNotification:
+		if connectorID == nil {
+			return func() { m.sendAllConnectorStatus(stationID, station) }, v16.TriggerMessageStatusAccepted
+		}
+		id := *connectorID
+		return func() { m.sendConnectorStatus(stationID, station, id) }, v16.TriggerMessageStatusAccepted
+
+	case v16.MessageTriggerMeterValues:
+		if station.SessionManager == nil {
+			return nil, v16.TriggerMessageStatusRejected
+		}
+		return func() {
+			if connectorID != nil {
+				m.sendTriggeredMeterValues(stationID, station, *connectorID)
+				return
+			}
+			for _, connector := range station.SessionManager.GetAllConnectors() {
+				m.sendTriggeredMeterValues(stationID, station, connector.ID)
+			}
+		}, v16.TriggerMessageStatusAccepted
+
+	default:
+		return nil, v16.TriggerMessageStatusNotImplemented
+	}
+}
+
+// sendV16SignCertificate generates a CSR for the charge point certificate and sends it with SignCertificate
+func (m *Manager) sendV16SignCertificate(stationID string, station *Station) {
+	csr, err := station.Security.GenerateCSR()
+	if err != nil {
+		m.logger.Error("Failed to generate CSR", "stationId", stationID, "error", err)
+		return
+	}
+
+	call, err := m.v16Handler.SendSignCertificate(stationID, &v16.SignCertificateRequest{Csr: csr})
+	if err != nil {
+		m.logger.Error("Failed to send SignCertificate", "stationId", stationID, "error", err)
+		return
+	}
+
+	m.logger.Info("Sent SignCertificate request", "stationId", stationID, "uniqueId", call.UniqueID)
+
+	// Store sent message
+	go m.storeMessage(stationID, "sent", call)
+}
+
+// sendConnectorStatus sends StatusNotification for a single connector.
+// Connector 0 reports the charge point itself, which is Unavailable only if all connectors are.
+func (m *Manager) sendConnectorStatus(stationID string, station *Station, connectorID int) {
+	if station.SessionManager == nil {
+		return
+	}
+
+	if connectorID == 0 {
+		status := v16.ChargePointStatusAvailable
+		connectors := station.SessionManager.GetAllConnectors()
+		unavailable := 0
+		for _, connector := range connectors {
+			if connector.GetState() == ConnectorStateUnavailable {
+				unavailable++
+			}
+		}
+		if len(connectors) > 0 && unavailable == len(connectors) {
+			status = v16.ChargePointStatusUnavailable
+		}
+		m.sendStatusNotification(stationID, 0, status, v16.ChargePointErrorNoError, "")
+		return
+	}
+
+	connector, err := station.SessionManager.GetConnector(connectorID)
+	if err != nil {
+		m.logger.Warn("Cannot send StatusNotification", "stationId", stationID, "error", err)
+		return
+	}
+
+	m.sendStatusNotification(stationID, connector.ID, v16.ChargePointStatus(connector.GetState()), connector.GetErrorCode(), "")
+}
+
+// sendTriggeredMeterValues sends the current meter reading of a connector
+func (m *Manager) sendTriggeredMeterValues(stationID string, station *Station, connectorID int) {
+	if err := station.SessionManager.TriggerMeterValues(connectorID); err != nil {
+		m.logger.Error("Failed to send triggered MeterValues",
+			"stationId", stationID,
+			"connectorId", connectorID,
+			"error", err,
+		)
+	}
+}
+
 // sendStatusNotification sends a StatusNotification message
 func (m *Manager) sendStatusNotification(stationID string, connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) {
 	req := &v16.StatusNotificationRequest{
@@ -2991,18 +6669,7 @@ func (m *Manager) sendSignCertificateRequest(stationID string, station *Station,
 		return
 	}
 
-	// Track pending request
-	station.pendingMu.Lock()
-	station.pendingRequests[call.UniqueID] = string(v201.ActionSignCertificate)
-	station.pendingMu.Unlock()
-
-	data, err := call.ToBytes()
-	if err != nil {
-		m.logger.Error("Failed to marshal SignCertificate", "stationId", stationID, "error", err)
-		return
-	}
-
-	if err := m.connManager.SendMessage(stationID, data); err != nil {
+	if _, err := m.sendCall(station, call, 0); err != nil {
 		m.logger.Error("Failed to send SignCertificate", "stationId", stationID, "error", err)
 		return
 	}
@@ -3043,12 +6710,20 @@ func (m *Manager) GetConnectors(ctx context.Context, stationID string) ([]map[st
 			"errorCode": string(connector.GetErrorCode()),
 		}
 
+		// Contract of the ISO 15118 Plug & Charge EV
+		if pnc := station.SessionManager.PlugAndCharge(); pnc.Enabled(connector.ID) {
+			connectorData["plugAndCharge"] = map[string]interface{}{
+				"emaid":          pnc.EMAID(connector.ID),
+				"contractExpiry": pnc.ContractExpiry(connector.ID),
+			}
+		}
+
 		// Add transaction info if active
 		if connector.HasActiveTransaction() {
 			tx := connector.GetTransaction()
 			if tx != nil {
 				tx.mu.RLock()
-				connectorData["transaction"] = map[string]interface{}{
+				transactionData := map[string]interface{}{
 					"id":              tx.ID,
 					"idTag":           tx.IDTag,
 					"startTime":       tx.StartTime,
@@ -3056,6 +6731,18 @@ func (m *Manager) GetConnectors(ctx context.Context, stationID string) ([]map[st
 					"currentMeter":    tx.CurrentMeter,
 				}
 				tx.mu.RUnlock()
+
+				// Charging schedule negotiated by an ISO 15118 EV
+				if schedule := station.SessionManager.EVChargingSchedule(connector.ID); schedule != nil {
+					transactionData["evChargingSchedule"] = schedule
+				}
+
+				// Cost calculated by OCPP 2.1 stations from the tariff of the transaction
+				if tariffID, cost := station.SessionManager.Tariffs().RunningCost(connector.ID); cost != nil {
+					transactionData["tariffId"] = tariffID
+					transactionData["cost"] = cost
+				}
+				connectorData["transaction"] = transactionData
 			}
 		}
 
@@ -3077,6 +6764,25 @@ func (m *Manager) GetConnectors(ctx context.Context, stationID string) ([]map[st
 
 // StartCharging initiates a charging session on a connector
 func (m *Manager) StartCharging(ctx context.Context, stationID string, connectorID int, idTag string) error {
+	return m.StartChargingWithIdToken(ctx, stationID, connectorID, v201.IdToken{IdToken: idTag, Type: v201.IdTokenTypeISO14443})
+}
+
+// IsPlugAndCharge reports whether an ISO 15118 EV at the connector of an OCPP 2.0.1/2.1 station
+// authorizes with its contract certificate
+func (m *Manager) IsPlugAndCharge(stationID string, connectorID int) bool {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists || station.SessionManager == nil {
+		return false
+	}
+	return station.usesTransactionEvents() && station.SessionManager.PlugAndCharge().Enabled(connectorID)
+}
+
+// StartChargingWithIdToken initiates a charging session on a connector with a typed idToken,
+// OCPP 1.6 stations use its value as ID tag
+func (m *Manager) StartChargingWithIdToken(ctx context.Context, stationID string, connectorID int, idToken v201.IdToken) error {
 	m.mu.RLock()
 	station, exists := m.stations[stationID]
 	m.mu.RUnlock()
@@ -3100,11 +6806,12 @@ func (m *Manager) StartCharging(ctx context.Context, stationID string, connector
 	m.logger.Info("Starting charging session",
 		"stationId", stationID,
 		"connectorId", connectorID,
-		"idTag", idTag,
+		"idTag", idToken.IdToken,
+		"idTokenType", idToken.Type,
 	)
 
 	// Start charging via session manager
-	transactionID, err := station.SessionManager.StartCharging(connectorID, idTag)
+	transactionID, err := station.SessionManager.StartChargingWithIdToken(connectorID, idToken)
 	if err != nil {
 		m.logger.Error("Failed to start charging",
 			"stationId", stationID,
@@ -3117,7 +6824,7 @@ func (m *Manager) StartCharging(ctx context.Context, stationID string, connector
 	m.logger.Info("Charging session started successfully",
 		"stationId", stationID,
 		"connectorId", connectorID,
-		"idTag", idTag,
+		"idTag", idToken.IdToken,
 		"transactionId", transactionID,
 	)
 
@@ -3223,8 +6930,29 @@ func (m *Manager) SendCustomMessage(ctx context.Context, stationID string, messa
 		return fmt.Errorf("invalid message type: must be 2 (Call), 3 (CallResult), or 4 (CallError)")
 	}
 
-	// Send the raw message
-	if err := m.connManager.SendMessage(stationID, messageJSON); err != nil {
+	// Calls are tracked so their response is correlated, other messages are sent raw
+	if msgType == 2 && len(msgArray) >= 4 {
+		uniqueID, _ := msgArray[1].(string)
+		action, _ := msgArray[2].(string)
+		payload, _ := json.Marshal(msgArray[3])
+
+		call := &ocpp.Call{
+			MessageTypeID: ocpp.MessageTypeCall,
+			UniqueID:      uniqueID,
+			Action:        action,
+			Payload:       payload,
+		}
+		if _, err := m.sendCall(station, call, 0); err != nil {
+			m.logger.Error("Failed to send custom message",
+				"stationId", stationID,
+				"error", err,
+			)
+			return fmt.Errorf("failed to send custom message: %w", err)
+		}
+
+		// Store the message for logging
+		go m.storeMessage(stationID, "sent", call)
+	} else if err := m.connManager.SendMessage(stationID, messageJSON); err != nil {
 		m.logger.Error("Failed to send custom message",
 			"stationId", stationID,
 			"error", err,
@@ -3237,22 +6965,127 @@ func (m *Manager) SendCustomMessage(ctx context.Context, stationID string, messa
 		"messageType", int(msgType),
 	)
 
-	// Store the message for logging
-	var call *ocpp.Call
-	if msgType == 2 && len(msgArray) >= 4 {
-		// It's a Call message
-		uniqueID, _ := msgArray[1].(string)
-		action, _ := msgArray[2].(string)
-		payload, _ := json.Marshal(msgArray[3])
+	return nil
+}
 
-		call = &ocpp.Call{
-			MessageTypeID: ocpp.MessageTypeCall,
-			UniqueID:      uniqueID,
-			Action:        action,
-			Payload:       payload,
-		}
-		go m.storeMessage(stationID, "sent", call)
+// SendCall sends a Call to the CSMS and waits for its response. A CallError answer is returned
+// as *ocpp.CallError, an unanswered Call as ocpp.ErrCallTimeout.
+func (m *Manager) SendCall(ctx context.Context, stationID string, call *ocpp.Call) (*ocpp.CallResult, error) {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("station not found: %s", stationID)
+	}
+
+	pending, err := m.sendCall(station, call, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", call.Action, err)
+	}
+
+	m.logger.Info("Sent Call", "stationId", stationID, "action", call.Action, "uniqueId", call.UniqueID)
+
+	// Store sent message
+	go m.storeMessage(stationID, "sent", call)
+
+	return pending.Wait(ctx)
+}
+
+// ExportDeviceModelProfile returns the OCPP 2.0.1 device model of a station as a profile
+func (m *Manager) ExportDeviceModelProfile(stationID string) (*v201.DeviceModelProfile, error) {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("station not found: %s", stationID)
+	}
+	if station.DeviceModel == nil {
+		return nil, fmt.Errorf("device model not initialized for station: %s", stationID)
+	}
+
+	return station.DeviceModel.ExportProfile(), nil
+}
+
+// ImportDeviceModelProfile applies a device model profile to a station and stores it with the
+// station, so that it is applied again after a restart
+func (m *Manager) ImportDeviceModelProfile(ctx context.Context, stationID string, profile *v201.DeviceModelProfile) error {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("station not found: %s", stationID)
+	}
+	if station.DeviceModel == nil {
+		return fmt.Errorf("device model not initialized for station: %s", stationID)
+	}
+
+	if err := station.DeviceModel.ImportProfile(profile); err != nil {
+		return fmt.Errorf("invalid device model profile: %w", err)
+	}
+
+	if station.SessionManager != nil {
+		station.SessionManager.SetTxControl(TxControlFromDeviceModel(station.DeviceModel))
+		station.SessionManager.IdTokenAuthorization().SetConfig(IdTokenAuthConfigFromDeviceModel(station.DeviceModel))
+	}
+
+	station.mu.Lock()
+	station.Config.DeviceModelProfile = profile
+	station.Config.DeviceModelValues = station.DeviceModel.PersistentValues()
+	station.Config.UpdatedAt = time.Now()
+	station.mu.Unlock()
+
+	m.logger.Info("Imported device model profile", "stationId", stationID, "components", len(profile.Components))
+
+	if m.db == nil {
+		return nil
+	}
+	if err := m.saveStationToDB(ctx, station); err != nil {
+		return fmt.Errorf("failed to save device model profile: %w", err)
+	}
+	return nil
+}
+
+// SetExternalConstraints installs a ChargingStationExternalConstraints profile on an EVSE of a
+// station, simulating a limit of a local energy management system. EVSE 0 limits the whole station.
+func (m *Manager) SetExternalConstraints(stationID string, evseID int, profile v201.ChargingProfile) error {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("station not found: %s", stationID)
+	}
+	if station.SessionManager == nil {
+		return fmt.Errorf("session manager not initialized for station: %s", stationID)
+	}
+
+	profile.ChargingProfilePurpose = v201.ChargingProfilePurposeChargingStationExternalConstraints
+	if err := station.SessionManager.SetEVSEChargingProfile(evseID, profile); err != nil {
+		return fmt.Errorf("invalid charging profile: %w", err)
 	}
 
+	m.logger.Info("External constraints set", "stationId", stationID, "evseId", evseID, "profileId", profile.Id)
 	return nil
 }
+
+// ClearExternalConstraints removes the external constraints of an EVSE, or of all EVSEs when
+// evseID is nil, and returns the number of removed profiles
+func (m *Manager) ClearExternalConstraints(stationID string, evseID *int) (int, error) {
+	m.mu.RLock()
+	station, exists := m.stations[stationID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return 0, fmt.Errorf("station not found: %s", stationID)
+	}
+	if station.SessionManager == nil {
+		return 0, fmt.Errorf("session manager not initialized for station: %s", stationID)
+	}
+
+	removed := station.SessionManager.ChargingProfiles().ClearProfiles(nil, evseID, ChargingProfilePurposeExternalConstraints, nil)
+	m.logger.Info("External constraints cleared", "stationId", stationID, "evseId", evseID, "count", removed)
+	return removed, nil
+}
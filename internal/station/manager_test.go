@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/ruslanhut/ocpp-emu/internal/connection"
+	"github.com/ruslanhut/ocpp-emu/internal/logging"
+	"github.com/ruslanhut/ocpp-emu/internal/ocpp"
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
 )
 
 func TestNewManager(t *testing.T) {
@@ -167,9 +171,8 @@ func TestOnStationConnected(t *testing.T) {
 
 	manager.mu.Lock()
 	manager.stations[config.StationID] = &Station{
-		Config:          config,
-		StateMachine:    NewStateMachine(),
-		pendingRequests: make(map[string]string),
+		Config:       config,
+		StateMachine: NewStateMachine(),
 		RuntimeState: RuntimeState{
 			State:            StateDisconnected,
 			ConnectionStatus: "not_connected",
@@ -441,3 +444,577 @@ func TestSyncStateNoDeadlock(t *testing.T) {
 		}
 	}
 }
+
+func TestCallTimeoutLogged(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	messageLogger := logging.NewMessageLogger(nil, logger, logging.LoggerConfig{})
+	manager := NewManager(nil, nil, messageLogger, logger, ManagerConfig{})
+
+	station := &Station{Config: Config{StationID: "CP001", ProtocolVersion: "ocpp2.0.1"}}
+	manager.mu.Lock()
+	manager.stations["CP001"] = station
+	manager.mu.Unlock()
+
+	entries := make(chan logging.MessageEntry, 1)
+	messageLogger.AddListener(func(entry logging.MessageEntry) { entries <- entry })
+
+	call, err := ocpp.NewCall("Heartbeat", struct{}{})
+	if err != nil {
+		t.Fatalf("Failed to create Call: %v", err)
+	}
+	manager.requestTracker(station).OnTimeout(call)
+
+	select {
+	case entry := <-entries:
+		if entry.MessageType != "Timeout" || entry.MessageID != call.UniqueID || entry.Action != "Heartbeat" || entry.ProtocolVersion != "ocpp2.0.1" {
+			t.Errorf("Expected a Timeout entry for the Heartbeat, got %+v", entry)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the Timeout entry in the message log")
+	}
+}
+
+func TestResetStation(t *testing.T) {
+	tests := []struct {
+		name         string
+		hard         bool
+		reason       v16.Reason
+		expectStopTx bool
+	}{
+		{name: "soft reset stops transactions", hard: false, reason: v16.ReasonSoftReset, expectStopTx: true},
+		{name: "hard reset drops transactions", hard: true, reason: v16.ReasonHardReset, expectStopTx: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+			connMgr := connection.NewManager(nil, logger)
+			manager := NewManager(nil, connMgr, nil, logger, ManagerConfig{})
+
+			sm := NewSessionManager("TEST010", []ConnectorConfig{
+				{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+			}, logger)
+			allowOfflineTx(sm)
+			sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
+				return &v16.StartTransactionResponse{
+					IdTagInfo:     v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted},
+					TransactionId: 1,
+				}, nil
+			}
+			var stopReasons []v16.Reason
+			sm.SendStopTransaction = func(transactionID int, idTag string, meterStop int, timestamp time.Time, reason v16.Reason) (*v16.StopTransactionResponse, error) {
+				stopReasons = append(stopReasons, reason)
+				return &v16.StopTransactionResponse{}, nil
+			}
+
+			if _, err := sm.StartCharging(1, "TAG123"); err != nil {
+				t.Fatalf("StartCharging failed: %v", err)
+			}
+
+			// A MeterValues request is awaiting its response
+			requests := ocpp.NewRequestTracker("TEST010", func(data []byte) error { return nil }, time.Minute, logger)
+			call, _ := ocpp.NewCall(string(v16.ActionMeterValues), &v16.MeterValuesRequest{ConnectorId: 1})
+			requests.Send(call)
+
+			// Disabled, so the station does not reconnect after the reset
+			station := &Station{
+				Config:         Config{StationID: "TEST010", Enabled: false},
+				StateMachine:   NewStateMachine(),
+				SessionManager: sm,
+				Security:       newTestSecurityManager(),
+				RuntimeState:   RuntimeState{State: StateConnected, ConnectionStatus: "connected"},
+				requests:       requests,
+			}
+			station.StateMachine.SetState(StateConnecting, "test")
+			station.StateMachine.SetState(StateConnected, "test")
+
+			manager.mu.Lock()
+			manager.stations["TEST010"] = station
+			manager.mu.Unlock()
+
+			manager.resetStation("TEST010", tt.hard, tt.reason)
+
+			if tt.expectStopTx {
+				if len(stopReasons) != 1 || stopReasons[0] != tt.reason {
+					t.Errorf("Expected StopTransaction with reason %s, got %v", tt.reason, stopReasons)
+				}
+			} else if len(stopReasons) != 0 {
+				t.Errorf("Expected no StopTransaction on hard reset, got %v", stopReasons)
+			}
+
+			connector, _ := sm.GetConnector(1)
+			if connector.HasActiveTransaction() {
+				t.Error("Expected no active transaction after reset")
+			}
+
+			if tt.hard && requests.Pending() != 0 {
+				t.Errorf("Expected pending requests to be dropped, got %d", requests.Pending())
+			}
+			if station.bootReason != v201.BootReasonRemoteReset {
+				t.Errorf("Expected boot reason RemoteReset, got %s", station.bootReason)
+			}
+
+			resetting := false
+			for _, transition := range station.StateMachine.GetHistory() {
+				if transition.To == StateResetting {
+					resetting = true
+				}
+			}
+			if !resetting {
+				t.Error("Expected reset in the state history")
+			}
+			if station.StateMachine.GetState() != StateDisconnected {
+				t.Errorf("Expected state Disconnected, got %s", station.StateMachine.GetState())
+			}
+		})
+	}
+}
+
+func TestHandleV201Reset(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm := NewSessionManager("TEST011", []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}, logger)
+
+	manager.mu.Lock()
+	manager.stations["TEST011"] = &Station{
+		Config:         Config{StationID: "TEST011"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: sm,
+	}
+	manager.mu.Unlock()
+
+	evseID := 1
+	resp, _ := manager.handleV201Reset("TEST011", &v201.ResetRequest{Type: v201.ResetImmediate, EvseId: &evseID})
+	if resp.Status != v201.ResetStatusRejected {
+		t.Errorf("Expected EVSE reset to be rejected, got %s", resp.Status)
+	}
+
+	resp, _ = manager.handleV201Reset("TEST011", &v201.ResetRequest{Type: v201.ResetOnIdle})
+	if resp.Status != v201.ResetStatusAccepted {
+		t.Errorf("Expected OnIdle reset without transactions to be accepted, got %s", resp.Status)
+	}
+
+	resp, _ = manager.handleV201Reset("UNKNOWN", &v201.ResetRequest{Type: v201.ResetImmediate})
+	if resp.Status != v201.ResetStatusRejected {
+		t.Errorf("Expected reset of unknown station to be rejected, got %s", resp.Status)
+	}
+}
+
+func TestHandleV201GetTransactionStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	queue := NewMessageQueue("TEST012", logger)
+	manager.mu.Lock()
+	manager.stations["TEST012"] = &Station{
+		Config:         Config{StationID: "TEST012"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: NewSessionManager("TEST012", nil, logger),
+		MessageQueue:   queue,
+	}
+	manager.mu.Unlock()
+
+	resp, _ := manager.handleV201GetTransactionStatus("TEST012", &v201.GetTransactionStatusRequest{})
+	if resp.MessagesInQueue || resp.OngoingIndicator != nil {
+		t.Errorf("Expected empty queue without ongoing indicator, got %+v", resp)
+	}
+
+	// Messages queued while offline
+	queue.Enqueue(string(v16.ActionStopTransaction), &v16.StopTransactionRequest{TransactionId: 5}, "5")
+
+	resp, _ = manager.handleV201GetTransactionStatus("TEST012", &v201.GetTransactionStatusRequest{})
+	if !resp.MessagesInQueue {
+		t.Error("Expected queued messages to be reported")
+	}
+
+	resp, _ = manager.handleV201GetTransactionStatus("TEST012", &v201.GetTransactionStatusRequest{TransactionId: "5"})
+	if !resp.MessagesInQueue || resp.OngoingIndicator == nil || *resp.OngoingIndicator {
+		t.Errorf("Expected queued messages of finished transaction 5, got %+v", resp)
+	}
+
+	resp, _ = manager.handleV201GetTransactionStatus("TEST012", &v201.GetTransactionStatusRequest{TransactionId: "6"})
+	if resp.MessagesInQueue {
+		t.Error("Expected no queued messages for transaction 6")
+	}
+}
+
+func TestHandleV201GetBaseReport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	manager.mu.Lock()
+	manager.stations["TEST013"] = &Station{
+		Config:       Config{StationID: "TEST013"},
+		StateMachine: NewStateMachine(),
+		DeviceModel:  v201.NewDeviceModel(),
+	}
+	manager.mu.Unlock()
+
+	resp, _ := manager.handleV201GetBaseReport("TEST013", &v201.GetBaseReportRequest{RequestId: 1, ReportBase: v201.ReportBaseFullInventory})
+	if resp.Status != v201.GenericDeviceModelStatusAccepted {
+		t.Errorf("Expected full inventory to be accepted, got %s", resp.Status)
+	}
+
+	resp, _ = manager.handleV201GetBaseReport("TEST013", &v201.GetBaseReportRequest{RequestId: 2, ReportBase: "Unknown"})
+	if resp.Status != v201.GenericDeviceModelStatusNotSupported {
+		t.Errorf("Expected unknown report base to be not supported, got %s", resp.Status)
+	}
+
+	reportResp, _ := manager.handleV201GetReport("TEST013", &v201.GetReportRequest{
+		RequestId:         3,
+		ComponentVariable: []v201.ComponentVariable{{Component: v201.Component{Name: "UnknownCtrlr"}}},
+	})
+	if reportResp.Status != v201.GenericDeviceModelStatusEmptyResultSet {
+		t.Errorf("Expected empty result set for unknown component, got %s", reportResp.Status)
+	}
+
+	reportResp, _ = manager.handleV201GetReport("TEST013", &v201.GetReportRequest{
+		RequestId:         4,
+		ComponentVariable: []v201.ComponentVariable{{Component: v201.Component{Name: "DeviceDataCtrlr"}}},
+	})
+	if reportResp.Status != v201.GenericDeviceModelStatusAccepted {
+		t.Errorf("Expected DeviceDataCtrlr report to be accepted, got %s", reportResp.Status)
+	}
+}
+
+func TestReportParts(t *testing.T) {
+	reportData := make([]v201.ReportData, 5)
+	for i := range reportData {
+		reportData[i] = v201.ReportData{Component: v201.Component{Name: "OCPPCommCtrlr"}}
+	}
+
+	parts := reportParts(7, reportData, 2, time.Now())
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 NotifyReport parts, got %d", len(parts))
+	}
+
+	for i, part := range parts {
+		if part.RequestId != 7 || part.SeqNo != i {
+			t.Errorf("Expected part %d of request 7, got seqNo %d of request %d", i, part.SeqNo, part.RequestId)
+		}
+		if part.Tbc != (i < len(parts)-1) {
+			t.Errorf("Unexpected tbc %v for part %d", part.Tbc, i)
+		}
+	}
+	if len(parts[2].ReportData) != 1 {
+		t.Errorf("Expected 1 item in the last part, got %d", len(parts[2].ReportData))
+	}
+
+	if parts := reportParts(8, reportData, 0, time.Now()); len(parts) != 1 || parts[0].Tbc {
+		t.Errorf("Expected a single part with the default page size, got %d", len(parts))
+	}
+}
+
+func TestHandleV201VariableMonitoring(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	deviceModel := v201.NewDeviceModel()
+	deviceModel.AddEVSEComponent(1)
+
+	manager.mu.Lock()
+	manager.stations["TEST014"] = &Station{
+		Config:       Config{StationID: "TEST014"},
+		StateMachine: NewStateMachine(),
+		DeviceModel:  deviceModel,
+	}
+	manager.mu.Unlock()
+
+	evse := v201.Component{Name: "EVSE", Instance: "1"}
+	power := v201.Variable{Name: "Power"}
+
+	resp, _ := manager.handleV201SetVariableMonitoring("TEST014", &v201.SetVariableMonitoringRequest{
+		SetMonitoringData: []v201.SetMonitoringData{
+			{Value: 10000, Type: v201.MonitorUpperThreshold, Severity: 4, Component: evse, Variable: power},
+			{Value: 20000, Type: v201.MonitorUpperThreshold, Severity: 4, Component: evse, Variable: power},
+			{Value: 1, Type: v201.MonitorLowerThreshold, Severity: 4, Component: evse, Variable: v201.Variable{Name: "AvailabilityState"}},
+			{Value: 10, Type: v201.MonitorPeriodic, Severity: 8, Component: evse, Variable: power},
+			{Value: 1, Type: v201.MonitorDelta, Severity: 4, Component: v201.Component{Name: "Unknown"}, Variable: power},
+		},
+	})
+
+	expected := []v201.SetMonitoringStatusType{
+		v201.SetMonitoringStatusAccepted,
+		v201.SetMonitoringStatusDuplicate,
+		v201.SetMonitoringStatusUnsupportedMonitorType,
+		v201.SetMonitoringStatusAccepted,
+		v201.SetMonitoringStatusUnknownComponent,
+	}
+	for i, result := range resp.SetMonitoringResult {
+		if result.Status != expected[i] {
+			t.Errorf("Monitor %d: expected %s, got %s", i, expected[i], result.Status)
+		}
+	}
+	thresholdID := *resp.SetMonitoringResult[0].Id
+
+	// The threshold alerts once when exceeded and once when cleared
+	events := deviceModel.UpdateActualValue("EVSE", "1", "Power", "12000", "")
+	if len(events) != 1 || events[0].Trigger != v201.EventTriggerAlerting || events[0].Cleared != nil {
+		t.Fatalf("Expected an Alerting event, got %+v", events)
+	}
+	if *events[0].VariableMonitoringId != thresholdID || events[0].EventNotificationType != v201.EventNotificationCustomMonitor {
+		t.Errorf("Expected event of custom monitor %d, got %+v", thresholdID, events[0])
+	}
+	if events := deviceModel.UpdateActualValue("EVSE", "1", "Power", "13000", ""); len(events) != 0 {
+		t.Errorf("Expected no event while the threshold stays exceeded, got %+v", events)
+	}
+	events = deviceModel.UpdateActualValue("EVSE", "1", "Power", "5000", "")
+	if len(events) != 1 || events[0].Cleared == nil || !*events[0].Cleared {
+		t.Errorf("Expected a cleared event, got %+v", events)
+	}
+
+	if events := deviceModel.PeriodicEvents(time.Now().Add(11 * time.Second)); len(events) != 1 || events[0].Trigger != v201.EventTriggerPeriodic {
+		t.Errorf("Expected a Periodic event, got %+v", events)
+	}
+
+	// Events above the monitoring level are not reported
+	levelResp, _ := manager.handleV201SetMonitoringLevel("TEST014", &v201.SetMonitoringLevelRequest{Severity: 3})
+	if levelResp.Status != v201.GenericStatusAccepted {
+		t.Fatalf("Expected monitoring level to be accepted, got %s", levelResp.Status)
+	}
+	if events := deviceModel.UpdateActualValue("EVSE", "1", "Power", "12000", ""); len(events) != 0 {
+		t.Errorf("Expected severity 4 event to be filtered, got %+v", events)
+	}
+	if levelResp, _ := manager.handleV201SetMonitoringLevel("TEST014", &v201.SetMonitoringLevelRequest{Severity: 10}); levelResp.Status != v201.GenericStatusRejected {
+		t.Errorf("Expected severity 10 to be rejected, got %s", levelResp.Status)
+	}
+
+	reportResp, _ := manager.handleV201GetMonitoringReport("TEST014", &v201.GetMonitoringReportRequest{
+		RequestId:          1,
+		MonitoringCriteria: []v201.MonitoringCriterionType{v201.MonitoringCriterionThreshold},
+	})
+	if reportResp.Status != v201.GenericDeviceModelStatusAccepted {
+		t.Errorf("Expected threshold monitoring report to be accepted, got %s", reportResp.Status)
+	}
+
+	// The hard wired temperature monitor cannot be cleared
+	hardWiredID := 0
+	for _, data := range deviceModel.MonitoringReport(nil, nil) {
+		if data.Variable.Name == "Temperature" {
+			hardWiredID = data.VariableMonitoring[0].Id
+		}
+	}
+
+	clearResp, _ := manager.handleV201ClearVariableMonitoring("TEST014", &v201.ClearVariableMonitoringRequest{Id: []int{thresholdID, hardWiredID, 999}})
+	expectedClear := []v201.ClearMonitoringStatusType{
+		v201.ClearMonitoringStatusAccepted,
+		v201.ClearMonitoringStatusRejected,
+		v201.ClearMonitoringStatusNotFound,
+	}
+	for i, result := range clearResp.ClearMonitoringResult {
+		if result.Status != expectedClear[i] {
+			t.Errorf("Clear %d: expected %s, got %s", result.Id, expectedClear[i], result.Status)
+		}
+	}
+
+	// HardWiredOnly removes the custom monitors and disables the preconfigured ones
+	baseResp, _ := manager.handleV201SetMonitoringBase("TEST014", &v201.SetMonitoringBaseRequest{MonitoringBase: v201.MonitoringBaseHardWiredOnly})
+	if baseResp.Status != v201.GenericDeviceModelStatusAccepted {
+		t.Fatalf("Expected monitoring base to be accepted, got %s", baseResp.Status)
+	}
+	manager.handleV201SetMonitoringLevel("TEST014", &v201.SetMonitoringLevelRequest{Severity: v201.MaxMonitoringSeverity})
+	if events := deviceModel.UpdateActualValue("EVSE", "1", "AvailabilityState", "Occupied", ""); len(events) != 0 {
+		t.Errorf("Expected preconfigured monitor to be disabled, got %+v", events)
+	}
+	if monitors := deviceModel.MonitoringReport(nil, nil); len(monitors) != 1 || monitors[0].Variable.Name != "Temperature" {
+		t.Errorf("Expected only the hard wired monitor, got %+v", monitors)
+	}
+}
+
+func TestVariableCharacteristicsValidate(t *testing.T) {
+	minLimit, maxLimit := 0.0, 9.0
+
+	tests := []struct {
+		name            string
+		characteristics v201.VariableCharacteristics
+		value           string
+		valid           bool
+	}{
+		{"integer", v201.VariableCharacteristics{DataType: v201.DataTypeInteger}, "60", true},
+		{"integer fraction", v201.VariableCharacteristics{DataType: v201.DataTypeInteger}, "1.5", false},
+		{"integer text", v201.VariableCharacteristics{DataType: v201.DataTypeInteger}, "abc", false},
+		{"integer below minimum", v201.VariableCharacteristics{DataType: v201.DataTypeInteger, MinLimit: &minLimit, MaxLimit: &maxLimit}, "-1", false},
+		{"integer above maximum", v201.VariableCharacteristics{DataType: v201.DataTypeInteger, MinLimit: &minLimit, MaxLimit: &maxLimit}, "10", false},
+		{"decimal", v201.VariableCharacteristics{DataType: v201.DataTypeDecimal}, "1.5", true},
+		{"boolean", v201.VariableCharacteristics{DataType: v201.DataTypeBoolean}, "true", true},
+		{"boolean number", v201.VariableCharacteristics{DataType: v201.DataTypeBoolean}, "1", false},
+		{"dateTime", v201.VariableCharacteristics{DataType: v201.DataTypeDateTime}, "2024-01-01T00:00:00Z", true},
+		{"string too long", v201.VariableCharacteristics{DataType: v201.DataTypeString, MaxLimit: &maxLimit}, "0123456789", false},
+		{"option", v201.VariableCharacteristics{DataType: v201.DataTypeOptionList, ValuesList: "A,B"}, "B", true},
+		{"unknown option", v201.VariableCharacteristics{DataType: v201.DataTypeOptionList, ValuesList: "A,B"}, "C", false},
+		{"members", v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "A,B,C"}, "C, A", true},
+		{"empty members", v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "A,B,C"}, "", true},
+		{"unknown member", v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "A,B,C"}, "A,D", false},
+		{"duplicate member", v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "A,B,C"}, "A,A", false},
+		{"sequence", v201.VariableCharacteristics{DataType: v201.DataTypeSequenceList, ValuesList: "A,B,C"}, "B,A", true},
+		{"duplicate sequence item", v201.VariableCharacteristics{DataType: v201.DataTypeSequenceList, ValuesList: "A,B,C"}, "B,A,B", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.characteristics.Validate(tt.value)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be valid, got %v", tt.value, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected %q to be rejected", tt.value)
+			}
+		})
+	}
+}
+
+func TestHandleV201SetVariablesValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	deviceModel := v201.NewDeviceModel()
+	station := &Station{
+		Config:       Config{StationID: "TEST015"},
+		StateMachine: NewStateMachine(),
+		DeviceModel:  deviceModel,
+	}
+	manager.mu.Lock()
+	manager.stations["TEST015"] = station
+	manager.mu.Unlock()
+
+	// The default values of the device model match their characteristics
+	profile := deviceModel.ExportProfile()
+	if err := profile.Validate(); err != nil {
+		t.Fatalf("Expected the default device model to be valid, got %v", err)
+	}
+
+	set := func(component, variable, value string) v201.SetVariableData {
+		return v201.SetVariableData{
+			AttributeValue: value,
+			Component:      v201.Component{Name: component},
+			Variable:       v201.Variable{Name: variable},
+		}
+	}
+
+	resp, _ := manager.v201Handler.OnSetVariables("TEST015", &v201.SetVariablesRequest{
+		SetVariableData: []v201.SetVariableData{
+			set("OCPPCommCtrlr", "HeartbeatInterval", "120"),
+			set("OCPPCommCtrlr", "HeartbeatInterval", "two minutes"),
+			set("MonitoringCtrlr", "ActiveMonitoringLevel", "10"),
+			set("TxCtrlr", "TxStartPoint", "EVConnected,Authorized"),
+			set("TxCtrlr", "TxStopPoint", "EVConnected,Unplugged"),
+			set("ClockCtrlr", "TimeSource", "Heartbeat,Heartbeat"),
+		},
+	})
+
+	expected := []v201.SetVariableStatusType{
+		v201.SetVariableStatusAccepted,
+		v201.SetVariableStatusRejected,
+		v201.SetVariableStatusRejected,
+		v201.SetVariableStatusAccepted,
+		v201.SetVariableStatusRejected,
+		v201.SetVariableStatusRejected,
+	}
+	for i, result := range resp.SetVariableResult {
+		if result.AttributeStatus != expected[i] {
+			t.Errorf("%s: expected %s, got %s", result.Variable.Name, expected[i], result.AttributeStatus)
+		}
+	}
+
+	// Accepted persistent values are recorded in the station configuration
+	values := map[string]string{}
+	for _, value := range station.Config.DeviceModelValues {
+		values[value.Variable] = value.Value
+	}
+	if values["HeartbeatInterval"] != "120" || values["TxStartPoint"] != "EVConnected,Authorized" {
+		t.Errorf("Expected persisted values, got %v", station.Config.DeviceModelValues)
+	}
+
+	// The persisted values are restored on a new device model
+	restored := v201.NewDeviceModel()
+	if errs := restored.RestoreValues(station.Config.DeviceModelValues); len(errs) != 0 {
+		t.Fatalf("Expected values to be restored, got %v", errs)
+	}
+	if value, _ := restored.GetVariable("OCPPCommCtrlr", "", "HeartbeatInterval", "", v201.AttributeActual); value != "120" {
+		t.Errorf("Expected restored HeartbeatInterval 120, got %s", value)
+	}
+}
+
+func TestDeviceModelProfileImport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	station := &Station{
+		Config:         Config{StationID: "TEST016"},
+		StateMachine:   NewStateMachine(),
+		DeviceModel:    v201.NewDeviceModel(),
+		SessionManager: NewSessionManager("TEST016", []ConnectorConfig{{ID: 1, Type: "Type2", MaxPower: 22000}}, logger),
+	}
+	manager.mu.Lock()
+	manager.stations["TEST016"] = station
+	manager.mu.Unlock()
+
+	maxCurrent := 32.0
+	profile := &v201.DeviceModelProfile{
+		Components: []v201.ComponentProfile{
+			{
+				Name: "TxCtrlr",
+				Variables: []v201.VariableProfile{{
+					Name:            "TxStartPoint",
+					Characteristics: v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "EVConnected,Authorized,PowerPathClosed"},
+					Attributes: []v201.VariableAttribute{
+						{Type: v201.AttributeActual, Value: "PowerPathClosed", Mutability: v201.MutabilityReadWrite, Persistent: true},
+					},
+				}},
+			},
+			{
+				Name: "ChargingStation",
+				Variables: []v201.VariableProfile{{
+					Name:            "MaxCurrent",
+					Characteristics: v201.VariableCharacteristics{DataType: v201.DataTypeDecimal, Unit: "A", MaxLimit: &maxCurrent},
+					Attributes: []v201.VariableAttribute{
+						{Type: v201.AttributeActual, Value: "16", Mutability: v201.MutabilityReadWrite, Persistent: true},
+					},
+				}},
+			},
+		},
+	}
+
+	if err := manager.ImportDeviceModelProfile(context.Background(), "TEST016", profile); err != nil {
+		t.Fatalf("ImportDeviceModelProfile failed: %v", err)
+	}
+	if station.Config.DeviceModelProfile != profile {
+		t.Error("Expected the profile to be stored with the station")
+	}
+	if control := station.SessionManager.TxControl(); len(control.StartPoints) != 1 || control.StartPoints[0] != TxPointPowerPathClosed {
+		t.Errorf("Expected the imported TxStartPoint to be applied, got %+v", control.StartPoints)
+	}
+
+	// The imported characteristics are enforced
+	if status := station.DeviceModel.SetVariable("ChargingStation", "", "MaxCurrent", "", v201.AttributeActual, "40"); status != v201.SetVariableStatusRejected {
+		t.Errorf("Expected MaxCurrent above the limit to be rejected, got %s", status)
+	}
+
+	// An exported profile can be imported into a new device model
+	exported, err := manager.ExportDeviceModelProfile("TEST016")
+	if err != nil {
+		t.Fatalf("ExportDeviceModelProfile failed: %v", err)
+	}
+	copied := v201.NewDeviceModel()
+	if err := copied.ImportProfile(exported); err != nil {
+		t.Fatalf("Expected exported profile to be imported, got %v", err)
+	}
+	if value, _ := copied.GetVariable("ChargingStation", "", "MaxCurrent", "", v201.AttributeActual); value != "16" {
+		t.Errorf("Expected MaxCurrent 16, got %s", value)
+	}
+
+	invalid := &v201.DeviceModelProfile{Components: []v201.ComponentProfile{{
+		Name: "TxCtrlr",
+		Variables: []v201.VariableProfile{{
+			Name:            "TxStartPoint",
+			Characteristics: v201.VariableCharacteristics{DataType: v201.DataTypeMemberList, ValuesList: "EVConnected"},
+			Attributes:      []v201.VariableAttribute{{Type: v201.AttributeActual, Value: "Authorized", Mutability: v201.MutabilityReadWrite}},
+		}},
+	}}}
+	if err := manager.ImportDeviceModelProfile(context.Background(), "TEST016", invalid); err == nil {
+		t.Error("Expected an invalid profile to be rejected")
+	}
+}
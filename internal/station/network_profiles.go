@@ -0,0 +1,169 @@
+package station
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// defaultNetworkSlot is the configuration slot of the CSMS connection of the station configuration
+const defaultNetworkSlot = 1
+
+// networkProfile returns the network connection profile of a configuration slot. Unless the CSMS
+// has set it, the default slot holds the CSMS connection of the station configuration with the
+// active security profile.
+func networkProfile(config Config, securityProfile, slot int) (v201.NetworkConnectionProfile, bool) {
+	for _, profile := range config.NetworkProfiles {
+		if profile.Slot == slot {
+			return profile.Profile, true
+		}
+	}
+
+	if slot != defaultNetworkSlot {
+		return v201.NetworkConnectionProfile{}, false
+	}
+	return v201.NetworkConnectionProfile{
+		OcppVersion:     ocppVersion(config.ProtocolVersion),
+		OcppTransport:   v201.OCPPTransportJSON,
+		OcppCsmsUrl:     config.CSMSURL,
+		SecurityProfile: securityProfile,
+		OcppInterface:   v201.OCPPInterfaceWired0,
+	}, true
+}
+
+// setNetworkProfile stores the profile of a configuration slot, replacing the previous one
+func setNetworkProfile(profiles []NetworkProfile, slot int, profile v201.NetworkConnectionProfile) []NetworkProfile {
+	updated := make([]NetworkProfile, 0, len(profiles)+1)
+	for _, existing := range profiles {
+		if existing.Slot != slot {
+			updated = append(updated, existing)
+		}
+	}
+	updated = append(updated, NetworkProfile{Slot: slot, Profile: profile})
+
+	sort.Slice(updated, func(i, j int) bool { return updated[i].Slot < updated[j].Slot })
+	return updated
+}
+
+// ocppVersion returns the OCPP version of a network connection profile for a protocol version,
+// OCPP 1.6 for unknown protocol versions
+func ocppVersion(protocolVersion string) v201.OCPPVersionType {
+	version, _ := parseProtocolVersion(protocolVersion)
+	return version
+}
+
+// parseProtocolVersion returns the OCPP version of a protocol version, false if it is unknown
+func parseProtocolVersion(protocolVersion string) (v201.OCPPVersionType, bool) {
+	switch protocolVersion {
+	case "ocpp1.6", "1.6":
+		return v201.OCPPVersion16, true
+	case "ocpp2.0.1", "2.0.1", "ocpp201":
+		return v201.OCPPVersion20, true
+	case "ocpp2.1", "2.1", "ocpp21":
+		return v201.OCPPVersion21, true
+	default:
+		return v201.OCPPVersion16, false
+	}
+}
+
+// usesTransactionEvents reports whether stations of a protocol version report transactions with
+// TransactionEvent (OCPP 2.0.1 and 2.1)
+func usesTransactionEvents(protocolVersion string) bool {
+	return ocppVersion(protocolVersion) != v201.OCPPVersion16
+}
+
+// validateNetworkProfile checks that the station can connect with a network connection profile
+func validateNetworkProfile(profile v201.NetworkConnectionProfile, protocolVersion string) error {
+	if profile.OcppVersion != ocppVersion(protocolVersion) {
+		return fmt.Errorf("OCPP version %s is not supported", profile.OcppVersion)
+	}
+	if profile.OcppTransport != v201.OCPPTransportJSON {
+		return fmt.Errorf("OCPP transport %s is not supported", profile.OcppTransport)
+	}
+	if profile.SecurityProfile < SecurityProfileNone || profile.SecurityProfile > SecurityProfileTLSClientCert {
+		return fmt.Errorf("unknown security profile %d", profile.SecurityProfile)
+	}
+	return nil
+}
+
+// validateCSMSURL checks that a CSMS URL is a WebSocket URL
+func validateCSMSURL(csmsURL string) error {
+	parsed, err := url.Parse(csmsURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "ws" && parsed.Scheme != "wss" {
+		return fmt.Errorf("ws:// or wss:// URL required")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// parseNetworkPriority parses the configuration slots of NetworkConfigurationPriority
+func parseNetworkPriority(value string) ([]int, error) {
+	var slots []int
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		slot, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration slot %q", item)
+		}
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return nil, fmt.Errorf("no configuration slot")
+	}
+	return slots, nil
+}
+
+// networkProfileFromV21 converts an OCPP 2.1 network connection profile to its OCPP 2.0.1 equivalent
+func networkProfileFromV21(p v21.NetworkConnectionProfileType) v201.NetworkConnectionProfile {
+	profile := v201.NetworkConnectionProfile{
+		OcppVersion:     v201.OCPPVersionType(p.OcppVersion),
+		OcppTransport:   v201.OCPPTransportType(p.OcppTransport),
+		OcppCsmsUrl:     p.OcppCsmsUrl,
+		MessageTimeout:  p.MessageTimeout,
+		SecurityProfile: p.SecurityProfile,
+		OcppInterface:   v201.OCPPInterfaceType(p.OcppInterface),
+	}
+	if p.VPN != nil {
+		profile.VPN = &v201.VPN{
+			Server:   p.VPN.Server,
+			User:     derefString(p.VPN.User),
+			Group:    derefString(p.VPN.Group),
+			Password: derefString(p.VPN.Password),
+			Key:      derefString(p.VPN.Key),
+			Type:     p.VPN.Type,
+		}
+	}
+	if p.APN != nil {
+		profile.APN = &v201.APN{
+			APN:                     p.APN.APN,
+			APNUserName:             derefString(p.APN.APNUserName),
+			APNPassword:             derefString(p.APN.APNPassword),
+			SimPin:                  p.APN.SimPin,
+			PreferredNetwork:        derefString(p.APN.PreferredNetwork),
+			UseOnlyPreferredNetwork: p.APN.UseOnlyPreferredNetwork,
+			APNAuthentication:       p.APN.APNAuthentication,
+		}
+	}
+	return profile
+}
+
+// derefString returns the value of an optional string, empty if not set
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
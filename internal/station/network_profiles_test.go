@@ -0,0 +1,159 @@
+package station
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func newNetworkProfile(csmsURL string, securityProfile int) v201.NetworkConnectionProfile {
+	return v201.NetworkConnectionProfile{
+		OcppVersion:     v201.OCPPVersion20,
+		OcppTransport:   v201.OCPPTransportJSON,
+		OcppCsmsUrl:     csmsURL,
+		MessageTimeout:  30,
+		SecurityProfile: securityProfile,
+		OcppInterface:   v201.OCPPInterfaceWired0,
+	}
+}
+
+func TestNetworkProfile_DefaultSlot(t *testing.T) {
+	config := Config{ProtocolVersion: "ocpp2.0.1", CSMSURL: "ws://csms.example.com/ocpp"}
+
+	profile, ok := networkProfile(config, SecurityProfileBasicAuth, defaultNetworkSlot)
+	if !ok || profile.OcppCsmsUrl != config.CSMSURL || profile.SecurityProfile != SecurityProfileBasicAuth || profile.OcppVersion != v201.OCPPVersion20 {
+		t.Errorf("Expected the configured CSMS connection in slot 1, got %+v", profile)
+	}
+	if _, ok := networkProfile(config, SecurityProfileBasicAuth, 2); ok {
+		t.Error("Expected slot 2 to be empty")
+	}
+
+	// A profile set by the CSMS replaces the configured connection
+	config.NetworkProfiles = setNetworkProfile(nil, 2, newNetworkProfile("ws://new.example.com/ocpp", 1))
+	config.NetworkProfiles = setNetworkProfile(config.NetworkProfiles, 1, newNetworkProfile("ws://other.example.com/ocpp", 1))
+	config.NetworkProfiles = setNetworkProfile(config.NetworkProfiles, 2, newNetworkProfile("ws://migrated.example.com/ocpp", 1))
+	if len(config.NetworkProfiles) != 2 || config.NetworkProfiles[0].Slot != 1 || config.NetworkProfiles[1].Slot != 2 {
+		t.Fatalf("Expected profiles of slots 1 and 2, got %+v", config.NetworkProfiles)
+	}
+	if profile, _ := networkProfile(config, SecurityProfileBasicAuth, 1); profile.OcppCsmsUrl != "ws://other.example.com/ocpp" {
+		t.Errorf("Expected the profile set for slot 1, got %s", profile.OcppCsmsUrl)
+	}
+	if profile, _ := networkProfile(config, SecurityProfileBasicAuth, 2); profile.OcppCsmsUrl != "ws://migrated.example.com/ocpp" {
+		t.Errorf("Expected the replaced profile of slot 2, got %s", profile.OcppCsmsUrl)
+	}
+}
+
+func TestHandleV201SetNetworkProfile(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	deviceModel := v201.NewDeviceModel()
+	station := &Station{
+		Config: Config{
+			StationID:       "TEST021",
+			ProtocolVersion: "ocpp2.0.1",
+			CSMSURL:         "ws://csms.example.com/ocpp",
+			CSMSAuth:        &CSMSAuthConfig{Type: "basic", Username: "TEST021", Password: "secret"},
+		},
+		StateMachine: NewStateMachine(),
+		DeviceModel:  deviceModel,
+		Security:     NewSecurityManager("TEST021", SecurityProfileBasicAuth, v201.NewCertificateStore("TEST021", "Test", "US"), nil),
+		RuntimeState: RuntimeState{NetworkSlot: 1},
+	}
+	manager.mu.Lock()
+	manager.stations["TEST021"] = station
+	manager.mu.Unlock()
+
+	rejections := []struct {
+		name       string
+		slot       int
+		profile    v201.NetworkConnectionProfile
+		reasonCode string
+	}{
+		{"unknown slot", 4, newNetworkProfile("ws://new.example.com/ocpp", 1), "InvalidConfSlot"},
+		{"active slot", 1, newNetworkProfile("ws://new.example.com/ocpp", 1), "InvalidConfSlot"},
+		{"http URL", 2, newNetworkProfile("http://new.example.com/ocpp", 1), "InvalidURL"},
+		{"OCPP 1.6", 2, v201.NetworkConnectionProfile{OcppVersion: v201.OCPPVersion16, OcppTransport: v201.OCPPTransportJSON, OcppCsmsUrl: "ws://new.example.com/ocpp", SecurityProfile: 1}, "InvalidProfile"},
+		{"security downgrade", 2, newNetworkProfile("ws://new.example.com/ocpp", 0), "NoSecurityDowngrade"},
+		{"TLS over ws", 2, newNetworkProfile("ws://new.example.com/ocpp", 2), "InvalidProfile"},
+	}
+	for _, tt := range rejections {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := manager.v201Handler.OnSetNetworkProfile("TEST021", &v201.SetNetworkProfileRequest{ConfigurationSlot: tt.slot, ConnectionData: tt.profile})
+			if resp.Status != v201.SetNetworkProfileStatusRejected || resp.StatusInfo == nil || resp.StatusInfo.ReasonCode != tt.reasonCode {
+				t.Errorf("Expected Rejected with %s, got %s %+v", tt.reasonCode, resp.Status, resp.StatusInfo)
+			}
+		})
+	}
+	if len(station.Config.NetworkProfiles) != 0 {
+		t.Fatalf("Expected no stored profile, got %+v", station.Config.NetworkProfiles)
+	}
+
+	// Slots without a profile cannot be prioritized
+	setPriority := func(value string) v201.SetVariableStatusType {
+		resp, _ := manager.v201Handler.OnSetVariables("TEST021", &v201.SetVariablesRequest{SetVariableData: []v201.SetVariableData{{
+			Component:      v201.Component{Name: "OCPPCommCtrlr"},
+			Variable:       v201.Variable{Name: "NetworkConfigurationPriority"},
+			AttributeValue: value,
+		}}})
+		return resp.SetVariableResult[0].AttributeStatus
+	}
+	if status := setPriority("2,1"); status != v201.SetVariableStatusRejected {
+		t.Errorf("Expected Rejected for an empty slot, got %s", status)
+	}
+
+	resp, _ := manager.v201Handler.OnSetNetworkProfile("TEST021", &v201.SetNetworkProfileRequest{
+		ConfigurationSlot: 2,
+		ConnectionData:    newNetworkProfile("ws://new.example.com/ocpp", 1),
+	})
+	if resp.Status != v201.SetNetworkProfileStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s %+v", resp.Status, resp.StatusInfo)
+	}
+	if len(station.Config.NetworkProfiles) != 1 || station.Config.NetworkProfiles[0].Slot != 2 {
+		t.Fatalf("Expected the profile of slot 2 to be stored, got %+v", station.Config.NetworkProfiles)
+	}
+
+	// OCPP 2.1 requests use the same slots
+	v21Resp, _ := manager.v21Handler.OnSetNetworkProfile("TEST021", &v21.SetNetworkProfileRequest{
+		ConfigurationSlot: 3,
+		ConnectionData: v21.NetworkConnectionProfileType{
+			OcppVersion:     "OCPP20",
+			OcppTransport:   "JSON",
+			OcppCsmsUrl:     "ws://backup.example.com/ocpp",
+			SecurityProfile: 1,
+			OcppInterface:   "Wireless0",
+		},
+	})
+	if v21Resp.Status != "Accepted" {
+		t.Fatalf("Expected Accepted for OCPP 2.1 request, got %s %+v", v21Resp.Status, v21Resp.StatusInfo)
+	}
+
+	if status := setPriority("2,1"); status != v201.SetVariableStatusRebootRequired {
+		t.Errorf("Expected RebootRequired, got %s", status)
+	}
+	if status := setPriority("4"); status != v201.SetVariableStatusRejected {
+		t.Errorf("Expected Rejected for an unknown slot, got %s", status)
+	}
+
+	// The station connects through the prioritized slots in order
+	station.mu.RLock()
+	endpoints, err := manager.networkEndpoints(station, station.Security.Profile())
+	station.mu.RUnlock()
+	if err != nil {
+		t.Fatalf("Expected endpoints, got %v", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].Slot != 2 || endpoints[0].URL != "ws://new.example.com/ocpp" || endpoints[1].Slot != 1 || endpoints[1].URL != "ws://csms.example.com/ocpp" {
+		t.Errorf("Expected endpoints of slots 2 and 1, got %+v", endpoints)
+	}
+	if endpoints[0].Auth == nil || endpoints[0].Auth.Username != "TEST021" || endpoints[0].Auth.Password != "secret" {
+		t.Errorf("Expected basic auth for slot 2, got %+v", endpoints[0].Auth)
+	}
+
+	if threshold := manager.offlineThreshold(station); threshold != 60*time.Second {
+		t.Errorf("Expected offline threshold of 60s, got %v", threshold)
+	}
+}
@@ -0,0 +1,473 @@
+package station
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+const (
+	// iso15118SchemaVersion is the ISO 15118 schema of the simulated Plug & Charge sessions
+	iso15118SchemaVersion = "urn:iso:15118:2:2013:MsgDef"
+
+	// contractValidity is the validity of the contract certificates issued by the test PKI
+	contractValidity = 365 * 24 * time.Hour
+
+	// contractRenewal is the remaining validity below which an EV updates its contract certificate
+	contractRenewal = 30 * 24 * time.Hour
+
+	// contractResponderURL is the OCSP responder of the certificates of the test PKI
+	contractResponderURL = "http://ocsp.example.com/mo"
+)
+
+// Get15118EVCertificate actions
+const (
+	certificateActionInstall = "Install"
+	certificateActionUpdate  = "Update"
+)
+
+// PlugAndCharge simulates the ISO 15118 Plug & Charge EVs at the connectors of an OCPP 2.0.1/2.1
+// station. An EV authorizes with the eMAID of its contract certificate, which it installs and
+// updates through the CSMS. Certificates are issued by a locally generated test PKI of a
+// mobility operator (MO) since the EXI messages of ISO 15118 are not simulated.
+type PlugAndCharge struct {
+	stationID string
+	emaids    map[int]string // Contract IDs of the Plug & Charge connectors
+
+	// validity of the contract certificates issued by the test PKI
+	validity time.Duration
+
+	mu        sync.Mutex
+	pki       *contractPKI
+	contracts map[int]*contractCertificate // Installed contract certificates by connector
+}
+
+// contractCertificate is the contract certificate of a Plug & Charge EV
+type contractCertificate struct {
+	emaid string
+	cert  *x509.Certificate
+	chain []*x509.Certificate // Issuers up to the MO root CA
+	key   *ecdsa.PrivateKey
+}
+
+// contractPKI is the test PKI of a mobility operator: MO root CA → MO sub-CA → contract certificates
+type contractPKI struct {
+	root     *x509.Certificate
+	rootKey  *ecdsa.PrivateKey
+	subCA    *x509.Certificate
+	subCAKey *ecdsa.PrivateKey
+}
+
+// NewPlugAndCharge creates the Plug & Charge EVs of the connectors configured for it
+func NewPlugAndCharge(stationID string, connectors []ConnectorConfig) *PlugAndCharge {
+	pc := &PlugAndCharge{
+		stationID: stationID,
+		emaids:    make(map[int]string),
+		validity:  contractValidity,
+		contracts: make(map[int]*contractCertificate),
+	}
+
+	for _, connector := range connectors {
+		if !connector.PlugAndCharge {
+			continue
+		}
+		emaid := connector.EMAID
+		if emaid == "" {
+			emaid = defaultEMAID(stationID, connector.ID)
+		}
+		pc.emaids[connector.ID] = emaid
+	}
+
+	return pc
+}
+
+// Enabled reports whether the EV at a connector uses Plug & Charge
+func (pc *PlugAndCharge) Enabled(connectorID int) bool {
+	_, ok := pc.emaids[connectorID]
+	return ok
+}
+
+// EMAID returns the contract ID of the EV at a Plug & Charge connector
+func (pc *PlugAndCharge) EMAID(connectorID int) string {
+	return pc.emaids[connectorID]
+}
+
+// ContractExpiry returns when the installed contract certificate of a connector expires, nil
+// without one
+func (pc *PlugAndCharge) ContractExpiry(connectorID int) *time.Time {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	contract, ok := pc.contracts[connectorID]
+	if !ok {
+		return nil
+	}
+	expiry := contract.cert.NotAfter
+	return &expiry
+}
+
+// contract returns the installed contract certificate of a connector and the Get15118EVCertificate
+// action it needs: Install without a certificate, Update if it expires within contractRenewal
+func (pc *PlugAndCharge) contract(connectorID int, now time.Time) (*contractCertificate, string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	contract, ok := pc.contracts[connectorID]
+	switch {
+	case !ok:
+		return nil, certificateActionInstall
+	case now.Add(contractRenewal).After(contract.cert.NotAfter):
+		return contract, certificateActionUpdate
+	default:
+		return contract, ""
+	}
+}
+
+// certificateRequest creates the key of a new contract certificate and the simulated EXI request
+// of its installation, the Base64 PKCS #10 request for the eMAID of the connector
+func (pc *PlugAndCharge) certificateRequest(connectorID int) (*ecdsa.PrivateKey, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate contract key: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:            pkix.Name{CommonName: pc.EMAID(connectorID)},
+		SignatureAlgorithm: x509.ECDSAWithSHA256,
+	}, key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create contract certificate request: %w", err)
+	}
+
+	return key, base64.StdEncoding.EncodeToString(csr), nil
+}
+
+// install installs the contract certificate of a Get15118EVCertificate response. A response with
+// the PEM or DER certificate chain of the key is installed as is, for other (EXI encoded)
+// responses the certificate is issued by the test PKI.
+func (pc *PlugAndCharge) install(connectorID int, key *ecdsa.PrivateKey, exiResponse string) (*contractCertificate, error) {
+	contract := &contractCertificate{emaid: pc.EMAID(connectorID), key: key}
+
+	if data, err := base64.StdEncoding.DecodeString(exiResponse); err == nil {
+		if chain := parseCertificateChain(data); len(chain) > 1 && key.PublicKey.Equal(chain[0].PublicKey) && chain[0].CheckSignatureFrom(chain[1]) == nil {
+			contract.cert, contract.chain = chain[0], chain[1:]
+		}
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if contract.cert == nil {
+		if pc.pki == nil {
+			pki, err := newContractPKI()
+			if err != nil {
+				return nil, err
+			}
+			pc.pki = pki
+		}
+
+		cert, err := pc.pki.issue(contract.emaid, &key.PublicKey, pc.validity)
+		if err != nil {
+			return nil, err
+		}
+		contract.cert, contract.chain = cert, []*x509.Certificate{pc.pki.subCA, pc.pki.root}
+	}
+
+	pc.contracts[connectorID] = contract
+	return contract, nil
+}
+
+// idToken returns the eMAID idToken the contract is authorized with
+func (c *contractCertificate) idToken() v201.IdToken {
+	return v201.IdToken{IdToken: c.emaid, Type: v201.IdTokenTypeEMAID}
+}
+
+// certificates returns the contract certificate followed by its issuers
+func (c *contractCertificate) certificates() []*x509.Certificate {
+	return append([]*x509.Certificate{c.cert}, c.chain...)
+}
+
+// hashData returns the OCSP request data of the contract certificate and its sub-CAs. The MO
+// root CA is known to the CSMS and not included.
+func (c *contractCertificate) hashData() []v201.OCSPRequestData {
+	certs := c.certificates()
+	data := make([]v201.OCSPRequestData, 0, len(certs)-1)
+	for i := 0; i+1 < len(certs); i++ {
+		data = append(data, ocspRequestData(certs[i], certs[i+1]))
+	}
+	return data
+}
+
+// newContractPKI generates the MO root CA and sub-CA of the test PKI
+func newContractPKI() (*contractPKI, error) {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MO root key: %w", err)
+	}
+	root, err := createCertificate(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Emulator MO Root CA", Organization: []string{"OCPP Emulator"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            1,
+	}, nil, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+
+	subCAKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MO sub-CA key: %w", err)
+	}
+	subCA, err := createCertificate(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Emulator MO Sub-CA", Organization: []string{"OCPP Emulator"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(4, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		OCSPServer:            []string{contractResponderURL},
+	}, root, &subCAKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contractPKI{root: root, rootKey: rootKey, subCA: subCA, subCAKey: subCAKey}, nil
+}
+
+// issue issues a contract certificate for an eMAID
+func (p *contractPKI) issue(emaid string, key *ecdsa.PublicKey, validity time.Duration) (*x509.Certificate, error) {
+	return createCertificate(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: emaid, Organization: []string{"OCPP Emulator"}},
+		NotBefore:   time.Now().Add(-time.Minute),
+		NotAfter:    time.Now().Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement,
+		OCSPServer:  []string{contractResponderURL},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, p.subCA, key, p.subCAKey)
+}
+
+// createCertificate creates a certificate with a random serial number, self-signed without parent
+func createCertificate(template, parent *x509.Certificate, pub *ecdsa.PublicKey, signer *ecdsa.PrivateKey) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	template.SerialNumber = serial
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate %q: %w", template.Subject.CommonName, err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// parseCertificateChain parses PEM or DER encoded certificates
+func parseCertificateChain(data []byte) []*x509.Certificate {
+	var chain []*x509.Certificate
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			chain = append(chain, cert)
+		}
+	}
+	if len(chain) == 0 {
+		chain, _ = x509.ParseCertificates(data)
+	}
+	return chain
+}
+
+// ocspRequestData returns the OCSP request data of a certificate: the SHA-256 hashes of the
+// issuer name and the issuer public key and the serial number
+func ocspRequestData(cert, issuer *x509.Certificate) v201.OCSPRequestData {
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &publicKeyInfo)
+
+	nameHash := sha256.Sum256(cert.RawIssuer)
+	keyHash := sha256.Sum256(publicKeyInfo.PublicKey.RightAlign())
+
+	data := v201.OCSPRequestData{
+		HashAlgorithm:  string(v201.HashAlgorithmSHA256),
+		IssuerNameHash: hex.EncodeToString(nameHash[:]),
+		IssuerKeyHash:  hex.EncodeToString(keyHash[:]),
+		SerialNumber:   hex.EncodeToString(cert.SerialNumber.Bytes()),
+	}
+	if len(cert.OCSPServer) > 0 {
+		data.ResponderURL = cert.OCSPServer[0]
+	}
+	return data
+}
+
+// defaultEMAID derives the contract ID of a connector's EV from the station and connector,
+// e.g. "DEEMUC1A2B3C4D"
+func defaultEMAID(stationID string, connectorID int) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s/%d", stationID, connectorID)
+	return fmt.Sprintf("DEEMUC%08X", h.Sum32())
+}
+
+// authorizeContract authorizes the Plug & Charge EV at a connector. Its contract certificate is
+// installed or updated through the CSMS if needed, checked with OCSP and authorized with its
+// certificate hash data. There is no local authorization, the CSMS has to accept the contract.
+func (sm *SessionManager) authorizeContract(connectorID int) (*v16.IdTagInfo, error) {
+	contract, err := sm.contractCertificate(connectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sm.checkContractStatus(contract); err != nil {
+		return nil, err
+	}
+
+	if sm.SendAuthorizeContract == nil {
+		return nil, errors.New("CSMS unavailable for Plug & Charge")
+	}
+
+	resp, err := sm.SendAuthorizeContract(contract.idToken(), contract.hashData())
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.CertificateStatus != nil && *resp.CertificateStatus != "Accepted" {
+		return nil, fmt.Errorf("contract certificate rejected: %s", *resp.CertificateStatus)
+	}
+
+	sm.logger.Info("Contract authorized",
+		"stationId", sm.stationID,
+		"connectorId", connectorID,
+		"emaid", contract.emaid,
+		"status", resp.IdTokenInfo.Status,
+	)
+
+	idTagInfo := IdTagInfoFromV201(&resp.IdTokenInfo)
+	return &idTagInfo, nil
+}
+
+// contractCertificate returns the contract certificate of the EV at a connector, installing or
+// updating it with Get15118EVCertificate. A failed update keeps the current certificate.
+func (sm *SessionManager) contractCertificate(connectorID int) (*contractCertificate, error) {
+	contract, action := sm.plugAndCharge.contract(connectorID, time.Now())
+	if action == "" {
+		return contract, nil
+	}
+
+	usable := func(err error) (*contractCertificate, error) {
+		if contract != nil && time.Now().Before(contract.cert.NotAfter) {
+			sm.logger.Warn("Contract certificate update failed, using the current certificate",
+				"stationId", sm.stationID,
+				"connectorId", connectorID,
+				"error", err,
+			)
+			return contract, nil
+		}
+		return nil, fmt.Errorf("contract certificate %s failed: %w", action, err)
+	}
+
+	if sm.SendGet15118EVCertificate == nil {
+		return usable(errors.New("CSMS unavailable"))
+	}
+
+	key, exiRequest, err := sm.plugAndCharge.certificateRequest(connectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.logger.Info("Requesting contract certificate",
+		"stationId", sm.stationID,
+		"connectorId", connectorID,
+		"action", action,
+	)
+
+	resp, err := sm.SendGet15118EVCertificate(&v201.Get15118EVCertificateRequest{
+		Iso15118SchemaVersion: iso15118SchemaVersion,
+		Action:                action,
+		ExiRequest:            exiRequest,
+	})
+	if err != nil {
+		return usable(err)
+	}
+	if resp.Status != "Accepted" {
+		return usable(fmt.Errorf("CSMS responded %s", resp.Status))
+	}
+
+	return sm.plugAndCharge.install(connectorID, key, resp.ExiResponse)
+}
+
+// checkContractStatus checks the certificates of a contract with OCSP through GetCertificateStatus.
+// A revoked certificate fails the authorization, a status the station cannot obtain is left to
+// the CSMS, which validates the hash data of the Authorize request.
+func (sm *SessionManager) checkContractStatus(contract *contractCertificate) error {
+	if sm.SendGetCertificateStatus == nil {
+		return nil
+	}
+
+	certs := contract.certificates()
+	for i, data := range contract.hashData() {
+		resp, err := sm.SendGetCertificateStatus(&v201.GetCertificateStatusRequest{OcspRequestData: data})
+		if err == nil && resp.Status != "Accepted" {
+			err = fmt.Errorf("CSMS responded %s", resp.Status)
+		}
+
+		var status *ocsp.Response
+		if err == nil {
+			status, err = parseOCSPResult(resp.OcspResult, certs[i], certs[i+1])
+		}
+		if err != nil {
+			sm.logger.Warn("Certificate status unavailable",
+				"stationId", sm.stationID,
+				"serialNumber", data.SerialNumber,
+				"error", err,
+			)
+			continue
+		}
+
+		if status.Status == ocsp.Revoked {
+			return fmt.Errorf("certificate %s revoked", data.SerialNumber)
+		}
+	}
+
+	return nil
+}
+
+// parseOCSPResult parses the Base64 OCSP response of a GetCertificateStatus for a certificate
+func parseOCSPResult(ocspResult string, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	der, err := base64.StdEncoding.DecodeString(ocspResult)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Base64 OCSP response: %w", err)
+	}
+	return ocsp.ParseResponseForCert(der, cert, issuer)
+}
@@ -0,0 +1,286 @@
+package station
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+// contractCSMS simulates the Plug & Charge services of a CSMS: it issues contract certificates
+// from its own MO PKI, answers OCSP requests for them and authorizes contracts
+type contractCSMS struct {
+	t   *testing.T
+	pki *contractPKI
+
+	mu                sync.Mutex
+	validity          time.Duration
+	exiResponse       string // Returned instead of the certificate chain if set
+	failUpdates       bool
+	revoked           bool
+	certificateStatus *string
+	issued            map[string]*x509.Certificate // By serial number
+	actions           []string
+	statusChecks      int
+	authorized        []*v201.AuthorizeRequest
+}
+
+func newContractCSMS(t *testing.T) *contractCSMS {
+	t.Helper()
+
+	pki, err := newContractPKI()
+	if err != nil {
+		t.Fatalf("Failed to create MO PKI: %v", err)
+	}
+	return &contractCSMS{
+		t:        t,
+		pki:      pki,
+		validity: contractValidity,
+		issued:   map[string]*x509.Certificate{serialNumber(pki.subCA): pki.subCA},
+	}
+}
+
+// attach wires the Plug & Charge callbacks of a session manager to the CSMS
+func (c *contractCSMS) attach(sm *SessionManager) {
+	sm.SendGet15118EVCertificate = c.get15118EVCertificate
+	sm.SendGetCertificateStatus = c.getCertificateStatus
+	sm.SendAuthorizeContract = c.authorize
+	sm.SendAuthorizeIdToken = func(idToken v201.IdToken) (*v201.AuthorizeResponse, error) {
+		c.t.Errorf("Expected no Authorize without certificate hash data, got %s", idToken.IdToken)
+		return nil, fmt.Errorf("unexpected Authorize")
+	}
+}
+
+func (c *contractCSMS) get15118EVCertificate(req *v201.Get15118EVCertificateRequest) (*v201.Get15118EVCertificateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.actions = append(c.actions, req.Action)
+	if req.Iso15118SchemaVersion != iso15118SchemaVersion {
+		c.t.Errorf("Expected schema version %s, got %s", iso15118SchemaVersion, req.Iso15118SchemaVersion)
+	}
+	if c.failUpdates && req.Action == certificateActionUpdate {
+		return &v201.Get15118EVCertificateResponse{Status: "Failed"}, nil
+	}
+	if c.exiResponse != "" {
+		return &v201.Get15118EVCertificateResponse{Status: "Accepted", ExiResponse: c.exiResponse}, nil
+	}
+
+	der, _ := base64.StdEncoding.DecodeString(req.ExiRequest)
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		c.t.Errorf("Expected a certificate request, got %v", err)
+		return &v201.Get15118EVCertificateResponse{Status: "Failed"}, nil
+	}
+
+	cert, err := c.pki.issue(csr.Subject.CommonName, csr.PublicKey.(*ecdsa.PublicKey), c.validity)
+	if err != nil {
+		c.t.Fatalf("Failed to issue contract certificate: %v", err)
+	}
+	c.issued[serialNumber(cert)] = cert
+
+	var chain []byte
+	for _, issued := range []*x509.Certificate{cert, c.pki.subCA, c.pki.root} {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issued.Raw})...)
+	}
+	return &v201.Get15118EVCertificateResponse{Status: "Accepted", ExiResponse: base64.StdEncoding.EncodeToString(chain)}, nil
+}
+
+func (c *contractCSMS) getCertificateStatus(req *v201.GetCertificateStatusRequest) (*v201.GetCertificateStatusResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.statusChecks++
+	cert, ok := c.issued[req.OcspRequestData.SerialNumber]
+	if !ok {
+		return &v201.GetCertificateStatusResponse{Status: "Failed"}, nil
+	}
+	if req.OcspRequestData.ResponderURL != contractResponderURL {
+		c.t.Errorf("Expected responder %s, got %s", contractResponderURL, req.OcspRequestData.ResponderURL)
+	}
+
+	issuer, issuerKey := c.pki.subCA, c.pki.subCAKey
+	if cert == c.pki.subCA {
+		issuer, issuerKey = c.pki.root, c.pki.rootKey
+	}
+	status := ocsp.Good
+	if c.revoked && cert != c.pki.subCA {
+		status = ocsp.Revoked
+	}
+
+	der, err := ocsp.CreateResponse(issuer, issuer, ocsp.Response{
+		Status:       status,
+		SerialNumber: cert.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+		RevokedAt:    time.Now().Add(-time.Minute),
+	}, issuerKey)
+	if err != nil {
+		c.t.Fatalf("Failed to create OCSP response: %v", err)
+	}
+	return &v201.GetCertificateStatusResponse{Status: "Accepted", OcspResult: base64.StdEncoding.EncodeToString(der)}, nil
+}
+
+func (c *contractCSMS) authorize(idToken v201.IdToken, hashData []v201.OCSPRequestData) (*v201.AuthorizeResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.authorized = append(c.authorized, &v201.AuthorizeRequest{IdToken: idToken, Iso15118CertificateHashData: hashData})
+	return &v201.AuthorizeResponse{
+		IdTokenInfo:       v201.IdTokenInfo{Status: v201.AuthorizationStatusAccepted},
+		CertificateStatus: c.certificateStatus,
+	}, nil
+}
+
+func serialNumber(cert *x509.Certificate) string {
+	return hex.EncodeToString(cert.SerialNumber.Bytes())
+}
+
+func newPlugAndChargeSession(t *testing.T) (*SessionManager, *contractCSMS, *eventRecorder) {
+	t.Helper()
+
+	sm := NewSessionManager("CP001", []ConnectorConfig{
+		{ID: 1, Type: "CCS", MaxPower: 150000, Status: "Available", PlugAndCharge: true},
+		{ID: 2, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}, slog.Default())
+	sm.SetProtocolVersion("ocpp2.0.1")
+
+	recorder := &eventRecorder{}
+	sm.SendTransactionEvent = recorder.send
+
+	csms := newContractCSMS(t)
+	csms.attach(sm)
+
+	return sm, csms, recorder
+}
+
+func TestPlugAndCharge_InstallAndAuthorize(t *testing.T) {
+	sm, csms, recorder := newPlugAndChargeSession(t)
+
+	emaid := sm.PlugAndCharge().EMAID(1)
+	if !strings.HasPrefix(emaid, "DEEMUC") || sm.PlugAndCharge().Enabled(2) {
+		t.Fatalf("Expected a derived eMAID for connector 1 only, got %q", emaid)
+	}
+
+	if _, err := sm.StartCharging(1, ""); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+
+	if fmt.Sprint(csms.actions) != "[Install]" {
+		t.Errorf("Expected the contract certificate to be installed, got %v", csms.actions)
+	}
+	if csms.statusChecks != 2 {
+		t.Errorf("Expected OCSP checks of the contract certificate and the sub-CA, got %d", csms.statusChecks)
+	}
+	if len(csms.authorized) != 1 {
+		t.Fatalf("Expected one Authorize, got %d", len(csms.authorized))
+	}
+
+	authorized := csms.authorized[0]
+	if authorized.IdToken.IdToken != emaid || authorized.IdToken.Type != v201.IdTokenTypeEMAID {
+		t.Errorf("Expected Authorize with eMAID %s, got %+v", emaid, authorized.IdToken)
+	}
+	hashData := authorized.Iso15118CertificateHashData
+	if len(hashData) != 2 || hashData[1].SerialNumber != serialNumber(csms.pki.subCA) {
+		t.Errorf("Expected hash data of the contract certificate and the sub-CA, got %+v", hashData)
+	} else if _, ok := csms.issued[hashData[0].SerialNumber]; !ok {
+		t.Errorf("Expected the contract certificate issued by the CSMS, got serial number %s", hashData[0].SerialNumber)
+	}
+	if expiry := sm.PlugAndCharge().ContractExpiry(1); expiry == nil || time.Until(*expiry) < contractRenewal {
+		t.Errorf("Expected an installed contract certificate, got expiry %v", expiry)
+	}
+
+	started := recorder.events[0]
+	if started.IdToken == nil || started.IdToken.IdToken != emaid || started.IdToken.Type != v201.IdTokenTypeEMAID {
+		t.Errorf("Expected the transaction to start with eMAID %s, got %+v", emaid, started.IdToken)
+	}
+
+	// The installed certificate is reused by the next session
+	if err := sm.StopCharging(1, v16.ReasonLocal); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+	if _, err := sm.StartCharging(1, ""); err != nil {
+		t.Fatalf("Second StartCharging failed: %v", err)
+	}
+	if len(csms.actions) != 1 || len(csms.authorized) != 2 {
+		t.Errorf("Expected the installed certificate to be reused, got actions %v", csms.actions)
+	}
+}
+
+func TestPlugAndCharge_UpdateContract(t *testing.T) {
+	sm, csms, _ := newPlugAndChargeSession(t)
+	csms.validity = 10 * 24 * time.Hour
+
+	if _, err := sm.StartCharging(1, ""); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	sm.StopCharging(1, v16.ReasonLocal)
+	installed := csms.authorized[0].Iso15118CertificateHashData[0].SerialNumber
+
+	// Certificates expiring within the renewal period are updated
+	if _, err := sm.StartCharging(1, ""); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	sm.StopCharging(1, v16.ReasonLocal)
+	updated := csms.authorized[1].Iso15118CertificateHashData[0].SerialNumber
+
+	if fmt.Sprint(csms.actions) != "[Install Update]" || updated == installed {
+		t.Errorf("Expected the contract certificate to be updated, got actions %v", csms.actions)
+	}
+
+	// A failed update keeps the still valid certificate
+	csms.failUpdates = true
+	if _, err := sm.StartCharging(1, ""); err != nil {
+		t.Fatalf("Expected the current certificate to be used after a failed update, got %v", err)
+	}
+	if current := csms.authorized[2].Iso15118CertificateHashData[0].SerialNumber; current != updated {
+		t.Errorf("Expected certificate %s, got %s", updated, current)
+	}
+}
+
+func TestPlugAndCharge_RevokedContract(t *testing.T) {
+	sm, csms, recorder := newPlugAndChargeSession(t)
+	csms.revoked = true
+
+	_, err := sm.StartCharging(1, "")
+	if err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Fatalf("Expected a revoked contract certificate to fail, got %v", err)
+	}
+	if len(csms.authorized) != 0 || len(recorder.events) != 0 {
+		t.Errorf("Expected no Authorize and no transaction, got %d Authorize and %v", len(csms.authorized), recorder.summary())
+	}
+}
+
+func TestPlugAndCharge_CertificateRejected(t *testing.T) {
+	sm, csms, recorder := newPlugAndChargeSession(t)
+
+	// Without a certificate chain in the response the test PKI of the station issues the contract
+	csms.exiResponse = base64.StdEncoding.EncodeToString([]byte("EXI encoded CertificateInstallationRes"))
+	status := "CertificateRevoked"
+	csms.certificateStatus = &status
+
+	_, err := sm.StartCharging(1, "")
+	if err == nil || !strings.Contains(err.Error(), "contract certificate rejected: CertificateRevoked") {
+		t.Fatalf("Expected the contract to be rejected, got %v", err)
+	}
+	if len(recorder.events) != 0 {
+		t.Errorf("Expected no transaction, got %v", recorder.summary())
+	}
+
+	hashData := csms.authorized[0].Iso15118CertificateHashData
+	if len(hashData) != 2 || hashData[1].SerialNumber != serialNumber(sm.PlugAndCharge().pki.subCA) {
+		t.Errorf("Expected hash data of the locally issued certificate, got %+v", hashData)
+	}
+}
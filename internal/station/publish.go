@@ -0,0 +1,312 @@
+package station
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// defaultPublishAddress is the listen address of the file server of a Local Controller without
+// a configured address: a random port reachable by the emulated stations on the same host
+const defaultPublishAddress = "127.0.0.1:0"
+
+// publishedFirmware is a firmware image served by a Local Controller
+type publishedFirmware struct {
+	name        string
+	image       []byte
+	publishedAt time.Time
+}
+
+// firmwareFileServer serves the published firmware of a Local Controller over HTTP at
+// /firmware/<checksum>/<file name>
+type firmwareFileServer struct {
+	server  *http.Server
+	baseURL string
+}
+
+// PublishFirmware starts downloading firmware for the stations behind the Local Controller.
+// Once its MD5 checksum has been verified the firmware is served by the file server of the
+// station and its URL reported with PublishFirmwareStatusNotification. A running download is
+// replaced by the new one.
+func (fm *FirmwareManager) PublishFirmware(requestID int, location, checksum string, retries, retryInterval *int) error {
+	if err := validateTransferLocation(location); err != nil {
+		return err
+	}
+	if err := validateChecksum(checksum); err != nil {
+		return err
+	}
+	checksum = strings.ToLower(checksum)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fm.mu.Lock()
+	if fm.publishCancel != nil {
+		fm.logger.Warn("Replacing running firmware download for publishing", "stationId", fm.stationID)
+		fm.publishCancel()
+	}
+	fm.publishCancel = cancel
+	fm.publishID++
+	publishID := fm.publishID
+	fm.publishChecksum = checksum
+	fm.publishRequestID = &requestID
+	fm.mu.Unlock()
+
+	fm.logger.Info("Publishing firmware",
+		"stationId", fm.stationID,
+		"location", location,
+		"checksum", checksum,
+		"requestId", requestID,
+	)
+
+	go fm.runPublish(ctx, publishID, location, checksum, attempts(retries), fm.retryDelay(retryInterval))
+
+	return nil
+}
+
+// UnpublishFirmware stops serving the firmware with the checksum
+func (fm *FirmwareManager) UnpublishFirmware(checksum string) v21.UnpublishFirmwareStatusType {
+	checksum = strings.ToLower(checksum)
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.publishCancel != nil && fm.publishChecksum == checksum {
+		return v21.UnpublishFirmwareStatusDownloadOngoing
+	}
+	if _, ok := fm.published[checksum]; !ok {
+		return v21.UnpublishFirmwareStatusNoFirmware
+	}
+
+	delete(fm.published, checksum)
+	fm.logger.Info("Unpublished firmware", "stationId", fm.stationID, "checksum", checksum)
+
+	return v21.UnpublishFirmwareStatusUnpublished
+}
+
+// PublishFirmwareStatus returns the last reported publish status and its request ID
+func (fm *FirmwareManager) PublishFirmwareStatus() (v21.PublishFirmwareStatusType, *int) {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.publishStatus, fm.publishRequestID
+}
+
+// PublishedFirmware returns the URLs of the published firmware by checksum
+func (fm *FirmwareManager) PublishedFirmware() map[string]string {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+
+	locations := make(map[string]string, len(fm.published))
+	for checksum, firmware := range fm.published {
+		locations[checksum] = fm.publishedLocation(checksum, firmware.name)
+	}
+	return locations
+}
+
+// runPublish downloads, verifies and publishes firmware
+func (fm *FirmwareManager) runPublish(ctx context.Context, publishID int, location, checksum string, maxAttempts int, retryDelay time.Duration) {
+	defer fm.finishPublish(publishID)
+
+	var image []byte
+	downloaded := false
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		fm.setPublishStatus(v21.PublishFirmwareStatusDownloading, nil)
+
+		data, err := fm.download(ctx, location)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			image = data
+			downloaded = true
+			break
+		}
+
+		fm.logger.Warn("Firmware download for publishing failed",
+			"stationId", fm.stationID,
+			"attempt", attempt,
+			"maxAttempts", maxAttempts,
+			"error", err,
+		)
+
+		if attempt < maxAttempts && !fm.sleep(ctx, retryDelay) {
+			return
+		}
+	}
+
+	if !downloaded {
+		fm.setPublishStatus(v21.PublishFirmwareStatusDownloadFailed, nil)
+		return
+	}
+
+	fm.setPublishStatus(v21.PublishFirmwareStatusDownloaded, nil)
+
+	digest := md5.Sum(image)
+	if hex.EncodeToString(digest[:]) != checksum {
+		fm.logger.Warn("Invalid firmware checksum", "stationId", fm.stationID, "location", location, "checksum", checksum)
+		fm.setPublishStatus(v21.PublishFirmwareStatusInvalidChecksum, nil)
+		return
+	}
+
+	fm.setPublishStatus(v21.PublishFirmwareStatusChecksumVerified, nil)
+
+	published, err := fm.publish(checksum, firmwareFileName(location), image)
+	if err != nil {
+		fm.logger.Error("Failed to publish firmware", "stationId", fm.stationID, "error", err)
+		fm.setPublishStatus(v21.PublishFirmwareStatusPublishFailed, nil)
+		return
+	}
+
+	fm.setPublishStatus(v21.PublishFirmwareStatusPublished, []string{published})
+}
+
+// publish serves a firmware image, starting the file server with the first one, and returns its URL
+func (fm *FirmwareManager) publish(checksum, name string, image []byte) (string, error) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.fileServer == nil {
+		server, err := fm.startFileServer(fm.config.PublishAddress)
+		if err != nil {
+			return "", err
+		}
+		fm.fileServer = server
+	}
+
+	fm.published[checksum] = publishedFirmware{
+		name:        name,
+		image:       image,
+		publishedAt: time.Now(),
+	}
+
+	return fm.publishedLocation(checksum, name), nil
+}
+
+// publishedLocation returns the URL of published firmware. The caller must hold fm.mu.
+func (fm *FirmwareManager) publishedLocation(checksum, name string) string {
+	if fm.fileServer == nil {
+		return ""
+	}
+	return fm.fileServer.baseURL + "/firmware/" + checksum + "/" + url.PathEscape(name)
+}
+
+// finishPublish clears the running download unless it has been replaced
+func (fm *FirmwareManager) finishPublish(publishID int) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.publishID == publishID && fm.publishCancel != nil {
+		fm.publishCancel()
+		fm.publishCancel = nil
+		fm.publishChecksum = ""
+	}
+}
+
+// setPublishStatus records and reports a publish status
+func (fm *FirmwareManager) setPublishStatus(status v21.PublishFirmwareStatusType, locations []string) {
+	fm.mu.Lock()
+	fm.publishStatus = status
+	requestID := 0
+	if fm.publishRequestID != nil {
+		requestID = *fm.publishRequestID
+	}
+	fm.mu.Unlock()
+
+	fm.logger.Info("Publish firmware status changed", "stationId", fm.stationID, "status", status, "requestId", requestID)
+
+	if fm.SendPublishFirmwareStatus != nil {
+		if err := fm.SendPublishFirmwareStatus(status, locations, requestID); err != nil {
+			fm.logger.Warn("Failed to report publish firmware status", "stationId", fm.stationID, "error", err)
+		}
+	}
+}
+
+// startFileServer listens on the address and serves published firmware
+func (fm *FirmwareManager) startFileServer(address string) (*firmwareFileServer, error) {
+	if address == "" {
+		address = defaultPublishAddress
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	// Stations on the same host reach an unspecified address through localhost
+	host, _, _ := net.SplitHostPort(address)
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /firmware/{checksum}/{name}", fm.servePublished)
+
+	fileServer := &firmwareFileServer{
+		server:  &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		baseURL: "http://" + net.JoinHostPort(host, port),
+	}
+
+	go func() {
+		if err := fileServer.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fm.logger.Error("Firmware file server stopped", "stationId", fm.stationID, "error", err)
+		}
+	}()
+
+	fm.logger.Info("Started firmware file server", "stationId", fm.stationID, "url", fileServer.baseURL)
+	return fileServer, nil
+}
+
+// servePublished serves a published firmware image
+func (fm *FirmwareManager) servePublished(w http.ResponseWriter, r *http.Request) {
+	fm.mu.RLock()
+	firmware, ok := fm.published[strings.ToLower(r.PathValue("checksum"))]
+	fm.mu.RUnlock()
+
+	if !ok || firmware.name != r.PathValue("name") {
+		http.NotFound(w, r)
+		return
+	}
+
+	fm.logger.Info("Serving published firmware", "stationId", fm.stationID, "file", firmware.name, "remoteAddr", r.RemoteAddr)
+	http.ServeContent(w, r, firmware.name, firmware.publishedAt, bytes.NewReader(firmware.image))
+}
+
+// close stops the file server
+func (s *firmwareFileServer) close() {
+	s.server.Close()
+}
+
+// validateChecksum checks that a checksum is a hex encoded MD5 digest
+func validateChecksum(checksum string) error {
+	digest, err := hex.DecodeString(checksum)
+	if err != nil || len(digest) != md5.Size {
+		return fmt.Errorf("invalid checksum %q, expected a hex encoded MD5 digest", checksum)
+	}
+	return nil
+}
+
+// firmwareFileName returns the file name of a firmware location, e.g.
+// "https://example.com/fw/cp-2.1.0.bin" becomes "cp-2.1.0.bin"
+func firmwareFileName(location string) string {
+	p := location
+	if u, err := url.Parse(location); err == nil {
+		p = u.Path
+	}
+
+	name := path.Base(p)
+	if name == "." || name == "/" || name == "" {
+		return "firmware.bin"
+	}
+	return name
+}
@@ -0,0 +1,185 @@
+package station
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// recordPublishStatuses records the statuses and the locations reported by a Local Controller
+func recordPublishStatuses(fm *FirmwareManager) (func() []v21.PublishFirmwareStatusType, func() []string) {
+	var mu sync.Mutex
+	var statuses []v21.PublishFirmwareStatusType
+	var locations []string
+	fm.SendPublishFirmwareStatus = func(status v21.PublishFirmwareStatusType, published []string, requestID int) error {
+		mu.Lock()
+		statuses = append(statuses, status)
+		locations = append(locations, published...)
+		mu.Unlock()
+		return nil
+	}
+
+	recorded := func() []v21.PublishFirmwareStatusType {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]v21.PublishFirmwareStatusType(nil), statuses...)
+	}
+	published := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), locations...)
+	}
+	return recorded, published
+}
+
+func TestFirmwareManager_PublishFirmware(t *testing.T) {
+	image := []byte("firmware image 4.0.0")
+	digest := md5.Sum(image)
+	checksum := hex.EncodeToString(digest[:])
+
+	csms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(image)
+	}))
+	defer csms.Close()
+
+	controller, _ := newTestFirmwareManager(FirmwareSimulationConfig{})
+	defer controller.Shutdown()
+	statuses, locations := recordPublishStatuses(controller)
+
+	if err := controller.PublishFirmware(7, csms.URL+"/fw/cp-4.0.0.bin", "not-a-checksum", nil, nil); err == nil {
+		t.Error("Expected an invalid checksum to be rejected")
+	}
+	if err := controller.PublishFirmware(7, "ftp://example.com/fw/cp-4.0.0.bin", checksum, nil, nil); err == nil {
+		t.Error("Expected an FTP location to be rejected")
+	}
+
+	if err := controller.PublishFirmware(7, csms.URL+"/fw/cp-4.0.0.bin", strings.ToUpper(checksum), nil, nil); err != nil {
+		t.Fatalf("Expected PublishFirmware to be accepted, got %v", err)
+	}
+	waitFor(t, func() bool { return len(locations()) > 0 })
+
+	expected := []v21.PublishFirmwareStatusType{
+		v21.PublishFirmwareStatusDownloading,
+		v21.PublishFirmwareStatusDownloaded,
+		v21.PublishFirmwareStatusChecksumVerified,
+		v21.PublishFirmwareStatusPublished,
+	}
+	if !reflect.DeepEqual(statuses(), expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, statuses())
+	}
+	published := locations()[0]
+	if !strings.HasSuffix(published, "/firmware/"+checksum+"/cp-4.0.0.bin") {
+		t.Errorf("Expected the firmware to be published by checksum and file name, got %s", published)
+	}
+	if status, requestID := controller.PublishFirmwareStatus(); status != v21.PublishFirmwareStatusPublished || requestID == nil || *requestID != 7 {
+		t.Errorf("Expected Published for request 7, got %s %v", status, requestID)
+	}
+
+	// Another station updates its firmware from the Local Controller
+	station := NewFirmwareManager("CP002", FirmwareSimulationConfig{}, slog.Default())
+	station.timeUnit = time.Millisecond
+	updated := recordSignedStatuses(station)
+	installed := make(chan string, 1)
+	station.OnFirmwareInstalled = func(version string) {
+		installed <- version
+	}
+	station.DownloadFirmware(1, FirmwareImage{Location: published, RetrieveDate: time.Now()}, nil, nil)
+
+	select {
+	case version := <-installed:
+		if version != "cp-4.0.0" {
+			t.Errorf("Expected version cp-4.0.0, got %s", version)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for the firmware from the Local Controller, got %v", updated())
+	}
+
+	if status := controller.UnpublishFirmware("00000000000000000000000000000000"); status != v21.UnpublishFirmwareStatusNoFirmware {
+		t.Errorf("Expected NoFirmware for an unknown checksum, got %s", status)
+	}
+	if status := controller.UnpublishFirmware(checksum); status != v21.UnpublishFirmwareStatusUnpublished {
+		t.Errorf("Expected Unpublished, got %s", status)
+	}
+
+	resp, err := http.Get(published)
+	if err != nil {
+		t.Fatalf("Expected the file server to keep running, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected unpublished firmware to be gone, got %s", resp.Status)
+	}
+}
+
+func TestFirmwareManager_PublishFirmware_InvalidChecksum(t *testing.T) {
+	release := make(chan struct{})
+	csms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("tampered firmware image"))
+	}))
+	defer csms.Close()
+
+	controller, _ := newTestFirmwareManager(FirmwareSimulationConfig{})
+	defer controller.Shutdown()
+	statuses, locations := recordPublishStatuses(controller)
+
+	checksum := "0123456789abcdef0123456789abcdef"
+	if err := controller.PublishFirmware(8, csms.URL+"/fw.bin", checksum, nil, nil); err != nil {
+		t.Fatalf("Expected PublishFirmware to be accepted, got %v", err)
+	}
+
+	waitFor(t, func() bool { return len(statuses()) > 0 })
+	if status := controller.UnpublishFirmware(checksum); status != v21.UnpublishFirmwareStatusDownloadOngoing {
+		t.Errorf("Expected DownloadOngoing while downloading, got %s", status)
+	}
+	close(release)
+
+	waitFor(t, func() bool {
+		status, _ := controller.PublishFirmwareStatus()
+		return status == v21.PublishFirmwareStatusInvalidChecksum
+	})
+	if len(locations()) != 0 || len(controller.PublishedFirmware()) != 0 {
+		t.Errorf("Expected firmware with an invalid checksum not to be published, got %v", locations())
+	}
+	waitFor(t, func() bool {
+		return controller.UnpublishFirmware(checksum) == v21.UnpublishFirmwareStatusNoFirmware
+	})
+}
+
+func TestHandleV21PublishFirmware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	station := &Station{
+		Config:   Config{StationID: "TEST023", ProtocolVersion: "ocpp2.1"},
+		Firmware: NewFirmwareManager("TEST023", FirmwareSimulationConfig{}, logger),
+	}
+	defer station.Firmware.Shutdown()
+	manager.mu.Lock()
+	manager.stations["TEST023"] = station
+	manager.mu.Unlock()
+
+	resp, _ := manager.v21Handler.OnPublishFirmware("TEST023", &v21.PublishFirmwareRequest{
+		Location:  "https://example.com/fw/cp-4.0.0.bin",
+		Checksum:  "d41d8cd98f00b204",
+		RequestId: 3,
+	})
+	if resp.Status != v21.GenericStatusRejected || resp.StatusInfo == nil || resp.StatusInfo.ReasonCode != "InvalidValue" {
+		t.Errorf("Expected Rejected for a truncated checksum, got %s %+v", resp.Status, resp.StatusInfo)
+	}
+
+	unpublished, _ := manager.v21Handler.OnUnpublishFirmware("TEST023", &v21.UnpublishFirmwareRequest{Checksum: "d41d8cd98f00b204e9800998ecf8427e"})
+	if unpublished.Status != v21.UnpublishFirmwareStatusNoFirmware {
+		t.Errorf("Expected NoFirmware, got %s", unpublished.Status)
+	}
+}
@@ -0,0 +1,419 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruslanhut/ocpp-emu/internal/storage"
+)
+
+// Defaults of the transaction message retry policy (OCPP 1.6 TransactionMessageAttempts/RetryInterval)
+const (
+	defaultTransactionMessageAttempts      = 3
+	defaultTransactionMessageRetryInterval = 60 * time.Second
+)
+
+// queueSnapshot is the state of a queue to persist
+type queueSnapshot struct {
+	version uint64
+	data    storage.MessageQueueData
+}
+
+// QueuedMessage is a transaction related OCPP Call awaiting delivery to the CSMS
+type QueuedMessage struct {
+	Action        string
+	Payload       json.RawMessage
+	TransactionID string // Transaction the message belongs to, empty if none
+	Attempts      int    // Failed delivery attempts
+	QueuedAt      time.Time
+}
+
+// MessageQueue is the durable outbound queue of transaction related messages of a station.
+// Messages are queued while the station is offline and replayed in order once it is back online.
+type MessageQueue struct {
+	stationID     string
+	messages      []QueuedMessage
+	online        bool
+	replayCancel  context.CancelFunc
+	replayMu      sync.Mutex // Held while replaying so a replay never overlaps its predecessor
+	maxAttempts   int
+	retryInterval time.Duration
+	nextLocalTxID int // Temporary transaction IDs are negative so they never clash with CSMS assigned IDs
+	mu            sync.Mutex
+	repo          *storage.MessageQueueRepository
+	logger        *slog.Logger
+
+	// Snapshots are versioned under mu and saved under persistMu, so a stale snapshot
+	// saved after a newer one never overwrites it
+	snapshotVersion  uint64
+	persistedVersion uint64
+	persistMu        sync.Mutex
+
+	// Send delivers a queued message and blocks until the CSMS has responded
+	// or the context is cancelled because the station went offline
+	Send func(ctx context.Context, msg QueuedMessage) error
+}
+
+// NewMessageQueue creates an empty message queue for a station
+func NewMessageQueue(stationID string, logger *slog.Logger) *MessageQueue {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &MessageQueue{
+		stationID:     stationID,
+		maxAttempts:   defaultTransactionMessageAttempts,
+		retryInterval: defaultTransactionMessageRetryInterval,
+		nextLocalTxID: -1,
+		logger:        logger,
+	}
+}
+
+// SetRepository sets the repository used to persist the queue
+func (q *MessageQueue) SetRepository(repo *storage.MessageQueueRepository) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.repo = repo
+}
+
+// Load restores the queued messages from the repository
+func (q *MessageQueue) Load(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.repo == nil {
+		return nil
+	}
+
+	data, err := q.repo.Get(ctx, q.stationID)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	q.messages = make([]QueuedMessage, 0, len(data.Messages))
+	for _, msg := range data.Messages {
+		q.messages = append(q.messages, QueuedMessage{
+			Action:        msg.Action,
+			Payload:       json.RawMessage(msg.Payload),
+			TransactionID: msg.TransactionID,
+			Attempts:      msg.Attempts,
+			QueuedAt:      msg.QueuedAt,
+		})
+
+		// Do not hand out temporary IDs still referenced by queued messages
+		if id, err := strconv.Atoi(msg.TransactionID); err == nil && id <= q.nextLocalTxID {
+			q.nextLocalTxID = id - 1
+		}
+	}
+
+	q.logger.Info("Loaded message queue", "stationId", q.stationID, "messages", len(q.messages))
+
+	return nil
+}
+
+// SetRetryPolicy sets how often delivery of a message is attempted and the interval between attempts.
+// The wait before a retry grows with the number of failed attempts.
+func (q *MessageQueue) SetRetryPolicy(attempts int, interval time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if attempts < 1 {
+		attempts = 1
+	}
+	q.maxAttempts = attempts
+	q.retryInterval = interval
+}
+
+// NextTemporaryTransactionID returns an ID for a transaction until the CSMS assigns one
+func (q *MessageQueue) NextTemporaryTransactionID() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := q.nextLocalTxID
+	q.nextLocalTxID--
+	return id
+}
+
+// ShouldQueue reports whether a message must be queued instead of sent directly:
+// the station is offline or older messages still wait for delivery
+func (q *MessageQueue) ShouldQueue() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return !q.online || len(q.messages) > 0
+}
+
+// Enqueue appends a message to the queue and starts delivering it if the station is online
+func (q *MessageQueue) Enqueue(action string, payload interface{}, transactionID string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", action, err)
+	}
+
+	q.mu.Lock()
+	q.messages = append(q.messages, QueuedMessage{
+		Action:        action,
+		Payload:       data,
+		TransactionID: transactionID,
+		QueuedAt:      time.Now(),
+	})
+	depth := len(q.messages)
+	snapshot := q.snapshotLocked()
+	q.startReplayLocked()
+	q.mu.Unlock()
+
+	q.logger.Info("Queued message", "stationId", q.stationID, "action", action, "transactionId", transactionID, "queueDepth", depth)
+
+	q.persist(snapshot)
+	return nil
+}
+
+// SetOnline marks the station as able to deliver messages. Going online replays the queue,
+// going offline pauses the replay until the station is back.
+func (q *MessageQueue) SetOnline(online bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.online = online
+	if online {
+		q.startReplayLocked()
+		return
+	}
+
+	if q.replayCancel != nil {
+		q.replayCancel()
+		q.replayCancel = nil
+	}
+}
+
+// IsOnline reports whether the station is able to deliver messages
+func (q *MessageQueue) IsOnline() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.online
+}
+
+// Len returns the number of queued messages
+func (q *MessageQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages)
+}
+
+// HasMessages reports whether messages of a transaction are queued.
+// An empty transaction ID matches any queued message.
+func (q *MessageQueue) HasMessages(transactionID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, msg := range q.messages {
+		if transactionID == "" || msg.TransactionID == transactionID {
+			return true
+		}
+	}
+	return false
+}
+
+// Messages returns a copy of the queued messages in delivery order
+func (q *MessageQueue) Messages() []QueuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	messages := make([]QueuedMessage, len(q.messages))
+	copy(messages, q.messages)
+	return messages
+}
+
+// RemapTransactionID replaces a temporary transaction ID in the queued messages
+// with the ID assigned by the CSMS
+func (q *MessageQueue) RemapTransactionID(oldID, newID int) {
+	oldKey, newKey := strconv.Itoa(oldID), strconv.Itoa(newID)
+
+	q.mu.Lock()
+	remapped := 0
+	for i := range q.messages {
+		msg := &q.messages[i]
+		if msg.TransactionID != oldKey {
+			continue
+		}
+		msg.TransactionID = newKey
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(msg.Payload, &fields); err != nil {
+			continue
+		}
+		if value, ok := fields["transactionId"]; ok && strings.TrimSpace(string(value)) == oldKey {
+			fields["transactionId"] = json.RawMessage(newKey)
+			if payload, err := json.Marshal(fields); err == nil {
+				msg.Payload = payload
+			}
+		}
+		remapped++
+	}
+	snapshot := q.snapshotLocked()
+	q.mu.Unlock()
+
+	if remapped > 0 {
+		q.logger.Info("Remapped transaction ID of queued messages",
+			"stationId", q.stationID,
+			"oldTransactionId", oldID,
+			"newTransactionId", newID,
+			"messages", remapped,
+		)
+		q.persist(snapshot)
+	}
+}
+
+// startReplayLocked starts delivering queued messages unless the station is offline
+// or a replay is already running. Must be called with q.mu held.
+func (q *MessageQueue) startReplayLocked() {
+	if !q.online || q.replayCancel != nil || len(q.messages) == 0 || q.Send == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.replayCancel = cancel
+
+	go q.replay(ctx)
+}
+
+// replay delivers the queued messages in order. A message that fails is retried after a
+// growing delay and dropped once the configured number of attempts has been reached.
+func (q *MessageQueue) replay(ctx context.Context) {
+	q.replayMu.Lock()
+	defer q.replayMu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	q.logger.Info("Replaying message queue", "stationId", q.stationID, "messages", q.Len())
+
+	for {
+		q.mu.Lock()
+		if len(q.messages) == 0 {
+			// Finished under the lock so a message queued meanwhile starts a new replay
+			if ctx.Err() == nil {
+				q.replayCancel()
+				q.replayCancel = nil
+			}
+			q.mu.Unlock()
+			q.logger.Info("Message queue delivered", "stationId", q.stationID)
+			return
+		}
+		msg := q.messages[0]
+		q.mu.Unlock()
+
+		err := q.Send(ctx, msg)
+
+		// Gone offline, the message is sent again after the next reconnect
+		if ctx.Err() != nil {
+			return
+		}
+
+		q.mu.Lock()
+		if err == nil {
+			q.messages = q.messages[1:]
+			snapshot := q.snapshotLocked()
+			q.mu.Unlock()
+
+			q.persist(snapshot)
+			continue
+		}
+
+		q.messages[0].Attempts++
+		attempts := q.messages[0].Attempts
+		if attempts >= q.maxAttempts {
+			q.messages = q.messages[1:]
+		}
+		delay := q.retryInterval * time.Duration(attempts)
+		maxAttempts := q.maxAttempts
+		snapshot := q.snapshotLocked()
+		q.mu.Unlock()
+
+		q.persist(snapshot)
+
+		if attempts >= maxAttempts {
+			q.logger.Error("Dropping queued message after failed attempts",
+				"stationId", q.stationID,
+				"action", msg.Action,
+				"attempts", attempts,
+				"error", err,
+			)
+			continue
+		}
+
+		q.logger.Warn("Failed to deliver queued message, retrying",
+			"stationId", q.stationID,
+			"action", msg.Action,
+			"attempt", attempts,
+			"retryIn", delay,
+			"error", err,
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// snapshotLocked returns the next version of the queue as stored in MongoDB. Must be called
+// with q.mu held.
+func (q *MessageQueue) snapshotLocked() queueSnapshot {
+	data := storage.MessageQueueData{
+		StationID: q.stationID,
+		Messages:  make([]storage.QueuedMessage, 0, len(q.messages)),
+	}
+
+	for _, msg := range q.messages {
+		data.Messages = append(data.Messages, storage.QueuedMessage{
+			Action:        msg.Action,
+			Payload:       string(msg.Payload),
+			TransactionID: msg.TransactionID,
+			Attempts:      msg.Attempts,
+			QueuedAt:      msg.QueuedAt,
+		})
+	}
+
+	q.snapshotVersion++
+	return queueSnapshot{version: q.snapshotVersion, data: data}
+}
+
+// persist saves a snapshot of the queue to the repository unless a newer one has been saved
+func (q *MessageQueue) persist(snapshot queueSnapshot) {
+	q.mu.Lock()
+	repo := q.repo
+	q.mu.Unlock()
+
+	if repo == nil {
+		return
+	}
+
+	q.persistMu.Lock()
+	defer q.persistMu.Unlock()
+
+	if snapshot.version <= q.persistedVersion {
+		return
+	}
+	q.persistedVersion = snapshot.version
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.Save(ctx, snapshot.data); err != nil {
+		q.logger.Error("Failed to persist message queue",
+			"stationId", q.stationID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,167 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+)
+
+// recordingSender collects the messages delivered by a message queue
+type recordingSender struct {
+	mu       sync.Mutex
+	actions  []string
+	payloads []json.RawMessage
+	failures int // Number of deliveries that fail before succeeding
+}
+
+func (rs *recordingSender) send(ctx context.Context, msg QueuedMessage) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.failures > 0 {
+		rs.failures--
+		return fmt.Errorf("CSMS unavailable")
+	}
+	rs.actions = append(rs.actions, msg.Action)
+	rs.payloads = append(rs.payloads, msg.Payload)
+	return nil
+}
+
+func (rs *recordingSender) sent() []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]string(nil), rs.actions...)
+}
+
+func waitForQueue(t *testing.T, q *MessageQueue, depth int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for q.Len() != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected queue depth %d, got %d", depth, q.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMessageQueue_ReplayInOrder(t *testing.T) {
+	q := NewMessageQueue("CP001", nil)
+	sender := &recordingSender{}
+	q.Send = sender.send
+
+	if !q.ShouldQueue() {
+		t.Fatal("Expected messages to be queued while offline")
+	}
+
+	txID := 42
+	q.Enqueue(string(v16.ActionStartTransaction), &v16.StartTransactionRequest{ConnectorId: 1, IdTag: "TAG1"}, "42")
+	q.Enqueue(string(v16.ActionMeterValues), &v16.MeterValuesRequest{ConnectorId: 1, TransactionId: &txID}, "42")
+	q.Enqueue(string(v16.ActionStopTransaction), &v16.StopTransactionRequest{TransactionId: txID}, "42")
+
+	if q.Len() != 3 || !q.HasMessages("42") || q.HasMessages("7") {
+		t.Fatalf("Expected 3 messages of transaction 42, got %d", q.Len())
+	}
+	if len(sender.sent()) != 0 {
+		t.Fatal("Expected nothing to be sent while offline")
+	}
+
+	q.SetOnline(true)
+	waitForQueue(t, q, 0)
+
+	sent := sender.sent()
+	expected := []string{"StartTransaction", "MeterValues", "StopTransaction"}
+	if fmt.Sprint(sent) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, sent)
+	}
+	if q.ShouldQueue() {
+		t.Error("Expected direct sending once the queue is empty and the station online")
+	}
+}
+
+func TestMessageQueue_RetryAndDrop(t *testing.T) {
+	q := NewMessageQueue("CP001", nil)
+	q.SetRetryPolicy(2, time.Millisecond)
+	sender := &recordingSender{failures: 3}
+	q.Send = sender.send
+
+	q.Enqueue(string(v16.ActionStopTransaction), &v16.StopTransactionRequest{TransactionId: 1}, "1")
+	q.Enqueue(string(v16.ActionStopTransaction), &v16.StopTransactionRequest{TransactionId: 2}, "2")
+	q.SetOnline(true)
+	waitForQueue(t, q, 0)
+
+	// The first message is dropped after two attempts, the second succeeds on its second attempt
+	sent := sender.sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected one delivered message, got %v", sent)
+	}
+
+	var req v16.StopTransactionRequest
+	json.Unmarshal(sender.payloads[0], &req)
+	if req.TransactionId != 2 {
+		t.Errorf("Expected transaction 2 to be delivered, got %d", req.TransactionId)
+	}
+}
+
+func TestMessageQueue_PauseWhileOffline(t *testing.T) {
+	q := NewMessageQueue("CP001", nil)
+	q.SetRetryPolicy(10, time.Hour)
+	sender := &recordingSender{failures: 1}
+	q.Send = sender.send
+
+	q.Enqueue(string(v16.ActionStopTransaction), &v16.StopTransactionRequest{TransactionId: 1}, "1")
+	q.SetOnline(true)
+
+	// The failed message waits for its retry, going offline must keep it queued
+	time.Sleep(20 * time.Millisecond)
+	q.SetOnline(false)
+
+	if q.Len() != 1 {
+		t.Fatalf("Expected message to stay queued, got %d", q.Len())
+	}
+
+	q.SetOnline(true)
+	waitForQueue(t, q, 0)
+
+	if len(sender.sent()) != 1 {
+		t.Errorf("Expected message to be delivered after reconnect, got %v", sender.sent())
+	}
+}
+
+func TestMessageQueue_RemapTransactionID(t *testing.T) {
+	q := NewMessageQueue("CP001", nil)
+
+	localID := q.NextTemporaryTransactionID()
+	if localID >= 0 || q.NextTemporaryTransactionID() == localID {
+		t.Fatalf("Expected unique negative temporary IDs, got %d", localID)
+	}
+
+	q.Enqueue(string(v16.ActionStartTransaction), &v16.StartTransactionRequest{ConnectorId: 1, IdTag: "TAG1"}, fmt.Sprint(localID))
+	q.Enqueue(string(v16.ActionMeterValues), &v16.MeterValuesRequest{ConnectorId: 1, TransactionId: &localID}, fmt.Sprint(localID))
+	q.Enqueue(string(v16.ActionStopTransaction), &v16.StopTransactionRequest{TransactionId: localID}, fmt.Sprint(localID))
+
+	q.RemapTransactionID(localID, 1234)
+
+	if q.HasMessages(fmt.Sprint(localID)) || !q.HasMessages("1234") {
+		t.Fatal("Expected messages to belong to transaction 1234")
+	}
+
+	messages := q.Messages()
+
+	var meterValues v16.MeterValuesRequest
+	json.Unmarshal(messages[1].Payload, &meterValues)
+	if meterValues.TransactionId == nil || *meterValues.TransactionId != 1234 {
+		t.Errorf("Expected MeterValues for transaction 1234, got %v", meterValues.TransactionId)
+	}
+
+	var stop v16.StopTransactionRequest
+	json.Unmarshal(messages[2].Payload, &stop)
+	if stop.TransactionId != 1234 {
+		t.Errorf("Expected StopTransaction for transaction 1234, got %d", stop.TransactionId)
+	}
+}
@@ -0,0 +1,477 @@
+package station
+
+import (
+	"fmt"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+// ReserveNow reserves a connector for an ID tag until the expiry date.
+// The connector moves to Reserved and the reservation is removed automatically on expiry.
+func (sm *SessionManager) ReserveNow(connectorID, reservationID int, idTag, parentIDTag string, expiryDate time.Time) v16.ReservationStatus {
+	sm.logger.Info("Reserving connector",
+		"stationId", sm.stationID,
+		"connectorId", connectorID,
+		"reservationId", reservationID,
+		"idTag", idTag,
+		"expiryDate", expiryDate,
+	)
+
+	// Reservations of the whole charge point (connector 0) are not supported
+	if connectorID == 0 {
+		return v16.ReservationStatusRejected
+	}
+
+	connector, err := sm.GetConnector(connectorID)
+	if err != nil {
+		return v16.ReservationStatusRejected
+	}
+
+	if !expiryDate.After(time.Now()) {
+		return v16.ReservationStatusRejected
+	}
+
+	// A reservation with the same ID on another connector is replaced
+	if existing := sm.findReservation(reservationID); existing != nil && existing.ID != connectorID {
+		sm.removeReservation(existing, "Reservation moved to another connector")
+	}
+
+	switch connector.GetState() {
+	case ConnectorStateAvailable:
+	case ConnectorStateReserved:
+		if reservation := connector.GetReservation(); reservation == nil || reservation.ID != reservationID {
+			return v16.ReservationStatusOccupied
+		}
+	case ConnectorStateFaulted:
+		return v16.ReservationStatusFaulted
+	case ConnectorStateUnavailable:
+		return v16.ReservationStatusUnavailable
+	default:
+		return v16.ReservationStatusOccupied
+	}
+
+	if err := connector.Reserve(reservationID, idTag, expiryDate, parentIDTag); err != nil {
+		sm.logger.Warn("Failed to reserve connector", "connectorId", connectorID, "error", err)
+		return v16.ReservationStatusRejected
+	}
+
+	if connector.GetState() != ConnectorStateReserved {
+		if err := connector.SetState(ConnectorStateReserved, v16.ChargePointErrorNoError, ""); err != nil {
+			connector.CancelReservation()
+			sm.logger.Warn("Failed to set state to Reserved", "connectorId", connectorID, "error", err)
+			return v16.ReservationStatusRejected
+		}
+
+		if sm.SendStatusNotification != nil {
+			sm.SendStatusNotification(connectorID, v16.ChargePointStatusReserved, v16.ChargePointErrorNoError, "")
+		}
+	}
+
+	sm.scheduleReservationExpiry(connectorID, reservationID, expiryDate)
+
+	return v16.ReservationStatusAccepted
+}
+
+// CancelReservation cancels the reservation with the given ID
+func (sm *SessionManager) CancelReservation(reservationID int) error {
+	connector := sm.findReservation(reservationID)
+	if connector == nil {
+		if !sm.removeUnboundReservation(reservationID) {
+			return fmt.Errorf("reservation %d not found", reservationID)
+		}
+
+		sm.logger.Info("Cancelling reservation", "stationId", sm.stationID, "reservationId", reservationID)
+		sm.updateHeldConnectors()
+		return nil
+	}
+
+	sm.logger.Info("Cancelling reservation",
+		"stationId", sm.stationID,
+		"connectorId", connector.ID,
+		"reservationId", reservationID,
+	)
+
+	sm.removeReservation(connector, "")
+	return nil
+}
+
+// findReservation returns the connector holding the reservation with the given ID
+func (sm *SessionManager) findReservation(reservationID int) *Connector {
+	for _, connector := range sm.GetAllConnectors() {
+		if reservation := connector.GetReservation(); reservation != nil && reservation.ID == reservationID {
+			return connector
+		}
+	}
+	return nil
+}
+
+// scheduleReservationExpiry (re)starts the expiry timer of a connector reservation
+func (sm *SessionManager) scheduleReservationExpiry(connectorID, reservationID int, expiryDate time.Time) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if timer, exists := sm.reservationTimers[connectorID]; exists {
+		timer.Stop()
+	}
+
+	sm.reservationTimers[connectorID] = time.AfterFunc(time.Until(expiryDate), func() {
+		connector, err := sm.GetConnector(connectorID)
+		if err != nil {
+			return
+		}
+
+		reservation := connector.GetReservation()
+		if reservation == nil || reservation.ID != reservationID {
+			return
+		}
+
+		sm.logger.Info("Reservation expired",
+			"stationId", sm.stationID,
+			"connectorId", connectorID,
+			"reservationId", reservationID,
+		)
+
+		sm.removeReservation(connector, "")
+		sm.reportReservationEnded(reservationID, v201.ReservationUpdateStatusExpired)
+	})
+}
+
+// stopReservationTimer stops the expiry timer of a connector reservation
+func (sm *SessionManager) stopReservationTimer(connectorID int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if timer, exists := sm.reservationTimers[connectorID]; exists {
+		timer.Stop()
+		delete(sm.reservationTimers, connectorID)
+	}
+}
+
+// removeReservation clears a connector reservation and makes the connector Available again
+func (sm *SessionManager) removeReservation(connector *Connector, info string) {
+	sm.stopReservationTimer(connector.ID)
+
+	if err := connector.CancelReservation(); err != nil {
+		return
+	}
+
+	if connector.GetState() != ConnectorStateReserved {
+		return
+	}
+
+	if err := connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, info); err != nil {
+		sm.logger.Warn("Failed to set state to Available", "connectorId", connector.ID, "error", err)
+		return
+	}
+
+	if sm.SendStatusNotification != nil {
+		sm.SendStatusNotification(connector.ID, v16.ChargePointStatusAvailable, v16.ChargePointErrorNoError, info)
+	}
+}
+
+// consumeReservation removes a reservation that was used to start a transaction, either the
+// reservation of the connector or a reservation without EVSE
+func (sm *SessionManager) consumeReservation(connector *Connector, reservationID int) {
+	sm.stopReservationTimer(connector.ID)
+	connector.CancelReservation()
+
+	if sm.removeUnboundReservation(reservationID) {
+		sm.updateHeldConnectors()
+	}
+}
+
+// checkReservation verifies that an ID tag may use a reserved connector.
+// parentIDTag is the parent of the ID tag as reported by authorization (may be empty).
+func checkReservation(reservation *Reservation, idTag, parentIDTag string) error {
+	if reservation == nil || time.Now().After(reservation.ExpiryDate) {
+		return nil
+	}
+
+	if reservation.allows(idTag, parentIDTag) {
+		return nil
+	}
+
+	return fmt.Errorf("connector is reserved for another ID tag (reservation %d)", reservation.ID)
+}
+
+// allows reports whether an ID tag with the given parent (may be empty) may use the reservation
+func (r *Reservation) allows(idTag, parentIDTag string) bool {
+	if r.IDTag == idTag {
+		return true
+	}
+	return r.ParentIDTag != "" && (r.ParentIDTag == idTag || r.ParentIDTag == parentIDTag)
+}
+
+// ReserveEVSE handles an OCPP 2.0.1 ReserveNow. A reservation of an EVSE reserves its connector
+// like ReserveNow, with the group ID token in the role of the parent ID tag. Without EVSE, the
+// reservation is held by the station: it is accepted while more compatible connectors are free
+// than reservations wait for them, and the free connectors are shown Reserved once all of them
+// are needed.
+func (sm *SessionManager) ReserveEVSE(reservationID int, evseID *int, connectorType v201.ConnectorType, idToken, groupIDToken string, expiryDate time.Time) v16.ReservationStatus {
+	if evseID != nil {
+		connector, err := sm.GetConnector(*evseID)
+		if err != nil || !connectorMatches(connector, connectorType) {
+			return v16.ReservationStatusRejected
+		}
+
+		// A reservation with the same ID without EVSE is replaced
+		if sm.removeUnboundReservation(reservationID) {
+			defer sm.updateHeldConnectors()
+		}
+		return sm.ReserveNow(*evseID, reservationID, idToken, groupIDToken, expiryDate)
+	}
+
+	sm.logger.Info("Reserving connector without EVSE",
+		"stationId", sm.stationID,
+		"reservationId", reservationID,
+		"connectorType", connectorType,
+		"idToken", idToken,
+		"expiryDate", expiryDate,
+	)
+
+	if !expiryDate.After(time.Now()) {
+		return v16.ReservationStatusRejected
+	}
+
+	var compatible []*Connector
+	for _, connector := range sm.GetAllConnectors() {
+		if connectorMatches(connector, connectorType) {
+			compatible = append(compatible, connector)
+		}
+	}
+	if len(compatible) == 0 {
+		return v16.ReservationStatusRejected
+	}
+
+	// A reservation with the same ID is replaced
+	if existing := sm.findReservation(reservationID); existing != nil {
+		sm.removeReservation(existing, "Reservation moved to another connector")
+	}
+	sm.removeUnboundReservation(reservationID)
+
+	reservation := &Reservation{
+		ID:            reservationID,
+		IDTag:         idToken,
+		ExpiryDate:    expiryDate,
+		ParentIDTag:   groupIDToken,
+		ConnectorType: connectorType,
+	}
+
+	sm.mu.Lock()
+	accepted := countFreeConnectors(compatible, connectorType) > countWaitingReservations(sm.unboundReservations, connectorType)
+	if accepted {
+		sm.unboundReservations[reservationID] = reservation
+		sm.unboundTimers[reservationID] = time.AfterFunc(time.Until(expiryDate), func() {
+			sm.mu.Lock()
+			if sm.unboundReservations[reservationID] != reservation {
+				sm.mu.Unlock()
+				return
+			}
+			delete(sm.unboundReservations, reservationID)
+			delete(sm.unboundTimers, reservationID)
+			sm.mu.Unlock()
+
+			sm.logger.Info("Reservation expired", "stationId", sm.stationID, "reservationId", reservationID)
+			sm.updateHeldConnectors()
+			sm.reportReservationEnded(reservationID, v201.ReservationUpdateStatusExpired)
+		})
+	}
+	sm.mu.Unlock()
+
+	sm.updateHeldConnectors()
+
+	if !accepted {
+		return unboundReservationStatus(compatible)
+	}
+	return v16.ReservationStatusAccepted
+}
+
+// removeUnboundReservation removes a reservation without EVSE and stops its expiry timer.
+// Returns false if there is no such reservation.
+func (sm *SessionManager) removeUnboundReservation(reservationID int) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if timer, exists := sm.unboundTimers[reservationID]; exists {
+		timer.Stop()
+		delete(sm.unboundTimers, reservationID)
+	}
+
+	if _, exists := sm.unboundReservations[reservationID]; !exists {
+		return false
+	}
+	delete(sm.unboundReservations, reservationID)
+	return true
+}
+
+// claimUnboundReservation returns the reservation without EVSE an ID tag uses to charge on a
+// connector. A connector held for reservations can only be used by one of them.
+func (sm *SessionManager) claimUnboundReservation(connector *Connector, idTag, parentIDTag string) (*Reservation, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	for _, reservation := range sm.unboundReservations {
+		if connectorMatches(connector, reservation.ConnectorType) && reservation.allows(idTag, parentIDTag) {
+			claimed := *reservation
+			return &claimed, nil
+		}
+	}
+
+	if connector.GetState() == ConnectorStateReserved && len(sm.unboundReservations) > 0 {
+		return nil, fmt.Errorf("connector is held for reservations of other ID tokens")
+	}
+	return nil, nil
+}
+
+// updateHeldConnectors shows the free connectors that are needed by reservations without EVSE
+// as Reserved and makes the others Available again
+func (sm *SessionManager) updateHeldConnectors() {
+	sm.holdMu.Lock()
+	defer sm.holdMu.Unlock()
+
+	connectors := sm.GetAllConnectors()
+
+	sm.mu.RLock()
+	var exhausted []v201.ConnectorType
+	for _, reservation := range sm.unboundReservations {
+		if countFreeConnectors(connectors, reservation.ConnectorType) <= countWaitingReservations(sm.unboundReservations, reservation.ConnectorType) {
+			exhausted = append(exhausted, reservation.ConnectorType)
+		}
+	}
+	sm.mu.RUnlock()
+
+	for _, connector := range connectors {
+		if !isFreeConnector(connector) {
+			continue
+		}
+
+		held := false
+		for _, connectorType := range exhausted {
+			if connectorMatches(connector, connectorType) {
+				held = true
+				break
+			}
+		}
+
+		status := v16.ChargePointStatusAvailable
+		switch state := connector.GetState(); {
+		case held && state == ConnectorStateAvailable:
+			if err := connector.SetState(ConnectorStateReserved, v16.ChargePointErrorNoError, ""); err != nil {
+				continue
+			}
+			status = v16.ChargePointStatusReserved
+		case !held && state == ConnectorStateReserved:
+			if err := connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, ""); err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if sm.SendStatusNotification != nil {
+			sm.SendStatusNotification(connector.ID, status, v16.ChargePointErrorNoError, "")
+		}
+	}
+}
+
+// hasUnboundReservations reports whether reservations without EVSE are waiting
+func (sm *SessionManager) hasUnboundReservations() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return len(sm.unboundReservations) > 0
+}
+
+// removeFaultedReservation removes the reservation of a connector that became Faulted
+func (sm *SessionManager) removeFaultedReservation(connector *Connector) {
+	reservation := connector.GetReservation()
+	if reservation == nil {
+		return
+	}
+
+	sm.logger.Info("Reservation removed, connector faulted",
+		"stationId", sm.stationID,
+		"connectorId", connector.ID,
+		"reservationId", reservation.ID,
+	)
+
+	sm.stopReservationTimer(connector.ID)
+	connector.CancelReservation()
+	sm.reportReservationEnded(reservation.ID, v201.ReservationUpdateStatusRemoved)
+}
+
+// reportReservationEnded sends a ReservationStatusUpdate for OCPP 2.0.1/2.1 stations
+func (sm *SessionManager) reportReservationEnded(reservationID int, status v201.ReservationUpdateStatusType) {
+	if sm.SendReservationStatusUpdate == nil || !sm.usesTransactionEvents() {
+		return
+	}
+
+	if err := sm.SendReservationStatusUpdate(reservationID, status); err != nil {
+		sm.logger.Warn("Failed to send ReservationStatusUpdate",
+			"stationId", sm.stationID,
+			"reservationId", reservationID,
+			"error", err,
+		)
+	}
+}
+
+// connectorMatches reports whether a connector has the given OCPP 2.0.1 connector type,
+// an empty type matches any connector
+func connectorMatches(connector *Connector, connectorType v201.ConnectorType) bool {
+	return connectorType == "" || v201.ConnectorTypeFromName(connector.Type) == connectorType
+}
+
+// isFreeConnector reports whether a connector can be used by a reservation without EVSE: it is
+// Available or held Reserved for such reservations
+func isFreeConnector(connector *Connector) bool {
+	switch connector.GetState() {
+	case ConnectorStateAvailable:
+		return true
+	case ConnectorStateReserved:
+		return connector.GetReservation() == nil
+	}
+	return false
+}
+
+// countFreeConnectors counts the free connectors of a connector type
+func countFreeConnectors(connectors []*Connector, connectorType v201.ConnectorType) int {
+	count := 0
+	for _, connector := range connectors {
+		if isFreeConnector(connector) && connectorMatches(connector, connectorType) {
+			count++
+		}
+	}
+	return count
+}
+
+// countWaitingReservations counts the reservations without EVSE that compete for connectors of
+// a connector type
+func countWaitingReservations(reservations map[int]*Reservation, connectorType v201.ConnectorType) int {
+	count := 0
+	for _, reservation := range reservations {
+		if connectorType == "" || reservation.ConnectorType == "" || reservation.ConnectorType == connectorType {
+			count++
+		}
+	}
+	return count
+}
+
+// unboundReservationStatus returns why a reservation without EVSE cannot be accepted
+func unboundReservationStatus(connectors []*Connector) v16.ReservationStatus {
+	faulted := true
+	for _, connector := range connectors {
+		switch connector.GetState() {
+		case ConnectorStateFaulted:
+		case ConnectorStateUnavailable:
+			faulted = false
+		default:
+			return v16.ReservationStatusOccupied
+		}
+	}
+
+	if faulted {
+		return v16.ReservationStatusFaulted
+	}
+	return v16.ReservationStatusUnavailable
+}
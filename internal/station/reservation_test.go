@@ -0,0 +1,399 @@
+package station
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func TestSessionManager_ReserveNow(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+		{ID: 2, Type: "Type2", MaxPower: 22000, Status: "Faulted"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	defer sm.Shutdown(context.Background())
+
+	var statuses []v16.ChargePointStatus
+	var mu sync.Mutex
+	sm.SendStatusNotification = func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+		return nil
+	}
+
+	expiry := time.Now().Add(time.Hour)
+
+	if status := sm.ReserveNow(1, 10, "TAG001", "", expiry); status != v16.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+
+	connector, _ := sm.GetConnector(1)
+	if connector.GetState() != ConnectorStateReserved {
+		t.Errorf("Expected connector state Reserved, got %s", connector.GetState())
+	}
+
+	mu.Lock()
+	if len(statuses) != 1 || statuses[0] != v16.ChargePointStatusReserved {
+		t.Errorf("Expected Reserved StatusNotification, got %v", statuses)
+	}
+	mu.Unlock()
+
+	// Another reservation on the same connector
+	if status := sm.ReserveNow(1, 11, "TAG002", "", expiry); status != v16.ReservationStatusOccupied {
+		t.Errorf("Expected Occupied for reserved connector, got %s", status)
+	}
+
+	// Same reservation ID replaces the reservation
+	if status := sm.ReserveNow(1, 10, "TAG003", "", expiry); status != v16.ReservationStatusAccepted {
+		t.Errorf("Expected Accepted when replacing reservation, got %s", status)
+	}
+	if reservation := connector.GetReservation(); reservation == nil || reservation.IDTag != "TAG003" {
+		t.Errorf("Expected reservation to be replaced, got %+v", reservation)
+	}
+
+	if status := sm.ReserveNow(2, 12, "TAG001", "", expiry); status != v16.ReservationStatusFaulted {
+		t.Errorf("Expected Faulted, got %s", status)
+	}
+
+	if status := sm.ReserveNow(0, 13, "TAG001", "", expiry); status != v16.ReservationStatusRejected {
+		t.Errorf("Expected Rejected for connector 0, got %s", status)
+	}
+}
+
+func TestSessionManager_CancelReservation(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	defer sm.Shutdown(context.Background())
+
+	sm.ReserveNow(1, 10, "TAG001", "", time.Now().Add(time.Hour))
+
+	if err := sm.CancelReservation(99); err == nil {
+		t.Error("Expected error for unknown reservation")
+	}
+
+	if err := sm.CancelReservation(10); err != nil {
+		t.Fatalf("CancelReservation failed: %v", err)
+	}
+
+	connector, _ := sm.GetConnector(1)
+	if connector.IsReserved() {
+		t.Error("Expected reservation to be removed")
+	}
+	if connector.GetState() != ConnectorStateAvailable {
+		t.Errorf("Expected connector state Available, got %s", connector.GetState())
+	}
+}
+
+func TestSessionManager_ReservationExpiry(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	defer sm.Shutdown(context.Background())
+
+	available := make(chan struct{}, 1)
+	sm.SendStatusNotification = func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error {
+		if status == v16.ChargePointStatusAvailable {
+			available <- struct{}{}
+		}
+		return nil
+	}
+
+	if status := sm.ReserveNow(1, 10, "TAG001", "", time.Now().Add(50*time.Millisecond)); status != v16.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+
+	select {
+	case <-available:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for reservation expiry")
+	}
+
+	connector, _ := sm.GetConnector(1)
+	if connector.IsReserved() || connector.GetState() != ConnectorStateAvailable {
+		t.Errorf("Expected expired reservation to free connector, state %s", connector.GetState())
+	}
+}
+
+func TestSessionManager_StartCharging_Reserved(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	defer sm.Shutdown(context.Background())
+
+	sm.SendAuthorize = func(idTag string) (*v16.AuthorizeResponse, error) {
+		info := v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted}
+		if idTag == "CHILD" {
+			info.ParentIdTag = "FLEET"
+		}
+		return &v16.AuthorizeResponse{IdTagInfo: info}, nil
+	}
+
+	sm.ReserveNow(1, 10, "TAG001", "FLEET", time.Now().Add(time.Hour))
+
+	if _, err := sm.StartCharging(1, "OTHER"); err == nil {
+		t.Error("Expected StartCharging with foreign ID tag to fail on reserved connector")
+	}
+
+	connector, _ := sm.GetConnector(1)
+	if connector.GetState() != ConnectorStateReserved {
+		t.Errorf("Expected connector to stay Reserved, got %s", connector.GetState())
+	}
+
+	// A tag sharing the reservation's parent tag may use the connector
+	if _, err := sm.StartCharging(1, "CHILD"); err != nil {
+		t.Fatalf("Expected StartCharging with matching parent ID tag to succeed: %v", err)
+	}
+
+	if connector.IsReserved() {
+		t.Error("Expected reservation to be consumed by the transaction")
+	}
+	if !connector.HasActiveTransaction() {
+		t.Error("Expected active transaction")
+	}
+}
+
+func TestSessionManager_StartCharging_ReservedRejected(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	defer sm.Shutdown(context.Background())
+
+	var statuses []v16.ChargePointStatus
+	var mu sync.Mutex
+	sm.SendStatusNotification = func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+		return nil
+	}
+	sm.SendAuthorize = func(idTag string) (*v16.AuthorizeResponse, error) {
+		return &v16.AuthorizeResponse{IdTagInfo: v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted}}, nil
+	}
+	sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
+		return &v16.StartTransactionResponse{TransactionId: 1, IdTagInfo: v16.IdTagInfo{Status: v16.AuthorizationStatusInvalid}}, nil
+	}
+
+	sm.ReserveNow(1, 10, "TAG001", "", time.Now().Add(time.Hour))
+
+	if _, err := sm.StartCharging(1, "TAG001"); err == nil {
+		t.Fatal("Expected StartCharging to fail when the CSMS rejects the ID tag")
+	}
+
+	// The connector returns to its reservation
+	connector, _ := sm.GetConnector(1)
+	if connector.GetState() != ConnectorStateReserved || !connector.IsReserved() {
+		t.Errorf("Expected connector to be Reserved again, got %s", connector.GetState())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) == 0 || statuses[len(statuses)-1] != v16.ChargePointStatusReserved {
+		t.Errorf("Expected a Reserved StatusNotification, got %v", statuses)
+	}
+}
+
+func TestSessionManager_ReserveEVSE_WithoutEVSE(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+		{ID: 2, Type: "Type2", MaxPower: 22000, Status: "Available"},
+		{ID: 3, Type: "CCS", MaxPower: 50000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	defer sm.Shutdown(context.Background())
+
+	sm.SendAuthorize = func(idTag string) (*v16.AuthorizeResponse, error) {
+		info := v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted}
+		if idTag == "CHILD" {
+			info.ParentIdTag = "FLEET"
+		}
+		return &v16.AuthorizeResponse{IdTagInfo: info}, nil
+	}
+
+	states := func() []ConnectorState {
+		var states []ConnectorState
+		for id := 1; id <= 3; id++ {
+			connector, _ := sm.GetConnector(id)
+			states = append(states, connector.GetState())
+		}
+		return states
+	}
+
+	expiry := time.Now().Add(time.Hour)
+
+	// The first reservation leaves a Type2 connector for other drivers
+	if status := sm.ReserveEVSE(1, nil, v201.ConnectorTypeType2, "TAG1", "", expiry); status != v16.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+	if got := states(); got[0] != ConnectorStateAvailable || got[1] != ConnectorStateAvailable {
+		t.Errorf("Expected Type2 connectors to stay Available, got %v", got)
+	}
+
+	// The second one needs both, they are shown Reserved
+	if status := sm.ReserveEVSE(2, nil, v201.ConnectorTypeType2, "TAG2", "FLEET", expiry); status != v16.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+	if got := states(); got[0] != ConnectorStateReserved || got[1] != ConnectorStateReserved || got[2] != ConnectorStateAvailable {
+		t.Errorf("Expected Type2 connectors Reserved and CCS Available, got %v", got)
+	}
+
+	if status := sm.ReserveEVSE(3, nil, v201.ConnectorTypeType2, "TAG3", "", expiry); status != v16.ReservationStatusOccupied {
+		t.Errorf("Expected Occupied without free Type2 connector, got %s", status)
+	}
+	if status := sm.ReserveEVSE(4, nil, v201.ConnectorTypeTesla, "TAG4", "", expiry); status != v16.ReservationStatusRejected {
+		t.Errorf("Expected Rejected for a missing connector type, got %s", status)
+	}
+	evseID := 3
+	if status := sm.ReserveEVSE(5, &evseID, v201.ConnectorTypeType2, "TAG5", "", expiry); status != v16.ReservationStatusRejected {
+		t.Errorf("Expected Rejected for an EVSE without the connector type, got %s", status)
+	}
+
+	if _, err := sm.StartCharging(1, "OTHER"); err == nil {
+		t.Error("Expected StartCharging with foreign ID tag to fail on a held connector")
+	}
+	if _, err := sm.StartCharging(3, "OTHER"); err != nil {
+		t.Errorf("Expected StartCharging on the CCS connector to succeed: %v", err)
+	}
+
+	// A token of the reservation's group uses it, the other Type2 connector stays held
+	if _, err := sm.StartCharging(1, "CHILD"); err != nil {
+		t.Fatalf("Expected StartCharging with matching group ID token to succeed: %v", err)
+	}
+	if got := states(); got[1] != ConnectorStateReserved {
+		t.Errorf("Expected connector 2 to stay Reserved for reservation 1, got %v", got)
+	}
+	if err := sm.CancelReservation(2); err == nil {
+		t.Error("Expected used reservation to be removed")
+	}
+
+	if err := sm.CancelReservation(1); err != nil {
+		t.Fatalf("CancelReservation failed: %v", err)
+	}
+	if got := states(); got[1] != ConnectorStateAvailable {
+		t.Errorf("Expected connector 2 to be Available after cancelling, got %v", got)
+	}
+}
+
+func TestSessionManager_ReservationStatusUpdate(t *testing.T) {
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	type update struct {
+		reservationID int
+		status        v201.ReservationUpdateStatusType
+	}
+	updates := make(chan update, 2)
+	sm.SendReservationStatusUpdate = func(reservationID int, status v201.ReservationUpdateStatusType) error {
+		updates <- update{reservationID, status}
+		return nil
+	}
+
+	waitUpdate := func(expected update) {
+		t.Helper()
+		select {
+		case got := <-updates:
+			if got != expected {
+				t.Errorf("Expected %+v, got %+v", expected, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for ReservationStatusUpdate %+v", expected)
+		}
+	}
+
+	connector, _ := sm.GetConnector(1)
+
+	if status := sm.ReserveEVSE(5, nil, "", "TAG1", "", time.Now().Add(50*time.Millisecond)); status != v16.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+	if connector.GetState() != ConnectorStateReserved {
+		t.Errorf("Expected the only connector to be held Reserved, got %s", connector.GetState())
+	}
+
+	waitUpdate(update{5, v201.ReservationUpdateStatusExpired})
+	if connector.GetState() != ConnectorStateAvailable {
+		t.Errorf("Expected connector Available after expiry, got %s", connector.GetState())
+	}
+
+	evseID := 1
+	if status := sm.ReserveEVSE(6, &evseID, v201.ConnectorTypeType2, "TAG1", "", time.Now().Add(time.Hour)); status != v16.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+	if err := connector.SetState(ConnectorStateFaulted, v16.ChargePointErrorGroundFailure, ""); err != nil {
+		t.Fatalf("SetState failed: %v", err)
+	}
+
+	waitUpdate(update{6, v201.ReservationUpdateStatusRemoved})
+}
+
+func TestHandleV201ReserveNow(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	deviceModel := v201.NewDeviceModel()
+	station := &Station{
+		Config:         Config{StationID: "TEST017", ProtocolVersion: "ocpp2.0.1"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: sm,
+		DeviceModel:    deviceModel,
+	}
+	manager.mu.Lock()
+	manager.stations["TEST017"] = station
+	manager.mu.Unlock()
+
+	deviceModel.SetVariable("ReservationCtrlr", "", "NonEvseSpecific", "", v201.AttributeActual, "false")
+
+	resp, _ := manager.v201Handler.OnReserveNow("TEST017", &v201.ReserveNowRequest{
+		Id:             1,
+		ExpiryDateTime: v201.DateTime{Time: time.Now().Add(time.Hour)},
+		IdToken:        v201.IdToken{IdToken: "TAG1", Type: "ISO14443"},
+	})
+	if resp.Status != v201.ReserveNowStatusRejected || resp.StatusInfo == nil {
+		t.Errorf("Expected Rejected without EVSE when NonEvseSpecific is false, got %+v", resp)
+	}
+
+	// OCPP 2.1 reservations use the same engine
+	evseID := 1
+	connectorType := v21.ConnectorTypeType2
+	resp21, _ := manager.v21Handler.OnReserveNow("TEST017", &v21.ReserveNowRequest{
+		Id:             2,
+		ExpiryDateTime: time.Now().Add(time.Hour).Format(time.RFC3339),
+		ConnectorType:  &connectorType,
+		EvseId:         &evseID,
+		IdToken:        v201.IdToken{IdToken: "TAG1", Type: "ISO14443"},
+	})
+	if resp21.Status != v21.ReservationStatusAccepted {
+		t.Fatalf("Expected Accepted, got %+v", resp21)
+	}
+
+	connector, _ := sm.GetConnector(1)
+	if reservation := connector.GetReservation(); reservation == nil || reservation.ID != 2 {
+		t.Errorf("Expected reservation 2 on connector 1, got %+v", reservation)
+	}
+
+	cancel, _ := manager.v201Handler.OnCancelReservation("TEST017", &v201.CancelReservationRequest{ReservationId: 2})
+	if cancel.Status != v201.CancelReservationStatusAccepted || connector.IsReserved() {
+		t.Errorf("Expected reservation 2 to be cancelled, got %+v", cancel)
+	}
+}
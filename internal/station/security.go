@@ -0,0 +1,468 @@
+package station
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+// Security related configuration keys (OCPP 1.6 security extensions)
+const (
+	ConfigKeySecurityProfile                = "SecurityProfile"
+	ConfigKeyAuthorizationKey               = "AuthorizationKey"
+	ConfigKeyCpoName                        = "CpoName"
+	ConfigKeyCertificateSignedMaxChainSize  = "CertificateSignedMaxChainSize"
+	ConfigKeyCertificateStoreMaxLength      = "CertificateStoreMaxLength"
+	ConfigKeyAdditionalRootCertificateCheck = "AdditionalRootCertificateCheck"
+)
+
+// Security profiles of the CSMS connection
+const (
+	SecurityProfileNone          = 0 // Connection settings of the station are used as configured
+	SecurityProfileBasicAuth     = 1 // HTTP Basic Authentication without TLS
+	SecurityProfileTLSBasicAuth  = 2 // TLS with HTTP Basic Authentication
+	SecurityProfileTLSClientCert = 3 // TLS with client side certificates
+)
+
+// Default limits of the certificate handling
+const (
+	defaultCertificateSignedMaxChainSize = 10000
+	defaultCertificateStoreMaxLength     = 20
+	maxSecurityEvents                    = 1000
+)
+
+// SecurityEvent is an entry of the security log
+type SecurityEvent struct {
+	Type      string
+	Timestamp time.Time
+	TechInfo  string
+}
+
+// SecurityManager implements the OCPP 1.6 security extensions of a station on top of its certificate store
+type SecurityManager struct {
+	stationID string
+	store     *v201.CertificateStore
+	logger    *slog.Logger
+
+	mu                            sync.RWMutex
+	profile                       int
+	authorizationKey              string
+	cpoName                       string
+	certificateSignedMaxChainSize int
+	certificateStoreMaxLength     int
+	events                        []SecurityEvent
+	unsent                        []SecurityEvent // Events that could not be reported yet
+
+	// Callbacks
+	SendSecurityEvent func(event SecurityEvent) error
+}
+
+// NewSecurityManager creates a security manager using the certificate store of the station
+func NewSecurityManager(stationID string, profile int, store *v201.CertificateStore, logger *slog.Logger) *SecurityManager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SecurityManager{
+		stationID:                     stationID,
+		store:                         store,
+		logger:                        logger,
+		profile:                       profile,
+		certificateSignedMaxChainSize: defaultCertificateSignedMaxChainSize,
+		certificateStoreMaxLength:     defaultCertificateStoreMaxLength,
+	}
+}
+
+// Profile returns the active security profile
+func (sec *SecurityManager) Profile() int {
+	sec.mu.RLock()
+	defer sec.mu.RUnlock()
+	return sec.profile
+}
+
+// SetProfile sets the security profile without validation (e.g. from the station configuration)
+func (sec *SecurityManager) SetProfile(profile int) {
+	sec.mu.Lock()
+	defer sec.mu.Unlock()
+	sec.profile = profile
+}
+
+// AuthorizationKey returns the Basic Authentication password set by the CSMS, empty if unset
+func (sec *SecurityManager) AuthorizationKey() string {
+	sec.mu.RLock()
+	defer sec.mu.RUnlock()
+	return sec.authorizationKey
+}
+
+// ConfigurationKeys returns the security configuration as OCPP configuration keys.
+// AuthorizationKey is write-only and reported without its value.
+func (sec *SecurityManager) ConfigurationKeys() []v16.KeyValue {
+	sec.mu.RLock()
+	defer sec.mu.RUnlock()
+
+	keyValue := func(key, value string, readonly bool) v16.KeyValue {
+		return v16.KeyValue{Key: key, Readonly: readonly, Value: value}
+	}
+
+	return []v16.KeyValue{
+		keyValue(ConfigKeySecurityProfile, strconv.Itoa(sec.profile), false),
+		keyValue(ConfigKeyAuthorizationKey, "", false),
+		keyValue(ConfigKeyCpoName, sec.cpoName, false),
+		keyValue(ConfigKeyCertificateSignedMaxChainSize, strconv.Itoa(sec.certificateSignedMaxChainSize), true),
+		keyValue(ConfigKeyCertificateStoreMaxLength, strconv.Itoa(sec.certificateStoreMaxLength), true),
+		keyValue(ConfigKeyAdditionalRootCertificateCheck, "false", true),
+	}
+}
+
+// ChangeConfiguration applies a security configuration key.
+// Returns false if the key is not a security key.
+func (sec *SecurityManager) ChangeConfiguration(key, value string) (bool, error) {
+	switch key {
+	case ConfigKeySecurityProfile:
+		profile, err := strconv.Atoi(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+		if err := sec.validateProfileChange(profile); err != nil {
+			return true, err
+		}
+		sec.SetProfile(profile)
+		return true, nil
+
+	case ConfigKeyAuthorizationKey:
+		if len(value) < 16 || len(value) > 40 {
+			return true, fmt.Errorf("%s must be 16 to 40 characters", key)
+		}
+		sec.mu.Lock()
+		sec.authorizationKey = value
+		sec.mu.Unlock()
+		return true, nil
+
+	case ConfigKeyCpoName:
+		sec.mu.Lock()
+		sec.cpoName = value
+		sec.mu.Unlock()
+		return true, nil
+
+	case ConfigKeyCertificateSignedMaxChainSize, ConfigKeyCertificateStoreMaxLength, ConfigKeyAdditionalRootCertificateCheck:
+		return true, fmt.Errorf("configuration key %s is read-only", key)
+
+	default:
+		return false, nil
+	}
+}
+
+// validateProfileChange checks that the station can connect with the requested security profile.
+// The security profile can only be increased.
+func (sec *SecurityManager) validateProfileChange(profile int) error {
+	current := sec.Profile()
+
+	if profile < SecurityProfileBasicAuth || profile > SecurityProfileTLSClientCert {
+		return fmt.Errorf("unknown security profile: %d", profile)
+	}
+	if profile < current {
+		return fmt.Errorf("security profile cannot be lowered from %d to %d", current, profile)
+	}
+
+	if profile >= SecurityProfileTLSBasicAuth && sec.store.GetCertificate(v201.CertificateUseCSMSRootCertificate) == nil {
+		return fmt.Errorf("security profile %d requires a CentralSystemRootCertificate", profile)
+	}
+	if profile == SecurityProfileTLSClientCert {
+		cert := sec.store.GetCertificate(v201.CertificateUseChargingStationCertificate)
+		if cert == nil || cert.PrivateKey == nil {
+			return fmt.Errorf("security profile %d requires a charge point certificate", profile)
+		}
+	}
+
+	return nil
+}
+
+// AddSecurityEvent records a security event in the security log and reports it to the CSMS.
+// Events that cannot be reported are kept until SendUnsentEvents is called.
+func (sec *SecurityManager) AddSecurityEvent(eventType, techInfo string) {
+	event := SecurityEvent{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		TechInfo:  techInfo,
+	}
+
+	sec.mu.Lock()
+	sec.events = append(sec.events, event)
+	if len(sec.events) > maxSecurityEvents {
+		sec.events = sec.events[len(sec.events)-maxSecurityEvents:]
+	}
+	sec.mu.Unlock()
+
+	sec.logger.Info("Security event", "stationId", sec.stationID, "type", eventType, "techInfo", techInfo)
+
+	if sec.SendSecurityEvent == nil || sec.SendSecurityEvent(event) != nil {
+		sec.mu.Lock()
+		sec.unsent = append(sec.unsent, event)
+		if len(sec.unsent) > maxSecurityEvents {
+			sec.unsent = sec.unsent[len(sec.unsent)-maxSecurityEvents:]
+		}
+		sec.mu.Unlock()
+	}
+}
+
+// SendUnsentEvents reports the security events recorded while the station could not reach the CSMS
+func (sec *SecurityManager) SendUnsentEvents() {
+	if sec.SendSecurityEvent == nil {
+		return
+	}
+
+	sec.mu.Lock()
+	unsent := sec.unsent
+	sec.unsent = nil
+	sec.mu.Unlock()
+
+	for i, event := range unsent {
+		if err := sec.SendSecurityEvent(event); err != nil {
+			sec.logger.Warn("Failed to report security event", "stationId", sec.stationID, "type", event.Type, "error", err)
+
+			sec.mu.Lock()
+			sec.unsent = append(unsent[i:], sec.unsent...)
+			sec.mu.Unlock()
+			return
+		}
+	}
+}
+
+// SecurityLog returns the recorded security events, oldest first
+func (sec *SecurityManager) SecurityLog() []SecurityEvent {
+	sec.mu.RLock()
+	defer sec.mu.RUnlock()
+
+	events := make([]SecurityEvent, len(sec.events))
+	copy(events, sec.events)
+	return events
+}
+
+// GenerateCSR creates a certificate signing request for the charge point certificate
+func (sec *SecurityManager) GenerateCSR() (string, error) {
+	encoded, err := sec.store.GenerateCSR(v201.CertificateUseChargingStationCertificate)
+	if err != nil {
+		return "", err
+	}
+
+	// The certificate store encodes the PEM for OCPP 2.0.1; OCPP 1.6 sends the PEM itself
+	csrPEM, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode CSR: %w", err)
+	}
+
+	return string(csrPEM), nil
+}
+
+// CertificateSigned installs the charge point certificate signed by the CSMS
+func (sec *SecurityManager) CertificateSigned(certificateChain string) v16.CertificateSignedStatus {
+	sec.mu.RLock()
+	maxChainSize := sec.certificateSignedMaxChainSize
+	sec.mu.RUnlock()
+
+	if len(certificateChain) > maxChainSize {
+		sec.logger.Warn("Certificate chain exceeds maximum size", "stationId", sec.stationID, "size", len(certificateChain))
+		return v16.CertificateSignedStatusRejected
+	}
+
+	if _, err := sec.store.InstallSignedCertificate(v201.CertificateUseChargingStationCertificate, certificateChain); err != nil {
+		sec.logger.Warn("Signed certificate rejected", "stationId", sec.stationID, "error", err)
+		sec.AddSecurityEvent(v16.SecurityEventInvalidChargePointCertificate, err.Error())
+		return v16.CertificateSignedStatusRejected
+	}
+
+	return v16.CertificateSignedStatusAccepted
+}
+
+// InstallCertificate installs a root certificate
+func (sec *SecurityManager) InstallCertificate(certType v16.CertificateUse, certificate string) v16.CertificateStatus {
+	storeType, ok := rootCertificateUse(certType)
+	if !ok {
+		return v16.CertificateStatusRejected
+	}
+
+	sec.mu.RLock()
+	maxLength := sec.certificateStoreMaxLength
+	sec.mu.RUnlock()
+
+	if sec.store.GetCertificateCount() >= maxLength {
+		return v16.CertificateStatusFailed
+	}
+
+	if _, err := sec.store.InstallCertificate(storeType, certificate); err != nil {
+		sec.logger.Warn("Certificate rejected", "stationId", sec.stationID, "certType", certType, "error", err)
+		if certType == v16.CertificateUseCentralSystemRootCertificate {
+			sec.AddSecurityEvent(v16.SecurityEventInvalidCentralSystemCertificate, err.Error())
+		}
+		return v16.CertificateStatusRejected
+	}
+
+	return v16.CertificateStatusAccepted
+}
+
+// DeleteCertificate removes a root certificate.
+// The last CentralSystemRootCertificate cannot be removed while TLS is used.
+func (sec *SecurityManager) DeleteCertificate(hashData v16.CertificateHashData) v16.DeleteCertificateStatus {
+	storeHash := v201.CertificateHashDataType{
+		HashAlgorithm:  string(hashData.HashAlgorithm),
+		IssuerNameHash: hashData.IssuerNameHash,
+		IssuerKeyHash:  hashData.IssuerKeyHash,
+		SerialNumber:   hashData.SerialNumber,
+	}
+
+	stored := sec.store.GetCertificateByHash(storeHash)
+	if stored == nil {
+		return v16.DeleteCertificateStatusNotFound
+	}
+
+	switch stored.CertificateType {
+	case v201.CertificateUseCSMSRootCertificate:
+		if sec.Profile() >= SecurityProfileTLSBasicAuth && sec.countCertificates(v201.CertificateUseCSMSRootCertificate) == 1 {
+			return v16.DeleteCertificateStatusFailed
+		}
+	case v201.CertificateUseManufacturerRootCertificate:
+	default:
+		// Only root certificates can be managed by the CSMS
+		return v16.DeleteCertificateStatusFailed
+	}
+
+	if sec.store.DeleteCertificate(storeHash) != v201.DeleteCertificateStatusAccepted {
+		return v16.DeleteCertificateStatusFailed
+	}
+
+	return v16.DeleteCertificateStatusAccepted
+}
+
+// GetInstalledCertificateIds returns the hash data of the installed root certificates of a type
+func (sec *SecurityManager) GetInstalledCertificateIds(certType v16.CertificateUse) (v16.GetInstalledCertificateStatus, []v16.CertificateHashData) {
+	storeType, ok := rootCertificateUse(certType)
+	if !ok {
+		return v16.GetInstalledCertificateStatusNotFound, nil
+	}
+
+	status, chains := sec.store.GetInstalledCertificateIds([]string{string(storeType)})
+	if status != v201.GetInstalledCertificateStatusAccepted {
+		return v16.GetInstalledCertificateStatusNotFound, nil
+	}
+
+	hashData := make([]v16.CertificateHashData, 0, len(chains))
+	for _, chain := range chains {
+		hashData = append(hashData, v16.CertificateHashData{
+			HashAlgorithm:  v16.HashAlgorithm(chain.CertificateHashData.HashAlgorithm),
+			IssuerNameHash: chain.CertificateHashData.IssuerNameHash,
+			IssuerKeyHash:  chain.CertificateHashData.IssuerKeyHash,
+			SerialNumber:   chain.CertificateHashData.SerialNumber,
+		})
+	}
+
+	return v16.GetInstalledCertificateStatusAccepted, hashData
+}
+
+// VerifyFirmwareSigningCertificate checks that a firmware signing certificate was issued
+// by an installed ManufacturerRootCertificate
+func (sec *SecurityManager) VerifyFirmwareSigningCertificate(certificate string) error {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return fmt.Errorf("failed to decode PEM certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	roots := sec.certPool(v201.CertificateUseManufacturerRootCertificate)
+	if roots == nil {
+		return fmt.Errorf("no ManufacturerRootCertificate installed")
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// RootCAs returns the installed CentralSystemRootCertificates used to verify the CSMS, nil if there are none
+func (sec *SecurityManager) RootCAs() *x509.CertPool {
+	return sec.certPool(v201.CertificateUseCSMSRootCertificate)
+}
+
+// ClientCertificate returns the charge point certificate with its private key for TLS client authentication
+func (sec *SecurityManager) ClientCertificate() (*tls.Certificate, error) {
+	stored := sec.store.GetCertificate(v201.CertificateUseChargingStationCertificate)
+	if stored == nil || stored.PrivateKey == nil {
+		return nil, fmt.Errorf("no charge point certificate installed")
+	}
+
+	cert := &tls.Certificate{
+		PrivateKey: stored.PrivateKey,
+		Leaf:       stored.Certificate,
+	}
+
+	// The stored PEM holds the full chain, leaf first
+	remaining := []byte(stored.PEM)
+	for len(remaining) > 0 {
+		block, rest := pem.Decode(remaining)
+		if block == nil {
+			break
+		}
+		remaining = rest
+		if block.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+		}
+	}
+
+	if len(cert.Certificate) == 0 {
+		cert.Certificate = [][]byte{stored.Certificate.Raw}
+	}
+
+	return cert, nil
+}
+
+// certPool returns a pool of the installed certificates of a type, nil if there are none
+func (sec *SecurityManager) certPool(certType v201.CertificateUseType) *x509.CertPool {
+	var pool *x509.CertPool
+	for _, stored := range sec.store.GetAllCertificates() {
+		if stored.CertificateType != certType {
+			continue
+		}
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AddCert(stored.Certificate)
+	}
+	return pool
+}
+
+// countCertificates returns the number of installed certificates of a type
+func (sec *SecurityManager) countCertificates(certType v201.CertificateUseType) int {
+	count := 0
+	for _, stored := range sec.store.GetAllCertificates() {
+		if stored.CertificateType == certType {
+			count++
+		}
+	}
+	return count
+}
+
+// rootCertificateUse maps an OCPP 1.6 root certificate type to the certificate store type
+func rootCertificateUse(certType v16.CertificateUse) (v201.CertificateUseType, bool) {
+	switch certType {
+	case v16.CertificateUseCentralSystemRootCertificate:
+		return v201.CertificateUseCSMSRootCertificate, true
+	case v16.CertificateUseManufacturerRootCertificate:
+		return v201.CertificateUseManufacturerRootCertificate, true
+	default:
+		return "", false
+	}
+}
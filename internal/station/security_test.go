@@ -0,0 +1,268 @@
+package station
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+// testCA is a certificate authority for signing test certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  string
+}
+
+func newTestCA(t *testing.T, serial int64) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// sign issues a leaf certificate for the public key of a PEM encoded CSR
+func (ca *testCA) sign(t *testing.T, csrPEM string, serial int64) string {
+	t.Helper()
+
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil {
+		t.Fatal("Failed to decode CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse CSR: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to sign certificate: %v", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func newTestSecurityManager() *SecurityManager {
+	return NewSecurityManager("CP001", SecurityProfileNone, v201.NewCertificateStore("CP001", "Test", "US"), nil)
+}
+
+func TestSecurityManager_RootCertificates(t *testing.T) {
+	sec := newTestSecurityManager()
+	ca := newTestCA(t, 1001)
+
+	if status := sec.InstallCertificate(v16.CertificateUseCentralSystemRootCertificate, ca.pem); status != v16.CertificateStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+	if status := sec.InstallCertificate(v16.CertificateUseCentralSystemRootCertificate, "invalid"); status != v16.CertificateStatusRejected {
+		t.Errorf("Expected Rejected for invalid certificate, got %s", status)
+	}
+
+	status, hashData := sec.GetInstalledCertificateIds(v16.CertificateUseCentralSystemRootCertificate)
+	if status != v16.GetInstalledCertificateStatusAccepted || len(hashData) != 1 {
+		t.Fatalf("Expected one installed certificate, got %s %v", status, hashData)
+	}
+	if status, _ := sec.GetInstalledCertificateIds(v16.CertificateUseManufacturerRootCertificate); status != v16.GetInstalledCertificateStatusNotFound {
+		t.Errorf("Expected NotFound for manufacturer roots, got %s", status)
+	}
+	if sec.RootCAs() == nil {
+		t.Error("Expected root CAs for the TLS connection")
+	}
+
+	// The last CSMS root cannot be removed while TLS is used
+	sec.SetProfile(SecurityProfileTLSBasicAuth)
+	if status := sec.DeleteCertificate(hashData[0]); status != v16.DeleteCertificateStatusFailed {
+		t.Errorf("Expected Failed while security profile 2 is active, got %s", status)
+	}
+
+	sec.SetProfile(SecurityProfileBasicAuth)
+	if status := sec.DeleteCertificate(hashData[0]); status != v16.DeleteCertificateStatusAccepted {
+		t.Errorf("Expected Accepted, got %s", status)
+	}
+	if status := sec.DeleteCertificate(hashData[0]); status != v16.DeleteCertificateStatusNotFound {
+		t.Errorf("Expected NotFound after deletion, got %s", status)
+	}
+}
+
+func TestSecurityManager_CertificateSigned(t *testing.T) {
+	sec := newTestSecurityManager()
+	ca := newTestCA(t, 2001)
+
+	var events []string
+	sec.SendSecurityEvent = func(event SecurityEvent) error {
+		events = append(events, event.Type)
+		return nil
+	}
+
+	// Without a pending CSR the certificate is rejected
+	if status := sec.CertificateSigned(ca.pem); status != v16.CertificateSignedStatusRejected {
+		t.Errorf("Expected Rejected without CSR, got %s", status)
+	}
+	if len(events) != 1 || events[0] != v16.SecurityEventInvalidChargePointCertificate {
+		t.Errorf("Expected InvalidChargePointCertificate event, got %v", events)
+	}
+
+	csr, err := sec.GenerateCSR()
+	if err != nil {
+		t.Fatalf("GenerateCSR failed: %v", err)
+	}
+
+	chain := ca.sign(t, csr, 2002) + ca.pem
+	if status := sec.CertificateSigned(chain); status != v16.CertificateSignedStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s", status)
+	}
+
+	cert, err := sec.ClientCertificate()
+	if err != nil {
+		t.Fatalf("ClientCertificate failed: %v", err)
+	}
+	if len(cert.Certificate) != 2 {
+		t.Errorf("Expected leaf and CA in the client certificate chain, got %d", len(cert.Certificate))
+	}
+}
+
+func TestSecurityManager_ChangeSecurityProfile(t *testing.T) {
+	sec := newTestSecurityManager()
+
+	if handled, err := sec.ChangeConfiguration(ConfigKeySecurityProfile, "1"); !handled || err != nil {
+		t.Fatalf("Expected profile 1 to be accepted, got handled=%v err=%v", handled, err)
+	}
+
+	// Profile 2 requires a CSMS root certificate
+	if _, err := sec.ChangeConfiguration(ConfigKeySecurityProfile, "2"); err == nil {
+		t.Error("Expected profile 2 to be rejected without CentralSystemRootCertificate")
+	}
+
+	ca := newTestCA(t, 3001)
+	sec.InstallCertificate(v16.CertificateUseCentralSystemRootCertificate, ca.pem)
+
+	if _, err := sec.ChangeConfiguration(ConfigKeySecurityProfile, "2"); err != nil {
+		t.Errorf("Expected profile 2 to be accepted, got %v", err)
+	}
+
+	// Profile 3 requires a charge point certificate
+	if _, err := sec.ChangeConfiguration(ConfigKeySecurityProfile, "3"); err == nil {
+		t.Error("Expected profile 3 to be rejected without charge point certificate")
+	}
+
+	// The profile cannot be lowered
+	if _, err := sec.ChangeConfiguration(ConfigKeySecurityProfile, "1"); err == nil {
+		t.Error("Expected lowering the security profile to be rejected")
+	}
+	if sec.Profile() != SecurityProfileTLSBasicAuth {
+		t.Errorf("Expected security profile 2, got %d", sec.Profile())
+	}
+
+	if _, err := sec.ChangeConfiguration(ConfigKeyAuthorizationKey, "short"); err == nil {
+		t.Error("Expected short AuthorizationKey to be rejected")
+	}
+	if _, err := sec.ChangeConfiguration(ConfigKeyAuthorizationKey, "0123456789abcdef0123"); err != nil {
+		t.Errorf("Expected AuthorizationKey to be accepted, got %v", err)
+	}
+	for _, kv := range sec.ConfigurationKeys() {
+		if kv.Key == ConfigKeyAuthorizationKey && kv.Value != "" {
+			t.Error("AuthorizationKey must not be reported")
+		}
+	}
+
+	if handled, _ := sec.ChangeConfiguration("HeartbeatInterval", "60"); handled {
+		t.Error("Expected non-security key not to be handled")
+	}
+}
+
+func TestSecurityManager_VerifyFirmwareSigningCertificate(t *testing.T) {
+	sec := newTestSecurityManager()
+	ca := newTestCA(t, 4001)
+
+	other := newTestSecurityManager()
+	csr, err := other.GenerateCSR()
+	if err != nil {
+		t.Fatalf("GenerateCSR failed: %v", err)
+	}
+	signingCert := ca.sign(t, csr, 4002)
+
+	if err := sec.VerifyFirmwareSigningCertificate(signingCert); err == nil {
+		t.Error("Expected verification to fail without ManufacturerRootCertificate")
+	}
+
+	sec.InstallCertificate(v16.CertificateUseManufacturerRootCertificate, ca.pem)
+
+	if err := sec.VerifyFirmwareSigningCertificate(signingCert); err != nil {
+		t.Errorf("Expected signing certificate to be valid, got %v", err)
+	}
+	if err := sec.VerifyFirmwareSigningCertificate(newTestCA(t, 4003).pem); err == nil {
+		t.Error("Expected certificate of another issuer to be rejected")
+	}
+}
+
+func TestSecurityManager_UnsentEvents(t *testing.T) {
+	sec := newTestSecurityManager()
+
+	online := false
+	var sent []string
+	sec.SendSecurityEvent = func(event SecurityEvent) error {
+		if !online {
+			return fmt.Errorf("station not connected")
+		}
+		sent = append(sent, event.Type)
+		return nil
+	}
+
+	sec.AddSecurityEvent(v16.SecurityEventResetOrReboot, "reset")
+	sec.AddSecurityEvent(v16.SecurityEventFirmwareUpdated, "1.2.3")
+
+	if len(sent) != 0 {
+		t.Fatalf("Expected no events to be sent while offline, got %v", sent)
+	}
+
+	online = true
+	sec.SendUnsentEvents()
+
+	if len(sent) != 2 || sent[0] != v16.SecurityEventResetOrReboot || sent[1] != v16.SecurityEventFirmwareUpdated {
+		t.Errorf("Expected queued events in order, got %v", sent)
+	}
+	if len(sec.SecurityLog()) != 2 {
+		t.Errorf("Expected 2 events in the security log, got %d", len(sec.SecurityLog()))
+	}
+}
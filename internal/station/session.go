@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math/rand"
+	"strconv"
 	"sync"
 	"time"
 
 	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
 	"github.com/ruslanhut/ocpp-emu/internal/storage"
 )
 
@@ -30,9 +32,73 @@ type SessionManager struct {
 	SendStatusNotification func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error
 	SendMeterValues        func(connectorID int, transactionID *int, meterValues []v16.MeterValue) error
 
+	// SendTransactionEvent reports the transactions of OCPP 2.0.1/2.1 stations, replacing
+	// StartTransaction, StopTransaction and MeterValues
+	SendTransactionEvent func(req *v201.TransactionEventRequest) error
+
+	// SendAuthorizeIdToken authorizes the typed idTokens of OCPP 2.0.1/2.1 stations with the CSMS
+	SendAuthorizeIdToken func(idToken v201.IdToken) (*v201.AuthorizeResponse, error)
+
+	// Plug & Charge: SendAuthorizeContract authorizes the eMAID of a contract certificate with its
+	// certificate hash data, SendGet15118EVCertificate installs and updates contract certificates
+	// and SendGetCertificateStatus obtains their OCSP status
+	SendAuthorizeContract     func(idToken v201.IdToken, hashData []v201.OCSPRequestData) (*v201.AuthorizeResponse, error)
+	SendGet15118EVCertificate func(req *v201.Get15118EVCertificateRequest) (*v201.Get15118EVCertificateResponse, error)
+	SendGetCertificateStatus  func(req *v201.GetCertificateStatusRequest) (*v201.GetCertificateStatusResponse, error)
+
+	// SendReservationStatusUpdate reports to OCPP 2.0.1/2.1 stations that a reservation
+	// expired or was removed without being used
+	SendReservationStatusUpdate func(reservationID int, status v201.ReservationUpdateStatusType) error
+
+	// SendNotifyEVChargingNeeds advertises the charging needs of an ISO 15118 or V2X EV
+	SendNotifyEVChargingNeeds func(req *v21.NotifyEVChargingNeedsRequest) error
+
+	// SendNotifyEVChargingSchedule reports the charging schedule an ISO 15118 EV selected
+	SendNotifyEVChargingSchedule func(req *v21.NotifyEVChargingScheduleRequest) error
+
+	// UpdateEVSEVariable reports a simulated EVSE value (AvailabilityState, Power, Temperature)
+	// to the device model, where it is evaluated by the variable monitors
+	UpdateEVSEVariable func(connectorID int, variable, value string)
+
 	// Meter value simulation
-	meterValueTickers map[int]*time.Ticker
-	stopChans         map[int]chan struct{}
+	meterValueTickers   map[int]*time.Ticker
+	stopChans           map[int]chan struct{}
+	meterSampleInterval int             // Seconds (MeterValueSampleInterval), 0 disables periodic meter values
+	sampledData         []v16.Measurand // Measurands of periodic meter values (MeterValuesSampledData)
+
+	// Smart charging profiles limiting the simulated power
+	chargingProfiles *ChargingProfileManager
+
+	// Simulated EV of new transactions
+	ev EVSimulationConfig
+
+	// ISO 15118 EVs negotiating their charging schedules (connector ID -> session)
+	evSessions map[int]*evChargingSession
+
+	// OCPP 2.1 tariffs and the locally calculated cost of transactions
+	tariffs *TariffManager
+
+	// ISO 15118 Plug & Charge EVs of the connectors
+	plugAndCharge *PlugAndCharge
+
+	// Reservation expiry timers (connector ID -> timer)
+	reservationTimers map[int]*time.Timer
+
+	// OCPP 2.0.1 reservations without EVSE and their expiry timers (reservation ID -> ...),
+	// holdMu serializes the updates of the connectors held for them
+	unboundReservations map[int]*Reservation
+	unboundTimers       map[int]*time.Timer
+	holdMu              sync.Mutex
+
+	// Local Authorization List and authorization cache
+	authorization *LocalAuthorization
+
+	// OCPP 2.0.1 Local Authorization List and authorization cache of typed idTokens
+	idTokenAuth *IdTokenAuthorization
+
+	// OCPP 2.0.1 transaction lifecycle (connector ID -> TransactionEvent state)
+	txControl TxControl
+	txEvents  map[int]*txEventState
 }
 
 // NewSessionManager creates a new session manager
@@ -42,12 +108,25 @@ func NewSessionManager(stationID string, connectorConfigs []ConnectorConfig, log
 	}
 
 	sm := &SessionManager{
-		stationID:         stationID,
-		connectors:        make(map[int]*Connector),
-		nextTransactionID: 1,
-		logger:            logger,
-		meterValueTickers: make(map[int]*time.Ticker),
-		stopChans:         make(map[int]chan struct{}),
+		stationID:           stationID,
+		connectors:          make(map[int]*Connector),
+		nextTransactionID:   1,
+		logger:              logger,
+		meterValueTickers:   make(map[int]*time.Ticker),
+		stopChans:           make(map[int]chan struct{}),
+		meterSampleInterval: defaultMeterValueSampleInterval,
+		sampledData:         defaultSampledData(),
+		chargingProfiles:    NewChargingProfileManager(),
+		tariffs:             NewTariffManager(),
+		evSessions:          make(map[int]*evChargingSession),
+		plugAndCharge:       NewPlugAndCharge(stationID, connectorConfigs),
+		reservationTimers:   make(map[int]*time.Timer),
+		unboundReservations: make(map[int]*Reservation),
+		unboundTimers:       make(map[int]*time.Timer),
+		authorization:       NewLocalAuthorization(stationID, logger),
+		idTokenAuth:         NewIdTokenAuthorization(stationID, logger),
+		txControl:           DefaultTxControl(),
+		txEvents:            make(map[int]*txEventState),
 	}
 
 	// Initialize connectors
@@ -103,6 +182,145 @@ func (sm *SessionManager) SetTransactionRepository(repo *storage.TransactionRepo
 // SetProtocolVersion sets the protocol version for transaction logging
 func (sm *SessionManager) SetProtocolVersion(version string) {
 	sm.protocolVersion = version
+
+	// Only OCPP 2.1 profiles discharge V2X EVs
+	sm.chargingProfiles.AllowDischarge(ocppVersion(version) == v201.OCPPVersion21)
+}
+
+// Authorization returns the local authorization list and cache of the station
+func (sm *SessionManager) Authorization() *LocalAuthorization {
+	return sm.authorization
+}
+
+// IdTokenAuthorization returns the OCPP 2.0.1 local authorization list and cache of the station
+func (sm *SessionManager) IdTokenAuthorization() *IdTokenAuthorization {
+	return sm.idTokenAuth
+}
+
+// ChargingProfiles returns the charging profile manager of the station
+func (sm *SessionManager) ChargingProfiles() *ChargingProfileManager {
+	return sm.chargingProfiles
+}
+
+// Tariffs returns the OCPP 2.1 tariffs of the station
+func (sm *SessionManager) Tariffs() *TariffManager {
+	return sm.tariffs
+}
+
+// PlugAndCharge returns the ISO 15118 Plug & Charge EVs of the connectors
+func (sm *SessionManager) PlugAndCharge() *PlugAndCharge {
+	return sm.plugAndCharge
+}
+
+// SetChargingProfile installs a charging profile on a connector.
+// TxProfiles are only accepted while the connector has a matching active transaction.
+func (sm *SessionManager) SetChargingProfile(connectorID int, profile v16.ChargingProfile) error {
+	if connectorID > 0 {
+		if _, err := sm.GetConnector(connectorID); err != nil {
+			return err
+		}
+	}
+
+	if profile.ChargingProfilePurpose == v16.ChargingProfilePurposeTxProfile {
+		connector, err := sm.GetConnector(connectorID)
+		if err != nil {
+			return err
+		}
+		tx := connector.GetTransaction()
+		if tx == nil || !connector.HasActiveTransaction() {
+			return fmt.Errorf("connector %d has no active transaction", connectorID)
+		}
+		if profile.TransactionId != nil && *profile.TransactionId != tx.ID {
+			return fmt.Errorf("transaction %d is not active on connector %d", *profile.TransactionId, connectorID)
+		}
+	}
+
+	if err := sm.chargingProfiles.SetProfile(connectorID, profile); err != nil {
+		return err
+	}
+
+	sm.logger.Info("Charging profile installed",
+		"stationId", sm.stationID,
+		"connectorId", connectorID,
+		"profileId", profile.ChargingProfileId,
+		"purpose", profile.ChargingProfilePurpose,
+		"stackLevel", profile.StackLevel,
+	)
+
+	return nil
+}
+
+// SetEVSEChargingProfile installs an OCPP 2.0.1 charging profile on an EVSE.
+// A TxProfile must reference the transaction that is active on the EVSE.
+func (sm *SessionManager) SetEVSEChargingProfile(evseID int, profile v201.ChargingProfile) error {
+	converted, err := chargingProfileFromV201(profile)
+	if err != nil {
+		return err
+	}
+
+	if converted.ChargingProfilePurpose == v16.ChargingProfilePurposeTxProfile {
+		connector, err := sm.GetConnector(evseID)
+		if err != nil {
+			return err
+		}
+		tx := connector.GetTransaction()
+		if tx == nil || !connector.HasActiveTransaction() || tx.StringID != profile.TransactionId {
+			return fmt.Errorf("transaction %q is not active on EVSE %d", profile.TransactionId, evseID)
+		}
+	}
+
+	return sm.SetChargingProfile(evseID, converted)
+}
+
+// GetCompositeSchedule calculates the composite schedule for a connector starting now
+func (sm *SessionManager) GetCompositeSchedule(connectorID int, duration int, unit v16.ChargingRateUnitType) (*v16.ChargingSchedule, error) {
+	var txStart *time.Time
+	if connectorID > 0 {
+		connector, err := sm.GetConnector(connectorID)
+		if err != nil {
+			return nil, err
+		}
+		txStart = transactionStart(connector)
+	}
+
+	schedule := sm.chargingProfiles.GetCompositeSchedule(connectorID, time.Now(), duration, unit, txStart)
+
+	// Without an applicable profile only the maximum power of the hardware limits charging
+	if len(schedule.ChargingSchedulePeriod) == 0 {
+		maxPower := 0
+		for _, connector := range sm.GetAllConnectors() {
+			if connectorID == 0 || connector.ID == connectorID {
+				maxPower += connector.MaxPower
+			}
+		}
+		if maxPower <= 0 {
+			return nil, fmt.Errorf("no charging limit known for connector %d", connectorID)
+		}
+
+		limit := ChargingLimit{Limit: float64(maxPower), Unit: v16.ChargingRateUnitW, NumberPhases: defaultNumberPhases}
+		value := limit.Watts()
+		if schedule.ChargingRateUnit == v16.ChargingRateUnitA {
+			value = float64(int(limit.Amps()*10)) / 10
+		}
+		phases := limit.NumberPhases
+		schedule.ChargingSchedulePeriod = []v16.ChargingSchedulePeriod{{StartPeriod: 0, Limit: value, NumberPhases: &phases}}
+	}
+
+	return &schedule, nil
+}
+
+// GetPowerLimit returns the current power limit in watts imposed by charging profiles on a connector
+func (sm *SessionManager) GetPowerLimit(connectorID int) (float64, bool) {
+	connector, err := sm.GetConnector(connectorID)
+	if err != nil {
+		return 0, false
+	}
+
+	limit, ok := sm.chargingProfiles.GetLimit(connectorID, time.Now(), transactionStart(connector))
+	if !ok {
+		return 0, false
+	}
+	return limit.Watts(), true
 }
 
 // SetStationStateCallback registers a callback for station-level state changes derived from connector states.
@@ -117,27 +335,143 @@ func (sm *SessionManager) NotifyStationState(reason string) {
 	sm.evaluateStationState(reason)
 }
 
-// Authorize authorizes an ID tag
+// Authorize authorizes an ID tag, OCPP 2.0.1/2.1 stations authorize it as ISO14443 idToken
 func (sm *SessionManager) Authorize(idTag string) (*v16.IdTagInfo, error) {
+	if sm.usesTransactionEvents() {
+		return sm.authorizeIdTag(v201.IdToken{IdToken: idTag, Type: v201.IdTokenTypeISO14443})
+	}
+
 	sm.logger.Info("Authorizing ID tag", "stationId", sm.stationID, "idTag", idTag)
 
+	// Locally known valid tags may start without waiting for the CSMS
+	if info, ok := sm.authorization.PreAuthorize(idTag); ok {
+		sm.logger.Info("ID tag pre-authorized locally", "stationId", sm.stationID, "idTag", idTag)
+		return info, nil
+	}
+
 	if sm.SendAuthorize == nil {
-		// Offline authorization - accept by default
-		return &v16.IdTagInfo{
-			Status: v16.AuthorizationStatusAccepted,
-		}, nil
+		return sm.authorizeOffline(idTag), nil
 	}
 
 	resp, err := sm.SendAuthorize(idTag)
 	if err != nil {
-		return nil, fmt.Errorf("authorization failed: %w", err)
+		sm.logger.Warn("CSMS authorization unavailable, using local authorization",
+			"stationId", sm.stationID,
+			"idTag", idTag,
+			"error", err,
+		)
+		return sm.authorizeOffline(idTag), nil
 	}
 
+	sm.authorization.UpdateCache(idTag, resp.IdTagInfo)
+
 	return &resp.IdTagInfo, nil
 }
 
+// authorizeOffline authorizes an ID tag against the Local Authorization List and cache
+func (sm *SessionManager) authorizeOffline(idTag string) *v16.IdTagInfo {
+	info := sm.authorization.AuthorizeOffline(idTag)
+
+	sm.logger.Info("Offline authorization",
+		"stationId", sm.stationID,
+		"idTag", idTag,
+		"status", info.Status,
+	)
+
+	return info
+}
+
+// AuthorizeIdToken authorizes an OCPP 2.0.1 idToken. Locally pre-authorized idTokens start
+// without the CSMS, CSMS responses are cached and the local list and cache are used offline.
+func (sm *SessionManager) AuthorizeIdToken(idToken v201.IdToken) (*v201.IdTokenInfo, error) {
+	sm.logger.Info("Authorizing idToken", "stationId", sm.stationID, "idToken", idToken.IdToken, "type", idToken.Type)
+
+	if !idToken.Type.Valid() {
+		return nil, fmt.Errorf("invalid idToken type %q", idToken.Type)
+	}
+
+	// Without authorization (AuthCtrlr.Enabled false) and for NoAuthorization idTokens every
+	// idToken is accepted
+	if !sm.idTokenAuth.Config().Enabled || idToken.Type == v201.IdTokenTypeNoAuthorization {
+		return &v201.IdTokenInfo{Status: v201.AuthorizationStatusAccepted}, nil
+	}
+
+	if info, ok := sm.idTokenAuth.PreAuthorize(idToken); ok {
+		sm.logger.Info("idToken pre-authorized locally", "stationId", sm.stationID, "idToken", idToken.IdToken)
+		return info, nil
+	}
+
+	if sm.SendAuthorizeIdToken == nil {
+		return sm.authorizeIdTokenOffline(idToken), nil
+	}
+
+	resp, err := sm.SendAuthorizeIdToken(idToken)
+	if err != nil {
+		sm.logger.Warn("CSMS authorization unavailable, using local authorization",
+			"stationId", sm.stationID,
+			"idToken", idToken.IdToken,
+			"error", err,
+		)
+		return sm.authorizeIdTokenOffline(idToken), nil
+	}
+
+	sm.idTokenAuth.UpdateCache(idToken, resp.IdTokenInfo)
+
+	return &resp.IdTokenInfo, nil
+}
+
+// authorizeIdTokenOffline authorizes an idToken against the Local Authorization List and cache
+func (sm *SessionManager) authorizeIdTokenOffline(idToken v201.IdToken) *v201.IdTokenInfo {
+	info := sm.idTokenAuth.AuthorizeOffline(idToken)
+
+	sm.logger.Info("Offline authorization",
+		"stationId", sm.stationID,
+		"idToken", idToken.IdToken,
+		"type", idToken.Type,
+		"status", info.Status,
+	)
+
+	return info
+}
+
+// authorizeIdTag authorizes an idToken for the session, which works with OCPP 1.6 ID tag info
+func (sm *SessionManager) authorizeIdTag(idToken v201.IdToken) (*v16.IdTagInfo, error) {
+	info, err := sm.AuthorizeIdToken(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	idTagInfo := IdTagInfoFromV201(info)
+	return &idTagInfo, nil
+}
+
 // StartCharging initiates a charging session
 func (sm *SessionManager) StartCharging(connectorID int, idTag string) (int, error) {
+	return sm.startCharging(connectorID, v201.IdToken{IdToken: idTag, Type: v201.IdTokenTypeISO14443}, nil)
+}
+
+// StartChargingWithIdToken initiates a charging session of an OCPP 2.0.1/2.1 station with a
+// typed idToken
+func (sm *SessionManager) StartChargingWithIdToken(connectorID int, idToken v201.IdToken) (int, error) {
+	return sm.startCharging(connectorID, idToken, nil)
+}
+
+// StartRemoteCharging initiates a charging session requested by the CSMS with an OCPP 2.0.1
+// RequestStartTransaction, the remote start ID is reported with the transaction
+func (sm *SessionManager) StartRemoteCharging(connectorID int, idToken v201.IdToken, remoteStartID int) (int, error) {
+	return sm.startCharging(connectorID, idToken, &remoteStartID)
+}
+
+// startCharging initiates a charging session, remoteStartID is set for OCPP 2.0.1 remote starts.
+// OCPP 1.6 stations use the value of the idToken as ID tag. Local starts at Plug & Charge
+// connectors of OCPP 2.0.1/2.1 stations use the eMAID of the EV instead of the idToken.
+func (sm *SessionManager) startCharging(connectorID int, idToken v201.IdToken, remoteStartID *int) (int, error) {
+	plugAndCharge := remoteStartID == nil && sm.usesTransactionEvents() && sm.plugAndCharge.Enabled(connectorID)
+	if plugAndCharge {
+		idToken = v201.IdToken{IdToken: sm.plugAndCharge.EMAID(connectorID), Type: v201.IdTokenTypeEMAID}
+	}
+	idTag := idToken.IdToken
+
 	sm.logger.Info("Starting charging session",
 		"stationId", sm.stationID,
 		"connectorId", connectorID,
@@ -151,17 +485,28 @@ func (sm *SessionManager) StartCharging(connectorID int, idTag string) (int, err
 	}
 
 	// Check if connector is available
-	if !connector.IsAvailable() {
+	if !connector.IsAvailable() && connector.GetState() != ConnectorStateReserved {
 		return 0, fmt.Errorf("connector %d is not available (state: %s)", connectorID, connector.GetState())
 	}
 
-	// Check reservation
-	if connector.IsReserved() && !connector.IsReservedFor(idTag) {
-		return 0, fmt.Errorf("connector %d is reserved for another ID tag", connectorID)
+	// Check reservation - a tag other than the reserving one may only pass via its parent tag
+	reservation := connector.GetReservation()
+	if reservation != nil && reservation.ParentIDTag == "" {
+		if err := checkReservation(reservation, idTag, ""); err != nil {
+			return 0, fmt.Errorf("connector %d: %w", connectorID, err)
+		}
 	}
 
 	// Authorize - this now waits for real CSMS response
-	authInfo, err := sm.Authorize(idTag)
+	var authInfo *v16.IdTagInfo
+	switch {
+	case plugAndCharge:
+		authInfo, err = sm.authorizeContract(connectorID)
+	case sm.usesTransactionEvents():
+		authInfo, err = sm.authorizeIdTag(idToken)
+	default:
+		authInfo, err = sm.Authorize(idTag)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("authorization failed: %w", err)
 	}
@@ -170,6 +515,42 @@ func (sm *SessionManager) StartCharging(connectorID int, idTag string) (int, err
 		return 0, fmt.Errorf("authorization rejected: %s", authInfo.Status)
 	}
 
+	if err := checkReservation(reservation, idTag, authInfo.ParentIdTag); err != nil {
+		return 0, fmt.Errorf("connector %d: %w", connectorID, err)
+	}
+
+	// Without a reservation of its own, the connector may be needed by reservations without EVSE
+	if reservation == nil {
+		if reservation, err = sm.claimUnboundReservation(connector, idTag, authInfo.ParentIdTag); err != nil {
+			return 0, fmt.Errorf("connector %d: %w", connectorID, err)
+		}
+	}
+
+	// Roll back to Available and on to Reserved while the reservation is still held
+	rollbackState := func() {
+		if err := connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, ""); err != nil {
+			sm.logger.Warn("Failed to roll back connector state", "connectorId", connectorID, "error", err)
+			return
+		}
+
+		status := v16.ChargePointStatusAvailable
+		reserved := connector.IsReserved()
+		if reserved {
+			if err := connector.SetState(ConnectorStateReserved, v16.ChargePointErrorNoError, ""); err != nil {
+				sm.logger.Warn("Failed to restore connector reservation", "connectorId", connectorID, "error", err)
+			} else {
+				status = v16.ChargePointStatusReserved
+			}
+		}
+
+		if sm.SendStatusNotification != nil {
+			sm.SendStatusNotification(connectorID, status, v16.ChargePointErrorNoError, "")
+		}
+		if !reserved {
+			sm.updateHeldConnectors()
+		}
+	}
+
 	// Transition to Preparing
 	if err := connector.SetState(ConnectorStatePreparing, v16.ChargePointErrorNoError, "Preparing to charge"); err != nil {
 		return 0, fmt.Errorf("failed to set state to Preparing: %w", err)
@@ -192,13 +573,24 @@ func (sm *SessionManager) StartCharging(connectorID int, idTag string) (int, err
 		meterStart = tx.CurrentMeter
 	}
 
+	// OCPP 2.0.1 transactions get a station generated ID and are reported with TransactionEvent
+	stringID := ""
+	if sm.usesTransactionEvents() {
+		var reservationID *int
+		if reservation != nil {
+			id := reservation.ID
+			reservationID = &id
+		}
+		stringID = sm.newTransactionEvents(connectorID, idToken, remoteStartID, reservationID)
+	}
+
 	// Send StartTransaction
 	var startResp *v16.StartTransactionResponse
-	if sm.SendStartTransaction != nil {
+	if sm.SendStartTransaction != nil && !sm.usesTransactionEvents() {
 		startResp, err = sm.SendStartTransaction(connectorID, idTag, meterStart, time.Now())
 		if err != nil {
 			// Rollback state
-			connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, "")
+			rollbackState()
 			return 0, fmt.Errorf("failed to send StartTransaction: %w", err)
 		}
 
@@ -207,16 +599,26 @@ func (sm *SessionManager) StartCharging(connectorID int, idTag string) (int, err
 
 		// Check authorization status
 		if startResp.IdTagInfo.Status != v16.AuthorizationStatusAccepted {
-			connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, "")
+			rollbackState()
 			return 0, fmt.Errorf("transaction rejected by CSMS: %s", startResp.IdTagInfo.Status)
 		}
 	}
 
 	// Start local transaction
 	if err := connector.StartTransaction(transactionID, idTag, meterStart); err != nil {
-		connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, "")
+		rollbackState()
 		return 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	if stringID != "" {
+		connector.SetStringTransactionID(stringID)
+	}
+	ev := sm.EVConfig()
+	connector.UpdateEnergy(meterStart, 0, float64(ev.InitialSoC))
+
+	// The reservation has been used by this transaction
+	if reservation != nil {
+		sm.consumeReservation(connector, reservation.ID)
+	}
 
 	// Persist transaction to database
 	if sm.transactionRepo != nil {
@@ -257,6 +659,15 @@ func (sm *SessionManager) StartCharging(connectorID int, idTag string) (int, err
 		sm.SendStatusNotification(connectorID, v16.ChargePointStatusCharging, v16.ChargePointErrorNoError, "Charging")
 	}
 
+	// Report the transaction with TransactionEvent (OCPP 2.0.1)
+	if stringID != "" {
+		if ocppVersion(sm.protocolVersion) == v201.OCPPVersion21 {
+			sm.tariffs.startTransaction(connectorID, stringID, idTag, meterStart, time.Now())
+		}
+		sm.sendStartEvents(connector, meterStart)
+		sm.sendEVChargingNeeds(connectorID, float64(ev.InitialSoC), time.Now())
+	}
+
 	// Start meter value simulation
 	sm.startMeterValueSimulation(connector, transactionID)
 
@@ -290,6 +701,7 @@ func (sm *SessionManager) StopCharging(connectorID int, reason v16.Reason) error
 
 	// Stop meter value simulation
 	sm.stopMeterValueSimulation(connectorID)
+	sm.endEVChargingSession(connectorID)
 
 	// Get transaction details
 	tx := connector.GetTransaction()
@@ -315,8 +727,11 @@ func (sm *SessionManager) StopCharging(connectorID int, reason v16.Reason) error
 		sm.logger.Error("Failed to stop transaction locally", "error", err)
 	}
 
-	// Send StopTransaction
-	if sm.SendStopTransaction != nil {
+	// Send StopTransaction, or the final TransactionEvents (OCPP 2.0.1)
+	if sm.usesTransactionEvents() {
+		cost := sm.tariffs.endTransaction(connectorID, meterStop, time.Now())
+		sm.sendStopEvents(connectorID, meterStop, tx.ExportMeter, tx.StartTime, reason, cost)
+	} else if sm.SendStopTransaction != nil {
 		_, err = sm.SendStopTransaction(tx.ID, tx.IDTag, meterStop, time.Now(), reason)
 		if err != nil {
 			sm.logger.Error("Failed to send StopTransaction", "error", err)
@@ -338,8 +753,9 @@ func (sm *SessionManager) StopCharging(connectorID int, reason v16.Reason) error
 		}
 	}
 
-	// Clear transaction
+	// Clear transaction and the TxProfiles bound to it
 	connector.ClearTransaction()
+	sm.chargingProfiles.ClearTxProfiles(connectorID)
 
 	// Transition back to Available
 	if err := connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, ""); err != nil {
@@ -432,8 +848,15 @@ func (sm *SessionManager) startMeterValueSimulation(connector *Connector, transa
 		close(stopChan)
 	}
 
-	// Create new ticker (every 60 seconds)
-	ticker := time.NewTicker(60 * time.Second)
+	delete(sm.meterValueTickers, connectorID)
+	delete(sm.stopChans, connectorID)
+
+	// A sample interval of 0 disables periodic meter values
+	if sm.meterSampleInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(sm.meterSampleInterval) * time.Second)
 	stopChan := make(chan struct{})
 
 	sm.meterValueTickers[connectorID] = ticker
@@ -470,6 +893,36 @@ func (sm *SessionManager) stopMeterValueSimulation(connectorID int) {
 	}
 }
 
+// SetMeterValueConfig sets the sample interval in seconds and the measurands of periodic meter values.
+// Meter value simulations of active transactions are restarted when the interval changes.
+func (sm *SessionManager) SetMeterValueConfig(intervalSeconds int, measurands []v16.Measurand) {
+	sm.mu.Lock()
+	changed := sm.meterSampleInterval != intervalSeconds
+	sm.meterSampleInterval = intervalSeconds
+	sm.sampledData = append([]v16.Measurand(nil), measurands...)
+	sm.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	for _, connector := range sm.GetAllConnectors() {
+		if !connector.HasActiveTransaction() {
+			continue
+		}
+		if tx := connector.GetTransaction(); tx != nil {
+			sm.startMeterValueSimulation(connector, tx.ID)
+		}
+	}
+}
+
+// MeterValueConfig returns the sample interval in seconds and the measurands of periodic meter values
+func (sm *SessionManager) MeterValueConfig() (int, []v16.Measurand) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.meterSampleInterval, append([]v16.Measurand(nil), sm.sampledData...)
+}
+
 // ResumeMeterValues resumes meter value simulation for a connector with an active transaction
 // This is typically called during reconciliation after server restart
 func (sm *SessionManager) ResumeMeterValues(connectorID int) error {
@@ -510,54 +963,204 @@ func (sm *SessionManager) sendMeterValue(connector *Connector) {
 	}
 
 	transactionID := tx.ID
+	ev := sm.EVConfig()
 
-	// Simulate power consumption (random between 5-7.5 kW)
-	powerWatts := 5000 + rand.Intn(2500)
+	// Simulate the power the EV draws
+	powerWatts := ev.chargePower()
 
-	// Energy increment (Wh) = Power (W) * time (h)
-	// 60 seconds = 1/60 hour
-	energyIncrement := powerWatts / 60
+	// Throttle to the limit of the active charging profile, a V2X EV discharges at a negative limit
+	if limitWatts, limited := sm.GetPowerLimit(connector.ID); limited {
+		powerWatts = ev.limitedPower(powerWatts, limitWatts)
+		sm.applySmartChargingSuspension(connector, powerWatts == 0)
+	}
+	// An ISO 15118 EV charges according to its negotiated charging schedule
+	if limitWatts, scheduled := sm.evScheduleLimit(connector.ID, time.Now()); scheduled && powerWatts > 0 {
+		powerWatts = min(powerWatts, max(int(limitWatts), 0))
+	}
+	powerWatts = ev.batteryPower(powerWatts, tx.SoC)
+
+	interval, measurands := sm.MeterValueConfig()
+	if ev.V2X {
+		measurands = withExportRegister(measurands)
+	}
+
+	// Energy increment (Wh) = Power (W) * time (h), discharged energy goes to the export register
+	energyIncrement, exportIncrement := powerWatts*interval/3600, 0
+	if powerWatts < 0 {
+		energyIncrement, exportIncrement = 0, -powerWatts*interval/3600
+	}
 
 	// Update meter
 	newMeter := tx.CurrentMeter + energyIncrement
-	connector.UpdateMeter(newMeter)
+	exportMeter := tx.ExportMeter + exportIncrement
+	connector.UpdateEnergy(newMeter, exportMeter, ev.stateOfCharge(tx.SoC, energyIncrement-exportIncrement))
+	cost := sm.tariffs.update(connector.ID, newMeter, powerWatts, time.Now())
+
+	// Power offered to the EV is the connector maximum unless a charging profile limits it
+	offeredWatts := connector.MaxPower
+	if limitWatts, limited := sm.GetPowerLimit(connector.ID); limited {
+		offeredWatts = int(limitWatts)
+	}
 
 	// Create meter value sample
-	if sm.SendMeterValues != nil {
-		meterValues := []v16.MeterValue{
-			{
-				Timestamp: v16.DateTime{Time: time.Now()},
-				SampledValue: []v16.SampledValue{
-					{
-						Value:     fmt.Sprintf("%d", newMeter),
-						Context:   v16.ReadingContextSamplePeriodic,
-						Measurand: v16.MeasurandEnergyActiveImportRegister,
-						Unit:      v16.UnitOfMeasureWh,
-						Location:  v16.LocationOutlet,
-					},
-					{
-						Value:     fmt.Sprintf("%d", powerWatts),
-						Context:   v16.ReadingContextSamplePeriodic,
-						Measurand: v16.MeasurandPowerActiveImport,
-						Unit:      v16.UnitOfMeasureW,
-						Location:  v16.LocationOutlet,
-					},
-				},
-			},
+	if len(measurands) > 0 && (sm.SendMeterValues != nil || sm.usesTransactionEvents()) {
+		sampledValues := make([]v16.SampledValue, 0, len(measurands))
+		for _, measurand := range measurands {
+			sampledValues = append(sampledValues, periodicSample(measurand, newMeter, exportMeter, energyIncrement, powerWatts, offeredWatts))
 		}
 
-		sm.SendMeterValues(connector.ID, &transactionID, meterValues)
+		if sm.usesTransactionEvents() {
+			// OCPP 2.0.1 reports meter values of a transaction with TransactionEvent Updated
+			sm.sendMeterValueEvent(connector.ID, sampledValues, cost)
+		} else {
+			meterValues := []v16.MeterValue{
+				{
+					Timestamp:    v16.DateTime{Time: time.Now()},
+					SampledValue: sampledValues,
+				},
+			}
+
+			sm.SendMeterValues(connector.ID, &transactionID, meterValues)
+		}
 	}
 
+	sm.updateEVSEVariable(connector.ID, "Power", strconv.Itoa(powerWatts))
+	sm.updateEVSEVariable(connector.ID, "Temperature", formatTemperature(evseTemperature(powerWatts)))
+
 	sm.logger.Debug("Meter value sent",
 		"stationId", sm.stationID,
 		"connectorId", connector.ID,
 		"transactionId", transactionID,
 		"meter", newMeter,
+		"exportMeter", exportMeter,
 		"power", powerWatts,
 	)
 }
 
+// periodicSample builds a sampled value of a measurand for a periodic meter value.
+// The power is negative while the EV discharges.
+func periodicSample(measurand v16.Measurand, meterWh, exportWh, intervalWh, powerWatts, offeredWatts int) v16.SampledValue {
+	sv := v16.SampledValue{
+		Context:   v16.ReadingContextSamplePeriodic,
+		Measurand: measurand,
+		Location:  v16.LocationOutlet,
+	}
+
+	switch measurand {
+	case v16.MeasurandEnergyActiveImportRegister:
+		sv.Value, sv.Unit = strconv.Itoa(meterWh), v16.UnitOfMeasureWh
+	case v16.MeasurandEnergyActiveExportRegister:
+		sv.Value, sv.Unit = strconv.Itoa(exportWh), v16.UnitOfMeasureWh
+	case v16.MeasurandEnergyActiveImportInterval:
+		sv.Value, sv.Unit = strconv.Itoa(intervalWh), v16.UnitOfMeasureWh
+	case v16.MeasurandPowerActiveImport:
+		sv.Value, sv.Unit = strconv.Itoa(powerWatts), v16.UnitOfMeasureW
+	case v16.MeasurandPowerActiveExport:
+		sv.Value, sv.Unit = strconv.Itoa(max(-powerWatts, 0)), v16.UnitOfMeasureW
+	case v16.MeasurandPowerOffered:
+		sv.Value, sv.Unit = strconv.Itoa(offeredWatts), v16.UnitOfMeasureW
+	case v16.MeasurandCurrentImport:
+		sv.Value, sv.Unit = fmt.Sprintf("%.1f", float64(powerWatts)/nominalVoltage), v16.UnitOfMeasureA
+	case v16.MeasurandCurrentOffered:
+		sv.Value, sv.Unit = fmt.Sprintf("%.1f", float64(offeredWatts)/nominalVoltage), v16.UnitOfMeasureA
+	case v16.MeasurandVoltage:
+		sv.Value, sv.Unit = fmt.Sprintf("%.1f", nominalVoltage), v16.UnitOfMeasureV
+	}
+
+	return sv
+}
+
+// TriggerMeterValues sends the current energy register of a connector with context Trigger.
+// Connector 0 reports the sum of all connectors.
+func (sm *SessionManager) TriggerMeterValues(connectorID int) error {
+	var connectors []*Connector
+	if connectorID == 0 {
+		connectors = sm.GetAllConnectors()
+	} else {
+		connector, err := sm.GetConnector(connectorID)
+		if err != nil {
+			return err
+		}
+		connectors = []*Connector{connector}
+	}
+
+	if sm.SendMeterValues == nil {
+		return fmt.Errorf("meter values callback not configured")
+	}
+
+	meter := 0
+	var transactionID *int
+	for _, connector := range connectors {
+		tx := connector.GetTransaction()
+		if tx == nil || tx.StopTime != nil {
+			continue
+		}
+		meter += tx.CurrentMeter
+		if connectorID != 0 {
+			id := tx.ID
+			transactionID = &id
+		}
+	}
+
+	meterValues := []v16.MeterValue{
+		{
+			Timestamp: v16.DateTime{Time: time.Now()},
+			SampledValue: []v16.SampledValue{
+				{
+					Value:     fmt.Sprintf("%d", meter),
+					Context:   v16.ReadingContextTrigger,
+					Measurand: v16.MeasurandEnergyActiveImportRegister,
+					Unit:      v16.UnitOfMeasureWh,
+					Location:  v16.LocationOutlet,
+				},
+			},
+		},
+	}
+
+	return sm.SendMeterValues(connectorID, transactionID, meterValues)
+}
+
+// applySmartChargingSuspension moves the connector between Charging and SuspendedEVSE
+// when a charging profile limits the power to zero or lifts that limit
+func (sm *SessionManager) applySmartChargingSuspension(connector *Connector, suspend bool) {
+	state := connector.GetState()
+
+	var (
+		newState ConnectorState
+		status   v16.ChargePointStatus
+	)
+
+	switch {
+	case suspend && state == ConnectorStateCharging:
+		newState, status = ConnectorStateSuspendedEVSE, v16.ChargePointStatusSuspendedEVSE
+	case !suspend && state == ConnectorStateSuspendedEVSE:
+		newState, status = ConnectorStateCharging, v16.ChargePointStatusCharging
+	default:
+		return
+	}
+
+	if err := connector.SetState(newState, v16.ChargePointErrorNoError, ""); err != nil {
+		sm.logger.Warn("Failed to apply charging limit state", "connectorId", connector.ID, "error", err)
+		return
+	}
+
+	if sm.SendStatusNotification != nil {
+		sm.SendStatusNotification(connector.ID, status, v16.ChargePointErrorNoError, "")
+	}
+}
+
+// transactionStart returns the start time of the connector's active transaction
+func transactionStart(connector *Connector) *time.Time {
+	connector.mu.RLock()
+	defer connector.mu.RUnlock()
+
+	if connector.Transaction == nil || connector.Transaction.StopTime != nil {
+		return nil
+	}
+	start := connector.Transaction.StartTime
+	return &start
+}
+
 // onConnectorStateChange is called when a connector state changes
 func (sm *SessionManager) onConnectorStateChange(connectorID int, oldState, newState ConnectorState) {
 	sm.logger.Info("Connector state changed",
@@ -568,27 +1171,144 @@ func (sm *SessionManager) onConnectorStateChange(connectorID int, oldState, newS
 	)
 
 	sm.evaluateStationState(fmt.Sprintf("connector %d transitioned from %s to %s", connectorID, oldState, newState))
+
+	// A faulted connector loses its reservation, reservations without EVSE move to other connectors
+	if newState == ConnectorStateFaulted {
+		if connector, err := sm.GetConnector(connectorID); err == nil {
+			sm.removeFaultedReservation(connector)
+		}
+	}
+	if sm.hasUnboundReservations() && newState != ConnectorStateReserved {
+		sm.updateHeldConnectors()
+	}
+
+	sm.updateEVSEVariable(connectorID, "AvailabilityState", string(connectorStatusV201(v16.ChargePointStatus(newState))))
+	if newState != ConnectorStateCharging {
+		// No energy is transferred, the EVSE cools down to ambient temperature
+		sm.updateEVSEVariable(connectorID, "Power", "0")
+		sm.updateEVSEVariable(connectorID, "Temperature", formatTemperature(ambientTemperature))
+	}
 }
 
-// Shutdown stops all ongoing sessions and cleanup
-func (sm *SessionManager) Shutdown(ctx context.Context) error {
-	sm.logger.Info("Shutting down session manager", "stationId", sm.stationID)
+// updateEVSEVariable reports a simulated EVSE value to the device model
+func (sm *SessionManager) updateEVSEVariable(connectorID int, variable, value string) {
+	if sm.UpdateEVSEVariable != nil {
+		sm.UpdateEVSEVariable(connectorID, variable, value)
+	}
+}
 
-	// Stop all active transactions
+// ambientTemperature is the simulated EVSE temperature without load, in Celsius
+const ambientTemperature = 25.0
+
+// evseTemperature simulates the EVSE temperature, rising with the power delivered
+func evseTemperature(powerWatts int) float64 {
+	return ambientTemperature + float64(powerWatts)/1000*1.5
+}
+
+// formatTemperature formats a temperature with one decimal
+func formatTemperature(celsius float64) string {
+	return strconv.FormatFloat(celsius, 'f', 1, 64)
+}
+
+// StopAllTransactions stops the active transactions of all connectors with the given reason.
+// Returns the number of stopped transactions.
+func (sm *SessionManager) StopAllTransactions(reason v16.Reason) int {
+	stopped := 0
+	for _, connector := range sm.GetAllConnectors() {
+		if !connector.HasActiveTransaction() {
+			continue
+		}
+
+		if err := sm.StopCharging(connector.ID, reason); err != nil {
+			sm.logger.Error("Failed to stop charging",
+				"stationId", sm.stationID,
+				"connectorId", connector.ID,
+				"reason", reason,
+				"error", err,
+			)
+			continue
+		}
+		stopped++
+	}
+
+	return stopped
+}
+
+// AbortTransactions ends the active transactions of all connectors locally, as after a power loss.
+// No StopTransaction or StatusNotification is sent. Returns the number of aborted transactions.
+func (sm *SessionManager) AbortTransactions(reason v16.Reason) int {
+	aborted := 0
 	for _, connector := range sm.GetAllConnectors() {
-		if connector.HasActiveTransaction() {
-			if err := sm.StopCharging(connector.ID, v16.ReasonReboot); err != nil {
-				sm.logger.Error("Failed to stop charging during shutdown",
-					"connectorId", connector.ID,
+		sm.stopMeterValueSimulation(connector.ID)
+
+		tx := connector.GetTransaction()
+		if !connector.HasActiveTransaction() || tx == nil {
+			continue
+		}
+
+		meterStop := tx.CurrentMeter
+		if err := connector.StopTransaction(meterStop, reason); err != nil {
+			sm.logger.Error("Failed to stop transaction locally", "error", err)
+		}
+
+		if sm.transactionRepo != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := sm.transactionRepo.Complete(ctx, tx.ID, sm.stationID, meterStop, string(reason)); err != nil {
+				sm.logger.Error("Failed to update transaction in database",
+					"transactionId", tx.ID,
 					"error", err,
 				)
 			}
+			cancel()
 		}
 
-		// Stop meter value simulation
+		connector.ClearTransaction()
+		sm.chargingProfiles.ClearTxProfiles(connector.ID)
+		sm.tariffs.endTransaction(connector.ID, meterStop, time.Now())
+
+		sm.mu.Lock()
+		delete(sm.txEvents, connector.ID)
+		delete(sm.evSessions, connector.ID)
+		sm.mu.Unlock()
+
+		// The connector is Available again after the restart
+		connector.SetState(ConnectorStateFinishing, v16.ChargePointErrorNoError, "")
+		if err := connector.SetState(ConnectorStateAvailable, v16.ChargePointErrorNoError, ""); err != nil {
+			sm.logger.Warn("Failed to set state to Available", "error", err)
+		}
+
+		sm.logger.Warn("Transaction aborted",
+			"stationId", sm.stationID,
+			"connectorId", connector.ID,
+			"transactionId", tx.ID,
+			"reason", reason,
+		)
+		aborted++
+	}
+
+	return aborted
+}
+
+// Shutdown stops all ongoing sessions and cleanup
+func (sm *SessionManager) Shutdown(ctx context.Context) error {
+	sm.logger.Info("Shutting down session manager", "stationId", sm.stationID)
+
+	// Stop all active transactions
+	sm.StopAllTransactions(v16.ReasonReboot)
+
+	// Stop meter value simulation and reservation expiry
+	for _, connector := range sm.GetAllConnectors() {
 		sm.stopMeterValueSimulation(connector.ID)
+		sm.stopReservationTimer(connector.ID)
 	}
 
+	sm.mu.Lock()
+	for reservationID, timer := range sm.unboundTimers {
+		timer.Stop()
+		delete(sm.unboundTimers, reservationID)
+	}
+	sm.mu.Unlock()
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package station
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,12 +67,30 @@ func TestSessionManager_Authorize(t *testing.T) {
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
 
-	// Test offline authorization (no callback set)
+	// Unknown ID tags are rejected when offline (no callback set)
 	idTagInfo, err := sm.Authorize("TAG123")
 	if err != nil {
 		t.Fatalf("Authorize failed: %v", err)
 	}
 
+	if idTagInfo.Status != v16.AuthorizationStatusInvalid {
+		t.Errorf("Expected status Invalid, got %s", idTagInfo.Status)
+	}
+
+	// ID tags on the Local Authorization List are accepted offline
+	sm.Authorization().SendLocalList(&v16.SendLocalListRequest{
+		ListVersion: 1,
+		UpdateType:  v16.UpdateTypeFull,
+		LocalAuthorizationList: []v16.AuthorizationData{
+			{IdTag: "TAG123", IdTagInfo: &v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted}},
+		},
+	})
+
+	idTagInfo, err = sm.Authorize("TAG123")
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+
 	if idTagInfo.Status != v16.AuthorizationStatusAccepted {
 		t.Errorf("Expected status Accepted, got %s", idTagInfo.Status)
 	}
@@ -101,6 +120,7 @@ func TestSessionManager_StartCharging(t *testing.T) {
 	}
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
 
 	// Set up callbacks
 	var startTxCalled bool
@@ -156,6 +176,7 @@ func TestSessionManager_StopCharging(t *testing.T) {
 	}
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
 
 	// Set up callbacks
 	sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
@@ -266,6 +287,7 @@ func TestSessionManager_ChangeAvailability_WhileCharging(t *testing.T) {
 	}
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
 
 	// Set up callbacks
 	sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
@@ -300,6 +322,7 @@ func TestSessionManager_Shutdown(t *testing.T) {
 	}
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
 
 	// Set up callbacks
 	sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
@@ -394,6 +417,7 @@ func TestSessionManager_TransactionIDIncrement(t *testing.T) {
 	}
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
 
 	// Override to not use CSMS transaction ID
 	sm.SendStartTransaction = nil
@@ -427,6 +451,7 @@ func TestSessionManager_StationStateCallback(t *testing.T) {
 	}
 
 	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
 
 	stateCh := make(chan State, 4)
 	sm.SetStationStateCallback(func(state State, reason string) {
@@ -462,3 +487,152 @@ func waitForStationState(t *testing.T, ch <-chan State, expected State) {
 		}
 	}
 }
+
+// allowOfflineTx lets unknown ID tags start transactions without a CSMS connection
+func allowOfflineTx(sm *SessionManager) {
+	config := sm.Authorization().Config()
+	config.AllowOfflineTxForUnknownId = true
+	sm.Authorization().SetConfig(config)
+
+	idTokenConfig := sm.IdTokenAuthorization().Config()
+	idTokenConfig.OfflineTxForUnknownIdEnabled = true
+	sm.IdTokenAuthorization().SetConfig(idTokenConfig)
+}
+
+func TestSessionManager_TriggerMeterValues(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+		{ID: 2, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
+
+	sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
+		return &v16.StartTransactionResponse{
+			IdTagInfo:     v16.IdTagInfo{Status: v16.AuthorizationStatusAccepted},
+			TransactionId: 777,
+		}, nil
+	}
+	sm.SendStatusNotification = func(connectorID int, status v16.ChargePointStatus, errorCode v16.ChargePointErrorCode, info string) error {
+		return nil
+	}
+
+	type sent struct {
+		connectorID   int
+		transactionID *int
+		values        []v16.MeterValue
+	}
+	var messages []sent
+	sm.SendMeterValues = func(connectorID int, transactionID *int, meterValues []v16.MeterValue) error {
+		messages = append(messages, sent{connectorID, transactionID, meterValues})
+		return nil
+	}
+
+	if _, err := sm.StartCharging(1, "TAG123"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	defer sm.Shutdown(context.Background())
+
+	if err := sm.TriggerMeterValues(1); err != nil {
+		t.Fatalf("TriggerMeterValues failed: %v", err)
+	}
+	if err := sm.TriggerMeterValues(2); err != nil {
+		t.Fatalf("TriggerMeterValues failed: %v", err)
+	}
+	if err := sm.TriggerMeterValues(3); err == nil {
+		t.Error("Expected error for unknown connector")
+	}
+
+	var triggered []sent
+	for _, msg := range messages {
+		if msg.values[0].SampledValue[0].Context == v16.ReadingContextTrigger {
+			triggered = append(triggered, msg)
+		}
+	}
+
+	if len(triggered) != 2 {
+		t.Fatalf("Expected 2 triggered meter values, got %d", len(triggered))
+	}
+	if triggered[0].connectorID != 1 || triggered[0].transactionID == nil || *triggered[0].transactionID != 777 {
+		t.Errorf("Expected connector 1 with transaction 777, got %+v", triggered[0])
+	}
+	if triggered[1].connectorID != 2 || triggered[1].transactionID != nil {
+		t.Errorf("Expected connector 2 without transaction, got %+v", triggered[1])
+	}
+	if triggered[0].values[0].SampledValue[0].Measurand != v16.MeasurandEnergyActiveImportRegister {
+		t.Errorf("Expected Energy.Active.Import.Register, got %s", triggered[0].values[0].SampledValue[0].Measurand)
+	}
+}
+
+func TestSessionManager_SetMeterValueConfig(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
+
+	if _, err := sm.StartCharging(1, "TAG123"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	defer sm.Shutdown(context.Background())
+
+	var reported []v16.MeterValue
+	sm.SendMeterValues = func(connectorID int, transactionID *int, meterValues []v16.MeterValue) error {
+		reported = meterValues
+		return nil
+	}
+
+	sm.SetMeterValueConfig(0, []v16.Measurand{v16.MeasurandVoltage, v16.MeasurandPowerOffered})
+
+	// An interval of 0 stops the periodic meter values
+	sm.mu.RLock()
+	_, running := sm.meterValueTickers[1]
+	sm.mu.RUnlock()
+	if running {
+		t.Error("Expected meter value simulation to be stopped")
+	}
+
+	connector, _ := sm.GetConnector(1)
+	sm.sendMeterValue(connector)
+
+	if len(reported) != 1 || len(reported[0].SampledValue) != 2 {
+		t.Fatalf("Expected one meter value with 2 samples, got %+v", reported)
+	}
+	if sv := reported[0].SampledValue[0]; sv.Measurand != v16.MeasurandVoltage || sv.Unit != v16.UnitOfMeasureV {
+		t.Errorf("Expected Voltage sample, got %+v", sv)
+	}
+	if sv := reported[0].SampledValue[1]; sv.Measurand != v16.MeasurandPowerOffered || sv.Value != "22000" {
+		t.Errorf("Expected 22000 W offered, got %+v", sv)
+	}
+
+	sm.SetMeterValueConfig(10, []v16.Measurand{v16.MeasurandEnergyActiveImportRegister})
+
+	sm.mu.RLock()
+	_, running = sm.meterValueTickers[1]
+	sm.mu.RUnlock()
+	if !running {
+		t.Error("Expected meter value simulation to be restarted")
+	}
+}
+
+func TestSessionManager_UpdateEVSEVariable(t *testing.T) {
+	sm := NewSessionManager("CP001", []ConnectorConfig{{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"}}, slog.Default())
+
+	var mu sync.Mutex
+	values := make(map[string]string)
+	sm.UpdateEVSEVariable = func(connectorID int, variable, value string) {
+		mu.Lock()
+		defer mu.Unlock()
+		values[variable] = value
+	}
+
+	sm.onConnectorStateChange(1, ConnectorStateAvailable, ConnectorStatePreparing)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if values["AvailabilityState"] != "Occupied" || values["Power"] != "0" || values["Temperature"] != "25.0" {
+		t.Errorf("Expected an occupied idle EVSE, got %v", values)
+	}
+}
@@ -0,0 +1,719 @@
+package station
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+const (
+	// nominalVoltage is used to convert between A and W limits
+	nominalVoltage = 230.0
+
+	// defaultNumberPhases is assumed when a schedule period does not specify phases
+	defaultNumberPhases = 3
+)
+
+// ChargingProfilePurposeExternalConstraints is the OCPP 2.0.1 ChargingStationExternalConstraints purpose.
+// Profiles of OCPP 2.0.1 and 2.1 stations are stored as OCPP 1.6 profiles; this purpose has no 1.6
+// equivalent. External constraints are set by an energy management system, not by the CSMS.
+const ChargingProfilePurposeExternalConstraints v16.ChargingProfilePurposeType = "ChargingStationExternalConstraints"
+
+// ChargingProfileManager stores the charging profiles installed on a station
+// and calculates the effective charging limit per connector (EVSE for OCPP 2.0.1).
+// Connector 0 holds station-wide profiles (ChargePointMaxProfile, TxDefaultProfile and
+// external constraints that apply to all connectors).
+type ChargingProfileManager struct {
+	profiles       map[int][]*v16.ChargingProfile
+	allowDischarge bool // Negative limits are accepted (OCPP 2.1 V2X)
+	mu             sync.RWMutex
+}
+
+// ChargingLimit represents the effective limit at a given moment, negative limits discharge the EV
+type ChargingLimit struct {
+	Limit        float64
+	Unit         v16.ChargingRateUnitType
+	NumberPhases int
+}
+
+// Watts returns the limit converted to watts
+func (l ChargingLimit) Watts() float64 {
+	if l.Unit == v16.ChargingRateUnitA {
+		return l.Limit * nominalVoltage * float64(l.NumberPhases)
+	}
+	return l.Limit
+}
+
+// Amps returns the limit converted to amperes per phase
+func (l ChargingLimit) Amps() float64 {
+	if l.Unit == v16.ChargingRateUnitA {
+		return l.Limit
+	}
+	return l.Limit / (nominalVoltage * float64(l.NumberPhases))
+}
+
+// NewChargingProfileManager creates an empty charging profile manager
+func NewChargingProfileManager() *ChargingProfileManager {
+	return &ChargingProfileManager{
+		profiles: make(map[int][]*v16.ChargingProfile),
+	}
+}
+
+// AllowDischarge sets whether profiles may have negative limits that discharge the EV
+func (cpm *ChargingProfileManager) AllowDischarge(allowed bool) {
+	cpm.mu.Lock()
+	defer cpm.mu.Unlock()
+	cpm.allowDischarge = allowed
+}
+
+// SetProfile installs a charging profile on a connector.
+// A profile with the same ID, or with the same purpose and stack level on the
+// same connector, is replaced.
+func (cpm *ChargingProfileManager) SetProfile(connectorID int, profile v16.ChargingProfile) error {
+	cpm.mu.Lock()
+	defer cpm.mu.Unlock()
+
+	if err := validateChargingProfile(connectorID, &profile, cpm.allowDischarge); err != nil {
+		return err
+	}
+
+	// Remove profile with the same ID from any connector
+	for id, list := range cpm.profiles {
+		cpm.profiles[id] = removeProfiles(list, func(p *v16.ChargingProfile) bool {
+			return p.ChargingProfileId == profile.ChargingProfileId
+		})
+	}
+
+	// Remove profile with same purpose and stack level on this connector
+	cpm.profiles[connectorID] = removeProfiles(cpm.profiles[connectorID], func(p *v16.ChargingProfile) bool {
+		return p.ChargingProfilePurpose == profile.ChargingProfilePurpose && p.StackLevel == profile.StackLevel
+	})
+
+	cpm.profiles[connectorID] = append(cpm.profiles[connectorID], &profile)
+	return nil
+}
+
+// ClearProfiles removes the profiles matching the given criteria and returns the number removed.
+// When profileID is set the other criteria are ignored. A nil criterion matches everything.
+// External constraints are only removed when their purpose is requested explicitly.
+func (cpm *ChargingProfileManager) ClearProfiles(profileID *int, connectorID *int, purpose v16.ChargingProfilePurposeType, stackLevel *int) int {
+	cpm.mu.Lock()
+	defer cpm.mu.Unlock()
+
+	removed := 0
+	for id, list := range cpm.profiles {
+		before := len(list)
+		cpm.profiles[id] = removeProfiles(list, func(p *v16.ChargingProfile) bool {
+			if p.ChargingProfilePurpose == ChargingProfilePurposeExternalConstraints && purpose != ChargingProfilePurposeExternalConstraints {
+				return false
+			}
+			if profileID != nil {
+				return p.ChargingProfileId == *profileID
+			}
+			if connectorID != nil && id != *connectorID {
+				return false
+			}
+			if purpose != "" && p.ChargingProfilePurpose != purpose {
+				return false
+			}
+			if stackLevel != nil && p.StackLevel != *stackLevel {
+				return false
+			}
+			return true
+		})
+		removed += before - len(cpm.profiles[id])
+	}
+
+	return removed
+}
+
+// ClearTxProfiles removes all TxProfiles from a connector (called when a transaction ends)
+func (cpm *ChargingProfileManager) ClearTxProfiles(connectorID int) {
+	purpose := v16.ChargingProfilePurposeTxProfile
+	cpm.ClearProfiles(nil, &connectorID, purpose, nil)
+}
+
+// GetProfiles returns a copy of the profiles installed on a connector
+func (cpm *ChargingProfileManager) GetProfiles(connectorID int) []v16.ChargingProfile {
+	cpm.mu.RLock()
+	defer cpm.mu.RUnlock()
+
+	result := make([]v16.ChargingProfile, 0, len(cpm.profiles[connectorID]))
+	for _, p := range cpm.profiles[connectorID] {
+		result = append(result, *p)
+	}
+	return result
+}
+
+// GetLimit returns the effective limit for a connector at the given time.
+// txStart is the start time of the active transaction on the connector, or nil when idle.
+// The second return value is false when no profile limits the connector.
+func (cpm *ChargingProfileManager) GetLimit(connectorID int, at time.Time, txStart *time.Time) (ChargingLimit, bool) {
+	cpm.mu.RLock()
+	defer cpm.mu.RUnlock()
+
+	return cpm.limitAt(connectorID, at, txStart)
+}
+
+// GetCompositeSchedule calculates the composite schedule of a connector for the given duration.
+// The periods are expressed in the requested unit (W when unit is empty).
+func (cpm *ChargingProfileManager) GetCompositeSchedule(connectorID int, start time.Time, duration int, unit v16.ChargingRateUnitType, txStart *time.Time) v16.ChargingSchedule {
+	if unit == "" {
+		unit = v16.ChargingRateUnitW
+	}
+
+	cpm.mu.RLock()
+	defer cpm.mu.RUnlock()
+
+	end := start.Add(time.Duration(duration) * time.Second)
+
+	// Collect every moment at which the effective limit may change
+	points := []time.Time{start}
+	for _, id := range []int{0, connectorID} {
+		for _, p := range cpm.profiles[id] {
+			points = append(points, profileBreakpoints(p, start, end, txStart)...)
+		}
+		if connectorID == 0 {
+			break
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	schedule := v16.ChargingSchedule{
+		Duration:               &duration,
+		StartSchedule:          &v16.DateTime{Time: start},
+		ChargingRateUnit:       unit,
+		ChargingSchedulePeriod: []v16.ChargingSchedulePeriod{},
+	}
+
+	var last *v16.ChargingSchedulePeriod
+	for _, point := range points {
+		if point.Before(start) || !point.Before(end) {
+			continue
+		}
+
+		limit, ok := cpm.limitAt(connectorID, point, txStart)
+		if !ok {
+			continue
+		}
+
+		value := limit.Watts()
+		if unit == v16.ChargingRateUnitA {
+			value = limit.Amps()
+		}
+		value = float64(int(value*10)) / 10
+
+		if last != nil && last.Limit == value && *last.NumberPhases == limit.NumberPhases {
+			continue
+		}
+
+		phases := limit.NumberPhases
+		schedule.ChargingSchedulePeriod = append(schedule.ChargingSchedulePeriod, v16.ChargingSchedulePeriod{
+			StartPeriod:  int(point.Sub(start).Seconds()),
+			Limit:        value,
+			NumberPhases: &phases,
+		})
+		last = &schedule.ChargingSchedulePeriod[len(schedule.ChargingSchedulePeriod)-1]
+	}
+
+	return schedule
+}
+
+// limitAt calculates the effective limit (caller must hold read lock).
+// A TxProfile overrides a TxDefaultProfile, a connector-specific TxDefaultProfile
+// overrides a station-wide one, and the result is capped by the ChargePointMaxProfile
+// and by the external constraints of the station and the connector. A negative limit is
+// lower than any charging limit, so a discharge request is never capped by them.
+func (cpm *ChargingProfileManager) limitAt(connectorID int, at time.Time, txStart *time.Time) (ChargingLimit, bool) {
+	var txLimit *ChargingLimit
+
+	if connectorID > 0 {
+		if txStart != nil {
+			txLimit = highestStackLimit(cpm.profiles[connectorID], v16.ChargingProfilePurposeTxProfile, at, txStart)
+		}
+		if txLimit == nil {
+			txLimit = highestStackLimit(cpm.profiles[connectorID], v16.ChargingProfilePurposeTxDefaultProfile, at, txStart)
+		}
+		if txLimit == nil {
+			txLimit = highestStackLimit(cpm.profiles[0], v16.ChargingProfilePurposeTxDefaultProfile, at, txStart)
+		}
+	}
+
+	limits := []*ChargingLimit{
+		txLimit,
+		highestStackLimit(cpm.profiles[0], v16.ChargingProfilePurposeChargePointMaxProfile, at, txStart),
+		highestStackLimit(cpm.profiles[0], ChargingProfilePurposeExternalConstraints, at, txStart),
+	}
+	if connectorID > 0 {
+		limits = append(limits, highestStackLimit(cpm.profiles[connectorID], ChargingProfilePurposeExternalConstraints, at, txStart))
+	}
+
+	var lowest *ChargingLimit
+	for _, limit := range limits {
+		if limit != nil && (lowest == nil || limit.Watts() < lowest.Watts()) {
+			lowest = limit
+		}
+	}
+	if lowest == nil {
+		return ChargingLimit{}, false
+	}
+	return *lowest, true
+}
+
+// highestStackLimit returns the limit of the active profile with the highest stack level
+func highestStackLimit(profiles []*v16.ChargingProfile, purpose v16.ChargingProfilePurposeType, at time.Time, txStart *time.Time) *ChargingLimit {
+	var (
+		best      *ChargingLimit
+		bestStack = -1
+	)
+
+	for _, p := range profiles {
+		if p.ChargingProfilePurpose != purpose || p.StackLevel <= bestStack {
+			continue
+		}
+		if limit, ok := profileLimitAt(p, at, txStart); ok {
+			best = &limit
+			bestStack = p.StackLevel
+		}
+	}
+
+	return best
+}
+
+// profileLimitAt returns the limit a single profile imposes at the given time
+func profileLimitAt(p *v16.ChargingProfile, at time.Time, txStart *time.Time) (ChargingLimit, bool) {
+	if p.ValidFrom != nil && at.Before(p.ValidFrom.Time) {
+		return ChargingLimit{}, false
+	}
+	if p.ValidTo != nil && !at.Before(p.ValidTo.Time) {
+		return ChargingLimit{}, false
+	}
+
+	scheduleStart, ok := scheduleStartFor(p, at, txStart)
+	if !ok || at.Before(scheduleStart) {
+		return ChargingLimit{}, false
+	}
+
+	offset := int(at.Sub(scheduleStart).Seconds())
+	schedule := p.ChargingSchedule
+	if schedule.Duration != nil && offset >= *schedule.Duration {
+		return ChargingLimit{}, false
+	}
+
+	var active *v16.ChargingSchedulePeriod
+	for i := range schedule.ChargingSchedulePeriod {
+		period := &schedule.ChargingSchedulePeriod[i]
+		if period.StartPeriod <= offset && (active == nil || period.StartPeriod >= active.StartPeriod) {
+			active = period
+		}
+	}
+	if active == nil {
+		return ChargingLimit{}, false
+	}
+
+	phases := defaultNumberPhases
+	if active.NumberPhases != nil && *active.NumberPhases > 0 {
+		phases = *active.NumberPhases
+	}
+
+	return ChargingLimit{
+		Limit:        active.Limit,
+		Unit:         schedule.ChargingRateUnit,
+		NumberPhases: phases,
+	}, true
+}
+
+// scheduleStartFor resolves the absolute start of a profile's schedule relevant for the given time
+func scheduleStartFor(p *v16.ChargingProfile, at time.Time, txStart *time.Time) (time.Time, bool) {
+	switch p.ChargingProfileKind {
+	case v16.ChargingProfileKindRelative:
+		if txStart == nil {
+			return time.Time{}, false
+		}
+		return *txStart, true
+
+	case v16.ChargingProfileKindRecurring:
+		if p.ChargingSchedule.StartSchedule == nil {
+			return time.Time{}, false
+		}
+		period := 24 * time.Hour
+		if p.RecurrencyKind == v16.RecurrencyKindWeekly {
+			period = 7 * 24 * time.Hour
+		}
+		start := p.ChargingSchedule.StartSchedule.Time
+		if at.Before(start) {
+			return start, true
+		}
+		cycles := at.Sub(start) / period
+		return start.Add(cycles * period), true
+
+	default:
+		if p.ChargingSchedule.StartSchedule == nil {
+			// Absolute profile without start schedule starts when the transaction starts
+			if txStart != nil {
+				return *txStart, true
+			}
+			return time.Time{}, false
+		}
+		return p.ChargingSchedule.StartSchedule.Time, true
+	}
+}
+
+// profileBreakpoints returns the moments within [from, to) at which a profile's limit may change
+func profileBreakpoints(p *v16.ChargingProfile, from, to time.Time, txStart *time.Time) []time.Time {
+	var points []time.Time
+
+	if p.ValidFrom != nil {
+		points = append(points, p.ValidFrom.Time)
+	}
+	if p.ValidTo != nil {
+		points = append(points, p.ValidTo.Time)
+	}
+
+	// Walk through every schedule occurrence overlapping the window
+	cursor := from
+	for i := 0; i < 32 && cursor.Before(to); i++ {
+		start, ok := scheduleStartFor(p, cursor, txStart)
+		if !ok {
+			break
+		}
+
+		for _, period := range p.ChargingSchedule.ChargingSchedulePeriod {
+			points = append(points, start.Add(time.Duration(period.StartPeriod)*time.Second))
+		}
+		if p.ChargingSchedule.Duration != nil {
+			points = append(points, start.Add(time.Duration(*p.ChargingSchedule.Duration)*time.Second))
+		}
+
+		if p.ChargingProfileKind != v16.ChargingProfileKindRecurring {
+			break
+		}
+
+		next := start.Add(24 * time.Hour)
+		if p.RecurrencyKind == v16.RecurrencyKindWeekly {
+			next = start.Add(7 * 24 * time.Hour)
+		}
+		if !next.After(cursor) {
+			break
+		}
+		cursor = next
+	}
+
+	return points
+}
+
+// chargingProfileFromV201 converts an OCPP 2.0.1 charging profile to the stored representation.
+// Only the first charging schedule is used; further schedules are alternatives for ISO 15118.
+func chargingProfileFromV201(p v201.ChargingProfile) (v16.ChargingProfile, error) {
+	if len(p.ChargingSchedule) == 0 {
+		return v16.ChargingProfile{}, fmt.Errorf("charging profile has no charging schedule")
+	}
+	schedule := p.ChargingSchedule[0]
+
+	profile := v16.ChargingProfile{
+		ChargingProfileId:      p.Id,
+		StackLevel:             p.StackLevel,
+		ChargingProfilePurpose: chargingProfilePurposeFromV201(p.ChargingProfilePurpose),
+		ChargingProfileKind:    v16.ChargingProfileKindType(p.ChargingProfileKind),
+		RecurrencyKind:         v16.RecurrencyKindType(p.RecurrencyKind),
+		ChargingSchedule: v16.ChargingSchedule{
+			Duration:               schedule.Duration,
+			ChargingRateUnit:       v16.ChargingRateUnitType(schedule.ChargingRateUnit),
+			MinChargingRate:        schedule.MinChargingRate,
+			ChargingSchedulePeriod: make([]v16.ChargingSchedulePeriod, len(schedule.ChargingSchedulePeriod)),
+		},
+	}
+	if p.ValidFrom != nil {
+		profile.ValidFrom = &v16.DateTime{Time: p.ValidFrom.Time}
+	}
+	if p.ValidTo != nil {
+		profile.ValidTo = &v16.DateTime{Time: p.ValidTo.Time}
+	}
+	if schedule.StartSchedule != nil {
+		profile.ChargingSchedule.StartSchedule = &v16.DateTime{Time: schedule.StartSchedule.Time}
+	}
+	for i, period := range schedule.ChargingSchedulePeriod {
+		profile.ChargingSchedule.ChargingSchedulePeriod[i] = v16.ChargingSchedulePeriod{
+			StartPeriod:  period.StartPeriod,
+			Limit:        period.Limit,
+			NumberPhases: period.NumberPhases,
+		}
+	}
+
+	return profile, nil
+}
+
+// chargingProfileFromV21 converts an OCPP 2.1 charging profile to its OCPP 2.0.1 equivalent
+func chargingProfileFromV21(p v21.ChargingProfileType) (v201.ChargingProfile, error) {
+	profile := v201.ChargingProfile{
+		Id:                     p.ID,
+		StackLevel:             p.StackLevel,
+		ChargingProfilePurpose: v201.ChargingProfilePurposeType(p.ChargingProfilePurpose),
+		ChargingProfileKind:    v201.ChargingProfileKindType(p.ChargingProfileKind),
+		ChargingSchedule:       make([]v201.ChargingSchedule, len(p.ChargingSchedule)),
+	}
+	if p.RecurrencyKind != nil {
+		profile.RecurrencyKind = v201.RecurrencyKindType(*p.RecurrencyKind)
+	}
+	if p.TransactionId != nil {
+		profile.TransactionId = *p.TransactionId
+	}
+
+	var err error
+	if profile.ValidFrom, err = parseOptionalDateTime(p.ValidFrom); err != nil {
+		return v201.ChargingProfile{}, fmt.Errorf("invalid validFrom: %w", err)
+	}
+	if profile.ValidTo, err = parseOptionalDateTime(p.ValidTo); err != nil {
+		return v201.ChargingProfile{}, fmt.Errorf("invalid validTo: %w", err)
+	}
+
+	for i, schedule := range p.ChargingSchedule {
+		startSchedule, err := parseOptionalDateTime(schedule.StartSchedule)
+		if err != nil {
+			return v201.ChargingProfile{}, fmt.Errorf("invalid startSchedule: %w", err)
+		}
+
+		periods := make([]v201.ChargingSchedulePeriod, len(schedule.ChargingSchedulePeriod))
+		for j, period := range schedule.ChargingSchedulePeriod {
+			limit, err := periodLimitFromV21(period)
+			if err != nil {
+				return v201.ChargingProfile{}, fmt.Errorf("period %d: %w", j, err)
+			}
+			periods[j] = v201.ChargingSchedulePeriod{
+				StartPeriod:  period.StartPeriod,
+				Limit:        limit,
+				NumberPhases: period.NumberPhases,
+				PhaseToUse:   period.PhaseToUse,
+			}
+		}
+
+		profile.ChargingSchedule[i] = v201.ChargingSchedule{
+			Id:                     schedule.ID,
+			StartSchedule:          startSchedule,
+			Duration:               schedule.Duration,
+			ChargingRateUnit:       v201.ChargingRateUnitType(schedule.ChargingRateUnit),
+			MinChargingRate:        schedule.MinChargingRate,
+			ChargingSchedulePeriod: periods,
+		}
+	}
+
+	return profile, nil
+}
+
+// periodLimitFromV21 returns the limit of an OCPP 2.1 schedule period for its operation mode,
+// negative if the EV is to discharge. Setpoint modes follow the setpoint, the other discharge
+// modes the limit; both are capped by the discharge limit. Idle periods stop the energy transfer.
+func periodLimitFromV21(period v21.ChargingSchedulePeriodType) (float64, error) {
+	mode := v21.OperationModeChargingOnly
+	if period.OperationMode != nil {
+		mode = *period.OperationMode
+	}
+
+	limit := period.Limit
+	switch mode {
+	case v21.OperationModeChargingOnly:
+		if limit < 0 || (period.Setpoint != nil && *period.Setpoint < 0) || (period.DischargeLimit != nil && *period.DischargeLimit < 0) {
+			return 0, fmt.Errorf("operation mode %s does not allow discharging", mode)
+		}
+		return limit, nil
+	case v21.OperationModeIdle:
+		return 0, nil
+	case v21.OperationModeCentralSetpoint, v21.OperationModeExternalSetpoint:
+		if period.Setpoint != nil {
+			limit = *period.Setpoint
+		}
+	case v21.OperationModeExternalLimits, v21.OperationModeCentralFrequency,
+		v21.OperationModeLocalFrequency, v21.OperationModeLocalLoadBalancing:
+	default:
+		return 0, fmt.Errorf("unknown operation mode %s", mode)
+	}
+
+	if period.DischargeLimit != nil {
+		if *period.DischargeLimit > 0 {
+			return 0, fmt.Errorf("positive discharge limit %.1f", *period.DischargeLimit)
+		}
+		if limit < *period.DischargeLimit {
+			limit = *period.DischargeLimit
+		}
+	}
+	return limit, nil
+}
+
+// chargingProfilePurposeFromV201 maps an OCPP 2.0.1 purpose to the stored purpose
+func chargingProfilePurposeFromV201(purpose v201.ChargingProfilePurposeType) v16.ChargingProfilePurposeType {
+	switch purpose {
+	case v201.ChargingProfilePurposeChargingStationMaxProfile:
+		return v16.ChargingProfilePurposeChargePointMaxProfile
+	case v201.ChargingProfilePurposeChargingStationExternalConstraints:
+		return ChargingProfilePurposeExternalConstraints
+	default:
+		return v16.ChargingProfilePurposeType(purpose)
+	}
+}
+
+// chargingProfileToV21 converts a stored charging profile to OCPP 2.1. The stored profile keeps
+// a single charging schedule, which is reported with the ID of the profile.
+func chargingProfileToV21(p v16.ChargingProfile, transactionID string) v21.ChargingProfileType {
+	purpose := v21.ChargingProfilePurposeType(p.ChargingProfilePurpose)
+	switch p.ChargingProfilePurpose {
+	case v16.ChargingProfilePurposeChargePointMaxProfile:
+		purpose = v21.ChargingProfilePurposeChargingStationMaxProfile
+	case ChargingProfilePurposeExternalConstraints:
+		purpose = v21.ChargingProfilePurposeChargingStationExternalConstraints
+	}
+
+	schedule := v21.ChargingScheduleType{
+		ID:                     p.ChargingProfileId,
+		Duration:               p.ChargingSchedule.Duration,
+		ChargingRateUnit:       string(p.ChargingSchedule.ChargingRateUnit),
+		MinChargingRate:        p.ChargingSchedule.MinChargingRate,
+		ChargingSchedulePeriod: make([]v21.ChargingSchedulePeriodType, len(p.ChargingSchedule.ChargingSchedulePeriod)),
+	}
+	if p.ChargingSchedule.StartSchedule != nil {
+		start := p.ChargingSchedule.StartSchedule.Time.UTC().Format(time.RFC3339)
+		schedule.StartSchedule = &start
+	}
+	for i, period := range p.ChargingSchedule.ChargingSchedulePeriod {
+		schedule.ChargingSchedulePeriod[i] = v21.ChargingSchedulePeriodType{
+			StartPeriod:  period.StartPeriod,
+			Limit:        period.Limit,
+			NumberPhases: period.NumberPhases,
+		}
+	}
+
+	profile := v21.ChargingProfileType{
+		ID:                     p.ChargingProfileId,
+		StackLevel:             p.StackLevel,
+		ChargingProfilePurpose: purpose,
+		ChargingProfileKind:    v21.ChargingProfileKindType(p.ChargingProfileKind),
+		ChargingSchedule:       []v21.ChargingScheduleType{schedule},
+	}
+	if p.RecurrencyKind != "" {
+		recurrency := v21.RecurrencyKindType(p.RecurrencyKind)
+		profile.RecurrencyKind = &recurrency
+	}
+	if p.ValidFrom != nil {
+		validFrom := p.ValidFrom.Time.UTC().Format(time.RFC3339)
+		profile.ValidFrom = &validFrom
+	}
+	if p.ValidTo != nil {
+		validTo := p.ValidTo.Time.UTC().Format(time.RFC3339)
+		profile.ValidTo = &validTo
+	}
+	if transactionID != "" {
+		profile.TransactionId = &transactionID
+	}
+
+	return profile
+}
+
+// compositeScheduleToV201 converts a calculated composite schedule of an EVSE to OCPP 2.0.1
+func compositeScheduleToV201(evseID int, schedule *v16.ChargingSchedule) *v201.CompositeSchedule {
+	composite := &v201.CompositeSchedule{
+		EvseId:                 evseID,
+		ScheduleStart:          v201.DateTime{Time: schedule.StartSchedule.Time},
+		ChargingRateUnit:       v201.ChargingRateUnitType(schedule.ChargingRateUnit),
+		ChargingSchedulePeriod: make([]v201.ChargingSchedulePeriod, len(schedule.ChargingSchedulePeriod)),
+	}
+	if schedule.Duration != nil {
+		composite.Duration = *schedule.Duration
+	}
+	for i, period := range schedule.ChargingSchedulePeriod {
+		composite.ChargingSchedulePeriod[i] = v201.ChargingSchedulePeriod{
+			StartPeriod:  period.StartPeriod,
+			Limit:        period.Limit,
+			NumberPhases: period.NumberPhases,
+		}
+	}
+	return composite
+}
+
+// parseOptionalDateTime parses an optional RFC 3339 date-time of an OCPP 2.1 message
+func parseOptionalDateTime(value *string) (*v201.DateTime, error) {
+	if value == nil {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &v201.DateTime{Time: t}, nil
+}
+
+// validateChargingProfile checks a profile against the OCPP 1.6 rules for its purpose.
+// Negative limits are only valid when discharging is allowed.
+func validateChargingProfile(connectorID int, p *v16.ChargingProfile, allowDischarge bool) error {
+	if connectorID < 0 {
+		return fmt.Errorf("invalid connector id %d", connectorID)
+	}
+
+	switch p.ChargingProfilePurpose {
+	case v16.ChargingProfilePurposeChargePointMaxProfile:
+		if connectorID != 0 {
+			return fmt.Errorf("ChargePointMaxProfile can only be set on connector 0")
+		}
+	case v16.ChargingProfilePurposeTxProfile:
+		if connectorID == 0 {
+			return fmt.Errorf("TxProfile cannot be set on connector 0")
+		}
+	case v16.ChargingProfilePurposeTxDefaultProfile, ChargingProfilePurposeExternalConstraints:
+	default:
+		return fmt.Errorf("unknown charging profile purpose: %s", p.ChargingProfilePurpose)
+	}
+
+	switch p.ChargingProfileKind {
+	case v16.ChargingProfileKindAbsolute, v16.ChargingProfileKindRelative:
+	case v16.ChargingProfileKindRecurring:
+		if p.RecurrencyKind == "" {
+			return fmt.Errorf("recurring profile requires recurrencyKind")
+		}
+		if p.ChargingSchedule.StartSchedule == nil {
+			return fmt.Errorf("recurring profile requires startSchedule")
+		}
+	default:
+		return fmt.Errorf("unknown charging profile kind: %s", p.ChargingProfileKind)
+	}
+
+	if p.StackLevel < 0 {
+		return fmt.Errorf("invalid stack level %d", p.StackLevel)
+	}
+
+	unit := p.ChargingSchedule.ChargingRateUnit
+	if unit != v16.ChargingRateUnitA && unit != v16.ChargingRateUnitW {
+		return fmt.Errorf("invalid charging rate unit: %s", unit)
+	}
+
+	if len(p.ChargingSchedule.ChargingSchedulePeriod) == 0 {
+		return fmt.Errorf("charging schedule has no periods")
+	}
+
+	for i, period := range p.ChargingSchedule.ChargingSchedulePeriod {
+		if period.Limit < 0 && !allowDischarge {
+			return fmt.Errorf("period %d has negative limit", i)
+		}
+		if i == 0 && period.StartPeriod != 0 {
+			return fmt.Errorf("first period must start at 0")
+		}
+		if i > 0 && period.StartPeriod <= p.ChargingSchedule.ChargingSchedulePeriod[i-1].StartPeriod {
+			return fmt.Errorf("periods must be in increasing order of startPeriod")
+		}
+	}
+
+	return nil
+}
+
+// removeProfiles returns the list without the profiles matching the predicate
+func removeProfiles(list []*v16.ChargingProfile, match func(*v16.ChargingProfile) bool) []*v16.ChargingProfile {
+	result := list[:0]
+	for _, p := range list {
+		if !match(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,493 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func newTestProfile(id, stackLevel int, purpose v16.ChargingProfilePurposeType, unit v16.ChargingRateUnitType, limits ...float64) v16.ChargingProfile {
+	periods := make([]v16.ChargingSchedulePeriod, len(limits))
+	for i, limit := range limits {
+		periods[i] = v16.ChargingSchedulePeriod{StartPeriod: i * 600, Limit: limit}
+	}
+
+	return v16.ChargingProfile{
+		ChargingProfileId:      id,
+		StackLevel:             stackLevel,
+		ChargingProfilePurpose: purpose,
+		ChargingProfileKind:    v16.ChargingProfileKindRelative,
+		ChargingSchedule: v16.ChargingSchedule{
+			ChargingRateUnit:       unit,
+			ChargingSchedulePeriod: periods,
+		},
+	}
+}
+
+func TestChargingProfileManager_SetProfileValidation(t *testing.T) {
+	cpm := NewChargingProfileManager()
+
+	maxProfile := newTestProfile(1, 0, v16.ChargingProfilePurposeChargePointMaxProfile, v16.ChargingRateUnitW, 11000)
+	if err := cpm.SetProfile(1, maxProfile); err == nil {
+		t.Error("Expected error for ChargePointMaxProfile on connector 1")
+	}
+	if err := cpm.SetProfile(0, maxProfile); err != nil {
+		t.Errorf("Expected ChargePointMaxProfile on connector 0 to be accepted: %v", err)
+	}
+
+	txProfile := newTestProfile(2, 0, v16.ChargingProfilePurposeTxProfile, v16.ChargingRateUnitA, 16)
+	if err := cpm.SetProfile(0, txProfile); err == nil {
+		t.Error("Expected error for TxProfile on connector 0")
+	}
+
+	invalid := newTestProfile(3, 0, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 11000)
+	invalid.ChargingSchedule.ChargingSchedulePeriod[0].StartPeriod = 10
+	if err := cpm.SetProfile(1, invalid); err == nil {
+		t.Error("Expected error for schedule not starting at 0")
+	}
+}
+
+func TestChargingProfileManager_ReplaceSameStackLevel(t *testing.T) {
+	cpm := NewChargingProfileManager()
+
+	cpm.SetProfile(1, newTestProfile(1, 2, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 11000))
+	cpm.SetProfile(1, newTestProfile(2, 2, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 7000))
+
+	profiles := cpm.GetProfiles(1)
+	if len(profiles) != 1 {
+		t.Fatalf("Expected 1 profile after replacing stack level, got %d", len(profiles))
+	}
+	if profiles[0].ChargingProfileId != 2 {
+		t.Errorf("Expected profile 2 to remain, got %d", profiles[0].ChargingProfileId)
+	}
+}
+
+func TestChargingProfileManager_Precedence(t *testing.T) {
+	cpm := NewChargingProfileManager()
+	now := time.Now()
+	txStart := now.Add(-time.Minute)
+
+	// Station-wide default, connector-specific default and TxProfile
+	cpm.SetProfile(0, newTestProfile(1, 0, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 20000))
+	limit, ok := cpm.GetLimit(1, now, &txStart)
+	if !ok || limit.Watts() != 20000 {
+		t.Errorf("Expected station-wide TxDefaultProfile limit 20000, got %v (ok=%v)", limit.Watts(), ok)
+	}
+
+	cpm.SetProfile(1, newTestProfile(2, 0, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 15000))
+	cpm.SetProfile(1, newTestProfile(3, 1, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 12000))
+	limit, _ = cpm.GetLimit(1, now, &txStart)
+	if limit.Watts() != 12000 {
+		t.Errorf("Expected highest stack level limit 12000, got %v", limit.Watts())
+	}
+
+	cpm.SetProfile(1, newTestProfile(4, 0, v16.ChargingProfilePurposeTxProfile, v16.ChargingRateUnitA, 10))
+	limit, _ = cpm.GetLimit(1, now, &txStart)
+	if limit.Watts() != 10*nominalVoltage*3 {
+		t.Errorf("Expected TxProfile limit %v W, got %v", 10*nominalVoltage*3, limit.Watts())
+	}
+
+	// ChargePointMaxProfile caps everything
+	cpm.SetProfile(0, newTestProfile(5, 0, v16.ChargingProfilePurposeChargePointMaxProfile, v16.ChargingRateUnitW, 5000))
+	limit, _ = cpm.GetLimit(1, now, &txStart)
+	if limit.Watts() != 5000 {
+		t.Errorf("Expected ChargePointMaxProfile cap 5000, got %v", limit.Watts())
+	}
+
+	// TxProfile only applies during a transaction
+	cpm.ClearProfiles(nil, nil, v16.ChargingProfilePurposeChargePointMaxProfile, nil)
+	cpm.ClearTxProfiles(1)
+	limit, _ = cpm.GetLimit(1, now, &txStart)
+	if limit.Watts() != 12000 {
+		t.Errorf("Expected TxDefaultProfile limit 12000 after clearing TxProfile, got %v", limit.Watts())
+	}
+}
+
+func TestChargingProfileManager_ClearProfiles(t *testing.T) {
+	cpm := NewChargingProfileManager()
+
+	cpm.SetProfile(0, newTestProfile(1, 0, v16.ChargingProfilePurposeChargePointMaxProfile, v16.ChargingRateUnitW, 22000))
+	cpm.SetProfile(1, newTestProfile(2, 0, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 11000))
+	cpm.SetProfile(2, newTestProfile(3, 1, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 11000))
+
+	id := 2
+	if removed := cpm.ClearProfiles(&id, nil, "", nil); removed != 1 {
+		t.Errorf("Expected 1 profile removed by id, got %d", removed)
+	}
+
+	stackLevel := 5
+	if removed := cpm.ClearProfiles(nil, nil, "", &stackLevel); removed != 0 {
+		t.Errorf("Expected no profile removed for unknown stack level, got %d", removed)
+	}
+
+	if removed := cpm.ClearProfiles(nil, nil, "", nil); removed != 2 {
+		t.Errorf("Expected 2 profiles removed when clearing all, got %d", removed)
+	}
+}
+
+func TestChargingProfileManager_CompositeSchedule(t *testing.T) {
+	cpm := NewChargingProfileManager()
+	now := time.Now().Truncate(time.Second)
+
+	// TxDefault: 16A for 10 minutes, then 8A
+	cpm.SetProfile(1, newTestProfile(1, 0, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitA, 16, 8))
+
+	// Station max: 7360W (~10.7A on 3 phases) starting 5 minutes from now
+	start := v16.DateTime{Time: now.Add(5 * time.Minute)}
+	maxProfile := newTestProfile(2, 0, v16.ChargingProfilePurposeChargePointMaxProfile, v16.ChargingRateUnitW, 7360)
+	maxProfile.ChargingProfileKind = v16.ChargingProfileKindAbsolute
+	maxProfile.ChargingSchedule.StartSchedule = &start
+	cpm.SetProfile(0, maxProfile)
+
+	schedule := cpm.GetCompositeSchedule(1, now, 1800, v16.ChargingRateUnitA, &now)
+
+	expected := []struct {
+		start int
+		limit float64
+	}{
+		{0, 16},
+		{300, 10.6},
+		{600, 8},
+	}
+
+	if len(schedule.ChargingSchedulePeriod) != len(expected) {
+		t.Fatalf("Expected %d periods, got %d: %+v", len(expected), len(schedule.ChargingSchedulePeriod), schedule.ChargingSchedulePeriod)
+	}
+
+	for i, e := range expected {
+		period := schedule.ChargingSchedulePeriod[i]
+		if period.StartPeriod != e.start || period.Limit != e.limit {
+			t.Errorf("Period %d: expected start %d limit %v, got start %d limit %v", i, e.start, e.limit, period.StartPeriod, period.Limit)
+		}
+	}
+}
+
+func TestHandleGetCompositeSchedule_NoProfiles(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm := NewSessionManager("CP001", []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+		{ID: 2, Type: "Type2", MaxPower: 11000, Status: "Available"},
+	}, logger)
+	defer sm.Shutdown(context.Background())
+
+	manager.mu.Lock()
+	manager.stations["CP001"] = &Station{
+		Config:         Config{StationID: "CP001", ProtocolVersion: "ocpp1.6"},
+		SessionManager: sm,
+	}
+	manager.mu.Unlock()
+
+	// Without profiles the schedule has a single period at the maximum power of the hardware
+	for _, tc := range []struct {
+		connectorID int
+		unit        v16.ChargingRateUnitType
+		limit       float64
+	}{
+		{1, v16.ChargingRateUnitW, 22000},
+		{1, v16.ChargingRateUnitA, 31.8},
+		{0, v16.ChargingRateUnitW, 33000},
+	} {
+		resp, _ := manager.v16Handler.OnGetCompositeSchedule("CP001", &v16.GetCompositeScheduleRequest{
+			ConnectorId:      tc.connectorID,
+			Duration:         3600,
+			ChargingRateUnit: tc.unit,
+		})
+		if resp.Status != v16.GetCompositeScheduleStatusAccepted || resp.ChargingSchedule == nil {
+			t.Fatalf("Expected a composite schedule for connector %d, got %+v", tc.connectorID, resp)
+		}
+		periods := resp.ChargingSchedule.ChargingSchedulePeriod
+		if len(periods) != 1 || periods[0].StartPeriod != 0 || periods[0].Limit != tc.limit {
+			t.Errorf("Expected a single period of %v %s for connector %d, got %+v", tc.limit, tc.unit, tc.connectorID, periods)
+		}
+	}
+}
+
+func TestChargingProfileManager_ExternalConstraints(t *testing.T) {
+	cpm := NewChargingProfileManager()
+	now := time.Now()
+	txStart := now.Add(-time.Minute)
+
+	cpm.SetProfile(1, newTestProfile(1, 0, v16.ChargingProfilePurposeTxDefaultProfile, v16.ChargingRateUnitW, 11000))
+	if err := cpm.SetProfile(0, newTestProfile(2, 0, ChargingProfilePurposeExternalConstraints, v16.ChargingRateUnitW, 9000)); err != nil {
+		t.Fatalf("Expected station-wide external constraints to be accepted: %v", err)
+	}
+	if err := cpm.SetProfile(1, newTestProfile(3, 0, ChargingProfilePurposeExternalConstraints, v16.ChargingRateUnitW, 7000)); err != nil {
+		t.Fatalf("Expected connector external constraints to be accepted: %v", err)
+	}
+
+	limit, ok := cpm.GetLimit(1, now, &txStart)
+	if !ok || limit.Watts() != 7000 {
+		t.Errorf("Expected the lowest external constraint 7000, got %v (ok=%v)", limit.Watts(), ok)
+	}
+
+	// External constraints are kept when the CSMS clears all profiles
+	if removed := cpm.ClearProfiles(nil, nil, "", nil); removed != 1 {
+		t.Errorf("Expected only the TxDefaultProfile to be cleared, got %d", removed)
+	}
+	limit, ok = cpm.GetLimit(1, now, &txStart)
+	if !ok || limit.Watts() != 7000 {
+		t.Errorf("Expected external constraints to remain, got %v (ok=%v)", limit.Watts(), ok)
+	}
+
+	connectorID := 1
+	if removed := cpm.ClearProfiles(nil, &connectorID, ChargingProfilePurposeExternalConstraints, nil); removed != 1 {
+		t.Errorf("Expected the connector external constraint to be cleared, got %d", removed)
+	}
+	limit, _ = cpm.GetLimit(1, now, &txStart)
+	if limit.Watts() != 9000 {
+		t.Errorf("Expected the station-wide external constraint 9000, got %v", limit.Watts())
+	}
+}
+
+func TestHandleV201SmartCharging(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	manager.mu.Lock()
+	manager.stations["CP001"] = &Station{
+		Config:         Config{StationID: "CP001", ProtocolVersion: "ocpp2.0.1"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: sm,
+	}
+	manager.mu.Unlock()
+
+	now := time.Now()
+	profile := func(id int, purpose v201.ChargingProfilePurposeType, unit v201.ChargingRateUnitType, limits ...float64) v201.ChargingProfile {
+		periods := make([]v201.ChargingSchedulePeriod, len(limits))
+		for i, limit := range limits {
+			periods[i] = v201.ChargingSchedulePeriod{StartPeriod: i * 600, Limit: limit}
+		}
+		return v201.ChargingProfile{
+			Id:                     id,
+			ChargingProfilePurpose: purpose,
+			ChargingProfileKind:    v201.ChargingProfileKindAbsolute,
+			ChargingSchedule: []v201.ChargingSchedule{{
+				Id:                     1,
+				StartSchedule:          &v201.DateTime{Time: now},
+				ChargingRateUnit:       unit,
+				ChargingSchedulePeriod: periods,
+			}},
+		}
+	}
+
+	set := func(evseID int, p v201.ChargingProfile) v201.ChargingProfileStatusType {
+		resp, _ := manager.handleV201SetChargingProfile("CP001", &v201.SetChargingProfileRequest{EvseId: evseID, ChargingProfile: p})
+		return resp.Status
+	}
+
+	if status := set(0, profile(1, v201.ChargingProfilePurposeChargingStationMaxProfile, v201.ChargingRateUnitW, 11000)); status != v201.ChargingProfileStatusAccepted {
+		t.Errorf("Expected ChargingStationMaxProfile to be accepted, got %s", status)
+	}
+	if status := set(1, profile(2, v201.ChargingProfilePurposeTxDefaultProfile, v201.ChargingRateUnitA, 16, 6)); status != v201.ChargingProfileStatusAccepted {
+		t.Errorf("Expected TxDefaultProfile to be accepted, got %s", status)
+	}
+	if status := set(0, profile(3, v201.ChargingProfilePurposeChargingStationExternalConstraints, v201.ChargingRateUnitW, 5000)); status != v201.ChargingProfileStatusRejected {
+		t.Errorf("Expected external constraints from the CSMS to be rejected, got %s", status)
+	}
+
+	// 16 A on 3 phases is capped by the station maximum, then 6 A (4140 W)
+	resp, _ := manager.handleV201GetCompositeSchedule("CP001", &v201.GetCompositeScheduleRequest{EvseId: 1, Duration: 1200, ChargingRateUnit: v201.ChargingRateUnitW})
+	if resp.Status != v201.GenericStatusAccepted || resp.Schedule == nil {
+		t.Fatalf("Expected a composite schedule, got %+v", resp)
+	}
+	periods := resp.Schedule.ChargingSchedulePeriod
+	if len(periods) != 2 || periods[0].Limit != 11000 || periods[1].Limit != 4140 || periods[1].StartPeriod < 598 {
+		t.Errorf("Expected periods 11000 W and 4140 W after 600 s, got %+v", periods)
+	}
+
+	unknown, _ := manager.handleV201GetCompositeSchedule("CP001", &v201.GetCompositeScheduleRequest{EvseId: 9, Duration: 60})
+	if unknown.Status != v201.GenericStatusRejected {
+		t.Errorf("Expected unknown EVSE to be rejected, got %s", unknown.Status)
+	}
+
+	// A TxProfile must reference the active transaction
+	txProfile := profile(4, v201.ChargingProfilePurposeTxProfile, v201.ChargingRateUnitW, 3000)
+	txProfile.TransactionId = "unknown"
+	if status := set(1, txProfile); status != v201.ChargingProfileStatusRejected {
+		t.Errorf("Expected TxProfile without transaction to be rejected, got %s", status)
+	}
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	connector, _ := sm.GetConnector(1)
+	txProfile.TransactionId = connector.GetTransaction().StringID
+	if status := set(1, txProfile); status != v201.ChargingProfileStatusAccepted {
+		t.Errorf("Expected TxProfile for the active transaction to be accepted, got %s", status)
+	}
+	if limit, ok := sm.GetPowerLimit(1); !ok || limit != 3000 {
+		t.Errorf("Expected the TxProfile to limit the EVSE to 3000 W, got %v (ok=%v)", limit, ok)
+	}
+
+	clearProfiles := func(purpose v201.ChargingProfilePurposeType) v201.ClearChargingProfileStatusType {
+		resp, _ := manager.handleV201ClearChargingProfile("CP001", &v201.ClearChargingProfileRequest{
+			ChargingProfileCriteria: &v201.ClearChargingProfile{ChargingProfilePurpose: purpose},
+		})
+		return resp.Status
+	}
+	if status := clearProfiles(v201.ChargingProfilePurposeChargingStationMaxProfile); status != v201.ClearChargingProfileStatusAccepted {
+		t.Errorf("Expected ChargingStationMaxProfile to be cleared, got %s", status)
+	}
+	if status := clearProfiles(v201.ChargingProfilePurposeChargingStationMaxProfile); status != v201.ClearChargingProfileStatusUnknown {
+		t.Errorf("Expected Unknown when no profile matches, got %s", status)
+	}
+}
+
+func TestHandleV21GetChargingProfiles(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm, _ := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	station := &Station{
+		Config:         Config{StationID: "CP001", ProtocolVersion: "ocpp2.1"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: sm,
+	}
+	manager.mu.Lock()
+	manager.stations["CP001"] = station
+	manager.mu.Unlock()
+
+	reported := make(chan *v21.ReportChargingProfilesRequest, 4)
+	manager.v21Handler.SendMessage = func(stationID string, data []byte) error {
+		var msg []json.RawMessage
+		var req v21.ReportChargingProfilesRequest
+		if err := json.Unmarshal(data, &msg); err == nil && len(msg) == 4 && json.Unmarshal(msg[3], &req) == nil {
+			reported <- &req
+		}
+		return nil
+	}
+
+	get := func(req *v21.GetChargingProfilesRequest) string {
+		resp, _ := manager.v21Handler.OnGetChargingProfiles("CP001", req)
+		return resp.Status
+	}
+	if status := get(&v21.GetChargingProfilesRequest{RequestId: 1}); status != "NoProfiles" {
+		t.Errorf("Expected NoProfiles without installed profiles, got %s", status)
+	}
+
+	set := func(evseID, id int, purpose v21.ChargingProfilePurposeType, limit float64) {
+		resp, _ := manager.v21Handler.OnSetChargingProfile("CP001", &v21.SetChargingProfileRequest{
+			EvseId: evseID,
+			ChargingProfile: v21.ChargingProfileType{
+				ID:                     id,
+				ChargingProfilePurpose: purpose,
+				ChargingProfileKind:    v21.ChargingProfileKindAbsolute,
+				ChargingSchedule: []v21.ChargingScheduleType{{
+					ID:                     1,
+					ChargingRateUnit:       "W",
+					ChargingSchedulePeriod: []v21.ChargingSchedulePeriodType{{Limit: limit}},
+				}},
+			},
+		})
+		if resp.Status != v21.ChargingProfileStatusAccepted {
+			t.Fatalf("Expected profile %d to be accepted, got %s", id, resp.Status)
+		}
+	}
+	set(0, 1, v21.ChargingProfilePurposeChargingStationMaxProfile, 11000)
+	set(1, 2, v21.ChargingProfilePurposeTxDefaultProfile, 7400)
+
+	// All profiles are reported per EVSE once the response has been sent
+	if status := get(&v21.GetChargingProfilesRequest{RequestId: 2}); status != "Accepted" {
+		t.Fatalf("Expected Accepted with installed profiles, got %s", status)
+	}
+	manager.runAfterResponseActions(station)
+	for _, expected := range []struct {
+		evseID, profileID int
+		purpose           v21.ChargingProfilePurposeType
+		tbc               bool
+	}{
+		{0, 1, v21.ChargingProfilePurposeChargingStationMaxProfile, true},
+		{1, 2, v21.ChargingProfilePurposeTxDefaultProfile, false},
+	} {
+		select {
+		case report := <-reported:
+			if report.RequestId != 2 || report.EvseId != expected.evseID || report.Tbc != expected.tbc || report.ChargingLimitSource != "CSO" ||
+				len(report.ChargingProfile) != 1 || report.ChargingProfile[0].ID != expected.profileID || report.ChargingProfile[0].ChargingProfilePurpose != expected.purpose {
+				t.Errorf("Expected profile %d of EVSE %d, got %+v", expected.profileID, expected.evseID, report)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for ReportChargingProfiles")
+		}
+	}
+
+	// The criteria select the profiles to report
+	purpose := v21.ChargingProfilePurposeTxDefaultProfile
+	reports := manager.chargingProfileReports(station, &v21.GetChargingProfilesRequest{
+		RequestId:       3,
+		ChargingProfile: &v21.ChargingProfileCriterionType{ChargingProfilePurpose: &purpose},
+	})
+	if len(reports) != 1 || reports[0].EvseId != 1 || reports[0].Tbc || reports[0].ChargingProfile[0].ChargingSchedule[0].ChargingSchedulePeriod[0].Limit != 7400 {
+		t.Errorf("Expected the TxDefaultProfile of EVSE 1, got %+v", reports)
+	}
+	evseID := 2
+	if status := get(&v21.GetChargingProfilesRequest{RequestId: 4, EvseId: &evseID}); status != "NoProfiles" {
+		t.Errorf("Expected NoProfiles for an EVSE without profiles, got %s", status)
+	}
+}
+
+func TestSessionManager_SmartChargingThrottlesMeterValues(t *testing.T) {
+	connectorConfigs := []ConnectorConfig{
+		{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"},
+	}
+
+	sm := NewSessionManager("CP001", connectorConfigs, slog.Default())
+	allowOfflineTx(sm)
+
+	if _, err := sm.StartCharging(1, "TAG001"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	defer sm.Shutdown(context.Background())
+
+	// TxProfile requires an active transaction with the matching ID
+	wrongTx := 999
+	profile := newTestProfile(1, 0, v16.ChargingProfilePurposeTxProfile, v16.ChargingRateUnitW, 3000)
+	profile.TransactionId = &wrongTx
+	if err := sm.SetChargingProfile(1, profile); err == nil {
+		t.Error("Expected TxProfile for unknown transaction to be rejected")
+	}
+
+	profile.TransactionId = nil
+	if err := sm.SetChargingProfile(1, profile); err != nil {
+		t.Fatalf("SetChargingProfile failed: %v", err)
+	}
+
+	var reported []v16.MeterValue
+	sm.SendMeterValues = func(connectorID int, transactionID *int, meterValues []v16.MeterValue) error {
+		reported = meterValues
+		return nil
+	}
+
+	connector, _ := sm.GetConnector(1)
+	sm.sendMeterValue(connector)
+
+	if len(reported) == 0 {
+		t.Fatal("Expected meter values to be sent")
+	}
+	for _, sv := range reported[0].SampledValue {
+		if sv.Measurand == v16.MeasurandPowerActiveImport && sv.Value != "3000" {
+			t.Errorf("Expected power to be throttled to 3000 W, got %s", sv.Value)
+		}
+	}
+
+	// Zero limit suspends charging
+	profile.ChargingSchedule.ChargingSchedulePeriod[0].Limit = 0
+	sm.SetChargingProfile(1, profile)
+	sm.sendMeterValue(connector)
+	if connector.GetState() != ConnectorStateSuspendedEVSE {
+		t.Errorf("Expected SuspendedEVSE with zero limit, got %s", connector.GetState())
+	}
+}
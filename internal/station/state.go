@@ -38,6 +38,9 @@ const (
 
 	// StateStopping means station is being stopped
 	StateStopping State = "stopping"
+
+	// StateResetting means station is performing a reset requested by the CSMS
+	StateResetting State = "resetting"
 )
 
 // StateMachine manages state transitions for a station
@@ -127,12 +130,13 @@ func (sm *StateMachine) CanTransition(to State) bool {
 		StateDisconnected: {StateConnecting, StateFaulted},
 		StateConnecting:   {StateConnected, StateDisconnected, StateFaulted},
 		StateConnected:    {StateRegistered, StateDisconnected, StateFaulted},
-		StateRegistered:   {StateAvailable, StateDisconnected, StateFaulted},
-		StateAvailable:    {StateCharging, StateUnavailable, StateDisconnected, StateFaulted, StateStopping},
-		StateCharging:     {StateAvailable, StateDisconnected, StateFaulted, StateStopping},
-		StateFaulted:      {StateAvailable, StateDisconnected, StateUnavailable},
-		StateUnavailable:  {StateAvailable, StateDisconnected},
+		StateRegistered:   {StateAvailable, StateDisconnected, StateFaulted, StateResetting},
+		StateAvailable:    {StateCharging, StateUnavailable, StateDisconnected, StateFaulted, StateStopping, StateResetting},
+		StateCharging:     {StateAvailable, StateDisconnected, StateFaulted, StateStopping, StateResetting},
+		StateFaulted:      {StateAvailable, StateDisconnected, StateUnavailable, StateResetting},
+		StateUnavailable:  {StateAvailable, StateDisconnected, StateResetting},
 		StateStopping:     {StateDisconnected},
+		StateResetting:    {StateStopping, StateDisconnected},
 	}
 
 	allowedStates, exists := validTransitions[sm.currentState]
@@ -0,0 +1,693 @@
+package station
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// evseKindAC is the kind of the simulated EVSEs in tariff conditions
+const evseKindAC = "AC"
+
+// TariffManager holds the OCPP 2.1 tariffs of a station: the default tariffs of its EVSEs, the
+// driver tariffs received with authorizations and the tariffs of running transactions, whose
+// cost is calculated locally
+type TariffManager struct {
+	mu           sync.RWMutex
+	defaults     map[int][]v21.TariffType  // EVSE ID (0 for all EVSEs) -> default tariffs
+	drivers      map[string]v21.TariffType // idToken -> tariff of its last authorization
+	transactions map[int]*transactionCost  // EVSE ID -> cost of the running transaction
+}
+
+// NewTariffManager creates a tariff manager without tariffs
+func NewTariffManager() *TariffManager {
+	return &TariffManager{
+		defaults:     make(map[int][]v21.TariffType),
+		drivers:      make(map[string]v21.TariffType),
+		transactions: make(map[int]*transactionCost),
+	}
+}
+
+// SetDefault sets the default tariff of an EVSE, 0 for all EVSEs. A tariff replaces the tariff
+// with the same id or validFrom, tariffs with a later validFrom take over at that time.
+func (tm *TariffManager) SetDefault(evseID int, tariff v21.TariffType) error {
+	if err := validateTariff(tariff); err != nil {
+		return err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	validFrom := tariffValidFrom(tariff)
+	tariffs := make([]v21.TariffType, 0, len(tm.defaults[evseID])+1)
+	for _, existing := range tm.defaults[evseID] {
+		if existing.TariffId != tariff.TariffId && !tariffValidFrom(existing).Equal(validFrom) {
+			tariffs = append(tariffs, existing)
+		}
+	}
+	tariffs = append(tariffs, tariff)
+
+	sort.Slice(tariffs, func(i, j int) bool { return tariffValidFrom(tariffs[i]).Before(tariffValidFrom(tariffs[j])) })
+	tm.defaults[evseID] = tariffs
+	return nil
+}
+
+// SetDriverTariff sets the tariff the CSMS returned with the authorization of an idToken, it
+// applies to the next transaction of the idToken. A nil tariff removes it.
+func (tm *TariffManager) SetDriverTariff(idToken string, tariff *v21.TariffType) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tariff == nil {
+		delete(tm.drivers, idToken)
+		return nil
+	}
+	if err := validateTariff(*tariff); err != nil {
+		return err
+	}
+	tm.drivers[idToken] = *tariff
+	return nil
+}
+
+// Assignments returns the tariffs in use on an EVSE, 0 for all EVSEs: the default tariffs and
+// the driver tariffs of running transactions
+func (tm *TariffManager) Assignments(evseID int) []v21.TariffAssignmentType {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	evseIDs := make([]int, 0, len(tm.defaults))
+	for id := range tm.defaults {
+		if evseID == 0 || id == 0 || id == evseID {
+			evseIDs = append(evseIDs, id)
+		}
+	}
+	sort.Ints(evseIDs)
+
+	var assignments []v21.TariffAssignmentType
+	for _, id := range evseIDs {
+		for _, tariff := range tm.defaults[id] {
+			assignment := v21.TariffAssignmentType{
+				TariffId:   tariff.TariffId,
+				TariffKind: v21.TariffKindDefaultTariff,
+				ValidFrom:  tariff.ValidFrom,
+			}
+			if id != 0 {
+				assignment.EvseIds = []int{id}
+			}
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	txEVSEs := make([]int, 0, len(tm.transactions))
+	for id, tc := range tm.transactions {
+		if tc.kind == v21.TariffKindDriverTariff && (evseID == 0 || id == evseID) {
+			txEVSEs = append(txEVSEs, id)
+		}
+	}
+	sort.Ints(txEVSEs)
+
+	for _, id := range txEVSEs {
+		tc := tm.transactions[id]
+		assignments = append(assignments, v21.TariffAssignmentType{
+			TariffId:   tc.tariff.TariffId,
+			TariffKind: v21.TariffKindDriverTariff,
+			EvseIds:    []int{id},
+			IdTokens:   []string{tc.idToken},
+		})
+	}
+
+	return assignments
+}
+
+// ClearDefaults removes default tariffs, all tariffs if no ids are given. With an EVSE only
+// its tariffs are removed. Running transactions keep their tariff.
+func (tm *TariffManager) ClearDefaults(tariffIDs []string, evseID *int) []v21.ClearTariffsResultType {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	removed := make(map[string]bool)
+	for id, tariffs := range tm.defaults {
+		if evseID != nil && id != *evseID {
+			continue
+		}
+
+		kept := tariffs[:0]
+		for _, tariff := range tariffs {
+			if len(tariffIDs) == 0 || containsString(tariffIDs, tariff.TariffId) {
+				removed[tariff.TariffId] = true
+				continue
+			}
+			kept = append(kept, tariff)
+		}
+		if len(kept) == 0 {
+			delete(tm.defaults, id)
+		} else {
+			tm.defaults[id] = kept
+		}
+	}
+
+	if len(tariffIDs) == 0 {
+		if len(removed) == 0 {
+			return []v21.ClearTariffsResultType{{Status: v21.TariffClearStatusNoTariff}}
+		}
+		return []v21.ClearTariffsResultType{{Status: v21.TariffClearStatusAccepted}}
+	}
+
+	results := make([]v21.ClearTariffsResultType, 0, len(tariffIDs))
+	for _, tariffID := range tariffIDs {
+		status := v21.TariffClearStatusNoTariff
+		if removed[tariffID] {
+			status = v21.TariffClearStatusAccepted
+		}
+		results = append(results, v21.ClearTariffsResultType{TariffId: &tariffID, Status: status})
+	}
+	return results
+}
+
+// ChangeTransactionTariff replaces the tariff of a running transaction. The cost so far is kept,
+// the new tariff applies from now on and must use the same currency.
+func (tm *TariffManager) ChangeTransactionTariff(transactionID string, tariff v21.TariffType, now time.Time) (v21.TariffChangeStatusType, error) {
+	if err := validateTariff(tariff); err != nil {
+		return v21.TariffChangeStatusRejected, err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	for _, tc := range tm.transactions {
+		if tc.transactionID != transactionID {
+			continue
+		}
+		if tc.tariff.Currency != tariff.Currency {
+			return v21.TariffChangeStatusNoCurrencyChange, fmt.Errorf("transaction is charged in %s", tc.tariff.Currency)
+		}
+		tc.add(tc.lastMeterWh, tc.powerWatts, tc.usage, now)
+		tc.tariff = tariff
+		return v21.TariffChangeStatusAccepted, nil
+	}
+	return v21.TariffChangeStatusTxNotFound, fmt.Errorf("unknown transaction %s", transactionID)
+}
+
+// RunningCost returns the tariff and cost of the transaction on an EVSE, nil without
+// transaction or tariff
+func (tm *TariffManager) RunningCost(evseID int) (string, *v21.CostDetailsType) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	tc := tm.transactions[evseID]
+	if tc == nil {
+		return "", nil
+	}
+	details := tc.details()
+	return tc.tariff.TariffId, &details
+}
+
+// startTransaction starts the cost calculation of a transaction with the driver tariff of its
+// idToken, or else the default tariff of the EVSE. Returns false if no tariff applies.
+func (tm *TariffManager) startTransaction(evseID int, transactionID, idToken string, meterWh int, now time.Time) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tariff, ok := tm.drivers[idToken]
+	kind := v21.TariffKindDriverTariff
+	if ok {
+		delete(tm.drivers, idToken)
+	} else {
+		if tariff, ok = tm.defaultTariff(evseID, now); !ok {
+			return false
+		}
+		kind = v21.TariffKindDefaultTariff
+	}
+
+	tc := &transactionCost{
+		transactionID: transactionID,
+		tariff:        tariff,
+		kind:          kind,
+		idToken:       idToken,
+		start:         now,
+		last:          now,
+		lastMeterWh:   meterWh,
+		usage:         usageCharging,
+	}
+	if tariff.FixedFee != nil {
+		for _, price := range tariff.FixedFee.Prices {
+			if tariffConditionsMet(price.Conditions, tc.state(now, 0)) {
+				tc.fixed = price.PriceFixed
+				break
+			}
+		}
+	}
+	tm.transactions[evseID] = tc
+	return true
+}
+
+// update adds the usage since the last update to the cost of the transaction on an EVSE and
+// returns its cost, nil if the transaction has no tariff
+func (tm *TariffManager) update(evseID, meterWh, powerWatts int, now time.Time) *v21.CostDetailsType {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tc := tm.transactions[evseID]
+	if tc == nil {
+		return nil
+	}
+	tc.add(meterWh, powerWatts, usageOf(powerWatts), now)
+
+	details := tc.details()
+	return &details
+}
+
+// endTransaction returns the final cost of the transaction on an EVSE and ends its calculation,
+// nil if the transaction has no tariff
+func (tm *TariffManager) endTransaction(evseID, meterWh int, now time.Time) *v21.CostDetailsType {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tc := tm.transactions[evseID]
+	if tc == nil {
+		return nil
+	}
+	delete(tm.transactions, evseID)
+	tc.add(meterWh, tc.powerWatts, tc.usage, now)
+
+	details := tc.details()
+	return &details
+}
+
+// defaultTariff returns the default tariff in effect on an EVSE, falling back to the tariffs
+// for all EVSEs
+func (tm *TariffManager) defaultTariff(evseID int, now time.Time) (v21.TariffType, bool) {
+	for _, id := range []int{evseID, 0} {
+		tariffs := tm.defaults[id]
+		for i := len(tariffs) - 1; i >= 0; i-- {
+			if !tariffValidFrom(tariffs[i]).After(now) {
+				return tariffs[i], true
+			}
+		}
+	}
+	return v21.TariffType{}, false
+}
+
+// tariffState is the state of a transaction the conditions of tariff prices are evaluated in
+type tariffState struct {
+	at           time.Time
+	energyWh     float64
+	powerWatts   int
+	duration     time.Duration
+	chargingTime time.Duration
+	idleTime     time.Duration
+}
+
+// evseUsage is how an EVSE was used in an interval between meter readings
+type evseUsage int
+
+const (
+	usageCharging evseUsage = iota
+	usageIdle
+	// usageDischarging is a V2X EV feeding energy back to the grid. It neither charges nor
+	// occupies the EVSE idly, so its time counts as neither charging nor idle time and is not
+	// billed by either time tariff.
+	usageDischarging
+)
+
+// usageOf returns the usage of an EVSE delivering a power, negative while discharging
+func usageOf(powerWatts int) evseUsage {
+	switch {
+	case powerWatts > 0:
+		return usageCharging
+	case powerWatts < 0:
+		return usageDischarging
+	default:
+		return usageIdle
+	}
+}
+
+// transactionCost is the running cost of a transaction, calculated from the usage of each
+// interval between meter readings at the prices in effect at its start
+type transactionCost struct {
+	transactionID string
+	tariff        v21.TariffType
+	kind          v21.TariffKindType
+	idToken       string
+	start         time.Time
+	last          time.Time
+	lastMeterWh   int
+	powerWatts    int       // Power of the last reading
+	usage         evseUsage // Usage of the EVSE in the last interval
+
+	energyWh     float64
+	chargingTime time.Duration
+	idleTime     time.Duration
+
+	// Cost excluding taxes per tariff component
+	fixed, energy, chargingCost, idleCost float64
+}
+
+// state returns the state of the transaction at the start of an interval
+func (tc *transactionCost) state(at time.Time, powerWatts int) tariffState {
+	return tariffState{
+		at:           at,
+		energyWh:     tc.energyWh,
+		powerWatts:   powerWatts,
+		duration:     at.Sub(tc.start),
+		chargingTime: tc.chargingTime,
+		idleTime:     tc.idleTime,
+	}
+}
+
+// add adds the usage up to a meter reading, the EV was charging at a power, idle or discharging
+// since the last reading
+func (tc *transactionCost) add(meterWh, powerWatts int, usage evseUsage, now time.Time) {
+	elapsed := max(now.Sub(tc.last), 0)
+	energyWh := float64(max(meterWh-tc.lastMeterWh, 0))
+	state := tc.state(tc.last, powerWatts)
+
+	if tc.tariff.Energy != nil {
+		for _, price := range tc.tariff.Energy.Prices {
+			if tariffConditionsMet(price.Conditions, state) {
+				tc.energy += price.PriceKwh * energyWh / 1000
+				break
+			}
+		}
+	}
+
+	var timeTariff *v21.TariffTimeType
+	var cost *float64
+	switch usage {
+	case usageCharging:
+		timeTariff, cost = tc.tariff.ChargingTime, &tc.chargingCost
+	case usageIdle:
+		timeTariff, cost = tc.tariff.IdleTime, &tc.idleCost
+	}
+	if timeTariff != nil {
+		for _, price := range timeTariff.Prices {
+			if tariffConditionsMet(price.Conditions, state) {
+				*cost += price.PriceMinute * elapsed.Minutes()
+				break
+			}
+		}
+	}
+
+	tc.energyWh += energyWh
+	switch usage {
+	case usageCharging:
+		tc.chargingTime += elapsed
+	case usageIdle:
+		tc.idleTime += elapsed
+	}
+	tc.last, tc.lastMeterWh, tc.powerWatts, tc.usage = now, meterWh, powerWatts, usage
+}
+
+// details returns the cost details of the transaction. The total is capped to the minimum and
+// maximum cost of the tariff.
+func (tc *transactionCost) details() v21.CostDetailsType {
+	total := v21.TotalCostType{
+		Currency:   tc.tariff.Currency,
+		TypeOfCost: v21.TariffCostNormalCost,
+	}
+
+	var exclTax, inclTax float64
+	component := func(cost float64, taxRates []v21.TaxRateType) *v21.PriceType {
+		price := taxedPrice(cost, taxRates)
+		exclTax += cost
+		inclTax += *price.InclTax
+		return price
+	}
+	if fee := tc.tariff.FixedFee; fee != nil {
+		total.Fixed = component(tc.fixed, fee.TaxRates)
+	}
+	if energy := tc.tariff.Energy; energy != nil {
+		total.Energy = component(tc.energy, energy.TaxRates)
+	}
+	if charging := tc.tariff.ChargingTime; charging != nil {
+		total.ChargingTime = component(tc.chargingCost, charging.TaxRates)
+	}
+	if idle := tc.tariff.IdleTime; idle != nil {
+		total.IdleTime = component(tc.idleCost, idle.TaxRates)
+	}
+
+	switch {
+	case tc.tariff.MinCost != nil && tc.tariff.MinCost.ExclTax != nil && exclTax < *tc.tariff.MinCost.ExclTax:
+		total.TypeOfCost = v21.TariffCostMinCost
+		exclTax, inclTax = limitCost(*tc.tariff.MinCost)
+	case tc.tariff.MaxCost != nil && tc.tariff.MaxCost.ExclTax != nil && exclTax > *tc.tariff.MaxCost.ExclTax:
+		total.TypeOfCost = v21.TariffCostMaxCost
+		exclTax, inclTax = limitCost(*tc.tariff.MaxCost)
+	}
+	exclTax, inclTax = roundPrice(exclTax), roundPrice(inclTax)
+	total.Total = v21.TotalPriceType{ExclTax: &exclTax, InclTax: &inclTax}
+
+	return v21.CostDetailsType{
+		TotalCost: total,
+		TotalUsage: v21.TotalUsageType{
+			Energy:       tc.energyWh,
+			ChargingTime: int(tc.chargingTime.Seconds()),
+			IdleTime:     int(tc.idleTime.Seconds()),
+		},
+	}
+}
+
+// taxedPrice returns a cost with its taxes. Taxes of a higher stack level are applied on top of
+// the cost including the taxes of lower levels.
+func taxedPrice(exclTax float64, taxRates []v21.TaxRateType) *v21.PriceType {
+	stacks := make(map[int]float64)
+	for _, rate := range taxRates {
+		stack := 0
+		if rate.Stack != nil {
+			stack = *rate.Stack
+		}
+		stacks[stack] += rate.Tax
+	}
+	levels := make([]int, 0, len(stacks))
+	for level := range stacks {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+
+	inclTax := exclTax
+	for _, level := range levels {
+		inclTax *= 1 + stacks[level]/100
+	}
+
+	roundedExcl, roundedIncl := roundPrice(exclTax), roundPrice(inclTax)
+	return &v21.PriceType{ExclTax: &roundedExcl, InclTax: &roundedIncl, TaxRates: taxRates}
+}
+
+// limitCost returns the amounts of a minimum or maximum cost, the amount including taxes is
+// calculated from its tax rates if not given
+func limitCost(limit v21.PriceType) (float64, float64) {
+	if limit.InclTax != nil {
+		return *limit.ExclTax, *limit.InclTax
+	}
+	return *limit.ExclTax, *taxedPrice(*limit.ExclTax, limit.TaxRates).InclTax
+}
+
+// roundPrice rounds an amount to cents
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// tariffConditionsMet reports whether a price applies in a state of the transaction, a price
+// without conditions always applies
+func tariffConditionsMet(c *v21.TariffConditionsType, s tariffState) bool {
+	if c == nil {
+		return true
+	}
+
+	if c.StartTimeOfDay != nil || c.EndTimeOfDay != nil {
+		minute := s.at.Hour()*60 + s.at.Minute()
+		start, end := 0, 24*60
+		if c.StartTimeOfDay != nil {
+			start, _ = parseTimeOfDay(*c.StartTimeOfDay)
+		}
+		if c.EndTimeOfDay != nil {
+			end, _ = parseTimeOfDay(*c.EndTimeOfDay)
+		}
+		// A period ending before it starts spans midnight
+		if start <= end && (minute < start || minute >= end) {
+			return false
+		}
+		if start > end && minute < start && minute >= end {
+			return false
+		}
+	}
+	if len(c.DayOfWeek) > 0 && !containsString(c.DayOfWeek, s.at.Weekday().String()) {
+		return false
+	}
+
+	date := s.at.Format(time.DateOnly)
+	if c.ValidFromDate != nil && date < *c.ValidFromDate {
+		return false
+	}
+	if c.ValidToDate != nil && date >= *c.ValidToDate {
+		return false
+	}
+	if c.EvseKind != nil && *c.EvseKind != evseKindAC {
+		return false
+	}
+
+	current := float64(s.powerWatts) / nominalVoltage
+	for _, r := range []struct {
+		value    float64
+		min, max *float64
+	}{
+		{s.energyWh, c.MinEnergy, c.MaxEnergy},
+		{float64(s.powerWatts), c.MinPower, c.MaxPower},
+		{current, c.MinCurrent, c.MaxCurrent},
+	} {
+		if (r.min != nil && r.value < *r.min) || (r.max != nil && r.value >= *r.max) {
+			return false
+		}
+	}
+	for _, r := range []struct {
+		value    time.Duration
+		min, max *int
+	}{
+		{s.duration, c.MinTime, c.MaxTime},
+		{s.chargingTime, c.MinChargingTime, c.MaxChargingTime},
+		{s.idleTime, c.MinIdleTime, c.MaxIdleTime},
+	} {
+		seconds := int(r.value.Seconds())
+		if (r.min != nil && seconds < *r.min) || (r.max != nil && seconds >= *r.max) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateTariff checks that a tariff has prices the station can calculate a cost with
+func validateTariff(tariff v21.TariffType) error {
+	if tariff.TariffId == "" {
+		return fmt.Errorf("missing tariffId")
+	}
+	if len(tariff.Currency) != 3 {
+		return fmt.Errorf("invalid currency %q", tariff.Currency)
+	}
+	if tariff.ValidFrom != nil {
+		if _, err := time.Parse(time.RFC3339, *tariff.ValidFrom); err != nil {
+			return fmt.Errorf("invalid validFrom: %w", err)
+		}
+	}
+	if tariff.Energy == nil && tariff.ChargingTime == nil && tariff.IdleTime == nil && tariff.FixedFee == nil {
+		return fmt.Errorf("tariff has no prices")
+	}
+
+	var conditions []*v21.TariffConditionsType
+	var prices []float64
+	if tariff.Energy != nil {
+		for _, p := range tariff.Energy.Prices {
+			conditions, prices = append(conditions, p.Conditions), append(prices, p.PriceKwh)
+		}
+	}
+	for _, timeTariff := range []*v21.TariffTimeType{tariff.ChargingTime, tariff.IdleTime} {
+		if timeTariff == nil {
+			continue
+		}
+		for _, p := range timeTariff.Prices {
+			conditions, prices = append(conditions, p.Conditions), append(prices, p.PriceMinute)
+		}
+	}
+	if tariff.FixedFee != nil {
+		for _, p := range tariff.FixedFee.Prices {
+			conditions, prices = append(conditions, p.Conditions), append(prices, p.PriceFixed)
+		}
+	}
+
+	for _, price := range prices {
+		if price < 0 {
+			return fmt.Errorf("negative price %v", price)
+		}
+	}
+	for _, c := range conditions {
+		if err := validateTariffConditions(c); err != nil {
+			return err
+		}
+	}
+	for _, limit := range []*v21.PriceType{tariff.MinCost, tariff.MaxCost} {
+		if limit != nil && limit.ExclTax == nil {
+			return fmt.Errorf("minCost and maxCost require exclTax")
+		}
+	}
+	return nil
+}
+
+// validateTariffConditions checks the formats of the conditions of a price
+func validateTariffConditions(c *v21.TariffConditionsType) error {
+	if c == nil {
+		return nil
+	}
+	for _, value := range []*string{c.StartTimeOfDay, c.EndTimeOfDay} {
+		if value == nil {
+			continue
+		}
+		if _, err := parseTimeOfDay(*value); err != nil {
+			return err
+		}
+	}
+	for _, day := range c.DayOfWeek {
+		if !containsString(weekdays, day) {
+			return fmt.Errorf("invalid dayOfWeek %q", day)
+		}
+	}
+	for _, value := range []*string{c.ValidFromDate, c.ValidToDate} {
+		if value == nil {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, *value); err != nil {
+			return fmt.Errorf("invalid date %q", *value)
+		}
+	}
+	if c.EvseKind != nil && *c.EvseKind != "AC" && *c.EvseKind != "DC" {
+		return fmt.Errorf("invalid evseKind %q", *c.EvseKind)
+	}
+	return nil
+}
+
+// weekdays are the values of the dayOfWeek tariff condition
+var weekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// parseTimeOfDay parses an HH:MM time of day to minutes since midnight
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// tariffValidFrom returns the time from which a tariff applies, the zero time if always
+func tariffValidFrom(tariff v21.TariffType) time.Time {
+	if tariff.ValidFrom == nil {
+		return time.Time{}
+	}
+	validFrom, _ := time.Parse(time.RFC3339, *tariff.ValidFrom)
+	return validFrom
+}
+
+// containsString reports whether a value is in a list, ignoring case
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCostDetails adds the cost calculated by the station to a TransactionEvent
+func withCostDetails(req *v201.TransactionEventRequest, cost *v21.CostDetailsType) {
+	if cost == nil {
+		return
+	}
+	if data, err := json.Marshal(cost); err == nil {
+		req.CostDetails = data
+	}
+}
@@ -0,0 +1,258 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+func newTariff(tariffID string, priceKwh float64) v21.TariffType {
+	return v21.TariffType{
+		TariffId: tariffID,
+		Currency: "EUR",
+		Energy: &v21.TariffEnergyType{
+			Prices:   []v21.TariffEnergyPriceType{{PriceKwh: priceKwh}},
+			TaxRates: []v21.TaxRateType{{Type: "VAT", Tax: 20}},
+		},
+	}
+}
+
+func TestTransactionCost(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+
+	tariff := newTariff("T1", 0.30)
+	tariff.ChargingTime = &v21.TariffTimeType{Prices: []v21.TariffTimePriceType{{PriceMinute: 0.05}}}
+	tariff.IdleTime = &v21.TariffTimeType{Prices: []v21.TariffTimePriceType{{PriceMinute: 0.10}}}
+	tariff.FixedFee = &v21.TariffFixedType{Prices: []v21.TariffFixedPriceType{{PriceFixed: 1}}}
+
+	tm := NewTariffManager()
+	if err := tm.SetDefault(0, tariff); err != nil {
+		t.Fatalf("Expected a valid tariff, got %v", err)
+	}
+
+	start := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	if !tm.startTransaction(1, "tx-1", "TAG1", 1000, start) {
+		t.Fatal("Expected the default tariff to apply")
+	}
+
+	// 5 kWh in 30 minutes of charging
+	tm.update(1, 6000, 10000, start.Add(30*time.Minute))
+	cost := tm.endTransaction(1, 6000, start.Add(30*time.Minute))
+
+	total := cost.TotalCost
+	if *total.Fixed.ExclTax != 1 || *total.Energy.ExclTax != 1.5 || *total.Energy.InclTax != 1.8 || *total.ChargingTime.ExclTax != 1.5 || *total.IdleTime.ExclTax != 0 {
+		t.Errorf("Unexpected cost components %+v %+v %+v %+v", total.Fixed, total.Energy, total.ChargingTime, total.IdleTime)
+	}
+	if cost.TotalUsage.Energy != 5000 || cost.TotalUsage.ChargingTime != 1800 || cost.TotalUsage.IdleTime != 0 {
+		t.Errorf("Expected 5 kWh and 30 minutes of charging, got %+v", cost.TotalUsage)
+	}
+
+	// The EV stops drawing power and idles
+	tm.startTransaction(1, "tx-2", "TAG1", 0, start)
+	tm.update(1, 5000, 10000, start.Add(30*time.Minute))
+	tm.update(1, 5000, 0, start.Add(50*time.Minute))
+	cost = tm.endTransaction(1, 5000, start.Add(60*time.Minute))
+
+	total = cost.TotalCost
+	if *total.IdleTime.ExclTax != 3 || cost.TotalUsage.IdleTime != 1800 || total.TypeOfCost != v21.TariffCostNormalCost {
+		t.Errorf("Expected 30 minutes idle for 3.00, got %v over %ds", *total.IdleTime.ExclTax, cost.TotalUsage.IdleTime)
+	}
+	if *total.Total.ExclTax != 7 || *total.Total.InclTax != 7.3 {
+		t.Errorf("Expected a total of 7.00 and 7.30 with VAT, got %v and %v", *total.Total.ExclTax, *total.Total.InclTax)
+	}
+
+	// A V2X EV discharging to the grid is neither charging nor idle
+	tm.startTransaction(1, "tx-4", "TAG1", 5000, start)
+	tm.update(1, 5000, -7000, start.Add(30*time.Minute))
+	cost = tm.endTransaction(1, 5000, start.Add(30*time.Minute))
+
+	total = cost.TotalCost
+	if *total.ChargingTime.ExclTax != 0 || *total.IdleTime.ExclTax != 0 || cost.TotalUsage.ChargingTime != 0 || cost.TotalUsage.IdleTime != 0 {
+		t.Errorf("Expected no charging or idle time while discharging, got %+v", cost.TotalUsage)
+	}
+
+	// The total is capped to the maximum cost of the tariff
+	tariff.MaxCost = &v21.PriceType{ExclTax: value(5), InclTax: value(6)}
+	tm.SetDefault(0, tariff)
+	tm.startTransaction(1, "tx-3", "TAG1", 0, start)
+	cost = tm.endTransaction(1, 50000, start.Add(time.Hour))
+	if cost.TotalCost.TypeOfCost != v21.TariffCostMaxCost || *cost.TotalCost.Total.ExclTax != 5 || *cost.TotalCost.Total.InclTax != 6 {
+		t.Errorf("Expected the maximum cost, got %s %v", cost.TotalCost.TypeOfCost, *cost.TotalCost.Total.ExclTax)
+	}
+
+	if tm.update(1, 60000, 10000, start) != nil {
+		t.Error("Expected no cost after the transaction ended")
+	}
+}
+
+func TestTariffConditions(t *testing.T) {
+	text := func(s string) *string { return &s }
+	value := func(v float64) *float64 { return &v }
+
+	night := &v21.TariffConditionsType{StartTimeOfDay: text("22:00"), EndTimeOfDay: text("06:00")}
+	bulk := &v21.TariffConditionsType{MinEnergy: value(10000)}
+	weekend := &v21.TariffConditionsType{DayOfWeek: []string{"Saturday", "Sunday"}}
+	dc := &v21.TariffConditionsType{EvseKind: text("DC")}
+
+	monday := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		conditions *v21.TariffConditionsType
+		state      tariffState
+		met        bool
+	}{
+		{"no conditions", nil, tariffState{at: monday}, true},
+		{"night before midnight", night, tariffState{at: monday.Add(11 * time.Hour)}, true},
+		{"night after midnight", night, tariffState{at: monday.Add(-7 * time.Hour)}, true},
+		{"day", night, tariffState{at: monday}, false},
+		{"below minimum energy", bulk, tariffState{at: monday, energyWh: 9999}, false},
+		{"minimum energy", bulk, tariffState{at: monday, energyWh: 10000}, true},
+		{"weekday", weekend, tariffState{at: monday}, false},
+		{"weekend", weekend, tariffState{at: monday.Add(-24 * time.Hour)}, true},
+		{"DC price on AC station", dc, tariffState{at: monday}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if met := tariffConditionsMet(tt.conditions, tt.state); met != tt.met {
+				t.Errorf("Expected %v, got %v", tt.met, met)
+			}
+		})
+	}
+
+	// The first price whose conditions are met applies
+	tariff := newTariff("T1", 0.40)
+	tariff.Energy.Prices = append([]v21.TariffEnergyPriceType{{PriceKwh: 0.20, Conditions: bulk}}, tariff.Energy.Prices...)
+
+	tc := &transactionCost{tariff: tariff, start: monday, last: monday}
+	tc.add(10000, 11000, usageCharging, monday.Add(time.Hour))
+	tc.add(20000, 11000, usageCharging, monday.Add(2*time.Hour))
+	if tc.energy != 6 {
+		t.Errorf("Expected 10 kWh at 0.40 and 10 kWh at 0.20, got %v", tc.energy)
+	}
+
+	invalid := newTariff("T2", 0.30)
+	invalid.Energy.Prices[0].Conditions = &v21.TariffConditionsType{StartTimeOfDay: text("25:00")}
+	if err := validateTariff(invalid); err == nil {
+		t.Error("Expected an invalid time of day to be rejected")
+	}
+	if err := validateTariff(v21.TariffType{TariffId: "T3", Currency: "EUR"}); err == nil {
+		t.Error("Expected a tariff without prices to be rejected")
+	}
+}
+
+func TestTariffCostInTransactionEvents(t *testing.T) {
+	sm, recorder := newTransactionEventSession(t, DefaultTxControl())
+	defer sm.Shutdown(context.Background())
+
+	sm.SetProtocolVersion("ocpp2.1")
+	sm.SetMeterValueConfig(3600, defaultSampledData())
+	sm.SetEVConfig(EVSimulationConfig{MaxChargePower: 11000})
+
+	if err := sm.Tariffs().SetDefault(0, newTariff("DEFAULT", 0.30)); err != nil {
+		t.Fatalf("SetDefault failed: %v", err)
+	}
+	sm.Tariffs().SetDriverTariff("TAG2", &v21.TariffType{
+		TariffId: "DRIVER",
+		Currency: "EUR",
+		Energy:   &v21.TariffEnergyType{Prices: []v21.TariffEnergyPriceType{{PriceKwh: 0.25}}},
+	})
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	connector, _ := sm.GetConnector(1)
+	sm.sendMeterValue(connector)
+
+	var cost v21.CostDetailsType
+	if err := json.Unmarshal(recorder.last().CostDetails, &cost); err != nil {
+		t.Fatalf("Expected cost details in the meter value event, got %v", err)
+	}
+	if cost.TotalUsage.Energy != 11000 || *cost.TotalCost.Total.ExclTax != 3.3 || *cost.TotalCost.Total.InclTax != 3.96 {
+		t.Errorf("Expected 11 kWh at the default tariff, got %+v %+v", cost.TotalUsage, cost.TotalCost.Total)
+	}
+	if tariffID, running := sm.Tariffs().RunningCost(1); tariffID != "DEFAULT" || running == nil || *running.TotalCost.Total.ExclTax != 3.3 {
+		t.Errorf("Expected the running cost of the default tariff, got %s %+v", tariffID, running)
+	}
+
+	// Tariff changes to another currency are rejected
+	txID := connector.GetTransaction().StringID
+	dollars := newTariff("USD", 0.50)
+	dollars.Currency = "USD"
+	if status, _ := sm.Tariffs().ChangeTransactionTariff(txID, dollars, time.Now()); status != v21.TariffChangeStatusNoCurrencyChange {
+		t.Errorf("Expected NoCurrencyChange, got %s", status)
+	}
+	if status, _ := sm.Tariffs().ChangeTransactionTariff("unknown", newTariff("T2", 0.5), time.Now()); status != v21.TariffChangeStatusTxNotFound {
+		t.Errorf("Expected TxNotFound, got %s", status)
+	}
+
+	if err := sm.StopCharging(1, v16.ReasonLocal); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+	ended := recorder.last()
+	if ended.EventType != v201.TransactionEventEnded || len(ended.CostDetails) == 0 {
+		t.Fatalf("Expected the final cost in the Ended event, got %s %s", ended.EventType, ended.CostDetails)
+	}
+	if _, running := sm.Tariffs().RunningCost(1); running != nil {
+		t.Error("Expected no running cost after the transaction ended")
+	}
+
+	// The driver tariff of the idToken replaces the default tariff
+	if _, err := sm.StartCharging(1, "TAG2"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	assignments := sm.Tariffs().Assignments(1)
+	if len(assignments) != 2 || assignments[1].TariffId != "DRIVER" || assignments[1].TariffKind != v21.TariffKindDriverTariff || assignments[1].IdTokens[0] != "TAG2" {
+		t.Errorf("Expected the default and the driver tariff, got %+v", assignments)
+	}
+}
+
+func TestHandleV21Tariffs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewManager(nil, nil, nil, logger, ManagerConfig{})
+
+	sm := NewSessionManager("TEST022", []ConnectorConfig{{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"}}, logger)
+	sm.SetProtocolVersion("ocpp2.1")
+	manager.mu.Lock()
+	manager.stations["TEST022"] = &Station{
+		Config:         Config{StationID: "TEST022", ProtocolVersion: "ocpp2.1"},
+		StateMachine:   NewStateMachine(),
+		SessionManager: sm,
+	}
+	manager.mu.Unlock()
+
+	resp, _ := manager.v21Handler.OnSetDefaultTariff("TEST022", &v21.SetDefaultTariffRequest{EvseId: 2, Tariff: newTariff("T1", 0.30)})
+	if resp.Status != v21.TariffSetStatusRejected || resp.StatusInfo.ReasonCode != "UnknownEvse" {
+		t.Errorf("Expected Rejected for an unknown EVSE, got %s %+v", resp.Status, resp.StatusInfo)
+	}
+	resp, _ = manager.v21Handler.OnSetDefaultTariff("TEST022", &v21.SetDefaultTariffRequest{EvseId: 1, Tariff: v21.TariffType{TariffId: "T0", Currency: "EUR"}})
+	if resp.Status != v21.TariffSetStatusRejected || resp.StatusInfo.ReasonCode != "InvalidTariff" {
+		t.Errorf("Expected Rejected for a tariff without prices, got %s %+v", resp.Status, resp.StatusInfo)
+	}
+	resp, _ = manager.v21Handler.OnSetDefaultTariff("TEST022", &v21.SetDefaultTariffRequest{EvseId: 1, Tariff: newTariff("T1", 0.30)})
+	if resp.Status != v21.TariffSetStatusAccepted {
+		t.Fatalf("Expected Accepted, got %s %+v", resp.Status, resp.StatusInfo)
+	}
+
+	tariffs, _ := manager.v21Handler.OnGetTariffs("TEST022", &v21.GetTariffsRequest{EvseId: 0})
+	if tariffs.Status != v21.TariffGetStatusAccepted || len(tariffs.TariffAssignments) != 1 || tariffs.TariffAssignments[0].EvseIds[0] != 1 {
+		t.Errorf("Expected the tariff of EVSE 1, got %s %+v", tariffs.Status, tariffs.TariffAssignments)
+	}
+
+	cleared, _ := manager.v21Handler.OnClearTariffs("TEST022", &v21.ClearTariffsRequest{TariffIds: []string{"T1", "T9"}})
+	results := cleared.ClearTariffsResult
+	if len(results) != 2 || results[0].Status != v21.TariffClearStatusAccepted || results[1].Status != v21.TariffClearStatusNoTariff {
+		t.Errorf("Expected T1 cleared and no tariff T9, got %+v", results)
+	}
+
+	tariffs, _ = manager.v21Handler.OnGetTariffs("TEST022", &v21.GetTariffsRequest{EvseId: 1})
+	if tariffs.Status != v21.TariffGetStatusNoTariff {
+		t.Errorf("Expected NoTariff, got %s", tariffs.Status)
+	}
+}
@@ -0,0 +1,506 @@
+package station
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+	v21 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v21"
+)
+
+// TxPoint is a point in the charging process at which an OCPP 2.0.1 transaction starts or stops
+// (TxCtrlr TxStartPoint/TxStopPoint values)
+type TxPoint string
+
+const (
+	TxPointParkingBayOccupancy TxPoint = "ParkingBayOccupancy"
+	TxPointEVConnected         TxPoint = "EVConnected"
+	TxPointAuthorized          TxPoint = "Authorized"
+	TxPointDataSigned          TxPoint = "DataSigned"
+	TxPointPowerPathClosed     TxPoint = "PowerPathClosed"
+	TxPointEnergyTransfer      TxPoint = "EnergyTransfer"
+)
+
+// TxControl holds the TxCtrlr variables that shape the OCPP 2.0.1 transaction lifecycle
+type TxControl struct {
+	StartPoints     []TxPoint
+	StopPoints      []TxPoint
+	StopOnInvalidID bool // Stop the transaction when the CSMS rejects its idToken (StopTxOnInvalidId)
+}
+
+// DefaultTxControl returns the TxCtrlr defaults of the device model
+func DefaultTxControl() TxControl {
+	return TxControl{
+		StartPoints:     []TxPoint{TxPointAuthorized},
+		StopPoints:      []TxPoint{TxPointEVConnected},
+		StopOnInvalidID: true,
+	}
+}
+
+// TxControlFromDeviceModel reads the TxCtrlr variables of a device model
+func TxControlFromDeviceModel(dm *v201.DeviceModel) TxControl {
+	control := DefaultTxControl()
+	if dm == nil {
+		return control
+	}
+
+	if value, status := dm.GetVariable("TxCtrlr", "", "TxStartPoint", "", v201.AttributeActual); status == v201.GetVariableStatusAccepted {
+		control.StartPoints = ParseTxPoints(value)
+	}
+	if value, status := dm.GetVariable("TxCtrlr", "", "TxStopPoint", "", v201.AttributeActual); status == v201.GetVariableStatusAccepted {
+		control.StopPoints = ParseTxPoints(value)
+	}
+	if value, status := dm.GetVariable("TxCtrlr", "", "StopTxOnInvalidId", "", v201.AttributeActual); status == v201.GetVariableStatusAccepted {
+		control.StopOnInvalidID = strings.EqualFold(value, "true")
+	}
+
+	return control
+}
+
+// ParseTxPoints parses a comma separated TxStartPoint/TxStopPoint value
+func ParseTxPoints(value string) []TxPoint {
+	var points []TxPoint
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			points = append(points, TxPoint(part))
+		}
+	}
+	return points
+}
+
+// containsTxPoint reports whether one of the given points is configured
+func containsTxPoint(configured []TxPoint, points ...TxPoint) bool {
+	for _, c := range configured {
+		for _, p := range points {
+			if c == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// txEventState tracks the TransactionEvent messages of the transaction on a connector
+type txEventState struct {
+	transactionID string
+	seqNo         int
+	started       bool // Started has been sent
+	idToken       v201.IdToken
+	remoteStartID *int
+	reservationID *int
+}
+
+// txStep is a step of the simulated charging process, a transaction starts or stops
+// at the first step whose points are configured as TxStartPoint/TxStopPoint
+type txStep struct {
+	points        []TxPoint
+	trigger       v201.TriggerReasonType
+	chargingState *v201.ChargingStateType
+}
+
+// usesTransactionEvents reports whether transactions are reported with TransactionEvent (OCPP 2.0.1 and 2.1)
+func (sm *SessionManager) usesTransactionEvents() bool {
+	return usesTransactionEvents(sm.protocolVersion)
+}
+
+// SetTxControl sets the TxCtrlr variables applied to transactions started afterwards
+func (sm *SessionManager) SetTxControl(control TxControl) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.txControl = control
+}
+
+// TxControl returns the TxCtrlr variables of the station
+func (sm *SessionManager) TxControl() TxControl {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.txControl
+}
+
+// newTransactionEvents assigns the station generated transaction ID of a new OCPP 2.0.1 transaction
+func (sm *SessionManager) newTransactionEvents(connectorID int, idToken v201.IdToken, remoteStartID, reservationID *int) string {
+	state := &txEventState{
+		transactionID: uuid.New().String(),
+		idToken:       idToken,
+		remoteStartID: remoteStartID,
+		reservationID: reservationID,
+	}
+
+	sm.mu.Lock()
+	sm.txEvents[connectorID] = state
+	sm.mu.Unlock()
+
+	return state.transactionID
+}
+
+// sendStartEvents reports the start of a transaction that has been authorized, plugged in and is charging.
+// The transaction starts at the first step that is a configured TxStartPoint, later steps are sent as Updated.
+func (sm *SessionManager) sendStartEvents(connector *Connector, meterStart int) {
+	sm.mu.RLock()
+	state := sm.txEvents[connector.ID]
+	startPoints := sm.txControl.StartPoints
+	sm.mu.RUnlock()
+
+	if state == nil {
+		return
+	}
+
+	authorizedTrigger := v201.TriggerReasonAuthorized
+	if state.remoteStartID != nil {
+		authorizedTrigger = v201.TriggerReasonRemoteStart
+	}
+	evConnected := v201.ChargingStateEVConnected
+	charging := v201.ChargingStateCharging
+
+	steps := []txStep{
+		{points: []TxPoint{TxPointAuthorized}, trigger: authorizedTrigger},
+		{points: []TxPoint{TxPointEVConnected, TxPointParkingBayOccupancy}, trigger: v201.TriggerReasonCablePluggedIn, chargingState: &evConnected},
+		{points: []TxPoint{TxPointPowerPathClosed, TxPointEnergyTransfer}, trigger: v201.TriggerReasonChargingStateChanged, chargingState: &charging},
+	}
+
+	// Without a known start point the transaction starts with the authorization
+	start := 0
+	for i, step := range steps {
+		if containsTxPoint(startPoints, step.points...) {
+			start = i
+			break
+		}
+	}
+
+	for i, step := range steps[start:] {
+		req := sm.nextTransactionEvent(connector.ID, v201.TransactionEventUpdated, step.trigger)
+		if req == nil {
+			return
+		}
+		req.TransactionInfo.ChargingState = step.chargingState
+
+		if i == 0 {
+			sm.fillStartedEvent(req, connector.ID, state, meterStart)
+		}
+
+		sm.sendTransactionEvent(req)
+	}
+}
+
+// fillStartedEvent adds the transaction details reported once, with the Started event
+func (sm *SessionManager) fillStartedEvent(req *v201.TransactionEventRequest, connectorID int, state *txEventState, meterStart int) {
+	connectorOnEVSE := 1
+
+	req.EventType = v201.TransactionEventStarted
+	req.EVSE = &v201.EVSE{ID: connectorID, ConnectorId: &connectorOnEVSE}
+	req.TransactionInfo.RemoteStartId = state.remoteStartID
+	req.ReservationId = state.reservationID
+	req.MeterValue = []v201.MeterValue{energyMeterValue(meterStart, v201.ReadingContextTransactionBegin)}
+
+	if state.idToken.IdToken != "" {
+		idToken := state.idToken
+		req.IdToken = &idToken
+	}
+
+	sm.mu.Lock()
+	state.started = true
+	sm.mu.Unlock()
+}
+
+// sendStopEvents reports the end of a transaction: the EV driver is deauthorized, energy transfer stops
+// and the EV is unplugged. The transaction ends at the first step that is a configured TxStopPoint.
+// The export register is reported if the EV discharged energy, the final cost if the station
+// calculated it from a tariff.
+func (sm *SessionManager) sendStopEvents(connectorID int, meterStop, exportStop int, startTime time.Time, reason v16.Reason, cost *v21.CostDetailsType) {
+	sm.mu.Lock()
+	state := sm.txEvents[connectorID]
+	started := state != nil && state.started
+	stopPoints := sm.txControl.StopPoints
+	delete(sm.txEvents, connectorID)
+	sm.mu.Unlock()
+
+	if !started {
+		return
+	}
+
+	evConnected := v201.ChargingStateEVConnected
+	idle := v201.ChargingStateIdle
+
+	steps := []txStep{
+		{points: []TxPoint{TxPointAuthorized, TxPointPowerPathClosed, TxPointEnergyTransfer}, trigger: stopTriggerReason(reason), chargingState: &evConnected},
+		{points: []TxPoint{TxPointEVConnected, TxPointParkingBayOccupancy}, trigger: v201.TriggerReasonEVCommunicationLost, chargingState: &idle},
+	}
+
+	// An unplugged EV ends the transaction regardless of the stop point
+	if reason == v16.ReasonEVDisconnected {
+		steps = steps[1:]
+	}
+
+	for i, step := range steps {
+		last := i == len(steps)-1 || containsTxPoint(stopPoints, step.points...)
+
+		eventType := v201.TransactionEventUpdated
+		if last {
+			eventType = v201.TransactionEventEnded
+		}
+
+		req := sm.transactionEvent(state, eventType, step.trigger)
+		req.TransactionInfo.ChargingState = step.chargingState
+
+		if last {
+			stoppedReason := stoppedReason(reason)
+			timeSpentCharging := int(time.Since(startTime).Seconds())
+			req.TransactionInfo.StoppedReason = &stoppedReason
+			req.TransactionInfo.TimeSpentCharging = &timeSpentCharging
+			meterValue := energyMeterValue(meterStop, v201.ReadingContextTransactionEnd)
+			if exportStop > 0 {
+				meterValue.SampledValue = append(meterValue.SampledValue,
+					registerSample(v201.MeasurandEnergyActiveExportRegister, exportStop, v201.ReadingContextTransactionEnd))
+			}
+			req.MeterValue = []v201.MeterValue{meterValue}
+			withCostDetails(req, cost)
+		}
+
+		sm.sendTransactionEvent(req)
+
+		if last {
+			return
+		}
+	}
+}
+
+// sendMeterValueEvent reports periodic meter values of a running transaction with its running cost
+func (sm *SessionManager) sendMeterValueEvent(connectorID int, sampledValues []v16.SampledValue, cost *v21.CostDetailsType) {
+	req := sm.nextTransactionEvent(connectorID, v201.TransactionEventUpdated, v201.TriggerReasonMeterValuePeriodic)
+	if req == nil {
+		return
+	}
+
+	meterValue := v201.MeterValue{
+		Timestamp:    req.Timestamp,
+		SampledValue: make([]v201.SampledValue, 0, len(sampledValues)),
+	}
+	for _, sv := range sampledValues {
+		meterValue.SampledValue = append(meterValue.SampledValue, toV201SampledValue(sv))
+	}
+	req.MeterValue = []v201.MeterValue{meterValue}
+	withCostDetails(req, cost)
+
+	sm.sendTransactionEvent(req)
+}
+
+// nextTransactionEvent creates the next event of the transaction on a connector, nil if there is none
+func (sm *SessionManager) nextTransactionEvent(connectorID int, eventType v201.TransactionEventType, trigger v201.TriggerReasonType) *v201.TransactionEventRequest {
+	sm.mu.RLock()
+	state := sm.txEvents[connectorID]
+	sm.mu.RUnlock()
+
+	if state == nil {
+		return nil
+	}
+
+	return sm.transactionEvent(state, eventType, trigger)
+}
+
+// transactionEvent creates an event of a transaction with the next sequence number
+func (sm *SessionManager) transactionEvent(state *txEventState, eventType v201.TransactionEventType, trigger v201.TriggerReasonType) *v201.TransactionEventRequest {
+	sm.mu.Lock()
+	seqNo := state.seqNo
+	state.seqNo++
+	sm.mu.Unlock()
+
+	return &v201.TransactionEventRequest{
+		EventType:     eventType,
+		Timestamp:     v201.DateTime{Time: time.Now()},
+		TriggerReason: trigger,
+		SeqNo:         seqNo,
+		TransactionInfo: v201.Transaction{
+			TransactionId: state.transactionID,
+		},
+	}
+}
+
+// sendTransactionEvent hands an event to the CSMS callback
+func (sm *SessionManager) sendTransactionEvent(req *v201.TransactionEventRequest) {
+	if sm.SendTransactionEvent == nil {
+		return
+	}
+
+	if err := sm.SendTransactionEvent(req); err != nil {
+		sm.logger.Error("Failed to send TransactionEvent",
+			"stationId", sm.stationID,
+			"transactionId", req.TransactionInfo.TransactionId,
+			"eventType", req.EventType,
+			"error", err,
+		)
+	}
+}
+
+// HandleTransactionEventResponse applies the idTokenInfo the CSMS returned for a TransactionEvent.
+// A transaction whose idToken is no longer accepted is stopped when StopTxOnInvalidId is set.
+func (sm *SessionManager) HandleTransactionEventResponse(req *v201.TransactionEventRequest, resp *v201.TransactionEventResponse) {
+	if resp.IdTokenInfo == nil {
+		return
+	}
+
+	var connector *Connector
+	for _, c := range sm.GetAllConnectors() {
+		if tx := c.GetTransaction(); tx != nil && tx.StringID == req.TransactionInfo.TransactionId {
+			connector = c
+			break
+		}
+	}
+
+	var idToken v201.IdToken
+	if req.IdToken != nil {
+		idToken = *req.IdToken
+	} else if connector != nil {
+		idToken = v201.IdToken{IdToken: connector.GetTransaction().IDTag, Type: v201.IdTokenTypeISO14443}
+	}
+	if idToken.IdToken != "" {
+		sm.idTokenAuth.UpdateCache(idToken, *resp.IdTokenInfo)
+	}
+
+	if resp.IdTokenInfo.Status == v201.AuthorizationStatusAccepted || req.EventType == v201.TransactionEventEnded {
+		return
+	}
+
+	sm.logger.Warn("Transaction idToken rejected by CSMS",
+		"stationId", sm.stationID,
+		"transactionId", req.TransactionInfo.TransactionId,
+		"status", resp.IdTokenInfo.Status,
+	)
+
+	if connector == nil || !connector.HasActiveTransaction() || !sm.TxControl().StopOnInvalidID {
+		return
+	}
+
+	if err := sm.StopCharging(connector.ID, v16.ReasonDeAuthorized); err != nil {
+		sm.logger.Error("Failed to stop deauthorized transaction",
+			"stationId", sm.stationID,
+			"connectorId", connector.ID,
+			"error", err,
+		)
+	}
+}
+
+// IdTagInfoFromV201 converts OCPP 2.0.1 idTokenInfo to the OCPP 1.6 authorization info used by the session
+func IdTagInfoFromV201(info *v201.IdTokenInfo) v16.IdTagInfo {
+	result := v16.IdTagInfo{}
+
+	switch info.Status {
+	case v201.AuthorizationStatusAccepted:
+		result.Status = v16.AuthorizationStatusAccepted
+	case v201.AuthorizationStatusBlocked:
+		result.Status = v16.AuthorizationStatusBlocked
+	case v201.AuthorizationStatusExpired:
+		result.Status = v16.AuthorizationStatusExpired
+	case v201.AuthorizationStatusConcurrentTx:
+		result.Status = v16.AuthorizationStatusConcurrentTx
+	default:
+		result.Status = v16.AuthorizationStatusInvalid
+	}
+
+	if info.CacheExpiryDateTime != nil {
+		result.ExpiryDate = &v16.DateTime{Time: info.CacheExpiryDateTime.Time}
+	}
+	if info.GroupIdToken != nil {
+		result.ParentIdTag = info.GroupIdToken.IdToken
+	}
+
+	return result
+}
+
+// stopTriggerReason returns the trigger reason of the event that deauthorizes a transaction
+func stopTriggerReason(reason v16.Reason) v201.TriggerReasonType {
+	switch reason {
+	case v16.ReasonRemote:
+		return v201.TriggerReasonRemoteStop
+	case v16.ReasonLocal:
+		return v201.TriggerReasonStopAuthorized
+	case v16.ReasonDeAuthorized:
+		return v201.TriggerReasonDeauthorized
+	case v16.ReasonHardReset, v16.ReasonSoftReset, v16.ReasonReboot:
+		return v201.TriggerReasonResetCommand
+	case v16.ReasonEVDisconnected:
+		return v201.TriggerReasonEVCommunicationLost
+	default:
+		return v201.TriggerReasonAbnormalCondition
+	}
+}
+
+// stoppedReason converts an OCPP 1.6 stop reason to the OCPP 2.0.1 stoppedReason
+func stoppedReason(reason v16.Reason) v201.ReasonType {
+	switch reason {
+	case v16.ReasonDeAuthorized:
+		return v201.ReasonDeAuthorized
+	case v16.ReasonEmergencyStop:
+		return v201.ReasonEmergencyStop
+	case v16.ReasonEVDisconnected:
+		return v201.ReasonEVDisconnected
+	case v16.ReasonHardReset, v16.ReasonSoftReset:
+		return v201.ReasonImmediateReset
+	case v16.ReasonLocal:
+		return v201.ReasonLocal
+	case v16.ReasonPowerLoss:
+		return v201.ReasonPowerLoss
+	case v16.ReasonReboot:
+		return v201.ReasonReboot
+	case v16.ReasonRemote:
+		return v201.ReasonRemote
+	default:
+		return v201.ReasonOther
+	}
+}
+
+// connectorStatusV201 converts an OCPP 1.6 charge point status to the OCPP 2.0.1 connector status
+func connectorStatusV201(status v16.ChargePointStatus) v201.ConnectorStatusType {
+	switch status {
+	case v16.ChargePointStatusAvailable:
+		return v201.ConnectorStatusAvailable
+	case v16.ChargePointStatusReserved:
+		return v201.ConnectorStatusReserved
+	case v16.ChargePointStatusUnavailable:
+		return v201.ConnectorStatusUnavailable
+	case v16.ChargePointStatusFaulted:
+		return v201.ConnectorStatusFaulted
+	default:
+		// Preparing, Charging, SuspendedEV, SuspendedEVSE and Finishing
+		return v201.ConnectorStatusOccupied
+	}
+}
+
+// energyMeterValue returns the energy register as a meter value with the given context
+func energyMeterValue(meterWh int, context v201.ReadingContextType) v201.MeterValue {
+	return v201.MeterValue{
+		Timestamp:    v201.DateTime{Time: time.Now()},
+		SampledValue: []v201.SampledValue{registerSample(v201.MeasurandEnergyActiveImportRegister, meterWh, context)},
+	}
+}
+
+// registerSample returns the reading of an energy register in Wh
+func registerSample(measurand v201.MeasurandType, wh int, context v201.ReadingContextType) v201.SampledValue {
+	location := v201.LocationOutlet
+
+	return v201.SampledValue{
+		Value:         float64(wh),
+		Context:       &context,
+		Measurand:     &measurand,
+		Location:      &location,
+		UnitOfMeasure: &v201.UnitOfMeasure{Unit: string(v16.UnitOfMeasureWh)},
+	}
+}
+
+// toV201SampledValue converts a simulated OCPP 1.6 sampled value
+func toV201SampledValue(sv v16.SampledValue) v201.SampledValue {
+	value, _ := strconv.ParseFloat(sv.Value, 64)
+	context := v201.ReadingContextType(sv.Context)
+	measurand := v201.MeasurandType(sv.Measurand)
+	location := v201.LocationType(sv.Location)
+
+	return v201.SampledValue{
+		Value:         value,
+		Context:       &context,
+		Measurand:     &measurand,
+		Location:      &location,
+		UnitOfMeasure: &v201.UnitOfMeasure{Unit: string(sv.Unit)},
+	}
+}
@@ -0,0 +1,158 @@
+package station
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	v16 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v16"
+	v201 "github.com/ruslanhut/ocpp-emu/internal/ocpp/v201"
+)
+
+// eventRecorder collects the TransactionEvents sent by a session manager
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []*v201.TransactionEventRequest
+}
+
+func (er *eventRecorder) send(req *v201.TransactionEventRequest) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	er.events = append(er.events, req)
+	return nil
+}
+
+// summary returns the events as "eventType/triggerReason/seqNo"
+func (er *eventRecorder) summary() []string {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	summary := make([]string, 0, len(er.events))
+	for _, req := range er.events {
+		summary = append(summary, fmt.Sprintf("%s/%s/%d", req.EventType, req.TriggerReason, req.SeqNo))
+	}
+	return summary
+}
+
+func (er *eventRecorder) last() *v201.TransactionEventRequest {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	return er.events[len(er.events)-1]
+}
+
+func newTransactionEventSession(t *testing.T, control TxControl) (*SessionManager, *eventRecorder) {
+	t.Helper()
+
+	sm := NewSessionManager("CP001", []ConnectorConfig{{ID: 1, Type: "Type2", MaxPower: 22000, Status: "Available"}}, slog.Default())
+	sm.SetProtocolVersion("ocpp2.0.1")
+	sm.SetTxControl(control)
+	allowOfflineTx(sm)
+
+	recorder := &eventRecorder{}
+	sm.SendTransactionEvent = recorder.send
+	sm.SendStartTransaction = func(connectorID int, idTag string, meterStart int, timestamp time.Time) (*v16.StartTransactionResponse, error) {
+		t.Error("Expected no StartTransaction for an OCPP 2.0.1 station")
+		return nil, fmt.Errorf("unexpected StartTransaction")
+	}
+
+	return sm, recorder
+}
+
+func TestTransactionEvents_DefaultStartAndStopPoints(t *testing.T) {
+	sm, recorder := newTransactionEventSession(t, DefaultTxControl())
+
+	if _, err := sm.StartRemoteCharging(1, v201.IdToken{IdToken: "TAG1", Type: v201.IdTokenTypeISO14443}, 7); err != nil {
+		t.Fatalf("StartRemoteCharging failed: %v", err)
+	}
+
+	connector, _ := sm.GetConnector(1)
+	tx := connector.GetTransaction()
+	if tx == nil || tx.StringID == "" {
+		t.Fatal("Expected a string transaction ID")
+	}
+
+	started := recorder.events[0]
+	if started.TransactionInfo.TransactionId != tx.StringID || started.IdToken == nil || started.IdToken.IdToken != "TAG1" {
+		t.Errorf("Expected Started for transaction %s with idToken TAG1, got %+v", tx.StringID, started)
+	}
+	if started.TransactionInfo.RemoteStartId == nil || *started.TransactionInfo.RemoteStartId != 7 || started.EVSE == nil {
+		t.Errorf("Expected Started with remoteStartId 7 and EVSE, got %+v", started.TransactionInfo)
+	}
+
+	if err := sm.StopCharging(1, v16.ReasonRemote); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+
+	expected := []string{
+		"Started/RemoteStart/0",
+		"Updated/CablePluggedIn/1",
+		"Updated/ChargingStateChanged/2",
+		"Updated/RemoteStop/3",
+		"Ended/EVCommunicationLost/4",
+	}
+	if fmt.Sprint(recorder.summary()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, recorder.summary())
+	}
+
+	ended := recorder.last()
+	if ended.TransactionInfo.StoppedReason == nil || *ended.TransactionInfo.StoppedReason != v201.ReasonRemote {
+		t.Errorf("Expected stoppedReason Remote, got %v", ended.TransactionInfo.StoppedReason)
+	}
+	if ended.TransactionInfo.ChargingState == nil || *ended.TransactionInfo.ChargingState != v201.ChargingStateIdle {
+		t.Errorf("Expected chargingState Idle, got %v", ended.TransactionInfo.ChargingState)
+	}
+}
+
+func TestTransactionEvents_ConfiguredStartAndStopPoints(t *testing.T) {
+	sm, recorder := newTransactionEventSession(t, TxControl{
+		StartPoints: ParseTxPoints("PowerPathClosed"),
+		StopPoints:  ParseTxPoints("Authorized, EVConnected"),
+	})
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+	if err := sm.StopCharging(1, v16.ReasonLocal); err != nil {
+		t.Fatalf("StopCharging failed: %v", err)
+	}
+
+	expected := []string{
+		"Started/ChargingStateChanged/0",
+		"Ended/StopAuthorized/1",
+	}
+	if fmt.Sprint(recorder.summary()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, recorder.summary())
+	}
+}
+
+func TestTransactionEvents_StopOnInvalidIdToken(t *testing.T) {
+	sm, recorder := newTransactionEventSession(t, DefaultTxControl())
+
+	if _, err := sm.StartCharging(1, "TAG1"); err != nil {
+		t.Fatalf("StartCharging failed: %v", err)
+	}
+
+	started := recorder.events[0]
+	sm.HandleTransactionEventResponse(started, &v201.TransactionEventResponse{
+		IdTokenInfo: &v201.IdTokenInfo{Status: v201.AuthorizationStatusBlocked},
+	})
+
+	connector, _ := sm.GetConnector(1)
+	if connector.HasActiveTransaction() {
+		t.Fatal("Expected the transaction to be stopped")
+	}
+
+	// The driver is deauthorized, the transaction ends once the EV is unplugged (TxStopPoint EVConnected)
+	summary := recorder.summary()
+	expected := []string{"Updated/Deauthorized/3", "Ended/EVCommunicationLost/4"}
+	if fmt.Sprint(summary[len(summary)-2:]) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, summary)
+	}
+
+	ended := recorder.last()
+	if ended.TransactionInfo.StoppedReason == nil || *ended.TransactionInfo.StoppedReason != v201.ReasonDeAuthorized {
+		t.Errorf("Expected stoppedReason DeAuthorized, got %v", ended.TransactionInfo.StoppedReason)
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuthorizationRepository handles persistence of local authorization data
+type AuthorizationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuthorizationRepository creates a new authorization repository
+func NewAuthorizationRepository(db *MongoDBClient) *AuthorizationRepository {
+	return &AuthorizationRepository{
+		collection: db.AuthorizationCollection,
+	}
+}
+
+// Get retrieves the authorization data of a station.
+// Returns nil without error if the station has no stored data yet.
+func (r *AuthorizationRepository) Get(ctx context.Context, stationID string) (*AuthorizationData, error) {
+	var data AuthorizationData
+	err := r.collection.FindOne(ctx, bson.M{"station_id": stationID}).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization data: %w", err)
+	}
+
+	return &data, nil
+}
+
+// Save replaces the OCPP 1.6 list and cache of a station
+func (r *AuthorizationRepository) Save(ctx context.Context, data AuthorizationData) error {
+	return r.update(ctx, data.StationID, bson.M{
+		"local_list_version": data.LocalListVersion,
+		"local_list":         data.LocalList,
+		"cache":              data.Cache,
+	})
+}
+
+// SaveIdTokens replaces the OCPP 2.0.1 list and cache of a station
+func (r *AuthorizationRepository) SaveIdTokens(ctx context.Context, data AuthorizationData) error {
+	return r.update(ctx, data.StationID, bson.M{
+		"id_token_list_version": data.IdTokenListVersion,
+		"id_token_list":         data.IdTokenList,
+		"id_token_cache":        data.IdTokenCache,
+	})
+}
+
+// update sets fields of the authorization data of a station, creating it if needed
+func (r *AuthorizationRepository) update(ctx context.Context, stationID string, fields bson.M) error {
+	fields["updated_at"] = time.Now()
+
+	filter := bson.M{"station_id": stationID}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": fields}, opts); err != nil {
+		return fmt.Errorf("failed to save authorization data: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the authorization data of a station
+func (r *AuthorizationRepository) Delete(ctx context.Context, stationID string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"station_id": stationID}); err != nil {
+		return fmt.Errorf("failed to delete authorization data: %w", err)
+	}
+
+	return nil
+}
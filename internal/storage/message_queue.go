@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MessageQueueRepository handles persistence of the outbound message queues of stations
+type MessageQueueRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMessageQueueRepository creates a new message queue repository
+func NewMessageQueueRepository(db *MongoDBClient) *MessageQueueRepository {
+	return &MessageQueueRepository{
+		collection: db.MessageQueueCollection,
+	}
+}
+
+// Get retrieves the queued messages of a station.
+// Returns nil without error if the station has no stored queue yet.
+func (r *MessageQueueRepository) Get(ctx context.Context, stationID string) (*MessageQueueData, error) {
+	var data MessageQueueData
+	err := r.collection.FindOne(ctx, bson.M{"station_id": stationID}).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message queue: %w", err)
+	}
+
+	return &data, nil
+}
+
+// Save replaces the queued messages of a station
+func (r *MessageQueueRepository) Save(ctx context.Context, data MessageQueueData) error {
+	data.ID = ""
+	data.UpdatedAt = time.Now()
+
+	filter := bson.M{"station_id": data.StationID}
+	opts := options.Replace().SetUpsert(true)
+
+	if _, err := r.collection.ReplaceOne(ctx, filter, data, opts); err != nil {
+		return fmt.Errorf("failed to save message queue: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the message queue of a station
+func (r *MessageQueueRepository) Delete(ctx context.Context, stationID string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"station_id": stationID}); err != nil {
+		return fmt.Errorf("failed to delete message queue: %w", err)
+	}
+
+	return nil
+}
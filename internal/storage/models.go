@@ -9,7 +9,7 @@ type Message struct {
 	ID               string                 `bson:"_id,omitempty"`
 	StationID        string                 `bson:"station_id"`
 	Direction        string                 `bson:"direction"`        // "sent" or "received"
-	MessageType      string                 `bson:"message_type"`     // "Call", "CallResult", "CallError"
+	MessageType      string                 `bson:"message_type"`     // "Call", "CallResult", "CallError", "Timeout"
 	Action           string                 `bson:"action"`           // e.g., "BootNotification", "Heartbeat"
 	MessageID        string                 `bson:"message_id"`       // Unique message ID
 	ProtocolVersion  string                 `bson:"protocol_version"` // "1.6", "2.0.1", "2.1"
@@ -42,31 +42,46 @@ type Transaction struct {
 
 // Station represents a charging station configuration
 type Station struct {
-	ID                string            `bson:"_id,omitempty"`
-	StationID         string            `bson:"station_id"`
-	Name              string            `bson:"name"`
-	Enabled           bool              `bson:"enabled"`
-	AutoStart         bool              `bson:"auto_start"`
-	ProtocolVersion   string            `bson:"protocol_version"`
-	Vendor            string            `bson:"vendor"`
-	Model             string            `bson:"model"`
-	SerialNumber      string            `bson:"serial_number"`
-	FirmwareVersion   string            `bson:"firmware_version"`
-	ICCID             string            `bson:"iccid,omitempty"`
-	IMSI              string            `bson:"imsi,omitempty"`
-	Connectors        []Connector       `bson:"connectors"`
-	SupportedProfiles []string          `bson:"supported_profiles"`
-	MeterValuesConfig MeterValuesConfig `bson:"meter_values_config"`
-	CSMSURL           string            `bson:"csms_url"`
-	CSMSAuth          CSMSAuth          `bson:"csms_auth,omitempty"`
-	Simulation        SimulationConfig  `bson:"simulation"`
-	ConnectionStatus  string            `bson:"connection_status"`
-	LastHeartbeat     *time.Time        `bson:"last_heartbeat,omitempty"`
-	LastError         string            `bson:"last_error,omitempty"`
-	CreatedAt         time.Time         `bson:"created_at"`
-	UpdatedAt         time.Time         `bson:"updated_at"`
-	CreatedBy         string            `bson:"created_by,omitempty"`
-	Tags              []string          `bson:"tags,omitempty"`
+	ID                 string             `bson:"_id,omitempty"`
+	StationID          string             `bson:"station_id"`
+	Name               string             `bson:"name"`
+	Enabled            bool               `bson:"enabled"`
+	AutoStart          bool               `bson:"auto_start"`
+	ProtocolVersion    string             `bson:"protocol_version"`
+	Vendor             string             `bson:"vendor"`
+	Model              string             `bson:"model"`
+	SerialNumber       string             `bson:"serial_number"`
+	FirmwareVersion    string             `bson:"firmware_version"`
+	ICCID              string             `bson:"iccid,omitempty"`
+	IMSI               string             `bson:"imsi,omitempty"`
+	Connectors         []Connector        `bson:"connectors"`
+	SupportedProfiles  []string           `bson:"supported_profiles"`
+	MeterValuesConfig  MeterValuesConfig  `bson:"meter_values_config"`
+	CSMSURL            string             `bson:"csms_url"`
+	CSMSAuth           CSMSAuth           `bson:"csms_auth,omitempty"`
+	SecurityProfile    int                `bson:"security_profile"`
+	NetworkProfiles    string             `bson:"network_profiles,omitempty"` // Network connection profiles set by the CSMS (JSON)
+	Simulation         SimulationConfig   `bson:"simulation"`
+	OCPPConfiguration  map[string]string  `bson:"ocpp_configuration,omitempty"`   // Writable OCPP 1.6 configuration keys
+	DeviceModelValues  []DeviceModelValue `bson:"device_model_values,omitempty"`  // Persistent OCPP 2.0.1 variables
+	DeviceModelProfile string             `bson:"device_model_profile,omitempty"` // Imported device model profile (JSON)
+	ConnectionStatus   string             `bson:"connection_status"`
+	LastHeartbeat      *time.Time         `bson:"last_heartbeat,omitempty"`
+	LastError          string             `bson:"last_error,omitempty"`
+	CreatedAt          time.Time          `bson:"created_at"`
+	UpdatedAt          time.Time          `bson:"updated_at"`
+	CreatedBy          string             `bson:"created_by,omitempty"`
+	Tags               []string           `bson:"tags,omitempty"`
+}
+
+// DeviceModelValue represents a persisted OCPP 2.0.1 variable attribute value
+type DeviceModelValue struct {
+	Component         string `bson:"component"`
+	ComponentInstance string `bson:"component_instance,omitempty"`
+	Variable          string `bson:"variable"`
+	VariableInstance  string `bson:"variable_instance,omitempty"`
+	AttributeType     string `bson:"attribute_type"`
+	Value             string `bson:"value"`
 }
 
 // Connector represents a charging connector
@@ -76,6 +91,8 @@ type Connector struct {
 	MaxPower             int    `bson:"max_power"` // Watts
 	Status               string `bson:"status"`    // Available, Occupied, Faulted, etc.
 	CurrentTransactionID *int   `bson:"current_transaction_id,omitempty"`
+	PlugAndCharge        bool   `bson:"plug_and_charge,omitempty"` // ISO 15118 contract certificate authorization
+	EMAID                string `bson:"emaid,omitempty"`           // Contract ID of the Plug & Charge EV
 }
 
 // MeterValuesConfig holds meter values configuration
@@ -94,13 +111,43 @@ type CSMSAuth struct {
 
 // SimulationConfig holds simulation behavior settings
 type SimulationConfig struct {
-	BootDelay                  int     `bson:"boot_delay"`         // Seconds
-	HeartbeatInterval          int     `bson:"heartbeat_interval"` // Seconds
-	StatusNotificationOnChange bool    `bson:"status_notification_on_change"`
-	DefaultIDTag               string  `bson:"default_id_tag"`
-	EnergyDeliveryRate         int     `bson:"energy_delivery_rate"` // Watts
-	RandomizeMeterValues       bool    `bson:"randomize_meter_values"`
-	MeterValueVariance         float64 `bson:"meter_value_variance"` // 0.0-1.0
+	BootDelay                  int                      `bson:"boot_delay"`         // Seconds
+	HeartbeatInterval          int                      `bson:"heartbeat_interval"` // Seconds
+	StatusNotificationOnChange bool                     `bson:"status_notification_on_change"`
+	DefaultIDTag               string                   `bson:"default_id_tag"`
+	EnergyDeliveryRate         int                      `bson:"energy_delivery_rate"` // Watts
+	RandomizeMeterValues       bool                     `bson:"randomize_meter_values"`
+	MeterValueVariance         float64                  `bson:"meter_value_variance"` // 0.0-1.0
+	Firmware                   FirmwareSimulationConfig `bson:"firmware"`
+	EV                         EVSimulationConfig       `bson:"ev"`
+}
+
+// FirmwareSimulationConfig holds simulated firmware update and diagnostics settings
+type FirmwareSimulationConfig struct {
+	DownloadDuration int    `bson:"download_duration"` // Seconds
+	InstallDuration  int    `bson:"install_duration"`  // Seconds
+	UploadDuration   int    `bson:"upload_duration"`   // Seconds
+	DownloadFailures int    `bson:"download_failures"` // Failing attempts before success
+	UploadFailures   int    `bson:"upload_failures"`   // Failing attempts before success
+	FailInstallation bool   `bson:"fail_installation"`
+	TargetVersion    string `bson:"target_version,omitempty"`
+	PublishAddress   string `bson:"publish_address,omitempty"` // Local Controller file server
+}
+
+// EVSimulationConfig holds the simulated EV settings
+type EVSimulationConfig struct {
+	BatteryCapacity   int  `bson:"battery_capacity"`    // Wh
+	InitialSoC        int  `bson:"initial_soc"`         // Percent
+	TargetSoC         int  `bson:"target_soc"`          // Percent
+	MinSoC            int  `bson:"min_soc"`             // Percent
+	MaxChargePower    int  `bson:"max_charge_power"`    // Watts
+	MaxDischargePower int  `bson:"max_discharge_power"` // Watts
+	DepartureDuration int  `bson:"departure_duration"`  // Seconds
+	V2X               bool `bson:"v2x"`
+	ISO15118          bool `bson:"iso15118"`
+	EnergyRequest     int  `bson:"energy_request"` // Wh
+	MinCurrent        int  `bson:"min_current"`    // Amperes per phase
+	MaxCurrent        int  `bson:"max_current"`    // Amperes per phase
 }
 
 // Session represents a WebSocket session
@@ -138,3 +185,60 @@ type MeterValueMetadata struct {
 	TransactionID int    `bson:"transaction_id"`
 	Measurand     string `bson:"measurand"` // Energy.Active.Import.Register, etc.
 }
+
+// AuthorizationData holds the Local Authorization List and authorization cache of a station,
+// OCPP 2.0.1 idTokens are kept apart from the OCPP 1.6 ID tags
+type AuthorizationData struct {
+	ID               string               `bson:"_id,omitempty"`
+	StationID        string               `bson:"station_id"`
+	LocalListVersion int                  `bson:"local_list_version"`
+	LocalList        []AuthorizationEntry `bson:"local_list"`
+	Cache            []AuthorizationEntry `bson:"cache"`
+
+	IdTokenListVersion int            `bson:"id_token_list_version,omitempty"`
+	IdTokenList        []IdTokenEntry `bson:"id_token_list,omitempty"`
+	IdTokenCache       []IdTokenEntry `bson:"id_token_cache,omitempty"`
+
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// MessageQueueData holds the outbound transaction messages of a station that await delivery
+type MessageQueueData struct {
+	ID        string          `bson:"_id,omitempty"`
+	StationID string          `bson:"station_id"`
+	Messages  []QueuedMessage `bson:"messages"`
+	UpdatedAt time.Time       `bson:"updated_at"`
+}
+
+// QueuedMessage represents an OCPP Call waiting in the outbound queue
+type QueuedMessage struct {
+	Action        string    `bson:"action"`         // e.g., "StartTransaction", "MeterValues"
+	Payload       string    `bson:"payload"`        // JSON encoded request payload
+	TransactionID string    `bson:"transaction_id"` // Transaction the message belongs to
+	Attempts      int       `bson:"attempts"`       // Failed delivery attempts
+	QueuedAt      time.Time `bson:"queued_at"`
+}
+
+// IdTokenEntry represents an OCPP 2.0.1 idToken with its authorization info
+type IdTokenEntry struct {
+	IDToken          string     `bson:"id_token"`
+	Type             string     `bson:"type"`
+	Status           string     `bson:"status"`
+	CacheExpiry      *time.Time `bson:"cache_expiry,omitempty"`
+	ChargingPriority *int       `bson:"charging_priority,omitempty"`
+	Language1        string     `bson:"language1,omitempty"`
+	Language2        string     `bson:"language2,omitempty"`
+	GroupIDToken     string     `bson:"group_id_token,omitempty"`
+	GroupIDTokenType string     `bson:"group_id_token_type,omitempty"`
+	EvseIDs          []int      `bson:"evse_ids,omitempty"`
+	ExpiresAt        *time.Time `bson:"expires_at,omitempty"` // End of the cache lifetime
+	LastUsed         *time.Time `bson:"last_used,omitempty"`  // Cache eviction order
+}
+
+// AuthorizationEntry represents an ID tag with its authorization info
+type AuthorizationEntry struct {
+	IDTag       string     `bson:"id_tag"`
+	Status      string     `bson:"status"` // Accepted, Blocked, Expired, Invalid, ConcurrentTx
+	ExpiryDate  *time.Time `bson:"expiry_date,omitempty"`
+	ParentIDTag string     `bson:"parent_id_tag,omitempty"`
+}
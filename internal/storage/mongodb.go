@@ -20,11 +20,13 @@ type MongoDBClient struct {
 	logger   *slog.Logger
 
 	// Collections
-	MessagesCollection     *mongo.Collection
-	TransactionsCollection *mongo.Collection
-	StationsCollection     *mongo.Collection
-	SessionsCollection     *mongo.Collection
-	MeterValuesCollection  *mongo.Collection
+	MessagesCollection      *mongo.Collection
+	TransactionsCollection  *mongo.Collection
+	StationsCollection      *mongo.Collection
+	SessionsCollection      *mongo.Collection
+	MeterValuesCollection   *mongo.Collection
+	AuthorizationCollection *mongo.Collection
+	MessageQueueCollection  *mongo.Collection
 }
 
 // NewMongoDBClient creates a new MongoDB client and establishes connection
@@ -65,15 +67,17 @@ func NewMongoDBClient(ctx context.Context, cfg *config.MongoDBConfig, logger *sl
 
 	// Create MongoDBClient instance
 	mongoClient := &MongoDBClient{
-		client:                 client,
-		database:               database,
-		cfg:                    cfg,
-		logger:                 logger,
-		MessagesCollection:     database.Collection(cfg.Collections.Messages),
-		TransactionsCollection: database.Collection(cfg.Collections.Transactions),
-		StationsCollection:     database.Collection(cfg.Collections.Stations),
-		SessionsCollection:     database.Collection(cfg.Collections.Sessions),
-		MeterValuesCollection:  database.Collection(cfg.Collections.MeterValues),
+		client:                  client,
+		database:                database,
+		cfg:                     cfg,
+		logger:                  logger,
+		MessagesCollection:      database.Collection(cfg.Collections.Messages),
+		TransactionsCollection:  database.Collection(cfg.Collections.Transactions),
+		StationsCollection:      database.Collection(cfg.Collections.Stations),
+		SessionsCollection:      database.Collection(cfg.Collections.Sessions),
+		MeterValuesCollection:   database.Collection(cfg.Collections.MeterValues),
+		AuthorizationCollection: database.Collection(cfg.Collections.Authorization),
+		MessageQueueCollection:  database.Collection(cfg.Collections.MessageQueue),
 	}
 
 	// Initialize collections and indexes
@@ -235,6 +239,30 @@ func (m *MongoDBClient) createIndexes(ctx context.Context) error {
 		return fmt.Errorf("failed to create sessions indexes: %w", err)
 	}
 
+	// Authorization indexes (one document per station)
+	authorizationIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "station_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	if _, err := m.AuthorizationCollection.Indexes().CreateMany(ctx, authorizationIndexes); err != nil {
+		return fmt.Errorf("failed to create authorization indexes: %w", err)
+	}
+
+	// Message queue indexes (one document per station)
+	messageQueueIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "station_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	if _, err := m.MessageQueueCollection.Indexes().CreateMany(ctx, messageQueueIndexes); err != nil {
+		return fmt.Errorf("failed to create message queue indexes: %w", err)
+	}
+
 	m.logger.Info("Successfully created all indexes")
 	return nil
 }
@@ -283,6 +311,7 @@ func (m *MongoDBClient) HealthCheck(ctx context.Context) error {
 		m.cfg.Collections.Stations,
 		m.cfg.Collections.Sessions,
 		m.cfg.Collections.MeterValues,
+		m.cfg.Collections.Authorization,
 	}
 
 	dbCollections, err := m.database.ListCollectionNames(ctx, bson.M{})
@@ -339,6 +368,10 @@ func (m *MongoDBClient) Stats(ctx context.Context) (map[string]interface{}, erro
 		collectionCounts["meter_values"] = count
 	}
 
+	if count, err := m.AuthorizationCollection.CountDocuments(ctx, bson.M{}); err == nil {
+		collectionCounts["authorization"] = count
+	}
+
 	stats["collection_counts"] = collectionCounts
 
 	return stats, nil
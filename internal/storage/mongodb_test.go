@@ -24,11 +24,13 @@ func TestMongoDBConnection(t *testing.T) {
 		ConnectionTimeout: 10 * time.Second,
 		MaxPoolSize:       10,
 		Collections: config.MongoDBCollectionsConfig{
-			Messages:     "messages",
-			Transactions: "transactions",
-			Stations:     "stations",
-			Sessions:     "sessions",
-			MeterValues:  "meter_values",
+			Messages:      "messages",
+			Transactions:  "transactions",
+			Stations:      "stations",
+			Sessions:      "sessions",
+			MeterValues:   "meter_values",
+			Authorization: "authorization",
+			MessageQueue:  "message_queue",
 		},
 		TimeSeries: config.MongoDBTimeSeriesConfig{
 			Enabled:     true,
@@ -77,11 +79,13 @@ func TestMongoDBClientCreation(t *testing.T) {
 		ConnectionTimeout: 1 * time.Second,
 		MaxPoolSize:       10,
 		Collections: config.MongoDBCollectionsConfig{
-			Messages:     "messages",
-			Transactions: "transactions",
-			Stations:     "stations",
-			Sessions:     "sessions",
-			MeterValues:  "meter_values",
+			Messages:      "messages",
+			Transactions:  "transactions",
+			Stations:      "stations",
+			Sessions:      "sessions",
+			MeterValues:   "meter_values",
+			Authorization: "authorization",
+			MessageQueue:  "message_queue",
 		},
 		TimeSeries: config.MongoDBTimeSeriesConfig{
 			Enabled:     true,
@@ -21,6 +21,14 @@ print('Created stations collection');
 db.createCollection('sessions');
 print('Created sessions collection');
 
+// Create authorization collection (local auth list and cache per station)
+db.createCollection('authorization');
+print('Created authorization collection');
+
+// Create message queue collection (undelivered transaction messages per station)
+db.createCollection('message_queue');
+print('Created message_queue collection');
+
 // Create time-series collection for meter values
 db.createCollection('meter_values', {
   timeseries: {
@@ -63,6 +71,14 @@ db.sessions.createIndex({ station_id: 1, status: 1 });
 db.sessions.createIndex({ status: 1 });
 print('Created indexes for sessions');
 
+// Indexes for authorization collection
+db.authorization.createIndex({ station_id: 1 }, { unique: true });
+print('Created indexes for authorization');
+
+// Indexes for message queue collection
+db.message_queue.createIndex({ station_id: 1 }, { unique: true });
+print('Created indexes for message_queue');
+
 // Text search indexes for message debugging
 db.messages.createIndex({
   action: 'text',